@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/crypto/ssh/terminal"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8"
+	"gopkg.in/src-d/hercules.v8/leaves"
+)
+
+// blameCmd represents the blame command
+var blameCmd = &cobra.Command{
+	Use:   "blame <repository> <path>",
+	Short: "Print the commit which introduced each line of a file.",
+	Long: `Run the commit processing pipeline up to the revision and print the origin of each line
+of the file similar to "git blame": the commit, the author, the date, the day since the beginning
+of the history and the line number. Unlike "git blame", the renames are detected the same way as
+in the other analyses and the authors are merged the same way as in --people-dict.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		rev, err := flags.GetString("rev")
+		if err != nil {
+			panic(err)
+		}
+		firstParent, err := flags.GetBool("first-parent")
+		if err != nil {
+			panic(err)
+		}
+		peopleDict, err := flags.GetString("people-dict")
+		if err != nil {
+			panic(err)
+		}
+		disableStatus, err := flags.GetBool("quiet")
+		if err != nil {
+			panic(err)
+		}
+		repository := loadRepository(args[0], "", disableStatus, "")
+		var onProgress func(int, int, string)
+		if !disableStatus {
+			onProgress = func(step, total int, action string) {
+				fmt.Fprintf(os.Stderr, "\033[2K\r%d / %d [%s]", step, total, action)
+			}
+		}
+		report, err := blameFile(repository, args[1], rev, firstParent, map[string]interface{}{
+			hercules.ConfigIdentityDetectorPeopleDictPath: peopleDict,
+		}, onProgress)
+		if !disableStatus {
+			fmt.Fprint(os.Stderr, "\033[2K\r")
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		writeBlame(os.Stdout, report)
+	},
+}
+
+// blameReport is the outcome of blameFile().
+type blameReport struct {
+	leaves.BlameResult
+	// Contents are the lines of the file at the blamed revision.
+	Contents []string
+	// People is the reversed people dictionary, see identity.Detector.ReversedPeopleDict.
+	People []string
+}
+
+// blameFile runs BlameAnalysis on the history of `rev` (HEAD if empty) and blames the file
+// at `filePath`. `facts` override the default pipeline configuration.
+func blameFile(repository *git.Repository, filePath, rev string, firstParent bool,
+	facts map[string]interface{}, onProgress func(int, int, string)) (blameReport, error) {
+	filePath = strings.TrimPrefix(path.Clean(filepath.ToSlash(filePath)), "/")
+	if rev == "" {
+		rev = "HEAD"
+	}
+	hash, err := repository.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return blameReport{}, fmt.Errorf("unable to resolve %s: %v", rev, err)
+	}
+	commit, err := repository.CommitObject(*hash)
+	if err != nil {
+		return blameReport{}, fmt.Errorf("unable to load %s: %v", hash.String(), err)
+	}
+	file, err := commit.File(filePath)
+	if err == object.ErrFileNotFound {
+		return blameReport{}, fmt.Errorf("%s does not exist in %s", filePath, rev)
+	} else if err != nil {
+		return blameReport{}, err
+	}
+	if binary, err := file.IsBinary(); err != nil {
+		return blameReport{}, err
+	} else if binary {
+		return blameReport{}, fmt.Errorf("%s is binary", filePath)
+	}
+	contents, err := file.Lines()
+	if err != nil {
+		return blameReport{}, err
+	}
+
+	pipeline := hercules.NewPipeline(repository)
+	pipeline.OnProgress = onProgress
+	commits, err := pipeline.CommitsInRange(hercules.RevisionRange{Head: hash.String()}, firstParent)
+	if err != nil {
+		return blameReport{}, fmt.Errorf("failed to list the commits: %v", err)
+	}
+	// the defaults of all the options are the same as on the command line of the root command
+	pipelineFacts, _ := hercules.Registry.AddFlags(pflag.NewFlagSet("blame", pflag.ContinueOnError))
+	for key, val := range facts {
+		pipelineFacts[key] = val
+	}
+	pipelineFacts[hercules.ConfigPipelineCommits] = commits
+	pipelineFacts[leaves.ConfigBlamePath] = filePath
+	blame := pipeline.DeployItem(&leaves.BlameAnalysis{}).(hercules.LeafPipelineItem)
+	if err = pipeline.Initialize(pipelineFacts); err != nil {
+		return blameReport{}, err
+	}
+	results, err := pipeline.Run(commits)
+	if err != nil {
+		return blameReport{}, fmt.Errorf("failed to run the pipeline: %v", err)
+	}
+	result := results[blame].(leaves.BlameResult)
+	if result.Lines == nil {
+		return blameReport{}, fmt.Errorf("%s is excluded from the analysis", filePath)
+	}
+	if len(result.Lines) != len(contents) {
+		return blameReport{}, fmt.Errorf("internal integrity error: blamed %d lines in %s "+
+			"while it has %d", len(result.Lines), filePath, len(contents))
+	}
+	people, _ := pipelineFacts[hercules.FactIdentityDetectorReversedPeopleDict].([]string)
+	return blameReport{BlameResult: result, Contents: contents, People: people}, nil
+}
+
+// blameAuthorName returns the name of the author of the line. The identified authors are
+// named after the first name in the people dictionary.
+func blameAuthorName(line leaves.BlameLine, people []string) string {
+	if line.Author != hercules.AuthorMissing && line.Author < len(people) {
+		return strings.SplitN(people[line.Author], "|", 2)[0]
+	}
+	if line.Commit != nil {
+		return line.Commit.Author.Name
+	}
+	return "?"
+}
+
+// writeBlame prints the report in the format which resembles "git blame".
+func writeBlame(writer io.Writer, report blameReport) {
+	names := make([]string, len(report.Lines))
+	nameWidth, dayWidth := 0, 0
+	for i, line := range report.Lines {
+		names[i] = blameAuthorName(line, report.People)
+		if len(names[i]) > nameWidth {
+			nameWidth = len(names[i])
+		}
+		if width := len(strconv.Itoa(line.Day)); width > dayWidth {
+			dayWidth = width
+		}
+	}
+	lineWidth := len(strconv.Itoa(len(report.Lines)))
+	for i, line := range report.Lines {
+		hash := strings.Repeat("0", 8)
+		date := strings.Repeat("?", 25)
+		if line.Commit != nil {
+			hash = line.Commit.Hash.String()[:8]
+			date = line.Commit.Author.When.Format("2006-01-02 15:04:05 -0700")
+		}
+		fmt.Fprintf(writer, "%s (%-*s %s %*d %*d) %s\n", hash, nameWidth, names[i], date,
+			dayWidth, line.Day, lineWidth, i+1, report.Contents[i])
+	}
+}
+
+func init() {
+	// set the default usage function before the command inherits formatUsage() from rootCmd
+	blameCmd.SetUsageFunc(blameCmd.UsageFunc())
+	rootCmd.AddCommand(blameCmd)
+	blameFlags := blameCmd.Flags()
+	blameFlags.String("rev", "", "The revision to blame instead of HEAD: a branch, a tag "+
+		"or a full hash.")
+	blameFlags.Bool("first-parent", false, "Follow only the first parent in the commit history - "+
+		"\"git log --first-parent\".")
+	blameFlags.String("people-dict", "", "Path to the file with the developer -> email "+
+		"associations, the same as in the root command.")
+	err := blameCmd.MarkFlagFilename("people-dict")
+	if err != nil {
+		panic(err)
+	}
+	hercules.PathifyFlagValue(blameFlags.Lookup("people-dict"))
+	blameFlags.Bool("quiet", !terminal.IsTerminal(int(os.Stdin.Fd())),
+		"Do not print status updates to stderr.")
+}
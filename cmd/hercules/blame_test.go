@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8"
+	"gopkg.in/src-d/hercules.v8/leaves"
+)
+
+func TestBlameFile(t *testing.T) {
+	path, err := filepath.Abs(filepath.Join("..", ".."))
+	assert.Nil(t, err)
+	repository := loadRepository(path, "", true, "")
+	report, err := blameFile(repository, "./LICENSE.md", "", true, map[string]interface{}{}, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "LICENSE.md", report.Path)
+	assert.True(t, len(report.Lines) > 0)
+	assert.Len(t, report.Contents, len(report.Lines))
+	for _, line := range report.Lines {
+		assert.NotNil(t, line.Commit)
+	}
+	assert.True(t, len(report.People) > 0)
+	_, err = blameFile(repository, "xxx", "", true, nil, nil)
+	assert.NotNil(t, err)
+	_, err = blameFile(repository, "LICENSE.md", "xxx", true, nil, nil)
+	assert.NotNil(t, err)
+}
+
+func TestWriteBlame(t *testing.T) {
+	commit := &object.Commit{
+		Hash: plumbing.NewHash("cce947b98a050c6d356bc6ba95030254914027b1"),
+		Author: object.Signature{
+			Name: "Vadim Markovtsev",
+			When: time.Date(2016, 12, 12, 17, 30, 29, 0, time.FixedZone("", 3600)),
+		},
+	}
+	report := blameReport{
+		BlameResult: leaves.BlameResult{Path: "main.go", Lines: []leaves.BlameLine{
+			{Commit: commit, Author: 0, Day: 0},
+			{Commit: commit, Author: hercules.AuthorMissing, Day: 10},
+			{Author: hercules.AuthorMissing, Day: 100},
+		}},
+		Contents: []string{"package main", "// main", "func main() {}"},
+		People:   []string{"vadim|vadim@sourced.tech"},
+	}
+	buffer := &bytes.Buffer{}
+	writeBlame(buffer, report)
+	assert.Equal(t, `cce947b9 (vadim            2016-12-12 17:30:29 +0100   0 1) package main
+cce947b9 (Vadim Markovtsev 2016-12-12 17:30:29 +0100  10 2) // main
+00000000 (?                ????????????????????????? 100 3) func main() {}
+`, buffer.String())
+}
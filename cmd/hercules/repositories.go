@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	progress "gopkg.in/cheggaaa/pb.v1"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing/identity"
+)
+
+// repositorySpec is a single line in the file passed to --repositories.
+type repositorySpec struct {
+	// URI is the same as the first positional argument of hercules.
+	URI string
+	// CachePath is the same as the second positional argument of hercules. Empty if not set.
+	CachePath string
+}
+
+// readRepositoriesManifest parses the file with the list of repositories to analyse.
+// Each line contains the repository URI optionally followed by the cache path,
+// separated with whitespace. Empty lines and lines which start with "#" are ignored.
+func readRepositoriesManifest(path string) ([]repositorySpec, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var specs []repositorySpec
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("%s:%d: expected the URI and the optional cache path",
+				path, lineNumber)
+		}
+		spec := repositorySpec{URI: fields[0]}
+		if len(fields) == 2 {
+			spec.CachePath = fields[1]
+		}
+		specs = append(specs, spec)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("%s does not list any repositories", path)
+	}
+	return specs, nil
+}
+
+// requestedLeaves returns the sorted names of the analyses which were enabled on the command line.
+func requestedLeaves() []string {
+	var leaves []string
+	for name, valPtr := range cmdlineDeployed {
+		if *valPtr {
+			leaves = append(leaves, name)
+		}
+	}
+	sort.Strings(leaves)
+	return leaves
+}
+
+// deployLeaves adds the named analyses to the pipeline. It returns nothing in the dry run mode.
+func deployLeaves(pipeline *hercules.Pipeline, leaves []string, dryRun bool) []hercules.LeafPipelineItem {
+	var deployed []hercules.LeafPipelineItem
+	for _, name := range leaves {
+		item := pipeline.DeployItem(hercules.Registry.Summon(name)[0])
+		if !dryRun {
+			deployed = append(deployed, item.(hercules.LeafPipelineItem))
+		}
+	}
+	return deployed
+}
+
+// forEachRepository calls `action` with every index from 0 to n-1 on up to `jobs` goroutines.
+func forEachRepository(n, jobs int, action func(int)) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for j := 0; j < jobs; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				action(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
+
+// analyseRepositories runs the same analyses on each repository, up to `jobs` repositories
+// at the same time, and merges the results with ResultMergeablePipelineItem.MergeResults().
+// Unless --people-dict is specified, the identities are resolved against the single
+// people dictionary generated from the commits of all the repositories, so that every
+// developer has the same index in each result. `listCommits` selects the commits to analyse.
+// The returned items and results have the same meaning as in printResults().
+func analyseRepositories(
+	specs []repositorySpec, leaves []string, jobs int, facts map[string]interface{},
+	listCommits func(*hercules.Pipeline, *git.Repository) ([]*object.Commit, error),
+	disableStatus bool, sshIdentity string) (
+	[]hercules.LeafPipelineItem, map[hercules.LeafPipelineItem]interface{}) {
+
+	repositories := make([]*git.Repository, len(specs))
+	histories := make([][]*object.Commit, len(specs))
+	if !disableStatus {
+		fmt.Fprint(os.Stderr, "git log...\r")
+	}
+	forEachRepository(len(specs), jobs, func(i int) {
+		repositories[i] = loadRepository(specs[i].URI, specs[i].CachePath, true, sshIdentity)
+		commits, err := listCommits(hercules.NewPipeline(repositories[i]), repositories[i])
+		if err != nil {
+			log.Fatalf("failed to list the commits of %s: %v", specs[i].URI, err)
+		}
+		histories[i] = commits
+	})
+	if path, _ := facts[identity.ConfigIdentityDetectorPeopleDictPath].(string); path == "" {
+		detector := identity.Detector{}
+		detector.CoAuthors, _ = facts[identity.ConfigIdentityDetectorCoAuthors].(bool)
+		detector.SignedOff, _ = facts[identity.ConfigIdentityDetectorSignedOff].(bool)
+		if policy, _ := facts[identity.ConfigIdentityDetectorBots].(string); policy != "" {
+			patterns, _ := facts[identity.ConfigIdentityDetectorBotPatterns].([]string)
+			botPatterns, err := identity.ParseBotPatterns(patterns)
+			if err != nil {
+				log.Fatal(err)
+			}
+			detector.BotsPolicy = identity.BotsPolicy(policy)
+			detector.BotPatterns = botPatterns
+		}
+		detector.GenerateSharedPeopleDict(histories)
+		facts[hercules.FactIdentityDetectorPeopleDict] = detector.PeopleDict
+		facts[hercules.FactIdentityDetectorReversedPeopleDict] = detector.ReversedPeopleDict
+	}
+
+	var bar *progress.ProgressBar
+	if !disableStatus {
+		bar = progress.New(len(specs))
+		bar.Callback = func(msg string) {
+			os.Stderr.WriteString("\033[2K\r" + msg)
+		}
+		bar.NotPrint = true
+		bar.ShowPercent = false
+		bar.ShowSpeed = false
+		bar.SetMaxWidth(80).Start()
+	}
+	dryRun, _ := facts[hercules.ConfigPipelineDryRun].(bool)
+	deployed := make([][]hercules.LeafPipelineItem, len(specs))
+	results := make([]map[hercules.LeafPipelineItem]interface{}, len(specs))
+	forEachRepository(len(specs), jobs, func(i int) {
+		pipeline := hercules.NewPipeline(repositories[i])
+		pipeline.SetFeaturesFromFlags()
+		repositoryFacts := map[string]interface{}{}
+		for key, val := range facts {
+			repositoryFacts[key] = val
+		}
+		repositoryFacts[hercules.ConfigPipelineCommits] = histories[i]
+		deployed[i] = deployLeaves(pipeline, leaves, dryRun)
+		if err := pipeline.Initialize(repositoryFacts); err != nil {
+			log.Fatalf("failed to initialize the pipeline for %s: %v", specs[i].URI, err)
+		}
+		var err error
+		results[i], err = pipeline.Run(histories[i])
+		if err != nil {
+			log.Fatalf("failed to run the pipeline on %s: %v", specs[i].URI, err)
+		}
+		// release the memory as soon as possible
+		repositories[i] = nil
+		histories[i] = nil
+		if bar != nil {
+			bar.Increment()
+		}
+	})
+	if bar != nil {
+		bar.Finish()
+		fmt.Fprint(os.Stderr, "\033[2K\rmerging...")
+	}
+
+	mergedResults := map[string]interface{}{}
+	mergedCommons := &hercules.CommonAnalysisResult{}
+	for i := range specs {
+		namedResults := map[string]interface{}{}
+		for _, item := range deployed[i] {
+			namedResults[item.Name()] = results[i][item]
+		}
+		mergeResults(mergedResults, mergedCommons, namedResults,
+			results[i][nil].(*hercules.CommonAnalysisResult), "")
+		results[i] = nil
+	}
+	merged := map[hercules.LeafPipelineItem]interface{}{nil: mergedCommons}
+	for _, item := range deployed[0] {
+		merged[item] = mergedResults[item.Name()]
+	}
+	return deployed[0], merged
+}
+
+// repositoriesURI joins the URIs of the repositories for the header of the merged results.
+func repositoriesURI(specs []repositorySpec) string {
+	uris := make([]string, len(specs))
+	for i, spec := range specs {
+		uris[i] = spec.URI
+	}
+	return strings.Join(uris, " & ")
+}
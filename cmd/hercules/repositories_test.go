@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8"
+	"gopkg.in/src-d/hercules.v8/leaves"
+)
+
+func TestReadRepositoriesManifest(t *testing.T) {
+	tmp, err := ioutil.TempFile("", "hercules-test-")
+	assert.Nil(t, err)
+	defer os.Remove(tmp.Name())
+	tmp.WriteString("# comment\n\n/path/to/repo\n  https://github.com/src-d/hercules /tmp/cache \n")
+	tmp.Close()
+	specs, err := readRepositoriesManifest(tmp.Name())
+	assert.Nil(t, err)
+	assert.Equal(t, []repositorySpec{
+		{URI: "/path/to/repo"},
+		{URI: "https://github.com/src-d/hercules", CachePath: "/tmp/cache"},
+	}, specs)
+	assert.Equal(t, "/path/to/repo & https://github.com/src-d/hercules", repositoriesURI(specs))
+
+	assert.Nil(t, ioutil.WriteFile(tmp.Name(), []byte("a b c\n"), 0666))
+	specs, err = readRepositoriesManifest(tmp.Name())
+	assert.Nil(t, specs)
+	assert.NotNil(t, err)
+	assert.Nil(t, ioutil.WriteFile(tmp.Name(), []byte("# nothing\n"), 0666))
+	specs, err = readRepositoriesManifest(tmp.Name())
+	assert.Nil(t, specs)
+	assert.NotNil(t, err)
+	specs, err = readRepositoriesManifest("/xxx")
+	assert.Nil(t, specs)
+	assert.NotNil(t, err)
+}
+
+func TestForEachRepository(t *testing.T) {
+	for _, jobs := range []int{0, 1, 3, 20} {
+		visited := make([]int32, 10)
+		forEachRepository(len(visited), jobs, func(i int) {
+			atomic.AddInt32(&visited[i], 1)
+		})
+		for _, val := range visited {
+			assert.Equal(t, int32(1), val)
+		}
+	}
+}
+
+func TestAnalyseRepositories(t *testing.T) {
+	repository, err := filepath.Abs(filepath.Join("..", ".."))
+	assert.Nil(t, err)
+	specs := []repositorySpec{{URI: repository}, {URI: repository}}
+	facts := map[string]interface{}{}
+	for key, val := range cmdlineFacts {
+		facts[key] = val
+	}
+	listed := int32(0)
+	listCommits := func(
+		pipeline *hercules.Pipeline, repository *git.Repository) ([]*object.Commit, error) {
+		atomic.AddInt32(&listed, 1)
+		return pipeline.Commits(true)
+	}
+	deployed, results := analyseRepositories(
+		specs, []string{"Devs"}, 2, facts, listCommits, true, "")
+	assert.Equal(t, int32(2), listed)
+	if !assert.Len(t, deployed, 1) {
+		return
+	}
+	assert.Equal(t, "Devs", deployed[0].Name())
+	reversedPeopleDict := facts[hercules.FactIdentityDetectorReversedPeopleDict].([]string)
+	assert.True(t, len(reversedPeopleDict) > 0)
+	commits, err := hercules.NewPipeline(loadRepository(repository, "", true, "")).Commits(true)
+	assert.Nil(t, err)
+	commons := results[nil].(*hercules.CommonAnalysisResult)
+	assert.Equal(t, 2*len(commits), commons.CommitsNumber)
+	devs := results[deployed[0]].(leaves.DevsResult)
+	assert.True(t, len(devs.Days) > 0)
+}
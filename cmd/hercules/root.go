@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -15,6 +17,7 @@ import (
 	"runtime/pprof"
 	"strings"
 	"text/template"
+	"time"
 	"unicode"
 
 	"github.com/Masterminds/sprig"
@@ -36,6 +39,7 @@ import (
 	"gopkg.in/src-d/go-git.v4/storage/memory"
 	"gopkg.in/src-d/hercules.v8"
 	"gopkg.in/src-d/hercules.v8/internal/pb"
+	"gopkg.in/src-d/hercules.v8/internal/yaml"
 )
 
 // oneLineWriter splits the output data by lines and outputs one on top of another using '\r'.
@@ -171,7 +175,15 @@ var rootCmd = &cobra.Command{
 the commit processing pipeline which is automatically generated from the dependencies of one
 or several analysis targets. The list of the available targets is printed in --help. External
 targets can be added using the --plugin system.`,
-	Args: cobra.RangeArgs(1, 2),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if manifest, _ := cmd.Flags().GetString("repositories"); manifest != "" {
+			if len(args) > 0 {
+				return errors.New("--repositories is mutually exclusive with the repository arguments")
+			}
+			return nil
+		}
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		flags := cmd.Flags()
 		getBool := func(name string) bool {
@@ -190,10 +202,47 @@ targets can be added using the --plugin system.`,
 		}
 		firstParent := getBool("first-parent")
 		commitsFile := getString("commits")
+		rev := getString("rev")
+		revisionRange := hercules.ParseRevisionRange(rev)
+		var err error
+		if since := getString("since"); since != "" {
+			if revisionRange.Since, err = parseDate(since, false); err != nil {
+				log.Fatalf("invalid --since: %v", err)
+			}
+		}
+		if until := getString("until"); until != "" {
+			if revisionRange.Until, err = parseDate(until, true); err != nil {
+				log.Fatalf("invalid --until: %v", err)
+			}
+		}
+		selectRange := rev != "" || !revisionRange.IsFullHistory()
+		if selectRange && commitsFile != "" {
+			log.Fatal("--commits is mutually exclusive with --rev, --since and --until")
+		}
+		manifest := getString("repositories")
+		jobs, err := flags.GetInt("jobs")
+		if err != nil {
+			panic(err)
+		}
 		protobuf := getBool("pb")
+		jsonOutput := getBool("json")
 		profile := getBool("profile")
 		disableStatus := getBool("quiet")
 		sshIdentity := getString("ssh-identity")
+		if protobuf && jsonOutput {
+			log.Fatal("--pb and --json are mutually exclusive")
+		}
+		leaves := requestedLeaves()
+		for _, name := range leaves {
+			item := hercules.Registry.Summon(name)[0]
+			if _, ok := item.(hercules.JSONSerializablePipelineItem); jsonOutput && !ok {
+				log.Fatalf("%s does not support the JSON output", item.Name())
+			}
+			if _, ok := item.(hercules.ResultMergeablePipelineItem); manifest != "" && !ok {
+				log.Fatalf("%s does not support merging the results of several repositories",
+					item.Name())
+			}
+		}
 
 		if profile {
 			go func() {
@@ -209,6 +258,47 @@ targets can be added using the --plugin system.`,
 			}
 			defer pprof.StopCPUProfile()
 		}
+		listCommits := func(
+			pipeline *hercules.Pipeline, repository *git.Repository) ([]*object.Commit, error) {
+			if selectRange {
+				commits, err := pipeline.CommitsInRange(revisionRange, firstParent)
+				if err == nil && len(commits) == 0 {
+					err = errors.New("there are no commits in the specified range")
+				}
+				return commits, err
+			}
+			if commitsFile != "" {
+				return hercules.LoadCommitsFromFile(commitsFile, repository)
+			}
+			return pipeline.Commits(firstParent)
+		}
+		if selectRange {
+			cmdlineFacts[hercules.ConfigPipelinePartialHistory] = true
+		}
+
+		if manifest != "" {
+			if commitsFile != "" {
+				log.Fatal("--commits is mutually exclusive with --repositories")
+			}
+			for _, name := range []string{
+				hercules.ConfigPipelineCheckpointPath, hercules.ConfigPipelineResumeFrom} {
+				if path, _ := cmdlineFacts[name].(string); path != "" {
+					log.Fatal("checkpoints are not supported with --repositories")
+				}
+			}
+			specs, err := readRepositoriesManifest(manifest)
+			if err != nil {
+				log.Fatalf("failed to read %s: %v", manifest, err)
+			}
+			deployed, results := analyseRepositories(
+				specs, leaves, jobs, cmdlineFacts, listCommits, disableStatus, sshIdentity)
+			if !disableStatus {
+				fmt.Fprint(os.Stderr, "\033[2K\r")
+			}
+			writeResults(repositoriesURI(specs), deployed, results, protobuf, jsonOutput)
+			return
+		}
+
 		uri := args[0]
 		cachePath := ""
 		if len(args) == 2 {
@@ -241,32 +331,22 @@ targets can be added using the --plugin system.`,
 			}
 		}
 
-		var commits []*object.Commit
-		var err error
 		if commitsFile == "" {
 			fmt.Fprint(os.Stderr, "git log...\r")
-			commits, err = pipeline.Commits(firstParent)
-		} else {
-			commits, err = hercules.LoadCommitsFromFile(commitsFile, repository)
 		}
+		commits, err := listCommits(pipeline, repository)
 		if err != nil {
 			log.Fatalf("failed to list the commits: %v", err)
 		}
 		cmdlineFacts[hercules.ConfigPipelineCommits] = commits
 		dryRun, _ := cmdlineFacts[hercules.ConfigPipelineDryRun].(bool)
-		var deployed []hercules.LeafPipelineItem
-		for name, valPtr := range cmdlineDeployed {
-			if *valPtr {
-				item := pipeline.DeployItem(hercules.Registry.Summon(name)[0])
-				if !dryRun {
-					deployed = append(deployed, item.(hercules.LeafPipelineItem))
-				}
-			}
-		}
+		deployed := deployLeaves(pipeline, leaves, dryRun)
 		err = pipeline.Initialize(cmdlineFacts)
 		if err != nil {
 			log.Fatal(err)
 		}
+		// Initialize() leaves only the new commits if we resume from a checkpoint
+		commits = cmdlineFacts[hercules.ConfigPipelineCommits].([]*object.Commit)
 		results, err := pipeline.Run(commits)
 		if err != nil {
 			log.Fatalf("failed to run the pipeline: %v", err)
@@ -278,14 +358,23 @@ targets can be added using the --plugin system.`,
 				fmt.Fprint(os.Stderr, "writing...\r")
 			}
 		}
-		if !protobuf {
-			printResults(uri, deployed, results)
-		} else {
-			protobufResults(uri, deployed, results)
-		}
+		writeResults(uri, deployed, results, protobuf, jsonOutput)
 	},
 }
 
+// writeResults prints the analysis results to stdout in the format chosen on the command line.
+func writeResults(
+	uri string, deployed []hercules.LeafPipelineItem,
+	results map[hercules.LeafPipelineItem]interface{}, protobuf, jsonOutput bool) {
+	if protobuf {
+		protobufResults(uri, deployed, results)
+	} else if jsonOutput {
+		jsonResults(uri, deployed, results, os.Stdout)
+	} else {
+		printResults(uri, deployed, results)
+	}
+}
+
 func printResults(
 	uri string, deployed []hercules.LeafPipelineItem,
 	results map[hercules.LeafPipelineItem]interface{}) {
@@ -299,6 +388,10 @@ func printResults(
 	fmt.Println("  end_unix_time:", commonResult.EndTime)
 	fmt.Println("  commits:", commonResult.CommitsNumber)
 	fmt.Println("  run_time:", commonResult.RunTime.Nanoseconds()/1e6)
+	fmt.Println("  tick_size:", int64(commonResult.TickSize/time.Second))
+	printStrings("include_patterns", commonResult.IncludePatterns)
+	printStrings("exclude_patterns", commonResult.ExcludePatterns)
+	printStrings("bots", commonResult.Bots)
 
 	for _, item := range deployed {
 		result := results[item]
@@ -309,9 +402,36 @@ func printResults(
 	}
 }
 
+// printStrings writes the YAML list of strings if it is not empty.
+func printStrings(key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Printf("  %s:\n", key)
+	for _, value := range values {
+		fmt.Println("    -", yaml.SafeString(value))
+	}
+}
+
 func protobufResults(
 	uri string, deployed []hercules.LeafPipelineItem,
 	results map[hercules.LeafPipelineItem]interface{}) {
+	message, err := newResultsMessage(uri, deployed, results)
+	if err != nil {
+		panic(err)
+	}
+	serialized, err := proto.Marshal(message)
+	if err != nil {
+		panic(err)
+	}
+	os.Stdout.Write(serialized)
+}
+
+// newResultsMessage serializes the analysis results to the message which protobufResults() writes.
+// It is shared with the server, see serve.go.
+func newResultsMessage(
+	uri string, deployed []hercules.LeafPipelineItem,
+	results map[hercules.LeafPipelineItem]interface{}) (*pb.AnalysisResults, error) {
 
 	header := pb.Metadata{
 		Version:    2,
@@ -329,16 +449,68 @@ func protobufResults(
 		result := results[item]
 		buffer := &bytes.Buffer{}
 		if err := item.Serialize(result, true, buffer); err != nil {
-			panic(err)
+			return nil, err
 		}
 		message.Contents[item.Name()] = buffer.Bytes()
 	}
+	return &message, nil
+}
 
-	serialized, err := proto.Marshal(&message)
+// jsonResults writes the analysis results as a single JSON object. The "hercules" key maps to
+// the same header as in printResults(), the rest of the keys are the names of the items.
+// The schemas are documented in doc/JSON.md.
+func jsonResults(
+	uri string, deployed []hercules.LeafPipelineItem,
+	results map[hercules.LeafPipelineItem]interface{}, writer io.Writer) {
+	commonResult := results[nil].(*hercules.CommonAnalysisResult)
+	header, err := json.Marshal(struct {
+		Version         int      `json:"version"`
+		Hash            string   `json:"hash"`
+		Repository      string   `json:"repository"`
+		BeginUnixTime   int64    `json:"begin_unix_time"`
+		EndUnixTime     int64    `json:"end_unix_time"`
+		Commits         int      `json:"commits"`
+		RunTime         int64    `json:"run_time"`
+		TickSize        int64    `json:"tick_size"`
+		IncludePatterns []string `json:"include_patterns,omitempty"`
+		ExcludePatterns []string `json:"exclude_patterns,omitempty"`
+		Bots            []string `json:"bots,omitempty"`
+	}{
+		Version:         hercules.BinaryVersion,
+		Hash:            hercules.BinaryGitHash,
+		Repository:      uri,
+		BeginUnixTime:   commonResult.BeginTime,
+		EndUnixTime:     commonResult.EndTime,
+		Commits:         commonResult.CommitsNumber,
+		RunTime:         commonResult.RunTime.Nanoseconds() / 1e6,
+		TickSize:        int64(commonResult.TickSize / time.Second),
+		IncludePatterns: commonResult.IncludePatterns,
+		ExcludePatterns: commonResult.ExcludePatterns,
+		Bots:            commonResult.Bots,
+	})
 	if err != nil {
 		panic(err)
 	}
-	os.Stdout.Write(serialized)
+	buffer := &bytes.Buffer{}
+	buffer.WriteString("{\"hercules\":")
+	buffer.Write(header)
+	for _, item := range deployed {
+		name, err := json.Marshal(item.Name())
+		if err != nil {
+			panic(err)
+		}
+		buffer.WriteRune(',')
+		buffer.Write(name)
+		buffer.WriteRune(':')
+		err = item.(hercules.JSONSerializablePipelineItem).SerializeJSON(results[item], buffer)
+		if err != nil {
+			panic(err)
+		}
+		// json.Encoder appends a newline
+		buffer.Truncate(len(bytes.TrimRight(buffer.Bytes(), "\n")))
+	}
+	buffer.WriteString("}\n")
+	writer.Write(buffer.Bytes())
 }
 
 // trimRightSpace removes the trailing whitespace characters.
@@ -462,6 +634,18 @@ Use "{{.c.CommandPath}} [command] --help" for more information about a command.{
 	return err
 }
 
+// parseDate reads the value of --since or --until. The dates without the time of day are
+// in the local time zone and denote the beginning of the day or the end of the day if `end` is true.
+func parseDate(value string, end bool) (time.Time, error) {
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if end {
+			date = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
 // versionCmd prints the API version and the Git commit hash
 var versionCmd = &cobra.Command{
 	Use:   "version",
@@ -490,7 +674,24 @@ func init() {
 	hercules.PathifyFlagValue(rootFlags.Lookup("commits"))
 	rootFlags.Bool("first-parent", false, "Follow only the first parent in the commit history - "+
 		"\"git log --first-parent\".")
+	rootFlags.String("rev", "", "The revision to analyse instead of HEAD: a branch, a tag "+
+		"or a full hash. \"A..B\" analyses the commits reachable from B but not from A.")
+	rootFlags.String("since", "", "Analyse only the commits which were committed at this date "+
+		"or later. The format is YYYY-MM-DD or RFC3339.")
+	rootFlags.String("until", "", "Analyse only the commits which were committed at this date "+
+		"or earlier. The format is YYYY-MM-DD or RFC3339.")
+	rootFlags.String("repositories", "", "Path to the text file with the repositories to "+
+		"analyse instead of the positional arguments, one \"URI [cache path]\" per line. "+
+		"The results are merged and the developers are matched across the repositories.")
+	err = rootCmd.MarkFlagFilename("repositories")
+	if err != nil {
+		panic(err)
+	}
+	hercules.PathifyFlagValue(rootFlags.Lookup("repositories"))
+	rootFlags.Int("jobs", 1, "The number of repositories to analyse at the same time "+
+		"with --repositories.")
 	rootFlags.Bool("pb", false, "The output format will be Protocol Buffers instead of YAML.")
+	rootFlags.Bool("json", false, "The output format will be JSON instead of YAML.")
 	rootFlags.Bool("quiet", !terminal.IsTerminal(int(os.Stdin.Fd())),
 		"Do not print status updates to stderr.")
 	rootFlags.Bool("profile", false, "Collect the profile to hercules.pprof.")
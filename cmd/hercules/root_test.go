@@ -1,18 +1,23 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/src-d/go-billy.v4/osfs"
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing/cache"
 	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+	"gopkg.in/src-d/hercules.v8"
+	"gopkg.in/src-d/hercules.v8/leaves"
 )
 
 func TestLoadRepository(t *testing.T) {
@@ -48,3 +53,43 @@ func TestLoadRepository(t *testing.T) {
 	assert.Panics(t, func() { loadRepository(filepath.Dir(filename), "", true, "") })
 	assert.Panics(t, func() { loadRepository("/xxx", "", true, "") })
 }
+
+func TestJSONResults(t *testing.T) {
+	history := &leaves.FileHistory{}
+	assert.Nil(t, history.Initialize(nil))
+	results := map[hercules.LeafPipelineItem]interface{}{
+		nil: &hercules.CommonAnalysisResult{
+			BeginTime: 1481719198, EndTime: 1539192488, CommitsNumber: 10, RunTime: 2 * time.Second,
+			TickSize: time.Hour,
+		},
+		history: history.Finalize(),
+	}
+	buffer := &bytes.Buffer{}
+	jsonResults("https://github.com/src-d/hercules",
+		[]hercules.LeafPipelineItem{history}, results, buffer)
+	var message map[string]json.RawMessage
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &message))
+	assert.Len(t, message, 2)
+	assert.Equal(t, `{"files":{}}`, string(message[history.Name()]))
+	var header map[string]interface{}
+	assert.Nil(t, json.Unmarshal(message["hercules"], &header))
+	assert.Equal(t, "https://github.com/src-d/hercules", header["repository"])
+	assert.Equal(t, float64(1481719198), header["begin_unix_time"])
+	assert.Equal(t, float64(1539192488), header["end_unix_time"])
+	assert.Equal(t, float64(10), header["commits"])
+	assert.Equal(t, float64(2000), header["run_time"])
+	assert.Equal(t, float64(3600), header["tick_size"])
+	assert.Equal(t, float64(hercules.BinaryVersion), header["version"])
+	assert.NotContains(t, header, "include_patterns")
+
+	results[nil].(*hercules.CommonAnalysisResult).IncludePatterns = []string{"services/**/*.go"}
+	results[nil].(*hercules.CommonAnalysisResult).ExcludePatterns = []string{"*_test.go"}
+	buffer.Reset()
+	jsonResults("https://github.com/src-d/hercules",
+		[]hercules.LeafPipelineItem{history}, results, buffer)
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &message))
+	header = nil
+	assert.Nil(t, json.Unmarshal(message["hercules"], &header))
+	assert.Equal(t, []interface{}{"services/**/*.go"}, header["include_patterns"])
+	assert.Equal(t, []interface{}{"*_test.go"}, header["exclude_patterns"])
+}
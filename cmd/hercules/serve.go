@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8"
+	"gopkg.in/src-d/hercules.v8/internal/pb"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the analyses of local repositories on gRPC requests.",
+	Long: `Listen for gRPC requests to analyse local repositories. The service is "Hercules"
+in internal/pb/pb.proto. Each request specifies the repository path, the analyses and their
+options as command line flags. The server streams the progress and finally sends the same
+AnalysisResults message which is written with --pb. Requests on different repositories are
+executed concurrently, requests on the same repository are queued.`,
+	Args: cobra.MaximumNArgs(0),
+	Run: func(cmd *cobra.Command, args []string) {
+		address, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			panic(err)
+		}
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			log.Fatalf("failed to listen on %s: %v", address, err)
+		}
+		grpcServer := grpc.NewServer()
+		pb.RegisterHerculesServer(grpcServer, newAnalysisServer())
+		log.Printf("listening on %s\n", listener.Addr())
+		if err = grpcServer.Serve(listener); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+// analysisServer implements pb.HerculesServer.
+type analysisServer struct {
+	// flagsMutex serializes hercules.Registry.AddFlags() which is not thread-safe.
+	flagsMutex sync.Mutex
+	// repositoriesMutex guards repositories.
+	repositoriesMutex sync.Mutex
+	// repositories maps the absolute paths of the analysed repositories to their locks.
+	repositories map[string]*sync.Mutex
+}
+
+func newAnalysisServer() *analysisServer {
+	return &analysisServer{repositories: map[string]*sync.Mutex{}}
+}
+
+// Analyse runs the requested analyses on a local repository. The stream contains the progress
+// updates followed by the results.
+func (server *analysisServer) Analyse(
+	request *pb.AnalysisRequest, stream pb.Hercules_AnalyseServer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = status.Errorf(codes.Internal, "failed to analyse %s: %v", request.Repository, r)
+		}
+	}()
+	facts, leaves, err := server.parseRequest(request)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	path, err := filepath.Abs(request.Repository)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer server.lockRepository(path).Unlock()
+	repository, err := git.PlainOpen(path)
+	if err != nil {
+		return status.Errorf(codes.NotFound, "failed to open %s: %v", request.Repository, err)
+	}
+
+	pipeline := hercules.NewPipeline(repository)
+	for _, feature := range request.Features {
+		pipeline.SetFeature(feature)
+	}
+	// OnProgress is invoked from the goroutine which called Run()
+	var sendErr error
+	pipeline.OnProgress = func(step, total int, action string) {
+		if sendErr != nil {
+			return
+		}
+		sendErr = stream.Send(&pb.AnalysisUpdate{Progress: &pb.AnalysisProgress{
+			Step: int32(step), Total: int32(total), Action: action,
+		}})
+	}
+	commits, err := pipeline.Commits(request.FirstParent)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to list the commits: %v", err)
+	}
+	facts[hercules.ConfigPipelineCommits] = commits
+	dryRun, _ := facts[hercules.ConfigPipelineDryRun].(bool)
+	deployed := deployLeaves(pipeline, leaves, dryRun)
+	if err = pipeline.Initialize(facts); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	commits = facts[hercules.ConfigPipelineCommits].([]*object.Commit)
+	results, err := pipeline.Run(commits)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to run the pipeline: %v", err)
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	message, err := newResultsMessage(request.Repository, deployed, results)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to serialize the results: %v", err)
+	}
+	return stream.Send(&pb.AnalysisUpdate{Results: message})
+}
+
+// parseRequest converts the request's leaves and facts to the pipeline facts the same way
+// as the command line flags are parsed. It returns the facts and the sorted names of the leaves.
+func (server *analysisServer) parseRequest(request *pb.AnalysisRequest) (
+	map[string]interface{}, []string, error) {
+	features := hercules.Registry.GetFeaturedItems()
+	for _, feature := range request.Features {
+		if _, exists := features[feature]; !exists {
+			return nil, nil, fmt.Errorf("feature \"%s\" is not registered", feature)
+		}
+	}
+	server.flagsMutex.Lock()
+	defer server.flagsMutex.Unlock()
+	flags := pflag.NewFlagSet("serve", pflag.ContinueOnError)
+	facts, deployed := hercules.Registry.AddFlags(flags)
+	for name, value := range request.Facts {
+		if name == "feature" {
+			// the flag is bound to the global registry state
+			return nil, nil, fmt.Errorf("features must be specified in AnalysisRequest.features")
+		}
+		if err := flags.Set(name, value); err != nil {
+			return nil, nil, fmt.Errorf("invalid value \"%s\" of %s: %v", value, name, err)
+		}
+	}
+	for _, flag := range request.Leaves {
+		if err := flags.Set(flag, "true"); err != nil {
+			return nil, nil, fmt.Errorf("unknown analysis %s: %v", flag, err)
+		}
+	}
+	var leaves []string
+	for name, valPtr := range deployed {
+		if *valPtr {
+			leaves = append(leaves, name)
+		}
+	}
+	if len(leaves) == 0 {
+		return nil, nil, fmt.Errorf("no analyses were requested")
+	}
+	sort.Strings(leaves)
+	return facts, leaves, nil
+}
+
+// lockRepository acquires the lock of the repository at the specified absolute path.
+func (server *analysisServer) lockRepository(path string) *sync.Mutex {
+	server.repositoriesMutex.Lock()
+	lock, exists := server.repositories[path]
+	if !exists {
+		lock = &sync.Mutex{}
+		server.repositories[path] = lock
+	}
+	server.repositoriesMutex.Unlock()
+	lock.Lock()
+	return lock
+}
+
+func init() {
+	// set the default usage function before the command inherits formatUsage() from rootCmd
+	serveCmd.SetUsageFunc(serveCmd.UsageFunc())
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("listen", "localhost:50051", "The address to listen on.")
+}
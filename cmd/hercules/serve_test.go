@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/src-d/hercules.v8"
+	"gopkg.in/src-d/hercules.v8/internal/pb"
+)
+
+func startTestServer(t *testing.T) (pb.HerculesClient, func()) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	pb.RegisterHerculesServer(grpcServer, newAnalysisServer())
+	go grpcServer.Serve(listener)
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pb.NewHerculesClient(conn), func() {
+		conn.Close()
+		grpcServer.Stop()
+	}
+}
+
+// analyse returns the number of progress updates and the results.
+func analyse(client pb.HerculesClient, request *pb.AnalysisRequest) (
+	int, *pb.AnalysisResults, error) {
+	stream, err := client.Analyse(context.Background(), request)
+	if err != nil {
+		return 0, nil, err
+	}
+	progress := 0
+	var results *pb.AnalysisResults
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			return progress, results, nil
+		}
+		if err != nil {
+			return progress, nil, err
+		}
+		if update.Progress != nil {
+			progress++
+			if int(update.Progress.Step) != progress {
+				return progress, nil, io.ErrUnexpectedEOF
+			}
+		}
+		if update.Results != nil {
+			results = update.Results
+		}
+	}
+}
+
+func TestServeAnalyse(t *testing.T) {
+	client, stop := startTestServer(t)
+	defer stop()
+	repository, err := filepath.Abs(filepath.Join("..", ".."))
+	assert.Nil(t, err)
+	request := &pb.AnalysisRequest{
+		Repository:  repository,
+		Leaves:      []string{"file-history", "devs"},
+		Facts:       map[string]string{"workers": "2"},
+		FirstParent: true,
+	}
+	var wg sync.WaitGroup
+	var progress [2]int
+	var results [2]*pb.AnalysisResults
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			progress[i], results[i], err = analyse(client, request)
+			assert.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+	var histories [2]pb.FileHistoryResultMessage
+	for i, result := range results {
+		if !assert.NotNil(t, result) {
+			return
+		}
+		assert.True(t, progress[i] > 0)
+		assert.Equal(t, repository, result.Header.Repository)
+		assert.Equal(t, hercules.BinaryGitHash, result.Header.Hash)
+		assert.True(t, result.Header.Commits > 0)
+		assert.Len(t, result.Contents, 2)
+		assert.Nil(t, proto.Unmarshal(result.Contents["FileHistory"], &histories[i]))
+		assert.True(t, len(histories[i].Files) > 0)
+		devs := pb.DevsAnalysisResults{}
+		assert.Nil(t, proto.Unmarshal(result.Contents["Devs"], &devs))
+		assert.True(t, len(devs.DevIndex) > 0)
+	}
+	assert.Equal(t, histories[0], histories[1])
+}
+
+func TestServeAnalyseErrors(t *testing.T) {
+	client, stop := startTestServer(t)
+	defer stop()
+	repository, err := filepath.Abs(filepath.Join("..", ".."))
+	assert.Nil(t, err)
+	requests := map[codes.Code]*pb.AnalysisRequest{
+		codes.InvalidArgument: {Repository: repository, Leaves: []string{"xxx"}},
+		codes.NotFound:        {Repository: "/xxx", Leaves: []string{"file-history"}},
+	}
+	for code, request := range requests {
+		_, _, err := analyse(client, request)
+		assert.Equal(t, code, status.Code(err))
+	}
+	invalid := []*pb.AnalysisRequest{
+		{Repository: repository},
+		{Repository: repository, Leaves: []string{"devs"},
+			Facts: map[string]string{"workers": "many"}},
+		{Repository: repository, Leaves: []string{"devs"},
+			Facts: map[string]string{"feature": "uast"}},
+		{Repository: repository, Leaves: []string{"devs"}, Features: []string{"xxx"}},
+	}
+	for _, request := range invalid {
+		_, _, err := analyse(client, request)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
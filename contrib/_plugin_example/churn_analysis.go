@@ -5,12 +5,9 @@ import (
 	"io"
 	"sort"
 	"strings"
-	"unicode/utf8"
 
 	"github.com/gogo/protobuf/proto"
-	"github.com/sergi/go-diff/diffmatchpatch"
 	"gopkg.in/src-d/go-git.v4"
-	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
 	"gopkg.in/src-d/hercules.v8"
@@ -65,16 +62,14 @@ func (churn *ChurnAnalysis) Provides() []string {
 }
 
 // Requires returns the list of dependencies which must be supplied in Consume().
-// file_diff - line diff for each commit change
+// line_stats - numbers of added, removed and changed lines for each commit change
 // changes - list of changed files for each commit
-// blob_cache - set of blobs affected by each commit
 // day - number of days since start for each commit
 // author - author of the commit
 func (churn *ChurnAnalysis) Requires() []string {
 	arr := [...]string{
-		hercules.DependencyFileDiff,
+		hercules.DependencyLineStats,
 		hercules.DependencyTreeChanges,
-		hercules.DependencyBlobCache,
 		hercules.DependencyDay,
 		hercules.DependencyAuthor}
 	return arr[:]
@@ -126,9 +121,8 @@ func (churn *ChurnAnalysis) Consume(deps map[string]interface{}) (map[string]int
 	if !churn.ShouldConsumeCommit(deps) {
 		return nil, nil
 	}
-	fileDiffs := deps[hercules.DependencyFileDiff].(map[string]hercules.FileDiffData)
+	lineStats := deps[hercules.DependencyLineStats].(map[object.ChangeEntry]hercules.FileLineStats)
 	treeDiffs := deps[hercules.DependencyTreeChanges].(object.Changes)
-	cache := deps[hercules.DependencyBlobCache].(map[plumbing.Hash]*hercules.CachedBlob)
 	day := deps[hercules.DependencyDay].(int)
 	author := deps[hercules.DependencyAuthor].(int)
 	for _, change := range treeDiffs {
@@ -136,32 +130,17 @@ func (churn *ChurnAnalysis) Consume(deps map[string]interface{}) (map[string]int
 		if err != nil {
 			return nil, err
 		}
-		added := 0
-		removed := 0
-		switch action {
-		case merkletrie.Insert:
-			added, _ = cache[change.To.TreeEntry.Hash].CountLines()
-		case merkletrie.Delete:
-			removed, _ = cache[change.From.TreeEntry.Hash].CountLines()
-		case merkletrie.Modify:
-			diffs := fileDiffs[change.To.Name]
-			for _, edit := range diffs.Diffs {
-				length := utf8.RuneCountInString(edit.Text)
-				switch edit.Type {
-				case diffmatchpatch.DiffEqual:
-					continue
-				case diffmatchpatch.DiffInsert:
-					added += length
-				case diffmatchpatch.DiffDelete:
-					removed += length
-				}
-			}
-
+		entry := change.To
+		if action == merkletrie.Delete {
+			entry = change.From
 		}
-		if err != nil {
-			return nil, err
+		// changed lines are both inserted and removed
+		stats := lineStats[entry]
+		ei := editInfo{
+			Day:     day,
+			Added:   stats.Added + stats.Changed,
+			Removed: stats.Removed + stats.Changed,
 		}
-		ei := editInfo{Day: day, Added: added, Removed: removed}
 		churn.global = append(churn.global, ei)
 		if churn.TrackPeople {
 			seq, exists := churn.people[author]
@@ -45,6 +45,13 @@ type LeafPipelineItem = core.LeafPipelineItem
 // ResultMergeablePipelineItem specifies the methods to combine several analysis results together.
 type ResultMergeablePipelineItem = core.ResultMergeablePipelineItem
 
+// JSONSerializablePipelineItem specifies the method to write the analysis result in JSON format.
+type JSONSerializablePipelineItem = core.JSONSerializablePipelineItem
+
+// CheckpointablePipelineItem specifies the methods to save and restore the complete state of
+// an item, so that the analysis can be resumed after the last analysed commit.
+type CheckpointablePipelineItem = core.CheckpointablePipelineItem
+
 // CommonAnalysisResult holds the information which is always extracted at Pipeline.Run().
 type CommonAnalysisResult = core.CommonAnalysisResult
 
@@ -78,13 +85,33 @@ const (
 	// ConfigPipelineCommits is the name of the Pipeline configuration option (Pipeline.Initialize())
 	// which allows to specify the custom commit sequence. By default, Pipeline.Commits() is used.
 	ConfigPipelineCommits = core.ConfigPipelineCommits
+	// ConfigPipelineCheckpointPath is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which enables saving the state of the items after the last analysed commit to the specified file.
+	ConfigPipelineCheckpointPath = core.ConfigPipelineCheckpointPath
+	// ConfigPipelineResumeFrom is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which loads the state of the items from the specified checkpoint file.
+	ConfigPipelineResumeFrom = core.ConfigPipelineResumeFrom
+	// ConfigPipelineWorkers is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which sets the number of goroutines to execute the items concurrently.
+	ConfigPipelineWorkers = core.ConfigPipelineWorkers
+	// ConfigPipelinePartialHistory is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which indicates that the parents of some analysed commits are not analysed.
+	ConfigPipelinePartialHistory = core.ConfigPipelinePartialHistory
 )
 
+// RevisionRange selects the part of the history to analyse, see Pipeline.CommitsInRange().
+type RevisionRange = core.RevisionRange
+
 // NewPipeline initializes a new instance of Pipeline struct.
 func NewPipeline(repository *git.Repository) *Pipeline {
 	return core.NewPipeline(repository)
 }
 
+// ParseRevisionRange converts "B" or "A..B" to RevisionRange.
+func ParseRevisionRange(spec string) RevisionRange {
+	return core.ParseRevisionRange(spec)
+}
+
 // LoadCommitsFromFile reads the file by the specified FS path and generates the sequence of commits
 // by interpreting each line as a Git commit hash.
 func LoadCommitsFromFile(path string, repository *git.Repository) ([]*object.Commit, error) {
@@ -120,6 +147,9 @@ const (
 	DependencyIsMerge = core.DependencyIsMerge
 	// DependencyAuthor is the name of the dependency provided by identity.Detector.
 	DependencyAuthor = identity.DependencyAuthor
+	// DependencyAuthors is the name of the dependency provided by identity.Detector: all the authors
+	// of the commit including those in the trailers.
+	DependencyAuthors = identity.DependencyAuthors
 	// DependencyBlobCache identifies the dependency provided by BlobCache.
 	DependencyBlobCache = plumbing.DependencyBlobCache
 	// DependencyDay is the name of the dependency which DaysSinceStart provides - the number
@@ -127,14 +157,25 @@ const (
 	DependencyDay = plumbing.DependencyDay
 	// DependencyFileDiff is the name of the dependency provided by FileDiff.
 	DependencyFileDiff = plumbing.DependencyFileDiff
+	// DependencyLineStats is the name of the dependency provided by LinesStatsCalculator:
+	// the numbers of added, removed and changed lines in each changed file.
+	DependencyLineStats = plumbing.DependencyLineStats
 	// DependencyTreeChanges is the name of the dependency provided by TreeDiff.
 	DependencyTreeChanges = plumbing.DependencyTreeChanges
+	// DependencyBaseTreeChanges is the name of the dependency provided by TreeDiff - the files
+	// which existed before the first analysed commit.
+	DependencyBaseTreeChanges = plumbing.DependencyBaseTreeChanges
 	// DependencyUastChanges is the name of the dependency provided by Changes.
 	DependencyUastChanges = uast.DependencyUastChanges
 	// DependencyUasts is the name of the dependency provided by Extractor.
 	DependencyUasts = uast.DependencyUasts
 	// FactCommitsByDay contains the mapping between day indices and the corresponding commits.
 	FactCommitsByDay = plumbing.FactCommitsByDay
+	// FactTickSize contains the duration of the time tick which DaysSinceStart uses
+	// to calculate DependencyDay.
+	FactTickSize = plumbing.FactTickSize
+	// DefaultTickSize is the duration of the time tick unless --tick-size is specified.
+	DefaultTickSize = plumbing.DefaultTickSize
 	// FactIdentityDetectorPeopleCount is the name of the fact which is inserted in
 	// identity.Detector.Configure(). It is equal to the overall number of unique authors
 	// (the length of ReversedPeopleDict).
@@ -147,11 +188,23 @@ const (
 	// identity.Detector.Configure(). It corresponds to identity.Detector.ReversedPeopleDict -
 	// the mapping from the author indices to the main signature.
 	FactIdentityDetectorReversedPeopleDict = identity.FactIdentityDetectorReversedPeopleDict
+	// ConfigIdentityDetectorPeopleDictPath is the name of the configuration option
+	// (identity.Detector.Configure()) which sets the path to the people dictionary.
+	ConfigIdentityDetectorPeopleDictPath = identity.ConfigIdentityDetectorPeopleDictPath
+	// AuthorMissing is the author index which corresponds to the unidentified developers.
+	AuthorMissing = identity.AuthorMissing
 )
 
 // FileDiffData is the type of the dependency provided by plumbing.FileDiff.
 type FileDiffData = plumbing.FileDiffData
 
+// LineStats holds the numbers of inserted, deleted and changed lines.
+type LineStats = plumbing.LineStats
+
+// FileLineStats is the type of the values of the dependency provided by
+// plumbing.LinesStatsCalculator: map[object.ChangeEntry]FileLineStats.
+type FileLineStats = plumbing.FileLineStats
+
 // CachedBlob allows to explicitly cache the binary data associated with the Blob object.
 // Such structs are returned by DependencyBlobCache.
 type CachedBlob = plumbing.CachedBlob
@@ -280,6 +280,38 @@ func (file *File) Update(time int, pos int, insLength int, delLength int) {
 	}
 }
 
+// Inherit inserts the lines which keep the value of their origin, e.g. the lines moved
+// from another place. The updaters are not called because the lines are not new.
+//
+// origin is the value of the inserted nodes.
+//
+// pos is the index of the line at which the lines are inserted.
+//
+// length is the number of inserted lines.
+func (file *File) Inherit(origin int, pos int, length int) {
+	file.updateSilently(origin, pos, length, 0)
+}
+
+// Withdraw deletes the lines which were moved to another place. The updaters are not called
+// because the lines continue to exist.
+//
+// pos is the index of the first deleted line.
+//
+// length is the number of deleted lines.
+func (file *File) Withdraw(pos int, length int) {
+	file.updateSilently(0, pos, 0, length)
+}
+
+// updateSilently calls Update() with the detached updaters.
+func (file *File) updateSilently(time int, pos int, insLength int, delLength int) {
+	updaters := file.updaters
+	file.updaters = nil
+	defer func() {
+		file.updaters = updaters
+	}()
+	file.Update(time, pos, insLength, delLength)
+}
+
 // Merge combines several prepared File-s together.
 func (file *File) Merge(day int, others ...*File) {
 	myself := file.flatten()
@@ -364,6 +396,11 @@ func (file File) Validate() {
 	}
 }
 
+// Get returns the value of the line at the specified position, which must be less than Len().
+func (file File) Get(pos int) int {
+	return int(file.tree.FindLE(uint32(pos)).Item().Value)
+}
+
 // ForEach visits each node in the underlying tree, in ascending key order.
 func (file File) ForEach(callback func(line, value int)) {
 	for iter := file.tree.Min(); !iter.Limit(); iter = iter.Next() {
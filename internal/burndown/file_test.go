@@ -164,6 +164,28 @@ func TestInsertFile(t *testing.T) {
 	assert.Equal(t, int64(10), status[1])
 }
 
+func TestInheritWithdrawFile(t *testing.T) {
+	file, status, _ := fixtureFile()
+	file.Update(1, 10, 10, 0)
+	// 0 0 | 10 1 | 20 0 | 110 -1               [0]: 100, [1]: 10
+	file.Inherit(1, 50, 5)
+	// 0 0 | 10 1 | 20 0 | 50 1 | 55 0 | 115 -1 [0]: 100, [1]: 10
+	assert.Equal(t, "0 0\n10 1\n20 0\n50 1\n55 0\n115 -1\n", file.Dump())
+	assert.Equal(t, int64(100), status[0])
+	assert.Equal(t, int64(10), status[1])
+	file.Withdraw(10, 10)
+	// 0 0 | 40 1 | 45 0 | 105 -1               [0]: 100, [1]: 10
+	assert.Equal(t, "0 0\n40 1\n45 0\n105 -1\n", file.Dump())
+	assert.Equal(t, int64(100), status[0])
+	assert.Equal(t, int64(10), status[1])
+	file.Inherit(0, 0, 5)
+	// 0 0 | 45 1 | 50 0 | 110 -1               [0]: 100, [1]: 10
+	assert.Equal(t, "0 0\n45 1\n50 0\n110 -1\n", file.Dump())
+	file.Update(2, 0, 0, 110)
+	assert.Equal(t, int64(-5), status[0])
+	assert.Equal(t, int64(5), status[1])
+}
+
 func TestZeroInitializeFile(t *testing.T) {
 	status := map[int]int64{}
 	file := NewFile(0, 0, rbtree.NewAllocator(), func(a, b, c int) {
@@ -527,6 +549,16 @@ func TestBug5File(t *testing.T) {
 	assert.Equal(t, "0 0\n14 157\n16 -1\n", dump)
 }
 
+func TestFileGet(t *testing.T) {
+	keys := []int{0, 2, 4, 7, 10}
+	vals := []int{24, 28, 24, 28, math.MaxUint32}
+	file := NewFileFromTree(keys, vals, rbtree.NewAllocator())
+	expected := []int{24, 24, 28, 28, 24, 24, 24, 28, 28, 28}
+	for i, val := range expected {
+		assert.Equal(t, val, file.Get(i), i)
+	}
+}
+
 func TestNewFileFromTreeInvalidSize(t *testing.T) {
 	keys := [...]int{1, 2, 3}
 	vals := [...]int{4, 5}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// checkpointFormatVersion is incremented each time the layout of pipelineCheckpoint changes.
+const checkpointFormatVersion = 1
+
+// pipelineCheckpoint is the state of a Pipeline after the last analysed commit.
+// It is written by Pipeline.Run() and read by Pipeline.Initialize().
+type pipelineCheckpoint struct {
+	// Version is the format version, see checkpointFormatVersion.
+	Version int
+	// Head is the hash of the last analysed commit in the master branch.
+	Head plumbing.Hash
+	// BeginTime is the time of the first commit in the whole analysed sequence.
+	BeginTime int64
+	// EndTime is the time of the last commit in the whole analysed sequence.
+	EndTime int64
+	// CommitsNumber is the overall number of analysed commits.
+	CommitsNumber int
+	// Items maps the names of the CheckpointablePipelineItem-s to their serialized states.
+	Items map[string][]byte
+}
+
+// UncheckpointableItems returns the sorted names of the items in the pipeline which do not
+// implement CheckpointablePipelineItem. Pipeline.Initialize() refuses to save or to resume
+// a checkpoint if there are any.
+func (pipeline *Pipeline) UncheckpointableItems() []string {
+	var names []string
+	for _, item := range pipeline.items {
+		if _, ok := item.(CheckpointablePipelineItem); !ok {
+			names = append(names, item.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkCheckpointable returns an error which lists the items which do not support checkpoints.
+func (pipeline *Pipeline) checkCheckpointable() error {
+	if names := pipeline.UncheckpointableItems(); len(names) > 0 {
+		return fmt.Errorf("the following items cannot be checkpointed: %s",
+			strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// loadCheckpoint reads the pipeline checkpoint from the specified file.
+func loadCheckpoint(path string) (*pipelineCheckpoint, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	state := &pipelineCheckpoint{}
+	err = gob.NewDecoder(file).Decode(state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the checkpoint %s", path)
+	}
+	if state.Version != checkpointFormatVersion {
+		return nil, fmt.Errorf("unsupported checkpoint format version %d in %s (expected %d)",
+			state.Version, path, checkpointFormatVersion)
+	}
+	return state, nil
+}
+
+// saveCheckpoint writes the states of the items after `head` to the specified file.
+// `result` carries the statistics of the current run and is merged with the statistics of
+// the checkpoint which we have resumed from, if any.
+func (pipeline *Pipeline) saveCheckpoint(
+	path string, head *object.Commit, items []PipelineItem, result *CommonAnalysisResult) error {
+	state := pipelineCheckpoint{
+		Version:       checkpointFormatVersion,
+		Head:          head.Hash,
+		BeginTime:     result.BeginTime,
+		EndTime:       result.EndTime,
+		CommitsNumber: result.CommitsNumber,
+		Items:         map[string][]byte{},
+	}
+	for _, item := range items {
+		buffer := &bytes.Buffer{}
+		err := item.(CheckpointablePipelineItem).Checkpoint(buffer)
+		if err != nil {
+			return errors.Wrapf(err, "%s failed to checkpoint", item.Name())
+		}
+		if _, exists := state.Items[item.Name()]; exists {
+			return fmt.Errorf("cannot checkpoint two items with the same name %s", item.Name())
+		}
+		state.Items[item.Name()] = buffer.Bytes()
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = gob.NewEncoder(file).Encode(&state)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// restoreItems configures, initializes and restores each item from the loaded checkpoint.
+// The items are processed one by one in the topological order so that the facts updated
+// in Restore() propagate to the dependent items.
+func (pipeline *Pipeline) restoreItems(facts map[string]interface{}) error {
+	for _, item := range pipeline.items {
+		err := item.Configure(facts)
+		if err != nil {
+			return errors.Wrapf(err, "%s failed to configure", item.Name())
+		}
+		err = item.Initialize(pipeline.repository)
+		if err != nil {
+			return errors.Wrapf(err, "%s failed to initialize", item.Name())
+		}
+		data, exists := pipeline.checkpoint.Items[item.Name()]
+		if !exists {
+			return fmt.Errorf("%s does not exist in the checkpoint", item.Name())
+		}
+		err = item.(CheckpointablePipelineItem).Restore(bytes.NewReader(data), facts)
+		if err != nil {
+			return errors.Wrapf(err, "%s failed to restore from the checkpoint", item.Name())
+		}
+	}
+	return nil
+}
+
+// selectDescendants leaves only those commits which descend from `head`, preserving the order.
+func selectDescendants(commits []*object.Commit, head plumbing.Hash) ([]*object.Commit, error) {
+	children := map[plumbing.Hash][]plumbing.Hash{}
+	found := false
+	for _, commit := range commits {
+		if commit.Hash == head {
+			found = true
+		}
+		for _, parent := range commit.ParentHashes {
+			children[parent] = append(children[parent], commit.Hash)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("the checkpoint head %s does not belong to the analysed history",
+			head.String())
+	}
+	descendants := map[plumbing.Hash]bool{}
+	for queue := children[head]; len(queue) > 0; {
+		hash := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if descendants[hash] {
+			continue
+		}
+		descendants[hash] = true
+		queue = append(queue, children[hash]...)
+	}
+	result := make([]*object.Commit, 0, len(descendants))
+	for _, commit := range commits {
+		if descendants[commit.Hash] {
+			result = append(result, commit)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+)
+
+type checkpointTestPipelineItem struct {
+	NoopMerger
+	Commits  int64
+	Restored bool
+}
+
+func (item *checkpointTestPipelineItem) Name() string {
+	return "CheckpointTest"
+}
+
+func (item *checkpointTestPipelineItem) Provides() []string {
+	return []string{}
+}
+
+func (item *checkpointTestPipelineItem) Requires() []string {
+	return []string{}
+}
+
+func (item *checkpointTestPipelineItem) ListConfigurationOptions() []ConfigurationOption {
+	return []ConfigurationOption{}
+}
+
+func (item *checkpointTestPipelineItem) Configure(facts map[string]interface{}) error {
+	return nil
+}
+
+func (item *checkpointTestPipelineItem) Initialize(repository *git.Repository) error {
+	item.Commits = 0
+	return nil
+}
+
+func (item *checkpointTestPipelineItem) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	item.Commits++
+	return nil, nil
+}
+
+func (item *checkpointTestPipelineItem) Fork(n int) []PipelineItem {
+	return ForkSamePipelineItem(item, n)
+}
+
+func (item *checkpointTestPipelineItem) Checkpoint(writer io.Writer) error {
+	return binary.Write(writer, binary.LittleEndian, item.Commits)
+}
+
+func (item *checkpointTestPipelineItem) Restore(reader io.Reader, facts map[string]interface{}) error {
+	item.Restored = true
+	facts["CheckpointTest.Restored"] = true
+	return binary.Read(reader, binary.LittleEndian, &item.Commits)
+}
+
+func TestPipelineCheckpointUncheckpointable(t *testing.T) {
+	pipeline := NewPipeline(test.Repository)
+	pipeline.AddItem(&testPipelineItem{})
+	pipeline.AddItem(&checkpointTestPipelineItem{})
+	assert.Equal(t, []string{"Test"}, pipeline.UncheckpointableItems())
+	err := pipeline.Initialize(map[string]interface{}{
+		ConfigPipelineCheckpointPath: "/tmp/hercules-checkpoint-test",
+	})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Test")
+	assert.NotContains(t, err.Error(), "CheckpointTest")
+}
+
+func TestPipelineCheckpointResume(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "hercules-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpdir)
+	path := filepath.Join(tmpdir, "checkpoint.bin")
+
+	pipeline := NewPipeline(test.Repository)
+	commits, err := pipeline.Commits(true)
+	assert.Nil(t, err)
+	assert.True(t, len(commits) > 20)
+	item := &checkpointTestPipelineItem{}
+	pipeline.AddItem(item)
+	assert.Nil(t, pipeline.Initialize(map[string]interface{}{
+		ConfigPipelineCommits:        commits[:10],
+		ConfigPipelineCheckpointPath: path,
+	}))
+	result, err := pipeline.Run(commits[:10])
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), item.Commits)
+	firstCommon := result[nil].(*CommonAnalysisResult)
+
+	pipeline = NewPipeline(test.Repository)
+	item = &checkpointTestPipelineItem{}
+	pipeline.AddItem(item)
+	facts := map[string]interface{}{
+		ConfigPipelineCommits:    commits,
+		ConfigPipelineResumeFrom: path,
+	}
+	assert.Nil(t, pipeline.Initialize(facts))
+	assert.True(t, item.Restored)
+	assert.Equal(t, true, facts["CheckpointTest.Restored"])
+	assert.Equal(t, int64(10), item.Commits)
+	newCommits := facts[ConfigPipelineCommits].([]*object.Commit)
+	assert.Equal(t, commits[10:], newCommits)
+	result, err = pipeline.Run(newCommits)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(commits)), item.Commits)
+	common := result[nil].(*CommonAnalysisResult)
+	assert.Equal(t, firstCommon.BeginTime, common.BeginTime)
+	assert.Equal(t, len(commits), common.CommitsNumber)
+	assert.Equal(t, commits[len(commits)-1].Committer.When.Unix(), common.EndTime)
+
+	pipeline = NewPipeline(test.Repository)
+	pipeline.AddItem(&checkpointTestPipelineItem{})
+	err = pipeline.Initialize(map[string]interface{}{
+		ConfigPipelineCommits:    commits[:5],
+		ConfigPipelineResumeFrom: path,
+	})
+	assert.NotNil(t, err)
+	err = pipeline.Initialize(map[string]interface{}{
+		ConfigPipelineCommits:    commits[:10],
+		ConfigPipelineResumeFrom: path,
+	})
+	assert.NotNil(t, err)
+	err = pipeline.Initialize(map[string]interface{}{
+		ConfigPipelineCommits:    commits,
+		ConfigPipelineResumeFrom: filepath.Join(tmpdir, "missing.bin"),
+	})
+	assert.NotNil(t, err)
+}
+
+func TestSelectDescendants(t *testing.T) {
+	hashes := []string{
+		"cce947b98a050c6d356bc6ba95030254914027b1",
+		"a28e9064c70618dc9d68e1401b889975e0680d11",
+		"af9ddc0db70f09f3f27b4b98e415592a7485171c",
+	}
+	var commits []*object.Commit
+	for _, h := range hashes {
+		commit, err := test.Repository.CommitObject(plumbing.NewHash(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+		commits = append(commits, commit)
+	}
+	result, err := selectDescendants(commits, commits[0].Hash)
+	assert.Nil(t, err)
+	assert.Equal(t, commits[1:], result)
+	result, err = selectDescendants(commits, commits[2].Hash)
+	assert.Nil(t, err)
+	assert.Len(t, result, 0)
+	_, err = selectDescendants(commits, plumbing.ZeroHash)
+	assert.NotNil(t, err)
+}
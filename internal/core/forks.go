@@ -119,6 +119,42 @@ func (ra runAction) String() string {
 	return ""
 }
 
+// isMergeStep returns the value of DependencyIsMerge for the specified runActionCommit.
+// A merge commit is executed in each of the merged branches and thus it occurs in the plan
+// several times in a row.
+func isMergeStep(plan []runAction, index int) bool {
+	commit := plan[index].Commit.Hash
+	match := false
+	// look for the same hash backward
+	for i := index - 1; i > 0; i-- {
+		switch plan[i].Action {
+		case runActionHibernate, runActionBoot:
+			continue
+		case runActionCommit:
+			match = plan[i].Commit.Hash == commit
+			fallthrough
+		default:
+			i = 0
+		}
+	}
+	if match {
+		return true
+	}
+	// look for the same hash forward
+	for i := index + 1; i < len(plan); i++ {
+		switch plan[i].Action {
+		case runActionHibernate, runActionBoot:
+			continue
+		case runActionCommit:
+			match = plan[i].Commit.Hash == commit
+			fallthrough
+		default:
+			i = len(plan)
+		}
+	}
+	return match
+}
+
 type orderer = func(reverse, direction bool) []string
 
 func cloneItems(origin []PipelineItem, n int) [][]PipelineItem {
@@ -158,6 +194,31 @@ func getMasterBranch(branches map[int][]PipelineItem) []PipelineItem {
 	return minVal
 }
 
+// getMasterBranchIndex returns the index of the branch which getMasterBranch() is going to choose
+// after the plan is executed.
+func getMasterBranchIndex(plan []runAction) int {
+	branches := map[int]bool{}
+	for _, step := range plan {
+		switch step.Action {
+		case runActionEmerge:
+			branches[step.Items[0]] = true
+		case runActionFork:
+			for _, branch := range step.Items[1:] {
+				branches[branch] = true
+			}
+		case runActionDelete:
+			delete(branches, step.Items[0])
+		}
+	}
+	minKey := 1 << 31
+	for key := range branches {
+		if key < minKey {
+			minKey = key
+		}
+	}
+	return minKey
+}
+
 // prepareRunPlan schedules the actions for Pipeline.Run().
 func prepareRunPlan(commits []*object.Commit, hibernationDistance int,
 	printResult bool) []runAction {
@@ -0,0 +1,308 @@
+package core
+
+import (
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// concurrentStep is the runAction prepared for the concurrent execution in runConcurrently().
+type concurrentStep struct {
+	// branches are the items of the branches which the action involves, in the order of
+	// runAction.Items. The slices of the new branches are filled during the execution.
+	branches [][]PipelineItem
+	// source is the list of items to clone in runActionEmerge, nil if nothing should be cloned.
+	source []PipelineItem
+	// state is the `deps` of runActionCommit which grows as the items finish Consume().
+	state map[string]interface{}
+	// done is the number of lanes which have finished the step.
+	done int
+}
+
+// runConcurrently executes the plan on up to pipeline.Workers goroutines.
+//
+// The items at the same position in different branches are not independent in general:
+// ForkSamePipelineItem() returns the same object and ForkCopyPipelineItem() shares the nested
+// maps and slices. Therefore each position forms a "lane" which executes all the plan steps
+// strictly in order, that is, every item receives exactly the same sequence of calls as in
+// runSequentially(). The lanes run concurrently and synchronize only on the dependencies
+// between the items within the same commit. Thus the commits in different branches overlap
+// as well as the consecutive commits in the same branch, and the results do not depend on
+// the number of workers. At most pipeline.Workers steps are in progress at the same time
+// to limit the memory consumption.
+func (pipeline *Pipeline) runConcurrently(
+	plan []runAction, branches map[int][]PipelineItem, rootClone []PipelineItem,
+	runTimePerItem map[string]float64, onProgress func(int, int, string), progressSteps int) error {
+	lanes := len(pipeline.items)
+	steps := make([]concurrentStep, len(plan))
+	commitIndex := 0
+	for index, step := range plan {
+		cs := &steps[index]
+		firstItem := step.Items[0]
+		switch step.Action {
+		case runActionCommit:
+			cs.branches = [][]PipelineItem{branches[firstItem]}
+			cs.state = map[string]interface{}{
+				DependencyCommit:  step.Commit,
+				DependencyIndex:   commitIndex,
+				DependencyIsMerge: isMergeStep(plan, index),
+			}
+			commitIndex++
+		case runActionFork:
+			cs.branches = [][]PipelineItem{branches[firstItem]}
+			for _, branch := range step.Items[1:] {
+				branches[branch] = make([]PipelineItem, lanes)
+				cs.branches = append(cs.branches, branches[branch])
+			}
+		case runActionEmerge:
+			if firstItem == rootBranchIndex {
+				branches[firstItem] = pipeline.items
+			} else {
+				branches[firstItem] = make([]PipelineItem, lanes)
+				cs.branches = [][]PipelineItem{branches[firstItem]}
+				cs.source = rootClone
+			}
+		case runActionDelete:
+			delete(branches, firstItem)
+		case runActionMerge, runActionHibernate, runActionBoot:
+			for _, branch := range step.Items {
+				cs.branches = append(cs.branches, branches[branch])
+			}
+		}
+	}
+	// producers[i] are the lanes which provide the dependencies of the i-th lane
+	producers := make([][]int, lanes)
+	providers := map[string]int{}
+	for i, item := range pipeline.items {
+		for _, dep := range item.Requires() {
+			if j, exists := providers[dep]; exists {
+				producers[i] = append(producers[i], j)
+			}
+		}
+		for _, key := range item.Provides() {
+			providers[key] = i
+		}
+	}
+
+	var mutex sync.Mutex
+	cond := sync.NewCond(&mutex)
+	// laneProgress[i] is the number of steps finished by the i-th lane
+	laneProgress := make([]int, lanes)
+	// completed is the number of steps finished by all the lanes
+	completed := 0
+	// the state of a completed step is released after it is reported
+	advance := func() {
+		for completed < len(steps) && steps[completed].done == lanes {
+			completed++
+		}
+	}
+	ready := func(lane, index int) bool {
+		if index >= completed+pipeline.Workers {
+			return false
+		}
+		if plan[index].Action == runActionCommit {
+			for _, producer := range producers[lane] {
+				if laneProgress[producer] <= index {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	aborted := false
+	var failure error
+	failureIndex := len(plan)
+	var failurePanic interface{}
+	semaphore := make(chan struct{}, pipeline.Workers)
+	laneRunTimes := make([]map[string]float64, lanes)
+	wg := sync.WaitGroup{}
+
+	runLane := func(lane int) {
+		defer wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				mutex.Lock()
+				if failurePanic == nil {
+					failurePanic = r
+				}
+				aborted = true
+				cond.Broadcast()
+				mutex.Unlock()
+			}
+		}()
+		runTime := map[string]float64{}
+		laneRunTimes[lane] = runTime
+		for index, step := range plan {
+			mutex.Lock()
+			for !aborted && !ready(lane, index) {
+				cond.Wait()
+			}
+			if aborted {
+				mutex.Unlock()
+				return
+			}
+			var deps map[string]interface{}
+			if state := steps[index].state; state != nil {
+				deps = make(map[string]interface{}, len(state))
+				for key, val := range state {
+					deps[key] = val
+				}
+			}
+			mutex.Unlock()
+			var update map[string]interface{}
+			var err error
+			func() {
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+				update, err = runConcurrentStep(step, &steps[index], lane, deps, runTime)
+			}()
+			if err != nil {
+				log.Printf("%s failed on commit #%d (%d) %s\n",
+					steps[index].branches[0][lane].Name(), deps[DependencyIndex].(int)+1,
+					index+1, step.Commit.Hash.String())
+			}
+			mutex.Lock()
+			if err != nil {
+				if index < failureIndex {
+					failure = err
+					failureIndex = index
+				}
+				aborted = true
+				cond.Broadcast()
+				mutex.Unlock()
+				return
+			}
+			for key, val := range update {
+				steps[index].state[key] = val
+			}
+			laneProgress[lane]++
+			steps[index].done++
+			advance()
+			cond.Broadcast()
+			mutex.Unlock()
+		}
+	}
+
+	for lane := 0; lane < lanes; lane++ {
+		wg.Add(1)
+		go runLane(lane)
+	}
+	masterIndex := getMasterBranchIndex(plan)
+	reported := 0
+	mutex.Lock()
+	advance()
+	for {
+		for reported < completed {
+			index := reported
+			reported++
+			state := steps[index].state
+			steps[index].state = nil
+			mutex.Unlock()
+			onProgress(index+1, progressSteps, plan[index].String())
+			if pipeline.PrintActions {
+				printAction(plan[index])
+			}
+			if state != nil && plan[index].Items[0] == masterIndex {
+				pipeline.notifyCommit(plan[index].Commit, state)
+			}
+			if index > 0 && index%100 == 0 && pipeline.HibernationDistance > 0 {
+				debug.FreeOSMemory()
+			}
+			mutex.Lock()
+		}
+		if aborted || reported == len(plan) {
+			break
+		}
+		cond.Wait()
+	}
+	mutex.Unlock()
+	wg.Wait()
+	for _, runTime := range laneRunTimes {
+		for key, val := range runTime {
+			runTimePerItem[key] += val
+		}
+	}
+	if failurePanic != nil {
+		panic(failurePanic)
+	}
+	return failure
+}
+
+// reloadCommits reads the commits from the pipeline's repository again so that their trees
+// and parents are read through the storage locked in Initialize().
+func (pipeline *Pipeline) reloadCommits(commits []*object.Commit) ([]*object.Commit, error) {
+	reloaded := make([]*object.Commit, len(commits))
+	for i, commit := range commits {
+		var err error
+		reloaded[i], err = pipeline.repository.CommitObject(commit.Hash)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read commit %s", commit.Hash.String())
+		}
+	}
+	return reloaded, nil
+}
+
+// runConcurrentStep executes the part of the plan step which belongs to the specified lane.
+// It returns the values provided by the item in case of runActionCommit.
+func runConcurrentStep(action runAction, step *concurrentStep, lane int,
+	deps map[string]interface{}, runTimePerItem map[string]float64) (map[string]interface{}, error) {
+	switch action.Action {
+	case runActionCommit:
+		item := step.branches[0][lane]
+		startTime := time.Now()
+		update, err := item.Consume(deps)
+		runTimePerItem[item.Name()] += time.Now().Sub(startTime).Seconds()
+		if err != nil {
+			return nil, err
+		}
+		provided := map[string]interface{}{}
+		for _, key := range item.Provides() {
+			val, ok := update[key]
+			if !ok {
+				log.Panicf("%s: Consume() did not return %s", item.Name(), key)
+			}
+			provided[key] = val
+		}
+		return provided, nil
+	case runActionFork:
+		startTime := time.Now()
+		for i, clone := range step.branches[0][lane].Fork(len(step.branches) - 1) {
+			step.branches[i+1][lane] = clone
+		}
+		runTimePerItem["*.Fork"] += time.Now().Sub(startTime).Seconds()
+	case runActionMerge:
+		startTime := time.Now()
+		others := make([]PipelineItem, len(step.branches)-1)
+		for i, branch := range step.branches[1:] {
+			others[i] = branch[lane]
+		}
+		step.branches[0][lane].Merge(others)
+		runTimePerItem["*.Merge"] += time.Now().Sub(startTime).Seconds()
+	case runActionEmerge:
+		if step.source != nil {
+			step.branches[0][lane] = step.source[lane].Fork(1)[0]
+		}
+	case runActionHibernate, runActionBoot:
+		for _, branch := range step.branches {
+			item := branch[lane]
+			if hi, ok := item.(HibernateablePipelineItem); ok {
+				startTime := time.Now()
+				var err error
+				if action.Action == runActionHibernate {
+					err = hi.Hibernate()
+				} else {
+					err = hi.Boot()
+				}
+				if err != nil {
+					log.Panicf("Failed to %s %s: %v\n", action.String(), item.Name(), err)
+				}
+				runTimePerItem[item.Name()+".Hibernation"] += time.Now().Sub(startTime).Seconds()
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+)
+
+// journalTestPipelineItem records every call into the journal which is shared between the forks.
+type journalTestPipelineItem struct {
+	Key         string
+	Requirement string
+	Journal     *[]string
+	Commits     int
+}
+
+func (item *journalTestPipelineItem) Name() string {
+	return "Journal" + item.Key
+}
+
+func (item *journalTestPipelineItem) Provides() []string {
+	return []string{item.Key}
+}
+
+func (item *journalTestPipelineItem) Requires() []string {
+	if item.Requirement == "" {
+		return []string{}
+	}
+	return []string{item.Requirement}
+}
+
+func (item *journalTestPipelineItem) ListConfigurationOptions() []ConfigurationOption {
+	return []ConfigurationOption{}
+}
+
+func (item *journalTestPipelineItem) Configure(facts map[string]interface{}) error {
+	return nil
+}
+
+func (item *journalTestPipelineItem) Initialize(repository *git.Repository) error {
+	item.Journal = &[]string{}
+	return nil
+}
+
+func (item *journalTestPipelineItem) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	value := deps[DependencyIndex].(int)
+	if item.Requirement != "" {
+		value += deps[item.Requirement].(int)
+	}
+	item.Commits++
+	*item.Journal = append(*item.Journal, fmt.Sprintf("%s %d %v %d %d",
+		deps[DependencyCommit].(*object.Commit).Hash.String()[:7], deps[DependencyIndex],
+		deps[DependencyIsMerge], value, item.Commits))
+	return map[string]interface{}{item.Key: value}, nil
+}
+
+func (item *journalTestPipelineItem) Fork(n int) []PipelineItem {
+	*item.Journal = append(*item.Journal, fmt.Sprintf("fork %d %d", n, item.Commits))
+	return ForkCopyPipelineItem(item, n)
+}
+
+func (item *journalTestPipelineItem) Merge(branches []PipelineItem) {
+	for _, branch := range branches {
+		item.Commits += branch.(*journalTestPipelineItem).Commits
+	}
+	*item.Journal = append(*item.Journal, fmt.Sprintf("merge %d %d", len(branches), item.Commits))
+}
+
+func (item *journalTestPipelineItem) Hibernate() error {
+	*item.Journal = append(*item.Journal, fmt.Sprintf("hibernate %d", item.Commits))
+	return nil
+}
+
+func (item *journalTestPipelineItem) Boot() error {
+	*item.Journal = append(*item.Journal, fmt.Sprintf("boot %d", item.Commits))
+	return nil
+}
+
+// runJournalPipeline executes the same plan as Pipeline.Run() would, because prepareRunPlan()
+// is not guaranteed to return the same plan each time.
+// The third returned value lists the commits reported to OnCommit() subscribers.
+func runJournalPipeline(t *testing.T, plan []runAction, workers int) (
+	[][]string, map[string]float64, []string) {
+	pipeline := NewPipeline(test.Repository)
+	items := []*journalTestPipelineItem{
+		{Key: "a"},
+		{Key: "b", Requirement: "a"},
+		{Key: "c", Requirement: "a"},
+		{Key: "d", Requirement: "c"},
+	}
+	for _, item := range items {
+		pipeline.AddItem(item)
+	}
+	assert.Nil(t, pipeline.Initialize(map[string]interface{}{
+		ConfigPipelineCommits: []*object.Commit{},
+		ConfigPipelineWorkers: workers,
+	}))
+	assert.Equal(t, workers, pipeline.Workers)
+	var notifications []string
+	pipeline.OnCommit(func(commit *object.Commit, state map[string]interface{}) {
+		assert.Equal(t, commit, state[DependencyCommit])
+		notifications = append(notifications, fmt.Sprintf("%s %d %v %d %d",
+			commit.Hash.String()[:7], state[DependencyIndex], state[DependencyIsMerge],
+			state["b"], state["d"]))
+	})
+	branches := map[int][]PipelineItem{}
+	rootClone := cloneItems(pipeline.items, 1)[0]
+	runTimePerItem := map[string]float64{}
+	progress := 0
+	onProgress := func(step, total int, action string) {
+		assert.Equal(t, progress+1, step)
+		assert.Equal(t, len(plan)+2, total)
+		assert.Equal(t, plan[step-1].String(), action)
+		progress = step
+	}
+	var err error
+	if workers > 1 {
+		err = pipeline.runConcurrently(
+			plan, branches, rootClone, runTimePerItem, onProgress, len(plan)+2)
+	} else {
+		err = pipeline.runSequentially(
+			plan, branches, rootClone, runTimePerItem, onProgress, len(plan)+2)
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, len(plan), progress)
+	journals := make([][]string, len(items))
+	for i, item := range items {
+		journals[i] = *item.Journal
+	}
+	return journals, runTimePerItem, notifications
+}
+
+func TestPipelineRunConcurrently(t *testing.T) {
+	commits, err := NewPipeline(test.Repository).Commits(false)
+	assert.Nil(t, err)
+	plan := prepareRunPlan(commits, 10, false)
+	journals, runTimePerItem, notifications := runJournalPipeline(t, plan, 1)
+	for _, journal := range journals {
+		assert.True(t, len(journal) > len(commits))
+	}
+	assert.True(t, len(notifications) > 0)
+	assert.True(t, len(notifications) <= len(commits))
+	var keys []string
+	for key := range runTimePerItem {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	assert.Contains(t, keys, "*.Fork")
+	assert.Contains(t, keys, "*.Merge")
+	assert.Contains(t, keys, "Journalb")
+	for _, workers := range []int{2, 4, 16} {
+		parallelJournals, parallelRunTimePerItem, parallelNotifications :=
+			runJournalPipeline(t, plan, workers)
+		assert.Equal(t, journals, parallelJournals)
+		assert.Equal(t, notifications, parallelNotifications)
+		var parallelKeys []string
+		for key := range parallelRunTimePerItem {
+			parallelKeys = append(parallelKeys, key)
+		}
+		sort.Strings(parallelKeys)
+		assert.Equal(t, keys, parallelKeys)
+	}
+}
+
+func TestPipelineRunConcurrentlyBranches(t *testing.T) {
+	pipeline := NewPipeline(test.Repository)
+	pipeline.Workers = 4
+	item := &testPipelineItem{}
+	pipeline.AddItem(item)
+	pipeline.Initialize(map[string]interface{}{})
+	hashes := []string{
+		"6db8065cdb9bb0758f36a7e75fc72ab95f9e8145",
+		"f30daba81ff2bf0b3ba02a1e1441e74f8a4f6fee",
+		"8a03b5620b1caa72ec9cb847ea88332621e2950a",
+		"dd9dd084d5851d7dc4399fc7dbf3d8292831ebc5",
+		"f4ed0405b14f006c0744029d87ddb3245607587a",
+	}
+	commits := make([]*object.Commit, len(hashes))
+	for i, h := range hashes {
+		var err error
+		commits[i], err = test.Repository.CommitObject(plumbing.NewHash(h))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	progress := 0
+	pipeline.OnProgress = func(step int, total int, action string) {
+		assert.Equal(t, progress+1, step)
+		progress = step
+	}
+	result, err := pipeline.Run(commits)
+	assert.Nil(t, err)
+	assert.True(t, item.Forked)
+	assert.True(t, *item.Merged)
+	assert.Equal(t, item, result[item].(*testPipelineItem))
+	assert.Equal(t, 5, result[nil].(*CommonAnalysisResult).CommitsNumber)
+	assert.Equal(t, 8, *item.MergeState)
+}
+
+func TestPipelineRunConcurrentlyErrors(t *testing.T) {
+	pipeline := NewPipeline(test.Repository)
+	item := &testPipelineItem{}
+	pipeline.AddItem(item)
+	pipeline.AddItem(&dependingTestPipelineItem{})
+	pipeline.Initialize(map[string]interface{}{ConfigPipelineWorkers: 2})
+	commits := make([]*object.Commit, 1)
+	commits[0], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"af9ddc0db70f09f3f27b4b98e415592a7485171c"))
+	item.TestError = true
+	result, err := pipeline.Run(commits)
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	item.TestError = false
+	item.ConsumePanics = true
+	assert.Panics(t, func() { pipeline.Run(commits) })
+	assert.Panics(t, func() {
+		pipeline.Initialize(map[string]interface{}{ConfigPipelineWorkers: -1})
+	})
+}
@@ -152,6 +152,14 @@ type ResultMergeablePipelineItem interface {
 	MergeResults(r1, r2 interface{}, c1, c2 *CommonAnalysisResult) interface{}
 }
 
+// JSONSerializablePipelineItem is the interface of the LeafPipelineItem-s which are able to
+// write their results in JSON format. The schemas are documented in doc/JSON.md.
+type JSONSerializablePipelineItem interface {
+	LeafPipelineItem
+	// SerializeJSON encodes the object returned by Finalize() to JSON.
+	SerializeJSON(result interface{}, writer io.Writer) error
+}
+
 // HibernateablePipelineItem is the interface to allow pipeline items to be frozen (compacted, unloaded)
 // while they are not needed in the hosting branch.
 type HibernateablePipelineItem interface {
@@ -162,6 +170,19 @@ type HibernateablePipelineItem interface {
 	Boot() error
 }
 
+// CheckpointablePipelineItem is the interface to allow pipeline items to save their complete state
+// after the last analysed commit and to restore it later, so that the analysis can continue
+// from that commit instead of replaying the whole history.
+type CheckpointablePipelineItem interface {
+	PipelineItem
+	// Checkpoint writes the state of the item which is required to resume the analysis.
+	Checkpoint(writer io.Writer) error
+	// Restore reads the state previously written by Checkpoint(). It is called right after
+	// Configure() and Initialize(). The item may update `facts` which are subsequently passed
+	// to Configure() of the dependent items.
+	Restore(reader io.Reader, facts map[string]interface{}) error
+}
+
 // CommonAnalysisResult holds the information which is always extracted at Pipeline.Run().
 type CommonAnalysisResult struct {
 	// BeginTime is the time of the first commit in the analysed sequence.
@@ -174,6 +195,14 @@ type CommonAnalysisResult struct {
 	RunTime time.Duration
 	// RunTimePerItem is the time elapsed by each PipelineItem.
 	RunTimePerItem map[string]float64
+	// TickSize is the duration of the time tick, see FactTickSize.
+	TickSize time.Duration
+	// IncludePatterns are the path patterns of the analysed files, see FactIncludePatterns.
+	IncludePatterns []string
+	// ExcludePatterns are the path patterns of the skipped files, see FactExcludePatterns.
+	ExcludePatterns []string
+	// Bots are the names of the identities which were detected as bots, see FactBots.
+	Bots []string
 }
 
 // Copy produces a deep clone of the object.
@@ -183,6 +212,9 @@ func (car CommonAnalysisResult) Copy() CommonAnalysisResult {
 	for key, val := range car.RunTimePerItem {
 		result.RunTimePerItem[key] = val
 	}
+	result.IncludePatterns = append([]string(nil), car.IncludePatterns...)
+	result.ExcludePatterns = append([]string(nil), car.ExcludePatterns...)
+	result.Bots = append([]string(nil), car.Bots...)
 	return result
 }
 
@@ -198,7 +230,8 @@ func (car *CommonAnalysisResult) EndTimeAsTime() time.Time {
 
 // Merge combines the CommonAnalysisResult with an other one.
 // We choose the earlier BeginTime, the later EndTime, sum the number of commits and the
-// elapsed run times.
+// elapsed run times. The merged TickSize is the coarser one: MergeResults() of the items
+// convert the ticks to it. The path patterns and the bots are joined.
 func (car *CommonAnalysisResult) Merge(other *CommonAnalysisResult) {
 	if car.EndTime == 0 || other.BeginTime == 0 {
 		panic("Merging with an uninitialized CommonAnalysisResult")
@@ -214,6 +247,40 @@ func (car *CommonAnalysisResult) Merge(other *CommonAnalysisResult) {
 	for key, val := range other.RunTimePerItem {
 		car.RunTimePerItem[key] += val
 	}
+	car.TickSize = MergeTickSizes(car.TickSize, other.TickSize)
+	car.IncludePatterns = mergePatterns(car.IncludePatterns, other.IncludePatterns)
+	car.ExcludePatterns = mergePatterns(car.ExcludePatterns, other.ExcludePatterns)
+	car.Bots = mergePatterns(car.Bots, other.Bots)
+}
+
+// mergePatterns appends the patterns which are absent in the first list.
+func mergePatterns(patterns1, patterns2 []string) []string {
+	exists := map[string]bool{}
+	for _, pattern := range patterns1 {
+		exists[pattern] = true
+	}
+	for _, pattern := range patterns2 {
+		if !exists[pattern] {
+			exists[pattern] = true
+			patterns1 = append(patterns1, pattern)
+		}
+	}
+	return patterns1
+}
+
+// MergeTickSizes returns the tick size of the merged results, that is, the coarser of the two.
+// Zero tick sizes mean one day, the historical default.
+func MergeTickSizes(tick1, tick2 time.Duration) time.Duration {
+	if tick1 == 0 {
+		tick1 = DefaultTickSize
+	}
+	if tick2 == 0 {
+		tick2 = DefaultTickSize
+	}
+	if tick2 > tick1 {
+		return tick2
+	}
+	return tick1
 }
 
 // FillMetadata copies the data to a Protobuf message.
@@ -223,6 +290,10 @@ func (car *CommonAnalysisResult) FillMetadata(meta *pb.Metadata) *pb.Metadata {
 	meta.Commits = int32(car.CommitsNumber)
 	meta.RunTime = car.RunTime.Nanoseconds() / 1e6
 	meta.RunTimePerItem = car.RunTimePerItem
+	meta.TickSize = int64(car.TickSize / time.Second)
+	meta.IncludePatterns = car.IncludePatterns
+	meta.ExcludePatterns = car.ExcludePatterns
+	meta.Bots = car.Bots
 	return meta
 }
 
@@ -230,13 +301,22 @@ func (car *CommonAnalysisResult) FillMetadata(meta *pb.Metadata) *pb.Metadata {
 type Metadata = pb.Metadata
 
 // MetadataToCommonAnalysisResult copies the data from a Protobuf message.
+// The files written before the tick size was recorded have one day ticks.
 func MetadataToCommonAnalysisResult(meta *Metadata) *CommonAnalysisResult {
+	tickSize := time.Duration(meta.TickSize) * time.Second
+	if tickSize == 0 {
+		tickSize = DefaultTickSize
+	}
 	return &CommonAnalysisResult{
-		BeginTime:      meta.BeginUnixTime,
-		EndTime:        meta.EndUnixTime,
-		CommitsNumber:  int(meta.Commits),
-		RunTime:        time.Duration(meta.RunTime * 1e6),
-		RunTimePerItem: meta.RunTimePerItem,
+		BeginTime:       meta.BeginUnixTime,
+		EndTime:         meta.EndUnixTime,
+		CommitsNumber:   int(meta.Commits),
+		RunTime:         time.Duration(meta.RunTime * 1e6),
+		RunTimePerItem:  meta.RunTimePerItem,
+		TickSize:        tickSize,
+		IncludePatterns: meta.IncludePatterns,
+		ExcludePatterns: meta.ExcludePatterns,
+		Bots:            meta.Bots,
 	}
 }
 
@@ -261,6 +341,15 @@ type Pipeline struct {
 	// PrintActions indicates whether to print the taken actions during the execution.
 	PrintActions bool
 
+	// CheckpointPath is the path to the file where to save the state of the items after
+	// the last analysed commit. Empty string disables checkpointing.
+	CheckpointPath string
+
+	// Workers is the number of goroutines which execute the items concurrently.
+	// 0 or 1 means the sequential execution. It must be set before Initialize() which
+	// puts the repository storage behind a mutex if there are several workers.
+	Workers int
+
 	// Repository points to the analysed Git repository struct from go-git.
 	repository *git.Repository
 
@@ -273,6 +362,21 @@ type Pipeline struct {
 
 	// Feature flags which enable the corresponding items.
 	features map[string]bool
+
+	// The checkpoint which the pipeline resumes from, nil if the analysis starts from scratch.
+	checkpoint *pipelineCheckpoint
+
+	// The callbacks registered with OnCommit().
+	commitSubscribers []func(commit *object.Commit, state map[string]interface{})
+
+	// The duration of the time tick published in FactTickSize.
+	tickSize time.Duration
+
+	// The path patterns published in FactIncludePatterns and FactExcludePatterns.
+	includePatterns, excludePatterns []string
+
+	// The names of the bots published in FactBots.
+	bots []string
 }
 
 const (
@@ -297,6 +401,38 @@ const (
 	// ConfigPipelinePrintActions is the name of the Pipeline configuration option (Pipeline.Initialize())
 	// which enables printing the taken actions of the execution plan to stderr.
 	ConfigPipelinePrintActions = "Pipeline.PrintActions"
+	// ConfigPipelineCheckpointPath is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which enables saving the state of the items after the last analysed commit to the specified file.
+	ConfigPipelineCheckpointPath = "Pipeline.CheckpointPath"
+	// ConfigPipelineResumeFrom is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which loads the state of the items from the specified checkpoint file. ConfigPipelineCommits
+	// is reduced to the commits which descend from the checkpointed head.
+	ConfigPipelineResumeFrom = "Pipeline.ResumeFrom"
+	// ConfigPipelineWorkers is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which sets the number of goroutines to execute the items concurrently.
+	ConfigPipelineWorkers = "Pipeline.Workers"
+	// ConfigPipelinePartialHistory is the name of the Pipeline configuration option (Pipeline.Initialize())
+	// which indicates that the parents of some analysed commits are not analysed, e.g. the commits
+	// were selected with CommitsInRange(). The items which maintain the state of the repository
+	// load it from those parents instead of assuming that the history starts from scratch.
+	ConfigPipelinePartialHistory = "Pipeline.PartialHistory"
+	// FactTickSize is the name of the fact which contains the duration of the time tick: the
+	// precision of the relative dates of the commits. It is set by the item which provides them
+	// (plumbing.DaysSinceStart) and is recorded in CommonAnalysisResult.
+	FactTickSize = "TickSize"
+	// DefaultTickSize is the duration of the time tick if FactTickSize is not set.
+	DefaultTickSize = 24 * time.Hour
+	// FactIncludePatterns is the name of the fact which contains the path patterns ([]string)
+	// of the analysed files. It is set by plumbing.TreeDiff and is recorded in CommonAnalysisResult.
+	FactIncludePatterns = "IncludePatterns"
+	// FactExcludePatterns is the name of the fact which contains the path patterns ([]string)
+	// of the files which are not analysed. It is set by plumbing.TreeDiff and is recorded
+	// in CommonAnalysisResult.
+	FactExcludePatterns = "ExcludePatterns"
+	// FactBots is the name of the fact which contains the names ([]string) of the identities
+	// which were detected as bots. It is set by identity.Detector and is recorded
+	// in CommonAnalysisResult.
+	FactBots = "Bots"
 	// DependencyCommit is the name of one of the three items in `deps` supplied to PipelineItem.Consume()
 	// which always exists. It corresponds to the currently analyzed commit.
 	DependencyCommit = "commit"
@@ -434,6 +570,44 @@ func (pipeline *Pipeline) Len() int {
 // (`git log --first-parent`) - effectively decreasing the accuracy but increasing performance.
 func (pipeline *Pipeline) Commits(firstParent bool) ([]*object.Commit, error) {
 	var result []*object.Commit
+	repository := pipeline.repository
+	head, err := pipeline.head()
+	if err != nil {
+		return nil, err
+	}
+
+	if firstParent {
+		commit, err := repository.CommitObject(head.Hash())
+		if err != nil {
+			panic(err)
+		}
+		// the first parent matches the head
+		for ; err != io.EOF; commit, err = commit.Parents().Next() {
+			if err != nil {
+				panic(err)
+			}
+			result = append(result, commit)
+		}
+		// reverse the order
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+		return result, nil
+	}
+	cit, err := repository.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to collect the commit history")
+	}
+	defer cit.Close()
+	cit.ForEach(func(commit *object.Commit) error {
+		result = append(result, commit)
+		return nil
+	})
+	return result, nil
+}
+
+// head returns the reference to the HEAD of the analysed repository.
+func (pipeline *Pipeline) head() (*plumbing.Reference, error) {
 	repository := pipeline.repository
 	head, err := repository.Head()
 	if err != nil {
@@ -454,18 +628,105 @@ func (pipeline *Pipeline) Commits(firstParent bool) ([]*object.Commit, error) {
 			return nil, errors.Wrap(err, "unable to collect the commit history")
 		}
 	}
+	return head, nil
+}
 
-	if firstParent {
-		commit, err := repository.CommitObject(head.Hash())
+// RevisionRange selects the part of the history to analyse, see Pipeline.CommitsInRange().
+// The zero value selects the whole history of HEAD, the same as Pipeline.Commits().
+type RevisionRange struct {
+	// Head is the revision to start from: a branch, a tag, a hash, etc. HEAD if empty.
+	Head string
+	// Exclude is the revision whose history is not analysed, "A" in `git log A..B`.
+	// Nothing is excluded if empty.
+	Exclude string
+	// Since excludes the commits which were committed before this moment. Ignored if zero.
+	Since time.Time
+	// Until excludes the commits which were committed after this moment. Ignored if zero.
+	Until time.Time
+}
+
+// ParseRevisionRange converts "B" or "A..B" to RevisionRange. Either side of ".." may be empty,
+// e.g. "A.." means "A..HEAD".
+func ParseRevisionRange(spec string) RevisionRange {
+	if pos := strings.Index(spec, ".."); pos >= 0 {
+		return RevisionRange{Head: spec[pos+2:], Exclude: spec[:pos]}
+	}
+	return RevisionRange{Head: spec}
+}
+
+// IsFullHistory returns true if the range selects the whole history of Head.
+func (rng RevisionRange) IsFullHistory() bool {
+	return rng.Exclude == "" && rng.Since.IsZero() && rng.Until.IsZero()
+}
+
+// contains returns true if the commit matches the date bounds.
+func (rng RevisionRange) contains(commit *object.Commit) bool {
+	when := commit.Committer.When
+	return (rng.Since.IsZero() || !when.Before(rng.Since)) &&
+		(rng.Until.IsZero() || !when.After(rng.Until))
+}
+
+// CommitsInRange returns the list of commits from the history similar to
+// `git log --since=<Since> --until=<Until> <Exclude>..<Head>`. The history walk stops
+// at the commits which are older than Since, so the result never contains their ancestors
+// which were committed later. `firstParent` has the same meaning as in Commits().
+// The first commits in the range may have parents; set ConfigPipelinePartialHistory
+// so that the items load the state of the repository before them.
+func (pipeline *Pipeline) CommitsInRange(rng RevisionRange, firstParent bool) ([]*object.Commit, error) {
+	repository := pipeline.repository
+	var headHash plumbing.Hash
+	if rng.Head == "" {
+		head, err := pipeline.head()
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
-		// the first parent matches the head
-		for ; err != io.EOF; commit, err = commit.Parents().Next() {
+		headHash = head.Hash()
+	} else {
+		hash, err := repository.ResolveRevision(plumbing.Revision(rng.Head))
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to resolve %s", rng.Head)
+		}
+		headHash = *hash
+	}
+	excluded := map[plumbing.Hash]bool{}
+	if rng.Exclude != "" {
+		hash, err := repository.ResolveRevision(plumbing.Revision(rng.Exclude))
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to resolve %s", rng.Exclude)
+		}
+		cit, err := repository.Log(&git.LogOptions{From: *hash})
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to collect the commit history of %s", rng.Exclude)
+		}
+		err = cit.ForEach(func(commit *object.Commit) error {
+			excluded[commit.Hash] = true
+			return nil
+		})
+		cit.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to collect the commit history of %s", rng.Exclude)
+		}
+	}
+	commit, err := repository.CommitObject(headHash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to load %s", headHash.String())
+	}
+	var result []*object.Commit
+	if firstParent {
+		for !excluded[commit.Hash] {
+			if !rng.Since.IsZero() && commit.Committer.When.Before(rng.Since) {
+				break
+			}
+			if rng.contains(commit) {
+				result = append(result, commit)
+			}
+			if commit.NumParents() == 0 {
+				break
+			}
+			commit, err = commit.Parent(0)
 			if err != nil {
-				panic(err)
+				return nil, errors.Wrap(err, "unable to collect the commit history")
 			}
-			result = append(result, commit)
 		}
 		// reverse the order
 		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
@@ -473,15 +734,24 @@ func (pipeline *Pipeline) Commits(firstParent bool) ([]*object.Commit, error) {
 		}
 		return result, nil
 	}
-	cit, err := repository.Log(&git.LogOptions{From: head.Hash()})
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to collect the commit history")
-	}
+	// the commits older than Since are marked as seen to skip their parents
+	cit := object.NewCommitPreorderIter(commit, excluded, nil)
 	defer cit.Close()
-	cit.ForEach(func(commit *object.Commit) error {
-		result = append(result, commit)
+	err = cit.ForEach(func(commit *object.Commit) error {
+		if !rng.Since.IsZero() && commit.Committer.When.Before(rng.Since) {
+			for _, parent := range commit.ParentHashes {
+				excluded[parent] = true
+			}
+			return nil
+		}
+		if rng.contains(commit) {
+			result = append(result, commit)
+		}
 		return nil
 	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to collect the commit history")
+	}
 	return result, nil
 }
 
@@ -654,8 +924,51 @@ func (pipeline *Pipeline) Initialize(facts map[string]interface{}) error {
 		}
 		pipeline.HibernationDistance = val
 	}
+	if val, exists := facts[ConfigPipelineWorkers].(int); exists {
+		if val < 0 {
+			log.Panicf("--workers cannot be negative (got %d)", val)
+		}
+		pipeline.Workers = val
+	}
+	if pipeline.Workers > 1 {
+		repository, err := lockRepository(pipeline.repository)
+		if err != nil {
+			cleanReturn = true
+			return errors.Wrap(err, "failed to lock the repository for the concurrent workers")
+		}
+		pipeline.repository = repository
+	}
+	if val, exists := facts[ConfigPipelineCheckpointPath].(string); exists {
+		pipeline.CheckpointPath = val
+	}
+	pipeline.checkpoint = nil
+	if resumePath, _ := facts[ConfigPipelineResumeFrom].(string); resumePath != "" {
+		checkpoint, err := loadCheckpoint(resumePath)
+		if err != nil {
+			cleanReturn = true
+			return err
+		}
+		commits, err := selectDescendants(
+			facts[ConfigPipelineCommits].([]*object.Commit), checkpoint.Head)
+		if err != nil {
+			cleanReturn = true
+			return err
+		}
+		if len(commits) == 0 {
+			cleanReturn = true
+			return fmt.Errorf("there are no new commits after %s", checkpoint.Head.String())
+		}
+		facts[ConfigPipelineCommits] = commits
+		pipeline.checkpoint = checkpoint
+	}
 	dumpPath, _ := facts[ConfigPipelineDAGPath].(string)
 	pipeline.resolve(dumpPath)
+	if pipeline.CheckpointPath != "" || pipeline.checkpoint != nil {
+		if err := pipeline.checkCheckpointable(); err != nil {
+			cleanReturn = true
+			return err
+		}
+	}
 	if dumpPlan, exists := facts[ConfigPipelineDumpPlan].(bool); exists {
 		pipeline.DumpPlan = dumpPlan
 	}
@@ -666,20 +979,35 @@ func (pipeline *Pipeline) Initialize(facts map[string]interface{}) error {
 			return nil
 		}
 	}
-	for _, item := range pipeline.items {
-		err := item.Configure(facts)
+	if pipeline.checkpoint != nil {
+		err := pipeline.restoreItems(facts)
 		if err != nil {
 			cleanReturn = true
-			return errors.Wrapf(err, "%s failed to configure", item.Name())
+			return err
 		}
-	}
-	for _, item := range pipeline.items {
-		err := item.Initialize(pipeline.repository)
-		if err != nil {
-			cleanReturn = true
-			return errors.Wrapf(err, "%s failed to initialize", item.Name())
+	} else {
+		for _, item := range pipeline.items {
+			err := item.Configure(facts)
+			if err != nil {
+				cleanReturn = true
+				return errors.Wrapf(err, "%s failed to configure", item.Name())
+			}
+		}
+		for _, item := range pipeline.items {
+			err := item.Initialize(pipeline.repository)
+			if err != nil {
+				cleanReturn = true
+				return errors.Wrapf(err, "%s failed to initialize", item.Name())
+			}
 		}
 	}
+	pipeline.tickSize, _ = facts[FactTickSize].(time.Duration)
+	if pipeline.tickSize == 0 {
+		pipeline.tickSize = DefaultTickSize
+	}
+	pipeline.includePatterns, _ = facts[FactIncludePatterns].([]string)
+	pipeline.excludePatterns, _ = facts[FactExcludePatterns].([]string)
+	pipeline.bots, _ = facts[FactBots].([]string)
 	if pipeline.HibernationDistance > 0 {
 		// if we want hibernation, then we want to minimize RSS
 		debug.SetGCPercent(20) // the default is 100
@@ -688,6 +1016,23 @@ func (pipeline *Pipeline) Initialize(facts map[string]interface{}) error {
 	return nil
 }
 
+// OnCommit subscribes the callback to the results of each commit in the master branch,
+// that is, the branch which produces the values returned from Run(). `state` is the same
+// mapping which the items receive in Consume() after all of them have finished: it contains
+// DependencyCommit, DependencyIndex, DependencyIsMerge and everything provided by the items.
+// The callback must not modify it. The callbacks are invoked from the goroutine which called
+// Run(), in the order of the analysis and in the order of the subscription.
+func (pipeline *Pipeline) OnCommit(callback func(commit *object.Commit, state map[string]interface{})) {
+	pipeline.commitSubscribers = append(pipeline.commitSubscribers, callback)
+}
+
+// notifyCommit invokes the callbacks registered with OnCommit().
+func (pipeline *Pipeline) notifyCommit(commit *object.Commit, state map[string]interface{}) {
+	for _, callback := range pipeline.commitSubscribers {
+		callback(commit, state)
+	}
+}
+
 // Run method executes the pipeline.
 //
 // `commits` is a slice with the git commits to analyse. Multiple branches are supported.
@@ -709,6 +1054,13 @@ func (pipeline *Pipeline) Run(commits []*object.Commit) (map[LeafPipelineItem]in
 	if onProgress == nil {
 		onProgress = func(int, int, string) {}
 	}
+	if pipeline.Workers > 1 && !pipeline.DryRun {
+		var err error
+		commits, err = pipeline.reloadCommits(commits)
+		if err != nil {
+			return nil, err
+		}
+	}
 	plan := prepareRunPlan(commits, pipeline.HibernationDistance, pipeline.DumpPlan)
 	progressSteps := len(plan) + 2
 	branches := map[int][]PipelineItem{}
@@ -717,42 +1069,80 @@ func (pipeline *Pipeline) Run(commits []*object.Commit) (map[LeafPipelineItem]in
 	if !pipeline.DryRun {
 		rootClone = cloneItems(pipeline.items, 1)[0]
 	}
-	var newestTime int64
 	runTimePerItem := map[string]float64{}
-
-	isMerge := func(index int, commit plumbing.Hash) bool {
-		match := false
-		// look for the same hash backward
-		for i := index - 1; i > 0; i-- {
-			switch plan[i].Action {
-			case runActionHibernate, runActionBoot:
+	var err error
+	if pipeline.Workers > 1 && !pipeline.DryRun {
+		err = pipeline.runConcurrently(
+			plan, branches, rootClone, runTimePerItem, onProgress, progressSteps)
+	} else {
+		err = pipeline.runSequentially(
+			plan, branches, rootClone, runTimePerItem, onProgress, progressSteps)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var newestTime int64
+	// the last consumed commit in each branch
+	heads := map[int]*object.Commit{}
+	if !pipeline.DryRun {
+		for _, step := range plan {
+			if step.Action != runActionCommit {
 				continue
-			case runActionCommit:
-				match = plan[i].Commit.Hash == commit
-				fallthrough
-			default:
-				i = 0
 			}
+			commitTime := step.Commit.Committer.When.Unix()
+			if commitTime > newestTime {
+				newestTime = commitTime
+			}
+			heads[step.Items[0]] = step.Commit
 		}
-		if match {
-			return true
+	}
+	commonResult := &CommonAnalysisResult{
+		BeginTime:       plan[0].Commit.Committer.When.Unix(),
+		EndTime:         newestTime,
+		CommitsNumber:   len(commits),
+		RunTimePerItem:  runTimePerItem,
+		TickSize:        pipeline.tickSize,
+		IncludePatterns: pipeline.includePatterns,
+		ExcludePatterns: pipeline.excludePatterns,
+		Bots:            pipeline.bots,
+	}
+	if pipeline.checkpoint != nil {
+		commonResult.BeginTime = pipeline.checkpoint.BeginTime
+		if pipeline.checkpoint.EndTime > commonResult.EndTime {
+			commonResult.EndTime = pipeline.checkpoint.EndTime
 		}
-		// look for the same hash forward
-		for i := index + 1; i < len(plan); i++ {
-			switch plan[i].Action {
-			case runActionHibernate, runActionBoot:
-				continue
-			case runActionCommit:
-				match = plan[i].Commit.Hash == commit
-				fallthrough
-			default:
-				i = len(plan)
+		commonResult.CommitsNumber += pipeline.checkpoint.CommitsNumber
+	}
+	onProgress(len(plan)+1, progressSteps, MessageFinalize)
+	result := map[LeafPipelineItem]interface{}{}
+	if !pipeline.DryRun {
+		masterIndex := getMasterBranchIndex(plan)
+		if pipeline.CheckpointPath != "" {
+			err = pipeline.saveCheckpoint(
+				pipeline.CheckpointPath, heads[masterIndex], branches[masterIndex], commonResult)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to save the checkpoint")
+			}
+		}
+		for index, item := range getMasterBranch(branches) {
+			if casted, ok := item.(LeafPipelineItem); ok {
+				result[pipeline.items[index].(LeafPipelineItem)] = casted.Finalize()
 			}
 		}
-		return match
 	}
+	onProgress(progressSteps, progressSteps, "")
+	commonResult.RunTime = time.Since(startRunTime)
+	result[nil] = commonResult
+	cleanReturn = true
+	return result, nil
+}
 
+// runSequentially executes the plan steps one by one in the current goroutine.
+func (pipeline *Pipeline) runSequentially(
+	plan []runAction, branches map[int][]PipelineItem, rootClone []PipelineItem,
+	runTimePerItem map[string]float64, onProgress func(int, int, string), progressSteps int) error {
 	commitIndex := 0
+	masterIndex := getMasterBranchIndex(plan)
 	for index, step := range plan {
 		onProgress(index+1, progressSteps, step.String())
 		if pipeline.DryRun {
@@ -770,7 +1160,7 @@ func (pipeline *Pipeline) Run(commits []*object.Commit) (map[LeafPipelineItem]in
 			state := map[string]interface{}{
 				DependencyCommit:  step.Commit,
 				DependencyIndex:   commitIndex,
-				DependencyIsMerge: isMerge(index, step.Commit.Hash),
+				DependencyIsMerge: isMergeStep(plan, index),
 			}
 			for _, item := range branches[firstItem] {
 				startTime := time.Now()
@@ -779,7 +1169,7 @@ func (pipeline *Pipeline) Run(commits []*object.Commit) (map[LeafPipelineItem]in
 				if err != nil {
 					log.Printf("%s failed on commit #%d (%d) %s\n",
 						item.Name(), commitIndex+1, index+1, step.Commit.Hash.String())
-					return nil, err
+					return err
 				}
 				for _, key := range item.Provides() {
 					val, ok := update[key]
@@ -789,9 +1179,8 @@ func (pipeline *Pipeline) Run(commits []*object.Commit) (map[LeafPipelineItem]in
 					state[key] = val
 				}
 			}
-			commitTime := step.Commit.Committer.When.Unix()
-			if commitTime > newestTime {
-				newestTime = commitTime
+			if firstItem == masterIndex {
+				pipeline.notifyCommit(step.Commit, state)
 			}
 			commitIndex++
 		case runActionFork:
@@ -844,25 +1233,7 @@ func (pipeline *Pipeline) Run(commits []*object.Commit) (map[LeafPipelineItem]in
 			}
 		}
 	}
-	onProgress(len(plan)+1, progressSteps, MessageFinalize)
-	result := map[LeafPipelineItem]interface{}{}
-	if !pipeline.DryRun {
-		for index, item := range getMasterBranch(branches) {
-			if casted, ok := item.(LeafPipelineItem); ok {
-				result[pipeline.items[index].(LeafPipelineItem)] = casted.Finalize()
-			}
-		}
-	}
-	onProgress(progressSteps, progressSteps, "")
-	result[nil] = &CommonAnalysisResult{
-		BeginTime:      plan[0].Commit.Committer.When.Unix(),
-		EndTime:        newestTime,
-		CommitsNumber:  len(commits),
-		RunTime:        time.Since(startRunTime),
-		RunTimePerItem: runTimePerItem,
-	}
-	cleanReturn = true
-	return result, nil
+	return nil
 }
 
 // LoadCommitsFromFile reads the file by the specified FS path and generates the sequence of commits
@@ -298,6 +298,7 @@ func TestPipelineRun(t *testing.T) {
 	assert.Equal(t, common.BeginTime, int64(1481719198))
 	assert.Equal(t, common.EndTime, int64(1481719198))
 	assert.Equal(t, common.CommitsNumber, 1)
+	assert.Equal(t, DefaultTickSize, common.TickSize)
 	assert.True(t, common.RunTime.Nanoseconds()/1e6 < 100)
 	assert.Len(t, common.RunTimePerItem, 1)
 	for key, val := range common.RunTimePerItem {
@@ -347,6 +348,31 @@ func TestPipelineRunBranches(t *testing.T) {
 	assert.Equal(t, *item.MergeState, 8)
 }
 
+func TestPipelineOnCommit(t *testing.T) {
+	pipeline := NewPipeline(test.Repository)
+	commits, err := pipeline.Commits(true)
+	assert.Nil(t, err)
+	pipeline.AddItem(&testPipelineItem{})
+	assert.Nil(t, pipeline.Initialize(map[string]interface{}{}))
+	var reported []*object.Commit
+	pipeline.OnCommit(func(commit *object.Commit, state map[string]interface{}) {
+		assert.Equal(t, commit, state[DependencyCommit])
+		assert.Equal(t, len(reported), state[DependencyIndex])
+		assert.Equal(t, false, state[DependencyIsMerge])
+		assert.Contains(t, state, "test")
+		reported = append(reported, commit)
+	})
+	counter := 0
+	pipeline.OnCommit(func(commit *object.Commit, state map[string]interface{}) {
+		counter++
+		assert.Equal(t, counter, len(reported))
+	})
+	_, err = pipeline.Run(commits)
+	assert.Nil(t, err)
+	assert.Equal(t, commits, reported)
+	assert.Equal(t, len(commits), counter)
+}
+
 func TestPipelineOnProgress(t *testing.T) {
 	pipeline := NewPipeline(test.Repository)
 	progressOk := 0
@@ -408,6 +434,84 @@ func TestPipelineCommitsFirstParent(t *testing.T) {
 		"a3ee37f91f0d705ec9c41ae88426f0ae44b2fbc3"))
 }
 
+func TestParseRevisionRange(t *testing.T) {
+	assert.Equal(t, RevisionRange{}, ParseRevisionRange(""))
+	assert.Equal(t, RevisionRange{Head: "v1"}, ParseRevisionRange("v1"))
+	assert.Equal(t, RevisionRange{Head: "v2", Exclude: "v1"}, ParseRevisionRange("v1..v2"))
+	assert.Equal(t, RevisionRange{Exclude: "v1"}, ParseRevisionRange("v1.."))
+	assert.True(t, RevisionRange{Head: "v1"}.IsFullHistory())
+	assert.False(t, RevisionRange{Exclude: "v1"}.IsFullHistory())
+	assert.False(t, RevisionRange{Since: time.Now()}.IsFullHistory())
+	assert.False(t, RevisionRange{Until: time.Now()}.IsFullHistory())
+}
+
+func commitHashes(commits []*object.Commit) []string {
+	hashes := make([]string, len(commits))
+	for i, commit := range commits {
+		hashes[i] = commit.Hash.String()[:7]
+	}
+	return hashes
+}
+
+func TestPipelineCommitsInRange(t *testing.T) {
+	pipeline := NewPipeline(test.Repository)
+	head := "a28e9064c70618dc9d68e1401b889975e0680d11"
+	commits, err := pipeline.CommitsInRange(RevisionRange{Head: head}, false)
+	assert.Nil(t, err)
+	assert.Len(t, commits, 10)
+	assert.Equal(t, head, commits[0].Hash.String())
+	commits, err = pipeline.CommitsInRange(
+		RevisionRange{Head: head, Exclude: "a3ee37f91f0d705ec9c41ae88426f0ae44b2fbc3"}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"a28e906", "186ff0d", "25ce7fe", "74ee938", "05937ec", "79f337d",
+		"ad401f5", "fc9ceec"}, commitHashes(commits))
+	commits, err = pipeline.CommitsInRange(
+		RevisionRange{Head: head, Exclude: "a3ee37f91f0d705ec9c41ae88426f0ae44b2fbc3"}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a28e906"}, commitHashes(commits))
+	commits, err = pipeline.CommitsInRange(
+		RevisionRange{Head: "fc9ceecb6dabcb2aab60e8619d972e8d8208a7df"}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cce947b", "a3ee37f", "fc9ceec"}, commitHashes(commits))
+}
+
+func TestPipelineCommitsInRangeDates(t *testing.T) {
+	pipeline := NewPipeline(test.Repository)
+	zone := time.FixedZone("CET", 3600)
+	rng := RevisionRange{
+		Head:  "a28e9064c70618dc9d68e1401b889975e0680d11",
+		Since: time.Date(2016, 12, 13, 19, 0, 0, 0, zone),
+	}
+	commits, err := pipeline.CommitsInRange(rng, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"a28e906", "186ff0d", "25ce7fe", "74ee938", "05937ec", "79f337d"}, commitHashes(commits))
+	rng.Until = time.Date(2016, 12, 13, 22, 0, 0, 0, zone)
+	commits, err = pipeline.CommitsInRange(rng, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"25ce7fe", "74ee938", "05937ec", "79f337d"}, commitHashes(commits))
+	commits, err = pipeline.CommitsInRange(rng, true)
+	assert.Nil(t, err)
+	assert.Len(t, commits, 0)
+	rng.Since = time.Time{}
+	rng.Head = "fc9ceecb6dabcb2aab60e8619d972e8d8208a7df"
+	rng.Until = time.Date(2016, 12, 13, 18, 0, 0, 0, zone)
+	commits, err = pipeline.CommitsInRange(rng, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cce947b", "a3ee37f"}, commitHashes(commits))
+}
+
+func TestPipelineCommitsInRangeErrors(t *testing.T) {
+	pipeline := NewPipeline(test.Repository)
+	commits, err := pipeline.CommitsInRange(RevisionRange{Head: "xxx"}, false)
+	assert.Nil(t, commits)
+	assert.NotNil(t, err)
+	commits, err = pipeline.CommitsInRange(RevisionRange{Exclude: "xxx"}, false)
+	assert.Nil(t, commits)
+	assert.NotNil(t, err)
+}
+
 func TestLoadCommitsFromFile(t *testing.T) {
 	tmp, err := ioutil.TempFile("", "hercules-test-")
 	assert.Nil(t, err)
@@ -565,6 +669,14 @@ func TestCommonAnalysisResultCopy(t *testing.T) {
 	assert.Equal(t, c1, c2)
 	c2.RunTimePerItem["one"] = 100500
 	assert.Equal(t, c1.RunTimePerItem["one"], float64(1))
+	c1.IncludePatterns = []string{"*.go"}
+	c2 = c1.Copy()
+	c2.IncludePatterns[0] = "*.py"
+	assert.Equal(t, []string{"*.go"}, c1.IncludePatterns)
+	c1.Bots = []string{"<bots>"}
+	c2 = c1.Copy()
+	c2.Bots[0] = "dependabot[bot]"
+	assert.Equal(t, []string{"<bots>"}, c1.Bots)
 }
 
 func TestCommonAnalysisResultMerge(t *testing.T) {
@@ -582,19 +694,50 @@ func TestCommonAnalysisResultMerge(t *testing.T) {
 	assert.Equal(t, c1.CommitsNumber, 3)
 	assert.Equal(t, c1.RunTime.Nanoseconds(), int64(300))
 	assert.Equal(t, c1.RunTimePerItem, map[string]float64{"one": 1, "two": 6, "three": 8})
+	assert.Equal(t, DefaultTickSize, c1.TickSize)
+	c2.TickSize = 7 * 24 * time.Hour
+	c1.Merge(&c2)
+	assert.Equal(t, 7*24*time.Hour, c1.TickSize)
+	c2.TickSize = time.Hour
+	c1.Merge(&c2)
+	assert.Equal(t, 7*24*time.Hour, c1.TickSize)
+	c1.IncludePatterns = []string{"*.go"}
+	c2.IncludePatterns = []string{"*.py", "*.go"}
+	c2.ExcludePatterns = []string{"*_test.go"}
+	c1.Merge(&c2)
+	assert.Equal(t, []string{"*.go", "*.py"}, c1.IncludePatterns)
+	assert.Equal(t, []string{"*_test.go"}, c1.ExcludePatterns)
+	assert.Equal(t, []string{"*.py", "*.go"}, c2.IncludePatterns)
+	c1.Bots = []string{"<bots>"}
+	c2.Bots = []string{"dependabot[bot]", "<bots>"}
+	c1.Merge(&c2)
+	assert.Equal(t, []string{"<bots>", "dependabot[bot]"}, c1.Bots)
 }
 
 func TestCommonAnalysisResultMetadata(t *testing.T) {
 	c1 := &CommonAnalysisResult{
 		BeginTime: 1513620635, EndTime: 1513720635, CommitsNumber: 1, RunTime: 100 * 1e6,
-		RunTimePerItem: map[string]float64{"one": 1, "two": 2}}
+		RunTimePerItem: map[string]float64{"one": 1, "two": 2}, TickSize: time.Hour}
 	meta := &pb.Metadata{}
 	c1 = MetadataToCommonAnalysisResult(c1.FillMetadata(meta))
+	assert.Equal(t, int64(3600), meta.TickSize)
+	assert.Equal(t, time.Hour, c1.TickSize)
 	assert.Equal(t, c1.BeginTimeAsTime().Unix(), int64(1513620635))
 	assert.Equal(t, c1.EndTimeAsTime().Unix(), int64(1513720635))
 	assert.Equal(t, c1.CommitsNumber, 1)
 	assert.Equal(t, c1.RunTime.Nanoseconds(), int64(100*1e6))
 	assert.Equal(t, c1.RunTimePerItem, map[string]float64{"one": 1, "two": 2})
+	meta.TickSize = 0
+	assert.Equal(t, DefaultTickSize, MetadataToCommonAnalysisResult(meta).TickSize)
+	c1.IncludePatterns = []string{"services/**/*.go"}
+	c1.ExcludePatterns = []string{"*_test.go"}
+	c1.Bots = []string{"<bots>"}
+	c1 = MetadataToCommonAnalysisResult(c1.FillMetadata(meta))
+	assert.Equal(t, []string{"services/**/*.go"}, meta.IncludePatterns)
+	assert.Equal(t, []string{"services/**/*.go"}, c1.IncludePatterns)
+	assert.Equal(t, []string{"*_test.go"}, c1.ExcludePatterns)
+	assert.Equal(t, []string{"<bots>"}, meta.Bots)
+	assert.Equal(t, []string{"<bots>"}, c1.Bots)
 }
 
 func TestConfigurationOptionTypeString(t *testing.T) {
@@ -266,6 +266,23 @@ func (registry *PipelineItemRegistry) AddFlags(flagSet *pflag.FlagSet) (
 		ptr5 := (**bool)(unsafe.Pointer(uintptr(unsafe.Pointer(&iface)) + unsafe.Sizeof(&iface)))
 		*ptr5 = flagSet.Bool("print-actions", false, "Print the executed actions to stderr.")
 		flags[ConfigPipelinePrintActions] = iface
+		iface = interface{}("")
+		ptr6 := (**string)(unsafe.Pointer(uintptr(unsafe.Pointer(&iface)) + unsafe.Sizeof(&iface)))
+		*ptr6 = flagSet.String("checkpoint", "", "Save the state of the pipeline after the last "+
+			"commit to the specified file. See also --resume-from.")
+		flags[ConfigPipelineCheckpointPath] = iface
+		PathifyFlagValue(flagSet.Lookup("checkpoint"))
+		iface = interface{}("")
+		ptr7 := (**string)(unsafe.Pointer(uintptr(unsafe.Pointer(&iface)) + unsafe.Sizeof(&iface)))
+		*ptr7 = flagSet.String("resume-from", "", "Load the pipeline state from the specified "+
+			"checkpoint file and analyse only the commits which descend from the saved head.")
+		flags[ConfigPipelineResumeFrom] = iface
+		PathifyFlagValue(flagSet.Lookup("resume-from"))
+		iface = interface{}(0)
+		ptr8 := (**int)(unsafe.Pointer(uintptr(unsafe.Pointer(&iface)) + unsafe.Sizeof(&iface)))
+		*ptr8 = flagSet.Int("workers", 1, "Number of goroutines to execute the independent "+
+			"parts of the pipeline concurrently. The results do not depend on this number.")
+		flags[ConfigPipelineWorkers] = iface
 	}
 	var features []string
 	for f := range registry.featureFlags.Choices {
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"io"
+	"sync"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/index"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/storage"
+)
+
+// lockedStorer serializes the access to the storage of the analysed repository.
+// go-git's filesystem storage is not safe for concurrent use, while runConcurrently()
+// executes several items which read the objects at the same time.
+type lockedStorer struct {
+	storage.Storer
+	lock *sync.Mutex
+}
+
+// lockRepository returns the repository which reads the same storage as `repository`
+// under a mutex. The objects which it returns never read the storage afterwards.
+func lockRepository(repository *git.Repository) (*git.Repository, error) {
+	if _, locked := repository.Storer.(*lockedStorer); locked {
+		return repository, nil
+	}
+	return git.Open(&lockedStorer{Storer: repository.Storer, lock: &sync.Mutex{}}, nil)
+}
+
+// detachObject reads the whole object into memory. The lazy objects of the filesystem
+// storage would otherwise read the packfiles outside of the lock.
+func detachObject(obj plumbing.EncodedObject) (plumbing.EncodedObject, error) {
+	if _, loaded := obj.(*plumbing.MemoryObject); loaded {
+		return obj, nil
+	}
+	reader, err := obj.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	detached := &plumbing.MemoryObject{}
+	detached.SetType(obj.Type())
+	if _, err = io.Copy(detached, reader); err != nil {
+		return nil, err
+	}
+	// calculate the hash now, the object may be shared by several goroutines later
+	detached.Hash()
+	return detached, nil
+}
+
+func (s *lockedStorer) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.SetEncodedObject(obj)
+}
+
+func (s *lockedStorer) EncodedObject(
+	objType plumbing.ObjectType, hash plumbing.Hash) (plumbing.EncodedObject, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	obj, err := s.Storer.EncodedObject(objType, hash)
+	if err != nil {
+		return nil, err
+	}
+	return detachObject(obj)
+}
+
+func (s *lockedStorer) IterEncodedObjects(objType plumbing.ObjectType) (storer.EncodedObjectIter, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	iter, err := s.Storer.IterEncodedObjects(objType)
+	if err != nil {
+		return nil, err
+	}
+	return &lockedObjectIter{iter: iter, lock: s.lock}, nil
+}
+
+func (s *lockedStorer) HasEncodedObject(hash plumbing.Hash) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.HasEncodedObject(hash)
+}
+
+func (s *lockedStorer) EncodedObjectSize(hash plumbing.Hash) (int64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.EncodedObjectSize(hash)
+}
+
+func (s *lockedStorer) SetReference(ref *plumbing.Reference) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.SetReference(ref)
+}
+
+func (s *lockedStorer) CheckAndSetReference(ref, old *plumbing.Reference) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.CheckAndSetReference(ref, old)
+}
+
+func (s *lockedStorer) Reference(name plumbing.ReferenceName) (*plumbing.Reference, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.Reference(name)
+}
+
+// IterReferences reads all the references at once so that the iterator does not need the lock.
+func (s *lockedStorer) IterReferences() (storer.ReferenceIter, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	iter, err := s.Storer.IterReferences()
+	if err != nil {
+		return nil, err
+	}
+	var refs []*plumbing.Reference
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		refs = append(refs, ref)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return storer.NewReferenceSliceIter(refs), nil
+}
+
+func (s *lockedStorer) RemoveReference(name plumbing.ReferenceName) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.RemoveReference(name)
+}
+
+func (s *lockedStorer) CountLooseRefs() (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.CountLooseRefs()
+}
+
+func (s *lockedStorer) PackRefs() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.PackRefs()
+}
+
+func (s *lockedStorer) SetShallow(commits []plumbing.Hash) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.SetShallow(commits)
+}
+
+func (s *lockedStorer) Shallow() ([]plumbing.Hash, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.Shallow()
+}
+
+func (s *lockedStorer) SetIndex(idx *index.Index) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.SetIndex(idx)
+}
+
+func (s *lockedStorer) Index() (*index.Index, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.Index()
+}
+
+func (s *lockedStorer) Config() (*config.Config, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.Config()
+}
+
+func (s *lockedStorer) SetConfig(cfg *config.Config) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.Storer.SetConfig(cfg)
+}
+
+// Module returns the storage of the submodule which shares the lock with the parent.
+func (s *lockedStorer) Module(name string) (storage.Storer, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	module, err := s.Storer.Module(name)
+	if err != nil {
+		return nil, err
+	}
+	return &lockedStorer{Storer: module, lock: s.lock}, nil
+}
+
+// lockedObjectIter is storer.EncodedObjectIter which reads the objects under the lock
+// of lockedStorer.
+type lockedObjectIter struct {
+	iter storer.EncodedObjectIter
+	lock *sync.Mutex
+}
+
+func (iter *lockedObjectIter) Next() (plumbing.EncodedObject, error) {
+	iter.lock.Lock()
+	defer iter.lock.Unlock()
+	obj, err := iter.iter.Next()
+	if err != nil {
+		return nil, err
+	}
+	return detachObject(obj)
+}
+
+// ForEach does not hold the lock while `callback` runs: it may read the storage, too.
+func (iter *lockedObjectIter) ForEach(callback func(plumbing.EncodedObject) error) error {
+	return storer.ForEachIterator(iter, callback)
+}
+
+func (iter *lockedObjectIter) Close() {
+	iter.lock.Lock()
+	defer iter.lock.Unlock()
+	iter.iter.Close()
+}
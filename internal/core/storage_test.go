@@ -0,0 +1,117 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+)
+
+// fixturePackedRepository writes several trees with distinct blobs to a new bare repository
+// in `dir`, packs them and returns the reopened repository with the tree hashes.
+func fixturePackedRepository(t *testing.T, dir string) (*git.Repository, []plumbing.Hash) {
+	storage := filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault())
+	repository, err := git.Init(storage, nil)
+	assert.Nil(t, err)
+	var trees []plumbing.Hash
+	for i := 0; i < 10; i++ {
+		tree := &object.Tree{}
+		for j := 0; j < 10; j++ {
+			blob := storage.NewEncodedObject()
+			blob.SetType(plumbing.BlobObject)
+			writer, _ := blob.Writer()
+			fmt.Fprintf(writer, "tree %d blob %d\n", i, j)
+			writer.Close()
+			hash, err := storage.SetEncodedObject(blob)
+			assert.Nil(t, err)
+			tree.Entries = append(tree.Entries, object.TreeEntry{
+				Name: fmt.Sprintf("%d", j), Mode: filemode.Regular, Hash: hash})
+		}
+		encoded := storage.NewEncodedObject()
+		assert.Nil(t, tree.Encode(encoded))
+		hash, err := storage.SetEncodedObject(encoded)
+		assert.Nil(t, err)
+		trees = append(trees, hash)
+	}
+	assert.Nil(t, repository.RepackObjects(&git.RepackConfig{}))
+	repository, err = git.Open(
+		filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault()), nil)
+	assert.Nil(t, err)
+	return repository, trees
+}
+
+func TestLockRepository(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "hercules-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpdir)
+	repository, trees := fixturePackedRepository(t, tmpdir)
+	locked, err := lockRepository(repository)
+	assert.Nil(t, err)
+	assert.NotEqual(t, repository, locked)
+	again, err := lockRepository(locked)
+	assert.Nil(t, err)
+	assert.Equal(t, locked, again)
+	obj, err := locked.Storer.EncodedObject(plumbing.TreeObject, trees[0])
+	assert.Nil(t, err)
+	assert.IsType(t, &plumbing.MemoryObject{}, obj)
+	assert.Equal(t, trees[0], obj.Hash())
+	iter, err := locked.Storer.IterEncodedObjects(plumbing.TreeObject)
+	assert.Nil(t, err)
+	count := 0
+	assert.Nil(t, iter.ForEach(func(obj plumbing.EncodedObject) error {
+		assert.IsType(t, &plumbing.MemoryObject{}, obj)
+		count++
+		return nil
+	}))
+	assert.Equal(t, len(trees), count)
+}
+
+// TestLockRepositoryConcurrentReads must be run with -race: the packfile index of go-git
+// caches the offsets without any synchronization.
+func TestLockRepositoryConcurrentReads(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "hercules-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpdir)
+	repository, trees := fixturePackedRepository(t, tmpdir)
+	repository, err = lockRepository(repository)
+	assert.Nil(t, err)
+	contents := make([][]string, 4)
+	wg := sync.WaitGroup{}
+	for i := range contents {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for _, hash := range trees {
+				tree, err := repository.TreeObject(hash)
+				if err != nil {
+					contents[i] = append(contents[i], err.Error())
+					continue
+				}
+				tree.Files().ForEach(func(file *object.File) error {
+					text, err := file.Contents()
+					if err != nil {
+						text = err.Error()
+					}
+					contents[i] = append(contents[i], text)
+					return nil
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, contents[0], len(trees)*10)
+	assert.Equal(t, "tree 0 blob 0\n", contents[0][0])
+	for _, other := range contents[1:] {
+		assert.Equal(t, contents[0], other)
+	}
+}
@@ -1,12 +1,23 @@
 package internal_test
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
 	"gopkg.in/src-d/hercules.v8/internal/core"
 	uast_items "gopkg.in/src-d/hercules.v8/internal/plumbing/uast"
 	"gopkg.in/src-d/hercules.v8/internal/test"
@@ -27,29 +38,43 @@ func TestPipelineSerialize(t *testing.T) {
 	bdot, _ := ioutil.ReadFile(dotpath)
 	dot := string(bdot)
 	assert.Equal(t, `digraph Hercules {
-  "6 BlobCache" -> "7 [blob_cache]"
-  "0 DaysSinceStart" -> "3 [day]"
-  "9 FileDiff" -> "11 [file_diff]"
-  "15 FileDiffRefiner" -> "16 Burndown"
-  "1 IdentityDetector" -> "4 [author]"
-  "8 RenameAnalysis" -> "16 Burndown"
-  "8 RenameAnalysis" -> "9 FileDiff"
-  "8 RenameAnalysis" -> "10 UAST"
-  "8 RenameAnalysis" -> "13 UASTChanges"
-  "2 TreeDiff" -> "5 [changes]"
-  "10 UAST" -> "12 [uasts]"
-  "13 UASTChanges" -> "14 [changed_uasts]"
-  "4 [author]" -> "16 Burndown"
-  "7 [blob_cache]" -> "16 Burndown"
-  "7 [blob_cache]" -> "9 FileDiff"
-  "7 [blob_cache]" -> "8 RenameAnalysis"
-  "7 [blob_cache]" -> "10 UAST"
-  "14 [changed_uasts]" -> "15 FileDiffRefiner"
-  "5 [changes]" -> "6 BlobCache"
-  "5 [changes]" -> "8 RenameAnalysis"
-  "3 [day]" -> "16 Burndown"
-  "11 [file_diff]" -> "15 FileDiffRefiner"
-  "12 [uasts]" -> "13 UASTChanges"
+  "10 BlobCache" -> "11 [blob_cache]"
+  "23 Burndown" -> "24 [burndown_lines]"
+  "0 DaysSinceStart" -> "4 [day]"
+  "14 FileDiff" -> "16 [file_diff]"
+  "20 FileDiffRefiner" -> "23 Burndown"
+  "20 FileDiffRefiner" -> "21 MoveDetection"
+  "1 IdentityDetector" -> "5 [author]"
+  "1 IdentityDetector" -> "6 [authors]"
+  "2 IgnoreRevisions" -> "7 [ignored_commit]"
+  "21 MoveDetection" -> "22 [moved_blocks]"
+  "12 RenameAnalysis" -> "23 Burndown"
+  "12 RenameAnalysis" -> "14 FileDiff"
+  "12 RenameAnalysis" -> "21 MoveDetection"
+  "12 RenameAnalysis" -> "15 UAST"
+  "12 RenameAnalysis" -> "18 UASTChanges"
+  "12 RenameAnalysis" -> "13 [copies]"
+  "3 TreeDiff" -> "9 [base_changes]"
+  "3 TreeDiff" -> "8 [changes]"
+  "15 UAST" -> "17 [uasts]"
+  "18 UASTChanges" -> "19 [changed_uasts]"
+  "5 [author]" -> "23 Burndown"
+  "6 [authors]" -> "23 Burndown"
+  "9 [base_changes]" -> "23 Burndown"
+  "11 [blob_cache]" -> "23 Burndown"
+  "11 [blob_cache]" -> "14 FileDiff"
+  "11 [blob_cache]" -> "21 MoveDetection"
+  "11 [blob_cache]" -> "12 RenameAnalysis"
+  "11 [blob_cache]" -> "15 UAST"
+  "19 [changed_uasts]" -> "20 FileDiffRefiner"
+  "8 [changes]" -> "10 BlobCache"
+  "8 [changes]" -> "12 RenameAnalysis"
+  "13 [copies]" -> "23 Burndown"
+  "4 [day]" -> "23 Burndown"
+  "16 [file_diff]" -> "20 FileDiffRefiner"
+  "7 [ignored_commit]" -> "23 Burndown"
+  "22 [moved_blocks]" -> "23 Burndown"
+  "17 [uasts]" -> "18 UASTChanges"
 }`, dot)
 }
 
@@ -67,21 +92,35 @@ func TestPipelineSerializeNoUast(t *testing.T) {
 	bdot, _ := ioutil.ReadFile(dotpath)
 	dot := string(bdot)
 	assert.Equal(t, `digraph Hercules {
-  "6 BlobCache" -> "7 [blob_cache]"
-  "0 DaysSinceStart" -> "3 [day]"
-  "9 FileDiff" -> "10 [file_diff]"
-  "1 IdentityDetector" -> "4 [author]"
-  "8 RenameAnalysis" -> "11 Burndown"
-  "8 RenameAnalysis" -> "9 FileDiff"
-  "2 TreeDiff" -> "5 [changes]"
-  "4 [author]" -> "11 Burndown"
-  "7 [blob_cache]" -> "11 Burndown"
-  "7 [blob_cache]" -> "9 FileDiff"
-  "7 [blob_cache]" -> "8 RenameAnalysis"
-  "5 [changes]" -> "6 BlobCache"
-  "5 [changes]" -> "8 RenameAnalysis"
-  "3 [day]" -> "11 Burndown"
-  "10 [file_diff]" -> "11 Burndown"
+  "10 BlobCache" -> "11 [blob_cache]"
+  "18 Burndown" -> "19 [burndown_lines]"
+  "0 DaysSinceStart" -> "4 [day]"
+  "14 FileDiff" -> "15 [file_diff]"
+  "1 IdentityDetector" -> "5 [author]"
+  "1 IdentityDetector" -> "6 [authors]"
+  "2 IgnoreRevisions" -> "7 [ignored_commit]"
+  "16 MoveDetection" -> "17 [moved_blocks]"
+  "12 RenameAnalysis" -> "18 Burndown"
+  "12 RenameAnalysis" -> "14 FileDiff"
+  "12 RenameAnalysis" -> "16 MoveDetection"
+  "12 RenameAnalysis" -> "13 [copies]"
+  "3 TreeDiff" -> "9 [base_changes]"
+  "3 TreeDiff" -> "8 [changes]"
+  "5 [author]" -> "18 Burndown"
+  "6 [authors]" -> "18 Burndown"
+  "9 [base_changes]" -> "18 Burndown"
+  "11 [blob_cache]" -> "18 Burndown"
+  "11 [blob_cache]" -> "14 FileDiff"
+  "11 [blob_cache]" -> "16 MoveDetection"
+  "11 [blob_cache]" -> "12 RenameAnalysis"
+  "8 [changes]" -> "10 BlobCache"
+  "8 [changes]" -> "12 RenameAnalysis"
+  "13 [copies]" -> "18 Burndown"
+  "4 [day]" -> "18 Burndown"
+  "15 [file_diff]" -> "18 Burndown"
+  "15 [file_diff]" -> "16 MoveDetection"
+  "7 [ignored_commit]" -> "18 Burndown"
+  "17 [moved_blocks]" -> "18 Burndown"
 }`, dot)
 }
 
@@ -91,3 +130,148 @@ func TestPipelineResolveIntegration(t *testing.T) {
 	pipeline.DeployItem(&leaves.CouplesAnalysis{})
 	pipeline.Initialize(nil)
 }
+
+// fixtureBranchedHistory commits a history with forks and merges to a new bare repository
+// in `dir` and packs the objects. The commits are returned in the order of their creation.
+func fixtureBranchedHistory(t *testing.T, dir string) (*git.Repository, []*object.Commit) {
+	storage := filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault())
+	repository, err := git.Init(storage, nil)
+	assert.Nil(t, err)
+	store := func(obj interface {
+		Encode(plumbing.EncodedObject) error
+	}) plumbing.Hash {
+		encoded := storage.NewEncodedObject()
+		assert.Nil(t, obj.Encode(encoded))
+		hash, err := storage.SetEncodedObject(encoded)
+		assert.Nil(t, err)
+		return hash
+	}
+	blob := func(lines ...string) plumbing.Hash {
+		obj := storage.NewEncodedObject()
+		obj.SetType(plumbing.BlobObject)
+		writer, _ := obj.Writer()
+		writer.Write([]byte(strings.Join(lines, "\n") + "\n"))
+		writer.Close()
+		hash, err := storage.SetEncodedObject(obj)
+		assert.Nil(t, err)
+		return hash
+	}
+	source := func(name string, version int) plumbing.Hash {
+		var lines []string
+		for i := 0; i < 20; i++ {
+			lines = append(lines, fmt.Sprintf("%s %d", name, i))
+			if i%(version+2) == 0 {
+				lines = append(lines, fmt.Sprintf("%s %d changed in %d", name, i, version))
+			}
+		}
+		return blob(lines...)
+	}
+	authors := []string{"one", "two", "three"}
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	var commits []*object.Commit
+	// each commit is the list of the parent indexes and the versions of the files
+	for i, step := range []struct {
+		parents []int
+		files   map[string]int
+	}{
+		{nil, map[string]int{"a.go": 0, "b.go": 0}},
+		{[]int{0}, map[string]int{"a.go": 1, "b.go": 0}},
+		{[]int{0}, map[string]int{"a.go": 0, "b.go": 2, "c/c.go": 0}},
+		{[]int{1}, map[string]int{"a.go": 3, "b.go": 0}},
+		{[]int{3, 2}, map[string]int{"a.go": 3, "b.go": 2, "c/c.go": 4}},
+		{[]int{4}, map[string]int{"a.go": 5, "b.go": 2, "c/c.go": 4}},
+		{[]int{4}, map[string]int{"a.go": 3, "b.go": 6, "c/c.go": 4, "c/d.go": 0}},
+		{[]int{5, 6}, map[string]int{"a.go": 5, "b.go": 6, "c/c.go": 7, "c/d.go": 0}},
+		{[]int{7}, map[string]int{"a.go": 8, "c/c.go": 7, "c/d.go": 8}},
+	} {
+		names := make([]string, 0, len(step.files))
+		for name := range step.files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		// "c" is the only subdirectory and it goes after the files in the root
+		root, subdir := &object.Tree{}, &object.Tree{}
+		for _, name := range names {
+			entry := object.TreeEntry{
+				Name: path.Base(name), Mode: filemode.Regular, Hash: source(name, step.files[name])}
+			if path.Dir(name) == "c" {
+				subdir.Entries = append(subdir.Entries, entry)
+			} else {
+				root.Entries = append(root.Entries, entry)
+			}
+		}
+		if len(subdir.Entries) > 0 {
+			root.Entries = append(root.Entries, object.TreeEntry{
+				Name: "c", Mode: filemode.Dir, Hash: store(subdir)})
+		}
+		var parents []plumbing.Hash
+		for _, parent := range step.parents {
+			parents = append(parents, commits[parent].Hash)
+		}
+		signature := object.Signature{
+			Name: authors[i%len(authors)], Email: authors[i%len(authors)] + "@srcd",
+			When: start.AddDate(0, 0, i*10)}
+		hash := store(&object.Commit{
+			Author: signature, Committer: signature, Message: fmt.Sprint(i),
+			TreeHash: store(root), ParentHashes: parents,
+		})
+		commit, err := object.GetCommit(storage, hash)
+		assert.Nil(t, err)
+		commits = append(commits, commit)
+	}
+	// the packfiles are shared by the readers unlike the loose objects
+	head := plumbing.NewHashReference(plumbing.Master, commits[len(commits)-1].Hash)
+	assert.Nil(t, storage.SetReference(head))
+	assert.Nil(t, repository.RepackObjects(&git.RepackConfig{}))
+	repository, err = git.Open(
+		filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault()), nil)
+	assert.Nil(t, err)
+	for i, commit := range commits {
+		commits[i], err = repository.CommitObject(commit.Hash)
+		assert.Nil(t, err)
+	}
+	return repository, commits
+}
+
+// TestPipelineRunConcurrentlyIntegration runs the real items on several workers and checks
+// that the results are the same as in the sequential run. Run it with -race.
+func TestPipelineRunConcurrentlyIntegration(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "hercules-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpdir)
+	// the packed filesystem storage is not safe for concurrent reads, see lockRepository()
+	repository, commits := fixtureBranchedHistory(t, tmpdir)
+	run := func(workers int) map[string]interface{} {
+		pipeline := core.NewPipeline(repository)
+		for _, leaf := range []core.LeafPipelineItem{
+			&leaves.BurndownAnalysis{}, &leaves.CouplesAnalysis{}, &leaves.DevsAnalysis{},
+			&leaves.OwnershipAnalysis{}} {
+			pipeline.DeployItem(leaf)
+		}
+		facts := map[string]interface{}{
+			core.ConfigPipelineCommits:       commits,
+			core.ConfigPipelineWorkers:       workers,
+			leaves.ConfigBurndownTrackFiles:  true,
+			leaves.ConfigBurndownTrackPeople: true,
+			leaves.ConfigBurndownGranularity: 10,
+			leaves.ConfigBurndownSampling:    10,
+		}
+		assert.Nil(t, pipeline.Initialize(facts))
+		results, err := pipeline.Run(commits)
+		assert.Nil(t, err)
+		named := map[string]interface{}{}
+		for item, result := range results {
+			if item == nil {
+				assert.Equal(t, len(commits), result.(*core.CommonAnalysisResult).CommitsNumber)
+				continue
+			}
+			named[item.Name()] = result
+		}
+		return named
+	}
+	sequential := run(0)
+	assert.Len(t, sequential, 4)
+	for _, workers := range []int{2, 4} {
+		assert.Equal(t, sequential, run(workers), "%d workers", workers)
+	}
+}
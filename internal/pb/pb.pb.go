@@ -33,6 +33,13 @@ It has these top-level messages:
 	Commit
 	CommitsAnalysisResults
 	AnalysisResults
+	AnalysisRequest
+	AnalysisProgress
+	AnalysisUpdate
+	OwnershipRecord
+	OwnershipAnalysisResults
+	CommitClassDays
+	CommitClassesAnalysisResults
 */
 package pb
 
@@ -40,6 +47,11 @@ import proto "github.com/gogo/protobuf/proto"
 import fmt "fmt"
 import math "math"
 
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
 var _ = fmt.Errorf
@@ -68,6 +80,14 @@ type Metadata struct {
 	RunTime int64 `protobuf:"varint,7,opt,name=run_time,json=runTime,proto3" json:"run_time,omitempty"`
 	// time taken by each pipeline item in seconds
 	RunTimePerItem map[string]float64 `protobuf:"bytes,8,rep,name=run_time_per_item,json=runTimePerItem" json:"run_time_per_item,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	// duration of the time tick in seconds, 0 means one day
+	TickSize int64 `protobuf:"varint,9,opt,name=tick_size,json=tickSize,proto3" json:"tick_size,omitempty"`
+	// path patterns of the analysed files, empty means all the files
+	IncludePatterns []string `protobuf:"bytes,10,rep,name=include_patterns,json=includePatterns" json:"include_patterns,omitempty"`
+	// path patterns of the files which are not analysed
+	ExcludePatterns []string `protobuf:"bytes,11,rep,name=exclude_patterns,json=excludePatterns" json:"exclude_patterns,omitempty"`
+	// names of the identities which were detected as bots
+	Bots []string `protobuf:"bytes,12,rep,name=bots" json:"bots,omitempty"`
 }
 
 func (m *Metadata) Reset()                    { *m = Metadata{} }
@@ -131,6 +151,34 @@ func (m *Metadata) GetRunTimePerItem() map[string]float64 {
 	return nil
 }
 
+func (m *Metadata) GetTickSize() int64 {
+	if m != nil {
+		return m.TickSize
+	}
+	return 0
+}
+
+func (m *Metadata) GetIncludePatterns() []string {
+	if m != nil {
+		return m.IncludePatterns
+	}
+	return nil
+}
+
+func (m *Metadata) GetExcludePatterns() []string {
+	if m != nil {
+		return m.ExcludePatterns
+	}
+	return nil
+}
+
+func (m *Metadata) GetBots() []string {
+	if m != nil {
+		return m.Bots
+	}
+	return nil
+}
+
 type BurndownSparseMatrixRow struct {
 	// the first `len(column)` elements are stored,
 	// the rest `number_of_columns - len(column)` values are zeros
@@ -222,6 +270,10 @@ type BurndownAnalysisResults struct {
 	PeopleInteraction *CompressedSparseRowMatrix `protobuf:"bytes,6,opt,name=people_interaction,json=peopleInteraction" json:"people_interaction,omitempty"`
 	// How many lines belong to relevant developers for each file. The order is the same as in `files`.
 	FilesOwnership []*FilesOwnership `protobuf:"bytes,7,rep,name=files_ownership,json=filesOwnership" json:"files_ownership,omitempty"`
+	// these two are included if `--teams` was specified together with `--burndown-people`
+	Teams []*BurndownSparseMatrix `protobuf:"bytes,8,rep,name=teams" json:"teams,omitempty"`
+	// rows and cols order correspond to `teams`
+	TeamsInteraction *CompressedSparseRowMatrix `protobuf:"bytes,9,opt,name=teams_interaction,json=teamsInteraction" json:"teams_interaction,omitempty"`
 }
 
 func (m *BurndownAnalysisResults) Reset()                    { *m = BurndownAnalysisResults{} }
@@ -278,6 +330,20 @@ func (m *BurndownAnalysisResults) GetFilesOwnership() []*FilesOwnership {
 	return nil
 }
 
+func (m *BurndownAnalysisResults) GetTeams() []*BurndownSparseMatrix {
+	if m != nil {
+		return m.Teams
+	}
+	return nil
+}
+
+func (m *BurndownAnalysisResults) GetTeamsInteraction() *CompressedSparseRowMatrix {
+	if m != nil {
+		return m.TeamsInteraction
+	}
+	return nil
+}
+
 type CompressedSparseRowMatrix struct {
 	NumberOfRows    int32 `protobuf:"varint,1,opt,name=number_of_rows,json=numberOfRows,proto3" json:"number_of_rows,omitempty"`
 	NumberOfColumns int32 `protobuf:"varint,2,opt,name=number_of_columns,json=numberOfColumns,proto3" json:"number_of_columns,omitempty"`
@@ -376,6 +442,10 @@ type CouplesAnalysisResults struct {
 	PeopleFiles []*TouchedFiles `protobuf:"bytes,8,rep,name=people_files,json=peopleFiles" json:"people_files,omitempty"`
 	// order corresponds to `files_couples::index`
 	FilesLines []int32 `protobuf:"varint,9,rep,packed,name=files_lines,json=filesLines" json:"files_lines,omitempty"`
+	// these two are included if `--teams` was specified
+	TeamCouples *Couples `protobuf:"bytes,10,opt,name=team_couples,json=teamCouples" json:"team_couples,omitempty"`
+	// order corresponds to `team_couples::index`
+	TeamFiles []*TouchedFiles `protobuf:"bytes,11,rep,name=team_files,json=teamFiles" json:"team_files,omitempty"`
 }
 
 func (m *CouplesAnalysisResults) Reset()                    { *m = CouplesAnalysisResults{} }
@@ -411,6 +481,20 @@ func (m *CouplesAnalysisResults) GetFilesLines() []int32 {
 	return nil
 }
 
+func (m *CouplesAnalysisResults) GetTeamCouples() *Couples {
+	if m != nil {
+		return m.TeamCouples
+	}
+	return nil
+}
+
+func (m *CouplesAnalysisResults) GetTeamFiles() []*TouchedFiles {
+	if m != nil {
+		return m.TeamFiles
+	}
+	return nil
+}
+
 type UASTChange struct {
 	FileName   string `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
 	SrcBefore  string `protobuf:"bytes,2,opt,name=src_before,json=srcBefore,proto3" json:"src_before,omitempty"`
@@ -567,6 +651,8 @@ type LineStats struct {
 	Added   int32 `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
 	Removed int32 `protobuf:"varint,2,opt,name=removed,proto3" json:"removed,omitempty"`
 	Changed int32 `protobuf:"varint,3,opt,name=changed,proto3" json:"changed,omitempty"`
+	// lines moved from elsewhere, see `--detect-moves`
+	Moved int32 `protobuf:"varint,4,opt,name=moved,proto3" json:"moved,omitempty"`
 }
 
 func (m *LineStats) Reset()                    { *m = LineStats{} }
@@ -595,6 +681,13 @@ func (m *LineStats) GetChanged() int32 {
 	return 0
 }
 
+func (m *LineStats) GetMoved() int32 {
+	if m != nil {
+		return m.Moved
+	}
+	return 0
+}
+
 type DevDay struct {
 	Commits   int32                 `protobuf:"varint,1,opt,name=commits,proto3" json:"commits,omitempty"`
 	Stats     *LineStats            `protobuf:"bytes,2,opt,name=stats" json:"stats,omitempty"`
@@ -646,6 +739,9 @@ func (m *DayDevs) GetDevs() map[int32]*DevDay {
 type DevsAnalysisResults struct {
 	Days     map[int32]*DayDevs `protobuf:"bytes,1,rep,name=days" json:"days,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
 	DevIndex []string           `protobuf:"bytes,2,rep,name=dev_index,json=devIndex" json:"dev_index,omitempty"`
+	// these two are included if `--teams` was specified
+	TeamDays  map[int32]*DayDevs `protobuf:"bytes,3,rep,name=team_days,json=teamDays" json:"team_days,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
+	TeamIndex []string           `protobuf:"bytes,4,rep,name=team_index,json=teamIndex" json:"team_index,omitempty"`
 }
 
 func (m *DevsAnalysisResults) Reset()                    { *m = DevsAnalysisResults{} }
@@ -667,6 +763,20 @@ func (m *DevsAnalysisResults) GetDevIndex() []string {
 	return nil
 }
 
+func (m *DevsAnalysisResults) GetTeamDays() map[int32]*DayDevs {
+	if m != nil {
+		return m.TeamDays
+	}
+	return nil
+}
+
+func (m *DevsAnalysisResults) GetTeamIndex() []string {
+	if m != nil {
+		return m.TeamIndex
+	}
+	return nil
+}
+
 type Sentiment struct {
 	Value    float32  `protobuf:"fixed32,1,opt,name=value,proto3" json:"value,omitempty"`
 	Comments []string `protobuf:"bytes,2,rep,name=comments" json:"comments,omitempty"`
@@ -760,6 +870,7 @@ type Commit struct {
 	WhenUnixTime int64         `protobuf:"varint,2,opt,name=when_unix_time,json=whenUnixTime,proto3" json:"when_unix_time,omitempty"`
 	Author       int32         `protobuf:"varint,3,opt,name=author,proto3" json:"author,omitempty"`
 	Files        []*CommitFile `protobuf:"bytes,4,rep,name=files" json:"files,omitempty"`
+	Merge        bool          `protobuf:"varint,5,opt,name=merge,proto3" json:"merge,omitempty"`
 }
 
 func (m *Commit) Reset()                    { *m = Commit{} }
@@ -795,6 +906,13 @@ func (m *Commit) GetFiles() []*CommitFile {
 	return nil
 }
 
+func (m *Commit) GetMerge() bool {
+	if m != nil {
+		return m.Merge
+	}
+	return false
+}
+
 type CommitsAnalysisResults struct {
 	Commits     []*Commit `protobuf:"bytes,1,rep,name=commits" json:"commits,omitempty"`
 	AuthorIndex []string  `protobuf:"bytes,2,rep,name=author_index,json=authorIndex" json:"author_index,omitempty"`
@@ -844,6 +962,251 @@ func (m *AnalysisResults) GetContents() map[string][]byte {
 	return nil
 }
 
+type AnalysisRequest struct {
+	// path to the local repository
+	Repository string `protobuf:"bytes,1,opt,name=repository,proto3" json:"repository,omitempty"`
+	// command line flags of the analyses to run, e.g. "burndown"
+	Leaves []string `protobuf:"bytes,2,rep,name=leaves" json:"leaves,omitempty"`
+	// command line flags of the configuration options mapped to their values,
+	// e.g. "granularity" -> "30"
+	Facts map[string]string `protobuf:"bytes,3,rep,name=facts" json:"facts,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// features to enable, e.g. "uast"
+	Features []string `protobuf:"bytes,4,rep,name=features" json:"features,omitempty"`
+	// follow only the first parent in the commit history
+	FirstParent bool `protobuf:"varint,5,opt,name=first_parent,json=firstParent,proto3" json:"first_parent,omitempty"`
+}
+
+func (m *AnalysisRequest) Reset()                    { *m = AnalysisRequest{} }
+func (m *AnalysisRequest) String() string            { return proto.CompactTextString(m) }
+func (*AnalysisRequest) ProtoMessage()               {}
+func (*AnalysisRequest) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{25} }
+
+func (m *AnalysisRequest) GetRepository() string {
+	if m != nil {
+		return m.Repository
+	}
+	return ""
+}
+
+func (m *AnalysisRequest) GetLeaves() []string {
+	if m != nil {
+		return m.Leaves
+	}
+	return nil
+}
+
+func (m *AnalysisRequest) GetFacts() map[string]string {
+	if m != nil {
+		return m.Facts
+	}
+	return nil
+}
+
+func (m *AnalysisRequest) GetFeatures() []string {
+	if m != nil {
+		return m.Features
+	}
+	return nil
+}
+
+func (m *AnalysisRequest) GetFirstParent() bool {
+	if m != nil {
+		return m.FirstParent
+	}
+	return false
+}
+
+type AnalysisProgress struct {
+	Step   int32  `protobuf:"varint,1,opt,name=step,proto3" json:"step,omitempty"`
+	Total  int32  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Action string `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+}
+
+func (m *AnalysisProgress) Reset()                    { *m = AnalysisProgress{} }
+func (m *AnalysisProgress) String() string            { return proto.CompactTextString(m) }
+func (*AnalysisProgress) ProtoMessage()               {}
+func (*AnalysisProgress) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{26} }
+
+func (m *AnalysisProgress) GetStep() int32 {
+	if m != nil {
+		return m.Step
+	}
+	return 0
+}
+
+func (m *AnalysisProgress) GetTotal() int32 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *AnalysisProgress) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+type AnalysisUpdate struct {
+	// exactly one of the fields is set; the results are sent in the last update
+	Progress *AnalysisProgress `protobuf:"bytes,1,opt,name=progress" json:"progress,omitempty"`
+	Results  *AnalysisResults  `protobuf:"bytes,2,opt,name=results" json:"results,omitempty"`
+}
+
+func (m *AnalysisUpdate) Reset()                    { *m = AnalysisUpdate{} }
+func (m *AnalysisUpdate) String() string            { return proto.CompactTextString(m) }
+func (*AnalysisUpdate) ProtoMessage()               {}
+func (*AnalysisUpdate) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{27} }
+
+func (m *AnalysisUpdate) GetProgress() *AnalysisProgress {
+	if m != nil {
+		return m.Progress
+	}
+	return nil
+}
+
+func (m *AnalysisUpdate) GetResults() *AnalysisResults {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type OwnershipRecord struct {
+	// developer index -> number of owned lines, -1 stands for the unidentified developers
+	Owners    map[int32]int32 `protobuf:"bytes,1,rep,name=owners" json:"owners,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	BusFactor int32           `protobuf:"varint,2,opt,name=bus_factor,json=busFactor,proto3" json:"bus_factor,omitempty"`
+}
+
+func (m *OwnershipRecord) Reset()                    { *m = OwnershipRecord{} }
+func (m *OwnershipRecord) String() string            { return proto.CompactTextString(m) }
+func (*OwnershipRecord) ProtoMessage()               {}
+func (*OwnershipRecord) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{28} }
+
+func (m *OwnershipRecord) GetOwners() map[int32]int32 {
+	if m != nil {
+		return m.Owners
+	}
+	return nil
+}
+
+func (m *OwnershipRecord) GetBusFactor() int32 {
+	if m != nil {
+		return m.BusFactor
+	}
+	return 0
+}
+
+type OwnershipAnalysisResults struct {
+	Files map[string]*OwnershipRecord `protobuf:"bytes,1,rep,name=files" json:"files,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
+	// "." is the root directory
+	Directories map[string]*OwnershipRecord `protobuf:"bytes,2,rep,name=directories" json:"directories,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
+	// developer index -> UNIX timestamp of the last commit
+	LastCommitTimes map[int32]int64 `protobuf:"bytes,3,rep,name=last_commit_times,json=lastCommitTimes" json:"last_commit_times,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	EndTime         int64           `protobuf:"varint,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	OrphanDays      int32           `protobuf:"varint,5,opt,name=orphan_days,json=orphanDays,proto3" json:"orphan_days,omitempty"`
+	OrphanedFiles   []string        `protobuf:"bytes,6,rep,name=orphaned_files,json=orphanedFiles" json:"orphaned_files,omitempty"`
+	DevIndex        []string        `protobuf:"bytes,7,rep,name=dev_index,json=devIndex" json:"dev_index,omitempty"`
+}
+
+func (m *OwnershipAnalysisResults) Reset()                    { *m = OwnershipAnalysisResults{} }
+func (m *OwnershipAnalysisResults) String() string            { return proto.CompactTextString(m) }
+func (*OwnershipAnalysisResults) ProtoMessage()               {}
+func (*OwnershipAnalysisResults) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{29} }
+
+func (m *OwnershipAnalysisResults) GetFiles() map[string]*OwnershipRecord {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+func (m *OwnershipAnalysisResults) GetDirectories() map[string]*OwnershipRecord {
+	if m != nil {
+		return m.Directories
+	}
+	return nil
+}
+
+func (m *OwnershipAnalysisResults) GetLastCommitTimes() map[int32]int64 {
+	if m != nil {
+		return m.LastCommitTimes
+	}
+	return nil
+}
+
+func (m *OwnershipAnalysisResults) GetEndTime() int64 {
+	if m != nil {
+		return m.EndTime
+	}
+	return 0
+}
+
+func (m *OwnershipAnalysisResults) GetOrphanDays() int32 {
+	if m != nil {
+		return m.OrphanDays
+	}
+	return 0
+}
+
+func (m *OwnershipAnalysisResults) GetOrphanedFiles() []string {
+	if m != nil {
+		return m.OrphanedFiles
+	}
+	return nil
+}
+
+func (m *OwnershipAnalysisResults) GetDevIndex() []string {
+	if m != nil {
+		return m.DevIndex
+	}
+	return nil
+}
+
+type CommitClassDays struct {
+	Days map[int32]*DayDevs `protobuf:"bytes,1,rep,name=days" json:"days,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *CommitClassDays) Reset()                    { *m = CommitClassDays{} }
+func (m *CommitClassDays) String() string            { return proto.CompactTextString(m) }
+func (*CommitClassDays) ProtoMessage()               {}
+func (*CommitClassDays) Descriptor() ([]byte, []int) { return fileDescriptorPb, []int{30} }
+
+func (m *CommitClassDays) GetDays() map[int32]*DayDevs {
+	if m != nil {
+		return m.Days
+	}
+	return nil
+}
+
+type CommitClassesAnalysisResults struct {
+	// commit class -> daily statistics per developer
+	Classes  map[string]*CommitClassDays `protobuf:"bytes,1,rep,name=classes" json:"classes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value"`
+	DevIndex []string                    `protobuf:"bytes,2,rep,name=dev_index,json=devIndex" json:"dev_index,omitempty"`
+}
+
+func (m *CommitClassesAnalysisResults) Reset()         { *m = CommitClassesAnalysisResults{} }
+func (m *CommitClassesAnalysisResults) String() string { return proto.CompactTextString(m) }
+func (*CommitClassesAnalysisResults) ProtoMessage()    {}
+func (*CommitClassesAnalysisResults) Descriptor() ([]byte, []int) {
+	return fileDescriptorPb, []int{31}
+}
+
+func (m *CommitClassesAnalysisResults) GetClasses() map[string]*CommitClassDays {
+	if m != nil {
+		return m.Classes
+	}
+	return nil
+}
+
+func (m *CommitClassesAnalysisResults) GetDevIndex() []string {
+	if m != nil {
+		return m.DevIndex
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Metadata)(nil), "Metadata")
 	proto.RegisterType((*BurndownSparseMatrixRow)(nil), "BurndownSparseMatrixRow")
@@ -870,102 +1233,248 @@ func init() {
 	proto.RegisterType((*Commit)(nil), "Commit")
 	proto.RegisterType((*CommitsAnalysisResults)(nil), "CommitsAnalysisResults")
 	proto.RegisterType((*AnalysisResults)(nil), "AnalysisResults")
+	proto.RegisterType((*AnalysisRequest)(nil), "AnalysisRequest")
+	proto.RegisterType((*AnalysisProgress)(nil), "AnalysisProgress")
+	proto.RegisterType((*AnalysisUpdate)(nil), "AnalysisUpdate")
+	proto.RegisterType((*OwnershipRecord)(nil), "OwnershipRecord")
+	proto.RegisterType((*OwnershipAnalysisResults)(nil), "OwnershipAnalysisResults")
+	proto.RegisterType((*CommitClassDays)(nil), "CommitClassDays")
+	proto.RegisterType((*CommitClassesAnalysisResults)(nil), "CommitClassesAnalysisResults")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Hercules service
+
+type HerculesClient interface {
+	Analyse(ctx context.Context, in *AnalysisRequest, opts ...grpc.CallOption) (Hercules_AnalyseClient, error)
+}
+
+type herculesClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewHerculesClient(cc *grpc.ClientConn) HerculesClient {
+	return &herculesClient{cc}
+}
+
+func (c *herculesClient) Analyse(ctx context.Context, in *AnalysisRequest, opts ...grpc.CallOption) (Hercules_AnalyseClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Hercules_serviceDesc.Streams[0], c.cc, "/Hercules/Analyse", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &herculesAnalyseClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Hercules_AnalyseClient interface {
+	Recv() (*AnalysisUpdate, error)
+	grpc.ClientStream
+}
+
+type herculesAnalyseClient struct {
+	grpc.ClientStream
+}
+
+func (x *herculesAnalyseClient) Recv() (*AnalysisUpdate, error) {
+	m := new(AnalysisUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Hercules service
+
+type HerculesServer interface {
+	Analyse(*AnalysisRequest, Hercules_AnalyseServer) error
+}
+
+func RegisterHerculesServer(s *grpc.Server, srv HerculesServer) {
+	s.RegisterService(&_Hercules_serviceDesc, srv)
+}
+
+func _Hercules_Analyse_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AnalysisRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HerculesServer).Analyse(m, &herculesAnalyseServer{stream})
+}
+
+type Hercules_AnalyseServer interface {
+	Send(*AnalysisUpdate) error
+	grpc.ServerStream
+}
+
+type herculesAnalyseServer struct {
+	grpc.ServerStream
+}
+
+func (x *herculesAnalyseServer) Send(m *AnalysisUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Hercules_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "Hercules",
+	HandlerType: (*HerculesServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Analyse",
+			Handler:       _Hercules_Analyse_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pb.proto",
 }
 
 func init() { proto.RegisterFile("pb.proto", fileDescriptorPb) }
 
 var fileDescriptorPb = []byte{
-	// 1467 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x57, 0x4f, 0x6f, 0xdb, 0xc6,
-	0x12, 0x07, 0xf5, 0x5f, 0x43, 0x59, 0x4e, 0x36, 0x79, 0x36, 0xa3, 0x07, 0xe7, 0x29, 0x84, 0x5f,
-	0x9e, 0x5f, 0x93, 0x32, 0x81, 0xd3, 0x43, 0x9a, 0x5e, 0xe2, 0xc8, 0x0d, 0x62, 0x20, 0x6e, 0x0a,
-	0x2a, 0x49, 0x6f, 0x15, 0xd6, 0xe2, 0xda, 0x62, 0x2b, 0x2e, 0x89, 0x5d, 0x52, 0xb2, 0x80, 0xf6,
-	0xab, 0xf4, 0xd6, 0x43, 0x0b, 0xf4, 0xd4, 0x2f, 0xd0, 0x43, 0x2f, 0xfd, 0x22, 0x05, 0xfa, 0x2d,
-	0x8a, 0xfd, 0xc7, 0x3f, 0x8a, 0x9c, 0xb6, 0x37, 0xce, 0xcc, 0x6f, 0x76, 0x66, 0x7e, 0x3b, 0x3b,
-	0xcb, 0x85, 0x4e, 0x72, 0xe6, 0x25, 0x2c, 0x4e, 0x63, 0xf7, 0xf7, 0x1a, 0x74, 0x4e, 0x49, 0x8a,
-	0x03, 0x9c, 0x62, 0xe4, 0x40, 0x7b, 0x41, 0x18, 0x0f, 0x63, 0xea, 0x58, 0x43, 0xeb, 0xa0, 0xe9,
-	0x1b, 0x11, 0x21, 0x68, 0xcc, 0x30, 0x9f, 0x39, 0xb5, 0xa1, 0x75, 0xd0, 0xf5, 0xe5, 0x37, 0xba,
-	0x0d, 0xc0, 0x48, 0x12, 0xf3, 0x30, 0x8d, 0xd9, 0xca, 0xa9, 0x4b, 0x4b, 0x49, 0x83, 0xee, 0xc2,
-	0xf6, 0x19, 0xb9, 0x08, 0xe9, 0x24, 0xa3, 0xe1, 0xe5, 0x24, 0x0d, 0x23, 0xe2, 0x34, 0x86, 0xd6,
-	0x41, 0xdd, 0xdf, 0x92, 0xea, 0x37, 0x34, 0xbc, 0x7c, 0x1d, 0x46, 0x04, 0xb9, 0xb0, 0x45, 0x68,
-	0x50, 0x42, 0x35, 0x25, 0xca, 0x26, 0x34, 0xc8, 0x31, 0x0e, 0xb4, 0xa7, 0x71, 0x14, 0x85, 0x29,
-	0x77, 0x5a, 0x2a, 0x33, 0x2d, 0xa2, 0x5b, 0xd0, 0x61, 0x19, 0x55, 0x8e, 0x6d, 0xe9, 0xd8, 0x66,
-	0x19, 0x95, 0x4e, 0x2f, 0xe0, 0xba, 0x31, 0x4d, 0x12, 0xc2, 0x26, 0x61, 0x4a, 0x22, 0xa7, 0x33,
-	0xac, 0x1f, 0xd8, 0x87, 0x7b, 0x9e, 0x29, 0xda, 0xf3, 0x15, 0xfa, 0x73, 0xc2, 0x4e, 0x52, 0x12,
-	0x7d, 0x4a, 0x53, 0xb6, 0xf2, 0xfb, 0xac, 0xa2, 0x1c, 0x1c, 0xc1, 0x8d, 0x0d, 0x30, 0x74, 0x0d,
-	0xea, 0x5f, 0x93, 0x95, 0xe4, 0xaa, 0xeb, 0x8b, 0x4f, 0x74, 0x13, 0x9a, 0x0b, 0x3c, 0xcf, 0x88,
-	0x24, 0xca, 0xf2, 0x95, 0xf0, 0xa4, 0xf6, 0xd8, 0x72, 0x1f, 0xc1, 0xee, 0xb3, 0x8c, 0xd1, 0x20,
-	0x5e, 0xd2, 0x71, 0x82, 0x19, 0x27, 0xa7, 0x38, 0x65, 0xe1, 0xa5, 0x1f, 0x2f, 0x55, 0x71, 0xf3,
-	0x2c, 0xa2, 0xdc, 0xb1, 0x86, 0xf5, 0x83, 0x2d, 0xdf, 0x88, 0xee, 0x8f, 0x16, 0xdc, 0xdc, 0xe4,
-	0x25, 0xf6, 0x83, 0xe2, 0x88, 0xe8, 0xd0, 0xf2, 0x1b, 0xed, 0x43, 0x9f, 0x66, 0xd1, 0x19, 0x61,
-	0x93, 0xf8, 0x7c, 0xc2, 0xe2, 0x25, 0x97, 0x49, 0x34, 0xfd, 0x9e, 0xd2, 0xbe, 0x3a, 0xf7, 0xe3,
-	0x25, 0x47, 0x1f, 0xc0, 0xf5, 0x02, 0x65, 0xc2, 0xd6, 0x25, 0x70, 0xdb, 0x00, 0x47, 0x4a, 0x8d,
-	0xee, 0x43, 0x43, 0xae, 0xd3, 0x90, 0x9c, 0x39, 0xde, 0x15, 0x05, 0xf8, 0x12, 0xe5, 0x7e, 0x03,
-	0xfd, 0xe7, 0xe1, 0x9c, 0xf0, 0x57, 0x4b, 0x4a, 0x18, 0x9f, 0x85, 0x09, 0x7a, 0x68, 0xd8, 0xb0,
-	0xe4, 0x02, 0x03, 0xaf, 0x6a, 0xf7, 0xde, 0x0a, 0xa3, 0x62, 0x5c, 0x01, 0x07, 0x8f, 0x01, 0x0a,
-	0x65, 0x99, 0xdf, 0xe6, 0x06, 0x7e, 0x9b, 0x65, 0x7e, 0xff, 0xa8, 0x15, 0x04, 0x1f, 0x51, 0x3c,
-	0x5f, 0xf1, 0x90, 0xfb, 0x84, 0x67, 0xf3, 0x94, 0xa3, 0x21, 0xd8, 0x17, 0x0c, 0xd3, 0x6c, 0x8e,
-	0x59, 0x98, 0x9a, 0xf5, 0xca, 0x2a, 0x34, 0x80, 0x0e, 0xc7, 0x51, 0x32, 0x0f, 0xe9, 0x85, 0x5e,
-	0x3a, 0x97, 0xd1, 0x03, 0x68, 0x27, 0x2c, 0xfe, 0x8a, 0x4c, 0x53, 0xc9, 0x93, 0x7d, 0xf8, 0xaf,
-	0xcd, 0x44, 0x18, 0x14, 0xba, 0x07, 0xcd, 0x73, 0x51, 0xa8, 0xe6, 0xed, 0x0a, 0xb8, 0xc2, 0xa0,
-	0x0f, 0xa1, 0x95, 0x90, 0x38, 0x99, 0x8b, 0xb6, 0x7f, 0x0f, 0x5a, 0x83, 0xd0, 0x09, 0x20, 0xf5,
-	0x35, 0x09, 0x69, 0x4a, 0x18, 0x9e, 0xa6, 0xe2, 0xb4, 0xb6, 0x64, 0x5e, 0x03, 0x6f, 0x14, 0x47,
-	0x09, 0x23, 0x9c, 0x93, 0x40, 0x39, 0xfb, 0xf1, 0x52, 0xfb, 0x5f, 0x57, 0x5e, 0x27, 0x85, 0x13,
-	0x7a, 0x0c, 0xdb, 0x32, 0x85, 0x49, 0x6c, 0x36, 0xc4, 0x69, 0xcb, 0x14, 0xb6, 0xd7, 0xf6, 0xc9,
-	0xef, 0x9f, 0x57, 0x64, 0xf7, 0x67, 0x0b, 0x6e, 0x5d, 0x19, 0x6a, 0x43, 0x1f, 0x5a, 0x7f, 0xb7,
-	0x0f, 0x6b, 0x9b, 0xfb, 0x10, 0x41, 0x43, 0x1c, 0x55, 0xa7, 0x3e, 0xac, 0x1f, 0xd4, 0xfd, 0x86,
-	0x99, 0x55, 0x21, 0x0d, 0xc2, 0xa9, 0xa6, 0xb9, 0xe9, 0x1b, 0x11, 0xed, 0x40, 0x2b, 0xa4, 0x41,
-	0x92, 0x32, 0xc9, 0x68, 0xdd, 0xd7, 0x92, 0x3b, 0x86, 0xf6, 0x28, 0xce, 0x12, 0x41, 0xfa, 0x4d,
-	0x68, 0x86, 0x34, 0x20, 0x97, 0xb2, 0x31, 0xbb, 0xbe, 0x12, 0xd0, 0x21, 0xb4, 0x22, 0x59, 0x82,
-	0xcc, 0xe3, 0xfd, 0x7c, 0x6a, 0xa4, 0xbb, 0x0f, 0xbd, 0xd7, 0x71, 0x36, 0x9d, 0x91, 0x40, 0x72,
-	0x26, 0x56, 0x56, 0x7b, 0x6f, 0xc9, 0xa4, 0x94, 0xe0, 0xfe, 0x66, 0xc1, 0x8e, 0x8e, 0xbd, 0xde,
-	0x9b, 0xf7, 0xa0, 0x27, 0x30, 0x93, 0xa9, 0x32, 0xeb, 0xad, 0xec, 0x78, 0x1a, 0xee, 0xdb, 0xc2,
-	0x6a, 0xf2, 0x7e, 0x00, 0x7d, 0xbd, 0xfb, 0x06, 0xde, 0x5e, 0x83, 0x6f, 0x29, 0xbb, 0x71, 0x78,
-	0x08, 0x3d, 0xed, 0xa0, 0xb2, 0x52, 0xd3, 0x6f, 0xcb, 0x2b, 0xe7, 0xec, 0xdb, 0x0a, 0xa2, 0x0a,
-	0xf8, 0x0f, 0xd8, 0xaa, 0x2b, 0xe6, 0x21, 0x25, 0xdc, 0xe9, 0xca, 0x32, 0x40, 0xaa, 0x5e, 0x0a,
-	0x8d, 0xfb, 0xbd, 0x05, 0xf0, 0xe6, 0x68, 0xfc, 0x7a, 0x34, 0xc3, 0xf4, 0x82, 0xa0, 0x7f, 0x43,
-	0x57, 0xe6, 0x5f, 0x1a, 0x47, 0x1d, 0xa1, 0xf8, 0x4c, 0x8c, 0xa4, 0x3d, 0x00, 0xce, 0xa6, 0x93,
-	0x33, 0x72, 0x1e, 0x33, 0xa2, 0x2f, 0x8f, 0x2e, 0x67, 0xd3, 0x67, 0x52, 0x21, 0x7c, 0x85, 0x19,
-	0x9f, 0xa7, 0x84, 0xe9, 0x0b, 0xa4, 0xc3, 0xd9, 0xf4, 0x48, 0xc8, 0x22, 0x91, 0x0c, 0xf3, 0xd4,
-	0x38, 0x37, 0xd4, 0xfd, 0x22, 0x54, 0xda, 0x7b, 0x0f, 0xa4, 0xa4, 0xdd, 0x9b, 0x6a, 0x71, 0xa1,
-	0x91, 0xfe, 0xee, 0x53, 0xd8, 0x2d, 0xd2, 0xe4, 0x63, 0xbc, 0x20, 0xcc, 0x70, 0xfe, 0x5f, 0x68,
-	0x4f, 0x95, 0x5a, 0x4f, 0x26, 0xdb, 0x2b, 0xa0, 0xbe, 0xb1, 0xb9, 0xbf, 0x5a, 0xd0, 0x1f, 0xcf,
-	0xe2, 0x94, 0x12, 0xce, 0x7d, 0x32, 0x8d, 0x59, 0x20, 0x3a, 0x31, 0x5d, 0x25, 0xf9, 0xdc, 0x15,
-	0xdf, 0xf9, 0x2c, 0xae, 0x95, 0x66, 0x31, 0x82, 0x86, 0x20, 0x41, 0x17, 0x25, 0xbf, 0xd1, 0xc7,
-	0xd0, 0x99, 0xc6, 0x99, 0x38, 0x80, 0x66, 0x32, 0xec, 0x79, 0xd5, 0xe5, 0xc5, 0x2e, 0x4a, 0xbb,
-	0x9a, 0x89, 0x39, 0x7c, 0xf0, 0x09, 0x6c, 0x55, 0x4c, 0xff, 0x68, 0x32, 0x1e, 0xc3, 0xae, 0x09,
-	0xb3, 0xde, 0x7c, 0xff, 0x87, 0x36, 0x93, 0x91, 0x0d, 0x11, 0xdb, 0x6b, 0x19, 0xf9, 0xc6, 0xee,
-	0xfe, 0x0f, 0x6c, 0xd1, 0x20, 0x2f, 0x42, 0x2e, 0x2f, 0xf7, 0xd2, 0x85, 0xac, 0xce, 0x90, 0x11,
-	0xdd, 0xef, 0x2c, 0x70, 0x4a, 0x48, 0x15, 0xea, 0x94, 0x70, 0x8e, 0x2f, 0x08, 0x7a, 0x52, 0x3e,
-	0x1e, 0xf6, 0xe1, 0xbe, 0x77, 0x15, 0x52, 0x8d, 0x20, 0x7d, 0x37, 0x48, 0x97, 0xc1, 0x73, 0x80,
-	0x42, 0xb9, 0xe1, 0xee, 0x75, 0xcb, 0x0c, 0xd8, 0x87, 0xbd, 0xca, 0xda, 0x25, 0x3e, 0xde, 0x40,
-	0x57, 0x74, 0xf2, 0x38, 0xc5, 0xa9, 0x3c, 0xaf, 0x38, 0x08, 0x48, 0xa0, 0xa9, 0x54, 0x82, 0xa8,
-	0x8e, 0x91, 0x28, 0x5e, 0x90, 0x40, 0xd3, 0x69, 0x44, 0x59, 0xb7, 0x6c, 0x8f, 0x40, 0x5f, 0x9a,
-	0x46, 0x14, 0xdd, 0xd2, 0x3a, 0x26, 0x8b, 0x63, 0xbc, 0x46, 0x4e, 0xe5, 0x6f, 0x65, 0x08, 0x4d,
-	0x2e, 0xe2, 0xea, 0x1c, 0xc1, 0xcb, 0x33, 0xf1, 0x95, 0x01, 0x7d, 0x04, 0xdd, 0x39, 0xa6, 0x17,
-	0x19, 0x16, 0xdd, 0x59, 0x97, 0x2c, 0xed, 0x78, 0x6a, 0x5d, 0xef, 0xa5, 0x31, 0x28, 0x5e, 0x0a,
-	0xe0, 0xe0, 0x05, 0xf4, 0xab, 0xc6, 0x0d, 0xfc, 0x0c, 0xab, 0xfc, 0x54, 0x62, 0x17, 0xec, 0x70,
-	0x68, 0x1f, 0xe3, 0xd5, 0x31, 0x59, 0x70, 0x74, 0x17, 0x1a, 0x01, 0x59, 0x98, 0xbd, 0x42, 0x9e,
-	0xd6, 0x8b, 0x6c, 0x74, 0x06, 0xd2, 0x3e, 0x78, 0x0a, 0xdd, 0x5c, 0xb5, 0xa1, 0x33, 0xf7, 0xaa,
-	0x71, 0xdb, 0xba, 0x9a, 0x72, 0xd0, 0x1f, 0x2c, 0xb8, 0x21, 0x96, 0x58, 0xef, 0xcf, 0x43, 0x31,
-	0xf8, 0x57, 0x26, 0x83, 0xdb, 0xde, 0x06, 0x8c, 0xc8, 0x2a, 0xcf, 0x06, 0xaf, 0xb8, 0x18, 0x2a,
-	0x01, 0x59, 0x4c, 0xd4, 0x7c, 0xaf, 0xc9, 0xde, 0xec, 0x04, 0x64, 0x71, 0x22, 0xe4, 0xc1, 0x11,
-	0x74, 0x73, 0xfc, 0x86, 0x54, 0x6f, 0x57, 0x53, 0xed, 0x98, 0x92, 0xcb, 0xb9, 0x7e, 0x01, 0xdd,
-	0x31, 0xa1, 0xe2, 0xa7, 0x92, 0xa6, 0xc5, 0xa9, 0x13, 0x8b, 0xd4, 0x34, 0x4c, 0xfc, 0x4d, 0x88,
-	0x0d, 0x27, 0x54, 0x6e, 0xb4, 0xcc, 0xc0, 0xc8, 0xe5, 0xde, 0xa8, 0x57, 0x0f, 0xce, 0x2f, 0x16,
-	0xec, 0x8e, 0x14, 0x2c, 0x0f, 0x60, 0x88, 0x78, 0x0b, 0xd7, 0xb8, 0xd1, 0x4d, 0xce, 0x56, 0x93,
-	0x00, 0xaf, 0x34, 0x29, 0xf7, 0xbd, 0x2b, 0x7c, 0xbc, 0x5c, 0xf1, 0x6c, 0x75, 0x8c, 0x57, 0xfa,
-	0xc7, 0x96, 0x57, 0x94, 0x83, 0x53, 0xb8, 0xb1, 0x01, 0xb6, 0x81, 0x99, 0x77, 0x9a, 0xa7, 0x08,
-	0x57, 0xe2, 0x86, 0x02, 0x8c, 0x64, 0x35, 0xe2, 0xe8, 0xa1, 0x3e, 0xd4, 0xd2, 0x58, 0x77, 0x60,
-	0x2d, 0x8d, 0xe5, 0x50, 0x64, 0x71, 0x64, 0x06, 0xa5, 0xf8, 0x16, 0x54, 0x99, 0x2e, 0x36, 0x37,
-	0x80, 0x91, 0x8b, 0xc3, 0xd2, 0xb8, 0xe2, 0xb0, 0xb8, 0xdf, 0x42, 0x4b, 0xc5, 0xcb, 0x1f, 0x28,
-	0x56, 0xe9, 0x81, 0xb2, 0x0f, 0xfd, 0xe5, 0x8c, 0x94, 0xdf, 0x1f, 0x35, 0xf9, 0x40, 0xe8, 0x09,
-	0x6d, 0xfe, 0xb4, 0xd8, 0x81, 0x16, 0xce, 0xd2, 0x59, 0xcc, 0xf4, 0x81, 0xd6, 0x12, 0xba, 0x53,
-	0xfd, 0x8b, 0xb3, 0xbd, 0xa2, 0x32, 0x73, 0xad, 0x7f, 0x29, 0x6e, 0x75, 0xb9, 0x79, 0xeb, 0x8d,
-	0x7b, 0xa7, 0x3a, 0x1e, 0x45, 0xd7, 0x2b, 0x64, 0x31, 0x0a, 0xee, 0x40, 0x4f, 0x45, 0xaa, 0xb4,
-	0xaa, 0xad, 0x74, 0xb2, 0x5b, 0xdd, 0x9f, 0x2c, 0xd8, 0x7e, 0x77, 0xe5, 0xd6, 0x8c, 0xe0, 0x80,
-	0x30, 0x59, 0xaa, 0x7d, 0xd8, 0xcd, 0x5f, 0x32, 0xbe, 0x36, 0xa0, 0x27, 0xa2, 0xfd, 0x68, 0x9a,
-	0xb7, 0x9f, 0x38, 0x39, 0xeb, 0xa7, 0x66, 0xa4, 0x01, 0xf9, 0x4d, 0xa3, 0x44, 0x75, 0xd3, 0x94,
-	0x4c, 0x7f, 0xf5, 0xc6, 0xe9, 0x95, 0xb6, 0xff, 0xac, 0x25, 0xdf, 0x94, 0x8f, 0xfe, 0x0c, 0x00,
-	0x00, 0xff, 0xff, 0x08, 0xb9, 0x9f, 0x5f, 0x5f, 0x0e, 0x00, 0x00,
+	// 2107 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0x4f, 0x73, 0xdc, 0x48,
+	0x15, 0x2f, 0xcd, 0x7f, 0x3d, 0x8d, 0x67, 0xec, 0x4e, 0x70, 0xb4, 0xb3, 0xeb, 0x30, 0x51, 0x65,
+	0x83, 0x37, 0xc9, 0x6a, 0x83, 0x77, 0x0f, 0xd9, 0x70, 0x60, 0x1d, 0x7b, 0x4d, 0x42, 0x39, 0xac,
+	0x4b, 0x76, 0x96, 0x2a, 0x0e, 0x4c, 0xb5, 0x47, 0x6d, 0x8f, 0xc8, 0x8c, 0x24, 0xba, 0x5b, 0x63,
+	0xcf, 0x16, 0x47, 0xbe, 0x01, 0x77, 0xaa, 0xa8, 0x82, 0x0b, 0x55, 0x9c, 0xf8, 0x02, 0x1c, 0xb8,
+	0xc3, 0x37, 0xa0, 0x8a, 0x3b, 0x47, 0xee, 0x54, 0xff, 0xd3, 0x48, 0xf2, 0x38, 0xde, 0x00, 0x37,
+	0xbd, 0xf7, 0x7e, 0xef, 0xf5, 0xeb, 0xf7, 0xaf, 0xbb, 0x05, 0x9d, 0xf4, 0xd4, 0x4f, 0x69, 0xc2,
+	0x13, 0xef, 0x5f, 0x75, 0xe8, 0xbc, 0x22, 0x1c, 0x87, 0x98, 0x63, 0xe4, 0x42, 0x7b, 0x4e, 0x28,
+	0x8b, 0x92, 0xd8, 0xb5, 0x86, 0xd6, 0x76, 0x33, 0x30, 0x24, 0x42, 0xd0, 0x98, 0x60, 0x36, 0x71,
+	0x6b, 0x43, 0x6b, 0xdb, 0x0e, 0xe4, 0x37, 0xba, 0x0b, 0x40, 0x49, 0x9a, 0xb0, 0x88, 0x27, 0x74,
+	0xe1, 0xd6, 0xa5, 0xa4, 0xc0, 0x41, 0x0f, 0xa0, 0x7f, 0x4a, 0xce, 0xa3, 0x78, 0x94, 0xc5, 0xd1,
+	0xe5, 0x88, 0x47, 0x33, 0xe2, 0x36, 0x86, 0xd6, 0x76, 0x3d, 0x58, 0x93, 0xec, 0xd7, 0x71, 0x74,
+	0x79, 0x12, 0xcd, 0x08, 0xf2, 0x60, 0x8d, 0xc4, 0x61, 0x01, 0xd5, 0x94, 0x28, 0x87, 0xc4, 0x61,
+	0x8e, 0x71, 0xa1, 0x3d, 0x4e, 0x66, 0xb3, 0x88, 0x33, 0xb7, 0xa5, 0x3c, 0xd3, 0x24, 0x7a, 0x0f,
+	0x3a, 0x34, 0x8b, 0x95, 0x62, 0x5b, 0x2a, 0xb6, 0x69, 0x16, 0x4b, 0xa5, 0x17, 0xb0, 0x61, 0x44,
+	0xa3, 0x94, 0xd0, 0x51, 0xc4, 0xc9, 0xcc, 0xed, 0x0c, 0xeb, 0xdb, 0xce, 0xce, 0x96, 0x6f, 0x36,
+	0xed, 0x07, 0x0a, 0x7d, 0x44, 0xe8, 0x4b, 0x4e, 0x66, 0x5f, 0xc6, 0x9c, 0x2e, 0x82, 0x1e, 0x2d,
+	0x31, 0xd1, 0xfb, 0x60, 0xf3, 0x68, 0xfc, 0x66, 0xc4, 0xa2, 0x6f, 0x88, 0x6b, 0xcb, 0x55, 0x3a,
+	0x82, 0x71, 0x1c, 0x7d, 0x43, 0xd0, 0x47, 0xb0, 0x1e, 0xc5, 0xe3, 0x69, 0x16, 0x92, 0x51, 0x8a,
+	0x39, 0x27, 0x34, 0x66, 0x2e, 0x0c, 0xeb, 0xdb, 0x76, 0xd0, 0xd7, 0xfc, 0x23, 0xcd, 0x16, 0x50,
+	0x72, 0x59, 0x81, 0x3a, 0x0a, 0x4a, 0x2e, 0xcb, 0x50, 0x04, 0x8d, 0xd3, 0x84, 0x33, 0xb7, 0x2b,
+	0xc5, 0xf2, 0x7b, 0xb0, 0x0b, 0xb7, 0x56, 0x78, 0x8b, 0xd6, 0xa1, 0xfe, 0x86, 0x2c, 0x64, 0xca,
+	0xec, 0x40, 0x7c, 0xa2, 0xdb, 0xd0, 0x9c, 0xe3, 0x69, 0x46, 0x64, 0xbe, 0xac, 0x40, 0x11, 0xcf,
+	0x6a, 0x4f, 0x2d, 0xef, 0x53, 0xb8, 0xf3, 0x3c, 0xa3, 0x71, 0x98, 0x5c, 0xc4, 0xc7, 0x29, 0xa6,
+	0x8c, 0xbc, 0xc2, 0x9c, 0x46, 0x97, 0x41, 0x72, 0xa1, 0x62, 0x3c, 0xcd, 0x66, 0x31, 0x73, 0xad,
+	0x61, 0x7d, 0x7b, 0x2d, 0x30, 0xa4, 0xf7, 0x47, 0x0b, 0x6e, 0xaf, 0xd2, 0x12, 0x4e, 0xc6, 0x78,
+	0x46, 0xf4, 0xd2, 0xf2, 0x1b, 0xdd, 0x87, 0x5e, 0x9c, 0xcd, 0x4e, 0x09, 0x1d, 0x25, 0x67, 0x23,
+	0x9a, 0x5c, 0x30, 0xe9, 0x44, 0x33, 0xe8, 0x2a, 0xee, 0x57, 0x67, 0x41, 0x72, 0xc1, 0xd0, 0x43,
+	0xd8, 0x58, 0xa2, 0xcc, 0xb2, 0x75, 0x09, 0xec, 0x1b, 0xe0, 0x9e, 0x62, 0xa3, 0xc7, 0xd0, 0x90,
+	0x76, 0x1a, 0x32, 0x75, 0xae, 0x7f, 0xcd, 0x06, 0x02, 0x89, 0xf2, 0x7e, 0x05, 0xbd, 0x83, 0x68,
+	0x4a, 0xd8, 0x57, 0x17, 0x31, 0xa1, 0x6c, 0x12, 0xa5, 0xe8, 0x89, 0x89, 0x86, 0x25, 0x0d, 0x0c,
+	0xfc, 0xb2, 0xdc, 0xff, 0x5a, 0x08, 0x55, 0xe2, 0x15, 0x70, 0xf0, 0x14, 0x60, 0xc9, 0x2c, 0xc6,
+	0xb7, 0xb9, 0x22, 0xbe, 0xcd, 0x62, 0x7c, 0xff, 0x51, 0x5f, 0x06, 0x78, 0x37, 0xc6, 0xd3, 0x05,
+	0x8b, 0x58, 0x40, 0x58, 0x36, 0xe5, 0x0c, 0x0d, 0xc1, 0x39, 0xa7, 0x38, 0xce, 0xa6, 0x98, 0x46,
+	0xdc, 0xd8, 0x2b, 0xb2, 0xd0, 0x00, 0x3a, 0x0c, 0xcf, 0xd2, 0x69, 0x14, 0x9f, 0x6b, 0xd3, 0x39,
+	0x8d, 0x3e, 0x81, 0x76, 0x4a, 0x93, 0x5f, 0x90, 0x31, 0x97, 0x71, 0x72, 0x76, 0xbe, 0xb3, 0x3a,
+	0x10, 0x06, 0x85, 0x1e, 0x41, 0xf3, 0x4c, 0x6c, 0x54, 0xc7, 0xed, 0x1a, 0xb8, 0xc2, 0xa0, 0x8f,
+	0xa1, 0x95, 0x92, 0x24, 0x9d, 0x8a, 0xee, 0x7b, 0x0b, 0x5a, 0x83, 0xd0, 0x4b, 0x40, 0xea, 0x6b,
+	0x14, 0xc5, 0x9c, 0x50, 0x3c, 0xe6, 0x62, 0x68, 0xb4, 0xa4, 0x5f, 0x03, 0x7f, 0x2f, 0x99, 0xa5,
+	0x94, 0x30, 0x46, 0x42, 0xa5, 0x1c, 0x24, 0x17, 0x5a, 0x7f, 0x43, 0x69, 0xbd, 0x5c, 0x2a, 0xa1,
+	0xa7, 0xd0, 0x97, 0x2e, 0x8c, 0x12, 0x93, 0x10, 0xb7, 0x2d, 0x5d, 0xe8, 0x57, 0xf2, 0x14, 0xf4,
+	0xce, 0xca, 0x79, 0x7d, 0x04, 0x4d, 0x4e, 0xf0, 0x8c, 0xb9, 0x9d, 0xb7, 0xb9, 0xac, 0x30, 0xe8,
+	0x47, 0xb0, 0x21, 0x3f, 0x4a, 0x0e, 0xdb, 0x37, 0x3a, 0xbc, 0x2e, 0x95, 0x0a, 0xfe, 0x7a, 0x7f,
+	0xb6, 0xe0, 0xbd, 0x6b, 0xf1, 0x2b, 0xaa, 0xdf, 0xfa, 0xb6, 0xd5, 0x5f, 0x5b, 0x5d, 0xfd, 0x08,
+	0x1a, 0x62, 0x4e, 0xb9, 0xf5, 0x61, 0x7d, 0xbb, 0x1e, 0x34, 0xcc, 0xa0, 0x8e, 0xe2, 0x30, 0x1a,
+	0xeb, 0xe4, 0x36, 0x03, 0x43, 0xa2, 0x4d, 0x68, 0x45, 0x71, 0x98, 0x72, 0x2a, 0xf3, 0x58, 0x0f,
+	0x34, 0xe5, 0x1d, 0x43, 0x7b, 0x2f, 0xc9, 0x52, 0x91, 0xea, 0xdb, 0xd0, 0x8c, 0xe2, 0x90, 0x5c,
+	0xca, 0x76, 0xb0, 0x03, 0x45, 0xa0, 0x1d, 0x68, 0xcd, 0xe4, 0x16, 0xdc, 0xda, 0x8d, 0x41, 0xd1,
+	0x48, 0xef, 0x3e, 0x74, 0x4f, 0x92, 0x6c, 0x3c, 0x21, 0xe1, 0x41, 0xa4, 0x2d, 0xab, 0x8a, 0xb3,
+	0xa4, 0x53, 0x8a, 0xf0, 0x7e, 0x5f, 0x83, 0x4d, 0xbd, 0x76, 0xb5, 0x23, 0x1e, 0x41, 0x57, 0x60,
+	0x46, 0x63, 0x25, 0xd6, 0x05, 0xd4, 0xf1, 0x35, 0x3c, 0x70, 0x84, 0xd4, 0xf8, 0xfd, 0x09, 0xf4,
+	0x74, 0xcd, 0x19, 0x78, 0xbb, 0x02, 0x5f, 0x53, 0x72, 0xa3, 0xf0, 0x04, 0xba, 0x5a, 0x41, 0x79,
+	0xa5, 0xca, 0x64, 0xcd, 0x2f, 0xfa, 0x1c, 0x38, 0x0a, 0xa2, 0x36, 0xf0, 0x5d, 0x70, 0x54, 0x2d,
+	0x4e, 0xa3, 0x98, 0x30, 0xd7, 0x96, 0xdb, 0x00, 0xc9, 0x3a, 0x14, 0x1c, 0xe1, 0xb0, 0x28, 0x88,
+	0xdc, 0x03, 0xa8, 0x3a, 0x2c, 0xa4, 0x66, 0xfd, 0xc7, 0x00, 0x12, 0xac, 0x56, 0x77, 0x56, 0xad,
+	0x6e, 0x0b, 0x80, 0xfc, 0xf4, 0xfe, 0x60, 0x01, 0xbc, 0xde, 0x3d, 0x3e, 0xd9, 0x9b, 0xe0, 0xf8,
+	0x9c, 0x88, 0x23, 0x47, 0x86, 0xa6, 0x30, 0x5f, 0x3b, 0x82, 0xf1, 0x13, 0x31, 0x63, 0xb7, 0x00,
+	0x18, 0x1d, 0x8f, 0x4e, 0xc9, 0x59, 0x42, 0x89, 0x3e, 0x94, 0x6d, 0x46, 0xc7, 0xcf, 0x25, 0x43,
+	0xe8, 0x0a, 0x31, 0x3e, 0xe3, 0x84, 0xea, 0x83, 0xb9, 0xc3, 0xe8, 0x78, 0x57, 0xd0, 0x62, 0x8f,
+	0x19, 0x66, 0xdc, 0x28, 0x37, 0xa4, 0x18, 0x04, 0x4b, 0x6b, 0x6f, 0x81, 0xa4, 0xb4, 0x7a, 0x53,
+	0x19, 0x17, 0x1c, 0xa9, 0xef, 0x7d, 0x01, 0x77, 0x96, 0x6e, 0xb2, 0x63, 0x3c, 0x27, 0xd4, 0xa4,
+	0xf3, 0x43, 0x68, 0x8f, 0x15, 0x5b, 0x8f, 0x5a, 0xc7, 0x5f, 0x42, 0x03, 0x23, 0xf3, 0xfe, 0x6a,
+	0x41, 0xef, 0x78, 0x92, 0xf0, 0x98, 0x30, 0x16, 0x90, 0x71, 0x42, 0x43, 0x51, 0xe4, 0x7c, 0x91,
+	0xe6, 0x07, 0x89, 0xf8, 0xce, 0x0f, 0x97, 0x5a, 0xe1, 0x70, 0x41, 0xd0, 0x10, 0x41, 0xd0, 0x9b,
+	0x92, 0xdf, 0xe8, 0x73, 0xe8, 0x8c, 0x93, 0x4c, 0x74, 0xa8, 0x19, 0x75, 0x5b, 0x7e, 0xd9, 0xbc,
+	0xbf, 0xa7, 0xe5, 0x6a, 0xc8, 0xe7, 0xf0, 0xc1, 0x0f, 0x60, 0xad, 0x24, 0x7a, 0xa7, 0x51, 0xbf,
+	0x0f, 0x77, 0xcc, 0x32, 0xd5, 0xba, 0xfe, 0x08, 0xda, 0x54, 0xae, 0x6c, 0x02, 0xd1, 0xaf, 0x78,
+	0x14, 0x18, 0xb9, 0xf7, 0x3d, 0x70, 0x44, 0xfe, 0x5f, 0x44, 0x4c, 0x5e, 0x9a, 0x0a, 0x17, 0x1d,
+	0xd5, 0x9e, 0x86, 0xf4, 0x7e, 0x6b, 0x81, 0x5b, 0x40, 0xaa, 0xa5, 0x5e, 0x11, 0xc6, 0xf0, 0x39,
+	0x41, 0xcf, 0x8a, 0x9d, 0xe7, 0xec, 0xdc, 0xf7, 0xaf, 0x43, 0x4a, 0x81, 0x8e, 0x83, 0x52, 0x19,
+	0x1c, 0x00, 0x2c, 0x99, 0x2b, 0x2e, 0x13, 0x5e, 0x31, 0x02, 0xce, 0x4e, 0xb7, 0x64, 0xbb, 0x10,
+	0x8f, 0x37, 0x60, 0x8b, 0x26, 0x39, 0xe6, 0x98, 0xcb, 0x51, 0x80, 0xc3, 0x90, 0x84, 0x3a, 0x94,
+	0x8a, 0x10, 0xbb, 0xa3, 0x64, 0x96, 0xcc, 0x49, 0xa8, 0xc3, 0x69, 0x48, 0xb9, 0x6f, 0x59, 0x1e,
+	0xa1, 0xbe, 0x05, 0x18, 0x52, 0x58, 0x52, 0x1a, 0x0d, 0x65, 0x49, 0x12, 0xa2, 0x86, 0x5a, 0xfb,
+	0x64, 0xbe, 0x8f, 0x2b, 0x21, 0x2b, 0xdd, 0x0d, 0x87, 0xd0, 0x64, 0xc2, 0x1b, 0xed, 0x39, 0xf8,
+	0xb9, 0x7f, 0x81, 0x12, 0xa0, 0xcf, 0xc0, 0x9e, 0xe2, 0xf8, 0x3c, 0xc3, 0xa2, 0x66, 0xeb, 0x32,
+	0x76, 0x9b, 0xbe, 0xb2, 0xeb, 0x1f, 0x1a, 0x81, 0x8a, 0xd6, 0x12, 0x38, 0x78, 0x01, 0xbd, 0xb2,
+	0x70, 0x45, 0xd4, 0x86, 0xe5, 0xa8, 0x95, 0xd6, 0x5e, 0xc6, 0x8c, 0x41, 0x7b, 0x1f, 0x2f, 0xf6,
+	0xc9, 0x9c, 0xa1, 0x07, 0xd0, 0x08, 0xc9, 0xdc, 0x64, 0x10, 0xf9, 0x9a, 0x2f, 0xbc, 0xd1, 0x1e,
+	0x48, 0xf9, 0xe0, 0x0b, 0xb0, 0x73, 0xd6, 0x8a, 0x7a, 0xdd, 0x2a, 0xaf, 0xdb, 0xd6, 0xbb, 0x29,
+	0x2e, 0xfa, 0xf7, 0x1a, 0xdc, 0x12, 0x26, 0xaa, 0x55, 0xbb, 0x23, 0x4e, 0x9a, 0x85, 0xf1, 0xe0,
+	0xae, 0xbf, 0x02, 0x23, 0xbc, 0xca, 0xbd, 0xc1, 0x0b, 0x26, 0x46, 0x4d, 0x48, 0xe6, 0x23, 0x75,
+	0xa0, 0xd4, 0x64, 0xc5, 0x76, 0x42, 0x32, 0x7f, 0x29, 0x68, 0xf4, 0x43, 0x90, 0xf3, 0x6d, 0x24,
+	0xad, 0xaa, 0xe8, 0x7a, 0x2b, 0xad, 0x9e, 0x10, 0x3c, 0x5b, 0x5a, 0xee, 0x70, 0x4d, 0x8a, 0x51,
+	0x24, 0x0d, 0x28, 0xf3, 0x0d, 0x69, 0x5e, 0x9a, 0x94, 0xf6, 0x07, 0xbb, 0x60, 0xe7, 0x5a, 0x2b,
+	0x42, 0x71, 0xb7, 0x1c, 0x8a, 0x8e, 0x09, 0x69, 0x21, 0x16, 0x83, 0x2f, 0x61, 0xad, 0xb4, 0xf8,
+	0x7f, 0x67, 0xc6, 0xfb, 0x29, 0xd8, 0xc7, 0x24, 0x16, 0x2f, 0x8d, 0x98, 0x2f, 0x47, 0x86, 0x30,
+	0x52, 0xd3, 0x30, 0x71, 0xb7, 0x13, 0x75, 0x49, 0x62, 0x59, 0x8f, 0x32, 0x50, 0x86, 0x2e, 0x96,
+	0x70, 0xbd, 0xdc, 0xf5, 0x7f, 0xb1, 0xe0, 0xce, 0x9e, 0x82, 0xe5, 0x0b, 0x98, 0x7c, 0x7d, 0x0d,
+	0xeb, 0xcc, 0xf0, 0x46, 0xa7, 0x0b, 0x11, 0x66, 0x9d, 0xbb, 0xc7, 0xfe, 0x35, 0x3a, 0x7e, 0xce,
+	0x78, 0xbe, 0xd8, 0xc7, 0x0b, 0xfd, 0xda, 0x61, 0x25, 0xe6, 0xe0, 0x15, 0xdc, 0x5a, 0x01, 0x5b,
+	0x11, 0x99, 0x2b, 0x35, 0xbe, 0x5c, 0xae, 0x10, 0x9b, 0x18, 0x60, 0x4f, 0xee, 0x46, 0xcc, 0x0d,
+	0xd4, 0x83, 0x1a, 0x4f, 0x74, 0xa3, 0xd4, 0x78, 0x22, 0x27, 0x3a, 0x4d, 0x66, 0x66, 0xca, 0x8b,
+	0x6f, 0x11, 0x2a, 0xd3, 0x6c, 0xe6, 0xf8, 0x32, 0xf4, 0xb2, 0xa7, 0x1b, 0xd7, 0xf4, 0xb4, 0xf7,
+	0x1b, 0x0b, 0x5a, 0x6a, 0xc1, 0xfc, 0xd9, 0x6a, 0x15, 0x9e, 0xad, 0xf7, 0xa1, 0x77, 0x31, 0x21,
+	0xc5, 0x57, 0x69, 0x4d, 0x3e, 0xe8, 0xba, 0x82, 0x9b, 0x3f, 0x38, 0x37, 0xa1, 0x85, 0x33, 0x3e,
+	0x49, 0xa8, 0x1e, 0x47, 0x9a, 0x42, 0xf7, 0xca, 0x97, 0x6a, 0xc7, 0x5f, 0x6e, 0xcd, 0x5c, 0xa5,
+	0xc5, 0xc0, 0x22, 0xf4, 0x5c, 0xbd, 0x63, 0x3b, 0x81, 0x22, 0xbc, 0x9f, 0xc3, 0xa6, 0x82, 0x5e,
+	0x69, 0xbb, 0x7b, 0xe5, 0x91, 0x2f, 0x7a, 0x56, 0x21, 0x97, 0x83, 0xec, 0x1e, 0x74, 0xd5, 0xfa,
+	0xa5, 0x46, 0x73, 0x14, 0x4f, 0xf6, 0x82, 0xf7, 0x27, 0x0b, 0xfa, 0x57, 0x2d, 0xb7, 0x26, 0x04,
+	0x87, 0x84, 0xca, 0x00, 0x38, 0x3b, 0x76, 0xfe, 0xea, 0x0d, 0xb4, 0x00, 0x3d, 0x13, 0x55, 0x19,
+	0xf3, 0xbc, 0x2a, 0x45, 0xdf, 0x57, 0xbb, 0x73, 0x4f, 0x03, 0xf2, 0xd3, 0x53, 0x91, 0xea, 0xf4,
+	0x2c, 0x88, 0x6e, 0x7a, 0x88, 0x76, 0x8b, 0x55, 0xf1, 0xef, 0x92, 0xbf, 0xbf, 0xcc, 0x08, 0xe3,
+	0x95, 0x3f, 0x0a, 0xd6, 0x95, 0x3f, 0x0a, 0x9b, 0xd0, 0x9a, 0x12, 0x3c, 0x27, 0xa6, 0x81, 0x34,
+	0x85, 0xbe, 0x0f, 0xcd, 0x33, 0x3c, 0xe6, 0x66, 0xc6, 0xbc, 0xef, 0x57, 0x0c, 0xfb, 0x07, 0x78,
+	0xac, 0x7d, 0x0c, 0x14, 0x52, 0x94, 0xd8, 0x19, 0xc1, 0x3c, 0xa3, 0x3a, 0x95, 0x76, 0x90, 0xd3,
+	0x22, 0xda, 0x67, 0x11, 0x65, 0x7c, 0x94, 0x62, 0x4a, 0x62, 0xae, 0xf3, 0xe8, 0x48, 0xde, 0x91,
+	0x64, 0x89, 0x07, 0xe2, 0xd2, 0xe6, 0x4d, 0xfb, 0xb6, 0x8b, 0xfb, 0x3e, 0x81, 0x75, 0xe3, 0xdd,
+	0x11, 0x4d, 0xce, 0x29, 0x61, 0xf2, 0x8a, 0xcf, 0x38, 0x49, 0x75, 0x6b, 0xc9, 0x6f, 0x61, 0x81,
+	0x27, 0x1c, 0x4f, 0xcd, 0xbd, 0x43, 0x12, 0xb2, 0x2c, 0xd5, 0xd3, 0x45, 0xf5, 0x85, 0xa6, 0xbc,
+	0x37, 0xd0, 0x33, 0x56, 0x5f, 0xa7, 0x21, 0xe6, 0x04, 0x7d, 0x0c, 0x9d, 0x54, 0xdb, 0xd7, 0xd9,
+	0xdf, 0xf0, 0xab, 0x0b, 0x07, 0x39, 0x04, 0x3d, 0x14, 0x27, 0xb3, 0x4c, 0xb7, 0x6e, 0xe6, 0xf5,
+	0x6a, 0x19, 0x04, 0x06, 0xe0, 0xfd, 0xce, 0x82, 0xfe, 0xf2, 0x55, 0xa6, 0x2e, 0x70, 0x9f, 0x41,
+	0x4b, 0xbd, 0xdf, 0x74, 0x0d, 0x7f, 0xe0, 0x57, 0x10, 0x9a, 0x56, 0x49, 0xd0, 0x58, 0x31, 0xdf,
+	0x4f, 0x33, 0x36, 0x12, 0x29, 0x49, 0xa8, 0xde, 0xa9, 0x7d, 0x9a, 0xb1, 0x03, 0xc9, 0x18, 0x7c,
+	0x0e, 0x4e, 0x41, 0xeb, 0x9d, 0x2e, 0x67, 0xff, 0x6c, 0x80, 0x9b, 0x7b, 0x50, 0xed, 0x8b, 0x2b,
+	0xb7, 0xa5, 0xeb, 0x90, 0x57, 0x6f, 0x4b, 0xe8, 0x10, 0x9c, 0x30, 0xa2, 0x44, 0xf8, 0x17, 0x11,
+	0xd3, 0x33, 0x0f, 0xaf, 0xb7, 0xb0, 0xbf, 0x04, 0x2b, 0x3b, 0x45, 0x75, 0xf4, 0x33, 0xd8, 0x98,
+	0x8a, 0xbb, 0xb6, 0x6a, 0x74, 0x39, 0x8e, 0x4c, 0x15, 0xfb, 0xd7, 0xdb, 0x3c, 0xc4, 0x8c, 0xab,
+	0x11, 0x21, 0x66, 0x95, 0xb6, 0xdb, 0x9f, 0x96, 0xb9, 0xe2, 0xcf, 0x98, 0xf8, 0xaf, 0x56, 0xf8,
+	0xf1, 0xd6, 0x26, 0x71, 0x28, 0x64, 0xe2, 0x0d, 0x90, 0xd0, 0x74, 0x82, 0x63, 0x75, 0x34, 0x37,
+	0x65, 0xf4, 0x40, 0xb1, 0xe4, 0xc1, 0xfb, 0x21, 0xf4, 0x14, 0x45, 0x42, 0xfd, 0x7c, 0x69, 0xc9,
+	0x26, 0x59, 0x33, 0x5c, 0xf5, 0x5e, 0x2a, 0x9d, 0xfe, 0xed, 0xf2, 0xe9, 0x3f, 0xf8, 0xf1, 0x0d,
+	0xf7, 0xca, 0x07, 0xe5, 0xd3, 0x63, 0xbd, 0x5a, 0x31, 0xc5, 0x63, 0xfa, 0x08, 0xd6, 0xab, 0x81,
+	0xfc, 0x1f, 0x2d, 0x3e, 0x87, 0xdb, 0xab, 0xc2, 0x78, 0x53, 0x91, 0xd5, 0x8b, 0x45, 0xf6, 0x6b,
+	0x0b, 0xfa, 0xca, 0xc0, 0xde, 0x14, 0x33, 0x26, 0x23, 0xe7, 0x97, 0x2e, 0x51, 0x03, 0xbf, 0x22,
+	0xaf, 0x5e, 0xa0, 0xfe, 0x0f, 0x77, 0x18, 0xef, 0x6f, 0x16, 0x7c, 0x50, 0x58, 0xe6, 0xea, 0x33,
+	0x7b, 0x1f, 0xda, 0x63, 0x25, 0xd1, 0x6e, 0x3d, 0xf4, 0xdf, 0x86, 0xf7, 0x35, 0x5b, 0xb9, 0x69,
+	0x54, 0xdf, 0x7a, 0xd5, 0x1b, 0x1c, 0x42, 0xb7, 0xa8, 0xf5, 0x6d, 0x92, 0x53, 0x89, 0x4c, 0x61,
+	0x47, 0x3b, 0xcf, 0xa0, 0xf3, 0x82, 0xd0, 0x71, 0x26, 0x6a, 0xcc, 0x87, 0xb6, 0xf2, 0x8f, 0xa0,
+	0xf5, 0xea, 0x60, 0x1f, 0xf4, 0xfd, 0xf2, 0xd8, 0x7b, 0x62, 0x9d, 0xb6, 0xe4, 0x8f, 0xed, 0x4f,
+	0xff, 0x33, 0x00, 0x91, 0x82, 0x07, 0x5c, 0xe4, 0x16, 0x00, 0x00,
 }
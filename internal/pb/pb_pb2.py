@@ -19,7 +19,7 @@ DESCRIPTOR = _descriptor.FileDescriptor(
   package='',
   syntax='proto3',
   serialized_options=None,
-  serialized_pb=_b('\n\x08pb.proto\"\x81\x02\n\x08Metadata\x12\x0f\n\x07version\x18\x01 \x01(\x05\x12\x0c\n\x04hash\x18\x02 \x01(\t\x12\x12\n\nrepository\x18\x03 \x01(\t\x12\x17\n\x0f\x62\x65gin_unix_time\x18\x04 \x01(\x03\x12\x15\n\rend_unix_time\x18\x05 \x01(\x03\x12\x0f\n\x07\x63ommits\x18\x06 \x01(\x05\x12\x10\n\x08run_time\x18\x07 \x01(\x03\x12\x38\n\x11run_time_per_item\x18\x08 \x03(\x0b\x32\x1d.Metadata.RunTimePerItemEntry\x1a\x35\n\x13RunTimePerItemEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x01:\x02\x38\x01\"*\n\x17\x42urndownSparseMatrixRow\x12\x0f\n\x07\x63olumns\x18\x01 \x03(\r\"\x7f\n\x14\x42urndownSparseMatrix\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x16\n\x0enumber_of_rows\x18\x02 \x01(\x05\x12\x19\n\x11number_of_columns\x18\x03 \x01(\x05\x12&\n\x04rows\x18\x04 \x03(\x0b\x32\x18.BurndownSparseMatrixRow\"i\n\x0e\x46ilesOwnership\x12)\n\x05value\x18\x01 \x03(\x0b\x32\x1a.FilesOwnership.ValueEntry\x1a,\n\nValueEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\r\n\x05value\x18\x02 \x01(\x05:\x02\x38\x01\"\x97\x02\n\x17\x42urndownAnalysisResults\x12\x13\n\x0bgranularity\x18\x01 \x01(\x05\x12\x10\n\x08sampling\x18\x02 \x01(\x05\x12&\n\x07project\x18\x03 \x01(\x0b\x32\x15.BurndownSparseMatrix\x12$\n\x05\x66iles\x18\x04 \x03(\x0b\x32\x15.BurndownSparseMatrix\x12%\n\x06people\x18\x05 \x03(\x0b\x32\x15.BurndownSparseMatrix\x12\x36\n\x12people_interaction\x18\x06 \x01(\x0b\x32\x1a.CompressedSparseRowMatrix\x12(\n\x0f\x66iles_ownership\x18\x07 \x03(\x0b\x32\x0f.FilesOwnership\"}\n\x19\x43ompressedSparseRowMatrix\x12\x16\n\x0enumber_of_rows\x18\x01 \x01(\x05\x12\x19\n\x11number_of_columns\x18\x02 \x01(\x05\x12\x0c\n\x04\x64\x61ta\x18\x03 \x03(\x03\x12\x0f\n\x07indices\x18\x04 \x03(\x05\x12\x0e\n\x06indptr\x18\x05 \x03(\x03\"D\n\x07\x43ouples\x12\r\n\x05index\x18\x01 \x03(\t\x12*\n\x06matrix\x18\x02 \x01(\x0b\x32\x1a.CompressedSparseRowMatrix\"\x1d\n\x0cTouchedFiles\x12\r\n\x05\x66iles\x18\x01 \x03(\x05\"\x94\x01\n\x16\x43ouplesAnalysisResults\x12\x1e\n\x0c\x66ile_couples\x18\x06 \x01(\x0b\x32\x08.Couples\x12 \n\x0epeople_couples\x18\x07 \x01(\x0b\x32\x08.Couples\x12#\n\x0cpeople_files\x18\x08 \x03(\x0b\x32\r.TouchedFiles\x12\x13\n\x0b\x66iles_lines\x18\t \x03(\x05\"o\n\nUASTChange\x12\x11\n\tfile_name\x18\x01 \x01(\t\x12\x12\n\nsrc_before\x18\x02 \x01(\t\x12\x11\n\tsrc_after\x18\x03 \x01(\t\x12\x13\n\x0buast_before\x18\x04 \x01(\t\x12\x12\n\nuast_after\x18\x05 \x01(\t\"7\n\x17UASTChangesSaverResults\x12\x1c\n\x07\x63hanges\x18\x01 \x03(\x0b\x32\x0b.UASTChange\"\x9c\x01\n\x0eShotnessRecord\x12\x0c\n\x04type\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x0c\n\x04\x66ile\x18\x03 \x01(\t\x12/\n\x08\x63ounters\x18\x04 \x03(\x0b\x32\x1d.ShotnessRecord.CountersEntry\x1a/\n\rCountersEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\r\n\x05value\x18\x02 \x01(\x05:\x02\x38\x01\";\n\x17ShotnessAnalysisResults\x12 \n\x07records\x18\x01 \x03(\x0b\x32\x0f.ShotnessRecord\"\x1e\n\x0b\x46ileHistory\x12\x0f\n\x07\x63ommits\x18\x01 \x03(\t\"\x8b\x01\n\x18\x46ileHistoryResultMessage\x12\x33\n\x05\x66iles\x18\x01 \x03(\x0b\x32$.FileHistoryResultMessage.FilesEntry\x1a:\n\nFilesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\x1b\n\x05value\x18\x02 \x01(\x0b\x32\x0c.FileHistory:\x02\x38\x01\"<\n\tLineStats\x12\r\n\x05\x61\x64\x64\x65\x64\x18\x01 \x01(\x05\x12\x0f\n\x07removed\x18\x02 \x01(\x05\x12\x0f\n\x07\x63hanged\x18\x03 \x01(\x05\"\x9d\x01\n\x06\x44\x65vDay\x12\x0f\n\x07\x63ommits\x18\x01 \x01(\x05\x12\x19\n\x05stats\x18\x02 \x01(\x0b\x32\n.LineStats\x12)\n\tlanguages\x18\x03 \x03(\x0b\x32\x16.DevDay.LanguagesEntry\x1a<\n\x0eLanguagesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\x19\n\x05value\x18\x02 \x01(\x0b\x32\n.LineStats:\x02\x38\x01\"a\n\x07\x44\x61yDevs\x12 \n\x04\x64\x65vs\x18\x01 \x03(\x0b\x32\x12.DayDevs.DevsEntry\x1a\x34\n\tDevsEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\x16\n\x05value\x18\x02 \x01(\x0b\x32\x07.DevDay:\x02\x38\x01\"\x8d\x01\n\x13\x44\x65vsAnalysisResults\x12,\n\x04\x64\x61ys\x18\x01 \x03(\x0b\x32\x1e.DevsAnalysisResults.DaysEntry\x12\x11\n\tdev_index\x18\x02 \x03(\t\x1a\x35\n\tDaysEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\x17\n\x05value\x18\x02 \x01(\x0b\x32\x08.DayDevs:\x02\x38\x01\"=\n\tSentiment\x12\r\n\x05value\x18\x01 \x01(\x02\x12\x10\n\x08\x63omments\x18\x02 \x03(\t\x12\x0f\n\x07\x63ommits\x18\x03 \x03(\t\"\xa4\x01\n\x17\x43ommentSentimentResults\x12\x46\n\x10sentiment_by_day\x18\x01 \x03(\x0b\x32,.CommentSentimentResults.SentimentByDayEntry\x1a\x41\n\x13SentimentByDayEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\x19\n\x05value\x18\x02 \x01(\x0b\x32\n.Sentiment:\x02\x38\x01\"S\n\nCommitFile\x12\n\n\x02to\x18\x01 \x01(\t\x12\x0c\n\x04\x66rom\x18\x02 \x01(\t\x12\x10\n\x08language\x18\x03 \x01(\t\x12\x19\n\x05stats\x18\x04 \x01(\x0b\x32\n.LineStats\"Z\n\x06\x43ommit\x12\x0c\n\x04hash\x18\x01 \x01(\t\x12\x16\n\x0ewhen_unix_time\x18\x02 \x01(\x03\x12\x0e\n\x06\x61uthor\x18\x03 \x01(\x05\x12\x1a\n\x05\x66iles\x18\x04 \x03(\x0b\x32\x0b.CommitFile\"H\n\x16\x43ommitsAnalysisResults\x12\x18\n\x07\x63ommits\x18\x01 \x03(\x0b\x32\x07.Commit\x12\x14\n\x0c\x61uthor_index\x18\x02 \x03(\t\"\x8f\x01\n\x0f\x41nalysisResults\x12\x19\n\x06header\x18\x01 \x01(\x0b\x32\t.Metadata\x12\x30\n\x08\x63ontents\x18\x02 \x03(\x0b\x32\x1e.AnalysisResults.ContentsEntry\x1a/\n\rContentsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x0c:\x02\x38\x01\x62\x06proto3')
+  serialized_pb=_b('\n\x08pb.proto\"\xd6\x02\n\x08Metadata\x12\x0f\n\x07version\x18\x01 \x01(\x05\x12\x0c\n\x04hash\x18\x02 \x01(\t\x12\x12\n\nrepository\x18\x03 \x01(\t\x12\x17\n\x0f\x62\x65gin_unix_time\x18\x04 \x01(\x03\x12\x15\n\rend_unix_time\x18\x05 \x01(\x03\x12\x0f\n\x07\x63ommits\x18\x06 \x01(\x05\x12\x10\n\x08run_time\x18\x07 \x01(\x03\x12\x38\n\x11run_time_per_item\x18\x08 \x03(\x0b\x32\x1d.Metadata.RunTimePerItemEntry\x12\x11\n\ttick_size\x18\t \x01(\x03\x12\x18\n\x10include_patterns\x18\n \x03(\t\x12\x18\n\x10\x65xclude_patterns\x18\x0b \x03(\t\x12\x0c\n\x04\x62ots\x18\x0c \x03(\t\x1a\x35\n\x13RunTimePerItemEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x01:\x02\x38\x01\"*\n\x17\x42urndownSparseMatrixRow\x12\x0f\n\x07\x63olumns\x18\x01 \x03(\r\"\x7f\n\x14\x42urndownSparseMatrix\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x16\n\x0enumber_of_rows\x18\x02 \x01(\x05\x12\x19\n\x11number_of_columns\x18\x03 \x01(\x05\x12&\n\x04rows\x18\x04 \x03(\x0b\x32\x18.BurndownSparseMatrixRow\"i\n\x0e\x46ilesOwnership\x12)\n\x05value\x18\x01 \x03(\x0b\x32\x1a.FilesOwnership.ValueEntry\x1a,\n\nValueEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\r\n\x05value\x18\x02 \x01(\x05:\x02\x38\x01\"\xf4\x02\n\x17\x42urndownAnalysisResults\x12\x13\n\x0bgranularity\x18\x01 \x01(\x05\x12\x10\n\x08sampling\x18\x02 \x01(\x05\x12&\n\x07project\x18\x03 \x01(\x0b\x32\x15.BurndownSparseMatrix\x12$\n\x05\x66iles\x18\x04 \x03(\x0b\x32\x15.BurndownSparseMatrix\x12%\n\x06people\x18\x05 \x03(\x0b\x32\x15.BurndownSparseMatrix\x12\x36\n\x12people_interaction\x18\x06 \x01(\x0b\x32\x1a.CompressedSparseRowMatrix\x12(\n\x0f\x66iles_ownership\x18\x07 \x03(\x0b\x32\x0f.FilesOwnership\x12$\n\x05teams\x18\x08 \x03(\x0b\x32\x15.BurndownSparseMatrix\x12\x35\n\x11teams_interaction\x18\t \x01(\x0b\x32\x1a.CompressedSparseRowMatrix\"}\n\x19\x43ompressedSparseRowMatrix\x12\x16\n\x0enumber_of_rows\x18\x01 \x01(\x05\x12\x19\n\x11number_of_columns\x18\x02 \x01(\x05\x12\x0c\n\x04\x64\x61ta\x18\x03 \x03(\x03\x12\x0f\n\x07indices\x18\x04 \x03(\x05\x12\x0e\n\x06indptr\x18\x05 \x03(\x03\"D\n\x07\x43ouples\x12\r\n\x05index\x18\x01 \x03(\t\x12*\n\x06matrix\x18\x02 \x01(\x0b\x32\x1a.CompressedSparseRowMatrix\"\x1d\n\x0cTouchedFiles\x12\r\n\x05\x66iles\x18\x01 \x03(\x05\"\xd7\x01\n\x16\x43ouplesAnalysisResults\x12\x1e\n\x0c\x66ile_couples\x18\x06 \x01(\x0b\x32\x08.Couples\x12 \n\x0epeople_couples\x18\x07 \x01(\x0b\x32\x08.Couples\x12#\n\x0cpeople_files\x18\x08 \x03(\x0b\x32\r.TouchedFiles\x12\x13\n\x0b\x66iles_lines\x18\t \x03(\x05\x12\x1e\n\x0cteam_couples\x18\n \x01(\x0b\x32\x08.Couples\x12!\n\nteam_files\x18\x0b \x03(\x0b\x32\r.TouchedFiles\"o\n\nUASTChange\x12\x11\n\tfile_name\x18\x01 \x01(\t\x12\x12\n\nsrc_before\x18\x02 \x01(\t\x12\x11\n\tsrc_after\x18\x03 \x01(\t\x12\x13\n\x0buast_before\x18\x04 \x01(\t\x12\x12\n\nuast_after\x18\x05 \x01(\t\"7\n\x17UASTChangesSaverResults\x12\x1c\n\x07\x63hanges\x18\x01 \x03(\x0b\x32\x0b.UASTChange\"\x9c\x01\n\x0eShotnessRecord\x12\x0c\n\x04type\x18\x01 \x01(\t\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\x0c\n\x04\x66ile\x18\x03 \x01(\t\x12/\n\x08\x63ounters\x18\x04 \x03(\x0b\x32\x1d.ShotnessRecord.CountersEntry\x1a/\n\rCountersEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\r\n\x05value\x18\x02 \x01(\x05:\x02\x38\x01\";\n\x17ShotnessAnalysisResults\x12 \n\x07records\x18\x01 \x03(\x0b\x32\x0f.ShotnessRecord\"\x1e\n\x0b\x46ileHistory\x12\x0f\n\x07\x63ommits\x18\x01 \x03(\t\"\x8b\x01\n\x18\x46ileHistoryResultMessage\x12\x33\n\x05\x66iles\x18\x01 \x03(\x0b\x32$.FileHistoryResultMessage.FilesEntry\x1a:\n\nFilesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\x1b\n\x05value\x18\x02 \x01(\x0b\x32\x0c.FileHistory:\x02\x38\x01\"K\n\tLineStats\x12\r\n\x05\x61\x64\x64\x65\x64\x18\x01 \x01(\x05\x12\x0f\n\x07removed\x18\x02 \x01(\x05\x12\x0f\n\x07\x63hanged\x18\x03 \x01(\x05\x12\r\n\x05moved\x18\x04 \x01(\x05\"\x9d\x01\n\x06\x44\x65vDay\x12\x0f\n\x07\x63ommits\x18\x01 \x01(\x05\x12\x19\n\x05stats\x18\x02 \x01(\x0b\x32\n.LineStats\x12)\n\tlanguages\x18\x03 \x03(\x0b\x32\x16.DevDay.LanguagesEntry\x1a<\n\x0eLanguagesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\x19\n\x05value\x18\x02 \x01(\x0b\x32\n.LineStats:\x02\x38\x01\"a\n\x07\x44\x61yDevs\x12 \n\x04\x64\x65vs\x18\x01 \x03(\x0b\x32\x12.DayDevs.DevsEntry\x1a\x34\n\tDevsEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\x16\n\x05value\x18\x02 \x01(\x0b\x32\x07.DevDay:\x02\x38\x01\"\x93\x02\n\x13\x44\x65vsAnalysisResults\x12,\n\x04\x64\x61ys\x18\x01 \x03(\x0b\x32\x1e.DevsAnalysisResults.DaysEntry\x12\x11\n\tdev_index\x18\x02 \x03(\t\x12\x35\n\tteam_days\x18\x03 \x03(\x0b\x32\".DevsAnalysisResults.TeamDaysEntry\x12\x12\n\nteam_index\x18\x04 \x03(\t\x1a\x35\n\tDaysEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\x17\n\x05value\x18\x02 \x01(\x0b\x32\x08.DayDevs:\x02\x38\x01\x1a\x39\n\rTeamDaysEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\x17\n\x05value\x18\x02 \x01(\x0b\x32\x08.DayDevs:\x02\x38\x01\"=\n\tSentiment\x12\r\n\x05value\x18\x01 \x01(\x02\x12\x10\n\x08\x63omments\x18\x02 \x03(\t\x12\x0f\n\x07\x63ommits\x18\x03 \x03(\t\"\xa4\x01\n\x17\x43ommentSentimentResults\x12\x46\n\x10sentiment_by_day\x18\x01 \x03(\x0b\x32,.CommentSentimentResults.SentimentByDayEntry\x1a\x41\n\x13SentimentByDayEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\x19\n\x05value\x18\x02 \x01(\x0b\x32\n.Sentiment:\x02\x38\x01\"S\n\nCommitFile\x12\n\n\x02to\x18\x01 \x01(\t\x12\x0c\n\x04\x66rom\x18\x02 \x01(\t\x12\x10\n\x08language\x18\x03 \x01(\t\x12\x19\n\x05stats\x18\x04 \x01(\x0b\x32\n.LineStats\"i\n\x06\x43ommit\x12\x0c\n\x04hash\x18\x01 \x01(\t\x12\x16\n\x0ewhen_unix_time\x18\x02 \x01(\x03\x12\x0e\n\x06\x61uthor\x18\x03 \x01(\x05\x12\x1a\n\x05\x66iles\x18\x04 \x03(\x0b\x32\x0b.CommitFile\x12\r\n\x05merge\x18\x05 \x01(\x08\"H\n\x16\x43ommitsAnalysisResults\x12\x18\n\x07\x63ommits\x18\x01 \x03(\x0b\x32\x07.Commit\x12\x14\n\x0c\x61uthor_index\x18\x02 \x03(\t\"\x8f\x01\n\x0f\x41nalysisResults\x12\x19\n\x06header\x18\x01 \x01(\x0b\x32\t.Metadata\x12\x30\n\x08\x63ontents\x18\x02 \x03(\x0b\x32\x1e.AnalysisResults.ContentsEntry\x1a/\n\rContentsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\x0c:\x02\x38\x01\"\xb7\x01\n\x0f\x41nalysisRequest\x12\x12\n\nrepository\x18\x01 \x01(\t\x12\x0e\n\x06leaves\x18\x02 \x03(\t\x12*\n\x05\x66\x61\x63ts\x18\x03 \x03(\x0b\x32\x1b.AnalysisRequest.FactsEntry\x12\x10\n\x08\x66\x65\x61tures\x18\x04 \x03(\t\x12\x14\n\x0c\x66irst_parent\x18\x05 \x01(\x08\x1a,\n\nFactsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"?\n\x10\x41nalysisProgress\x12\x0c\n\x04step\x18\x01 \x01(\x05\x12\r\n\x05total\x18\x02 \x01(\x05\x12\x0e\n\x06\x61\x63tion\x18\x03 \x01(\t\"X\n\x0e\x41nalysisUpdate\x12#\n\x08progress\x18\x01 \x01(\x0b\x32\x11.AnalysisProgress\x12!\n\x07results\x18\x02 \x01(\x0b\x32\x10.AnalysisResults\"\x82\x01\n\x0fOwnershipRecord\x12,\n\x06owners\x18\x01 \x03(\x0b\x32\x1c.OwnershipRecord.OwnersEntry\x12\x12\n\nbus_factor\x18\x02 \x01(\x05\x1a-\n\x0bOwnersEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\r\n\x05value\x18\x02 \x01(\x05:\x02\x38\x01\"\xeb\x03\n\x18OwnershipAnalysisResults\x12\x33\n\x05\x66iles\x18\x01 \x03(\x0b\x32$.OwnershipAnalysisResults.FilesEntry\x12?\n\x0b\x64irectories\x18\x02 \x03(\x0b\x32*.OwnershipAnalysisResults.DirectoriesEntry\x12I\n\x11last_commit_times\x18\x03 \x03(\x0b\x32..OwnershipAnalysisResults.LastCommitTimesEntry\x12\x10\n\x08\x65nd_time\x18\x04 \x01(\x03\x12\x13\n\x0borphan_days\x18\x05 \x01(\x05\x12\x16\n\x0eorphaned_files\x18\x06 \x03(\t\x12\x11\n\tdev_index\x18\x07 \x03(\t\x1a>\n\nFilesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\x1f\n\x05value\x18\x02 \x01(\x0b\x32\x10.OwnershipRecord:\x02\x38\x01\x1a\x44\n\x10\x44irectoriesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\x1f\n\x05value\x18\x02 \x01(\x0b\x32\x10.OwnershipRecord:\x02\x38\x01\x1a\x36\n\x14LastCommitTimesEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\r\n\x05value\x18\x02 \x01(\x03:\x02\x38\x01\"r\n\x0f\x43ommitClassDays\x12(\n\x04\x64\x61ys\x18\x01 \x03(\x0b\x32\x1a.CommitClassDays.DaysEntry\x1a\x35\n\tDaysEntry\x12\x0b\n\x03key\x18\x01 \x01(\x05\x12\x17\n\x05value\x18\x02 \x01(\x0b\x32\x08.DayDevs:\x02\x38\x01\"\xb0\x01\n\x1c\x43ommitClassesAnalysisResults\x12;\n\x07\x63lasses\x18\x01 \x03(\x0b\x32*.CommitClassesAnalysisResults.ClassesEntry\x12\x11\n\tdev_index\x18\x02 \x03(\t\x1a@\n\x0c\x43lassesEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\x1f\n\x05value\x18\x02 \x01(\x0b\x32\x10.CommitClassDays:\x02\x38\x01\x32:\n\x08Hercules\x12.\n\x07\x41nalyse\x12\x10.AnalysisRequest\x1a\x0f.AnalysisUpdate0\x01\x62\x06proto3')
 )
 
 
@@ -58,8 +58,8 @@ _METADATA_RUNTIMEPERITEMENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=217,
-  serialized_end=270,
+  serialized_start=302,
+  serialized_end=355,
 )
 
 _METADATA = _descriptor.Descriptor(
@@ -125,6 +125,34 @@ _METADATA = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='tick_size', full_name='Metadata.tick_size', index=8,
+      number=9, type=3, cpp_type=2, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='include_patterns', full_name='Metadata.include_patterns', index=9,
+      number=10, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='exclude_patterns', full_name='Metadata.exclude_patterns', index=10,
+      number=11, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='bots', full_name='Metadata.bots', index=11,
+      number=12, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -138,7 +166,7 @@ _METADATA = _descriptor.Descriptor(
   oneofs=[
   ],
   serialized_start=13,
-  serialized_end=270,
+  serialized_end=355,
 )
 
 
@@ -168,8 +196,8 @@ _BURNDOWNSPARSEMATRIXROW = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=272,
-  serialized_end=314,
+  serialized_start=357,
+  serialized_end=399,
 )
 
 
@@ -220,8 +248,8 @@ _BURNDOWNSPARSEMATRIX = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=316,
-  serialized_end=443,
+  serialized_start=401,
+  serialized_end=528,
 )
 
 
@@ -258,8 +286,8 @@ _FILESOWNERSHIP_VALUEENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=506,
-  serialized_end=550,
+  serialized_start=591,
+  serialized_end=635,
 )
 
 _FILESOWNERSHIP = _descriptor.Descriptor(
@@ -288,8 +316,8 @@ _FILESOWNERSHIP = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=445,
-  serialized_end=550,
+  serialized_start=530,
+  serialized_end=635,
 )
 
 
@@ -349,6 +377,20 @@ _BURNDOWNANALYSISRESULTS = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='teams', full_name='BurndownAnalysisResults.teams', index=7,
+      number=8, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='teams_interaction', full_name='BurndownAnalysisResults.teams_interaction', index=8,
+      number=9, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -361,8 +403,8 @@ _BURNDOWNANALYSISRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=553,
-  serialized_end=832,
+  serialized_start=638,
+  serialized_end=1010,
 )
 
 
@@ -420,8 +462,8 @@ _COMPRESSEDSPARSEROWMATRIX = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=834,
-  serialized_end=959,
+  serialized_start=1012,
+  serialized_end=1137,
 )
 
 
@@ -458,8 +500,8 @@ _COUPLES = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=961,
-  serialized_end=1029,
+  serialized_start=1139,
+  serialized_end=1207,
 )
 
 
@@ -489,8 +531,8 @@ _TOUCHEDFILES = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1031,
-  serialized_end=1060,
+  serialized_start=1209,
+  serialized_end=1238,
 )
 
 
@@ -529,6 +571,20 @@ _COUPLESANALYSISRESULTS = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='team_couples', full_name='CouplesAnalysisResults.team_couples', index=4,
+      number=10, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='team_files', full_name='CouplesAnalysisResults.team_files', index=5,
+      number=11, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -541,8 +597,8 @@ _COUPLESANALYSISRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1063,
-  serialized_end=1211,
+  serialized_start=1241,
+  serialized_end=1456,
 )
 
 
@@ -600,8 +656,8 @@ _UASTCHANGE = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1213,
-  serialized_end=1324,
+  serialized_start=1458,
+  serialized_end=1569,
 )
 
 
@@ -631,8 +687,8 @@ _UASTCHANGESSAVERRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1326,
-  serialized_end=1381,
+  serialized_start=1571,
+  serialized_end=1626,
 )
 
 
@@ -669,8 +725,8 @@ _SHOTNESSRECORD_COUNTERSENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1493,
-  serialized_end=1540,
+  serialized_start=1738,
+  serialized_end=1785,
 )
 
 _SHOTNESSRECORD = _descriptor.Descriptor(
@@ -720,8 +776,8 @@ _SHOTNESSRECORD = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1384,
-  serialized_end=1540,
+  serialized_start=1629,
+  serialized_end=1785,
 )
 
 
@@ -751,8 +807,8 @@ _SHOTNESSANALYSISRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1542,
-  serialized_end=1601,
+  serialized_start=1787,
+  serialized_end=1846,
 )
 
 
@@ -782,8 +838,8 @@ _FILEHISTORY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1603,
-  serialized_end=1633,
+  serialized_start=1848,
+  serialized_end=1878,
 )
 
 
@@ -820,8 +876,8 @@ _FILEHISTORYRESULTMESSAGE_FILESENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1717,
-  serialized_end=1775,
+  serialized_start=1962,
+  serialized_end=2020,
 )
 
 _FILEHISTORYRESULTMESSAGE = _descriptor.Descriptor(
@@ -850,8 +906,8 @@ _FILEHISTORYRESULTMESSAGE = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1636,
-  serialized_end=1775,
+  serialized_start=1881,
+  serialized_end=2020,
 )
 
 
@@ -883,6 +939,13 @@ _LINESTATS = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='moved', full_name='LineStats.moved', index=3,
+      number=4, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -895,8 +958,8 @@ _LINESTATS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1777,
-  serialized_end=1837,
+  serialized_start=2022,
+  serialized_end=2097,
 )
 
 
@@ -933,8 +996,8 @@ _DEVDAY_LANGUAGESENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1937,
-  serialized_end=1997,
+  serialized_start=2197,
+  serialized_end=2257,
 )
 
 _DEVDAY = _descriptor.Descriptor(
@@ -977,8 +1040,8 @@ _DEVDAY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1840,
-  serialized_end=1997,
+  serialized_start=2100,
+  serialized_end=2257,
 )
 
 
@@ -1015,8 +1078,8 @@ _DAYDEVS_DEVSENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=2044,
-  serialized_end=2096,
+  serialized_start=2304,
+  serialized_end=2356,
 )
 
 _DAYDEVS = _descriptor.Descriptor(
@@ -1045,8 +1108,8 @@ _DAYDEVS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=1999,
-  serialized_end=2096,
+  serialized_start=2259,
+  serialized_end=2356,
 )
 
 
@@ -1083,8 +1146,45 @@ _DEVSANALYSISRESULTS_DAYSENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=2187,
-  serialized_end=2240,
+  serialized_start=2522,
+  serialized_end=2575,
+)
+
+_DEVSANALYSISRESULTS_TEAMDAYSENTRY = _descriptor.Descriptor(
+  name='TeamDaysEntry',
+  full_name='DevsAnalysisResults.TeamDaysEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='DevsAnalysisResults.TeamDaysEntry.key', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='DevsAnalysisResults.TeamDaysEntry.value', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=_b('8\001'),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=2577,
+  serialized_end=2634,
 )
 
 _DEVSANALYSISRESULTS = _descriptor.Descriptor(
@@ -1108,10 +1208,24 @@ _DEVSANALYSISRESULTS = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='team_days', full_name='DevsAnalysisResults.team_days', index=2,
+      number=3, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='team_index', full_name='DevsAnalysisResults.team_index', index=3,
+      number=4, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
-  nested_types=[_DEVSANALYSISRESULTS_DAYSENTRY, ],
+  nested_types=[_DEVSANALYSISRESULTS_DAYSENTRY, _DEVSANALYSISRESULTS_TEAMDAYSENTRY, ],
   enum_types=[
   ],
   serialized_options=None,
@@ -1120,8 +1234,8 @@ _DEVSANALYSISRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=2099,
-  serialized_end=2240,
+  serialized_start=2359,
+  serialized_end=2634,
 )
 
 
@@ -1165,8 +1279,8 @@ _SENTIMENT = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=2242,
-  serialized_end=2303,
+  serialized_start=2636,
+  serialized_end=2697,
 )
 
 
@@ -1203,8 +1317,8 @@ _COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=2405,
-  serialized_end=2470,
+  serialized_start=2799,
+  serialized_end=2864,
 )
 
 _COMMENTSENTIMENTRESULTS = _descriptor.Descriptor(
@@ -1233,8 +1347,8 @@ _COMMENTSENTIMENTRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=2306,
-  serialized_end=2470,
+  serialized_start=2700,
+  serialized_end=2864,
 )
 
 
@@ -1285,8 +1399,8 @@ _COMMITFILE = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=2472,
-  serialized_end=2555,
+  serialized_start=2866,
+  serialized_end=2949,
 )
 
 
@@ -1325,6 +1439,13 @@ _COMMIT = _descriptor.Descriptor(
       message_type=None, enum_type=None, containing_type=None,
       is_extension=False, extension_scope=None,
       serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='merge', full_name='Commit.merge', index=4,
+      number=5, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
   ],
   extensions=[
   ],
@@ -1337,8 +1458,8 @@ _COMMIT = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=2557,
-  serialized_end=2647,
+  serialized_start=2951,
+  serialized_end=3056,
 )
 
 
@@ -1375,8 +1496,8 @@ _COMMITSANALYSISRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=2649,
-  serialized_end=2721,
+  serialized_start=3058,
+  serialized_end=3130,
 )
 
 
@@ -1413,8 +1534,8 @@ _ANALYSISRESULTS_CONTENTSENTRY = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=2820,
-  serialized_end=2867,
+  serialized_start=3229,
+  serialized_end=3276,
 )
 
 _ANALYSISRESULTS = _descriptor.Descriptor(
@@ -1450,105 +1571,712 @@ _ANALYSISRESULTS = _descriptor.Descriptor(
   extension_ranges=[],
   oneofs=[
   ],
-  serialized_start=2724,
-  serialized_end=2867,
+  serialized_start=3133,
+  serialized_end=3276,
 )
 
-_METADATA_RUNTIMEPERITEMENTRY.containing_type = _METADATA
-_METADATA.fields_by_name['run_time_per_item'].message_type = _METADATA_RUNTIMEPERITEMENTRY
-_BURNDOWNSPARSEMATRIX.fields_by_name['rows'].message_type = _BURNDOWNSPARSEMATRIXROW
-_FILESOWNERSHIP_VALUEENTRY.containing_type = _FILESOWNERSHIP
-_FILESOWNERSHIP.fields_by_name['value'].message_type = _FILESOWNERSHIP_VALUEENTRY
-_BURNDOWNANALYSISRESULTS.fields_by_name['project'].message_type = _BURNDOWNSPARSEMATRIX
-_BURNDOWNANALYSISRESULTS.fields_by_name['files'].message_type = _BURNDOWNSPARSEMATRIX
-_BURNDOWNANALYSISRESULTS.fields_by_name['people'].message_type = _BURNDOWNSPARSEMATRIX
-_BURNDOWNANALYSISRESULTS.fields_by_name['people_interaction'].message_type = _COMPRESSEDSPARSEROWMATRIX
-_BURNDOWNANALYSISRESULTS.fields_by_name['files_ownership'].message_type = _FILESOWNERSHIP
-_COUPLES.fields_by_name['matrix'].message_type = _COMPRESSEDSPARSEROWMATRIX
-_COUPLESANALYSISRESULTS.fields_by_name['file_couples'].message_type = _COUPLES
-_COUPLESANALYSISRESULTS.fields_by_name['people_couples'].message_type = _COUPLES
-_COUPLESANALYSISRESULTS.fields_by_name['people_files'].message_type = _TOUCHEDFILES
-_UASTCHANGESSAVERRESULTS.fields_by_name['changes'].message_type = _UASTCHANGE
-_SHOTNESSRECORD_COUNTERSENTRY.containing_type = _SHOTNESSRECORD
-_SHOTNESSRECORD.fields_by_name['counters'].message_type = _SHOTNESSRECORD_COUNTERSENTRY
-_SHOTNESSANALYSISRESULTS.fields_by_name['records'].message_type = _SHOTNESSRECORD
-_FILEHISTORYRESULTMESSAGE_FILESENTRY.fields_by_name['value'].message_type = _FILEHISTORY
-_FILEHISTORYRESULTMESSAGE_FILESENTRY.containing_type = _FILEHISTORYRESULTMESSAGE
-_FILEHISTORYRESULTMESSAGE.fields_by_name['files'].message_type = _FILEHISTORYRESULTMESSAGE_FILESENTRY
-_DEVDAY_LANGUAGESENTRY.fields_by_name['value'].message_type = _LINESTATS
-_DEVDAY_LANGUAGESENTRY.containing_type = _DEVDAY
-_DEVDAY.fields_by_name['stats'].message_type = _LINESTATS
-_DEVDAY.fields_by_name['languages'].message_type = _DEVDAY_LANGUAGESENTRY
-_DAYDEVS_DEVSENTRY.fields_by_name['value'].message_type = _DEVDAY
-_DAYDEVS_DEVSENTRY.containing_type = _DAYDEVS
-_DAYDEVS.fields_by_name['devs'].message_type = _DAYDEVS_DEVSENTRY
-_DEVSANALYSISRESULTS_DAYSENTRY.fields_by_name['value'].message_type = _DAYDEVS
-_DEVSANALYSISRESULTS_DAYSENTRY.containing_type = _DEVSANALYSISRESULTS
-_DEVSANALYSISRESULTS.fields_by_name['days'].message_type = _DEVSANALYSISRESULTS_DAYSENTRY
-_COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY.fields_by_name['value'].message_type = _SENTIMENT
-_COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY.containing_type = _COMMENTSENTIMENTRESULTS
-_COMMENTSENTIMENTRESULTS.fields_by_name['sentiment_by_day'].message_type = _COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY
-_COMMITFILE.fields_by_name['stats'].message_type = _LINESTATS
-_COMMIT.fields_by_name['files'].message_type = _COMMITFILE
-_COMMITSANALYSISRESULTS.fields_by_name['commits'].message_type = _COMMIT
-_ANALYSISRESULTS_CONTENTSENTRY.containing_type = _ANALYSISRESULTS
-_ANALYSISRESULTS.fields_by_name['header'].message_type = _METADATA
-_ANALYSISRESULTS.fields_by_name['contents'].message_type = _ANALYSISRESULTS_CONTENTSENTRY
-DESCRIPTOR.message_types_by_name['Metadata'] = _METADATA
-DESCRIPTOR.message_types_by_name['BurndownSparseMatrixRow'] = _BURNDOWNSPARSEMATRIXROW
-DESCRIPTOR.message_types_by_name['BurndownSparseMatrix'] = _BURNDOWNSPARSEMATRIX
-DESCRIPTOR.message_types_by_name['FilesOwnership'] = _FILESOWNERSHIP
-DESCRIPTOR.message_types_by_name['BurndownAnalysisResults'] = _BURNDOWNANALYSISRESULTS
-DESCRIPTOR.message_types_by_name['CompressedSparseRowMatrix'] = _COMPRESSEDSPARSEROWMATRIX
-DESCRIPTOR.message_types_by_name['Couples'] = _COUPLES
-DESCRIPTOR.message_types_by_name['TouchedFiles'] = _TOUCHEDFILES
-DESCRIPTOR.message_types_by_name['CouplesAnalysisResults'] = _COUPLESANALYSISRESULTS
-DESCRIPTOR.message_types_by_name['UASTChange'] = _UASTCHANGE
-DESCRIPTOR.message_types_by_name['UASTChangesSaverResults'] = _UASTCHANGESSAVERRESULTS
-DESCRIPTOR.message_types_by_name['ShotnessRecord'] = _SHOTNESSRECORD
-DESCRIPTOR.message_types_by_name['ShotnessAnalysisResults'] = _SHOTNESSANALYSISRESULTS
-DESCRIPTOR.message_types_by_name['FileHistory'] = _FILEHISTORY
-DESCRIPTOR.message_types_by_name['FileHistoryResultMessage'] = _FILEHISTORYRESULTMESSAGE
-DESCRIPTOR.message_types_by_name['LineStats'] = _LINESTATS
-DESCRIPTOR.message_types_by_name['DevDay'] = _DEVDAY
-DESCRIPTOR.message_types_by_name['DayDevs'] = _DAYDEVS
-DESCRIPTOR.message_types_by_name['DevsAnalysisResults'] = _DEVSANALYSISRESULTS
-DESCRIPTOR.message_types_by_name['Sentiment'] = _SENTIMENT
-DESCRIPTOR.message_types_by_name['CommentSentimentResults'] = _COMMENTSENTIMENTRESULTS
-DESCRIPTOR.message_types_by_name['CommitFile'] = _COMMITFILE
-DESCRIPTOR.message_types_by_name['Commit'] = _COMMIT
-DESCRIPTOR.message_types_by_name['CommitsAnalysisResults'] = _COMMITSANALYSISRESULTS
-DESCRIPTOR.message_types_by_name['AnalysisResults'] = _ANALYSISRESULTS
-_sym_db.RegisterFileDescriptor(DESCRIPTOR)
-
-Metadata = _reflection.GeneratedProtocolMessageType('Metadata', (_message.Message,), dict(
-
-  RunTimePerItemEntry = _reflection.GeneratedProtocolMessageType('RunTimePerItemEntry', (_message.Message,), dict(
-    DESCRIPTOR = _METADATA_RUNTIMEPERITEMENTRY,
-    __module__ = 'pb_pb2'
-    # @@protoc_insertion_point(class_scope:Metadata.RunTimePerItemEntry)
-    ))
-  ,
-  DESCRIPTOR = _METADATA,
-  __module__ = 'pb_pb2'
-  # @@protoc_insertion_point(class_scope:Metadata)
-  ))
-_sym_db.RegisterMessage(Metadata)
-_sym_db.RegisterMessage(Metadata.RunTimePerItemEntry)
+_ANALYSISREQUEST_FACTSENTRY = _descriptor.Descriptor(
+  name='FactsEntry',
+  full_name='AnalysisRequest.FactsEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='AnalysisRequest.FactsEntry.key', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='AnalysisRequest.FactsEntry.value', index=1,
+      number=2, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=_b('8\001'),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3418,
+  serialized_end=3462,
+)
 
-BurndownSparseMatrixRow = _reflection.GeneratedProtocolMessageType('BurndownSparseMatrixRow', (_message.Message,), dict(
-  DESCRIPTOR = _BURNDOWNSPARSEMATRIXROW,
-  __module__ = 'pb_pb2'
-  # @@protoc_insertion_point(class_scope:BurndownSparseMatrixRow)
-  ))
-_sym_db.RegisterMessage(BurndownSparseMatrixRow)
+_ANALYSISREQUEST = _descriptor.Descriptor(
+  name='AnalysisRequest',
+  full_name='AnalysisRequest',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='repository', full_name='AnalysisRequest.repository', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='leaves', full_name='AnalysisRequest.leaves', index=1,
+      number=2, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='facts', full_name='AnalysisRequest.facts', index=2,
+      number=3, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='features', full_name='AnalysisRequest.features', index=3,
+      number=4, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='first_parent', full_name='AnalysisRequest.first_parent', index=4,
+      number=5, type=8, cpp_type=7, label=1,
+      has_default_value=False, default_value=False,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[_ANALYSISREQUEST_FACTSENTRY, ],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3279,
+  serialized_end=3462,
+)
 
-BurndownSparseMatrix = _reflection.GeneratedProtocolMessageType('BurndownSparseMatrix', (_message.Message,), dict(
-  DESCRIPTOR = _BURNDOWNSPARSEMATRIX,
-  __module__ = 'pb_pb2'
-  # @@protoc_insertion_point(class_scope:BurndownSparseMatrix)
-  ))
-_sym_db.RegisterMessage(BurndownSparseMatrix)
+_ANALYSISPROGRESS = _descriptor.Descriptor(
+  name='AnalysisProgress',
+  full_name='AnalysisProgress',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='step', full_name='AnalysisProgress.step', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='total', full_name='AnalysisProgress.total', index=1,
+      number=2, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='action', full_name='AnalysisProgress.action', index=2,
+      number=3, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3464,
+  serialized_end=3527,
+)
+
+_ANALYSISUPDATE = _descriptor.Descriptor(
+  name='AnalysisUpdate',
+  full_name='AnalysisUpdate',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='progress', full_name='AnalysisUpdate.progress', index=0,
+      number=1, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='results', full_name='AnalysisUpdate.results', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3529,
+  serialized_end=3617,
+)
+
+_OWNERSHIPRECORD_OWNERSENTRY = _descriptor.Descriptor(
+  name='OwnersEntry',
+  full_name='OwnershipRecord.OwnersEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='OwnershipRecord.OwnersEntry.key', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='OwnershipRecord.OwnersEntry.value', index=1,
+      number=2, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=_b('8\001'),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3705,
+  serialized_end=3750,
+)
+
+_OWNERSHIPRECORD = _descriptor.Descriptor(
+  name='OwnershipRecord',
+  full_name='OwnershipRecord',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='owners', full_name='OwnershipRecord.owners', index=0,
+      number=1, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='bus_factor', full_name='OwnershipRecord.bus_factor', index=1,
+      number=2, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[_OWNERSHIPRECORD_OWNERSENTRY, ],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3620,
+  serialized_end=3750,
+)
+
+_OWNERSHIPANALYSISRESULTS_FILESENTRY = _descriptor.Descriptor(
+  name='FilesEntry',
+  full_name='OwnershipAnalysisResults.FilesEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='OwnershipAnalysisResults.FilesEntry.key', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='OwnershipAnalysisResults.FilesEntry.value', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=_b('8\001'),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=4056,
+  serialized_end=4118,
+)
+
+_OWNERSHIPANALYSISRESULTS_DIRECTORIESENTRY = _descriptor.Descriptor(
+  name='DirectoriesEntry',
+  full_name='OwnershipAnalysisResults.DirectoriesEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='OwnershipAnalysisResults.DirectoriesEntry.key', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='OwnershipAnalysisResults.DirectoriesEntry.value', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=_b('8\001'),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=4120,
+  serialized_end=4188,
+)
+
+_OWNERSHIPANALYSISRESULTS_LASTCOMMITTIMESENTRY = _descriptor.Descriptor(
+  name='LastCommitTimesEntry',
+  full_name='OwnershipAnalysisResults.LastCommitTimesEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='OwnershipAnalysisResults.LastCommitTimesEntry.key', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='OwnershipAnalysisResults.LastCommitTimesEntry.value', index=1,
+      number=2, type=3, cpp_type=2, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=_b('8\001'),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=4190,
+  serialized_end=4244,
+)
+
+_OWNERSHIPANALYSISRESULTS = _descriptor.Descriptor(
+  name='OwnershipAnalysisResults',
+  full_name='OwnershipAnalysisResults',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='files', full_name='OwnershipAnalysisResults.files', index=0,
+      number=1, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='directories', full_name='OwnershipAnalysisResults.directories', index=1,
+      number=2, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='last_commit_times', full_name='OwnershipAnalysisResults.last_commit_times', index=2,
+      number=3, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='end_time', full_name='OwnershipAnalysisResults.end_time', index=3,
+      number=4, type=3, cpp_type=2, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='orphan_days', full_name='OwnershipAnalysisResults.orphan_days', index=4,
+      number=5, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='orphaned_files', full_name='OwnershipAnalysisResults.orphaned_files', index=5,
+      number=6, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='dev_index', full_name='OwnershipAnalysisResults.dev_index', index=6,
+      number=7, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[_OWNERSHIPANALYSISRESULTS_FILESENTRY, _OWNERSHIPANALYSISRESULTS_DIRECTORIESENTRY, _OWNERSHIPANALYSISRESULTS_LASTCOMMITTIMESENTRY, ],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=3753,
+  serialized_end=4244,
+)
+
+_COMMITCLASSDAYS_DAYSENTRY = _descriptor.Descriptor(
+  name='DaysEntry',
+  full_name='CommitClassDays.DaysEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='CommitClassDays.DaysEntry.key', index=0,
+      number=1, type=5, cpp_type=1, label=1,
+      has_default_value=False, default_value=0,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='CommitClassDays.DaysEntry.value', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=_b('8\001'),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=4307,
+  serialized_end=4360,
+)
+
+_COMMITCLASSDAYS = _descriptor.Descriptor(
+  name='CommitClassDays',
+  full_name='CommitClassDays',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='days', full_name='CommitClassDays.days', index=0,
+      number=1, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[_COMMITCLASSDAYS_DAYSENTRY, ],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=4246,
+  serialized_end=4360,
+)
+
+_COMMITCLASSESANALYSISRESULTS_CLASSESENTRY = _descriptor.Descriptor(
+  name='ClassesEntry',
+  full_name='CommitClassesAnalysisResults.ClassesEntry',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='key', full_name='CommitClassesAnalysisResults.ClassesEntry.key', index=0,
+      number=1, type=9, cpp_type=9, label=1,
+      has_default_value=False, default_value=_b("").decode('utf-8'),
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='value', full_name='CommitClassesAnalysisResults.ClassesEntry.value', index=1,
+      number=2, type=11, cpp_type=10, label=1,
+      has_default_value=False, default_value=None,
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[],
+  enum_types=[
+  ],
+  serialized_options=_b('8\001'),
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=4475,
+  serialized_end=4539,
+)
+
+_COMMITCLASSESANALYSISRESULTS = _descriptor.Descriptor(
+  name='CommitClassesAnalysisResults',
+  full_name='CommitClassesAnalysisResults',
+  filename=None,
+  file=DESCRIPTOR,
+  containing_type=None,
+  fields=[
+    _descriptor.FieldDescriptor(
+      name='classes', full_name='CommitClassesAnalysisResults.classes', index=0,
+      number=1, type=11, cpp_type=10, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+    _descriptor.FieldDescriptor(
+      name='dev_index', full_name='CommitClassesAnalysisResults.dev_index', index=1,
+      number=2, type=9, cpp_type=9, label=3,
+      has_default_value=False, default_value=[],
+      message_type=None, enum_type=None, containing_type=None,
+      is_extension=False, extension_scope=None,
+      serialized_options=None, file=DESCRIPTOR),
+  ],
+  extensions=[
+  ],
+  nested_types=[_COMMITCLASSESANALYSISRESULTS_CLASSESENTRY, ],
+  enum_types=[
+  ],
+  serialized_options=None,
+  is_extendable=False,
+  syntax='proto3',
+  extension_ranges=[],
+  oneofs=[
+  ],
+  serialized_start=4363,
+  serialized_end=4539,
+)
+_METADATA_RUNTIMEPERITEMENTRY.containing_type = _METADATA
+_METADATA.fields_by_name['run_time_per_item'].message_type = _METADATA_RUNTIMEPERITEMENTRY
+_BURNDOWNSPARSEMATRIX.fields_by_name['rows'].message_type = _BURNDOWNSPARSEMATRIXROW
+_FILESOWNERSHIP_VALUEENTRY.containing_type = _FILESOWNERSHIP
+_FILESOWNERSHIP.fields_by_name['value'].message_type = _FILESOWNERSHIP_VALUEENTRY
+_BURNDOWNANALYSISRESULTS.fields_by_name['project'].message_type = _BURNDOWNSPARSEMATRIX
+_BURNDOWNANALYSISRESULTS.fields_by_name['files'].message_type = _BURNDOWNSPARSEMATRIX
+_BURNDOWNANALYSISRESULTS.fields_by_name['people'].message_type = _BURNDOWNSPARSEMATRIX
+_BURNDOWNANALYSISRESULTS.fields_by_name['people_interaction'].message_type = _COMPRESSEDSPARSEROWMATRIX
+_BURNDOWNANALYSISRESULTS.fields_by_name['files_ownership'].message_type = _FILESOWNERSHIP
+_BURNDOWNANALYSISRESULTS.fields_by_name['teams'].message_type = _BURNDOWNSPARSEMATRIX
+_BURNDOWNANALYSISRESULTS.fields_by_name['teams_interaction'].message_type = _COMPRESSEDSPARSEROWMATRIX
+_COUPLES.fields_by_name['matrix'].message_type = _COMPRESSEDSPARSEROWMATRIX
+_COUPLESANALYSISRESULTS.fields_by_name['file_couples'].message_type = _COUPLES
+_COUPLESANALYSISRESULTS.fields_by_name['people_couples'].message_type = _COUPLES
+_COUPLESANALYSISRESULTS.fields_by_name['people_files'].message_type = _TOUCHEDFILES
+_COUPLESANALYSISRESULTS.fields_by_name['team_couples'].message_type = _COUPLES
+_COUPLESANALYSISRESULTS.fields_by_name['team_files'].message_type = _TOUCHEDFILES
+_UASTCHANGESSAVERRESULTS.fields_by_name['changes'].message_type = _UASTCHANGE
+_SHOTNESSRECORD_COUNTERSENTRY.containing_type = _SHOTNESSRECORD
+_SHOTNESSRECORD.fields_by_name['counters'].message_type = _SHOTNESSRECORD_COUNTERSENTRY
+_SHOTNESSANALYSISRESULTS.fields_by_name['records'].message_type = _SHOTNESSRECORD
+_FILEHISTORYRESULTMESSAGE_FILESENTRY.fields_by_name['value'].message_type = _FILEHISTORY
+_FILEHISTORYRESULTMESSAGE_FILESENTRY.containing_type = _FILEHISTORYRESULTMESSAGE
+_FILEHISTORYRESULTMESSAGE.fields_by_name['files'].message_type = _FILEHISTORYRESULTMESSAGE_FILESENTRY
+_DEVDAY_LANGUAGESENTRY.fields_by_name['value'].message_type = _LINESTATS
+_DEVDAY_LANGUAGESENTRY.containing_type = _DEVDAY
+_DEVDAY.fields_by_name['stats'].message_type = _LINESTATS
+_DEVDAY.fields_by_name['languages'].message_type = _DEVDAY_LANGUAGESENTRY
+_DAYDEVS_DEVSENTRY.fields_by_name['value'].message_type = _DEVDAY
+_DAYDEVS_DEVSENTRY.containing_type = _DAYDEVS
+_DAYDEVS.fields_by_name['devs'].message_type = _DAYDEVS_DEVSENTRY
+_DEVSANALYSISRESULTS_DAYSENTRY.fields_by_name['value'].message_type = _DAYDEVS
+_DEVSANALYSISRESULTS_DAYSENTRY.containing_type = _DEVSANALYSISRESULTS
+_DEVSANALYSISRESULTS_TEAMDAYSENTRY.fields_by_name['value'].message_type = _DAYDEVS
+_DEVSANALYSISRESULTS_TEAMDAYSENTRY.containing_type = _DEVSANALYSISRESULTS
+_DEVSANALYSISRESULTS.fields_by_name['days'].message_type = _DEVSANALYSISRESULTS_DAYSENTRY
+_DEVSANALYSISRESULTS.fields_by_name['team_days'].message_type = _DEVSANALYSISRESULTS_TEAMDAYSENTRY
+_COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY.fields_by_name['value'].message_type = _SENTIMENT
+_COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY.containing_type = _COMMENTSENTIMENTRESULTS
+_COMMENTSENTIMENTRESULTS.fields_by_name['sentiment_by_day'].message_type = _COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY
+_COMMITFILE.fields_by_name['stats'].message_type = _LINESTATS
+_COMMIT.fields_by_name['files'].message_type = _COMMITFILE
+_COMMITSANALYSISRESULTS.fields_by_name['commits'].message_type = _COMMIT
+_ANALYSISRESULTS_CONTENTSENTRY.containing_type = _ANALYSISRESULTS
+_ANALYSISRESULTS.fields_by_name['header'].message_type = _METADATA
+_ANALYSISRESULTS.fields_by_name['contents'].message_type = _ANALYSISRESULTS_CONTENTSENTRY
+_ANALYSISREQUEST_FACTSENTRY.containing_type = _ANALYSISREQUEST
+_ANALYSISREQUEST.fields_by_name['facts'].message_type = _ANALYSISREQUEST_FACTSENTRY
+_ANALYSISUPDATE.fields_by_name['progress'].message_type = _ANALYSISPROGRESS
+_ANALYSISUPDATE.fields_by_name['results'].message_type = _ANALYSISRESULTS
+_OWNERSHIPRECORD_OWNERSENTRY.containing_type = _OWNERSHIPRECORD
+_OWNERSHIPRECORD.fields_by_name['owners'].message_type = _OWNERSHIPRECORD_OWNERSENTRY
+_OWNERSHIPANALYSISRESULTS_FILESENTRY.fields_by_name['value'].message_type = _OWNERSHIPRECORD
+_OWNERSHIPANALYSISRESULTS_FILESENTRY.containing_type = _OWNERSHIPANALYSISRESULTS
+_OWNERSHIPANALYSISRESULTS_DIRECTORIESENTRY.fields_by_name['value'].message_type = _OWNERSHIPRECORD
+_OWNERSHIPANALYSISRESULTS_DIRECTORIESENTRY.containing_type = _OWNERSHIPANALYSISRESULTS
+_OWNERSHIPANALYSISRESULTS_LASTCOMMITTIMESENTRY.containing_type = _OWNERSHIPANALYSISRESULTS
+_OWNERSHIPANALYSISRESULTS.fields_by_name['files'].message_type = _OWNERSHIPANALYSISRESULTS_FILESENTRY
+_OWNERSHIPANALYSISRESULTS.fields_by_name['directories'].message_type = _OWNERSHIPANALYSISRESULTS_DIRECTORIESENTRY
+_OWNERSHIPANALYSISRESULTS.fields_by_name['last_commit_times'].message_type = _OWNERSHIPANALYSISRESULTS_LASTCOMMITTIMESENTRY
+_COMMITCLASSDAYS_DAYSENTRY.fields_by_name['value'].message_type = _DAYDEVS
+_COMMITCLASSDAYS_DAYSENTRY.containing_type = _COMMITCLASSDAYS
+_COMMITCLASSDAYS.fields_by_name['days'].message_type = _COMMITCLASSDAYS_DAYSENTRY
+_COMMITCLASSESANALYSISRESULTS_CLASSESENTRY.fields_by_name['value'].message_type = _COMMITCLASSDAYS
+_COMMITCLASSESANALYSISRESULTS_CLASSESENTRY.containing_type = _COMMITCLASSESANALYSISRESULTS
+_COMMITCLASSESANALYSISRESULTS.fields_by_name['classes'].message_type = _COMMITCLASSESANALYSISRESULTS_CLASSESENTRY
+DESCRIPTOR.message_types_by_name['Metadata'] = _METADATA
+DESCRIPTOR.message_types_by_name['BurndownSparseMatrixRow'] = _BURNDOWNSPARSEMATRIXROW
+DESCRIPTOR.message_types_by_name['BurndownSparseMatrix'] = _BURNDOWNSPARSEMATRIX
+DESCRIPTOR.message_types_by_name['FilesOwnership'] = _FILESOWNERSHIP
+DESCRIPTOR.message_types_by_name['BurndownAnalysisResults'] = _BURNDOWNANALYSISRESULTS
+DESCRIPTOR.message_types_by_name['CompressedSparseRowMatrix'] = _COMPRESSEDSPARSEROWMATRIX
+DESCRIPTOR.message_types_by_name['Couples'] = _COUPLES
+DESCRIPTOR.message_types_by_name['TouchedFiles'] = _TOUCHEDFILES
+DESCRIPTOR.message_types_by_name['CouplesAnalysisResults'] = _COUPLESANALYSISRESULTS
+DESCRIPTOR.message_types_by_name['UASTChange'] = _UASTCHANGE
+DESCRIPTOR.message_types_by_name['UASTChangesSaverResults'] = _UASTCHANGESSAVERRESULTS
+DESCRIPTOR.message_types_by_name['ShotnessRecord'] = _SHOTNESSRECORD
+DESCRIPTOR.message_types_by_name['ShotnessAnalysisResults'] = _SHOTNESSANALYSISRESULTS
+DESCRIPTOR.message_types_by_name['FileHistory'] = _FILEHISTORY
+DESCRIPTOR.message_types_by_name['FileHistoryResultMessage'] = _FILEHISTORYRESULTMESSAGE
+DESCRIPTOR.message_types_by_name['LineStats'] = _LINESTATS
+DESCRIPTOR.message_types_by_name['DevDay'] = _DEVDAY
+DESCRIPTOR.message_types_by_name['DayDevs'] = _DAYDEVS
+DESCRIPTOR.message_types_by_name['DevsAnalysisResults'] = _DEVSANALYSISRESULTS
+DESCRIPTOR.message_types_by_name['Sentiment'] = _SENTIMENT
+DESCRIPTOR.message_types_by_name['CommentSentimentResults'] = _COMMENTSENTIMENTRESULTS
+DESCRIPTOR.message_types_by_name['CommitFile'] = _COMMITFILE
+DESCRIPTOR.message_types_by_name['Commit'] = _COMMIT
+DESCRIPTOR.message_types_by_name['CommitsAnalysisResults'] = _COMMITSANALYSISRESULTS
+DESCRIPTOR.message_types_by_name['AnalysisResults'] = _ANALYSISRESULTS
+DESCRIPTOR.message_types_by_name['AnalysisRequest'] = _ANALYSISREQUEST
+DESCRIPTOR.message_types_by_name['AnalysisProgress'] = _ANALYSISPROGRESS
+DESCRIPTOR.message_types_by_name['AnalysisUpdate'] = _ANALYSISUPDATE
+DESCRIPTOR.message_types_by_name['OwnershipRecord'] = _OWNERSHIPRECORD
+DESCRIPTOR.message_types_by_name['OwnershipAnalysisResults'] = _OWNERSHIPANALYSISRESULTS
+DESCRIPTOR.message_types_by_name['CommitClassDays'] = _COMMITCLASSDAYS
+DESCRIPTOR.message_types_by_name['CommitClassesAnalysisResults'] = _COMMITCLASSESANALYSISRESULTS
+_sym_db.RegisterFileDescriptor(DESCRIPTOR)
+
+Metadata = _reflection.GeneratedProtocolMessageType('Metadata', (_message.Message,), dict(
+
+  RunTimePerItemEntry = _reflection.GeneratedProtocolMessageType('RunTimePerItemEntry', (_message.Message,), dict(
+    DESCRIPTOR = _METADATA_RUNTIMEPERITEMENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:Metadata.RunTimePerItemEntry)
+    ))
+  ,
+  DESCRIPTOR = _METADATA,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:Metadata)
+  ))
+_sym_db.RegisterMessage(Metadata)
+_sym_db.RegisterMessage(Metadata.RunTimePerItemEntry)
+
+BurndownSparseMatrixRow = _reflection.GeneratedProtocolMessageType('BurndownSparseMatrixRow', (_message.Message,), dict(
+  DESCRIPTOR = _BURNDOWNSPARSEMATRIXROW,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:BurndownSparseMatrixRow)
+  ))
+_sym_db.RegisterMessage(BurndownSparseMatrixRow)
+
+BurndownSparseMatrix = _reflection.GeneratedProtocolMessageType('BurndownSparseMatrix', (_message.Message,), dict(
+  DESCRIPTOR = _BURNDOWNSPARSEMATRIX,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:BurndownSparseMatrix)
+  ))
+_sym_db.RegisterMessage(BurndownSparseMatrix)
 
 FilesOwnership = _reflection.GeneratedProtocolMessageType('FilesOwnership', (_message.Message,), dict(
 
@@ -1703,12 +2431,20 @@ DevsAnalysisResults = _reflection.GeneratedProtocolMessageType('DevsAnalysisResu
     # @@protoc_insertion_point(class_scope:DevsAnalysisResults.DaysEntry)
     ))
   ,
+
+  TeamDaysEntry = _reflection.GeneratedProtocolMessageType('TeamDaysEntry', (_message.Message,), dict(
+    DESCRIPTOR = _DEVSANALYSISRESULTS_TEAMDAYSENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:DevsAnalysisResults.TeamDaysEntry)
+    ))
+  ,
   DESCRIPTOR = _DEVSANALYSISRESULTS,
   __module__ = 'pb_pb2'
   # @@protoc_insertion_point(class_scope:DevsAnalysisResults)
   ))
 _sym_db.RegisterMessage(DevsAnalysisResults)
 _sym_db.RegisterMessage(DevsAnalysisResults.DaysEntry)
+_sym_db.RegisterMessage(DevsAnalysisResults.TeamDaysEntry)
 
 Sentiment = _reflection.GeneratedProtocolMessageType('Sentiment', (_message.Message,), dict(
   DESCRIPTOR = _SENTIMENT,
@@ -1768,6 +2504,109 @@ AnalysisResults = _reflection.GeneratedProtocolMessageType('AnalysisResults', (_
 _sym_db.RegisterMessage(AnalysisResults)
 _sym_db.RegisterMessage(AnalysisResults.ContentsEntry)
 
+AnalysisRequest = _reflection.GeneratedProtocolMessageType('AnalysisRequest', (_message.Message,), dict(
+
+  FactsEntry = _reflection.GeneratedProtocolMessageType('FactsEntry', (_message.Message,), dict(
+    DESCRIPTOR = _ANALYSISREQUEST_FACTSENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:AnalysisRequest.FactsEntry)
+    ))
+  ,
+  DESCRIPTOR = _ANALYSISREQUEST,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:AnalysisRequest)
+  ))
+_sym_db.RegisterMessage(AnalysisRequest)
+_sym_db.RegisterMessage(AnalysisRequest.FactsEntry)
+
+AnalysisProgress = _reflection.GeneratedProtocolMessageType('AnalysisProgress', (_message.Message,), dict(
+  DESCRIPTOR = _ANALYSISPROGRESS,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:AnalysisProgress)
+  ))
+_sym_db.RegisterMessage(AnalysisProgress)
+
+AnalysisUpdate = _reflection.GeneratedProtocolMessageType('AnalysisUpdate', (_message.Message,), dict(
+  DESCRIPTOR = _ANALYSISUPDATE,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:AnalysisUpdate)
+  ))
+_sym_db.RegisterMessage(AnalysisUpdate)
+
+OwnershipRecord = _reflection.GeneratedProtocolMessageType('OwnershipRecord', (_message.Message,), dict(
+
+  OwnersEntry = _reflection.GeneratedProtocolMessageType('OwnersEntry', (_message.Message,), dict(
+    DESCRIPTOR = _OWNERSHIPRECORD_OWNERSENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:OwnershipRecord.OwnersEntry)
+    ))
+  ,
+  DESCRIPTOR = _OWNERSHIPRECORD,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:OwnershipRecord)
+  ))
+_sym_db.RegisterMessage(OwnershipRecord)
+_sym_db.RegisterMessage(OwnershipRecord.OwnersEntry)
+
+OwnershipAnalysisResults = _reflection.GeneratedProtocolMessageType('OwnershipAnalysisResults', (_message.Message,), dict(
+
+  FilesEntry = _reflection.GeneratedProtocolMessageType('FilesEntry', (_message.Message,), dict(
+    DESCRIPTOR = _OWNERSHIPANALYSISRESULTS_FILESENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:OwnershipAnalysisResults.FilesEntry)
+    ))
+  ,
+  DirectoriesEntry = _reflection.GeneratedProtocolMessageType('DirectoriesEntry', (_message.Message,), dict(
+    DESCRIPTOR = _OWNERSHIPANALYSISRESULTS_DIRECTORIESENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:OwnershipAnalysisResults.DirectoriesEntry)
+    ))
+  ,
+  LastCommitTimesEntry = _reflection.GeneratedProtocolMessageType('LastCommitTimesEntry', (_message.Message,), dict(
+    DESCRIPTOR = _OWNERSHIPANALYSISRESULTS_LASTCOMMITTIMESENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:OwnershipAnalysisResults.LastCommitTimesEntry)
+    ))
+  ,
+  DESCRIPTOR = _OWNERSHIPANALYSISRESULTS,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:OwnershipAnalysisResults)
+  ))
+_sym_db.RegisterMessage(OwnershipAnalysisResults)
+_sym_db.RegisterMessage(OwnershipAnalysisResults.FilesEntry)
+_sym_db.RegisterMessage(OwnershipAnalysisResults.DirectoriesEntry)
+_sym_db.RegisterMessage(OwnershipAnalysisResults.LastCommitTimesEntry)
+
+CommitClassDays = _reflection.GeneratedProtocolMessageType('CommitClassDays', (_message.Message,), dict(
+
+  DaysEntry = _reflection.GeneratedProtocolMessageType('DaysEntry', (_message.Message,), dict(
+    DESCRIPTOR = _COMMITCLASSDAYS_DAYSENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:CommitClassDays.DaysEntry)
+    ))
+  ,
+  DESCRIPTOR = _COMMITCLASSDAYS,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:CommitClassDays)
+  ))
+_sym_db.RegisterMessage(CommitClassDays)
+_sym_db.RegisterMessage(CommitClassDays.DaysEntry)
+
+CommitClassesAnalysisResults = _reflection.GeneratedProtocolMessageType('CommitClassesAnalysisResults', (_message.Message,), dict(
+
+  ClassesEntry = _reflection.GeneratedProtocolMessageType('ClassesEntry', (_message.Message,), dict(
+    DESCRIPTOR = _COMMITCLASSESANALYSISRESULTS_CLASSESENTRY,
+    __module__ = 'pb_pb2'
+    # @@protoc_insertion_point(class_scope:CommitClassesAnalysisResults.ClassesEntry)
+    ))
+  ,
+  DESCRIPTOR = _COMMITCLASSESANALYSISRESULTS,
+  __module__ = 'pb_pb2'
+  # @@protoc_insertion_point(class_scope:CommitClassesAnalysisResults)
+  ))
+_sym_db.RegisterMessage(CommitClassesAnalysisResults)
+_sym_db.RegisterMessage(CommitClassesAnalysisResults.ClassesEntry)
+
 
 _METADATA_RUNTIMEPERITEMENTRY._options = None
 _FILESOWNERSHIP_VALUEENTRY._options = None
@@ -1776,6 +2615,38 @@ _FILEHISTORYRESULTMESSAGE_FILESENTRY._options = None
 _DEVDAY_LANGUAGESENTRY._options = None
 _DAYDEVS_DEVSENTRY._options = None
 _DEVSANALYSISRESULTS_DAYSENTRY._options = None
+_DEVSANALYSISRESULTS_TEAMDAYSENTRY._options = None
 _COMMENTSENTIMENTRESULTS_SENTIMENTBYDAYENTRY._options = None
 _ANALYSISRESULTS_CONTENTSENTRY._options = None
+_ANALYSISREQUEST_FACTSENTRY._options = None
+_OWNERSHIPRECORD_OWNERSENTRY._options = None
+_OWNERSHIPANALYSISRESULTS_FILESENTRY._options = None
+_OWNERSHIPANALYSISRESULTS_DIRECTORIESENTRY._options = None
+_OWNERSHIPANALYSISRESULTS_LASTCOMMITTIMESENTRY._options = None
+_COMMITCLASSDAYS_DAYSENTRY._options = None
+_COMMITCLASSESANALYSISRESULTS_CLASSESENTRY._options = None
+
+_HERCULES = _descriptor.ServiceDescriptor(
+  name='Hercules',
+  full_name='Hercules',
+  file=DESCRIPTOR,
+  index=0,
+  serialized_options=None,
+  serialized_start=4541,
+  serialized_end=4599,
+  methods=[
+  _descriptor.MethodDescriptor(
+    name='Analyse',
+    full_name='Hercules.Analyse',
+    index=0,
+    containing_service=None,
+    input_type=_ANALYSISREQUEST,
+    output_type=_ANALYSISUPDATE,
+    serialized_options=None,
+  ),
+])
+_sym_db.RegisterServiceDescriptor(_HERCULES)
+
+DESCRIPTOR.services_by_name['Hercules'] = _HERCULES
+
 # @@protoc_insertion_point(module_scope)
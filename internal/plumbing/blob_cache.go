@@ -267,6 +267,16 @@ func (blobCache *BlobCache) Fork(n int) []core.PipelineItem {
 	return caches
 }
 
+// Checkpoint does nothing: the cached blobs are reloaded from the repository on demand.
+func (blobCache *BlobCache) Checkpoint(writer io.Writer) error {
+	return nil
+}
+
+// Restore does nothing: the cached blobs are reloaded from the repository on demand.
+func (blobCache *BlobCache) Restore(reader io.Reader, facts map[string]interface{}) error {
+	return nil
+}
+
 // FileGetter defines a function which loads the Git file by
 // the specified path. The state can be arbitrary though here it always
 // corresponds to the currently processed commit.
@@ -283,7 +293,12 @@ func (blobCache *BlobCache) getBlob(entry *object.ChangeEntry, fileGetter FileGe
 			return nil, err
 		}
 		if entry.TreeEntry.Mode != 0160000 {
-			// this is not a submodule
+			// this is not a submodule, but the file may belong to one, see TreeDiff.Submodules
+			if entry.Tree != nil {
+				if file, errTree := entry.Tree.TreeEntryFile(&entry.TreeEntry); errTree == nil {
+					return &file.Blob, nil
+				}
+			}
 			return nil, err
 		} else if !blobCache.FailOnMissingSubmodules {
 			return internal.CreateDummyBlob(entry.TreeEntry.Hash)
@@ -1,19 +1,30 @@
 package plumbing
 
 import (
+	"encoding/gob"
+	"io"
 	"log"
 	"time"
 
+	"github.com/pkg/errors"
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/burndown"
 	"gopkg.in/src-d/hercules.v8/internal/core"
 )
 
 // DaysSinceStart provides the relative date information for every commit.
-// It is a PipelineItem.
+// It is a PipelineItem. Despite the name, the dates are counted in ticks of TickSize which
+// is one day by default.
 type DaysSinceStart struct {
 	core.NoopMerger
+	// TickSize is the duration of a single tick, the precision of the relative dates.
+	TickSize time.Duration
+	// CommitterTimezone indicates whether the ticks are aligned to the committers' local time
+	// instead of UTC, so that, for example, a commit at 23:00 local time belongs to that day.
+	CommitterTimezone bool
+
 	remote      string
 	day0        *time.Time
 	previousDay int
@@ -27,6 +38,19 @@ const (
 
 	// FactCommitsByDay contains the mapping between day indices and the corresponding commits.
 	FactCommitsByDay = "DaysSinceStart.Commits"
+
+	// FactTickSize contains the duration of the tick as time.Duration.
+	FactTickSize = core.FactTickSize
+
+	// ConfigDaysSinceStartTickSize is the name of the option to set DaysSinceStart.TickSize.
+	ConfigDaysSinceStartTickSize = "DaysSinceStart.TickSize"
+
+	// ConfigDaysSinceStartCommitterTimezone is the name of the option to set
+	// DaysSinceStart.CommitterTimezone.
+	ConfigDaysSinceStartCommitterTimezone = "DaysSinceStart.CommitterTimezone"
+
+	// DefaultTickSize is the default value of DaysSinceStart.TickSize.
+	DefaultTickSize = core.DefaultTickSize
 )
 
 // Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
@@ -51,15 +75,46 @@ func (days *DaysSinceStart) Requires() []string {
 
 // ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
 func (days *DaysSinceStart) ListConfigurationOptions() []core.ConfigurationOption {
-	return []core.ConfigurationOption{}
+	options := [...]core.ConfigurationOption{{
+		Name: ConfigDaysSinceStartTickSize,
+		Description: "Duration of the time tick, the precision of the commit dates in all the " +
+			"analyses, e.g. 1h or 168h. The analysed history may span at most 16382 ticks, " +
+			"e.g. 682 days with 1h.",
+		Flag:    "tick-size",
+		Type:    core.StringConfigurationOption,
+		Default: DefaultTickSize.String()}, {
+		Name: ConfigDaysSinceStartCommitterTimezone,
+		Description: "Align the time ticks to the local time of each committer instead " +
+			"of UTC.",
+		Flag:    "committer-timezone",
+		Type:    core.BoolConfigurationOption,
+		Default: false},
+	}
+	return options[:]
 }
 
 // Configure sets the properties previously published by ListConfigurationOptions().
 func (days *DaysSinceStart) Configure(facts map[string]interface{}) error {
+	if val, exists := facts[ConfigDaysSinceStartTickSize].(string); exists {
+		tickSize, err := time.ParseDuration(val)
+		if err != nil {
+			return errors.Wrapf(err, "invalid tick size %q", val)
+		}
+		days.TickSize = tickSize
+	}
+	if days.TickSize == 0 {
+		days.TickSize = DefaultTickSize
+	} else if days.TickSize < 0 {
+		return errors.Errorf("the tick size must be positive, got %s", days.TickSize)
+	}
+	if val, exists := facts[ConfigDaysSinceStartCommitterTimezone].(bool); exists {
+		days.CommitterTimezone = val
+	}
 	if days.commits == nil {
 		days.commits = map[int][]plumbing.Hash{}
 	}
 	facts[FactCommitsByDay] = days.commits
+	facts[FactTickSize] = days.TickSize
 	return nil
 }
 
@@ -91,21 +146,28 @@ func (days *DaysSinceStart) Initialize(repository *git.Repository) error {
 func (days *DaysSinceStart) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
 	commit := deps[core.DependencyCommit].(*object.Commit)
 	index := deps[core.DependencyIndex].(int)
-	if index == 0 {
+	when := days.commitTime(commit)
+	if index == 0 && days.day0.IsZero() {
 		// first iteration - initialize the file objects from the tree
-		// our precision is 1 day
-		*days.day0 = commit.Committer.When.Truncate(24 * time.Hour)
+		// our precision is 1 tick
+		*days.day0 = when.Truncate(days.TickSize)
 		if days.day0.Unix() < 631152000 { // 01.01.1990, that was 30 years ago
 			log.Println()
 			log.Printf("Warning: suspicious committer timestamp in %s > %s",
 				days.remote, commit.Hash.String())
 		}
 	}
-	day := int(commit.Committer.When.Sub(*days.day0).Hours() / 24)
+	day := int(when.Sub(*days.day0) / days.TickSize)
 	if day < days.previousDay {
 		// rebase works miracles, but we need the monotonous time
 		day = days.previousDay
 	}
+	if day >= burndown.TreeMergeMark {
+		// the burndown line tracking packs the ticks into 14 bits
+		return nil, errors.Errorf("commit %s is %d ticks of %s after the first commit, "+
+			"the maximum is %d: increase the tick size", commit.Hash.String(), day, days.TickSize,
+			burndown.TreeMergeMark-1)
+	}
 	days.previousDay = day
 	dayCommits := days.commits[day]
 	if dayCommits == nil {
@@ -125,6 +187,55 @@ func (days *DaysSinceStart) Consume(deps map[string]interface{}) (map[string]int
 	return map[string]interface{}{DependencyDay: day}, nil
 }
 
+// commitTime returns the time of the commit which is split into ticks. If CommitterTimezone is set,
+// the local time of the committer is returned as if it was UTC.
+func (days *DaysSinceStart) commitTime(commit *object.Commit) time.Time {
+	when := commit.Committer.When
+	if !days.CommitterTimezone {
+		return when
+	}
+	_, offset := when.Zone()
+	return when.UTC().Add(time.Duration(offset) * time.Second)
+}
+
+// daysSinceStartCheckpoint is the state of DaysSinceStart which is saved in Checkpoint().
+type daysSinceStartCheckpoint struct {
+	Day0        time.Time
+	PreviousDay int
+	Commits     map[int][]plumbing.Hash
+	TickSize    time.Duration
+}
+
+// Checkpoint writes the time of the first commit and the commits grouped by day.
+func (days *DaysSinceStart) Checkpoint(writer io.Writer) error {
+	return gob.NewEncoder(writer).Encode(&daysSinceStartCheckpoint{
+		Day0:        *days.day0,
+		PreviousDay: days.previousDay,
+		Commits:     days.commits,
+		TickSize:    days.TickSize,
+	})
+}
+
+// Restore reads the state previously written by Checkpoint().
+func (days *DaysSinceStart) Restore(reader io.Reader, facts map[string]interface{}) error {
+	state := daysSinceStartCheckpoint{}
+	err := gob.NewDecoder(reader).Decode(&state)
+	if err != nil {
+		return err
+	}
+	if state.TickSize != 0 && state.TickSize != days.TickSize {
+		return errors.Errorf("the checkpoint was made with the tick size %s while the current "+
+			"tick size is %s", state.TickSize, days.TickSize)
+	}
+	*days.day0 = state.Day0
+	days.previousDay = state.PreviousDay
+	// days.commits is shared through FactCommitsByDay so we must update it in-place
+	for day, hashes := range state.Commits {
+		days.commits[day] = hashes
+	}
+	return nil
+}
+
 // Fork clones this PipelineItem.
 func (days *DaysSinceStart) Fork(n int) []core.PipelineItem {
 	return core.ForkCopyPipelineItem(days, n)
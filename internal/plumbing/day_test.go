@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/burndown"
 	"gopkg.in/src-d/hercules.v8/internal/core"
 	"gopkg.in/src-d/hercules.v8/internal/test"
 )
@@ -26,8 +28,109 @@ func TestDaysSinceStartMeta(t *testing.T) {
 	assert.Equal(t, len(dss.Provides()), 1)
 	assert.Equal(t, dss.Provides()[0], DependencyDay)
 	assert.Equal(t, len(dss.Requires()), 0)
-	assert.Len(t, dss.ListConfigurationOptions(), 0)
-	dss.Configure(map[string]interface{}{})
+	opts := dss.ListConfigurationOptions()
+	assert.Len(t, opts, 2)
+	assert.Equal(t, opts[0].Name, ConfigDaysSinceStartTickSize)
+	assert.Equal(t, opts[0].Flag, "tick-size")
+	assert.Equal(t, opts[1].Name, ConfigDaysSinceStartCommitterTimezone)
+	assert.Equal(t, opts[1].Flag, "committer-timezone")
+	facts := map[string]interface{}{}
+	assert.Nil(t, dss.Configure(facts))
+	assert.Equal(t, DefaultTickSize, dss.TickSize)
+	assert.Equal(t, DefaultTickSize, facts[FactTickSize])
+}
+
+func TestDaysSinceStartConfigure(t *testing.T) {
+	dss := DaysSinceStart{}
+	facts := map[string]interface{}{
+		ConfigDaysSinceStartTickSize:          "1h",
+		ConfigDaysSinceStartCommitterTimezone: true,
+	}
+	assert.Nil(t, dss.Configure(facts))
+	assert.Equal(t, time.Hour, dss.TickSize)
+	assert.True(t, dss.CommitterTimezone)
+	assert.Equal(t, time.Hour, facts[FactTickSize])
+	facts[ConfigDaysSinceStartTickSize] = "xxx"
+	assert.NotNil(t, dss.Configure(facts))
+	facts[ConfigDaysSinceStartTickSize] = "-1h"
+	assert.NotNil(t, dss.Configure(facts))
+}
+
+func TestDaysSinceStartConsumeTickSize(t *testing.T) {
+	dss := DaysSinceStart{}
+	dss.Configure(map[string]interface{}{ConfigDaysSinceStartTickSize: "1h"})
+	dss.Initialize(test.Repository)
+	deps := map[string]interface{}{}
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	deps[core.DependencyCommit] = commit
+	deps[core.DependencyIndex] = 0
+	res, err := dss.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res[DependencyDay].(int))
+	assert.Equal(t, 0, dss.day0.Minute())
+	day0 := *dss.day0
+	commit, _ = test.Repository.CommitObject(plumbing.NewHash(
+		"fc9ceecb6dabcb2aab60e8619d972e8d8208a7df"))
+	deps[core.DependencyCommit] = commit
+	deps[core.DependencyIndex] = 10
+	res, err = dss.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, int(commit.Committer.When.Sub(day0)/time.Hour), res[DependencyDay].(int))
+}
+
+func TestDaysSinceStartConsumeTickOverflow(t *testing.T) {
+	dss := DaysSinceStart{}
+	assert.Nil(t, dss.Configure(map[string]interface{}{ConfigDaysSinceStartTickSize: "1h"}))
+	assert.Nil(t, dss.Initialize(test.Repository))
+	when := time.Date(2016, 12, 13, 0, 0, 0, 0, time.UTC)
+	deps := map[string]interface{}{
+		core.DependencyCommit: &object.Commit{Committer: object.Signature{When: when}},
+		core.DependencyIndex:  0,
+	}
+	_, err := dss.Consume(deps)
+	assert.Nil(t, err)
+	deps[core.DependencyCommit] = &object.Commit{Committer: object.Signature{
+		When: when.Add((burndown.TreeMergeMark - 1) * time.Hour)}}
+	deps[core.DependencyIndex] = 1
+	res, err := dss.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, burndown.TreeMergeMark-1, res[DependencyDay].(int))
+	deps[core.DependencyCommit] = &object.Commit{Committer: object.Signature{
+		When: when.Add(burndown.TreeMergeMark * time.Hour)}}
+	deps[core.DependencyIndex] = 2
+	res, err = dss.Consume(deps)
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+}
+
+func TestDaysSinceStartCommitterTimezone(t *testing.T) {
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	// 00:30 local time is the previous day in UTC
+	commit.Committer.When = time.Date(2016, 12, 13, 0, 30, 0, 0, time.FixedZone("", 3600))
+	deps := map[string]interface{}{
+		core.DependencyCommit: commit,
+		core.DependencyIndex:  0,
+	}
+	dss := fixtureDaysSinceStart()
+	_, err := dss.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, 12, dss.day0.Day())
+	dss = &DaysSinceStart{}
+	dss.Configure(map[string]interface{}{ConfigDaysSinceStartCommitterTimezone: true})
+	dss.Initialize(test.Repository)
+	_, err = dss.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, 13, dss.day0.Day())
+	// 23:30 local time is the next day in UTC
+	commit2 := *commit
+	commit2.Committer.When = time.Date(2016, 12, 13, 23, 30, 0, 0, time.FixedZone("", -3600))
+	deps[core.DependencyCommit] = &commit2
+	deps[core.DependencyIndex] = 1
+	res, err := dss.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res[DependencyDay].(int))
 }
 
 func TestDaysSinceStartRegistration(t *testing.T) {
@@ -128,6 +231,45 @@ func TestDaysSinceStartFork(t *testing.T) {
 	dss1.Merge([]core.PipelineItem{dss2})
 }
 
+func TestDaysSinceStartCheckpointRestore(t *testing.T) {
+	dss := fixtureDaysSinceStart()
+	deps := map[string]interface{}{}
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	deps[core.DependencyCommit] = commit
+	deps[core.DependencyIndex] = 0
+	dss.Consume(deps)
+	commit, _ = test.Repository.CommitObject(plumbing.NewHash(
+		"fc9ceecb6dabcb2aab60e8619d972e8d8208a7df"))
+	deps[core.DependencyCommit] = commit
+	deps[core.DependencyIndex] = 10
+	res, _ := dss.Consume(deps)
+	day := res[DependencyDay].(int)
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, dss.Checkpoint(buffer))
+
+	restored := DaysSinceStart{}
+	facts := map[string]interface{}{}
+	restored.Configure(facts)
+	restored.Initialize(test.Repository)
+	assert.Nil(t, restored.Restore(buffer, facts))
+	assert.True(t, dss.day0.Equal(*restored.day0))
+	assert.Equal(t, dss.previousDay, restored.previousDay)
+	assert.Equal(t, dss.commits, facts[FactCommitsByDay])
+	// the index starts from 0 again after resuming, day0 must not be reset
+	deps[core.DependencyIndex] = 0
+	res, err := restored.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, day, res[DependencyDay].(int))
+
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, dss.Checkpoint(buffer))
+	restored = DaysSinceStart{}
+	restored.Configure(map[string]interface{}{ConfigDaysSinceStartTickSize: "1h"})
+	restored.Initialize(test.Repository)
+	assert.NotNil(t, restored.Restore(buffer, facts))
+}
+
 func TestDaysSinceStartConsumeZero(t *testing.T) {
 	dss := fixtureDaysSinceStart()
 	deps := map[string]interface{}{}
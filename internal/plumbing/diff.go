@@ -1,6 +1,8 @@
 package plumbing
 
 import (
+	"fmt"
+	"io"
 	"strings"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
@@ -17,6 +19,12 @@ type FileDiff struct {
 	core.NoopMerger
 	CleanupDisabled  bool
 	WhitespaceIgnore bool
+	// Algorithm is the line diff algorithm, one of DiffAlgorithmDMP, DiffAlgorithmMyers,
+	// DiffAlgorithmPatience and DiffAlgorithmHistogram.
+	Algorithm string
+
+	// diskCache stores the calculated diffs between the runs, see ConfigCacheDirectory.
+	diskCache *DiskCache
 }
 
 const (
@@ -31,6 +39,10 @@ const (
 	// ConfigFileWhitespaceIgnore is the name of the configuration option (FileDiff.Configure())
 	// to suppress whitespace changes which can pollute the core diff of the files
 	ConfigFileWhitespaceIgnore = "FileDiff.WhitespaceIgnore"
+
+	// ConfigFileDiffAlgorithm is the name of the configuration option (FileDiff.Configure())
+	// to choose the line diff algorithm.
+	ConfigFileDiffAlgorithm = "FileDiff.Algorithm"
 )
 
 // FileDiffData is the type of the dependency provided by FileDiff.
@@ -76,6 +88,22 @@ func (diff *FileDiff) ListConfigurationOptions() []core.ConfigurationOption {
 			Flag:        "no-diff-whitespace",
 			Type:        core.BoolConfigurationOption,
 			Default:     false},
+		{
+			Name: ConfigCacheDirectory,
+			Description: "Directory where to store the line counts, the languages and the diffs " +
+				"of the blobs to speed up the subsequent runs. May be shared between " +
+				"concurrent processes.",
+			Flag:    "cache-dir",
+			Type:    core.PathConfigurationOption,
+			Default: ""},
+		{
+			Name: ConfigFileDiffAlgorithm,
+			Description: "Line diff algorithm: " + DiffAlgorithmDMP + " (diffmatchpatch), " +
+				DiffAlgorithmMyers + ", " + DiffAlgorithmPatience + " or " + DiffAlgorithmHistogram +
+				" (the same as in Git).",
+			Flag:    "diff-algorithm",
+			Type:    core.StringConfigurationOption,
+			Default: DiffAlgorithmDMP},
 	}
 
 	return options[:]
@@ -89,6 +117,15 @@ func (diff *FileDiff) Configure(facts map[string]interface{}) error {
 	if val, exists := facts[ConfigFileWhitespaceIgnore].(bool); exists {
 		diff.WhitespaceIgnore = val
 	}
+	if val, exists := facts[ConfigFileDiffAlgorithm].(string); exists {
+		switch val {
+		case DiffAlgorithmDMP, DiffAlgorithmMyers, DiffAlgorithmPatience, DiffAlgorithmHistogram:
+			diff.Algorithm = val
+		default:
+			return fmt.Errorf("unknown diff algorithm: %s", val)
+		}
+	}
+	diff.diskCache = NewDiskCache(facts)
 	return nil
 }
 
@@ -122,23 +159,11 @@ func (diff *FileDiff) Consume(deps map[string]interface{}) (map[string]interface
 		}
 		switch action {
 		case merkletrie.Modify:
-			blobFrom := cache[change.From.TreeEntry.Hash]
-			blobTo := cache[change.To.TreeEntry.Hash]
-			// we are not validating UTF-8 here because for example
-			// git/git 4f7770c87ce3c302e1639a7737a6d2531fe4b160 fetch-pack.c is invalid UTF-8
-			strFrom, strTo := string(blobFrom.Data), string(blobTo.Data)
-			dmp := diffmatchpatch.New()
-			src, dst, _ := dmp.DiffLinesToRunes(stripWhitespace(strFrom, diff.WhitespaceIgnore), stripWhitespace(strTo, diff.WhitespaceIgnore))
-			diffs := dmp.DiffMainRunes(src, dst, false)
-
-			if !diff.CleanupDisabled {
-				diffs = dmp.DiffCleanupMerge(dmp.DiffCleanupSemanticLossless(diffs))
-			}
-			result[change.To.Name] = FileDiffData{
-				OldLinesOfCode: len(src),
-				NewLinesOfCode: len(dst),
-				Diffs:          diffs,
+			data, err := diff.Diff(cache[change.From.TreeEntry.Hash], cache[change.To.TreeEntry.Hash])
+			if err != nil {
+				return nil, err
 			}
+			result[change.To.Name] = data
 		default:
 			continue
 		}
@@ -146,11 +171,62 @@ func (diff *FileDiff) Consume(deps map[string]interface{}) (map[string]interface
 	return map[string]interface{}{DependencyFileDiff: result}, nil
 }
 
+// Diff calculates the line diff of two text blobs with the configured options.
+// The diffs are loaded from and saved to the disk cache if it is enabled.
+func (diff *FileDiff) Diff(blobFrom, blobTo *CachedBlob) (FileDiffData, error) {
+	var key string
+	if keyFrom, keyTo := blobKey(blobFrom), blobKey(blobTo); keyFrom != "" && keyTo != "" {
+		key = fmt.Sprintf("%s %s %t %t %s", keyFrom, keyTo,
+			diff.CleanupDisabled, diff.WhitespaceIgnore, diff.algorithm())
+	}
+	var data FileDiffData
+	if diff.diskCache.Load(DiskCacheFileDiffs, key, &data) {
+		return data, nil
+	}
+	data = diffBlobs(blobFrom, blobTo, diff.algorithm(), diff.CleanupDisabled, diff.WhitespaceIgnore)
+	if err := diff.diskCache.Store(DiskCacheFileDiffs, key, data); err != nil {
+		return FileDiffData{}, err
+	}
+	return data, nil
+}
+
+// algorithm returns the configured line diff algorithm or the default.
+func (diff *FileDiff) algorithm() string {
+	if diff.Algorithm == "" {
+		return DiffAlgorithmDMP
+	}
+	return diff.Algorithm
+}
+
+// diffBlobs calculates the line diff of two text blobs.
+func diffBlobs(blobFrom, blobTo *CachedBlob, algorithm string, cleanupDisabled, whitespaceIgnore bool) FileDiffData {
+	// we are not validating UTF-8 here because for example
+	// git/git 4f7770c87ce3c302e1639a7737a6d2531fe4b160 fetch-pack.c is invalid UTF-8
+	strFrom, strTo := string(blobFrom.Data), string(blobTo.Data)
+	dmp := diffmatchpatch.New()
+	src, dst, _ := dmp.DiffLinesToRunes(stripWhitespace(strFrom, whitespaceIgnore), stripWhitespace(strTo, whitespaceIgnore))
+	return FileDiffData{
+		OldLinesOfCode: len(src),
+		NewLinesOfCode: len(dst),
+		Diffs:          DiffRunes(src, dst, algorithm, cleanupDisabled),
+	}
+}
+
 // Fork clones this PipelineItem.
 func (diff *FileDiff) Fork(n int) []core.PipelineItem {
 	return core.ForkSamePipelineItem(diff, n)
 }
 
+// Checkpoint does nothing: FileDiff does not keep any state between the commits.
+func (diff *FileDiff) Checkpoint(writer io.Writer) error {
+	return nil
+}
+
+// Restore does nothing: FileDiff does not keep any state between the commits.
+func (diff *FileDiff) Restore(reader io.Reader, facts map[string]interface{}) error {
+	return nil
+}
+
 func init() {
 	core.Registry.Register(&FileDiff{})
 }
@@ -0,0 +1,822 @@
+package plumbing
+
+import (
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	// DiffAlgorithmDMP is the diff algorithm of diffmatchpatch, the default.
+	DiffAlgorithmDMP = "dmp"
+	// DiffAlgorithmMyers is the default diff algorithm of Git.
+	DiffAlgorithmMyers = "myers"
+	// DiffAlgorithmPatience is the same as `git diff --diff-algorithm=patience`.
+	DiffAlgorithmPatience = "patience"
+	// DiffAlgorithmHistogram is the same as `git diff --diff-algorithm=histogram`.
+	DiffAlgorithmHistogram = "histogram"
+)
+
+// The constants of Git's xdiff which tune the Myers algorithm.
+const (
+	xdlMaxEqLimit    = 1024
+	xdlSimScanWindow = 100
+	xdlKeepDisRun    = 4
+	xdlMaxCostMin    = 256
+	xdlHeurMinCost   = 256
+	xdlSnakeCnt      = 20
+	xdlKHeur         = 4
+	xdlLineMax       = int(^uint(0) >> 1)
+	xdlMaxChainLen   = 64
+)
+
+// DiffRunes calculates the line diff of two files which were converted to runes with
+// diffmatchpatch.DiffLinesToRunes(), so that each rune is a line. The algorithm is one of
+// DiffAlgorithmDMP, DiffAlgorithmMyers, DiffAlgorithmPatience or DiffAlgorithmHistogram.
+// The changed blocks of Myers, patience and histogram are slid the same way as Git does it,
+// so that the results are the same as `git diff --no-indent-heuristic`; Git's default indent
+// heuristic is not implemented. cleanupDisabled turns off this sliding and
+// DiffCleanupSemanticLossless() of diffmatchpatch.
+func DiffRunes(src, dst []rune, algorithm string, cleanupDisabled bool) []diffmatchpatch.Diff {
+	switch algorithm {
+	case DiffAlgorithmMyers, DiffAlgorithmPatience, DiffAlgorithmHistogram:
+	default:
+		dmp := diffmatchpatch.New()
+		diffs := dmp.DiffMainRunes(src, dst, false)
+		if !cleanupDisabled {
+			diffs = dmp.DiffCleanupMerge(dmp.DiffCleanupSemanticLossless(diffs))
+		}
+		return diffs
+	}
+	xd := newLineDiff(src, dst)
+	switch algorithm {
+	case DiffAlgorithmMyers:
+		xd.myers(0, len(src), 0, len(dst))
+	case DiffAlgorithmPatience:
+		xd.patience(0, len(src), 0, len(dst))
+	case DiffAlgorithmHistogram:
+		xd.histogram(0, len(src), 0, len(dst))
+	}
+	if !cleanupDisabled {
+		xd.compact(xd.a, xd.b)
+		xd.compact(xd.b, xd.a)
+	}
+	return xd.script()
+}
+
+// lineDiffSide is one of the compared files.
+type lineDiffSide struct {
+	lines []rune
+	// changed marks the deleted or inserted lines. There is a sentinel false
+	// on both sides, so the index of line i is i + 1.
+	changed []bool
+}
+
+func (side *lineDiffSide) isChanged(i int) bool {
+	return side.changed[i+1]
+}
+
+func (side *lineDiffSide) setChanged(i int, value bool) {
+	side.changed[i+1] = value
+}
+
+// lineDiff is the state of the line diff algorithms ported from Git's xdiff.
+type lineDiff struct {
+	a, b *lineDiffSide
+}
+
+func newLineDiff(src, dst []rune) *lineDiff {
+	return &lineDiff{
+		a: &lineDiffSide{lines: src, changed: make([]bool, len(src)+2)},
+		b: &lineDiffSide{lines: dst, changed: make([]bool, len(dst)+2)},
+	}
+}
+
+// script converts the changed lines to diffmatchpatch edits. The deletions always go before
+// the insertions, the same as in the unified diff format.
+func (xd *lineDiff) script() []diffmatchpatch.Diff {
+	var diffs []diffmatchpatch.Diff
+	add := func(op diffmatchpatch.Operation, lines []rune) {
+		if len(lines) > 0 {
+			diffs = append(diffs, diffmatchpatch.Diff{Type: op, Text: string(lines)})
+		}
+	}
+	src, dst := xd.a.lines, xd.b.lines
+	i, j := 0, 0
+	for i < len(src) || j < len(dst) {
+		i0, j0 := i, j
+		for i < len(src) && xd.a.isChanged(i) {
+			i++
+		}
+		for j < len(dst) && xd.b.isChanged(j) {
+			j++
+		}
+		add(diffmatchpatch.DiffDelete, src[i0:i])
+		add(diffmatchpatch.DiffInsert, dst[j0:j])
+		i0 = i
+		for i < len(src) && j < len(dst) && !xd.a.isChanged(i) && !xd.b.isChanged(j) {
+			i++
+			j++
+		}
+		add(diffmatchpatch.DiffEqual, src[i0:i])
+	}
+	return diffs
+}
+
+// markChanged marks the lines of both ranges as changed.
+func (xd *lineDiff) markChanged(start1, end1, start2, end2 int) {
+	for i := start1; i < end1; i++ {
+		xd.a.setChanged(i, true)
+	}
+	for i := start2; i < end2; i++ {
+		xd.b.setChanged(i, true)
+	}
+}
+
+// myers runs xdl_do_diff() on the ranges: trims the common ends, discards the lines which
+// cannot match (xdl_cleanup_records()) and divides and conquers the rest (xdl_recs_cmp()).
+func (xd *lineDiff) myers(start1, end1, start2, end2 int) {
+	// the number of occurrences of each line in the other file before the trimming
+	counts1 := map[rune]int{}
+	for _, line := range xd.a.lines[start1:end1] {
+		counts1[line]++
+	}
+	counts2 := map[rune]int{}
+	for _, line := range xd.b.lines[start2:end2] {
+		counts2[line]++
+	}
+	mlim1, mlim2 := bogoSqrt(end1-start1), bogoSqrt(end2-start2)
+	for start1 < end1 && start2 < end2 && xd.a.lines[start1] == xd.b.lines[start2] {
+		start1++
+		start2++
+	}
+	for start1 < end1 && start2 < end2 && xd.a.lines[end1-1] == xd.b.lines[end2-1] {
+		end1--
+		end2--
+	}
+	index1 := xd.cleanupRecords(xd.a, start1, end1, counts2, mlim1)
+	index2 := xd.cleanupRecords(xd.b, start2, end2, counts1, mlim2)
+	ha1 := make([]rune, len(index1))
+	for i, line := range index1 {
+		ha1[i] = xd.a.lines[line]
+	}
+	ha2 := make([]rune, len(index2))
+	for i, line := range index2 {
+		ha2[i] = xd.b.lines[line]
+	}
+	ndiags := len(ha1) + len(ha2) + 3
+	env := &myersEnv{
+		ha1: ha1, ha2: ha2, index1: index1, index2: index2,
+		kvdf:    make([]int, ndiags),
+		kvdb:    make([]int, ndiags),
+		kOffset: len(ha2) + 1,
+		mxcost:  bogoSqrt(ndiags),
+	}
+	if env.mxcost < xdlMaxCostMin {
+		env.mxcost = xdlMaxCostMin
+	}
+	xd.recsCmp(env, 0, len(ha1), 0, len(ha2), false)
+}
+
+// cleanupRecords marks the lines which do not exist in the other file and the runs of
+// the lines which are too frequent as changed. It returns the indexes of the remaining lines.
+func (xd *lineDiff) cleanupRecords(
+	side *lineDiffSide, start, end int, otherCounts map[rune]int, mlim int) []int {
+
+	if mlim > xdlMaxEqLimit {
+		mlim = xdlMaxEqLimit
+	}
+	dis := make([]byte, end-start)
+	for i := range dis {
+		if nm := otherCounts[side.lines[start+i]]; nm == 0 {
+			dis[i] = 0
+		} else if nm >= mlim {
+			dis[i] = 2
+		} else {
+			dis[i] = 1
+		}
+	}
+	var index []int
+	for i := range dis {
+		if dis[i] == 1 || (dis[i] == 2 && !cleanMultiMatch(dis, i)) {
+			index = append(index, start+i)
+		} else {
+			side.setChanged(start+i, true)
+		}
+	}
+	return index
+}
+
+// cleanMultiMatch is xdl_clean_mmatch(): it decides whether the frequent line is in the middle
+// of the lines without a match and should be discarded.
+func cleanMultiMatch(dis []byte, i int) bool {
+	s, e := 0, len(dis)-1
+	if i-s > xdlSimScanWindow {
+		s = i - xdlSimScanWindow
+	}
+	if e-i > xdlSimScanWindow {
+		e = i + xdlSimScanWindow
+	}
+	rdis0, rpdis0 := 0, 1
+	for r := 1; i-r >= s; r++ {
+		if dis[i-r] == 0 {
+			rdis0++
+		} else if dis[i-r] == 2 {
+			rpdis0++
+		} else {
+			break
+		}
+	}
+	if rdis0 == 0 {
+		return false
+	}
+	rdis1, rpdis1 := 0, 1
+	for r := 1; i+r <= e; r++ {
+		if dis[i+r] == 0 {
+			rdis1++
+		} else if dis[i+r] == 2 {
+			rpdis1++
+		} else {
+			break
+		}
+	}
+	if rdis1 == 0 {
+		return false
+	}
+	rdis1 += rdis0
+	rpdis1 += rpdis0
+	return rpdis1*xdlKeepDisRun < rpdis1+rdis1
+}
+
+// bogoSqrt is xdl_bogosqrt(), the approximate square root.
+func bogoSqrt(n int) int {
+	i := 1
+	for ; n > 0; n >>= 2 {
+		i <<= 1
+	}
+	return i
+}
+
+// myersEnv carries the reduced sequences of lines and the diagonal vectors.
+type myersEnv struct {
+	ha1, ha2       []rune
+	index1, index2 []int
+	kvdf, kvdb     []int
+	kOffset        int
+	mxcost         int
+}
+
+// recsCmp is xdl_recs_cmp().
+func (xd *lineDiff) recsCmp(env *myersEnv, off1, lim1, off2, lim2 int, needMin bool) {
+	ha1, ha2 := env.ha1, env.ha2
+	for off1 < lim1 && off2 < lim2 && ha1[off1] == ha2[off2] {
+		off1++
+		off2++
+	}
+	for off1 < lim1 && off2 < lim2 && ha1[lim1-1] == ha2[lim2-1] {
+		lim1--
+		lim2--
+	}
+	if off1 == lim1 {
+		for ; off2 < lim2; off2++ {
+			xd.b.setChanged(env.index2[off2], true)
+		}
+	} else if off2 == lim2 {
+		for ; off1 < lim1; off1++ {
+			xd.a.setChanged(env.index1[off1], true)
+		}
+	} else {
+		i1, i2, minLo, minHi := env.split(off1, lim1, off2, lim2, needMin)
+		xd.recsCmp(env, off1, i1, off2, i2, minLo)
+		xd.recsCmp(env, i1, lim1, i2, lim2, minHi)
+	}
+}
+
+// split is xdl_split(): it finds the middle snake of the box, or a good enough split point
+// if the edit cost is too high.
+func (env *myersEnv) split(off1, lim1, off2, lim2 int, needMin bool) (int, int, bool, bool) {
+	ha1, ha2 := env.ha1, env.ha2
+	kvdf := func(d int) *int { return &env.kvdf[d+env.kOffset] }
+	kvdb := func(d int) *int { return &env.kvdb[d+env.kOffset] }
+	dmin, dmax := off1-lim2, lim1-off2
+	fmid, bmid := off1-off2, lim1-lim2
+	odd := (fmid-bmid)&1 != 0
+	fmin, fmax := fmid, fmid
+	bmin, bmax := bmid, bmid
+	*kvdf(fmid) = off1
+	*kvdb(bmid) = lim1
+
+	for ec := 1; ; ec++ {
+		gotSnake := false
+		if fmin > dmin {
+			fmin--
+			*kvdf(fmin - 1) = -1
+		} else {
+			fmin++
+		}
+		if fmax < dmax {
+			fmax++
+			*kvdf(fmax + 1) = -1
+		} else {
+			fmax--
+		}
+		for d := fmax; d >= fmin; d -= 2 {
+			var i1 int
+			if *kvdf(d - 1) >= *kvdf(d + 1) {
+				i1 = *kvdf(d - 1) + 1
+			} else {
+				i1 = *kvdf(d + 1)
+			}
+			prev1 := i1
+			i2 := i1 - d
+			for ; i1 < lim1 && i2 < lim2 && ha1[i1] == ha2[i2]; i1, i2 = i1+1, i2+1 {
+			}
+			if i1-prev1 > xdlSnakeCnt {
+				gotSnake = true
+			}
+			*kvdf(d) = i1
+			if odd && bmin <= d && d <= bmax && *kvdb(d) <= i1 {
+				return i1, i2, true, true
+			}
+		}
+
+		if bmin > dmin {
+			bmin--
+			*kvdb(bmin - 1) = xdlLineMax
+		} else {
+			bmin++
+		}
+		if bmax < dmax {
+			bmax++
+			*kvdb(bmax + 1) = xdlLineMax
+		} else {
+			bmax--
+		}
+		for d := bmax; d >= bmin; d -= 2 {
+			var i1 int
+			if *kvdb(d - 1) < *kvdb(d + 1) {
+				i1 = *kvdb(d - 1)
+			} else {
+				i1 = *kvdb(d + 1) - 1
+			}
+			prev1 := i1
+			i2 := i1 - d
+			for ; i1 > off1 && i2 > off2 && ha1[i1-1] == ha2[i2-1]; i1, i2 = i1-1, i2-1 {
+			}
+			if prev1-i1 > xdlSnakeCnt {
+				gotSnake = true
+			}
+			*kvdb(d) = i1
+			if !odd && fmin <= d && d <= fmax && i1 <= *kvdf(d) {
+				return i1, i2, true, true
+			}
+		}
+
+		if needMin {
+			continue
+		}
+
+		// the edit cost is high and we have found a long snake: take the furthest
+		// reaching diagonal which ends with the snake
+		if gotSnake && ec > xdlHeurMinCost {
+			best, spl1, spl2 := 0, 0, 0
+			for d := fmax; d >= fmin; d -= 2 {
+				dd := d - fmid
+				if dd < 0 {
+					dd = -dd
+				}
+				i1 := *kvdf(d)
+				i2 := i1 - d
+				v := (i1 - off1) + (i2 - off2) - dd
+				if v > xdlKHeur*ec && v > best &&
+					off1+xdlSnakeCnt <= i1 && i1 < lim1 &&
+					off2+xdlSnakeCnt <= i2 && i2 < lim2 {
+					for k := 1; ha1[i1-k] == ha2[i2-k]; k++ {
+						if k == xdlSnakeCnt {
+							best, spl1, spl2 = v, i1, i2
+							break
+						}
+					}
+				}
+			}
+			if best > 0 {
+				return spl1, spl2, true, false
+			}
+			for d := bmax; d >= bmin; d -= 2 {
+				dd := d - bmid
+				if dd < 0 {
+					dd = -dd
+				}
+				i1 := *kvdb(d)
+				i2 := i1 - d
+				v := (lim1 - i1) + (lim2 - i2) - dd
+				if v > xdlKHeur*ec && v > best &&
+					off1 < i1 && i1 <= lim1-xdlSnakeCnt &&
+					off2 < i2 && i2 <= lim2-xdlSnakeCnt {
+					for k := 0; ha1[i1+k] == ha2[i2+k]; k++ {
+						if k == xdlSnakeCnt-1 {
+							best, spl1, spl2 = v, i1, i2
+							break
+						}
+					}
+				}
+			}
+			if best > 0 {
+				return spl1, spl2, false, true
+			}
+		}
+
+		// we have spent too much time here, take the furthest reaching path
+		if ec >= env.mxcost {
+			fbest, fbest1 := -1, -1
+			for d := fmax; d >= fmin; d -= 2 {
+				i1 := *kvdf(d)
+				if i1 > lim1 {
+					i1 = lim1
+				}
+				i2 := i1 - d
+				if lim2 < i2 {
+					i1 = lim2 + d
+					i2 = lim2
+				}
+				if fbest < i1+i2 {
+					fbest = i1 + i2
+					fbest1 = i1
+				}
+			}
+			bbest, bbest1 := xdlLineMax, xdlLineMax
+			for d := bmax; d >= bmin; d -= 2 {
+				i1 := *kvdb(d)
+				if i1 < off1 {
+					i1 = off1
+				}
+				i2 := i1 - d
+				if i2 < off2 {
+					i1 = off2 + d
+					i2 = off2
+				}
+				if i1+i2 < bbest {
+					bbest = i1 + i2
+					bbest1 = i1
+				}
+			}
+			if (lim1+lim2)-bbest < fbest-(off1+off2) {
+				return fbest1, fbest - fbest1, true, false
+			}
+			return bbest1, bbest - bbest1, false, true
+		}
+	}
+}
+
+// patienceEntry is a line of the patience diff hash map.
+type patienceEntry struct {
+	line1, line2 int
+	unique       bool
+	previous     *patienceEntry
+	next         *patienceEntry
+}
+
+// patience is patience_diff() of Git's xpatience.c: it matches the lines which are unique
+// in both ranges with the longest increasing subsequence and recurses between them.
+func (xd *lineDiff) patience(start1, end1, start2, end2 int) {
+	if start1 == end1 || start2 == end2 {
+		xd.markChanged(start1, end1, start2, end2)
+		return
+	}
+	entries := map[rune]*patienceEntry{}
+	var order []*patienceEntry
+	for i := start1; i < end1; i++ {
+		line := xd.a.lines[i]
+		if entry := entries[line]; entry != nil {
+			entry.unique = false
+			continue
+		}
+		entry := &patienceEntry{line1: i, line2: -1, unique: true}
+		entries[line] = entry
+		order = append(order, entry)
+	}
+	hasMatches := false
+	for i := start2; i < end2; i++ {
+		entry := entries[xd.b.lines[i]]
+		if entry == nil {
+			continue
+		}
+		hasMatches = true
+		if entry.line2 >= 0 {
+			entry.unique = false
+		} else {
+			entry.line2 = i
+		}
+	}
+	if !hasMatches {
+		xd.markChanged(start1, end1, start2, end2)
+		return
+	}
+	// find the longest common sequence of the unique lines
+	var sequence []*patienceEntry
+	for _, entry := range order {
+		if !entry.unique || entry.line2 < 0 {
+			continue
+		}
+		left, right := -1, len(sequence)
+		for left+1 < right {
+			middle := left + (right-left)/2
+			if sequence[middle].line2 > entry.line2 {
+				right = middle
+			} else {
+				left = middle
+			}
+		}
+		if left >= 0 {
+			entry.previous = sequence[left]
+		}
+		if left+1 == len(sequence) {
+			sequence = append(sequence, entry)
+		} else {
+			sequence[left+1] = entry
+		}
+	}
+	if len(sequence) == 0 {
+		xd.myers(start1, end1, start2, end2)
+		return
+	}
+	first := sequence[len(sequence)-1]
+	for first.previous != nil {
+		first.previous.next = first
+		first = first.previous
+	}
+	// walk the common sequence
+	line1, line2 := start1, start2
+	for {
+		var next1, next2 int
+		if first != nil {
+			next1, next2 = first.line1, first.line2
+			for next1 > line1 && next2 > line2 && xd.a.lines[next1-1] == xd.b.lines[next2-1] {
+				next1--
+				next2--
+			}
+		} else {
+			next1, next2 = end1, end2
+		}
+		for line1 < next1 && line2 < next2 && xd.a.lines[line1] == xd.b.lines[line2] {
+			line1++
+			line2++
+		}
+		if next1 > line1 || next2 > line2 {
+			xd.patience(line1, next1, line2, next2)
+		}
+		if first == nil {
+			return
+		}
+		for first.next != nil && first.next.line1 == first.line1+1 && first.next.line2 == first.line2+1 {
+			first = first.next
+		}
+		line1 = first.line1 + 1
+		line2 = first.line2 + 1
+		first = first.next
+	}
+}
+
+// histogramIndex is struct histindex of Git's xhistogram.c.
+type histogramIndex struct {
+	// records map the lines to the first occurrence in the first range and the number
+	// of occurrences.
+	records map[rune]*histogramRecord
+	// next is the index of the next occurrence of the same line, -1 if there is none.
+	next []int
+	// lineRecords map the line indexes to the records.
+	lineRecords []*histogramRecord
+	start1      int
+	cnt         int
+	hasCommon   bool
+}
+
+type histogramRecord struct {
+	ptr int
+	cnt int
+}
+
+// histogramRegion is the longest common region with the rarest lines.
+type histogramRegion struct {
+	begin1, end1, begin2, end2 int
+	found                      bool
+}
+
+// histogram is histogram_diff() of Git's xhistogram.c: it finds the longest common region
+// of the least frequent lines and recurses on both sides of it.
+func (xd *lineDiff) histogram(start1, end1, start2, end2 int) {
+	for {
+		if start1 >= end1 && start2 >= end2 {
+			return
+		}
+		if start1 == end1 || start2 == end2 {
+			xd.markChanged(start1, end1, start2, end2)
+			return
+		}
+		lcs, fallBack := xd.findLCS(start1, end1, start2, end2)
+		if fallBack {
+			xd.myers(start1, end1, start2, end2)
+			return
+		}
+		if !lcs.found {
+			xd.markChanged(start1, end1, start2, end2)
+			return
+		}
+		xd.histogram(start1, lcs.begin1, start2, lcs.begin2)
+		start1, start2 = lcs.end1+1, lcs.end2+1
+	}
+}
+
+// findLCS is find_lcs(). It returns true if we must fall back to Myers because the common
+// lines are too frequent.
+func (xd *lineDiff) findLCS(start1, end1, start2, end2 int) (histogramRegion, bool) {
+	index := &histogramIndex{
+		records:     map[rune]*histogramRecord{},
+		next:        make([]int, end1-start1),
+		lineRecords: make([]*histogramRecord, end1-start1),
+		start1:      start1,
+	}
+	for ptr := end1 - 1; ptr >= start1; ptr-- {
+		line := xd.a.lines[ptr]
+		if rec := index.records[line]; rec != nil {
+			index.next[ptr-start1] = rec.ptr
+			rec.ptr = ptr
+			rec.cnt++
+			index.lineRecords[ptr-start1] = rec
+			continue
+		}
+		rec := &histogramRecord{ptr: ptr, cnt: 1}
+		index.records[line] = rec
+		index.next[ptr-start1] = -1
+		index.lineRecords[ptr-start1] = rec
+	}
+	index.cnt = xdlMaxChainLen + 1
+	lcs := histogramRegion{}
+	for bPtr := start2; bPtr < end2; {
+		bPtr = xd.tryLCS(index, &lcs, bPtr, start1, end1, start2, end2)
+	}
+	return lcs, index.hasCommon && xdlMaxChainLen < index.cnt
+}
+
+// tryLCS is try_lcs(). It returns the next line in the second range to try.
+func (xd *lineDiff) tryLCS(
+	index *histogramIndex, lcs *histogramRegion, bPtr, start1, end1, start2, end2 int) int {
+
+	bNext := bPtr + 1
+	rec := index.records[xd.b.lines[bPtr]]
+	if rec == nil {
+		return bNext
+	}
+	if rec.cnt > index.cnt {
+		index.hasCommon = true
+		return bNext
+	}
+	cnt := func(ptr int) int {
+		return index.lineRecords[ptr-index.start1].cnt
+	}
+	as := rec.ptr
+	index.hasCommon = true
+	for {
+		np := index.next[as-index.start1]
+		bs := bPtr
+		ae := as
+		be := bs
+		rc := rec.cnt
+		for start1 < as && start2 < bs && xd.a.lines[as-1] == xd.b.lines[bs-1] {
+			as--
+			bs--
+			if 1 < rc && cnt(as) < rc {
+				rc = cnt(as)
+			}
+		}
+		for ae < end1-1 && be < end2-1 && xd.a.lines[ae+1] == xd.b.lines[be+1] {
+			ae++
+			be++
+			if 1 < rc && cnt(ae) < rc {
+				rc = cnt(ae)
+			}
+		}
+		if bNext <= be {
+			bNext = be + 1
+		}
+		if lcs.end1-lcs.begin1 < ae-as || rc < index.cnt {
+			*lcs = histogramRegion{begin1: as, end1: ae, begin2: bs, end2: be, found: true}
+			index.cnt = rc
+		}
+		if np < 0 {
+			break
+		}
+		for np >= 0 && np <= ae {
+			np = index.next[np-index.start1]
+		}
+		if np < 0 {
+			break
+		}
+		as = np
+	}
+	return bNext
+}
+
+// compact is xdl_change_compact() without the indent heuristic: it slides each group
+// of changed lines as far down as possible, unless it can be aligned with a group of
+// changes in the other file.
+func (xd *lineDiff) compact(side, other *lineDiffSide) {
+	g := lineGroup{}
+	g.init(side)
+	og := lineGroup{}
+	og.init(other)
+	for {
+		if g.end != g.start {
+			var groupSize, earliestEnd int
+			endMatchingOther := -1
+			for {
+				groupSize = g.end - g.start
+				endMatchingOther = -1
+				for g.slideUp(side) {
+					og.previous(other)
+				}
+				earliestEnd = g.end
+				if og.end > og.start {
+					endMatchingOther = g.end
+				}
+				for g.slideDown(side) {
+					og.next(other)
+					if og.end > og.start {
+						endMatchingOther = g.end
+					}
+				}
+				if groupSize == g.end-g.start {
+					break
+				}
+			}
+			if g.end != earliestEnd && endMatchingOther != -1 {
+				for og.end == og.start {
+					g.slideUp(side)
+					og.previous(other)
+				}
+			}
+		}
+		if !g.next(side) {
+			break
+		}
+		og.next(other)
+	}
+}
+
+// lineGroup is a group of consecutive changed lines, possibly empty. end is exclusive.
+type lineGroup struct {
+	start, end int
+}
+
+func (g *lineGroup) init(side *lineDiffSide) {
+	g.start, g.end = 0, 0
+	for side.isChanged(g.end) {
+		g.end++
+	}
+}
+
+func (g *lineGroup) next(side *lineDiffSide) bool {
+	if g.end == len(side.lines) {
+		return false
+	}
+	g.start = g.end + 1
+	for g.end = g.start; side.isChanged(g.end); g.end++ {
+	}
+	return true
+}
+
+func (g *lineGroup) previous(side *lineDiffSide) bool {
+	if g.start == 0 {
+		return false
+	}
+	g.end = g.start - 1
+	for g.start = g.end; side.isChanged(g.start - 1); g.start-- {
+	}
+	return true
+}
+
+func (g *lineGroup) slideDown(side *lineDiffSide) bool {
+	if g.end < len(side.lines) && side.lines[g.start] == side.lines[g.end] {
+		side.setChanged(g.start, false)
+		g.start++
+		side.setChanged(g.end, true)
+		g.end++
+		for side.isChanged(g.end) {
+			g.end++
+		}
+		return true
+	}
+	return false
+}
+
+func (g *lineGroup) slideUp(side *lineDiffSide) bool {
+	if g.start > 0 && side.lines[g.start-1] == side.lines[g.end-1] {
+		g.start--
+		side.setChanged(g.start, true)
+		g.end--
+		side.setChanged(g.end, false)
+		for side.isChanged(g.start - 1) {
+			g.start--
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package plumbing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/stretchr/testify/assert"
+)
+
+var diffAlgorithms = []string{DiffAlgorithmMyers, DiffAlgorithmPatience, DiffAlgorithmHistogram}
+
+// loadDiffCorpus returns the names of the test cases in test_data/diff. Each case consists of
+// NAME.old, NAME.new and the edit scripts produced by
+// `git diff --no-index --no-indent-heuristic --diff-algorithm=ALGORITHM -U1000000`
+// in NAME.ALGORITHM: one line per edit, "=", "-" or "+" followed by the number of lines.
+func loadDiffCorpus(t *testing.T) []string {
+	files, err := filepath.Glob(path.Join("..", "test_data", "diff", "*.old"))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, files)
+	var names []string
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(file, ".old"))
+	}
+	return names
+}
+
+func readDiffCorpusFile(t *testing.T, name string) string {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// formatEditScript converts the line diffs to the format of the corpus.
+func formatEditScript(diffs []diffmatchpatch.Diff) string {
+	ops := map[diffmatchpatch.Operation]string{
+		diffmatchpatch.DiffEqual:  "=",
+		diffmatchpatch.DiffDelete: "-",
+		diffmatchpatch.DiffInsert: "+",
+	}
+	var lines []string
+	for _, edit := range diffs {
+		lines = append(lines, fmt.Sprintf("%s%d", ops[edit.Type], utf8.RuneCountInString(edit.Text)))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// checkEditScript validates that the diffs transform src into dst.
+func checkEditScript(t *testing.T, src, dst []rune, diffs []diffmatchpatch.Diff, msg string) {
+	var old, new []rune
+	for i, edit := range diffs {
+		runes := []rune(edit.Text)
+		assert.NotEmpty(t, runes, msg)
+		if i > 0 {
+			assert.NotEqual(t, diffs[i-1].Type, edit.Type, msg)
+			assert.False(t, diffs[i-1].Type == diffmatchpatch.DiffInsert &&
+				edit.Type == diffmatchpatch.DiffDelete, msg)
+		}
+		switch edit.Type {
+		case diffmatchpatch.DiffEqual:
+			old = append(old, runes...)
+			new = append(new, runes...)
+		case diffmatchpatch.DiffDelete:
+			old = append(old, runes...)
+		case diffmatchpatch.DiffInsert:
+			new = append(new, runes...)
+		}
+	}
+	assert.Equal(t, string(src), string(old), msg)
+	assert.Equal(t, string(dst), string(new), msg)
+}
+
+func TestDiffRunesGitCorpus(t *testing.T) {
+	for _, name := range loadDiffCorpus(t) {
+		dmp := diffmatchpatch.New()
+		src, dst, _ := dmp.DiffLinesToRunes(
+			readDiffCorpusFile(t, name+".old"), readDiffCorpusFile(t, name+".new"))
+		for _, algorithm := range diffAlgorithms {
+			msg := path.Base(name) + " " + algorithm
+			diffs := DiffRunes(src, dst, algorithm, false)
+			checkEditScript(t, src, dst, diffs, msg)
+			assert.Equal(t, readDiffCorpusFile(t, name+"."+algorithm), formatEditScript(diffs), msg)
+		}
+	}
+}
+
+func TestDiffRunesNoCleanup(t *testing.T) {
+	for _, name := range loadDiffCorpus(t) {
+		dmp := diffmatchpatch.New()
+		src, dst, _ := dmp.DiffLinesToRunes(
+			readDiffCorpusFile(t, name+".old"), readDiffCorpusFile(t, name+".new"))
+		for _, algorithm := range append(diffAlgorithms, DiffAlgorithmDMP) {
+			checkEditScript(t, src, dst, DiffRunes(src, dst, algorithm, true),
+				path.Base(name)+" "+algorithm)
+		}
+	}
+}
+
+func TestDiffRunesDMP(t *testing.T) {
+	dmp := diffmatchpatch.New()
+	src, dst, _ := dmp.DiffLinesToRunes(
+		readDiffCorpusFile(t, path.Join("..", "test_data", "1.java")),
+		readDiffCorpusFile(t, path.Join("..", "test_data", "2.java")))
+	diffs := dmp.DiffMainRunes(src, dst, false)
+	assert.Equal(t, diffs, DiffRunes(src, dst, DiffAlgorithmDMP, true))
+	assert.Equal(t, dmp.DiffCleanupMerge(dmp.DiffCleanupSemanticLossless(diffs)),
+		DiffRunes(src, dst, DiffAlgorithmDMP, false))
+	assert.Equal(t, DiffRunes(src, dst, DiffAlgorithmDMP, false), DiffRunes(src, dst, "", false))
+}
+
+func TestDiffRunesEmpty(t *testing.T) {
+	lines := []rune("abc")
+	for _, algorithm := range diffAlgorithms {
+		assert.Nil(t, DiffRunes(nil, nil, algorithm, false), algorithm)
+		assert.Equal(t, []diffmatchpatch.Diff{{Type: diffmatchpatch.DiffInsert, Text: "abc"}},
+			DiffRunes(nil, lines, algorithm, false), algorithm)
+		assert.Equal(t, []diffmatchpatch.Diff{{Type: diffmatchpatch.DiffDelete, Text: "abc"}},
+			DiffRunes(lines, nil, algorithm, false), algorithm)
+		assert.Equal(t, []diffmatchpatch.Diff{{Type: diffmatchpatch.DiffEqual, Text: "abc"}},
+			DiffRunes(lines, lines, algorithm, false), algorithm)
+	}
+}
@@ -24,15 +24,24 @@ func TestFileDiffMeta(t *testing.T) {
 	assert.Equal(t, len(fd.Requires()), 2)
 	assert.Equal(t, fd.Requires()[0], items.DependencyTreeChanges)
 	assert.Equal(t, fd.Requires()[1], items.DependencyBlobCache)
-	assert.Len(t, fd.ListConfigurationOptions(), 2)
+	assert.Len(t, fd.ListConfigurationOptions(), 4)
 	assert.Equal(t, fd.ListConfigurationOptions()[0].Name, items.ConfigFileDiffDisableCleanup)
 	assert.Equal(t, fd.ListConfigurationOptions()[1].Name, items.ConfigFileWhitespaceIgnore)
+	assert.Equal(t, fd.ListConfigurationOptions()[2].Name, items.ConfigCacheDirectory)
+	assert.Equal(t, fd.ListConfigurationOptions()[2].Flag, "cache-dir")
+	assert.Equal(t, fd.ListConfigurationOptions()[3].Name, items.ConfigFileDiffAlgorithm)
+	assert.Equal(t, fd.ListConfigurationOptions()[3].Flag, "diff-algorithm")
 	facts := map[string]interface{}{}
 	facts[items.ConfigFileDiffDisableCleanup] = true
 	facts[items.ConfigFileWhitespaceIgnore] = true
-	fd.Configure(facts)
+	facts[items.ConfigFileDiffAlgorithm] = items.DiffAlgorithmHistogram
+	assert.Nil(t, fd.Configure(facts))
 	assert.True(t, fd.CleanupDisabled)
 	assert.True(t, fd.WhitespaceIgnore)
+	assert.Equal(t, fd.Algorithm, items.DiffAlgorithmHistogram)
+	facts[items.ConfigFileDiffAlgorithm] = "meld"
+	assert.NotNil(t, fd.Configure(facts))
+	assert.Equal(t, fd.Algorithm, items.DiffAlgorithmHistogram)
 }
 
 func TestFileDiffRegistration(t *testing.T) {
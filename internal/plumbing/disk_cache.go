@@ -0,0 +1,116 @@
+package plumbing
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ConfigCacheDirectory is the name of the configuration option (FileDiff.Configure(),
+	// LanguagesDetection.Configure() and LinesStatsCalculator.Configure()) which sets
+	// the directory of the persistent DiskCache. The cache is disabled if it is empty.
+	ConfigCacheDirectory = "Cache.Directory"
+
+	// diskCacheVersion is incremented each time the format of the cached values changes.
+	diskCacheVersion = "1"
+)
+
+const (
+	// DiskCacheLineCounts is the kind of the DiskCache entries which store the number
+	// of lines in a blob, -1 if it is binary.
+	DiskCacheLineCounts = "lines"
+	// DiskCacheLanguages is the kind of the DiskCache entries which store the programming
+	// language of a blob with the given file name.
+	DiskCacheLanguages = "languages"
+	// DiskCacheFileDiffs is the kind of the DiskCache entries which store FileDiffData
+	// of a pair of blobs.
+	DiskCacheFileDiffs = "diffs"
+)
+
+// DiskCache is the persistent content-addressed storage of the values which are calculated
+// from the blob contents: line counts, languages and diffs. The keys must include the hashes
+// of all the involved blobs. It is safe to share the same directory between concurrent
+// processes: the values are written to temporary files which are atomically renamed.
+// nil *DiskCache is valid and never finds anything.
+type DiskCache struct {
+	// Directory is the root directory of the cache.
+	Directory string
+}
+
+// NewDiskCache returns the DiskCache which is configured in the facts or nil if
+// ConfigCacheDirectory is not set.
+func NewDiskCache(facts map[string]interface{}) *DiskCache {
+	if val, exists := facts[ConfigCacheDirectory].(string); exists && val != "" {
+		return &DiskCache{Directory: val}
+	}
+	return nil
+}
+
+// blobKey returns the part of the key which corresponds to the blob or an empty string if
+// the blob does not originate from the repository and thus cannot be cached.
+func blobKey(blob *CachedBlob) string {
+	if blob.Hash.IsZero() {
+		return ""
+	}
+	return blob.Hash.String()
+}
+
+// path returns the location of the cached value. The keys are hashed to be safe file names
+// and to distribute the files evenly between the subdirectories.
+func (cache *DiskCache) path(kind, key string) string {
+	hash := sha1.Sum([]byte(key))
+	name := hex.EncodeToString(hash[:])
+	return filepath.Join(cache.Directory, "v"+diskCacheVersion, kind, name[:2], name[2:])
+}
+
+// Load reads the cached value into `value` which must be a pointer. It returns false if
+// the value does not exist or cannot be read.
+func (cache *DiskCache) Load(kind, key string, value interface{}) bool {
+	if cache == nil || key == "" {
+		return false
+	}
+	data, err := ioutil.ReadFile(cache.path(kind, key))
+	if err != nil {
+		return false
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value) == nil
+}
+
+// Store writes the value to the cache. Existing values are overwritten.
+func (cache *DiskCache) Store(kind, key string, value interface{}) error {
+	if cache == nil || key == "" {
+		return nil
+	}
+	buffer := &bytes.Buffer{}
+	if err := gob.NewEncoder(buffer).Encode(value); err != nil {
+		return errors.Wrapf(err, "failed to encode %s", key)
+	}
+	path := cache.path(kind, key)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create %s", dir)
+	}
+	file, err := ioutil.TempFile(dir, filepath.Base(path)+".tmp")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create a temporary file in %s", dir)
+	}
+	_, err = file.Write(buffer.Bytes())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	return nil
+}
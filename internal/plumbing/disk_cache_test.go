@@ -0,0 +1,170 @@
+package plumbing
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+)
+
+func fixtureDiskCache(t *testing.T) (*DiskCache, func()) {
+	dir, err := ioutil.TempDir("", "hercules-cache-")
+	if err != nil {
+		assert.FailNow(t, "ioutil.TempDir")
+	}
+	return &DiskCache{Directory: dir}, func() { os.RemoveAll(dir) }
+}
+
+func TestNewDiskCache(t *testing.T) {
+	assert.Nil(t, NewDiskCache(map[string]interface{}{}))
+	assert.Nil(t, NewDiskCache(map[string]interface{}{ConfigCacheDirectory: ""}))
+	cache := NewDiskCache(map[string]interface{}{ConfigCacheDirectory: "/tmp/xxx"})
+	assert.Equal(t, "/tmp/xxx", cache.Directory)
+}
+
+func TestDiskCacheLoadStore(t *testing.T) {
+	cache, cleanup := fixtureDiskCache(t)
+	defer cleanup()
+	var lines int
+	assert.False(t, cache.Load(DiskCacheLineCounts, "key", &lines))
+	assert.Nil(t, cache.Store(DiskCacheLineCounts, "key", 0))
+	lines = 10
+	assert.True(t, cache.Load(DiskCacheLineCounts, "key", &lines))
+	assert.Equal(t, 0, lines)
+	assert.False(t, cache.Load(DiskCacheLanguages, "key", &lines))
+	var lang string
+	assert.Nil(t, cache.Store(DiskCacheLanguages, "key", ""))
+	assert.True(t, cache.Load(DiskCacheLanguages, "key", &lang))
+	assert.Equal(t, "", lang)
+	data := FileDiffData{OldLinesOfCode: 1, NewLinesOfCode: 2, Diffs: []diffmatchpatch.Diff{
+		{Type: diffmatchpatch.DiffDelete, Text: "\x00"},
+		{Type: diffmatchpatch.DiffInsert, Text: "\x01\x02"},
+	}}
+	assert.Nil(t, cache.Store(DiskCacheFileDiffs, "key", data))
+	var loaded FileDiffData
+	assert.True(t, cache.Load(DiskCacheFileDiffs, "key", &loaded))
+	assert.Equal(t, data, loaded)
+	// the type mismatch is a miss
+	assert.False(t, cache.Load(DiskCacheFileDiffs, "key", &lines))
+	// no temporary files are left
+	files, _ := filepath.Glob(filepath.Join(cache.Directory, "*", "*", "*", "*"))
+	assert.Len(t, files, 3)
+	// the empty key is never cached
+	assert.Nil(t, cache.Store(DiskCacheLineCounts, "", 1))
+	assert.False(t, cache.Load(DiskCacheLineCounts, "", &lines))
+	// a corrupted file is a miss
+	assert.Nil(t, ioutil.WriteFile(cache.path(DiskCacheLineCounts, "key"), []byte("xxx"), 0644))
+	assert.False(t, cache.Load(DiskCacheLineCounts, "key", &lines))
+}
+
+func TestDiskCacheNil(t *testing.T) {
+	var cache *DiskCache
+	var lines int
+	assert.Nil(t, cache.Store(DiskCacheLineCounts, "key", 1))
+	assert.False(t, cache.Load(DiskCacheLineCounts, "key", &lines))
+}
+
+func TestDiskCacheStoreError(t *testing.T) {
+	cache, cleanup := fixtureDiskCache(t)
+	defer cleanup()
+	// a file blocks the directory
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(cache.Directory, "v"+diskCacheVersion),
+		nil, 0644))
+	assert.NotNil(t, cache.Store(DiskCacheLineCounts, "key", 1))
+}
+
+func TestDiskCacheConcurrent(t *testing.T) {
+	cache, cleanup := fixtureDiskCache(t)
+	defer cleanup()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			other := &DiskCache{Directory: cache.Directory}
+			for j := 0; j < 20; j++ {
+				assert.Nil(t, other.Store(DiskCacheLanguages, "key", "Go"))
+				var lang string
+				assert.True(t, other.Load(DiskCacheLanguages, "key", &lang))
+				assert.Equal(t, "Go", lang)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDiskCachePipelineItems(t *testing.T) {
+	cache, cleanup := fixtureDiskCache(t)
+	defer cleanup()
+	facts := map[string]interface{}{ConfigCacheDirectory: cache.Directory}
+	blobs := map[plumbing.Hash]*CachedBlob{}
+	AddHash(t, blobs, "334cde09da4afcb74f8d2b3e6fd6cce61228b485")
+	AddHash(t, blobs, "dc248ba2b22048cc730c571a748e8ffcf7085ab9")
+	AddHash(t, blobs, "291286b4ac41952cbd1389fda66420ec03c1a9fe")
+	treeFrom, _ := test.Repository.TreeObject(plumbing.NewHash(
+		"a1eb2ea76eb7f9bfbde9b243861474421000eb96"))
+	treeTo, _ := test.Repository.TreeObject(plumbing.NewHash(
+		"994eac1cd07235bb9815e547a75c84265dea00f5"))
+	changes := object.Changes{&object.Change{From: object.ChangeEntry{
+		Name: "analyser.go", Tree: treeFrom, TreeEntry: object.TreeEntry{
+			Name: "analyser.go", Mode: 0100644,
+			Hash: plumbing.NewHash("dc248ba2b22048cc730c571a748e8ffcf7085ab9"),
+		},
+	}, To: object.ChangeEntry{
+		Name: "analyser.go", Tree: treeTo, TreeEntry: object.TreeEntry{
+			Name: "analyser.go", Mode: 0100644,
+			Hash: plumbing.NewHash("334cde09da4afcb74f8d2b3e6fd6cce61228b485"),
+		},
+	}}, &object.Change{To: object.ChangeEntry{
+		Name: ".travis.yml", Tree: treeTo, TreeEntry: object.TreeEntry{
+			Name: ".travis.yml", Mode: 0100644,
+			Hash: plumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe"),
+		},
+	}}}
+	run := func() map[string]interface{} {
+		deps := map[string]interface{}{
+			DependencyBlobCache: blobs, DependencyTreeChanges: changes,
+		}
+		for _, item := range []interface {
+			Configure(map[string]interface{}) error
+			Consume(map[string]interface{}) (map[string]interface{}, error)
+		}{&FileDiff{}, &LanguagesDetection{}, &LinesStatsCalculator{}} {
+			assert.Nil(t, item.Configure(facts))
+			result, err := item.Consume(deps)
+			assert.Nil(t, err)
+			for key, val := range result {
+				deps[key] = val
+			}
+		}
+		return deps
+	}
+	deps1 := run()
+	for _, kind := range []string{DiskCacheFileDiffs, DiskCacheLanguages, DiskCacheLineCounts} {
+		files, _ := filepath.Glob(filepath.Join(cache.Directory, "v"+diskCacheVersion, kind, "*", "*"))
+		assert.True(t, len(files) > 0, kind)
+	}
+	// poison the cached diff to check that it is used
+	var key string
+	for _, change := range changes {
+		if action, _ := change.Action(); action == merkletrie.Modify {
+			key = change.From.TreeEntry.Hash.String() + " " + change.To.TreeEntry.Hash.String() +
+				" false false " + DiffAlgorithmDMP
+		}
+	}
+	poisoned := FileDiffData{OldLinesOfCode: 1, NewLinesOfCode: 1}
+	assert.Nil(t, cache.Store(DiskCacheFileDiffs, key, poisoned))
+	deps2 := run()
+	assert.Equal(t, poisoned, deps2[DependencyFileDiff].(map[string]FileDiffData)["analyser.go"])
+	assert.Equal(t, deps1[DependencyLanguages], deps2[DependencyLanguages])
+	stats1 := deps1[DependencyLineStats].(map[object.ChangeEntry]FileLineStats)
+	stats2 := deps2[DependencyLineStats].(map[object.ChangeEntry]FileLineStats)
+	assert.Equal(t, stats1[changes[1].To], stats2[changes[1].To])
+}
@@ -0,0 +1,140 @@
+package plumbing
+
+import (
+	"bytes"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/gitattributes"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const (
+	// gitAttributesFile is the name of the files which set the path attributes.
+	gitAttributesFile = ".gitattributes"
+
+	attrLinguistGenerated = "linguist-generated"
+	attrLinguistVendored  = "linguist-vendored"
+	attrLinguistLanguage  = "linguist-language"
+	attrBinary            = "binary"
+	attrDiff              = "diff"
+)
+
+// gitAttributes holds the parsed .gitattributes files of the analysed tree, including
+// the nested ones. It is immutable so that the forked TreeDiff-s can share it.
+// nil *gitAttributes is valid and matches nothing.
+type gitAttributes struct {
+	// files maps the paths of .gitattributes files to their parsed contents.
+	files   map[string][]gitattributes.MatchAttribute
+	matcher gitattributes.Matcher
+}
+
+// pathAttributes are the attributes of a single path.
+type pathAttributes map[string]gitattributes.Attribute
+
+// update returns the gitAttributes which reflect the changed .gitattributes files.
+// The receiver is returned as is if there are no such changes.
+func (ga *gitAttributes) update(
+	changes object.Changes, load func(hash plumbing.Hash) ([]byte, error)) (*gitAttributes, error) {
+	var updated *gitAttributes
+	for _, change := range changes {
+		if path.Base(change.From.Name) != gitAttributesFile &&
+			path.Base(change.To.Name) != gitAttributesFile {
+			continue
+		}
+		if updated == nil {
+			updated = &gitAttributes{files: map[string][]gitattributes.MatchAttribute{}}
+			if ga != nil {
+				for key, val := range ga.files {
+					updated.files[key] = val
+				}
+			}
+		}
+		if change.From.Name != "" {
+			delete(updated.files, change.From.Name)
+		}
+		if path.Base(change.To.Name) != gitAttributesFile {
+			continue
+		}
+		contents, err := load(change.To.TreeEntry.Hash)
+		if err != nil {
+			return nil, err
+		}
+		var domain []string
+		if dir := path.Dir(change.To.Name); dir != "." {
+			domain = strings.Split(dir, "/")
+		}
+		attrs, err := gitattributes.ReadAttributes(bytes.NewReader(contents), domain, domain == nil)
+		if err != nil {
+			log.Printf("%s: %v\n", change.To.Name, err)
+		}
+		updated.files[change.To.Name] = attrs
+	}
+	if updated == nil {
+		return ga, nil
+	}
+	names := make([]string, 0, len(updated.files))
+	for name := range updated.files {
+		names = append(names, name)
+	}
+	// the deeper files have the higher priority
+	sort.Slice(names, func(i, j int) bool {
+		di, dj := strings.Count(names[i], "/"), strings.Count(names[j], "/")
+		if di != dj {
+			return di < dj
+		}
+		return names[i] < names[j]
+	})
+	var stack []gitattributes.MatchAttribute
+	for _, name := range names {
+		stack = append(stack, updated.files[name]...)
+	}
+	// gitattributes.Matcher lets the first matching pattern in the stack win
+	for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+		stack[i], stack[j] = stack[j], stack[i]
+	}
+	updated.matcher = gitattributes.NewMatcher(stack)
+	return updated, nil
+}
+
+// match returns the attributes of the file.
+func (ga *gitAttributes) match(name string) pathAttributes {
+	if ga == nil || name == "" {
+		return nil
+	}
+	attrs, _ := ga.matcher.Match(strings.Split(name, "/"), nil)
+	return attrs
+}
+
+// flag returns the boolean value of the attribute and whether it is specified at all.
+// The values "false" and "0" are false, the same as in GitHub Linguist.
+func (attrs pathAttributes) flag(name string) (value bool, specified bool) {
+	attr, exists := attrs[name]
+	if !exists || attr.IsUnspecified() {
+		return false, false
+	}
+	if attr.IsValueSet() {
+		return attr.Value() != "false" && attr.Value() != "0", true
+	}
+	return attr.IsSet(), true
+}
+
+// binary checks whether the file is marked as binary or not diffable.
+func (attrs pathAttributes) binary() bool {
+	if binary, _ := attrs.flag(attrBinary); binary {
+		return true
+	}
+	diff, specified := attrs.flag(attrDiff)
+	return specified && !diff
+}
+
+// language returns the value of linguist-language or an empty string.
+func (attrs pathAttributes) language() string {
+	if attr, exists := attrs[attrLinguistLanguage]; exists && attr.IsValueSet() {
+		return attr.Value()
+	}
+	return ""
+}
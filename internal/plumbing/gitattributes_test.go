@@ -0,0 +1,248 @@
+package plumbing
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+	"gopkg.in/src-d/hercules.v8/internal"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+)
+
+// fixtureGitAttributes maps the fake blob hashes to the contents of .gitattributes files.
+func fixtureGitAttributes(contents ...string) (
+	func(name string, index int) *object.Change, func(hash plumbing.Hash) ([]byte, error)) {
+	hashes := map[plumbing.Hash][]byte{}
+	for i, text := range contents {
+		hashes[plumbing.Hash{byte(i + 1)}] = []byte(text)
+	}
+	change := func(name string, index int) *object.Change {
+		return &object.Change{To: object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+			Name: ".gitattributes", Hash: plumbing.Hash{byte(index + 1)}}}}
+	}
+	load := func(hash plumbing.Hash) ([]byte, error) {
+		if text, exists := hashes[hash]; exists {
+			return text, nil
+		}
+		return nil, errors.New("not found")
+	}
+	return change, load
+}
+
+func TestGitAttributesUpdateMatch(t *testing.T) {
+	change, load := fixtureGitAttributes(
+		"*.pb.go linguist-generated\nvendor/** linguist-vendored\n*.png binary\n*.svg -diff\n"+
+			"*.h linguist-language=C++\n",
+		"*.pb.go -linguist-generated\nlib.go linguist-vendored=false\n",
+		"*.pb.go linguist-generated=true\n")
+	var ga *gitAttributes
+	assert.Nil(t, ga.match("a.pb.go"))
+	same, err := ga.update(object.Changes{
+		&object.Change{To: object.ChangeEntry{Name: "main.go"}}}, load)
+	assert.Nil(t, err)
+	assert.Nil(t, same)
+
+	ga, err = ga.update(object.Changes{change(".gitattributes", 0), change("api/.gitattributes", 1)}, load)
+	assert.Nil(t, err)
+	assert.Len(t, ga.files, 2)
+	flag := func(name, attr string) []bool {
+		value, specified := ga.match(name).flag(attr)
+		return []bool{value, specified}
+	}
+	assert.Equal(t, []bool{true, true}, flag("pb/x.pb.go", attrLinguistGenerated))
+	assert.Equal(t, []bool{false, true}, flag("api/x.pb.go", attrLinguistGenerated))
+	assert.Equal(t, []bool{false, false}, flag("x.go", attrLinguistGenerated))
+	assert.Equal(t, []bool{true, true}, flag("vendor/a/b.go", attrLinguistVendored))
+	assert.Equal(t, []bool{false, true}, flag("api/lib.go", attrLinguistVendored))
+	assert.True(t, ga.match("img/logo.png").binary())
+	assert.True(t, ga.match("logo.svg").binary())
+	assert.False(t, ga.match("main.go").binary())
+	assert.Equal(t, "C++", ga.match("include/x.h").language())
+	assert.Equal(t, "", ga.match("x.c").language())
+
+	// modification of the nested file does not affect the previous instance
+	updated, err := ga.update(object.Changes{&object.Change{
+		From: change("api/.gitattributes", 1).To, To: change("api/.gitattributes", 2).To}}, load)
+	assert.Nil(t, err)
+	assert.Equal(t, []bool{false, true}, flag("api/x.pb.go", attrLinguistGenerated))
+	value, _ := updated.match("api/x.pb.go").flag(attrLinguistGenerated)
+	assert.True(t, value)
+
+	// deletion
+	updated, err = updated.update(object.Changes{&object.Change{
+		From: change("api/.gitattributes", 2).To}}, load)
+	assert.Nil(t, err)
+	assert.Len(t, updated.files, 1)
+	_, specified := updated.match("api/lib.go").flag(attrLinguistVendored)
+	assert.False(t, specified)
+
+	_, err = ga.update(object.Changes{change("x/.gitattributes", 10)}, load)
+	assert.NotNil(t, err)
+}
+
+func TestTreeDiffFilterGitAttributes(t *testing.T) {
+	change, load := fixtureGitAttributes(
+		"*.pb.go linguist-generated\n*.png binary\nthird_party/** -linguist-vendored\n")
+	td := fixtureTreeDiff()
+	assert.Nil(t, td.Configure(map[string]interface{}{
+		ConfigTreeDiffGitAttributes: true,
+		ConfigTreeDiffSkipGenerated: true,
+	}))
+	assert.True(t, td.GitAttributes)
+	assert.True(t, td.SkipGenerated)
+	var err error
+	td.attributes, err = td.attributes.update(object.Changes{change(".gitattributes", 0)}, load)
+	assert.Nil(t, err)
+	td.SkipFiles = []string{"vendor/"}
+	diffs := object.Changes{}
+	for _, name := range []string{
+		"main.go", "api/api.pb.go", "logo.png", "vendor/x.go", "third_party/x.go"} {
+		diffs = append(diffs, &object.Change{To: object.ChangeEntry{Name: name}})
+	}
+	filtered := td.filterDiffs(diffs, td.attributes)
+	var names []string
+	for _, change := range filtered {
+		names = append(names, change.To.Name)
+	}
+	assert.Equal(t, []string{"main.go", "third_party/x.go"}, names)
+}
+
+func TestTreeDiffConsumeGitAttributes(t *testing.T) {
+	td := fixtureTreeDiff()
+	td.GitAttributes = true
+	// the test repository has no .gitattributes
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"2b1ed978194a94edeabbca6de7ff3b5771d4d665"))
+	res, err := td.Consume(map[string]interface{}{core.DependencyCommit: commit})
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyTreeChanges].(object.Changes), 21)
+	assert.Nil(t, td.attributes)
+}
+
+// fixtureLinearHistory commits the trees one after another to a new in-memory repository.
+// Each tree maps the file names to their contents.
+func fixtureLinearHistory(t *testing.T, trees ...map[string]string) (
+	*git.Repository, []*object.Commit) {
+	storage := memory.NewStorage()
+	repository, err := git.Init(storage, nil)
+	assert.Nil(t, err)
+	store := func(obj interface {
+		Encode(plumbing.EncodedObject) error
+	}) plumbing.Hash {
+		encoded := storage.NewEncodedObject()
+		assert.Nil(t, obj.Encode(encoded))
+		hash, err := storage.SetEncodedObject(encoded)
+		assert.Nil(t, err)
+		return hash
+	}
+	blob := func(text string) plumbing.Hash {
+		obj := storage.NewEncodedObject()
+		obj.SetType(plumbing.BlobObject)
+		writer, _ := obj.Writer()
+		writer.Write([]byte(text))
+		writer.Close()
+		hash, err := storage.SetEncodedObject(obj)
+		assert.Nil(t, err)
+		return hash
+	}
+	var commits []*object.Commit
+	var parents []plumbing.Hash
+	for i, files := range trees {
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		tree := &object.Tree{}
+		for _, name := range names {
+			tree.Entries = append(tree.Entries, object.TreeEntry{
+				Name: name, Mode: filemode.Regular, Hash: blob(files[name])})
+		}
+		treeHash := store(tree)
+		signature := object.Signature{Name: "test", Email: "test@srcd"}
+		commitHash := store(&object.Commit{
+			Author: signature, Committer: signature, Message: strconv.Itoa(i),
+			TreeHash: treeHash, ParentHashes: parents,
+		})
+		commit, err := object.GetCommit(storage, commitHash)
+		assert.Nil(t, err)
+		commits = append(commits, commit)
+		parents = []plumbing.Hash{commitHash}
+	}
+	return repository, commits
+}
+
+func TestTreeDiffConsumeGitAttributesChange(t *testing.T) {
+	var trees []map[string]string
+	for i, attributes := range []string{"*.pb.go linguist-generated\n", "*.pb.go diff=go\n"} {
+		trees = append(trees, map[string]string{
+			".gitattributes": attributes,
+			"api.pb.go":      string(rune('a'+i)) + "\n",
+			"main.go":        "package main\n",
+		})
+	}
+	repository, commits := fixtureLinearHistory(t, trees...)
+	td := &TreeDiff{}
+	assert.Nil(t, td.Configure(map[string]interface{}{ConfigTreeDiffGitAttributes: true}))
+	assert.Nil(t, td.Initialize(repository))
+	names := func(commit *object.Commit) []string {
+		res, err := td.Consume(map[string]interface{}{core.DependencyCommit: commit})
+		assert.Nil(t, err)
+		var names []string
+		for _, change := range res[DependencyTreeChanges].(object.Changes) {
+			names = append(names, change.To.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{".gitattributes", "main.go"}, names(commits[0]))
+	assert.Equal(t, []string{".gitattributes", "api.pb.go"}, names(commits[1]))
+}
+
+func TestTreeDiffConsumeGitAttributesReclassify(t *testing.T) {
+	var trees []map[string]string
+	for i, attributes := range []string{
+		"*.txt text\n", "*.pb.go linguist-generated\n", "*.pb.go linguist-generated\n",
+		"*.txt text\n", "*.pb.go binary\n"} {
+		trees = append(trees, map[string]string{
+			".gitattributes": attributes,
+			"api.pb.go":      string(rune('a'+internal.Min(i, 2))) + "\n",
+			"main.go":        "package main\n",
+		})
+	}
+	repository, commits := fixtureLinearHistory(t, trees...)
+	td := &TreeDiff{}
+	assert.Nil(t, td.Configure(map[string]interface{}{ConfigTreeDiffGitAttributes: true}))
+	assert.Nil(t, td.Initialize(repository))
+	// "+" is an insertion, "-" is a deletion
+	consume := func(commit *object.Commit) []string {
+		res, err := td.Consume(map[string]interface{}{core.DependencyCommit: commit})
+		assert.Nil(t, err)
+		changes := []string{}
+		for _, change := range res[DependencyTreeChanges].(object.Changes) {
+			if change.From.Name == "" {
+				changes = append(changes, "+"+change.To.Name)
+			} else if change.To.Name == "" {
+				changes = append(changes, "-"+change.From.Name)
+			} else {
+				changes = append(changes, change.To.Name)
+			}
+		}
+		return changes
+	}
+	assert.Equal(t, []string{"+.gitattributes", "+api.pb.go", "+main.go"}, consume(commits[0]))
+	// the tracked file becomes generated and is modified
+	assert.Equal(t, []string{".gitattributes", "-api.pb.go"}, consume(commits[1]))
+	assert.Equal(t, []string{}, consume(commits[2]))
+	// the attribute is cleared and the file is not modified
+	assert.Equal(t, []string{".gitattributes", "+api.pb.go"}, consume(commits[3]))
+	// the file becomes binary and is not modified
+	assert.Equal(t, []string{".gitattributes", "-api.pb.go"}, consume(commits[4]))
+}
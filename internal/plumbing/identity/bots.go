@@ -0,0 +1,88 @@
+package identity
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// BotsPolicy defines what Detector does with the commits made by bots and service accounts.
+type BotsPolicy string
+
+const (
+	// BotsKeep disables the bot detection, the bots are treated the same as humans.
+	BotsKeep BotsPolicy = ""
+	// BotsExclude does not credit the bot commits to anybody.
+	BotsExclude BotsPolicy = "exclude"
+	// BotsMerge attributes all the bot commits to the single synthetic identity BotsName.
+	BotsMerge BotsPolicy = "merge"
+	// BotsFlag keeps the bots as separate identities and lists them in FactBots.
+	BotsFlag BotsPolicy = "flag"
+
+	// BotsName is the name of the synthetic identity of all the bots under BotsMerge.
+	BotsName = "<bots>"
+)
+
+// builtinBotPatterns match the names and the emails of the well-known bots.
+var builtinBotPatterns = []string{
+	`\[bot\]$`,
+	`\[bot\]@`,
+	`^(dependabot|dependabot-preview|renovate|renovate-bot|greenkeeper|snyk-bot|github-actions|semantic-release-bot)$`,
+	`^(no-?reply|bots?|ci|build|release)@`,
+}
+
+// ParseBotPatterns compiles the built-in bot patterns together with the user-supplied
+// regular expressions. All of them are case-insensitive.
+func ParseBotPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var result []*regexp.Regexp
+	for _, pattern := range append(append([]string{}, builtinBotPatterns...), patterns...) {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid bot pattern %s", pattern)
+		}
+		result = append(result, re)
+	}
+	return result, nil
+}
+
+// isBot returns true if the name or the email of the signature match any of BotPatterns.
+func (detector *Detector) isBot(signature object.Signature) bool {
+	if detector.BotsPolicy == BotsKeep {
+		return false
+	}
+	for _, re := range detector.BotPatterns {
+		if re.MatchString(signature.Name) || re.MatchString(signature.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+// botNames returns the sorted names of the identities in ReversedPeopleDict which are bots.
+func (detector *Detector) botNames() []string {
+	var names []string
+	switch detector.BotsPolicy {
+	case BotsMerge:
+		if id, exists := detector.PeopleDict[BotsName]; exists {
+			names = append(names, detector.ReversedPeopleDict[id])
+		}
+	case BotsFlag:
+		flagged := map[int]bool{}
+		for key, id := range detector.PeopleDict {
+			if flagged[id] {
+				continue
+			}
+			for _, re := range detector.BotPatterns {
+				if re.MatchString(key) {
+					flagged[id] = true
+					names = append(names, detector.ReversedPeopleDict[id])
+					break
+				}
+			}
+		}
+		sort.Strings(names)
+	}
+	return names
+}
@@ -0,0 +1,150 @@
+package identity
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+)
+
+func fixtureBotCommits() []*object.Commit {
+	commit := func(name, email string) *object.Commit {
+		fake := getFakeCommitWithFile(".mailmap", "")
+		fake.Author = object.Signature{Name: name, Email: email}
+		return fake
+	}
+	return []*object.Commit{
+		commit("Vadim Markovtsev", "vadim@sourced.tech"),
+		commit("dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com"),
+		commit("Renovate Bot", "bot@renovateapp.com"),
+		commit("Jenkins", "jenkins@example.com"),
+		commit("Alice", "12345+alice@users.noreply.github.com"),
+	}
+}
+
+func TestParseBotPatterns(t *testing.T) {
+	patterns, err := ParseBotPatterns(nil)
+	assert.Nil(t, err)
+	id := Detector{BotsPolicy: BotsFlag, BotPatterns: patterns}
+	for _, commit := range fixtureBotCommits() {
+		assert.Equal(t, commit.Author.Name == "dependabot[bot]" || commit.Author.Name == "Renovate Bot",
+			id.isBot(commit.Author), commit.Author.Name)
+	}
+	assert.True(t, id.isBot(object.Signature{Name: "GitHub-Actions", Email: "x@y.z"}))
+	assert.True(t, id.isBot(object.Signature{Name: "Builder", Email: "No-Reply@example.com"}))
+	id.BotPatterns, err = ParseBotPatterns([]string{"^jenkins$"})
+	assert.Nil(t, err)
+	assert.True(t, id.isBot(object.Signature{Name: "JENKINS", Email: "ci-admin@example.com"}))
+	id.BotsPolicy = BotsKeep
+	assert.False(t, id.isBot(object.Signature{Name: "dependabot[bot]"}))
+	_, err = ParseBotPatterns([]string{"("})
+	assert.NotNil(t, err)
+}
+
+func TestIdentityDetectorConfigureBots(t *testing.T) {
+	facts := map[string]interface{}{core.ConfigPipelineCommits: fixtureBotCommits()}
+	id := Detector{}
+	assert.Nil(t, id.Configure(facts))
+	assert.Equal(t, BotsKeep, id.BotsPolicy)
+	assert.Nil(t, id.BotPatterns)
+	assert.NotContains(t, facts, core.FactBots)
+	assert.Len(t, id.ReversedPeopleDict, 5)
+
+	facts = map[string]interface{}{
+		core.ConfigPipelineCommits:        fixtureBotCommits(),
+		ConfigIdentityDetectorBots:        "flag",
+		ConfigIdentityDetectorBotPatterns: []string{"^jenkins$"},
+	}
+	id = Detector{}
+	assert.Nil(t, id.Configure(facts))
+	assert.Equal(t, BotsFlag, id.BotsPolicy)
+	assert.Len(t, id.ReversedPeopleDict, 5)
+	assert.Equal(t, []string{
+		"dependabot[bot]|49699333+dependabot[bot]@users.noreply.github.com",
+		"jenkins|jenkins@example.com",
+		"renovate bot|bot@renovateapp.com",
+	}, facts[core.FactBots])
+
+	facts[ConfigIdentityDetectorBots] = "merge"
+	delete(facts, FactIdentityDetectorPeopleDict)
+	delete(facts, FactIdentityDetectorReversedPeopleDict)
+	id = Detector{}
+	assert.Nil(t, id.Configure(facts))
+	assert.Equal(t, []string{
+		"vadim markovtsev|vadim@sourced.tech", BotsName, "alice|12345+alice@users.noreply.github.com",
+	}, id.ReversedPeopleDict)
+	assert.Equal(t, []string{BotsName}, facts[core.FactBots])
+
+	facts[ConfigIdentityDetectorBots] = "exclude"
+	delete(facts, FactIdentityDetectorPeopleDict)
+	delete(facts, FactIdentityDetectorReversedPeopleDict)
+	id = Detector{}
+	assert.Nil(t, id.Configure(facts))
+	assert.Equal(t, []string{
+		"vadim markovtsev|vadim@sourced.tech", "alice|12345+alice@users.noreply.github.com",
+	}, id.ReversedPeopleDict)
+	assert.Nil(t, facts[core.FactBots])
+
+	facts[ConfigIdentityDetectorBots] = "drop"
+	assert.NotNil(t, (&Detector{}).Configure(facts))
+	facts[ConfigIdentityDetectorBots] = "flag"
+	facts[ConfigIdentityDetectorBotPatterns] = []string{"["}
+	assert.NotNil(t, (&Detector{}).Configure(facts))
+}
+
+func TestIdentityDetectorConsumeBots(t *testing.T) {
+	commits := fixtureBotCommits()
+	consume := func(id *Detector, commit *object.Commit) (int, []int) {
+		res, err := id.Consume(map[string]interface{}{core.DependencyCommit: commit})
+		assert.Nil(t, err)
+		return res[DependencyAuthor].(int), res[DependencyAuthors].([]int)
+	}
+	patterns, _ := ParseBotPatterns(nil)
+
+	id := &Detector{BotsPolicy: BotsMerge, BotPatterns: patterns}
+	id.GeneratePeopleDict(commits)
+	author, authors := consume(id, commits[1])
+	assert.Equal(t, 1, author)
+	assert.Equal(t, []int{1}, authors)
+	author, _ = consume(id, commits[2])
+	assert.Equal(t, 1, author)
+	commits[0].Message = "Bump\n\nCo-authored-by: dependabot[bot] <support@github.com>\n"
+	id.CoAuthors = true
+	author, authors = consume(id, commits[0])
+	assert.Equal(t, 0, author)
+	assert.Equal(t, []int{0, 1}, authors)
+
+	id = &Detector{BotsPolicy: BotsExclude, BotPatterns: patterns, CoAuthors: true}
+	id.GeneratePeopleDict(commits)
+	author, authors = consume(id, commits[1])
+	assert.Equal(t, AuthorMissing, author)
+	assert.Equal(t, []int{}, authors)
+	author, authors = consume(id, commits[0])
+	assert.Equal(t, 0, author)
+	assert.Equal(t, []int{0}, authors)
+
+	id = &Detector{BotsPolicy: BotsFlag, BotPatterns: patterns}
+	id.GeneratePeopleDict(commits)
+	author, authors = consume(id, commits[1])
+	assert.Equal(t, 1, author)
+	assert.Equal(t, []int{1}, authors)
+}
+
+func TestIdentityDetectorLoadPeopleDictBots(t *testing.T) {
+	tmpf, err := ioutil.TempFile("", "hercules-test-")
+	assert.Nil(t, err)
+	defer os.Remove(tmpf.Name())
+	_, err = tmpf.WriteString("Vadim|vadim@sourced.tech\nAlice|alice@example.com\n")
+	assert.Nil(t, err)
+	assert.Nil(t, tmpf.Close())
+	patterns, _ := ParseBotPatterns(nil)
+	id := &Detector{BotsPolicy: BotsMerge, BotPatterns: patterns}
+	assert.Nil(t, id.LoadPeopleDict(tmpf.Name()))
+	assert.Equal(t, []string{"Vadim", "Alice", BotsName, AuthorMissingName}, id.ReversedPeopleDict)
+	res, err := id.Consume(map[string]interface{}{core.DependencyCommit: fixtureBotCommits()[1]})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, res[DependencyAuthor])
+}
@@ -0,0 +1,81 @@
+package identity
+
+import (
+	"bufio"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// CoAuthorsCredit is the policy which defines how the people-aware analyses credit
+// the several authors of the same commit.
+type CoAuthorsCredit string
+
+const (
+	// CoAuthorsCreditDuplicate gives the full credit for the commit to every author.
+	CoAuthorsCreditDuplicate CoAuthorsCredit = "duplicate"
+	// CoAuthorsCreditSplit divides the changed lines of the commit equally between the authors.
+	// The commit itself still counts for each of them.
+	CoAuthorsCreditSplit CoAuthorsCredit = "split"
+
+	// coAuthoredByTrailer is the commit message trailer which lists an additional author.
+	coAuthoredByTrailer = "Co-authored-by"
+	// signedOffByTrailer is the commit message trailer which lists a person who certified the commit.
+	signedOffByTrailer = "Signed-off-by"
+)
+
+// Share returns the part of the non-negative `value` which is credited to the author number
+// `index` out of `count`. The split remainder goes to the first authors, so that the shares
+// always sum to `value`.
+func (policy CoAuthorsCredit) Share(value, index, count int) int {
+	if policy != CoAuthorsCreditSplit || count <= 1 {
+		return value
+	}
+	share := value / count
+	if index < value%count {
+		share++
+	}
+	return share
+}
+
+// parseTrailers extracts the signatures from the commit message lines which start with any
+// of the specified keys, e.g. "Co-authored-by: Name <email>". The keys are case-insensitive.
+// The signatures without an email are ignored.
+func parseTrailers(message string, keys []string) []object.Signature {
+	if len(keys) == 0 {
+		return nil
+	}
+	var signatures []object.Signature
+	scanner := bufio.NewScanner(strings.NewReader(message))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			continue
+		}
+		matched := false
+		for _, key := range keys {
+			if strings.EqualFold(line[:colon], key) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		value := line[colon+1:]
+		open := strings.Index(value, "<")
+		end := strings.LastIndex(value, ">")
+		if open < 0 || end < open {
+			continue
+		}
+		signature := object.Signature{
+			Name:  strings.TrimSpace(value[:open]),
+			Email: strings.TrimSpace(value[open+1 : end]),
+		}
+		if signature.Email != "" {
+			signatures = append(signatures, signature)
+		}
+	}
+	return signatures
+}
@@ -0,0 +1,114 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+)
+
+const coAuthoredMessage = `Fix the parser
+
+Co-authored-by: Alice <alice@example.com>
+co-authored-by: Bob Smith <BOB@example.com>
+Co-authored-by: Nobody
+Signed-off-by: Carol <carol@example.com>
+`
+
+func TestParseTrailers(t *testing.T) {
+	assert.Nil(t, parseTrailers(coAuthoredMessage, nil))
+	assert.Equal(t, []object.Signature{
+		{Name: "Alice", Email: "alice@example.com"},
+		{Name: "Bob Smith", Email: "BOB@example.com"},
+	}, parseTrailers(coAuthoredMessage, []string{coAuthoredByTrailer}))
+	assert.Equal(t, []object.Signature{
+		{Name: "Alice", Email: "alice@example.com"},
+		{Name: "Bob Smith", Email: "BOB@example.com"},
+		{Name: "Carol", Email: "carol@example.com"},
+	}, parseTrailers(coAuthoredMessage, []string{coAuthoredByTrailer, signedOffByTrailer}))
+	assert.Nil(t, parseTrailers("Co-authored-by: <>\nno trailers: here", []string{coAuthoredByTrailer}))
+}
+
+func TestCoAuthorsCreditShare(t *testing.T) {
+	assert.Equal(t, 7, CoAuthorsCreditDuplicate.Share(7, 2, 3))
+	assert.Equal(t, 7, CoAuthorsCredit("").Share(7, 0, 3))
+	assert.Equal(t, 7, CoAuthorsCreditSplit.Share(7, 0, 1))
+	assert.Equal(t, 3, CoAuthorsCreditSplit.Share(7, 0, 3))
+	assert.Equal(t, 2, CoAuthorsCreditSplit.Share(7, 1, 3))
+	assert.Equal(t, 2, CoAuthorsCreditSplit.Share(7, 2, 3))
+	assert.Equal(t, 1, CoAuthorsCreditSplit.Share(1, 0, 2))
+	assert.Equal(t, 0, CoAuthorsCreditSplit.Share(1, 1, 2))
+}
+
+func TestIdentityDetectorConfigureCoAuthors(t *testing.T) {
+	id := fixtureIdentityDetector()
+	facts := map[string]interface{}{}
+	assert.Nil(t, id.Configure(facts))
+	assert.False(t, id.CoAuthors)
+	assert.False(t, id.SignedOff)
+	assert.Equal(t, CoAuthorsCreditDuplicate, facts[FactIdentityDetectorCoAuthorsCredit])
+	facts[ConfigIdentityDetectorCoAuthors] = true
+	facts[ConfigIdentityDetectorSignedOff] = true
+	facts[ConfigIdentityDetectorCoAuthorsCredit] = "split"
+	assert.Nil(t, id.Configure(facts))
+	assert.True(t, id.CoAuthors)
+	assert.True(t, id.SignedOff)
+	assert.Equal(t, CoAuthorsCreditSplit, facts[FactIdentityDetectorCoAuthorsCredit])
+	facts[ConfigIdentityDetectorCoAuthorsCredit] = "share"
+	assert.NotNil(t, id.Configure(facts))
+}
+
+func TestIdentityDetectorConsumeCoAuthors(t *testing.T) {
+	id := fixtureIdentityDetector()
+	id.PeopleDict["alice@example.com"] = 1
+	id.PeopleDict["bob smith"] = 2
+	id.PeopleDict["carol@example.com"] = 3
+	commit := &object.Commit{
+		Author: object.Signature{Name: "Vadim", Email: "vadim@sourced.tech"},
+		Message: coAuthoredMessage + "Co-authored-by: Vadim <gmarkhor@gmail.com>\n" +
+			"Co-authored-by: Stranger <stranger@example.com>\n",
+	}
+	deps := map[string]interface{}{core.DependencyCommit: commit}
+	res, err := id.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res[DependencyAuthor])
+	assert.Equal(t, []int{0}, res[DependencyAuthors])
+	id.CoAuthors = true
+	res, err = id.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res[DependencyAuthor])
+	assert.Equal(t, []int{0, 1, 2}, res[DependencyAuthors])
+	id.SignedOff = true
+	res, err = id.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, res[DependencyAuthors])
+	commit.Author = object.Signature{Name: "Stranger", Email: "stranger@example.com"}
+	res, err = id.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, AuthorMissing, res[DependencyAuthor])
+	assert.Equal(t, []int{AuthorMissing, 1, 2, 3, 0}, res[DependencyAuthors])
+}
+
+func TestIdentityDetectorGeneratePeopleDictCoAuthors(t *testing.T) {
+	commit := getFakeCommitWithFile(".mailmap", "")
+	commit.Message = coAuthoredMessage + "Co-authored-by: vadim markovtsev <vadim@other.com>\n"
+	id := fixtureIdentityDetector()
+	id.GeneratePeopleDict([]*object.Commit{commit})
+	assert.Len(t, id.ReversedPeopleDict, 1)
+	id = fixtureIdentityDetector()
+	id.CoAuthors = true
+	id.GeneratePeopleDict([]*object.Commit{commit})
+	assert.Equal(t, []string{
+		"vadim markovtsev|vadim@other.com|vadim@sourced.tech",
+		"alice|alice@example.com",
+		"bob smith|bob@example.com",
+	}, id.ReversedPeopleDict)
+	assert.Equal(t, 2, id.PeopleDict["bob@example.com"])
+	id = fixtureIdentityDetector()
+	id.CoAuthors = true
+	id.SignedOff = true
+	id.GeneratePeopleDict([]*object.Commit{commit})
+	assert.Len(t, id.ReversedPeopleDict, 4)
+	assert.Equal(t, 3, id.PeopleDict["carol"])
+}
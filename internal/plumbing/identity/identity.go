@@ -2,7 +2,10 @@ package identity
 
 import (
 	"bufio"
+	"encoding/gob"
+	"io"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -21,6 +24,14 @@ type Detector struct {
 	PeopleDict map[string]int
 	// ReversedPeopleDict maps developer id -> description
 	ReversedPeopleDict []string
+	// CoAuthors enables the detection of the additional authors in "Co-authored-by" trailers.
+	CoAuthors bool
+	// SignedOff enables the detection of the additional authors in "Signed-off-by" trailers.
+	SignedOff bool
+	// BotsPolicy defines what to do with the commits made by bots.
+	BotsPolicy BotsPolicy
+	// BotPatterns match the names and the emails of the bots, see ParseBotPatterns().
+	BotPatterns []*regexp.Regexp
 }
 
 const (
@@ -45,9 +56,41 @@ const (
 	// Detector.Configure(). It is equal to the overall number of unique authors
 	// (the length of ReversedPeopleDict).
 	FactIdentityDetectorPeopleCount = "IdentityDetector.PeopleCount"
+	// ConfigIdentityDetectorTeamsPath is the name of the configuration option
+	// (Detector.Configure()) which sets the YAML file with the team -> developers mapping.
+	ConfigIdentityDetectorTeamsPath = "IdentityDetector.TeamsPath"
+	// FactIdentityDetectorTeams is the name of the fact which is inserted in
+	// Detector.Configure(). It contains *Teams loaded from ConfigIdentityDetectorTeamsPath
+	// and is not set if there is no such file. The people-aware leaves additionally
+	// aggregate their results per team if it exists.
+	FactIdentityDetectorTeams = "IdentityDetector.Teams"
+	// ConfigIdentityDetectorCoAuthors is the name of the configuration option
+	// (Detector.Configure()) which sets Detector.CoAuthors.
+	ConfigIdentityDetectorCoAuthors = "IdentityDetector.CoAuthors"
+	// ConfigIdentityDetectorSignedOff is the name of the configuration option
+	// (Detector.Configure()) which sets Detector.SignedOff.
+	ConfigIdentityDetectorSignedOff = "IdentityDetector.SignedOff"
+	// ConfigIdentityDetectorCoAuthorsCredit is the name of the configuration option
+	// (Detector.Configure()) which sets the CoAuthorsCredit policy.
+	ConfigIdentityDetectorCoAuthorsCredit = "IdentityDetector.CoAuthorsCredit"
+	// FactIdentityDetectorCoAuthorsCredit is the name of the fact which is inserted in
+	// Detector.Configure(). It is the CoAuthorsCredit policy which the people-aware leaves
+	// apply to DependencyAuthors.
+	FactIdentityDetectorCoAuthorsCredit = "IdentityDetector.CoAuthorsCreditPolicy"
+	// ConfigIdentityDetectorBots is the name of the configuration option
+	// (Detector.Configure()) which sets Detector.BotsPolicy.
+	ConfigIdentityDetectorBots = "IdentityDetector.Bots"
+	// ConfigIdentityDetectorBotPatterns is the name of the configuration option
+	// (Detector.Configure()) which adds the user-supplied regular expressions to Detector.BotPatterns.
+	ConfigIdentityDetectorBotPatterns = "IdentityDetector.BotPatterns"
 
 	// DependencyAuthor is the name of the dependency provided by Detector.
 	DependencyAuthor = "author"
+	// DependencyAuthors is the name of the dependency provided by Detector: []int with all
+	// the authors of the commit. The first is the same as DependencyAuthor, the rest are
+	// the unique identified authors from the trailers. It is empty if the commit was made
+	// by a bot and BotsPolicy is BotsExclude.
+	DependencyAuthors = "authors"
 )
 
 // Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
@@ -59,7 +102,7 @@ func (detector *Detector) Name() string {
 // Each produced entity will be inserted into `deps` of dependent Consume()-s according
 // to this list. Also used by core.Registry to build the global map of providers.
 func (detector *Detector) Provides() []string {
-	arr := [...]string{DependencyAuthor}
+	arr := [...]string{DependencyAuthor, DependencyAuthors}
 	return arr[:]
 }
 
@@ -77,13 +120,78 @@ func (detector *Detector) ListConfigurationOptions() []core.ConfigurationOption
 		Description: "Path to the file with developer -> name|email associations.",
 		Flag:        "people-dict",
 		Type:        core.PathConfigurationOption,
-		Default:     ""},
+		Default:     ""}, {
+		Name: ConfigIdentityDetectorTeamsPath,
+		Description: "Path to the YAML file with team -> list of developer names|emails " +
+			"associations. Enables the team-level results in the people-aware analyses.",
+		Flag:    "teams",
+		Type:    core.PathConfigurationOption,
+		Default: ""}, {
+		Name:        ConfigIdentityDetectorCoAuthors,
+		Description: "Credit the additional authors listed in \"Co-authored-by\" commit message trailers.",
+		Flag:        "co-authors",
+		Type:        core.BoolConfigurationOption,
+		Default:     false}, {
+		Name:        ConfigIdentityDetectorSignedOff,
+		Description: "Credit the additional authors listed in \"Signed-off-by\" commit message trailers.",
+		Flag:        "signed-off-by",
+		Type:        core.BoolConfigurationOption,
+		Default:     false}, {
+		Name: ConfigIdentityDetectorCoAuthorsCredit,
+		Description: "How to credit several authors of the same commit: \"duplicate\" gives the full " +
+			"credit to each, \"split\" divides the changed lines equally. The burndown lines are " +
+			"always divided because each line has a single owner.",
+		Flag:    "co-authors-credit",
+		Type:    core.StringConfigurationOption,
+		Default: string(CoAuthorsCreditDuplicate)}, {
+		Name: ConfigIdentityDetectorBots,
+		Description: "Detect the commits made by bots and \"exclude\" them, \"merge\" them into " +
+			"a single identity or \"flag\" them in the results. Empty disables the detection.",
+		Flag:    "bots",
+		Type:    core.StringConfigurationOption,
+		Default: ""}, {
+		Name: ConfigIdentityDetectorBotPatterns,
+		Description: "Additional case-insensitive regular expressions which match the names or " +
+			"the emails of the bots. Separated with commas \",\".",
+		Flag:    "bot-patterns",
+		Type:    core.StringsConfigurationOption,
+		Default: []string{}},
 	}
 	return options[:]
 }
 
 // Configure sets the properties previously published by ListConfigurationOptions().
 func (detector *Detector) Configure(facts map[string]interface{}) error {
+	if val, exists := facts[ConfigIdentityDetectorCoAuthors].(bool); exists {
+		detector.CoAuthors = val
+	}
+	if val, exists := facts[ConfigIdentityDetectorSignedOff].(bool); exists {
+		detector.SignedOff = val
+	}
+	credit := CoAuthorsCreditDuplicate
+	if val, _ := facts[ConfigIdentityDetectorCoAuthorsCredit].(string); val != "" {
+		credit = CoAuthorsCredit(val)
+		if credit != CoAuthorsCreditDuplicate && credit != CoAuthorsCreditSplit {
+			return errors.Errorf("unknown co-authors credit policy: %s", val)
+		}
+	}
+	facts[FactIdentityDetectorCoAuthorsCredit] = credit
+	if val, exists := facts[ConfigIdentityDetectorBots].(string); exists {
+		detector.BotsPolicy = BotsPolicy(val)
+		switch detector.BotsPolicy {
+		case BotsKeep, BotsExclude, BotsMerge, BotsFlag:
+		default:
+			return errors.Errorf("unknown bots policy: %s", val)
+		}
+	}
+	if detector.BotsPolicy != BotsKeep {
+		patterns, _ := facts[ConfigIdentityDetectorBotPatterns].([]string)
+		botPatterns, err := ParseBotPatterns(patterns)
+		if err != nil {
+			return err
+		}
+		detector.BotPatterns = botPatterns
+	}
 	if val, exists := facts[FactIdentityDetectorPeopleDict].(map[string]int); exists {
 		detector.PeopleDict = val
 	}
@@ -110,6 +218,23 @@ func (detector *Detector) Configure(facts map[string]interface{}) error {
 	}
 	facts[FactIdentityDetectorPeopleDict] = detector.PeopleDict
 	facts[FactIdentityDetectorReversedPeopleDict] = detector.ReversedPeopleDict
+	if detector.BotsPolicy != BotsKeep {
+		facts[core.FactBots] = detector.botNames()
+	}
+	return detector.configureTeams(facts)
+}
+
+// configureTeams loads the teams mapping if ConfigIdentityDetectorTeamsPath is set.
+func (detector *Detector) configureTeams(facts map[string]interface{}) error {
+	teamsPath, _ := facts[ConfigIdentityDetectorTeamsPath].(string)
+	if teamsPath == "" {
+		return nil
+	}
+	teams, err := LoadTeams(teamsPath, detector.PeopleDict)
+	if err != nil {
+		return errors.Errorf("failed to load %s: %v", teamsPath, err)
+	}
+	facts[FactIdentityDetectorTeams] = teams
 	return nil
 }
 
@@ -126,7 +251,40 @@ func (detector *Detector) Initialize(repository *git.Repository) error {
 // in Provides(). If there was an error, nil is returned.
 func (detector *Detector) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
 	commit := deps[core.DependencyCommit].(*object.Commit)
-	signature := commit.Author
+	authorID := detector.findAuthor(commit.Author)
+	authors := []int{authorID}
+	if detector.BotsPolicy == BotsExclude && detector.isBot(commit.Author) {
+		// the excluded bot commits are not credited to anybody
+		return map[string]interface{}{DependencyAuthor: AuthorMissing, DependencyAuthors: []int{}}, nil
+	}
+	for _, signature := range parseTrailers(commit.Message, detector.trailerKeys()) {
+		coAuthorID := detector.findAuthor(signature)
+		if coAuthorID == AuthorMissing ||
+			(detector.BotsPolicy == BotsExclude && detector.isBot(signature)) {
+			continue
+		}
+		unique := true
+		for _, id := range authors {
+			if id == coAuthorID {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			authors = append(authors, coAuthorID)
+		}
+	}
+	return map[string]interface{}{DependencyAuthor: authorID, DependencyAuthors: authors}, nil
+}
+
+// findAuthor returns the index of the developer with the specified signature or AuthorMissing.
+// The bots are attributed to BotsName under BotsMerge.
+func (detector *Detector) findAuthor(signature object.Signature) int {
+	if detector.BotsPolicy == BotsMerge && detector.isBot(signature) {
+		if botsID, exists := detector.PeopleDict[BotsName]; exists {
+			return botsID
+		}
+	}
 	authorID, exists := detector.PeopleDict[strings.ToLower(signature.Email)]
 	if !exists {
 		authorID, exists = detector.PeopleDict[strings.ToLower(signature.Name)]
@@ -134,7 +292,19 @@ func (detector *Detector) Consume(deps map[string]interface{}) (map[string]inter
 			authorID = AuthorMissing
 		}
 	}
-	return map[string]interface{}{DependencyAuthor: authorID}, nil
+	return authorID
+}
+
+// trailerKeys returns the commit message trailers which list the additional authors.
+func (detector *Detector) trailerKeys() []string {
+	var keys []string
+	if detector.CoAuthors {
+		keys = append(keys, coAuthoredByTrailer)
+	}
+	if detector.SignedOff {
+		keys = append(keys, signedOffByTrailer)
+	}
+	return keys
 }
 
 // Fork clones this PipelineItem.
@@ -142,6 +312,77 @@ func (detector *Detector) Fork(n int) []core.PipelineItem {
 	return core.ForkSamePipelineItem(detector, n)
 }
 
+// detectorCheckpoint is the state of Detector which is saved in Checkpoint().
+type detectorCheckpoint struct {
+	PeopleDict         map[string]int
+	ReversedPeopleDict []string
+}
+
+// Checkpoint writes the identities dictionaries.
+func (detector *Detector) Checkpoint(writer io.Writer) error {
+	return gob.NewEncoder(writer).Encode(&detectorCheckpoint{
+		PeopleDict:         detector.PeopleDict,
+		ReversedPeopleDict: detector.ReversedPeopleDict,
+	})
+}
+
+// Restore reads the state previously written by Checkpoint(). The identities which were
+// discovered after the checkpoint are appended to the restored dictionaries so that the
+// author indices remain valid. The corresponding facts are updated for the dependent items.
+func (detector *Detector) Restore(reader io.Reader, facts map[string]interface{}) error {
+	state := detectorCheckpoint{}
+	err := gob.NewDecoder(reader).Decode(&state)
+	if err != nil {
+		return err
+	}
+	peopleDict := state.PeopleDict
+	reversedPeopleDict := state.ReversedPeopleDict
+	peopleCount := len(reversedPeopleDict)
+	if peopleDictPath, _ := facts[ConfigIdentityDetectorPeopleDictPath].(string); peopleDictPath != "" {
+		// the external dictionary is authoritative, it ends with AuthorMissingName
+		peopleCount--
+	} else {
+		keys := make([]string, 0, len(detector.PeopleDict))
+		for key := range detector.PeopleDict {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		// map the current author indices to the restored ones
+		mapping := map[int]int{}
+		for _, key := range keys {
+			id := detector.PeopleDict[key]
+			if restoredID, exists := peopleDict[key]; exists {
+				if _, mapped := mapping[id]; !mapped {
+					mapping[id] = restoredID
+				}
+			}
+		}
+		for _, key := range keys {
+			if _, exists := peopleDict[key]; exists {
+				continue
+			}
+			id := detector.PeopleDict[key]
+			restoredID, mapped := mapping[id]
+			if !mapped {
+				restoredID = len(reversedPeopleDict)
+				reversedPeopleDict = append(reversedPeopleDict, detector.ReversedPeopleDict[id])
+				mapping[id] = restoredID
+			}
+			peopleDict[key] = restoredID
+		}
+		peopleCount = len(reversedPeopleDict)
+	}
+	detector.PeopleDict = peopleDict
+	detector.ReversedPeopleDict = reversedPeopleDict
+	facts[FactIdentityDetectorPeopleCount] = peopleCount
+	facts[FactIdentityDetectorPeopleDict] = peopleDict
+	facts[FactIdentityDetectorReversedPeopleDict] = reversedPeopleDict
+	if detector.BotsPolicy != BotsKeep {
+		facts[core.FactBots] = detector.botNames()
+	}
+	return detector.configureTeams(facts)
+}
+
 // LoadPeopleDict loads author signatures from a text file.
 // The format is one signature per line, and the signature consists of several
 // keys separated by "|". The first key is the main one and used to reference all the rest.
@@ -163,6 +404,10 @@ func (detector *Detector) LoadPeopleDict(path string) error {
 		reverseDict = append(reverseDict, ids[0])
 		size++
 	}
+	if detector.BotsPolicy == BotsMerge {
+		dict[BotsName] = size
+		reverseDict = append(reverseDict, BotsName)
+	}
 	reverseDict = append(reverseDict, AuthorMissingName)
 	detector.PeopleDict = dict
 	detector.ReversedPeopleDict = reverseDict
@@ -171,69 +416,96 @@ func (detector *Detector) LoadPeopleDict(path string) error {
 
 // GeneratePeopleDict loads author signatures from the specified list of Git commits.
 func (detector *Detector) GeneratePeopleDict(commits []*object.Commit) {
+	detector.GenerateSharedPeopleDict([][]*object.Commit{commits})
+}
+
+// GenerateSharedPeopleDict loads author signatures from several lists of Git commits, e.g.
+// from different repositories, so that the same developer has the same index in all of them.
+// .mailmap is read from the last commit in each list. The signatures in the commit message
+// trailers are included if CoAuthors or SignedOff is set.
+func (detector *Detector) GenerateSharedPeopleDict(histories [][]*object.Commit) {
 	dict := map[string]int{}
 	emails := map[int][]string{}
 	names := map[int][]string{}
 	size := 0
 
-	mailmapFile, err := commits[len(commits)-1].File(".mailmap")
-	if err == nil {
-		mailMapContents, err := mailmapFile.Contents()
+	for _, commits := range histories {
+		if len(commits) == 0 {
+			continue
+		}
+		mailmapFile, err := commits[len(commits)-1].File(".mailmap")
 		if err == nil {
-			mailmap := ParseMailmap(mailMapContents)
-			for key, val := range mailmap {
-				key = strings.ToLower(key)
-				toEmail := strings.ToLower(val.Email)
-				toName := strings.ToLower(val.Name)
-				id, exists := dict[toEmail]
-				if !exists {
-					id, exists = dict[toName]
-				}
-				if exists {
-					dict[key] = id
-				} else {
-					id = size
-					size++
-					if toEmail != "" {
-						dict[toEmail] = id
-						emails[id] = append(emails[id], toEmail)
-					}
-					if toName != "" {
-						dict[toName] = id
-						names[id] = append(names[id], toName)
-					}
-					dict[key] = id
-				}
-				if strings.Contains(key, "@") {
-					exists := false
-					for _, val := range emails[id] {
-						if key == val {
-							exists = true
-							break
-						}
-					}
+			mailMapContents, err := mailmapFile.Contents()
+			if err == nil {
+				mailmap := ParseMailmap(mailMapContents)
+				for key, val := range mailmap {
+					key = strings.ToLower(key)
+					toEmail := strings.ToLower(val.Email)
+					toName := strings.ToLower(val.Name)
+					id, exists := dict[toEmail]
 					if !exists {
-						emails[id] = append(emails[id], key)
+						id, exists = dict[toName]
 					}
-				} else {
-					exists := false
-					for _, val := range names[id] {
-						if key == val {
-							exists = true
-							break
+					if exists {
+						dict[key] = id
+					} else {
+						id = size
+						size++
+						if toEmail != "" {
+							dict[toEmail] = id
+							emails[id] = append(emails[id], toEmail)
 						}
+						if toName != "" {
+							dict[toName] = id
+							names[id] = append(names[id], toName)
+						}
+						dict[key] = id
 					}
-					if !exists {
-						names[id] = append(names[id], key)
+					if strings.Contains(key, "@") {
+						exists := false
+						for _, val := range emails[id] {
+							if key == val {
+								exists = true
+								break
+							}
+						}
+						if !exists {
+							emails[id] = append(emails[id], key)
+						}
+					} else {
+						exists := false
+						for _, val := range names[id] {
+							if key == val {
+								exists = true
+								break
+							}
+						}
+						if !exists {
+							names[id] = append(names[id], key)
+						}
 					}
 				}
 			}
 		}
 	}
 
-	for _, commit := range commits {
-		email := strings.ToLower(commit.Author.Email)
-		name := strings.ToLower(commit.Author.Name)
+	botsID := -1
+	addSignature := func(signature object.Signature) {
+		if detector.isBot(signature) {
+			switch detector.BotsPolicy {
+			case BotsExclude:
+				return
+			case BotsMerge:
+				if botsID < 0 {
+					botsID = size
+					dict[BotsName] = size
+					size++
+				}
+				return
+			}
+		}
+		email := strings.ToLower(signature.Email)
+		name := strings.ToLower(signature.Name)
 		id, exists := dict[email]
 		if exists {
 			_, exists := dict[name]
@@ -241,13 +513,13 @@ func (detector *Detector) GeneratePeopleDict(commits []*object.Commit) {
 				dict[name] = id
 				names[id] = append(names[id], name)
 			}
-			continue
+			return
 		}
 		id, exists = dict[name]
 		if exists {
 			dict[email] = id
 			emails[id] = append(emails[id], email)
-			continue
+			return
 		}
 		dict[email] = size
 		dict[name] = size
@@ -255,12 +527,24 @@ func (detector *Detector) GeneratePeopleDict(commits []*object.Commit) {
 		names[size] = append(names[size], name)
 		size++
 	}
+	trailerKeys := detector.trailerKeys()
+	for _, commits := range histories {
+		for _, commit := range commits {
+			addSignature(commit.Author)
+			for _, signature := range parseTrailers(commit.Message, trailerKeys) {
+				addSignature(signature)
+			}
+		}
+	}
 	reverseDict := make([]string, size)
 	for _, val := range dict {
 		sort.Strings(names[val])
 		sort.Strings(emails[val])
 		reverseDict[val] = strings.Join(names[val], "|") + "|" + strings.Join(emails[val], "|")
 	}
+	if botsID >= 0 {
+		reverseDict[botsID] = BotsName
+	}
 	detector.PeopleDict = dict
 	detector.ReversedPeopleDict = reverseDict
 }
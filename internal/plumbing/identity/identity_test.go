@@ -1,6 +1,7 @@
 package identity
 
 import (
+	"bytes"
 	"io"
 	"io/ioutil"
 	"os"
@@ -36,11 +37,18 @@ func TestIdentityDetectorMeta(t *testing.T) {
 	id := fixtureIdentityDetector()
 	assert.Equal(t, id.Name(), "IdentityDetector")
 	assert.Equal(t, len(id.Requires()), 0)
-	assert.Equal(t, len(id.Provides()), 1)
+	assert.Equal(t, len(id.Provides()), 2)
 	assert.Equal(t, id.Provides()[0], DependencyAuthor)
+	assert.Equal(t, id.Provides()[1], DependencyAuthors)
 	opts := id.ListConfigurationOptions()
-	assert.Len(t, opts, 1)
+	assert.Len(t, opts, 7)
 	assert.Equal(t, opts[0].Name, ConfigIdentityDetectorPeopleDictPath)
+	assert.Equal(t, opts[1].Name, ConfigIdentityDetectorTeamsPath)
+	assert.Equal(t, opts[2].Name, ConfigIdentityDetectorCoAuthors)
+	assert.Equal(t, opts[3].Name, ConfigIdentityDetectorSignedOff)
+	assert.Equal(t, opts[4].Name, ConfigIdentityDetectorCoAuthorsCredit)
+	assert.Equal(t, opts[5].Name, ConfigIdentityDetectorBots)
+	assert.Equal(t, opts[6].Name, ConfigIdentityDetectorBotPatterns)
 }
 
 func TestIdentityDetectorConfigure(t *testing.T) {
@@ -381,6 +389,34 @@ func TestIdentityDetectorGeneratePeopleDictMailmap(t *testing.T) {
 		"strange guy|vadim markovtsev|gmarkhor@gmail.com|vadim@sourced.tech")
 }
 
+func TestIdentityDetectorGenerateSharedPeopleDict(t *testing.T) {
+	commits := make([]*object.Commit, 0)
+	iter, err := test.Repository.CommitObjects()
+	commit, err := iter.Next()
+	for ; err != io.EOF; commit, err = iter.Next() {
+		if err != nil {
+			panic(err)
+		}
+		commits = append(commits, commit)
+	}
+	id1 := fixtureIdentityDetector()
+	id1.GeneratePeopleDict(commits)
+	id2 := fixtureIdentityDetector()
+	half := len(commits) / 2
+	id2.GenerateSharedPeopleDict([][]*object.Commit{commits[:half], nil, commits[half:]})
+	assert.Equal(t, id1.PeopleDict, id2.PeopleDict)
+	assert.Equal(t, id1.ReversedPeopleDict, id2.ReversedPeopleDict)
+	// .mailmap applies to all the histories
+	fake := getFakeCommitWithFile(
+		".mailmap",
+		"Strange Guy <vadim@sourced.tech>\nVadim Markovtsev <vadim@sourced.tech> Strange Guy <vadim@sourced.tech>")
+	first := append(commits[:half:half], fake)
+	id2.GenerateSharedPeopleDict([][]*object.Commit{first, commits[half:]})
+	assert.Contains(t, id2.ReversedPeopleDict,
+		"strange guy|vadim markovtsev|gmarkhor@gmail.com|vadim@sourced.tech")
+	assert.Len(t, id2.ReversedPeopleDict, len(id1.ReversedPeopleDict))
+}
+
 func TestIdentityDetectorMergeReversedDicts(t *testing.T) {
 	pa1 := [...]string{"one", "two"}
 	pa2 := [...]string{"two", "three"}
@@ -411,3 +447,26 @@ func TestIdentityDetectorFork(t *testing.T) {
 	assert.True(t, id1 == id2)
 	id1.Merge([]core.PipelineItem{id2})
 }
+
+func TestIdentityDetectorCheckpointRestore(t *testing.T) {
+	id := fixtureIdentityDetector()
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, id.Checkpoint(buffer))
+	restored := &Detector{
+		PeopleDict: map[string]int{
+			"new@sourced.tech": 0, "newbie": 0,
+			"gmarkhor@gmail.com": 1, "vadim": 1,
+		},
+		ReversedPeopleDict: []string{"newbie|new@sourced.tech", "vadim|gmarkhor@gmail.com"},
+	}
+	facts := map[string]interface{}{}
+	assert.Nil(t, restored.Restore(buffer, facts))
+	assert.Equal(t, []string{"Vadim", "newbie|new@sourced.tech"}, restored.ReversedPeopleDict)
+	assert.Equal(t, map[string]int{
+		"vadim@sourced.tech": 0, "gmarkhor@gmail.com": 0, "vadim": 0,
+		"new@sourced.tech": 1, "newbie": 1,
+	}, restored.PeopleDict)
+	assert.Equal(t, 2, facts[FactIdentityDetectorPeopleCount])
+	assert.Equal(t, restored.PeopleDict, facts[FactIdentityDetectorPeopleDict])
+	assert.Equal(t, restored.ReversedPeopleDict, facts[FactIdentityDetectorReversedPeopleDict])
+}
@@ -0,0 +1,74 @@
+package identity
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/hercules.v8/internal/yaml"
+)
+
+// TeamMissingName is the name of the team of the developers who do not belong to any team
+// in the mapping. It is always the last in Teams.Names.
+const TeamMissingName = "<unassigned>"
+
+// Teams maps the developers to their teams. The leaves which report per-developer results
+// use it to aggregate them per team.
+type Teams struct {
+	// Names are the team names, the last one is TeamMissingName.
+	Names []string
+	// members maps the author indices to the team indices.
+	members map[int]int
+}
+
+// LoadTeams reads the YAML file which maps the team names to the lists of their members,
+// for example,
+//
+//	backend:
+//	  - alice@example.com
+//	  - Bob Smith
+//	frontend: [carol@example.com, dave]
+//
+// The members are identified by emails or names, the same as in PeopleDict. The people
+// who are mentioned in several teams belong to the first of them.
+func LoadTeams(path string, peopleDict map[string]int) (*Teams, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	names, members, err := yaml.ReadStringLists(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	teams := &Teams{members: map[int]int{}}
+	for _, name := range names {
+		if name == TeamMissingName {
+			return nil, errors.Errorf("%s: team name %s is reserved", path, TeamMissingName)
+		}
+		team := len(teams.Names)
+		teams.Names = append(teams.Names, name)
+		for _, member := range members[name] {
+			author, exists := peopleDict[strings.ToLower(member)]
+			if !exists {
+				continue
+			}
+			if _, assigned := teams.members[author]; !assigned {
+				teams.members[author] = team
+			}
+		}
+	}
+	teams.Names = append(teams.Names, TeamMissingName)
+	return teams, nil
+}
+
+// Team returns the index of the developer's team in Names. AuthorMissing remains as is.
+func (teams *Teams) Team(author int) int {
+	if author == AuthorMissing {
+		return AuthorMissing
+	}
+	if team, exists := teams.members[author]; exists {
+		return team
+	}
+	return len(teams.Names) - 1
+}
@@ -0,0 +1,65 @@
+package identity
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureTeamsFile(t *testing.T, text string) string {
+	tmpf, err := ioutil.TempFile("", "hercules-test-")
+	assert.Nil(t, err)
+	_, err = tmpf.WriteString(text)
+	assert.Nil(t, err)
+	assert.Nil(t, tmpf.Close())
+	return tmpf.Name()
+}
+
+func TestLoadTeams(t *testing.T) {
+	path := fixtureTeamsFile(t, `backend:
+  - Vadim@sourced.tech
+  - nobody@example.com
+frontend: [egor, vadim]
+`)
+	defer os.Remove(path)
+	peopleDict := map[string]int{"vadim@sourced.tech": 0, "vadim": 0, "egor": 1, "alex": 2}
+	teams, err := LoadTeams(path, peopleDict)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"backend", "frontend", TeamMissingName}, teams.Names)
+	assert.Equal(t, 0, teams.Team(0))
+	assert.Equal(t, 1, teams.Team(1))
+	assert.Equal(t, 2, teams.Team(2))
+	assert.Equal(t, AuthorMissing, teams.Team(AuthorMissing))
+	_, err = LoadTeams(path+".absent", peopleDict)
+	assert.NotNil(t, err)
+}
+
+func TestLoadTeamsInvalid(t *testing.T) {
+	for _, text := range []string{"backend: [egor\n", "\"<unassigned>\": [egor]\n"} {
+		path := fixtureTeamsFile(t, text)
+		_, err := LoadTeams(path, map[string]int{"egor": 0})
+		assert.NotNil(t, err)
+		os.Remove(path)
+	}
+}
+
+func TestIdentityDetectorConfigureTeams(t *testing.T) {
+	path := fixtureTeamsFile(t, "backend: [vadim@sourced.tech]\n")
+	defer os.Remove(path)
+	id := fixtureIdentityDetector()
+	facts := map[string]interface{}{
+		FactIdentityDetectorPeopleDict:         id.PeopleDict,
+		FactIdentityDetectorReversedPeopleDict: id.ReversedPeopleDict,
+	}
+	assert.Nil(t, id.Configure(facts))
+	assert.NotContains(t, facts, FactIdentityDetectorTeams)
+	facts[ConfigIdentityDetectorTeamsPath] = path
+	assert.Nil(t, id.Configure(facts))
+	teams := facts[FactIdentityDetectorTeams].(*Teams)
+	assert.Equal(t, []string{"backend", TeamMissingName}, teams.Names)
+	assert.Equal(t, 0, teams.Team(0))
+	facts[ConfigIdentityDetectorTeamsPath] = path + ".absent"
+	assert.NotNil(t, id.Configure(facts))
+}
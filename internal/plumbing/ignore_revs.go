@@ -0,0 +1,180 @@
+package plumbing
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+)
+
+// IgnoreRevisions marks the commits which must not change the attribution of the lines,
+// e.g. the mass reformatting or refactoring, similar to `git blame --ignore-revs-file`.
+// The ignored commits still change the tree, but the analyses do not credit them.
+// It is a PipelineItem.
+type IgnoreRevisions struct {
+	core.NoopMerger
+	// File is the path to the list of the ignored commits. If it is empty, we read
+	// IgnoreRevisionsDefaultFile from the tree of the last analysed commit, if it exists.
+	File string
+	// Revisions is the set of the ignored commits.
+	Revisions map[plumbing.Hash]bool
+
+	commits []*object.Commit
+}
+
+const (
+	// DependencyIgnoredCommit is the name of the dependency provided by IgnoreRevisions.
+	// It is true if the current commit is ignored.
+	DependencyIgnoredCommit = "ignored_commit"
+
+	// ConfigIgnoreRevisionsFile is the name of the configuration option
+	// (IgnoreRevisions.Configure()) which sets the path to the list of the ignored commits.
+	ConfigIgnoreRevisionsFile = "IgnoreRevisions.File"
+
+	// IgnoreRevisionsDefaultFile is the conventional name of the list of the ignored commits
+	// in the root of the repository.
+	IgnoreRevisionsDefaultFile = ".git-blame-ignore-revs"
+)
+
+// Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
+func (ignore *IgnoreRevisions) Name() string {
+	return "IgnoreRevisions"
+}
+
+// Provides returns the list of names of entities which are produced by this PipelineItem.
+// Each produced entity will be inserted into `deps` of dependent Consume()-s according
+// to this list. Also used by core.Registry to build the global map of providers.
+func (ignore *IgnoreRevisions) Provides() []string {
+	arr := [...]string{DependencyIgnoredCommit}
+	return arr[:]
+}
+
+// Requires returns the list of names of entities which are needed by this PipelineItem.
+// Each requested entity will be inserted into `deps` of Consume(). In turn, those
+// entities are Provides() upstream.
+func (ignore *IgnoreRevisions) Requires() []string {
+	return []string{}
+}
+
+// ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
+func (ignore *IgnoreRevisions) ListConfigurationOptions() []core.ConfigurationOption {
+	options := [...]core.ConfigurationOption{{
+		Name: ConfigIgnoreRevisionsFile,
+		Description: "Path to the file with the commits which must not change the line attribution, " +
+			"one full hash per line. Defaults to " + IgnoreRevisionsDefaultFile + " in the analysed tree.",
+		Flag:    "ignore-revs-file",
+		Type:    core.PathConfigurationOption,
+		Default: ""},
+	}
+	return options[:]
+}
+
+// Configure sets the properties previously published by ListConfigurationOptions().
+func (ignore *IgnoreRevisions) Configure(facts map[string]interface{}) error {
+	if val, exists := facts[ConfigIgnoreRevisionsFile].(string); exists {
+		ignore.File = val
+	}
+	if val, exists := facts[core.ConfigPipelineCommits].([]*object.Commit); exists {
+		ignore.commits = val
+	}
+	return nil
+}
+
+// Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
+// calls. The repository which is going to be analysed is supplied as an argument.
+func (ignore *IgnoreRevisions) Initialize(repository *git.Repository) error {
+	ignore.Revisions = map[plumbing.Hash]bool{}
+	if ignore.File != "" {
+		file, err := os.Open(ignore.File)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open the ignored revisions file %s", ignore.File)
+		}
+		defer file.Close()
+		ignore.Revisions, err = parseIgnoredRevisions(file)
+		return errors.Wrapf(err, "failed to read %s", ignore.File)
+	}
+	var head *object.Commit
+	if len(ignore.commits) > 0 {
+		head = ignore.commits[len(ignore.commits)-1]
+	} else if ref, err := repository.Head(); err == nil {
+		head, _ = repository.CommitObject(ref.Hash())
+	}
+	if head == nil {
+		return nil
+	}
+	tree, err := head.Tree()
+	if err != nil {
+		return err
+	}
+	file, err := tree.File(IgnoreRevisionsDefaultFile)
+	if err == object.ErrFileNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	ignore.Revisions, err = parseIgnoredRevisions(reader)
+	return errors.Wrapf(err, "failed to read %s in %s", IgnoreRevisionsDefaultFile, head.Hash.String())
+}
+
+// Consume runs this PipelineItem on the next commit data.
+// `deps` contain all the results from upstream PipelineItem-s as requested by Requires().
+// Additionally, DependencyCommit is always present there and represents the analysed *object.Commit.
+// This function returns the mapping with analysis results. The keys must be the same as
+// in Provides(). If there was an error, nil is returned.
+func (ignore *IgnoreRevisions) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	commit := deps[core.DependencyCommit].(*object.Commit)
+	return map[string]interface{}{DependencyIgnoredCommit: ignore.Revisions[commit.Hash]}, nil
+}
+
+// Fork clones this PipelineItem.
+func (ignore *IgnoreRevisions) Fork(n int) []core.PipelineItem {
+	return core.ForkSamePipelineItem(ignore, n)
+}
+
+// Checkpoint does nothing: IgnoreRevisions does not keep any state between the commits.
+func (ignore *IgnoreRevisions) Checkpoint(writer io.Writer) error {
+	return nil
+}
+
+// Restore does nothing: IgnoreRevisions does not keep any state between the commits.
+func (ignore *IgnoreRevisions) Restore(reader io.Reader, facts map[string]interface{}) error {
+	return nil
+}
+
+// parseIgnoredRevisions reads the full commit hashes, one per line. Empty lines and
+// the text after "#" are ignored, the same as in `git blame --ignore-revs-file`.
+func parseIgnoredRevisions(reader io.Reader) (map[plumbing.Hash]bool, error) {
+	revisions := map[plumbing.Hash]bool{}
+	scanner := bufio.NewScanner(reader)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := scanner.Text()
+		if pos := strings.Index(line, "#"); pos >= 0 {
+			line = line[:pos]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if _, err := hex.DecodeString(line); err != nil || len(line) != 40 {
+			return nil, errors.Errorf("line %d: invalid commit hash %q", lineno, line)
+		}
+		revisions[plumbing.NewHash(line)] = true
+	}
+	return revisions, scanner.Err()
+}
+
+func init() {
+	core.Registry.Register(&IgnoreRevisions{})
+}
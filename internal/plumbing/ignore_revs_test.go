@@ -0,0 +1,90 @@
+package plumbing
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+)
+
+func TestIgnoreRevisionsMeta(t *testing.T) {
+	ignore := &IgnoreRevisions{}
+	assert.Equal(t, ignore.Name(), "IgnoreRevisions")
+	assert.Equal(t, ignore.Provides(), []string{DependencyIgnoredCommit})
+	assert.Len(t, ignore.Requires(), 0)
+	opts := ignore.ListConfigurationOptions()
+	assert.Len(t, opts, 1)
+	assert.Equal(t, opts[0].Name, ConfigIgnoreRevisionsFile)
+	assert.Equal(t, opts[0].Flag, "ignore-revs-file")
+	commits := []*object.Commit{{}}
+	assert.Nil(t, ignore.Configure(map[string]interface{}{
+		ConfigIgnoreRevisionsFile:  "/tmp/revs",
+		core.ConfigPipelineCommits: commits,
+	}))
+	assert.Equal(t, ignore.File, "/tmp/revs")
+	assert.Equal(t, ignore.commits, commits)
+}
+
+func TestIgnoreRevisionsRegistration(t *testing.T) {
+	summoned := core.Registry.Summon((&IgnoreRevisions{}).Name())
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, summoned[0].Name(), "IgnoreRevisions")
+	summoned = core.Registry.Summon((&IgnoreRevisions{}).Provides()[0])
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, summoned[0].Name(), "IgnoreRevisions")
+}
+
+func TestParseIgnoredRevisions(t *testing.T) {
+	revisions, err := parseIgnoredRevisions(strings.NewReader(`# gofmt
+cce947b98a050c6d356bc6ba95030254914027b1
+
+  a3ee37f91f0d705ec9c41ae88426f0ae44b2fbc3 # black
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, map[plumbing.Hash]bool{
+		plumbing.NewHash("cce947b98a050c6d356bc6ba95030254914027b1"): true,
+		plumbing.NewHash("a3ee37f91f0d705ec9c41ae88426f0ae44b2fbc3"): true,
+	}, revisions)
+	_, err = parseIgnoredRevisions(strings.NewReader("cce947b98a050c6d356bc6ba95030254914027b1\ncce947b\n"))
+	assert.EqualError(t, err, `line 2: invalid commit hash "cce947b"`)
+	_, err = parseIgnoredRevisions(strings.NewReader("HEAD~1\n"))
+	assert.NotNil(t, err)
+}
+
+func TestIgnoreRevisionsInitializeConsume(t *testing.T) {
+	file, err := ioutil.TempFile("", "hercules-")
+	assert.Nil(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("cce947b98a050c6d356bc6ba95030254914027b1\n")
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+	ignore := &IgnoreRevisions{File: file.Name()}
+	assert.Nil(t, ignore.Initialize(test.Repository))
+	assert.Len(t, ignore.Revisions, 1)
+	deps := map[string]interface{}{}
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	res, err := ignore.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, true, res[DependencyIgnoredCommit])
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"a3ee37f91f0d705ec9c41ae88426f0ae44b2fbc3"))
+	res, err = ignore.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, false, res[DependencyIgnoredCommit])
+
+	ignore.File = file.Name() + "-missing"
+	assert.NotNil(t, ignore.Initialize(test.Repository))
+
+	// the analysed tree does not have .git-blame-ignore-revs
+	ignore.File = ""
+	ignore.commits = []*object.Commit{deps[core.DependencyCommit].(*object.Commit)}
+	assert.Nil(t, ignore.Initialize(test.Repository))
+	assert.Len(t, ignore.Revisions, 0)
+}
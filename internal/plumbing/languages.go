@@ -1,6 +1,7 @@
 package plumbing
 
 import (
+	"io"
 	"path"
 
 	"gopkg.in/src-d/enry.v1"
@@ -15,6 +16,9 @@ import (
 // LanguagesDetection run programming language detection over the changed files.
 type LanguagesDetection struct {
 	core.NoopMerger
+
+	// diskCache stores the detected languages between the runs, see ConfigCacheDirectory.
+	diskCache *DiskCache
 }
 
 const (
@@ -50,6 +54,7 @@ func (langs *LanguagesDetection) ListConfigurationOptions() []core.Configuration
 
 // Configure sets the properties previously published by ListConfigurationOptions().
 func (langs *LanguagesDetection) Configure(facts map[string]interface{}) error {
+	langs.diskCache = NewDiskCache(facts)
 	return nil
 }
 
@@ -73,18 +78,21 @@ func (langs *LanguagesDetection) Consume(deps map[string]interface{}) (map[strin
 		if err != nil {
 			return nil, err
 		}
+		var entries []object.ChangeEntry
 		switch action {
 		case merkletrie.Insert:
-			result[change.To.TreeEntry.Hash] = langs.detectLanguage(
-				change.To.Name, cache[change.To.TreeEntry.Hash])
+			entries = []object.ChangeEntry{change.To}
 		case merkletrie.Delete:
-			result[change.From.TreeEntry.Hash] = langs.detectLanguage(
-				change.From.Name, cache[change.From.TreeEntry.Hash])
+			entries = []object.ChangeEntry{change.From}
 		case merkletrie.Modify:
-			result[change.To.TreeEntry.Hash] = langs.detectLanguage(
-				change.To.Name, cache[change.To.TreeEntry.Hash])
-			result[change.From.TreeEntry.Hash] = langs.detectLanguage(
-				change.From.Name, cache[change.From.TreeEntry.Hash])
+			entries = []object.ChangeEntry{change.To, change.From}
+		}
+		for _, entry := range entries {
+			lang, err := langs.detectLanguage(entry.Name, cache[entry.TreeEntry.Hash])
+			if err != nil {
+				return nil, err
+			}
+			result[entry.TreeEntry.Hash] = lang
 		}
 	}
 	return map[string]interface{}{DependencyLanguages: result}, nil
@@ -95,8 +103,33 @@ func (langs *LanguagesDetection) Fork(n int) []core.PipelineItem {
 	return core.ForkSamePipelineItem(langs, n)
 }
 
-// detectLanguage returns the programming language of a blob.
-func (langs *LanguagesDetection) detectLanguage(name string, blob *CachedBlob) string {
+// Checkpoint does nothing: LanguagesDetection does not keep any state between the commits.
+func (langs *LanguagesDetection) Checkpoint(writer io.Writer) error {
+	return nil
+}
+
+// Restore does nothing: LanguagesDetection does not keep any state between the commits.
+func (langs *LanguagesDetection) Restore(reader io.Reader, facts map[string]interface{}) error {
+	return nil
+}
+
+// detectLanguage returns the programming language of a blob, possibly from the disk cache.
+func (langs *LanguagesDetection) detectLanguage(name string, blob *CachedBlob) (string, error) {
+	var key string
+	if blobKey := blobKey(blob); blobKey != "" {
+		key = blobKey + " " + path.Base(name)
+	}
+	var lang string
+	if langs.diskCache.Load(DiskCacheLanguages, key, &lang) {
+		return lang, nil
+	}
+	lang = DetectLanguage(name, blob)
+	return lang, langs.diskCache.Store(DiskCacheLanguages, key, lang)
+}
+
+// DetectLanguage returns the programming language of a blob the same way as
+// LanguagesDetection does. Binary blobs have an empty language.
+func DetectLanguage(name string, blob *CachedBlob) string {
 	_, err := blob.CountLines()
 	if err == ErrorBinary {
 		return ""
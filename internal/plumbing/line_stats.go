@@ -0,0 +1,243 @@
+package plumbing
+
+import (
+	"io"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+)
+
+// LinesStatsCalculator measures the numbers of added, removed, changed and moved lines in each
+// changed text file of the commit. It is a PipelineItem.
+type LinesStatsCalculator struct {
+	core.NoopMerger
+
+	// diskCache stores the line counts between the runs, see ConfigCacheDirectory.
+	diskCache *DiskCache
+}
+
+// LineStats holds the numbers of inserted, deleted, changed and moved lines.
+type LineStats struct {
+	// Added is the number of added lines.
+	Added int
+	// Removed is the number of removed lines.
+	Removed int
+	// Changed is the number of changed lines.
+	Changed int
+	// Moved is the number of lines which were moved to this file, see MoveDetection.
+	// They are not counted as added, and their origin is not counted as removed.
+	Moved int
+}
+
+// FileLineStats is the value type of DependencyLineStats.
+type FileLineStats struct {
+	LineStats
+	// Language is the programming language of the file, see LanguagesDetection.
+	Language string
+}
+
+const (
+	// DependencyLineStats is the name of the dependency provided by LinesStatsCalculator:
+	// map[object.ChangeEntry]FileLineStats. The keys are the new entries of the changes and
+	// the old entries of the deletions. Binary files are not included.
+	DependencyLineStats = "line_stats"
+)
+
+// Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
+func (lsc *LinesStatsCalculator) Name() string {
+	return "LinesStats"
+}
+
+// Provides returns the list of names of entities which are produced by this PipelineItem.
+// Each produced entity will be inserted into `deps` of dependent Consume()-s according
+// to this list. Also used by core.Registry to build the global map of providers.
+func (lsc *LinesStatsCalculator) Provides() []string {
+	arr := [...]string{DependencyLineStats}
+	return arr[:]
+}
+
+// Requires returns the list of names of entities which are needed by this PipelineItem.
+// Each requested entity will be inserted into `deps` of Consume(). In turn, those
+// entities are Provides() upstream.
+func (lsc *LinesStatsCalculator) Requires() []string {
+	arr := [...]string{
+		DependencyTreeChanges, DependencyBlobCache, DependencyFileDiff, DependencyLanguages,
+		DependencyMovedBlocks}
+	return arr[:]
+}
+
+// ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
+func (lsc *LinesStatsCalculator) ListConfigurationOptions() []core.ConfigurationOption {
+	return []core.ConfigurationOption{}
+}
+
+// Configure sets the properties previously published by ListConfigurationOptions().
+func (lsc *LinesStatsCalculator) Configure(facts map[string]interface{}) error {
+	lsc.diskCache = NewDiskCache(facts)
+	return nil
+}
+
+// Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
+// calls. The repository which is going to be analysed is supplied as an argument.
+func (lsc *LinesStatsCalculator) Initialize(repository *git.Repository) error {
+	return nil
+}
+
+// Consume runs this PipelineItem on the next commit data.
+// `deps` contain all the results from upstream PipelineItem-s as requested by Requires().
+// Additionally, DependencyCommit is always present there and represents the analysed *object.Commit.
+// This function returns the mapping with analysis results. The keys must be the same as
+// in Provides(). If there was an error, nil is returned.
+func (lsc *LinesStatsCalculator) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	result := map[object.ChangeEntry]FileLineStats{}
+	treeDiff := deps[DependencyTreeChanges].(object.Changes)
+	cache := deps[DependencyBlobCache].(map[plumbing.Hash]*CachedBlob)
+	fileDiffs := deps[DependencyFileDiff].(map[string]FileDiffData)
+	langs := deps[DependencyLanguages].(map[plumbing.Hash]string)
+	movedBlocks, _ := deps[DependencyMovedBlocks].([]MovedBlock)
+	movedFrom, movedTo := MovedLines(movedBlocks)
+	for _, change := range treeDiff {
+		action, err := change.Action()
+		if err != nil {
+			return nil, err
+		}
+		switch action {
+		case merkletrie.Insert:
+			lines, err := lsc.countLines(cache[change.To.TreeEntry.Hash])
+			if err == ErrorBinary {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			moved := len(movedTo[change.To.Name])
+			result[change.To] = FileLineStats{
+				LineStats: LineStats{Added: lines - moved, Moved: moved},
+				Language:  langs[change.To.TreeEntry.Hash],
+			}
+		case merkletrie.Delete:
+			lines, err := lsc.countLines(cache[change.From.TreeEntry.Hash])
+			if err == ErrorBinary {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			result[change.From] = FileLineStats{
+				LineStats: LineStats{Removed: lines - len(movedFrom[change.From.Name])},
+				Language:  langs[change.From.TreeEntry.Hash],
+			}
+		case merkletrie.Modify:
+			result[change.To] = FileLineStats{
+				LineStats: diffLineStats(fileDiffs[change.To.Name].Diffs,
+					movedFrom[change.From.Name], movedTo[change.To.Name]),
+				Language: langs[change.To.TreeEntry.Hash],
+			}
+		}
+	}
+	return map[string]interface{}{DependencyLineStats: result}, nil
+}
+
+// countLines returns the number of lines in the blob, possibly from the disk cache,
+// or ErrorBinary if it is binary.
+func (lsc *LinesStatsCalculator) countLines(blob *CachedBlob) (int, error) {
+	key := blobKey(blob)
+	var lines int
+	if !lsc.diskCache.Load(DiskCacheLineCounts, key, &lines) {
+		var err error
+		lines, err = blob.CountLines()
+		if err == ErrorBinary {
+			lines = -1
+		}
+		if err := lsc.diskCache.Store(DiskCacheLineCounts, key, lines); err != nil {
+			return 0, err
+		}
+	}
+	if lines < 0 {
+		return 0, ErrorBinary
+	}
+	return lines, nil
+}
+
+// DiffLineStats counts the added, removed and changed lines in the line diff of a file,
+// see FileDiffData. A deletion followed by an insertion are changed lines.
+func DiffLineStats(diffs []diffmatchpatch.Diff) LineStats {
+	return diffLineStats(diffs, nil, nil)
+}
+
+// diffLineStats is DiffLineStats() which excludes the moved lines: the deleted lines
+// whose old indexes are in `movedFrom` and the inserted lines whose new indexes are in `movedTo`.
+// The latter are counted in LineStats.Moved.
+func diffLineStats(diffs []diffmatchpatch.Diff, movedFrom, movedTo map[int]int) LineStats {
+	var stats LineStats
+	var removedPending int
+	oldLine, newLine := 0, 0
+	countMoved := func(moved map[int]int, start, length int) int {
+		if len(moved) == 0 {
+			return 0
+		}
+		count := 0
+		for i := start; i < start+length; i++ {
+			if _, exists := moved[i]; exists {
+				count++
+			}
+		}
+		return count
+	}
+	for _, edit := range diffs {
+		length := utf8.RuneCountInString(edit.Text)
+		switch edit.Type {
+		case diffmatchpatch.DiffEqual:
+			if removedPending > 0 {
+				stats.Removed += removedPending
+			}
+			removedPending = 0
+			oldLine += length
+			newLine += length
+		case diffmatchpatch.DiffInsert:
+			moved := countMoved(movedTo, newLine, length)
+			newLine += length
+			stats.Moved += moved
+			added := length - moved
+			if removedPending > added {
+				removed := removedPending - added
+				stats.Changed += added
+				stats.Removed += removed
+			} else {
+				added := added - removedPending
+				stats.Changed += removedPending
+				stats.Added += added
+			}
+			removedPending = 0
+		case diffmatchpatch.DiffDelete:
+			removedPending = length - countMoved(movedFrom, oldLine, length)
+			oldLine += length
+		}
+	}
+	if removedPending > 0 {
+		stats.Removed += removedPending
+	}
+	return stats
+}
+
+// Fork clones this PipelineItem.
+func (lsc *LinesStatsCalculator) Fork(n int) []core.PipelineItem {
+	return core.ForkSamePipelineItem(lsc, n)
+}
+
+// Checkpoint does nothing: LinesStatsCalculator does not keep any state between the commits.
+func (lsc *LinesStatsCalculator) Checkpoint(writer io.Writer) error {
+	return nil
+}
+
+// Restore does nothing: LinesStatsCalculator does not keep any state between the commits.
+func (lsc *LinesStatsCalculator) Restore(reader io.Reader, facts map[string]interface{}) error {
+	return nil
+}
+
+func init() {
+	core.Registry.Register(&LinesStatsCalculator{})
+}
@@ -0,0 +1,145 @@
+package plumbing_test
+
+import (
+	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	items "gopkg.in/src-d/hercules.v8/internal/plumbing"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+	"gopkg.in/src-d/hercules.v8/internal/test/fixtures"
+)
+
+func TestLinesStatsMeta(t *testing.T) {
+	ls := fixtures.LineStats()
+	assert.Equal(t, ls.Name(), "LinesStats")
+	assert.Equal(t, len(ls.Provides()), 1)
+	assert.Equal(t, ls.Provides()[0], items.DependencyLineStats)
+	assert.Equal(t, len(ls.Requires()), 5)
+	assert.Equal(t, ls.Requires()[0], items.DependencyTreeChanges)
+	assert.Equal(t, ls.Requires()[1], items.DependencyBlobCache)
+	assert.Equal(t, ls.Requires()[2], items.DependencyFileDiff)
+	assert.Equal(t, ls.Requires()[3], items.DependencyLanguages)
+	assert.Equal(t, ls.Requires()[4], items.DependencyMovedBlocks)
+	assert.Len(t, ls.ListConfigurationOptions(), 0)
+	assert.Nil(t, ls.Configure(nil))
+}
+
+func TestLinesStatsRegistration(t *testing.T) {
+	summoned := core.Registry.Summon((&items.LinesStatsCalculator{}).Name())
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, summoned[0].Name(), "LinesStats")
+	summoned = core.Registry.Summon((&items.LinesStatsCalculator{}).Provides()[0])
+	assert.True(t, len(summoned) >= 1)
+	matched := false
+	for _, tp := range summoned {
+		matched = matched || tp.Name() == "LinesStats"
+	}
+	assert.True(t, matched)
+}
+
+func TestLinesStatsConsume(t *testing.T) {
+	deps := map[string]interface{}{}
+	cache := map[plumbing.Hash]*items.CachedBlob{}
+	items.AddHash(t, cache, "291286b4ac41952cbd1389fda66420ec03c1a9fe")
+	items.AddHash(t, cache, "334cde09da4afcb74f8d2b3e6fd6cce61228b485")
+	items.AddHash(t, cache, "dc248ba2b22048cc730c571a748e8ffcf7085ab9")
+	items.AddHash(t, cache, "14c3fa5a1cca103032f10379467a3a2f210e5f94")
+	cache[plumbing.NewHash("ffffffffffffffffffffffffffffffffffffffff")] = &items.CachedBlob{
+		Data: []byte{0, 1, 2}}
+	deps[items.DependencyBlobCache] = cache
+	treeFrom, _ := test.Repository.TreeObject(plumbing.NewHash(
+		"a1eb2ea76eb7f9bfbde9b243861474421000eb96"))
+	treeTo, _ := test.Repository.TreeObject(plumbing.NewHash(
+		"994eac1cd07235bb9815e547a75c84265dea00f5"))
+	changes := object.Changes{&object.Change{From: object.ChangeEntry{
+		Name: "analyser.go",
+		Tree: treeFrom,
+		TreeEntry: object.TreeEntry{
+			Name: "analyser.go",
+			Mode: 0100644,
+			Hash: plumbing.NewHash("dc248ba2b22048cc730c571a748e8ffcf7085ab9"),
+		},
+	}, To: object.ChangeEntry{
+		Name: "analyser.go",
+		Tree: treeTo,
+		TreeEntry: object.TreeEntry{
+			Name: "analyser.go",
+			Mode: 0100644,
+			Hash: plumbing.NewHash("334cde09da4afcb74f8d2b3e6fd6cce61228b485"),
+		},
+	}}, &object.Change{From: object.ChangeEntry{}, To: object.ChangeEntry{
+		Name: ".travis.yml",
+		Tree: treeTo,
+		TreeEntry: object.TreeEntry{
+			Name: ".travis.yml",
+			Mode: 0100644,
+			Hash: plumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe"),
+		},
+	}}, &object.Change{From: object.ChangeEntry{
+		Name: "rbtree.go",
+		Tree: treeFrom,
+		TreeEntry: object.TreeEntry{
+			Name: "rbtree.go",
+			Mode: 0100644,
+			Hash: plumbing.NewHash("14c3fa5a1cca103032f10379467a3a2f210e5f94"),
+		},
+	}, To: object.ChangeEntry{},
+	}, &object.Change{From: object.ChangeEntry{}, To: object.ChangeEntry{
+		Name: "burndown.bin",
+		Tree: treeTo,
+		TreeEntry: object.TreeEntry{
+			Name: "burndown.bin",
+			Mode: 0100644,
+			Hash: plumbing.NewHash("ffffffffffffffffffffffffffffffffffffffff"),
+		},
+	}}}
+	deps[items.DependencyTreeChanges] = changes
+	deps[items.DependencyLanguages] = map[plumbing.Hash]string{
+		plumbing.NewHash("334cde09da4afcb74f8d2b3e6fd6cce61228b485"): "Go",
+		plumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe"): "YAML",
+		plumbing.NewHash("14c3fa5a1cca103032f10379467a3a2f210e5f94"): "Go",
+	}
+	res, err := fixtures.FileDiff().Consume(deps)
+	assert.Nil(t, err)
+	deps[items.DependencyFileDiff] = res[items.DependencyFileDiff]
+	res, err = fixtures.LineStats().Consume(deps)
+	assert.Nil(t, err)
+	stats := res[items.DependencyLineStats].(map[object.ChangeEntry]items.FileLineStats)
+	assert.Len(t, stats, 3)
+	assert.Equal(t, items.FileLineStats{
+		LineStats: items.LineStats{Added: 2, Removed: 0, Changed: 13},
+		Language:  "Go",
+	}, stats[changes[0].To])
+	assert.Equal(t, items.FileLineStats{
+		LineStats: items.LineStats{Added: 12, Removed: 0, Changed: 0},
+		Language:  "YAML",
+	}, stats[changes[1].To])
+	assert.Equal(t, "Go", stats[changes[2].From].Language)
+	assert.True(t, stats[changes[2].From].Removed > 0)
+	assert.Equal(t, 0, stats[changes[2].From].Added)
+	_, exists := stats[changes[3].To]
+	assert.False(t, exists)
+}
+
+func TestDiffLineStats(t *testing.T) {
+	diffs := []diffmatchpatch.Diff{
+		{Type: diffmatchpatch.DiffEqual, Text: "aa"},
+		{Type: diffmatchpatch.DiffDelete, Text: "bbb"},
+		{Type: diffmatchpatch.DiffInsert, Text: "c"},
+		{Type: diffmatchpatch.DiffEqual, Text: "d"},
+		{Type: diffmatchpatch.DiffDelete, Text: "e"},
+		{Type: diffmatchpatch.DiffInsert, Text: "fff"},
+		{Type: diffmatchpatch.DiffEqual, Text: "g"},
+		{Type: diffmatchpatch.DiffDelete, Text: "hh"},
+		{Type: diffmatchpatch.DiffEqual, Text: "i"},
+		{Type: diffmatchpatch.DiffInsert, Text: "j"},
+		{Type: diffmatchpatch.DiffDelete, Text: "kk"},
+	}
+	assert.Equal(t, items.LineStats{Added: 3, Removed: 6, Changed: 2},
+		items.DiffLineStats(diffs))
+	assert.Equal(t, items.LineStats{}, items.DiffLineStats(nil))
+}
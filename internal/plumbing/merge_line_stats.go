@@ -0,0 +1,358 @@
+package plumbing
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+)
+
+// MergeLinesStatsCalculator measures the line changes of merge commits relative to their parents.
+// The tree changes and the file diffs of a merge are relative to the previous commit in the same
+// branch of the analysis rather than to the parents, so it compares the trees itself.
+// It is a PipelineItem.
+type MergeLinesStatsCalculator struct {
+	core.NoopMerger
+
+	// Mode is one of MergeDiffFirstParent, MergeDiffCombined, MergeDiffNewLines and MergeDiffNone.
+	Mode string
+
+	// fileDiff calculates the line diffs with the same options and the same disk cache
+	// as FileDiff in the pipeline.
+	fileDiff FileDiff
+}
+
+// MergeFileLineStats is the element type of DependencyMergeLineStats.
+type MergeFileLineStats struct {
+	FileLineStats
+	// ToName is the name of the file in the merge commit, empty if it was deleted.
+	ToName string
+	// FromName is the name of the file in the first parent, empty if it was added.
+	FromName string
+}
+
+const (
+	// DependencyMergeLineStats is the name of the dependency provided by
+	// MergeLinesStatsCalculator: []MergeFileLineStats. It lists the files changed in a merge
+	// commit relative to the first parent according to ConfigMergeDiffMode and is empty
+	// for the other commits. Binary files have zero statistics.
+	DependencyMergeLineStats = "merge_line_stats"
+
+	// ConfigMergeDiffMode is the name of the configuration option
+	// (MergeLinesStatsCalculator.Configure()) which sets how the line changes of merge commits
+	// are counted.
+	ConfigMergeDiffMode = "MergeDiff.Mode"
+
+	// MergeDiffFirstParent counts the line changes of a merge commit relative to the first parent.
+	// The merge author is credited with all the lines brought by the merged branches, which are
+	// already credited to their authors.
+	MergeDiffFirstParent = "first-parent"
+	// MergeDiffCombined counts the line changes relative to the first parent only in the files
+	// which differ from every parent, similar to "git diff --cc". It is the default.
+	MergeDiffCombined = "combined"
+	// MergeDiffNewLines counts only the lines which do not exist in any parent and the lines
+	// which exist in every parent but were removed, that is, the conflict resolutions.
+	MergeDiffNewLines = "new-lines"
+	// MergeDiffNone ignores the line changes of merge commits.
+	MergeDiffNone = "none"
+)
+
+// Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
+func (mlsc *MergeLinesStatsCalculator) Name() string {
+	return "MergeLinesStats"
+}
+
+// Provides returns the list of names of entities which are produced by this PipelineItem.
+// Each produced entity will be inserted into `deps` of dependent Consume()-s according
+// to this list. Also used by core.Registry to build the global map of providers.
+func (mlsc *MergeLinesStatsCalculator) Provides() []string {
+	arr := [...]string{DependencyMergeLineStats}
+	return arr[:]
+}
+
+// Requires returns the list of names of entities which are needed by this PipelineItem.
+// Each requested entity will be inserted into `deps` of Consume(). In turn, those
+// entities are Provides() upstream.
+func (mlsc *MergeLinesStatsCalculator) Requires() []string {
+	return []string{}
+}
+
+// ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
+func (mlsc *MergeLinesStatsCalculator) ListConfigurationOptions() []core.ConfigurationOption {
+	options := [...]core.ConfigurationOption{{
+		Name: ConfigMergeDiffMode,
+		Description: fmt.Sprintf("How to count the line changes of merge commits: \"%s\" "+
+			"(only the files which differ from every parent), \"%s\" (all the changes relative to "+
+			"the first parent, the lines of the merged branches are counted twice), \"%s\" "+
+			"(only the lines which are not present in any parent) or \"%s\".",
+			MergeDiffCombined, MergeDiffFirstParent, MergeDiffNewLines, MergeDiffNone),
+		Flag:    "merge-diff",
+		Type:    core.StringConfigurationOption,
+		Default: MergeDiffCombined}}
+	return options[:]
+}
+
+// Configure sets the properties previously published by ListConfigurationOptions().
+// The line diffs take the FileDiff options from the same facts.
+func (mlsc *MergeLinesStatsCalculator) Configure(facts map[string]interface{}) error {
+	if val, exists := facts[ConfigMergeDiffMode].(string); exists {
+		mlsc.Mode = val
+	}
+	switch mlsc.Mode {
+	case "":
+		mlsc.Mode = MergeDiffCombined
+	case MergeDiffFirstParent, MergeDiffCombined, MergeDiffNewLines, MergeDiffNone:
+	default:
+		return fmt.Errorf("unknown merge diff mode: %s", mlsc.Mode)
+	}
+	return mlsc.fileDiff.Configure(facts)
+}
+
+// Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
+// calls. The repository which is going to be analysed is supplied as an argument.
+func (mlsc *MergeLinesStatsCalculator) Initialize(repository *git.Repository) error {
+	return nil
+}
+
+// Consume runs this PipelineItem on the next commit data.
+// `deps` contain all the results from upstream PipelineItem-s as requested by Requires().
+// Additionally, DependencyCommit is always present there and represents the analysed *object.Commit.
+// This function returns the mapping with analysis results. The keys must be the same as
+// in Provides(). If there was an error, nil is returned.
+func (mlsc *MergeLinesStatsCalculator) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	files := []MergeFileLineStats{}
+	if deps[core.DependencyIsMerge].(bool) {
+		var err error
+		files, err = mlsc.computeFileStats(deps[core.DependencyCommit].(*object.Commit))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return map[string]interface{}{DependencyMergeLineStats: files}, nil
+}
+
+// Fork clones this PipelineItem.
+func (mlsc *MergeLinesStatsCalculator) Fork(n int) []core.PipelineItem {
+	return core.ForkSamePipelineItem(mlsc, n)
+}
+
+// Checkpoint does nothing: MergeLinesStatsCalculator does not keep any state between the commits.
+func (mlsc *MergeLinesStatsCalculator) Checkpoint(writer io.Writer) error {
+	return nil
+}
+
+// Restore does nothing: MergeLinesStatsCalculator does not keep any state between the commits.
+func (mlsc *MergeLinesStatsCalculator) Restore(reader io.Reader, facts map[string]interface{}) error {
+	return nil
+}
+
+// computeFileStats calculates the line statistics of each file changed in the merge commit
+// according to the mode. The files are returned in the order of the changes relative
+// to the first parent. Binary files have zero statistics.
+func (mlsc *MergeLinesStatsCalculator) computeFileStats(
+	commit *object.Commit) ([]MergeFileLineStats, error) {
+	if mlsc.Mode == MergeDiffNone || commit.NumParents() < 2 {
+		return []MergeFileLineStats{}, nil
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	parents := make([]*object.Tree, commit.NumParents())
+	for i := range parents {
+		parent, err := commit.Parent(i)
+		if err != nil {
+			return nil, err
+		}
+		parents[i], err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(parents[0], tree)
+	if err != nil {
+		return nil, err
+	}
+	files := []MergeFileLineStats{}
+	for _, change := range changes {
+		if (mlsc.Mode == MergeDiffCombined || mlsc.Mode == MergeDiffNewLines) &&
+			!differsFromParents(change, parents[1:]) {
+			continue
+		}
+		from, to, err := change.Files()
+		if err != nil {
+			return nil, err
+		}
+		if from == nil && to == nil {
+			// submodules and the like
+			continue
+		}
+		fromBlob, err := cacheFile(from)
+		if err != nil {
+			return nil, err
+		}
+		toBlob, err := cacheFile(to)
+		if err != nil {
+			return nil, err
+		}
+		stat := MergeFileLineStats{ToName: change.To.Name, FromName: change.From.Name}
+		if to != nil {
+			stat.Language = DetectLanguage(change.To.Name, toBlob)
+		} else {
+			stat.Language = DetectLanguage(change.From.Name, fromBlob)
+		}
+		if _, err := fromBlob.CountLines(); err != nil {
+			// binary
+			files = append(files, stat)
+			continue
+		}
+		if _, err := toBlob.CountLines(); err != nil {
+			// binary
+			files = append(files, stat)
+			continue
+		}
+		if mlsc.Mode == MergeDiffNewLines {
+			name := change.To.Name
+			if name == "" {
+				name = change.From.Name
+			}
+			parentLines := [][]string{mlsc.splitLines(fromBlob)}
+			for _, parent := range parents[1:] {
+				blob, err := findFile(parent, name)
+				if err != nil {
+					return nil, err
+				}
+				parentLines = append(parentLines, mlsc.splitLines(blob))
+			}
+			stat.LineStats = countNewLines(mlsc.splitLines(toBlob), parentLines)
+		} else {
+			diff, err := mlsc.fileDiff.Diff(fromBlob, toBlob)
+			if err != nil {
+				return nil, err
+			}
+			stat.LineStats = DiffLineStats(diff.Diffs)
+		}
+		files = append(files, stat)
+	}
+	return files, nil
+}
+
+// stripWhitespace removes the spaces if FileDiff.WhitespaceIgnore is set.
+func (mlsc *MergeLinesStatsCalculator) stripWhitespace(str string) string {
+	if mlsc.fileDiff.WhitespaceIgnore {
+		return strings.Replace(str, " ", "", -1)
+	}
+	return str
+}
+
+// splitLines returns the lines of the blob; binary blobs have no lines.
+func (mlsc *MergeLinesStatsCalculator) splitLines(blob *CachedBlob) []string {
+	if _, err := blob.CountLines(); err != nil || len(blob.Data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(mlsc.stripWhitespace(string(blob.Data)), "\n"), "\n")
+}
+
+// differsFromParents checks whether the version of the changed file in the merge commit
+// is different from the versions in all the specified parents.
+func differsFromParents(change *object.Change, parents []*object.Tree) bool {
+	name := change.To.Name
+	if name == "" {
+		name = change.From.Name
+	}
+	for _, parent := range parents {
+		entry, err := parent.FindEntry(name)
+		if err != nil {
+			// the file does not exist in the parent
+			if change.To.Name == "" {
+				return false
+			}
+			continue
+		}
+		if change.To.Name != "" && entry.Hash == change.To.TreeEntry.Hash {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheFile reads the contents of the file. nil file yields an empty blob.
+func cacheFile(file *object.File) (*CachedBlob, error) {
+	blob := &CachedBlob{}
+	if file == nil {
+		return blob, nil
+	}
+	blob.Blob = file.Blob
+	if err := blob.Cache(); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// findFile reads the file in the tree. A missing file yields an empty blob.
+func findFile(tree *object.Tree, name string) (*CachedBlob, error) {
+	file, err := tree.File(name)
+	if err != nil {
+		if err == object.ErrFileNotFound {
+			return &CachedBlob{}, nil
+		}
+		return nil, err
+	}
+	return cacheFile(file)
+}
+
+// countNewLines compares the lines of a file in the merge commit with the lines in each parent.
+// The lines which occur more times than in any parent are added, the lines which occur less times
+// than in every parent are removed. Added and removed lines are paired into changed lines.
+func countNewLines(lines []string, parents [][]string) LineStats {
+	count := func(lines []string) map[string]int {
+		counts := map[string]int{}
+		for _, line := range lines {
+			counts[line]++
+		}
+		return counts
+	}
+	counts := count(lines)
+	parentCounts := make([]map[string]int, len(parents))
+	for i, parent := range parents {
+		parentCounts[i] = count(parent)
+	}
+	var stats LineStats
+	for line, n := range counts {
+		max := 0
+		for _, pc := range parentCounts {
+			if pc[line] > max {
+				max = pc[line]
+			}
+		}
+		if n > max {
+			stats.Added += n - max
+		}
+	}
+	if len(parentCounts) > 0 {
+		for line, min := range parentCounts[0] {
+			for _, pc := range parentCounts[1:] {
+				if pc[line] < min {
+					min = pc[line]
+				}
+			}
+			if min > counts[line] {
+				stats.Removed += min - counts[line]
+			}
+		}
+	}
+	stats.Changed = stats.Added
+	if stats.Removed < stats.Changed {
+		stats.Changed = stats.Removed
+	}
+	stats.Added -= stats.Changed
+	stats.Removed -= stats.Changed
+	return stats
+}
+
+func init() {
+	core.Registry.Register(&MergeLinesStatsCalculator{})
+}
@@ -0,0 +1,183 @@
+package plumbing
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+)
+
+func TestMergeLinesStatsMeta(t *testing.T) {
+	mlsc := MergeLinesStatsCalculator{}
+	assert.Equal(t, "MergeLinesStats", mlsc.Name())
+	assert.Equal(t, []string{DependencyMergeLineStats}, mlsc.Provides())
+	assert.Len(t, mlsc.Requires(), 0)
+	opts := mlsc.ListConfigurationOptions()
+	assert.Len(t, opts, 1)
+	assert.Equal(t, ConfigMergeDiffMode, opts[0].Name)
+	assert.Equal(t, "merge-diff", opts[0].Flag)
+	assert.Equal(t, core.StringConfigurationOption, opts[0].Type)
+	assert.Equal(t, MergeDiffCombined, opts[0].Default)
+}
+
+func TestMergeLinesStatsRegistration(t *testing.T) {
+	summoned := core.Registry.Summon((&MergeLinesStatsCalculator{}).Name())
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, "MergeLinesStats", summoned[0].Name())
+	summoned = core.Registry.Summon(DependencyMergeLineStats)
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, "MergeLinesStats", summoned[0].Name())
+}
+
+func TestMergeLinesStatsConsume(t *testing.T) {
+	mlsc := MergeLinesStatsCalculator{}
+	assert.Nil(t, mlsc.Configure(map[string]interface{}{}))
+	assert.Nil(t, mlsc.Initialize(test.Repository))
+	deps := map[string]interface{}{}
+	deps[core.DependencyIsMerge] = true
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"fe00c0d50719a23ee77370dc29984fe4e16b2090"))
+	res, err := mlsc.Consume(deps)
+	assert.Nil(t, err)
+	files := res[DependencyMergeLineStats].([]MergeFileLineStats)
+	assert.Len(t, files, 1)
+	assert.Equal(t, "README.md", files[0].ToName)
+	assert.Equal(t, "Markdown", files[0].Language)
+	assert.Equal(t, LineStats{Added: 8, Removed: 0, Changed: 2}, files[0].LineStats)
+
+	// the tree changes of the other commits are covered by LinesStatsCalculator
+	deps[core.DependencyIsMerge] = false
+	res, err = mlsc.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyMergeLineStats].([]MergeFileLineStats), 0)
+}
+
+func TestMergeLinesStatsConfigure(t *testing.T) {
+	mlsc := MergeLinesStatsCalculator{}
+	assert.Nil(t, mlsc.Configure(map[string]interface{}{}))
+	assert.Equal(t, MergeDiffCombined, mlsc.Mode)
+	assert.False(t, mlsc.fileDiff.CleanupDisabled)
+	assert.False(t, mlsc.fileDiff.WhitespaceIgnore)
+	assert.Nil(t, mlsc.Configure(map[string]interface{}{
+		ConfigMergeDiffMode:          MergeDiffFirstParent,
+		ConfigFileDiffDisableCleanup: true,
+		ConfigFileWhitespaceIgnore:   true,
+		ConfigFileDiffAlgorithm:      DiffAlgorithmPatience,
+	}))
+	assert.Equal(t, MergeDiffFirstParent, mlsc.Mode)
+	assert.True(t, mlsc.fileDiff.CleanupDisabled)
+	assert.True(t, mlsc.fileDiff.WhitespaceIgnore)
+	assert.Equal(t, DiffAlgorithmPatience, mlsc.fileDiff.Algorithm)
+	assert.NotNil(t, mlsc.Configure(map[string]interface{}{ConfigMergeDiffMode: "xxx"}))
+	assert.NotNil(t, mlsc.Configure(map[string]interface{}{ConfigFileDiffAlgorithm: "xxx"}))
+}
+
+func TestMergeLinesStatsComputeFileStats(t *testing.T) {
+	// fe00c0d merges 9612f22 into 15e244a; only README.md differs from both parents
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"fe00c0d50719a23ee77370dc29984fe4e16b2090"))
+	mlsc := MergeLinesStatsCalculator{Mode: MergeDiffFirstParent}
+	files, err := mlsc.computeFileStats(commit)
+	assert.Nil(t, err)
+	assert.Len(t, files, 4)
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.ToName
+		assert.Equal(t, file.ToName, file.FromName)
+	}
+	assert.Equal(t, []string{".travis.yml", "Makefile", "README.md", "cmd/hercules/root.go"}, names)
+	assert.Equal(t, LineStats{Added: 5, Removed: 1, Changed: 2}, files[0].LineStats)
+	assert.Equal(t, LineStats{Added: 14, Removed: 6, Changed: 11}, files[1].LineStats)
+	assert.Equal(t, LineStats{Added: 8, Removed: 0, Changed: 2}, files[2].LineStats)
+	assert.Equal(t, LineStats{Added: 1, Removed: 15, Changed: 15}, files[3].LineStats)
+
+	mlsc.Mode = MergeDiffCombined
+	files, err = mlsc.computeFileStats(commit)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, "README.md", files[0].ToName)
+	assert.Equal(t, LineStats{Added: 8, Removed: 0, Changed: 2}, files[0].LineStats)
+
+	mlsc.Mode = MergeDiffNewLines
+	files, err = mlsc.computeFileStats(commit)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, LineStats{Added: 1}, files[0].LineStats)
+
+	mlsc.Mode = MergeDiffNone
+	files, err = mlsc.computeFileStats(commit)
+	assert.Nil(t, err)
+	assert.Len(t, files, 0)
+
+	// not a merge
+	mlsc.Mode = MergeDiffFirstParent
+	commit, _ = test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	files, err = mlsc.computeFileStats(commit)
+	assert.Nil(t, err)
+	assert.Len(t, files, 0)
+}
+
+func TestMergeLinesStatsResolvedConflict(t *testing.T) {
+	// 15e244a takes the fixed typo in README.md from the second parent
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"15e244af10b7a729691499b3a08622cacc3d4c8c"))
+	mlsc := MergeLinesStatsCalculator{Mode: MergeDiffCombined}
+	files, err := mlsc.computeFileStats(commit)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, LineStats{Changed: 1}, files[0].LineStats)
+	mlsc.Mode = MergeDiffNewLines
+	files, err = mlsc.computeFileStats(commit)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, LineStats{}, files[0].LineStats)
+}
+
+func TestMergeLinesStatsDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hercules-merge-diff-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	mlsc := MergeLinesStatsCalculator{}
+	assert.Nil(t, mlsc.Configure(map[string]interface{}{
+		ConfigMergeDiffMode:  MergeDiffCombined,
+		ConfigCacheDirectory: dir,
+	}))
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"fe00c0d50719a23ee77370dc29984fe4e16b2090"))
+	files, err := mlsc.computeFileStats(commit)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, LineStats{Added: 8, Removed: 0, Changed: 2}, files[0].LineStats)
+	cached := 0
+	assert.Nil(t, filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			cached++
+		}
+		return err
+	}))
+	assert.Equal(t, 1, cached)
+	// the second run reads the same diff from the cache
+	files, err = mlsc.computeFileStats(commit)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, LineStats{Added: 8, Removed: 0, Changed: 2}, files[0].LineStats)
+}
+
+func TestMergeLinesStatsCountNewLines(t *testing.T) {
+	parent1 := []string{"a", "b", "c", "d"}
+	parent2 := []string{"a", "b", "x", "d"}
+	assert.Equal(t, LineStats{}, countNewLines([]string{"a", "b", "x", "d"},
+		[][]string{parent1, parent2}))
+	assert.Equal(t, LineStats{Changed: 1}, countNewLines([]string{"a", "y", "c", "x", "d"},
+		[][]string{parent1, parent2}))
+	assert.Equal(t, LineStats{Removed: 1, Changed: 2}, countNewLines([]string{"y", "z", "c", "x"},
+		[][]string{parent1, parent2}))
+	assert.Equal(t, LineStats{Added: 2}, countNewLines([]string{"a", "a"}, [][]string{nil, nil}))
+	assert.Equal(t, LineStats{}, countNewLines(nil, [][]string{parent1, nil}))
+}
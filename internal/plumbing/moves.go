@@ -0,0 +1,345 @@
+package plumbing
+
+import (
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+)
+
+// MoveDetection finds the blocks of lines which were deleted in one place and inserted
+// in another place by the same commit, within a file or across files, similar to
+// `git diff --color-moved` and `git blame -M`. It is a PipelineItem.
+type MoveDetection struct {
+	core.NoopMerger
+	// Enabled turns the detection on. Otherwise MoveDetection always provides no blocks.
+	Enabled bool
+	// MinLines is the minimum number of lines with letters or digits in a moved block.
+	// The blocks which consist of fewer such lines are too generic to tell whether
+	// they were moved or written anew.
+	MinLines int
+}
+
+// MovedBlock is a block of identical consecutive lines which was deleted and inserted
+// by the same commit.
+type MovedBlock struct {
+	// From is the old path of the file and the index of the first line in the old revision.
+	From LinePosition
+	// To is the new path of the file and the index of the first line in the new revision.
+	To LinePosition
+	// Length is the number of lines in the block.
+	Length int
+}
+
+// LinePosition is the location of a line in a file.
+type LinePosition struct {
+	Name string
+	Line int
+}
+
+const (
+	// DependencyMovedBlocks is the name of the dependency provided by MoveDetection: []MovedBlock.
+	DependencyMovedBlocks = "moved_blocks"
+
+	// ConfigMoveDetectionEnabled is the name of the configuration option
+	// (MoveDetection.Configure()) which enables the detection of the moved lines.
+	ConfigMoveDetectionEnabled = "MoveDetection.Enabled"
+
+	// ConfigMoveDetectionMinLines is the name of the configuration option
+	// (MoveDetection.Configure()) which sets the minimum size of a moved block.
+	ConfigMoveDetectionMinLines = "MoveDetection.MinLines"
+
+	// DefaultMoveDetectionMinLines is the default value of MoveDetection.MinLines.
+	DefaultMoveDetectionMinLines = 3
+
+	// maxMoveCandidates limits the number of the deleted lines which we try to match
+	// against each inserted line.
+	maxMoveCandidates = 64
+)
+
+// Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
+func (moves *MoveDetection) Name() string {
+	return "MoveDetection"
+}
+
+// Provides returns the list of names of entities which are produced by this PipelineItem.
+// Each produced entity will be inserted into `deps` of dependent Consume()-s according
+// to this list. Also used by core.Registry to build the global map of providers.
+func (moves *MoveDetection) Provides() []string {
+	arr := [...]string{DependencyMovedBlocks}
+	return arr[:]
+}
+
+// Requires returns the list of names of entities which are needed by this PipelineItem.
+// Each requested entity will be inserted into `deps` of Consume(). In turn, those
+// entities are Provides() upstream.
+func (moves *MoveDetection) Requires() []string {
+	arr := [...]string{DependencyTreeChanges, DependencyBlobCache, DependencyFileDiff}
+	return arr[:]
+}
+
+// ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
+func (moves *MoveDetection) ListConfigurationOptions() []core.ConfigurationOption {
+	options := [...]core.ConfigurationOption{{
+		Name: ConfigMoveDetectionEnabled,
+		Description: "Detect the blocks of lines which were moved within a file or between files " +
+			"and keep their authors and ages.",
+		Flag:    "detect-moves",
+		Type:    core.BoolConfigurationOption,
+		Default: false}, {
+		Name:        ConfigMoveDetectionMinLines,
+		Description: "Minimum number of lines with letters or digits in a moved block.",
+		Flag:        "moves-min-lines",
+		Type:        core.IntConfigurationOption,
+		Default:     DefaultMoveDetectionMinLines},
+	}
+	return options[:]
+}
+
+// Configure sets the properties previously published by ListConfigurationOptions().
+func (moves *MoveDetection) Configure(facts map[string]interface{}) error {
+	if val, exists := facts[ConfigMoveDetectionEnabled].(bool); exists {
+		moves.Enabled = val
+	}
+	if val, exists := facts[ConfigMoveDetectionMinLines].(int); exists {
+		moves.MinLines = val
+	}
+	return nil
+}
+
+// Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
+// calls. The repository which is going to be analysed is supplied as an argument.
+func (moves *MoveDetection) Initialize(repository *git.Repository) error {
+	if moves.MinLines < 1 {
+		if moves.MinLines != 0 {
+			log.Printf("Warning: adjusted the minimum moved block size to %d",
+				DefaultMoveDetectionMinLines)
+		}
+		moves.MinLines = DefaultMoveDetectionMinLines
+	}
+	return nil
+}
+
+// changedLines is a run of consecutive lines which were deleted from or inserted to a file.
+type changedLines struct {
+	name  string
+	start int
+	lines []string
+}
+
+// Consume runs this PipelineItem on the next commit data.
+// `deps` contain all the results from upstream PipelineItem-s as requested by Requires().
+// Additionally, DependencyCommit is always present there and represents the analysed *object.Commit.
+// This function returns the mapping with analysis results. The keys must be the same as
+// in Provides(). If there was an error, nil is returned.
+func (moves *MoveDetection) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	var blocks []MovedBlock
+	// the changes of merges are relative to the previous commit in the branch rather than
+	// to the parents, so they may contain the unrelated edits from the other branches
+	if isMerge, _ := deps[core.DependencyIsMerge].(bool); !moves.Enabled || isMerge {
+		return map[string]interface{}{DependencyMovedBlocks: blocks}, nil
+	}
+	cache := deps[DependencyBlobCache].(map[plumbing.Hash]*CachedBlob)
+	treeDiff := deps[DependencyTreeChanges].(object.Changes)
+	fileDiffs := deps[DependencyFileDiff].(map[string]FileDiffData)
+	var deleted, inserted []changedLines
+	for _, change := range treeDiff {
+		action, err := change.Action()
+		if err != nil {
+			return nil, err
+		}
+		switch action {
+		case merkletrie.Insert:
+			if lines, ok := blobLines(cache[change.To.TreeEntry.Hash]); ok {
+				inserted = append(inserted, changedLines{name: change.To.Name, lines: lines})
+			}
+		case merkletrie.Delete:
+			if lines, ok := blobLines(cache[change.From.TreeEntry.Hash]); ok {
+				deleted = append(deleted, changedLines{name: change.From.Name, lines: lines})
+			}
+		case merkletrie.Modify:
+			oldLines, okFrom := blobLines(cache[change.From.TreeEntry.Hash])
+			newLines, okTo := blobLines(cache[change.To.TreeEntry.Hash])
+			fileDiff, exists := fileDiffs[change.To.Name]
+			if !okFrom || !okTo || !exists {
+				continue
+			}
+			oldLine, newLine := 0, 0
+			for _, edit := range fileDiff.Diffs {
+				length := utf8.RuneCountInString(edit.Text)
+				switch edit.Type {
+				case diffmatchpatch.DiffEqual:
+					oldLine += length
+					newLine += length
+				case diffmatchpatch.DiffDelete:
+					deleted = append(deleted, changedLines{
+						name: change.From.Name, start: oldLine,
+						lines: oldLines[oldLine : oldLine+length]})
+					oldLine += length
+				case diffmatchpatch.DiffInsert:
+					inserted = append(inserted, changedLines{
+						name: change.To.Name, start: newLine,
+						lines: newLines[newLine : newLine+length]})
+					newLine += length
+				}
+			}
+		}
+	}
+	if len(deleted) > 0 && len(inserted) > 0 {
+		blocks = moves.matchBlocks(deleted, inserted)
+	}
+	return map[string]interface{}{DependencyMovedBlocks: blocks}, nil
+}
+
+// matchBlocks greedily pairs the longest runs of the inserted lines with the identical runs
+// of the deleted lines. Each deleted line can be moved only once.
+func (moves *MoveDetection) matchBlocks(deleted, inserted []changedLines) []MovedBlock {
+	type linePtr struct {
+		run    int
+		offset int
+	}
+	index := map[string][]linePtr{}
+	used := make([][]bool, len(deleted))
+	for i, run := range deleted {
+		used[i] = make([]bool, len(run.lines))
+		for j, line := range run.lines {
+			if isSignificantLine(line) {
+				index[line] = append(index[line], linePtr{i, j})
+			}
+		}
+	}
+	var blocks []MovedBlock
+	for _, run := range inserted {
+		for j := 0; j < len(run.lines); {
+			candidates := index[run.lines[j]]
+			if len(candidates) > maxMoveCandidates {
+				candidates = candidates[:maxMoveCandidates]
+			}
+			best, bestLength := linePtr{}, 0
+			for _, ptr := range candidates {
+				source := deleted[ptr.run].lines
+				length := 0
+				for j+length < len(run.lines) && ptr.offset+length < len(source) &&
+					!used[ptr.run][ptr.offset+length] &&
+					source[ptr.offset+length] == run.lines[j+length] {
+					length++
+				}
+				if length > bestLength {
+					best, bestLength = ptr, length
+				}
+			}
+			significant := 0
+			for _, line := range run.lines[j : j+bestLength] {
+				if isSignificantLine(line) {
+					significant++
+				}
+			}
+			if bestLength == 0 || significant < moves.MinLines {
+				j++
+				continue
+			}
+			for k := 0; k < bestLength; k++ {
+				used[best.run][best.offset+k] = true
+			}
+			source := deleted[best.run]
+			blocks = append(blocks, MovedBlock{
+				From:   LinePosition{Name: source.name, Line: source.start + best.offset},
+				To:     LinePosition{Name: run.name, Line: run.start + j},
+				Length: bestLength,
+			})
+			j += bestLength
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].To.Name != blocks[j].To.Name {
+			return blocks[i].To.Name < blocks[j].To.Name
+		}
+		return blocks[i].To.Line < blocks[j].To.Line
+	})
+	return blocks
+}
+
+// blobLines splits the text blob into lines which keep their "\n", the same as
+// diffmatchpatch.DiffLinesToRunes(). The second returned value is false if the blob is binary.
+func blobLines(blob *CachedBlob) ([]string, bool) {
+	if blob == nil {
+		return nil, false
+	}
+	if _, err := blob.CountLines(); err != nil {
+		return nil, false
+	}
+	text := string(blob.Data)
+	var lines []string
+	for len(text) > 0 {
+		pos := strings.IndexByte(text, '\n') + 1
+		if pos == 0 {
+			pos = len(text)
+		}
+		lines = append(lines, text[:pos])
+		text = text[pos:]
+	}
+	return lines, true
+}
+
+// isSignificantLine returns true if the line contains letters or digits, unlike
+// the blank lines or the lone braces.
+func isSignificantLine(line string) bool {
+	for _, char := range line {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) {
+			return true
+		}
+	}
+	return false
+}
+
+// MovedLines returns the moved line indexes in each file: the old lines by the old file names
+// and the new lines by the new file names. The values are the indexes of the blocks.
+func MovedLines(blocks []MovedBlock) (from, to map[string]map[int]int) {
+	from = map[string]map[int]int{}
+	to = map[string]map[int]int{}
+	for i, block := range blocks {
+		fromLines := from[block.From.Name]
+		if fromLines == nil {
+			fromLines = map[int]int{}
+			from[block.From.Name] = fromLines
+		}
+		toLines := to[block.To.Name]
+		if toLines == nil {
+			toLines = map[int]int{}
+			to[block.To.Name] = toLines
+		}
+		for j := 0; j < block.Length; j++ {
+			fromLines[block.From.Line+j] = i
+			toLines[block.To.Line+j] = i
+		}
+	}
+	return from, to
+}
+
+// Fork clones this PipelineItem.
+func (moves *MoveDetection) Fork(n int) []core.PipelineItem {
+	return core.ForkSamePipelineItem(moves, n)
+}
+
+// Checkpoint does nothing: MoveDetection does not keep any state between the commits.
+func (moves *MoveDetection) Checkpoint(writer io.Writer) error {
+	return nil
+}
+
+// Restore does nothing: MoveDetection does not keep any state between the commits.
+func (moves *MoveDetection) Restore(reader io.Reader, facts map[string]interface{}) error {
+	return nil
+}
+
+func init() {
+	core.Registry.Register(&MoveDetection{})
+}
@@ -0,0 +1,210 @@
+package plumbing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+)
+
+func fixtureMoveDetection() *MoveDetection {
+	moves := &MoveDetection{Enabled: true}
+	moves.Initialize(nil)
+	return moves
+}
+
+func TestMoveDetectionMeta(t *testing.T) {
+	moves := fixtureMoveDetection()
+	assert.Equal(t, moves.Name(), "MoveDetection")
+	assert.Len(t, moves.Provides(), 1)
+	assert.Equal(t, moves.Provides()[0], DependencyMovedBlocks)
+	assert.Len(t, moves.Requires(), 3)
+	assert.Equal(t, moves.Requires()[0], DependencyTreeChanges)
+	assert.Equal(t, moves.Requires()[1], DependencyBlobCache)
+	assert.Equal(t, moves.Requires()[2], DependencyFileDiff)
+	opts := moves.ListConfigurationOptions()
+	assert.Len(t, opts, 2)
+	assert.Equal(t, opts[0].Name, ConfigMoveDetectionEnabled)
+	assert.Equal(t, opts[0].Flag, "detect-moves")
+	assert.Equal(t, opts[1].Name, ConfigMoveDetectionMinLines)
+	assert.Equal(t, opts[1].Default, DefaultMoveDetectionMinLines)
+	assert.Nil(t, moves.Configure(map[string]interface{}{
+		ConfigMoveDetectionEnabled:  false,
+		ConfigMoveDetectionMinLines: 5,
+	}))
+	assert.False(t, moves.Enabled)
+	assert.Equal(t, moves.MinLines, 5)
+	moves.MinLines = -1
+	assert.Nil(t, moves.Initialize(nil))
+	assert.Equal(t, moves.MinLines, DefaultMoveDetectionMinLines)
+}
+
+func TestMoveDetectionRegistration(t *testing.T) {
+	summoned := core.Registry.Summon((&MoveDetection{}).Name())
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, summoned[0].Name(), "MoveDetection")
+	summoned = core.Registry.Summon((&MoveDetection{}).Provides()[0])
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, summoned[0].Name(), "MoveDetection")
+}
+
+const moveSourceOld = `package a
+
+func Alpha(x int) int {
+	y := x * 2
+	z := y - 1
+	return z + 1
+}
+
+func Beta() {
+	println("beta")
+}
+`
+
+const moveSourceNew = `package a
+
+func Beta() {
+	println("beta")
+}
+`
+
+const moveTarget = `package b
+
+// Alpha was moved here.
+func Alpha(x int) int {
+	y := x * 2
+	z := y - 1
+	return z + 1
+}
+`
+
+const moveReorderOld = `package c
+
+func One() {
+	println("one")
+	println("uno")
+}
+
+func Two() {
+	println("two")
+	println("dos")
+}
+`
+
+const moveReorderNew = `package c
+
+func Two() {
+	println("two")
+	println("dos")
+}
+
+func One() {
+	println("one")
+	println("uno")
+}
+`
+
+// fixtureMoves returns the dependencies of a commit which moves Alpha() from a.go to the new
+// file b.go and swaps the functions in c.go.
+func fixtureMoves(t *testing.T) map[string]interface{} {
+	cache := map[plumbing.Hash]*CachedBlob{}
+	entry := func(name, text string) object.ChangeEntry {
+		blob := &CachedBlob{Data: []byte(text)}
+		blob.Hash = plumbing.ComputeHash(plumbing.BlobObject, blob.Data)
+		cache[blob.Hash] = blob
+		return object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+			Name: name, Mode: 0100644, Hash: blob.Hash}}
+	}
+	changes := object.Changes{
+		&object.Change{From: entry("a.go", moveSourceOld), To: entry("a.go", moveSourceNew)},
+		&object.Change{To: entry("b.go", moveTarget)},
+		&object.Change{From: entry("c.go", moveReorderOld), To: entry("c.go", moveReorderNew)},
+	}
+	deps := map[string]interface{}{
+		DependencyBlobCache:    cache,
+		DependencyTreeChanges:  changes,
+		core.DependencyIsMerge: false,
+	}
+	res, err := (&FileDiff{}).Consume(deps)
+	assert.Nil(t, err)
+	deps[DependencyFileDiff] = res[DependencyFileDiff]
+	return deps
+}
+
+func TestMoveDetectionConsume(t *testing.T) {
+	moves := fixtureMoveDetection()
+	deps := fixtureMoves(t)
+	res, err := moves.Consume(deps)
+	assert.Nil(t, err)
+	blocks := res[DependencyMovedBlocks].([]MovedBlock)
+	assert.Equal(t, []MovedBlock{
+		{From: LinePosition{"a.go", 2}, To: LinePosition{"b.go", 3}, Length: 5},
+		{From: LinePosition{"c.go", 2}, To: LinePosition{"c.go", 7}, Length: 4},
+	}, blocks)
+	from, to := MovedLines(blocks)
+	assert.Len(t, from["a.go"], 5)
+	assert.Len(t, from["c.go"], 4)
+	assert.Len(t, to["b.go"], 5)
+	assert.Equal(t, 1, to["c.go"][8])
+
+	moves.MinLines = 4
+	// "}" does not count
+	res, err = moves.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, []MovedBlock{
+		{From: LinePosition{"a.go", 2}, To: LinePosition{"b.go", 3}, Length: 5},
+	}, res[DependencyMovedBlocks].([]MovedBlock))
+
+	deps[core.DependencyIsMerge] = true
+	res, err = moves.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyMovedBlocks].([]MovedBlock), 0)
+	deps[core.DependencyIsMerge] = false
+	moves.Enabled = false
+	res, err = moves.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyMovedBlocks].([]MovedBlock), 0)
+}
+
+func TestLinesStatsConsumeMoved(t *testing.T) {
+	deps := fixtureMoves(t)
+	deps[DependencyLanguages] = map[plumbing.Hash]string{}
+	lsc := &LinesStatsCalculator{}
+	assert.Nil(t, lsc.Configure(map[string]interface{}{}))
+	changes := deps[DependencyTreeChanges].(object.Changes)
+	res, err := lsc.Consume(deps)
+	assert.Nil(t, err)
+	stats := res[DependencyLineStats].(map[object.ChangeEntry]FileLineStats)
+	assert.Equal(t, LineStats{Removed: 6}, stats[changes[0].To].LineStats)
+	assert.Equal(t, LineStats{Added: 8}, stats[changes[1].To].LineStats)
+	before := stats[changes[2].To].LineStats
+	assert.Equal(t, 0, before.Moved)
+
+	res, err = fixtureMoveDetection().Consume(deps)
+	assert.Nil(t, err)
+	deps[DependencyMovedBlocks] = res[DependencyMovedBlocks]
+	res, err = lsc.Consume(deps)
+	assert.Nil(t, err)
+	stats = res[DependencyLineStats].(map[object.ChangeEntry]FileLineStats)
+	// the blank line after Alpha() was removed
+	assert.Equal(t, LineStats{Removed: 1}, stats[changes[0].To].LineStats)
+	assert.Equal(t, LineStats{Added: 3, Moved: 5}, stats[changes[1].To].LineStats)
+	after := stats[changes[2].To].LineStats
+	assert.Equal(t, 4, after.Moved)
+	assert.Equal(t, before.Added+before.Changed-4, after.Added+after.Changed)
+}
+
+func TestBlobLines(t *testing.T) {
+	lines, ok := blobLines(&CachedBlob{Data: []byte("a\nb\n\nc")})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a\n", "b\n", "\n", "c"}, lines)
+	lines, ok = blobLines(&CachedBlob{Data: []byte{}})
+	assert.True(t, ok)
+	assert.Len(t, lines, 0)
+	_, ok = blobLines(&CachedBlob{Data: []byte{0, 1, 2}})
+	assert.False(t, ok)
+	_, ok = blobLines(nil)
+	assert.False(t, ok)
+}
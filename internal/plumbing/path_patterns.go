@@ -0,0 +1,76 @@
+package plumbing
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/gitignore"
+)
+
+// excludePrefix marks the exclude patterns in the patterns file.
+const excludePrefix = "!"
+
+// pathPatterns is the compiled list of gitignore-style path patterns, e.g.
+// "services/**/*.go", "*_test.go" or "/docs/". The patterns without a slash match
+// the file name or any of the parent directories, the rest are relative to the root.
+type pathPatterns []gitignore.Pattern
+
+// newPathPatterns compiles the patterns. It returns nil if the list is empty.
+// The leading "!" is dropped because the list itself tells whether the patterns include
+// or exclude, and a gitignore negation would never match.
+func newPathPatterns(patterns []string) pathPatterns {
+	var compiled pathPatterns
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(strings.TrimPrefix(pattern, excludePrefix))
+		if pattern == "" {
+			continue
+		}
+		compiled = append(compiled, gitignore.ParsePattern(pattern, nil))
+	}
+	return compiled
+}
+
+// match checks whether any of the patterns matches the file.
+func (patterns pathPatterns) match(name string) bool {
+	if name == "" {
+		return false
+	}
+	parts := strings.Split(name, "/")
+	for _, pattern := range patterns {
+		if pattern.Match(parts, false) == gitignore.Exclude {
+			return true
+		}
+	}
+	return false
+}
+
+// readPatternsFile loads the include and exclude patterns from the file. Each line is a single
+// pattern, the exclude patterns start with "!". Empty lines and lines starting with "#"
+// are ignored.
+func readPatternsFile(path string) (includes []string, excludes []string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to open the patterns file %s", path)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, excludePrefix) {
+			if line = strings.TrimSpace(line[len(excludePrefix):]); line != "" {
+				excludes = append(excludes, line)
+			}
+			continue
+		}
+		includes = append(includes, line)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read the patterns file %s", path)
+	}
+	return includes, excludes, nil
+}
@@ -0,0 +1,104 @@
+package plumbing
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+)
+
+func TestPathPatternsMatch(t *testing.T) {
+	var patterns pathPatterns
+	assert.False(t, patterns.match("main.go"))
+	patterns = newPathPatterns([]string{"services/**/*.go", "*_test.go", "**/mocks/**", "/docs/"})
+	assert.True(t, patterns.match("services/a/b/main.go"))
+	assert.True(t, patterns.match("services/main.go"))
+	assert.False(t, patterns.match("services/README.md"))
+	assert.False(t, patterns.match("lib/services/main.go"))
+	assert.True(t, patterns.match("lib/x_test.go"))
+	assert.True(t, patterns.match("lib/mocks/x.py"))
+	assert.True(t, patterns.match("docs/index.md"))
+	assert.False(t, patterns.match("lib/docs/index.md"))
+	assert.False(t, patterns.match(""))
+	patterns = newPathPatterns([]string{"!*_test.go", "!"})
+	assert.Len(t, patterns, 1)
+	assert.True(t, patterns.match("lib/x_test.go"))
+	assert.False(t, patterns.match("lib/x.go"))
+}
+
+func TestReadPatternsFile(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "hercules-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpdir)
+	path := filepath.Join(tmpdir, "patterns")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(
+		"# Go services\nservices/**/*.go\n\n  !*_test.go\n! **/mocks/**\n!\n"), 0666))
+	includes, excludes, err := readPatternsFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"services/**/*.go"}, includes)
+	assert.Equal(t, []string{"*_test.go", "**/mocks/**"}, excludes)
+	_, _, err = readPatternsFile(filepath.Join(tmpdir, "absent"))
+	assert.NotNil(t, err)
+}
+
+func TestTreeDiffConfigurePatterns(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "hercules-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpdir)
+	path := filepath.Join(tmpdir, "patterns")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("*.py\n!**/mocks/**\n"), 0666))
+	td := fixtureTreeDiff()
+	facts := map[string]interface{}{}
+	assert.Nil(t, td.Configure(facts))
+	assert.Nil(t, td.IncludePatterns)
+	assert.NotContains(t, facts, core.FactIncludePatterns)
+	assert.NotContains(t, facts, core.FactExcludePatterns)
+	facts[ConfigTreeDiffIncludePatterns] = []string{"*.go"}
+	facts[ConfigTreeDiffExcludePatterns] = []string{"*_test.go"}
+	facts[ConfigTreeDiffPatternsFile] = path
+	assert.Nil(t, td.Configure(facts))
+	assert.Equal(t, []string{"*.go", "*.py"}, td.IncludePatterns)
+	assert.Equal(t, []string{"*_test.go", "**/mocks/**"}, td.ExcludePatterns)
+	assert.Equal(t, td.IncludePatterns, facts[core.FactIncludePatterns])
+	assert.Equal(t, td.ExcludePatterns, facts[core.FactExcludePatterns])
+	facts[ConfigTreeDiffPatternsFile] = filepath.Join(tmpdir, "absent")
+	assert.NotNil(t, td.Configure(facts))
+}
+
+func TestTreeDiffConsumePatterns(t *testing.T) {
+	td := fixtureTreeDiff()
+	td.IncludePatterns = []string{"cmd/", "*.go"}
+	td.ExcludePatterns = []string{"*_test.go", "/doc.go"}
+	assert.Nil(t, td.Initialize(test.Repository))
+	names := func(commit string) []string {
+		commitObj, err := test.Repository.CommitObject(plumbing.NewHash(commit))
+		assert.Nil(t, err)
+		res, err := td.Consume(map[string]interface{}{core.DependencyCommit: commitObj})
+		assert.Nil(t, err)
+		var names []string
+		for _, change := range res[DependencyTreeChanges].(object.Changes) {
+			if change.To.Name != "" {
+				names = append(names, change.To.Name)
+			} else {
+				names = append(names, change.From.Name)
+			}
+		}
+		return names
+	}
+	// the initial tree walk
+	assert.ElementsMatch(t, []string{
+		"analyser.go", "cmd/hercules/main.go", "file.go", "rbtree.go",
+	}, names("fbe766ffdc3f87f6affddc051c6f8b419beea6a2"))
+	// the diff
+	assert.ElementsMatch(t, []string{
+		"analyser.go", "blob_cache.go", "burndown.go", "cmd/hercules/main.go", "day.go",
+		"dummies.go", "identity.go", "pipeline.go", "renames.go", "toposort/toposort.go",
+		"tree_diff.go",
+	}, names("2b1ed978194a94edeabbca6de7ff3b5771d4d665"))
+}
@@ -1,11 +1,13 @@
 package plumbing
 
 import (
+	"io"
 	"log"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"unicode/utf8"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
@@ -26,8 +28,25 @@ type RenameAnalysis struct {
 	// It has the same units as cgit's -X rename-threshold or -M. Better to
 	// set it to the default value of 80 (80%).
 	SimilarityThreshold int
+	// CopyDetection enables matching the added files against the existing files, similar to
+	// cgit's -C. CopyDetectionModified looks for the sources among the files modified in the same
+	// commit, CopyDetectionHarder additionally looks through all the unchanged files.
+	CopyDetection int
+
+	cleanupDisabled  bool
+	whitespaceIgnore bool
+	diffAlgorithm    string
+	repository       *git.Repository
+}
 
-	repository *git.Repository
+// FileCopy is a file which was added as a copy of an existing file with subsequent edits.
+type FileCopy struct {
+	// From is the copied file as it was in the parent commit.
+	From object.ChangeEntry
+	// To is the added file.
+	To object.ChangeEntry
+	// Diff is the line diff from the contents of From to the contents of To.
+	Diff FileDiffData
 }
 
 const (
@@ -53,6 +72,22 @@ const (
 	// RenameAnalysisByteDiffSizeThreshold is the maximum size of each of the compared parts
 	// to be diff-ed on byte level.
 	RenameAnalysisByteDiffSizeThreshold = 100000
+
+	// ConfigRenameAnalysisCopyDetection is the name of the configuration option
+	// (RenameAnalysis.Configure()) which enables the copy detection.
+	ConfigRenameAnalysisCopyDetection = "RenameAnalysis.CopyDetection"
+
+	// CopyDetectionModified searches the sources of the copies among the modified files, like cgit's -C.
+	CopyDetectionModified = 1
+
+	// CopyDetectionHarder searches the sources of the copies among all the files in the parent
+	// commit, like cgit's -C -C. It is much slower than CopyDetectionModified.
+	CopyDetectionHarder = 2
+
+	// DependencyCopies is the name of the dependency provided by RenameAnalysis.
+	// It maps the names of the copied files to FileCopy-s and is empty unless
+	// the copy detection is enabled.
+	DependencyCopies = "copies"
 )
 
 // Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
@@ -64,7 +99,7 @@ func (ra *RenameAnalysis) Name() string {
 // Each produced entity will be inserted into `deps` of dependent Consume()-s according
 // to this list. Also used by core.Registry to build the global map of providers.
 func (ra *RenameAnalysis) Provides() []string {
-	arr := [...]string{DependencyTreeChanges}
+	arr := [...]string{DependencyTreeChanges, DependencyCopies}
 	return arr[:]
 }
 
@@ -83,7 +118,13 @@ func (ra *RenameAnalysis) ListConfigurationOptions() []core.ConfigurationOption
 		Description: "The threshold on the similarity index used to detect renames.",
 		Flag:        "M",
 		Type:        core.IntConfigurationOption,
-		Default:     RenameAnalysisDefaultThreshold},
+		Default:     RenameAnalysisDefaultThreshold}, {
+		Name: ConfigRenameAnalysisCopyDetection,
+		Description: "Detect the files which were copied from the existing files: " +
+			"1 - among the modified files, 2 - among all the files (slow).",
+		Flag:    "C",
+		Type:    core.IntConfigurationOption,
+		Default: 0},
 	}
 	return options[:]
 }
@@ -93,6 +134,18 @@ func (ra *RenameAnalysis) Configure(facts map[string]interface{}) error {
 	if val, exists := facts[ConfigRenameAnalysisSimilarityThreshold].(int); exists {
 		ra.SimilarityThreshold = val
 	}
+	if val, exists := facts[ConfigRenameAnalysisCopyDetection].(int); exists {
+		ra.CopyDetection = val
+	}
+	if val, exists := facts[ConfigFileDiffDisableCleanup].(bool); exists {
+		ra.cleanupDisabled = val
+	}
+	if val, exists := facts[ConfigFileWhitespaceIgnore].(bool); exists {
+		ra.whitespaceIgnore = val
+	}
+	if val, exists := facts[ConfigFileDiffAlgorithm].(string); exists {
+		ra.diffAlgorithm = val
+	}
 	return nil
 }
 
@@ -104,6 +157,10 @@ func (ra *RenameAnalysis) Initialize(repository *git.Repository) error {
 			RenameAnalysisDefaultThreshold)
 		ra.SimilarityThreshold = RenameAnalysisDefaultThreshold
 	}
+	if ra.CopyDetection < 0 || ra.CopyDetection > CopyDetectionHarder {
+		log.Printf("Warning: disabled the copy detection, unsupported mode %d\n", ra.CopyDetection)
+		ra.CopyDetection = 0
+	}
 	ra.repository = repository
 	return nil
 }
@@ -200,7 +257,9 @@ func (ra *RenameAnalysis) Consume(deps map[string]interface{}) (map[string]inter
 	sort.Sort(addedBlobs)
 	sort.Sort(deletedBlobs)
 
-	var finished, finishedA, finishedB bool
+	// finished is set atomically by whichever function stops first
+	var finished int32
+	var finishedA, finishedB bool
 	matchesA := make(object.Changes, 0, changes.Len())
 	matchesB := make(object.Changes, 0, changes.Len())
 	addedBlobsA := addedBlobs
@@ -211,10 +270,7 @@ func (ra *RenameAnalysis) Consume(deps map[string]interface{}) (map[string]inter
 	copy(deletedBlobsB, deletedBlobs)
 	wg := sync.WaitGroup{}
 	matchA := func() error {
-		defer func() {
-			finished = true
-			wg.Done()
-		}()
+		defer atomic.StoreInt32(&finished, 1)
 		aStart := 0
 		// we will try to find a matching added blob for each deleted blob
 		for d := 0; d < deletedBlobsA.Len(); d++ {
@@ -236,7 +292,7 @@ func (ra *RenameAnalysis) Consume(deps map[string]interface{}) (map[string]inter
 			})
 			var ci int
 			for ci, a = range candidates {
-				if finished {
+				if atomic.LoadInt32(&finished) != 0 {
 					return nil
 				}
 				if ci > maxCandidates {
@@ -267,10 +323,7 @@ func (ra *RenameAnalysis) Consume(deps map[string]interface{}) (map[string]inter
 		return nil
 	}
 	matchB := func() error {
-		defer func() {
-			finished = true
-			wg.Done()
-		}()
+		defer atomic.StoreInt32(&finished, 1)
 		dStart := 0
 		for a := 0; a < addedBlobsB.Len(); a++ {
 			myBlob := cache[addedBlobsB[a].change.To.TreeEntry.Hash]
@@ -291,7 +344,7 @@ func (ra *RenameAnalysis) Consume(deps map[string]interface{}) (map[string]inter
 			})
 			var ci int
 			for ci, d = range candidates {
-				if finished {
+				if atomic.LoadInt32(&finished) != 0 {
 					return nil
 				}
 				if ci > maxCandidates {
@@ -323,12 +376,16 @@ func (ra *RenameAnalysis) Consume(deps map[string]interface{}) (map[string]inter
 	}
 	// run two functions in parallel, and take the result from the one which finished earlier
 	wg.Add(2)
-	var err error
-	go func() { err = matchA() }()
-	go func() { err = matchB() }()
+	var errA, errB error
+	// wg.Done() must happen after the errors are assigned
+	go func() { defer wg.Done(); errA = matchA() }()
+	go func() { defer wg.Done(); errB = matchB() }()
 	wg.Wait()
-	if err != nil {
-		return nil, err
+	if errA != nil {
+		return nil, errA
+	}
+	if errB != nil {
+		return nil, errB
 	}
 	var matches object.Changes
 	if finishedA {
@@ -358,7 +415,162 @@ func (ra *RenameAnalysis) Consume(deps map[string]interface{}) (map[string]inter
 	for _, change := range smallChanges {
 		reducedChanges = append(reducedChanges, change)
 	}
-	return map[string]interface{}{DependencyTreeChanges: reducedChanges}, nil
+
+	// Stage 4 - the remaining additions may be copies of the existing files
+	// they stay insertions in DependencyTreeChanges
+	copies := map[string]FileCopy{}
+	commit, _ := deps[core.DependencyCommit].(*object.Commit)
+	if ra.CopyDetection > 0 && len(addedBlobs) > 0 && commit != nil && commit.NumParents() == 1 {
+		var err error
+		copies, err = ra.detectCopies(commit, changes, addedBlobs, cache, maxCandidates)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return map[string]interface{}{DependencyTreeChanges: reducedChanges, DependencyCopies: copies}, nil
+}
+
+// copySource is a possible origin of a copied file. The blob is loaded lazily.
+type copySource struct {
+	entry object.ChangeEntry
+	size  int64
+	blob  *CachedBlob
+}
+
+// detectCopies matches the added files against the copy sources: first by the hashes,
+// then by the similarity, the same way as the renames.
+func (ra *RenameAnalysis) detectCopies(
+	commit *object.Commit, changes object.Changes, added sortableBlobs,
+	cache map[plumbing.Hash]*CachedBlob, maxCandidates int) (map[string]FileCopy, error) {
+
+	var sources []*copySource
+	touched := map[string]bool{}
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, err
+		}
+		if action == merkletrie.Insert {
+			continue
+		}
+		touched[change.From.Name] = true
+		if action != merkletrie.Modify {
+			continue
+		}
+		blob := cache[change.From.TreeEntry.Hash]
+		if blob == nil || blob.Size < RenameAnalysisMinimumSize {
+			continue
+		}
+		sources = append(sources, &copySource{entry: change.From, size: blob.Size, blob: blob})
+	}
+	if ra.CopyDetection >= CopyDetectionHarder {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		tree, err := parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+		err = tree.Files().ForEach(func(file *object.File) error {
+			if touched[file.Name] || file.Size < RenameAnalysisMinimumSize {
+				return nil
+			}
+			sources = append(sources, &copySource{
+				entry: object.ChangeEntry{
+					Name: file.Name,
+					Tree: tree,
+					TreeEntry: object.TreeEntry{
+						Name: filepath.Base(file.Name), Mode: file.Mode, Hash: file.Hash},
+				},
+				size: file.Size,
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	byHash := map[plumbing.Hash]*copySource{}
+	for _, source := range sources {
+		if _, exists := byHash[source.entry.TreeEntry.Hash]; !exists {
+			byHash[source.entry.TreeEntry.Hash] = source
+		}
+	}
+
+	copies := map[string]FileCopy{}
+	for _, addition := range added {
+		myBlob := cache[addition.change.To.TreeEntry.Hash]
+		if _, err := myBlob.CountLines(); err == ErrorBinary {
+			continue
+		}
+		match := byHash[addition.change.To.TreeEntry.Hash]
+		if match == nil {
+			var candidates []int
+			for i, source := range sources {
+				if ra.sizesAreClose(addition.size, source.size) {
+					candidates = append(candidates, i)
+				}
+			}
+			sortRenameCandidates(candidates, filepath.Base(addition.change.To.Name), func(i int) string {
+				return sources[i].entry.Name
+			})
+			for ci, i := range candidates {
+				if ci >= maxCandidates {
+					break
+				}
+				blob, err := ra.loadCopySource(sources[i])
+				if err != nil {
+					return nil, err
+				}
+				if blob == nil {
+					continue
+				}
+				blobsAreClose, err := ra.blobsAreClose(blob, myBlob)
+				if err != nil {
+					return nil, err
+				}
+				if blobsAreClose {
+					match = sources[i]
+					break
+				}
+			}
+		}
+		if match == nil {
+			continue
+		}
+		blob, err := ra.loadCopySource(match)
+		if err != nil {
+			return nil, err
+		}
+		if blob == nil {
+			continue
+		}
+		copies[addition.change.To.Name] = FileCopy{
+			From: match.entry,
+			To:   addition.change.To,
+			Diff: diffBlobs(blob, myBlob, ra.diffAlgorithm, ra.cleanupDisabled, ra.whitespaceIgnore),
+		}
+	}
+	return copies, nil
+}
+
+// loadCopySource reads the contents of the copy source. It returns nil if the source is binary.
+func (ra *RenameAnalysis) loadCopySource(source *copySource) (*CachedBlob, error) {
+	if source.blob == nil {
+		blob, err := ra.repository.BlobObject(source.entry.TreeEntry.Hash)
+		if err != nil {
+			return nil, err
+		}
+		source.blob = &CachedBlob{Blob: *blob}
+		if err = source.blob.Cache(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := source.blob.CountLines(); err == ErrorBinary {
+		return nil, nil
+	}
+	return source.blob, nil
 }
 
 // Fork clones this PipelineItem.
@@ -366,6 +578,16 @@ func (ra *RenameAnalysis) Fork(n int) []core.PipelineItem {
 	return core.ForkSamePipelineItem(ra, n)
 }
 
+// Checkpoint does nothing: RenameAnalysis does not keep any state between the commits.
+func (ra *RenameAnalysis) Checkpoint(writer io.Writer) error {
+	return nil
+}
+
+// Restore does nothing: RenameAnalysis does not keep any state between the commits.
+func (ra *RenameAnalysis) Restore(reader io.Reader, facts map[string]interface{}) error {
+	return nil
+}
+
 func (ra *RenameAnalysis) sizesAreClose(size1 int64, size2 int64) bool {
 	size := internal.Max64(1, internal.Max64(size1, size2))
 	return (internal.Abs64(size1-size2)*10000)/size <= int64(100-ra.SimilarityThreshold)*100
@@ -3,6 +3,7 @@ package plumbing
 import (
 	"bytes"
 	"compress/gzip"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
@@ -24,14 +25,16 @@ func fixtureRenameAnalysis() *RenameAnalysis {
 func TestRenameAnalysisMeta(t *testing.T) {
 	ra := fixtureRenameAnalysis()
 	assert.Equal(t, ra.Name(), "RenameAnalysis")
-	assert.Equal(t, len(ra.Provides()), 1)
+	assert.Equal(t, len(ra.Provides()), 2)
 	assert.Equal(t, ra.Provides()[0], DependencyTreeChanges)
+	assert.Equal(t, ra.Provides()[1], DependencyCopies)
 	assert.Equal(t, len(ra.Requires()), 2)
 	assert.Equal(t, ra.Requires()[0], DependencyBlobCache)
 	assert.Equal(t, ra.Requires()[1], DependencyTreeChanges)
 	opts := ra.ListConfigurationOptions()
-	assert.Len(t, opts, 1)
+	assert.Len(t, opts, 2)
 	assert.Equal(t, opts[0].Name, ConfigRenameAnalysisSimilarityThreshold)
+	assert.Equal(t, opts[1].Name, ConfigRenameAnalysisCopyDetection)
 	ra.SimilarityThreshold = 0
 	facts := map[string]interface{}{}
 	facts[ConfigRenameAnalysisSimilarityThreshold] = 70
@@ -40,6 +43,15 @@ func TestRenameAnalysisMeta(t *testing.T) {
 	delete(facts, ConfigRenameAnalysisSimilarityThreshold)
 	ra.Configure(facts)
 	assert.Equal(t, ra.SimilarityThreshold, 70)
+	facts[ConfigRenameAnalysisCopyDetection] = CopyDetectionHarder
+	facts[ConfigFileDiffDisableCleanup] = true
+	facts[ConfigFileWhitespaceIgnore] = true
+	facts[ConfigFileDiffAlgorithm] = DiffAlgorithmMyers
+	ra.Configure(facts)
+	assert.Equal(t, ra.CopyDetection, CopyDetectionHarder)
+	assert.True(t, ra.cleanupDisabled)
+	assert.True(t, ra.whitespaceIgnore)
+	assert.Equal(t, ra.diffAlgorithm, DiffAlgorithmMyers)
 }
 
 func TestRenameAnalysisRegistration(t *testing.T) {
@@ -68,6 +80,15 @@ func TestRenameAnalysisInitializeInvalidThreshold(t *testing.T) {
 	ra.Initialize(test.Repository)
 }
 
+func TestRenameAnalysisInitializeInvalidCopyDetection(t *testing.T) {
+	ra := RenameAnalysis{SimilarityThreshold: 80, CopyDetection: 3}
+	ra.Initialize(test.Repository)
+	assert.Equal(t, ra.CopyDetection, 0)
+	ra = RenameAnalysis{SimilarityThreshold: 80, CopyDetection: CopyDetectionHarder}
+	ra.Initialize(test.Repository)
+	assert.Equal(t, ra.CopyDetection, CopyDetectionHarder)
+}
+
 func TestRenameAnalysisConsume(t *testing.T) {
 	ra := fixtureRenameAnalysis()
 	changes := make(object.Changes, 3)
@@ -132,6 +153,151 @@ func TestRenameAnalysisConsume(t *testing.T) {
 	assert.Nil(t, err)
 	renamed = res[DependencyTreeChanges].(object.Changes)
 	assert.Equal(t, len(renamed), 3)
+	assert.Len(t, res[DependencyCopies].(map[string]FileCopy), 0)
+}
+
+func fixtureRenameAnalysisCopies(t *testing.T) (map[string]interface{}, *object.ChangeEntry) {
+	// 2b1ed978194a94edeabbca6de7ff3b5771d4d665
+	treeFrom, _ := test.Repository.TreeObject(plumbing.NewHash(
+		"96c6ece9b2f3c7c51b83516400d278dea5605100"))
+	treeTo, _ := test.Repository.TreeObject(plumbing.NewHash(
+		"251f2094d7b523d5bcc60e663b6cf38151bf8844"))
+	analyser := object.ChangeEntry{
+		Name: "analyser.go",
+		Tree: treeFrom,
+		TreeEntry: object.TreeEntry{
+			Name: "analyser.go",
+			Mode: 0100644,
+			Hash: plumbing.NewHash("baa64828831d174f40140e4b3cfa77d1e917a2c1"),
+		},
+	}
+	changes := object.Changes{&object.Change{From: object.ChangeEntry{}, To: object.ChangeEntry{
+		Name: "burndown.go",
+		Tree: treeTo,
+		TreeEntry: object.TreeEntry{
+			Name: "burndown.go",
+			Mode: 0100644,
+			Hash: plumbing.NewHash("29c9fafd6a2fae8cd20298c3f60115bc31a4c0f2"),
+		},
+	}}}
+	cache := map[plumbing.Hash]*CachedBlob{}
+	AddHash(t, cache, "baa64828831d174f40140e4b3cfa77d1e917a2c1")
+	AddHash(t, cache, "29c9fafd6a2fae8cd20298c3f60115bc31a4c0f2")
+	AddHash(t, cache, "f7d918ec500e2f925ecde79b51cc007bac27de72")
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"2b1ed978194a94edeabbca6de7ff3b5771d4d665"))
+	deps := map[string]interface{}{}
+	deps[DependencyBlobCache] = cache
+	deps[DependencyTreeChanges] = changes
+	deps[core.DependencyCommit] = commit
+	return deps, &analyser
+}
+
+func TestRenameAnalysisConsumeCopiesModified(t *testing.T) {
+	ra := fixtureRenameAnalysis()
+	ra.SimilarityThreshold = 37
+	deps, analyser := fixtureRenameAnalysisCopies(t)
+	res, err := ra.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyCopies].(map[string]FileCopy), 0)
+	ra.CopyDetection = CopyDetectionModified
+	res, err = ra.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyCopies].(map[string]FileCopy), 0)
+	to := *analyser
+	to.TreeEntry.Hash = plumbing.NewHash("f7d918ec500e2f925ecde79b51cc007bac27de72")
+	deps[DependencyTreeChanges] = append(
+		deps[DependencyTreeChanges].(object.Changes), &object.Change{From: *analyser, To: to})
+	res, err = ra.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyTreeChanges].(object.Changes), 2)
+	copies := res[DependencyCopies].(map[string]FileCopy)
+	assert.Len(t, copies, 1)
+	fileCopy := copies["burndown.go"]
+	assert.Equal(t, fileCopy.From.Name, "analyser.go")
+	assert.Equal(t, fileCopy.To.Name, "burndown.go")
+	cache := deps[DependencyBlobCache].(map[plumbing.Hash]*CachedBlob)
+	oldLines, _ := cache[analyser.TreeEntry.Hash].CountLines()
+	newLines, _ := cache[fileCopy.To.TreeEntry.Hash].CountLines()
+	assert.Equal(t, fileCopy.Diff.OldLinesOfCode, oldLines)
+	assert.Equal(t, fileCopy.Diff.NewLinesOfCode, newLines)
+	assert.True(t, len(fileCopy.Diff.Diffs) > 1)
+	ra.SimilarityThreshold = 39
+	res, err = ra.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyCopies].(map[string]FileCopy), 0)
+	// the initial commit has no parents
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	ra.SimilarityThreshold = 37
+	res, err = ra.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyCopies].(map[string]FileCopy), 0)
+}
+
+func TestRenameAnalysisConsumeCopiesHarder(t *testing.T) {
+	ra := fixtureRenameAnalysis()
+	ra.CopyDetection = CopyDetectionHarder
+	deps, analyser := fixtureRenameAnalysisCopies(t)
+	to := *analyser
+	to.Name = "copy/analyser.go"
+	deps[DependencyTreeChanges] = append(
+		deps[DependencyTreeChanges].(object.Changes), &object.Change{To: to})
+	res, err := ra.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyTreeChanges].(object.Changes), 2)
+	copies := res[DependencyCopies].(map[string]FileCopy)
+	assert.Len(t, copies, 1)
+	fileCopy := copies["copy/analyser.go"]
+	assert.Equal(t, fileCopy.From.Name, "analyser.go")
+	assert.Equal(t, fileCopy.From.TreeEntry.Hash, analyser.TreeEntry.Hash)
+	assert.Equal(t, fileCopy.Diff.OldLinesOfCode, fileCopy.Diff.NewLinesOfCode)
+	assert.Len(t, fileCopy.Diff.Diffs, 1)
+	ra.SimilarityThreshold = 37
+	res, err = ra.Consume(deps)
+	assert.Nil(t, err)
+	copies = res[DependencyCopies].(map[string]FileCopy)
+	assert.Len(t, copies, 2)
+	assert.Equal(t, copies["burndown.go"].From.Name, "analyser.go")
+}
+
+// TestRenameAnalysisDetectCopiesMaxCandidates checks that at most maxCandidates copy sources
+// are compared with each added file.
+func TestRenameAnalysisDetectCopiesMaxCandidates(t *testing.T) {
+	cache := map[plumbing.Hash]*CachedBlob{}
+	entry := func(name, text string) object.ChangeEntry {
+		blob := &CachedBlob{Data: []byte(text)}
+		blob.Hash = plumbing.ComputeHash(plumbing.BlobObject, blob.Data)
+		blob.Size = int64(len(blob.Data))
+		cache[blob.Hash] = blob
+		return object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+			Name: name, Mode: 0100644, Hash: blob.Hash}}
+	}
+	text := func(format string) string {
+		result := ""
+		for i := 0; len(result) < 1000; i++ {
+			result += fmt.Sprintf(format, i)
+		}
+		return result
+	}
+	original, other := text("line number %d\n"), text("other text %d\n")
+	added := &object.Change{To: entry("copy-b.txt", "edited\n"+original)}
+	changes := object.Changes{
+		added,
+		// the closest name, so it is the first candidate
+		&object.Change{From: entry("copy-a.txt", other), To: entry("copy-a.txt", other+"\n")},
+		&object.Change{From: entry("source.txt", original), To: entry("source.txt", original+"\n")},
+	}
+	blobs := sortableBlobs{{change: added, size: cache[added.To.TreeEntry.Hash].Size}}
+	ra := fixtureRenameAnalysis()
+	ra.CopyDetection = CopyDetectionModified
+	copies, err := ra.detectCopies(nil, changes, blobs, cache, 1)
+	assert.Nil(t, err)
+	assert.Len(t, copies, 0)
+	copies, err = ra.detectCopies(nil, changes, blobs, cache, 2)
+	assert.Nil(t, err)
+	assert.Len(t, copies, 1)
+	assert.Equal(t, "source.txt", copies["copy-b.txt"].From.Name)
 }
 
 func TestSortableChanges(t *testing.T) {
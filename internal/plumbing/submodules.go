@@ -0,0 +1,210 @@
+package plumbing
+
+import (
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// Submodules resolves the gitlinks of the analysed repository against the local clones of
+// the corresponding submodule repositories. It expands the changed commit pointers into the
+// changes of the files inside the submodules, prefixed by the submodule paths.
+// nil *Submodules is valid and does not expand anything.
+type Submodules struct {
+	// Paths are the sorted paths of the submodules in the analysed repository.
+	Paths []string
+
+	repositories map[string]*git.Repository
+}
+
+// NewSubmodules opens the submodule clones. Each mapping has the format
+// "path/in/the/repository=/path/to/the/clone". It returns nil if there are no mappings.
+func NewSubmodules(mappings []string) (*Submodules, error) {
+	if len(mappings) == 0 {
+		return nil, nil
+	}
+	submodules := &Submodules{repositories: map[string]*git.Repository{}}
+	for _, mapping := range mappings {
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("invalid submodule mapping %q, must be path=clone", mapping)
+		}
+		name := strings.Trim(path.Clean(parts[0]), "/")
+		if _, exists := submodules.repositories[name]; exists {
+			return nil, errors.Errorf("submodule %s is specified twice", name)
+		}
+		repository, err := git.PlainOpen(parts[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to open the clone of submodule %s at %s",
+				name, parts[1])
+		}
+		submodules.repositories[name] = repository
+		submodules.Paths = append(submodules.Paths, name)
+	}
+	sort.Strings(submodules.Paths)
+	return submodules, nil
+}
+
+// Expand replaces the changes of the known gitlinks with the changes of the files
+// inside the submodules. The other changes are returned intact.
+func (submodules *Submodules) Expand(changes object.Changes) (object.Changes, error) {
+	if submodules == nil {
+		return changes, nil
+	}
+	result := make(object.Changes, 0, len(changes))
+	for _, change := range changes {
+		fromLink := submodules.isGitlink(&change.From)
+		toLink := submodules.isGitlink(&change.To)
+		if !fromLink && !toLink {
+			result = append(result, change)
+			continue
+		}
+		var fromTree, toTree *object.Tree
+		var err error
+		if fromLink {
+			fromTree, err = submodules.tree(change.From.Name, change.From.TreeEntry.Hash)
+			if err != nil {
+				return nil, err
+			}
+		} else if change.From.Name != "" {
+			// a file was replaced with a submodule
+			result = append(result, &object.Change{From: change.From})
+		}
+		if toLink {
+			toTree, err = submodules.tree(change.To.Name, change.To.TreeEntry.Hash)
+			if err != nil {
+				return nil, err
+			}
+		} else if change.To.Name != "" {
+			// a submodule was replaced with a file
+			result = append(result, &object.Change{To: change.To})
+		}
+		inner, err := object.DiffTree(fromTree, toTree)
+		if err != nil {
+			return nil, err
+		}
+		prefix := change.To.Name
+		if !toLink {
+			prefix = change.From.Name
+		}
+		for _, innerChange := range inner {
+			if innerChange.From.Name != "" {
+				innerChange.From.Name = prefix + "/" + innerChange.From.Name
+			}
+			if innerChange.To.Name != "" {
+				innerChange.To.Name = prefix + "/" + innerChange.To.Name
+			}
+			result = append(result, innerChange)
+		}
+	}
+	return result, nil
+}
+
+// ListFiles returns all the files in the submodules referenced by the tree as insertions.
+func (submodules *Submodules) ListFiles(tree *object.Tree) (object.Changes, error) {
+	if submodules == nil {
+		return nil, nil
+	}
+	var changes object.Changes
+	for _, name := range submodules.Paths {
+		entry, err := tree.FindEntry(name)
+		if err != nil || entry.Mode != filemode.Submodule {
+			continue
+		}
+		subtree, err := submodules.tree(name, entry.Hash)
+		if err != nil {
+			return nil, err
+		}
+		fileIter := subtree.Files()
+		for {
+			file, err := fileIter.Next()
+			if err != nil {
+				fileIter.Close()
+				if err == io.EOF {
+					break
+				}
+				return nil, err
+			}
+			changes = append(changes, &object.Change{To: object.ChangeEntry{
+				Name: name + "/" + file.Name, Tree: subtree, TreeEntry: object.TreeEntry{
+					Name: path.Base(file.Name), Mode: file.Mode, Hash: file.Hash}}})
+		}
+	}
+	return changes, nil
+}
+
+// File returns the file in the commit; the files inside the known submodules are resolved
+// against the corresponding clones.
+func (submodules *Submodules) File(commit *object.Commit, name string) (*object.File, error) {
+	file, err := commit.File(name)
+	if err != object.ErrFileNotFound || submodules == nil {
+		return file, err
+	}
+	for _, subname := range submodules.Paths {
+		if !strings.HasPrefix(name, subname+"/") {
+			continue
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			return nil, err
+		}
+		entry, err := tree.FindEntry(subname)
+		if err != nil || entry.Mode != filemode.Submodule {
+			continue
+		}
+		subtree, err := submodules.tree(subname, entry.Hash)
+		if err != nil {
+			return nil, err
+		}
+		file, err := subtree.File(name[len(subname)+1:])
+		if err != nil {
+			return nil, err
+		}
+		file.Name = name
+		return file, nil
+	}
+	return nil, object.ErrFileNotFound
+}
+
+// BlobObject loads the blob from the first clone which contains it.
+func (submodules *Submodules) BlobObject(hash plumbing.Hash) (*object.Blob, error) {
+	if submodules != nil {
+		for _, name := range submodules.Paths {
+			blob, err := submodules.repositories[name].BlobObject(hash)
+			if err == nil {
+				return blob, nil
+			}
+		}
+	}
+	return nil, plumbing.ErrObjectNotFound
+}
+
+// isGitlink checks whether the change entry points to a commit of a known submodule.
+func (submodules *Submodules) isGitlink(entry *object.ChangeEntry) bool {
+	if entry.Name == "" || entry.TreeEntry.Mode != filemode.Submodule {
+		return false
+	}
+	_, exists := submodules.repositories[entry.Name]
+	return exists
+}
+
+// tree loads the tree of the submodule commit.
+func (submodules *Submodules) tree(name string, hash plumbing.Hash) (*object.Tree, error) {
+	commit, err := submodules.repositories[name].CommitObject(hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load commit %s of submodule %s", hash, name)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the tree of commit %s of submodule %s",
+			hash, name)
+	}
+	return tree, nil
+}
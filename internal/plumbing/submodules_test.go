@@ -0,0 +1,224 @@
+package plumbing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+)
+
+// fixtureSuperproject creates an in-memory repository with two commits which reference
+// fbe766f and 2b1ed97 of the test repository as submodule "lib/hercules".
+func fixtureSuperproject(t *testing.T) (*git.Repository, []*object.Commit) {
+	storage := memory.NewStorage()
+	repository, err := git.Init(storage, nil)
+	assert.Nil(t, err)
+	store := func(obj interface {
+		Encode(plumbing.EncodedObject) error
+	}) plumbing.Hash {
+		encoded := storage.NewEncodedObject()
+		assert.Nil(t, obj.Encode(encoded))
+		hash, err := storage.SetEncodedObject(encoded)
+		assert.Nil(t, err)
+		return hash
+	}
+	readme := storage.NewEncodedObject()
+	readme.SetType(plumbing.BlobObject)
+	writer, _ := readme.Writer()
+	writer.Write([]byte("superproject\n"))
+	writer.Close()
+	readmeHash, err := storage.SetEncodedObject(readme)
+	assert.Nil(t, err)
+	var commits []*object.Commit
+	var parents []plumbing.Hash
+	for _, pointer := range []string{
+		"fbe766ffdc3f87f6affddc051c6f8b419beea6a2", "2b1ed978194a94edeabbca6de7ff3b5771d4d665"} {
+		libHash := store(&object.Tree{Entries: []object.TreeEntry{
+			{Name: "hercules", Mode: filemode.Submodule, Hash: plumbing.NewHash(pointer)},
+		}})
+		rootHash := store(&object.Tree{Entries: []object.TreeEntry{
+			{Name: "README.md", Mode: filemode.Regular, Hash: readmeHash},
+			{Name: "lib", Mode: filemode.Dir, Hash: libHash},
+		}})
+		signature := object.Signature{Name: "test", Email: "test@srcd"}
+		commitHash := store(&object.Commit{
+			Author: signature, Committer: signature, Message: pointer,
+			TreeHash: rootHash, ParentHashes: parents,
+		})
+		commit, err := object.GetCommit(storage, commitHash)
+		assert.Nil(t, err)
+		commits = append(commits, commit)
+		parents = []plumbing.Hash{commitHash}
+	}
+	return repository, commits
+}
+
+func fixtureSubmodules() *Submodules {
+	return &Submodules{
+		Paths:        []string{"lib/hercules"},
+		repositories: map[string]*git.Repository{"lib/hercules": test.Repository},
+	}
+}
+
+func TestNewSubmodules(t *testing.T) {
+	submodules, err := NewSubmodules(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, submodules)
+	_, err = NewSubmodules([]string{"lib/hercules"})
+	assert.NotNil(t, err)
+	_, err = NewSubmodules([]string{"lib/hercules=/does/not/exist"})
+	assert.NotNil(t, err)
+}
+
+func TestSubmodulesExpand(t *testing.T) {
+	_, commits := fixtureSuperproject(t)
+	tree1, _ := commits[0].Tree()
+	tree2, _ := commits[1].Tree()
+	changes, err := object.DiffTree(tree1, tree2)
+	assert.Nil(t, err)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, filemode.Submodule, changes[0].To.TreeEntry.Mode)
+
+	var submodules *Submodules
+	expanded, err := submodules.Expand(changes)
+	assert.Nil(t, err)
+	assert.Equal(t, changes, expanded)
+
+	submodules = fixtureSubmodules()
+	expanded, err = submodules.Expand(changes)
+	assert.Nil(t, err)
+	assert.Len(t, expanded, 12)
+	actions := map[string]merkletrie.Action{}
+	for _, change := range expanded {
+		action, err := change.Action()
+		assert.Nil(t, err)
+		if action == merkletrie.Delete {
+			actions[change.From.Name] = action
+		} else {
+			actions[change.To.Name] = action
+		}
+	}
+	assert.Equal(t, merkletrie.Delete, actions["lib/hercules/analyser.go"])
+	assert.Equal(t, merkletrie.Modify, actions["lib/hercules/cmd/hercules/main.go"])
+	assert.Equal(t, merkletrie.Insert, actions["lib/hercules/toposort/toposort.go"])
+
+	// the submodule is added
+	changes[0].From = object.ChangeEntry{}
+	expanded, err = submodules.Expand(changes)
+	assert.Nil(t, err)
+	assert.Len(t, expanded, 21)
+
+	// the commit pointer is not in the clone
+	changes[0].To.TreeEntry.Hash = plumbing.NewHash("ffffffffffffffffffffffffffffffffffffffff")
+	_, err = submodules.Expand(changes)
+	assert.NotNil(t, err)
+}
+
+func TestSubmodulesListFiles(t *testing.T) {
+	_, commits := fixtureSuperproject(t)
+	tree, _ := commits[1].Tree()
+	var submodules *Submodules
+	files, err := submodules.ListFiles(tree)
+	assert.Nil(t, err)
+	assert.Len(t, files, 0)
+	submodules = fixtureSubmodules()
+	files, err = submodules.ListFiles(tree)
+	assert.Nil(t, err)
+	assert.Len(t, files, 21)
+	for _, change := range files {
+		assert.Contains(t, change.To.Name, "lib/hercules/")
+		file, err := change.To.Tree.TreeEntryFile(&change.To.TreeEntry)
+		assert.Nil(t, err)
+		assert.Equal(t, change.To.TreeEntry.Hash, file.Hash)
+	}
+}
+
+func TestSubmodulesFile(t *testing.T) {
+	_, commits := fixtureSuperproject(t)
+	var submodules *Submodules
+	_, err := submodules.File(commits[1], "lib/hercules/burndown.go")
+	assert.Equal(t, object.ErrFileNotFound, err)
+	submodules = fixtureSubmodules()
+	file, err := submodules.File(commits[1], "lib/hercules/burndown.go")
+	assert.Nil(t, err)
+	assert.Equal(t, "lib/hercules/burndown.go", file.Name)
+	_, err = submodules.File(commits[0], "lib/hercules/burndown.go")
+	assert.NotNil(t, err)
+	file, err = submodules.File(commits[1], "README.md")
+	assert.Nil(t, err)
+	assert.Equal(t, "README.md", file.Name)
+	_, err = submodules.File(commits[1], "lib/other.go")
+	assert.Equal(t, object.ErrFileNotFound, err)
+}
+
+func TestSubmodulesBlobObject(t *testing.T) {
+	var submodules *Submodules
+	hash := plumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe")
+	_, err := submodules.BlobObject(hash)
+	assert.Equal(t, plumbing.ErrObjectNotFound, err)
+	submodules = fixtureSubmodules()
+	blob, err := submodules.BlobObject(hash)
+	assert.Nil(t, err)
+	assert.Equal(t, hash, blob.Hash)
+	_, err = submodules.BlobObject(plumbing.NewHash("ffffffffffffffffffffffffffffffffffffffff"))
+	assert.Equal(t, plumbing.ErrObjectNotFound, err)
+}
+
+func TestTreeDiffConsumeSubmodules(t *testing.T) {
+	repository, commits := fixtureSuperproject(t)
+	td := &TreeDiff{}
+	facts := map[string]interface{}{}
+	assert.Nil(t, td.Configure(facts))
+	assert.Nil(t, facts[FactTreeDiffSubmodules])
+	td.Submodules = fixtureSubmodules()
+	assert.Nil(t, td.Configure(facts))
+	assert.Equal(t, td.Submodules, facts[FactTreeDiffSubmodules])
+	assert.Nil(t, td.Initialize(repository))
+	cache := &BlobCache{}
+	assert.Nil(t, cache.Initialize(repository))
+
+	deps := map[string]interface{}{core.DependencyCommit: commits[0]}
+	res, err := td.Consume(deps)
+	assert.Nil(t, err)
+	changes := res[DependencyTreeChanges].(object.Changes)
+	assert.Len(t, changes, 13)
+	assert.Equal(t, "README.md", changes[0].To.Name)
+	deps[DependencyTreeChanges] = changes
+	res, err = cache.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyBlobCache], 13)
+
+	deps = map[string]interface{}{core.DependencyCommit: commits[1]}
+	res, err = td.Consume(deps)
+	assert.Nil(t, err)
+	changes = res[DependencyTreeChanges].(object.Changes)
+	assert.Len(t, changes, 12)
+	deps[DependencyTreeChanges] = changes
+	res, err = cache.Consume(deps)
+	assert.Nil(t, err)
+	blobs := res[DependencyBlobCache].(map[plumbing.Hash]*CachedBlob)
+	for _, change := range changes {
+		for _, entry := range []object.ChangeEntry{change.From, change.To} {
+			if entry.Name != "" {
+				assert.True(t, len(blobs[entry.TreeEntry.Hash].Data) > 0, entry.Name)
+			}
+		}
+	}
+
+	// the blob exists only in the submodule clone
+	td.Languages = map[string]bool{"Go": true}
+	_, err = td.checkLanguage("lib/hercules/.travis.yml", nil,
+		td.sniffer(plumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe")))
+	assert.Nil(t, err)
+	td.Submodules = nil
+	_, err = td.checkLanguage("lib/hercules/.travis.yml", nil,
+		td.sniffer(plumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe")))
+	assert.NotNil(t, err)
+}
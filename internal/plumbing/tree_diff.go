@@ -3,6 +3,7 @@ package plumbing
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"path"
 	"regexp"
@@ -25,15 +26,37 @@ type TreeDiff struct {
 	SkipFiles  []string
 	NameFilter *regexp.Regexp
 	Languages  map[string]bool
+	// PartialHistory makes the first commit to be diffed with its first parent if it has any.
+	PartialHistory bool
+	// Submodules expands the changed submodule pointers into the changes of the files inside.
+	Submodules *Submodules
+	// GitAttributes skips the files which are marked as generated, vendored or binary in
+	// .gitattributes and makes the language filter respect linguist-language.
+	GitAttributes bool
+	// SkipGenerated skips the files which are detected as generated by enry.IsGenerated.
+	SkipGenerated bool
+	// IncludePatterns are the gitignore-style patterns of the analysed files.
+	// All the files are analysed if it is empty.
+	IncludePatterns []string
+	// ExcludePatterns are the gitignore-style patterns of the skipped files.
+	// They have the priority over IncludePatterns.
+	ExcludePatterns []string
 
 	previousTree   *object.Tree
 	previousCommit plumbing.Hash
 	repository     *git.Repository
+	attributes     *gitAttributes
+	includes       pathPatterns
+	excludes       pathPatterns
 }
 
 const (
 	// DependencyTreeChanges is the name of the dependency provided by TreeDiff.
 	DependencyTreeChanges = "changes"
+	// DependencyBaseTreeChanges is the name of the dependency provided by TreeDiff.
+	// It lists the files which existed before the first consumed commit as insertions.
+	// It is not empty only if ConfigPipelinePartialHistory is set and the commit has parents.
+	DependencyBaseTreeChanges = "base_changes"
 	// ConfigTreeDiffEnableBlacklist is the name of the configuration option
 	// (TreeDiff.Configure()) which allows to skip blacklisted directories.
 	ConfigTreeDiffEnableBlacklist = "TreeDiff.EnableBlacklist"
@@ -52,6 +75,32 @@ const (
 	// ConfigTreeDiffFilterRegexp is the name of the configuration option
 	// (TreeDiff.Configure()) which makes FileDiff consider only those files which have names matching this regexp.
 	ConfigTreeDiffFilterRegexp = "TreeDiff.FilteredRegexes"
+
+	// ConfigTreeDiffSubmodules is the name of the configuration option (TreeDiff.Configure())
+	// which maps the submodule paths to their local clones: "path=clone".
+	ConfigTreeDiffSubmodules = "TreeDiff.Submodules"
+	// FactTreeDiffSubmodules contains the *Submodules which are expanded by TreeDiff.
+	// It is not set if ConfigTreeDiffSubmodules is empty.
+	FactTreeDiffSubmodules = "TreeDiff.SubmoduleClones"
+
+	// ConfigTreeDiffGitAttributes is the name of the configuration option (TreeDiff.Configure())
+	// which makes TreeDiff respect linguist-generated, linguist-vendored, linguist-language,
+	// binary and diff in .gitattributes.
+	ConfigTreeDiffGitAttributes = "TreeDiff.GitAttributes"
+	// ConfigTreeDiffSkipGenerated is the name of the configuration option (TreeDiff.Configure())
+	// which skips the generated files according to src-d/enry.IsGenerated.
+	ConfigTreeDiffSkipGenerated = "TreeDiff.SkipGenerated"
+
+	// ConfigTreeDiffIncludePatterns is the name of the configuration option
+	// (TreeDiff.Configure()) which sets the gitignore-style patterns of the analysed files.
+	ConfigTreeDiffIncludePatterns = "TreeDiff.IncludePatterns"
+	// ConfigTreeDiffExcludePatterns is the name of the configuration option
+	// (TreeDiff.Configure()) which sets the gitignore-style patterns of the skipped files.
+	ConfigTreeDiffExcludePatterns = "TreeDiff.ExcludePatterns"
+	// ConfigTreeDiffPatternsFile is the name of the configuration option (TreeDiff.Configure())
+	// which loads the include and exclude patterns from the file, one per line.
+	// The exclude patterns start with "!".
+	ConfigTreeDiffPatternsFile = "TreeDiff.PatternsFile"
 )
 
 // defaultBlacklistedPrefixes is the list of file path prefixes which should be skipped by default.
@@ -71,7 +120,7 @@ func (treediff *TreeDiff) Name() string {
 // Each produced entity will be inserted into `deps` of dependent Consume()-s according
 // to this list. Also used by core.Registry to build the global map of providers.
 func (treediff *TreeDiff) Provides() []string {
-	arr := [...]string{DependencyTreeChanges}
+	arr := [...]string{DependencyTreeChanges, DependencyBaseTreeChanges}
 	return arr[:]
 }
 
@@ -113,7 +162,51 @@ func (treediff *TreeDiff) ListConfigurationOptions() []core.ConfigurationOption
 		Description: "Whitelist regexp to determine which files to analyze.",
 		Flag:        "whitelist",
 		Type:        core.StringConfigurationOption,
-		Default:     ""},
+		Default:     ""}, {
+
+		Name: ConfigTreeDiffSubmodules,
+		Description: "Analyse the files inside the submodules. Each value maps the path of " +
+			"a submodule to the local clone of its repository: \"path/to/submodule=/path/to/clone\". " +
+			"Separated with commas \",\". The other submodules are ignored.",
+		Flag:    "submodules",
+		Type:    core.StringsConfigurationOption,
+		Default: []string{}}, {
+
+		Name: ConfigTreeDiffGitAttributes,
+		Description: "Skip the files which are marked as linguist-generated, linguist-vendored, " +
+			"binary or -diff in .gitattributes. linguist-vendored=false and linguist-generated=false " +
+			"override the automatic detection and linguist-language overrides the language.",
+		Flag:    "gitattributes",
+		Type:    core.BoolConfigurationOption,
+		Default: false}, {
+
+		Name:        ConfigTreeDiffSkipGenerated,
+		Description: "Skip generated files (according to src-d/enry.IsGenerated).",
+		Flag:        "skip-generated",
+		Type:        core.BoolConfigurationOption,
+		Default:     false}, {
+
+		Name: ConfigTreeDiffIncludePatterns,
+		Description: "Analyse only the files which match these gitignore-style patterns, " +
+			"e.g. \"services/**/*.go\". Separated with commas \",\".",
+		Flag:    "include",
+		Type:    core.StringsConfigurationOption,
+		Default: []string{}}, {
+
+		Name: ConfigTreeDiffExcludePatterns,
+		Description: "Skip the files which match these gitignore-style patterns, " +
+			"e.g. \"*_test.go\" or \"**/mocks/**\". Separated with commas \",\".",
+		Flag:    "exclude",
+		Type:    core.StringsConfigurationOption,
+		Default: []string{}}, {
+
+		Name: ConfigTreeDiffPatternsFile,
+		Description: "Path to the file with the gitignore-style patterns of the analysed files, " +
+			"one per line. The patterns of the skipped files start with \"!\". " +
+			"They are added to --include and --exclude.",
+		Flag:    "patterns-file",
+		Type:    core.PathConfigurationOption,
+		Default: ""},
 	}
 	return options[:]
 }
@@ -136,6 +229,55 @@ func (treediff *TreeDiff) Configure(facts map[string]interface{}) error {
 	if val, exists := facts[ConfigTreeDiffFilterRegexp].(string); exists {
 		treediff.NameFilter = regexp.MustCompile(val)
 	}
+	if val, exists := facts[core.ConfigPipelinePartialHistory].(bool); exists {
+		treediff.PartialHistory = val
+	}
+	if val, exists := facts[ConfigTreeDiffSubmodules].([]string); exists {
+		submodules, err := NewSubmodules(val)
+		if err != nil {
+			return err
+		}
+		treediff.Submodules = submodules
+	}
+	if treediff.Submodules != nil {
+		facts[FactTreeDiffSubmodules] = treediff.Submodules
+	}
+	if val, exists := facts[ConfigTreeDiffGitAttributes].(bool); exists {
+		treediff.GitAttributes = val
+	}
+	if val, exists := facts[ConfigTreeDiffSkipGenerated].(bool); exists {
+		treediff.SkipGenerated = val
+	}
+	if err := treediff.configurePatterns(facts); err != nil {
+		return err
+	}
+	if len(treediff.IncludePatterns) > 0 {
+		facts[core.FactIncludePatterns] = treediff.IncludePatterns
+	}
+	if len(treediff.ExcludePatterns) > 0 {
+		facts[core.FactExcludePatterns] = treediff.ExcludePatterns
+	}
+	return nil
+}
+
+// configurePatterns sets IncludePatterns and ExcludePatterns from the flags and the patterns file.
+func (treediff *TreeDiff) configurePatterns(facts map[string]interface{}) error {
+	includes, includesExist := facts[ConfigTreeDiffIncludePatterns].([]string)
+	excludes, excludesExist := facts[ConfigTreeDiffExcludePatterns].([]string)
+	patternsFile, _ := facts[ConfigTreeDiffPatternsFile].(string)
+	if !includesExist && !excludesExist && patternsFile == "" {
+		return nil
+	}
+	treediff.IncludePatterns = append([]string{}, includes...)
+	treediff.ExcludePatterns = append([]string{}, excludes...)
+	if patternsFile != "" {
+		fileIncludes, fileExcludes, err := readPatternsFile(patternsFile)
+		if err != nil {
+			return err
+		}
+		treediff.IncludePatterns = append(treediff.IncludePatterns, fileIncludes...)
+		treediff.ExcludePatterns = append(treediff.ExcludePatterns, fileExcludes...)
+	}
 	return nil
 }
 
@@ -143,7 +285,10 @@ func (treediff *TreeDiff) Configure(facts map[string]interface{}) error {
 // calls. The repository which is going to be analysed is supplied as an argument.
 func (treediff *TreeDiff) Initialize(repository *git.Repository) error {
 	treediff.previousTree = nil
+	treediff.attributes = nil
 	treediff.repository = repository
+	treediff.includes = newPathPatterns(treediff.IncludePatterns)
+	treediff.excludes = newPathPatterns(treediff.ExcludePatterns)
 	if treediff.Languages == nil {
 		treediff.Languages = map[string]bool{}
 		treediff.Languages[allLanguages] = true
@@ -171,81 +316,173 @@ func (treediff *TreeDiff) Consume(deps map[string]interface{}) (map[string]inter
 	if err != nil {
 		return nil, err
 	}
-	var diffs object.Changes
+	var diffs, baseDiffs object.Changes
+	if treediff.previousTree == nil && treediff.PartialHistory && commit.NumParents() > 0 {
+		// the history before this commit is not analysed
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		treediff.previousTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+		if err = treediff.loadAttributes(treediff.previousTree); err != nil {
+			return nil, err
+		}
+		baseDiffs, err = treediff.listFiles(treediff.previousTree)
+		if err != nil {
+			return nil, err
+		}
+		baseDiffs = treediff.filterDiffs(baseDiffs, treediff.attributes)
+	}
+	previousAttributes := treediff.attributes
+	var reclassified object.Changes
 	if treediff.previousTree != nil {
 		diffs, err = object.DiffTree(treediff.previousTree, tree)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		diffs = []*object.Change{}
-		err = func() error {
-			fileIter := tree.Files()
-			defer fileIter.Close()
-			for {
-				file, err := fileIter.Next()
-				if err != nil {
-					if err == io.EOF {
-						break
-					}
-					return err
-				}
-				pass, err := treediff.checkLanguage(file.Name, file.Hash)
-				if err != nil {
-					return err
-				}
-				if !pass {
-					continue
-				}
-				diffs = append(diffs, &object.Change{
-					To: object.ChangeEntry{Name: file.Name, Tree: tree, TreeEntry: object.TreeEntry{
-						Name: file.Name, Mode: file.Mode, Hash: file.Hash}}})
+		diffs, err = treediff.Submodules.Expand(diffs)
+		if err != nil {
+			return nil, err
+		}
+		if treediff.GitAttributes {
+			treediff.attributes, err = treediff.attributes.update(diffs, treediff.readBlob)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if treediff.attributes != previousAttributes {
+			reclassified, err = treediff.reclassify(diffs, tree, previousAttributes)
+			if err != nil {
+				return nil, err
 			}
-			return nil
-		}()
+		}
+	} else {
+		if err = treediff.loadAttributes(tree); err != nil {
+			return nil, err
+		}
+		diffs, err = treediff.listFiles(tree)
 		if err != nil {
 			return nil, err
 		}
 	}
 	treediff.previousTree = tree
 	treediff.previousCommit = commit.Hash
-	diffs = treediff.filterDiffs(diffs)
-	return map[string]interface{}{DependencyTreeChanges: diffs}, nil
+	diffs = append(treediff.filterDiffs(diffs, previousAttributes), reclassified...)
+	return map[string]interface{}{
+		DependencyTreeChanges: diffs, DependencyBaseTreeChanges: baseDiffs}, nil
 }
 
-func (treediff *TreeDiff) filterDiffs(diffs object.Changes) object.Changes {
-	// filter without allocation
+// listFiles returns all the files in the tree as insertions.
+func (treediff *TreeDiff) listFiles(tree *object.Tree) (object.Changes, error) {
+	diffs := object.Changes{}
+	fileIter := tree.Files()
+	defer fileIter.Close()
+	for {
+		file, err := fileIter.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		diffs = append(diffs, &object.Change{
+			To: object.ChangeEntry{Name: file.Name, Tree: tree, TreeEntry: object.TreeEntry{
+				Name: file.Name, Mode: file.Mode, Hash: file.Hash}}})
+	}
+	submoduleFiles, err := treediff.Submodules.ListFiles(tree)
+	if err != nil {
+		return nil, err
+	}
+	return append(diffs, submoduleFiles...), nil
+}
+
+// filterDiffs removes the changes of the skipped files. previous are the attributes before
+// the changes: a modified file which starts or stops being analysed, e.g. because it became
+// generated, turns into the insertion or the deletion.
+func (treediff *TreeDiff) filterDiffs(diffs object.Changes, previous *gitAttributes) object.Changes {
 	filteredDiffs := make(object.Changes, 0, len(diffs))
-OUTER:
 	for _, change := range diffs {
-		if len(treediff.SkipFiles) > 0 && (enry.IsVendor(change.To.Name) || enry.IsVendor(change.From.Name)) {
+		before := treediff.analysed(
+			change.From, previous, treediff.sniffer(change.From.TreeEntry.Hash))
+		after := treediff.analysed(
+			change.To, treediff.attributes, treediff.sniffer(change.To.TreeEntry.Hash))
+		if before && after {
+			filteredDiffs = append(filteredDiffs, change)
+		} else if before {
+			filteredDiffs = append(filteredDiffs, &object.Change{From: change.From})
+		} else if after {
+			filteredDiffs = append(filteredDiffs, &object.Change{To: change.To})
+		}
+	}
+	return filteredDiffs
+}
+
+// reclassify returns the insertions and the deletions of the files which are not in diffs
+// but started or stopped being analysed because the attributes changed from previous.
+func (treediff *TreeDiff) reclassify(
+	diffs object.Changes, tree *object.Tree, previous *gitAttributes) (object.Changes, error) {
+
+	var reclassified object.Changes
+	changed := map[string]bool{}
+	for _, change := range diffs {
+		changed[change.From.Name] = true
+		changed[change.To.Name] = true
+	}
+	files, err := treediff.listFiles(tree)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if changed[file.To.Name] {
 			continue
 		}
-		for _, dir := range treediff.SkipFiles {
-			if strings.HasPrefix(change.To.Name, dir) || strings.HasPrefix(change.From.Name, dir) {
-				continue OUTER
-			}
+		sniff := treediff.sniffer(file.To.TreeEntry.Hash)
+		before := treediff.analysed(file.To, previous, sniff)
+		after := treediff.analysed(file.To, treediff.attributes, sniff)
+		if before && !after {
+			reclassified = append(reclassified, &object.Change{From: file.To})
+		} else if !before && after {
+			reclassified = append(reclassified, file)
 		}
-		if treediff.NameFilter != nil {
-			matchedTo := treediff.NameFilter.MatchString(change.To.Name)
-			matchedFrom := treediff.NameFilter.MatchString(change.From.Name)
+	}
+	return reclassified, nil
+}
 
-			if !matchedTo && !matchedFrom {
-				continue
-			}
-		}
-		var changeEntry object.ChangeEntry
-		if change.To.Tree == nil {
-			changeEntry = change.From
-		} else {
-			changeEntry = change.To
+// analysed returns whether the file passes all the filters given the attributes.
+// sniff returns the beginning of the file and is called only if the contents matter.
+func (treediff *TreeDiff) analysed(
+	entry object.ChangeEntry, attributes *gitAttributes, sniff func() ([]byte, error)) bool {
+
+	if entry.Name == "" || !treediff.checkPatterns(entry.Name) {
+		return false
+	}
+	for _, dir := range treediff.SkipFiles {
+		if strings.HasPrefix(entry.Name, dir) {
+			return false
 		}
-		if pass, _ := treediff.checkLanguage(changeEntry.Name, changeEntry.TreeEntry.Hash); !pass {
-			continue
+	}
+	if treediff.NameFilter != nil && !treediff.NameFilter.MatchString(entry.Name) {
+		return false
+	}
+	attrs := attributes.match(entry.Name)
+	vendored, vendoredSet := attrs.flag(attrLinguistVendored)
+	generated, generatedSet := attrs.flag(attrLinguistGenerated)
+	if vendored || generated || attrs.binary() {
+		return false
+	}
+	if !vendoredSet && len(treediff.SkipFiles) > 0 && enry.IsVendor(entry.Name) {
+		return false
+	}
+	if !generatedSet && treediff.SkipGenerated {
+		if buffer, err := sniff(); err == nil && enry.IsGenerated(entry.Name, buffer) {
+			return false
 		}
-		filteredDiffs = append(filteredDiffs, change)
 	}
-	return filteredDiffs
+	pass, _ := treediff.checkLanguage(entry.Name, attrs, sniff)
+	return pass
 }
 
 // Fork clones this PipelineItem.
@@ -253,29 +490,141 @@ func (treediff *TreeDiff) Fork(n int) []core.PipelineItem {
 	return core.ForkCopyPipelineItem(treediff, n)
 }
 
-// checkLanguage returns whether the blob corresponds to the list of required languages.
-func (treediff *TreeDiff) checkLanguage(name string, blobHash plumbing.Hash) (bool, error) {
+// Checkpoint writes the hash of the last consumed commit.
+func (treediff *TreeDiff) Checkpoint(writer io.Writer) error {
+	_, err := writer.Write(treediff.previousCommit[:])
+	return err
+}
+
+// Restore reads the hash of the last consumed commit and loads the corresponding tree.
+func (treediff *TreeDiff) Restore(reader io.Reader, facts map[string]interface{}) error {
+	var hash plumbing.Hash
+	_, err := io.ReadFull(reader, hash[:])
+	if err != nil {
+		return err
+	}
+	if hash == plumbing.ZeroHash {
+		return nil
+	}
+	commit, err := treediff.repository.CommitObject(hash)
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	treediff.previousCommit = hash
+	treediff.previousTree = tree
+	return treediff.loadAttributes(tree)
+}
+
+// loadAttributes parses all the .gitattributes files in the tree if GitAttributes is set.
+func (treediff *TreeDiff) loadAttributes(tree *object.Tree) error {
+	treediff.attributes = nil
+	if !treediff.GitAttributes {
+		return nil
+	}
+	changes, err := treediff.Submodules.ListFiles(tree)
+	if err != nil {
+		return err
+	}
+	err = tree.Files().ForEach(func(file *object.File) error {
+		if path.Base(file.Name) == gitAttributesFile {
+			changes = append(changes, &object.Change{To: object.ChangeEntry{
+				Name: file.Name, Tree: tree, TreeEntry: object.TreeEntry{
+					Name: gitAttributesFile, Mode: file.Mode, Hash: file.Hash}}})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	treediff.attributes, err = treediff.attributes.update(changes, treediff.readBlob)
+	return err
+}
+
+// checkPatterns returns whether the file matches IncludePatterns and does not match ExcludePatterns.
+func (treediff *TreeDiff) checkPatterns(name string) bool {
+	if len(treediff.includes) > 0 && !treediff.includes.match(name) {
+		return false
+	}
+	return !treediff.excludes.match(name)
+}
+
+// checkLanguage returns whether the file corresponds to the list of required languages.
+// sniff returns the beginning of the file and is called only if attrs do not set the language.
+func (treediff *TreeDiff) checkLanguage(
+	name string, attrs pathAttributes, sniff func() ([]byte, error)) (bool, error) {
 	if treediff.Languages[allLanguages] {
 		return true, nil
 	}
-	blob, err := treediff.repository.BlobObject(blobHash)
+	if lang := attrs.language(); lang != "" {
+		return treediff.Languages[lang], nil
+	}
+	buffer, err := sniff()
 	if err != nil {
 		return false, err
 	}
+	lang := enry.GetLanguage(path.Base(name), buffer)
+	return treediff.Languages[lang], nil
+}
+
+// blobObject loads the blob from the analysed repository or from the submodule clones.
+func (treediff *TreeDiff) blobObject(hash plumbing.Hash) (*object.Blob, error) {
+	blob, err := treediff.repository.BlobObject(hash)
+	if err == plumbing.ErrObjectNotFound && treediff.Submodules != nil {
+		blob, err = treediff.Submodules.BlobObject(hash)
+	}
+	return blob, err
+}
+
+// sniffBlob reads the beginning of the blob which is enough to detect its language.
+func (treediff *TreeDiff) sniffBlob(hash plumbing.Hash) ([]byte, error) {
+	blob, err := treediff.blobObject(hash)
+	if err != nil {
+		return nil, err
+	}
 	reader, err := blob.Reader()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
+	defer reader.Close()
 	buffer := make([]byte, 1024)
 	n, err := reader.Read(buffer)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	if n < len(buffer) {
-		buffer = buffer[:n]
+	return buffer[:n], nil
+}
+
+// sniffer returns the function which reads the beginning of the blob on the first call
+// and returns the same result afterwards.
+func (treediff *TreeDiff) sniffer(hash plumbing.Hash) func() ([]byte, error) {
+	var buffer []byte
+	var err error
+	read := false
+	return func() ([]byte, error) {
+		if !read {
+			buffer, err = treediff.sniffBlob(hash)
+			read = true
+		}
+		return buffer, err
 	}
-	lang := enry.GetLanguage(path.Base(name), buffer)
-	return treediff.Languages[lang], nil
+}
+
+// readBlob reads the whole blob.
+func (treediff *TreeDiff) readBlob(hash plumbing.Hash) ([]byte, error) {
+	blob, err := treediff.blobObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package plumbing
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,10 +23,11 @@ func TestTreeDiffMeta(t *testing.T) {
 	td := fixtureTreeDiff()
 	assert.Equal(t, td.Name(), "TreeDiff")
 	assert.Equal(t, len(td.Requires()), 0)
-	assert.Equal(t, len(td.Provides()), 1)
+	assert.Equal(t, len(td.Provides()), 2)
 	assert.Equal(t, td.Provides()[0], DependencyTreeChanges)
+	assert.Equal(t, td.Provides()[1], DependencyBaseTreeChanges)
 	opts := td.ListConfigurationOptions()
-	assert.Len(t, opts, 4)
+	assert.Len(t, opts, 10)
 }
 
 func TestTreeDiffConfigure(t *testing.T) {
@@ -35,11 +37,13 @@ func TestTreeDiffConfigure(t *testing.T) {
 		ConfigTreeDiffBlacklistedPrefixes: []string{"vendor"},
 		ConfigTreeDiffLanguages:           []string{"go"},
 		ConfigTreeDiffFilterRegexp:        "_.*",
+		core.ConfigPipelinePartialHistory: true,
 	}
 	assert.Nil(t, td.Configure(facts))
 	assert.Equal(t, td.Languages, map[string]bool{"go": true})
 	assert.Equal(t, td.SkipFiles, []string{"vendor"})
 	assert.Equal(t, td.NameFilter.String(), "_.*")
+	assert.True(t, td.PartialHistory)
 	delete(facts, ConfigTreeDiffLanguages)
 	td.Languages = nil
 	assert.Nil(t, td.Configure(facts))
@@ -74,7 +78,7 @@ func TestTreeDiffConsume(t *testing.T) {
 	td.previousTree, _ = prevCommit.Tree()
 	res, err := td.Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(res), 1)
+	assert.Equal(t, len(res), 2)
 	changes := res[DependencyTreeChanges].(object.Changes)
 	assert.Equal(t, len(changes), 12)
 	baseline := map[string]merkletrie.Action{
@@ -112,7 +116,7 @@ func TestTreeDiffConsumeFirst(t *testing.T) {
 	deps[core.DependencyCommit] = commit
 	res, err := td.Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(res), 1)
+	assert.Equal(t, len(res), 2)
 	changes := res[DependencyTreeChanges].(object.Changes)
 	assert.Equal(t, len(changes), 21)
 	for _, change := range changes {
@@ -122,6 +126,46 @@ func TestTreeDiffConsumeFirst(t *testing.T) {
 	}
 }
 
+func TestTreeDiffConsumePartialHistory(t *testing.T) {
+	td := fixtureTreeDiff()
+	td.PartialHistory = true
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"2b1ed978194a94edeabbca6de7ff3b5771d4d665"))
+	deps := map[string]interface{}{}
+	deps[core.DependencyCommit] = commit
+	res, err := td.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, len(res), 2)
+	assert.Len(t, res[DependencyTreeChanges].(object.Changes), 12)
+	baseChanges := res[DependencyBaseTreeChanges].(object.Changes)
+	assert.Len(t, baseChanges, 12)
+	baseFiles := map[string]bool{}
+	for _, change := range baseChanges {
+		action, err := change.Action()
+		assert.Nil(t, err)
+		assert.Equal(t, action, merkletrie.Insert)
+		baseFiles[change.To.Name] = true
+	}
+	assert.True(t, baseFiles["analyser.go"])
+	// the next commit is diffed as usual
+	commit, _ = test.Repository.CommitObject(plumbing.NewHash(
+		"f2966518478c184578b5519e6eb57eb495a08d2a"))
+	deps[core.DependencyCommit] = commit
+	res, err = td.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, res[DependencyBaseTreeChanges].(object.Changes), 0)
+	// the root commit does not have the base
+	td = fixtureTreeDiff()
+	td.PartialHistory = true
+	commit, _ = test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	deps[core.DependencyCommit] = commit
+	res, err = td.Consume(deps)
+	assert.Nil(t, err)
+	assert.True(t, len(res[DependencyTreeChanges].(object.Changes)) > 0)
+	assert.Len(t, res[DependencyBaseTreeChanges].(object.Changes), 0)
+}
+
 func TestTreeDiffBadCommit(t *testing.T) {
 	td := fixtureTreeDiff()
 	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
@@ -147,7 +191,7 @@ func TestTreeDiffConsumeSkip(t *testing.T) {
 	td.previousTree, _ = prevCommit.Tree()
 	res, err := td.Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(res), 1)
+	assert.Equal(t, len(res), 2)
 	changes := res[DependencyTreeChanges].(object.Changes)
 	assert.Equal(t, 37, len(changes))
 
@@ -160,7 +204,7 @@ func TestTreeDiffConsumeSkip(t *testing.T) {
 	})
 	res, err = td.Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(res), 1)
+	assert.Equal(t, len(res), 2)
 	changes = res[DependencyTreeChanges].(object.Changes)
 	assert.Equal(t, 31, len(changes))
 }
@@ -178,7 +222,7 @@ func TestTreeDiffConsumeOnlyFilesThatMatchFilter(t *testing.T) {
 	td.previousTree, _ = prevCommit.Tree()
 	res, err := td.Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(res), 1)
+	assert.Equal(t, len(res), 2)
 	changes := res[DependencyTreeChanges].(object.Changes)
 	assert.Equal(t, 37, len(changes))
 
@@ -190,7 +234,7 @@ func TestTreeDiffConsumeOnlyFilesThatMatchFilter(t *testing.T) {
 	})
 	res, err = td.Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(res), 1)
+	assert.Equal(t, len(res), 2)
 	changes = res[DependencyTreeChanges].(object.Changes)
 	assert.Equal(t, 27, len(changes))
 }
@@ -204,7 +248,7 @@ func TestTreeDiffConsumeLanguageFilterFirst(t *testing.T) {
 	deps[core.DependencyCommit] = commit
 	res, err := td.Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(res), 1)
+	assert.Equal(t, len(res), 2)
 	changes := res[DependencyTreeChanges].(object.Changes)
 	assert.Equal(t, len(changes), 6)
 	assert.Equal(t, changes[0].To.Name, "analyser.go")
@@ -224,13 +268,13 @@ func TestTreeDiffConsumeLanguageFilter(t *testing.T) {
 	deps[core.DependencyCommit] = commit
 	res, err := td.Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(res), 1)
+	assert.Equal(t, len(res), 2)
 	commit, _ = test.Repository.CommitObject(plumbing.NewHash(
 		"fbe766ffdc3f87f6affddc051c6f8b419beea6a2"))
 	deps[core.DependencyCommit] = commit
 	res, err = td.Consume(deps)
 	assert.Nil(t, err)
-	assert.Equal(t, len(res), 1)
+	assert.Equal(t, len(res), 2)
 	changes := res[DependencyTreeChanges].(object.Changes)
 	assert.Equal(t, len(changes), 1)
 	assert.Equal(t, changes[0].To.Name, "labours.py")
@@ -248,11 +292,38 @@ func TestTreeDiffFork(t *testing.T) {
 	td1.Merge([]core.PipelineItem{td2})
 }
 
+func TestTreeDiffCheckpointRestore(t *testing.T) {
+	td := fixtureTreeDiff()
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"fbe766ffdc3f87f6affddc051c6f8b419beea6a2"))
+	deps := map[string]interface{}{core.DependencyCommit: commit}
+	_, err := td.Consume(deps)
+	assert.Nil(t, err)
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, td.Checkpoint(buffer))
+	restored := fixtureTreeDiff()
+	assert.Nil(t, restored.Restore(buffer, map[string]interface{}{}))
+	assert.Equal(t, td.previousCommit, restored.previousCommit)
+	assert.Equal(t, td.previousTree.Hash, restored.previousTree.Hash)
+	commit, _ = test.Repository.CommitObject(plumbing.NewHash(
+		"2b1ed978194a94edeabbca6de7ff3b5771d4d665"))
+	deps[core.DependencyCommit] = commit
+	res, err := restored.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, len(res[DependencyTreeChanges].(object.Changes)), 12)
+
+	restored = fixtureTreeDiff()
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, restored.Checkpoint(buffer))
+	assert.Nil(t, restored.Restore(buffer, map[string]interface{}{}))
+	assert.Nil(t, restored.previousTree)
+}
+
 func TestTreeDiffCheckLanguage(t *testing.T) {
 	td := fixtureTreeDiff()
 	td.Languages["Go"] = true
-	lang, err := td.checkLanguage(
-		"version.go", plumbing.NewHash("975f35a1412b8ae79b5ba2558f71f41e707fd5a9"))
+	lang, err := td.checkLanguage("version.go", nil,
+		td.sniffer(plumbing.NewHash("975f35a1412b8ae79b5ba2558f71f41e707fd5a9")))
 	assert.Nil(t, err)
 	assert.True(t, lang)
 }
@@ -267,12 +338,12 @@ func TestTreeDiffConsumeEnryFilter(t *testing.T) {
 	}}
 	td := fixtureTreeDiff()
 
-	newDiffs := td.filterDiffs(diffs)
+	newDiffs := td.filterDiffs(diffs, nil)
 	assert.Len(t, newDiffs, 2)
 	td.Configure(map[string]interface{}{
 		ConfigTreeDiffEnableBlacklist:     true,
 		ConfigTreeDiffBlacklistedPrefixes: []string{"whatever"},
 	})
-	newDiffs = td.filterDiffs(diffs)
+	newDiffs = td.filterDiffs(diffs, nil)
 	assert.Len(t, newDiffs, 0)
 }
@@ -1,34 +1,217 @@
 package rbtree
 
-/*
-#cgo CFLAGS: -std=c99
-int LZ4_compressBound(int isize);
-int LZ4_compress_HC(const void* src, void* dst, int srcSize, int dstCapacity, int compressionLevel);
-int LZ4_decompress_fast(const void* source, void* dest, int originalSize);
-*/
-import "C"
-import "unsafe"
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// The compressed data is a raw LZ4 block of the little-endian uint32-s, the same as what
+// the previous cgo implementation on top of lz4hc.c produced on amd64, so the allocators
+// which were serialized on disk before remain readable.
+// See https://github.com/lz4/lz4/blob/master/doc/lz4_Block_format.md
+const (
+	// lz4MinMatch is the minimum length of a match.
+	lz4MinMatch = 4
+	// lz4LastLiterals is the number of the trailing bytes which must be literals.
+	lz4LastLiterals = 5
+	// lz4MFLimit is the minimum distance between the beginning of the last match and the end.
+	lz4MFLimit = 12
+	// lz4MaxDistance is the maximum match offset.
+	lz4MaxDistance = 65535
+	// lz4HashLog is the binary logarithm of the hash table size.
+	lz4HashLog = 16
+	// lz4MaxAttempts is the maximum number of the hash chain links we follow at each position.
+	// The higher, the better the compression ratio and the slower.
+	lz4MaxAttempts = 64
+)
+
+var errCorruptedLZ4 = errors.New("corrupted LZ4 block")
 
 // CompressUInt32Slice compresses a slice of uint32-s with LZ4.
 func CompressUInt32Slice(data []uint32) []byte {
-	dstSize := C.LZ4_compressBound(C.int(len(data) * 4))
-	dst := make([]byte, dstSize)
-	dstSize = C.LZ4_compress_HC(
-		unsafe.Pointer(&data[0]),
-		unsafe.Pointer(&dst[0]),
-		C.int(len(data)*4),
-		dstSize,
-		12)
-	finalDst := make([]byte, dstSize)
-	copy(finalDst, dst[:dstSize])
-	return finalDst
+	src := make([]byte, len(data)*4)
+	for i, x := range data {
+		binary.LittleEndian.PutUint32(src[i*4:], x)
+	}
+	return compressLZ4(src)
 }
 
 // DecompressUInt32Slice decompresses a slice of uint32-s previously compressed with LZ4.
 // `result` must be preallocated.
-func DecompressUInt32Slice(data []byte, result []uint32) {
-	C.LZ4_decompress_fast(
-		unsafe.Pointer(&data[0]),
-		unsafe.Pointer(&result[0]),
-		C.int(len(result)*4))
+func DecompressUInt32Slice(data []byte, result []uint32) error {
+	dst := make([]byte, len(result)*4)
+	if err := decompressLZ4(data, dst); err != nil {
+		return err
+	}
+	for i := range result {
+		result[i] = binary.LittleEndian.Uint32(dst[i*4:])
+	}
+	return nil
+}
+
+// compressLZ4 finds the longest match at each position through the hash chains and
+// postpones it if the next position has a longer one, similar to LZ4 HC.
+func compressLZ4(src []byte) []byte {
+	dst := make([]byte, 0, len(src)+len(src)/255+16)
+	anchor := 0
+	if len(src) > lz4MFLimit {
+		// heads are the last positions + 1 with the same hash, 0 means none
+		heads := make([]int32, 1<<lz4HashLog)
+		// chain links each position to the previous one with the same hash
+		chain := make([]uint16, lz4MaxDistance+1)
+		hash := func(pos int) uint32 {
+			return (binary.LittleEndian.Uint32(src[pos:]) * 2654435761) >> (32 - lz4HashLog)
+		}
+		insert := func(pos int) {
+			h := hash(pos)
+			delta := pos - int(heads[h]) + 1
+			if heads[h] == 0 || delta > lz4MaxDistance {
+				delta = 0
+			}
+			chain[pos&lz4MaxDistance] = uint16(delta)
+			heads[h] = int32(pos + 1)
+		}
+		matchLimit := len(src) - lz4LastLiterals
+		findMatch := func(pos int) (bestOffset, bestLength int) {
+			candidate := int(heads[hash(pos)]) - 1
+			for attempt := 0; attempt < lz4MaxAttempts && candidate >= 0 &&
+				pos-candidate <= lz4MaxDistance; attempt++ {
+				if src[candidate+bestLength] == src[pos+bestLength] {
+					length := 0
+					for pos+length < matchLimit && src[candidate+length] == src[pos+length] {
+						length++
+					}
+					if length > bestLength {
+						bestOffset, bestLength = pos-candidate, length
+					}
+				}
+				delta := int(chain[candidate&lz4MaxDistance])
+				if delta == 0 {
+					break
+				}
+				candidate -= delta
+			}
+			return bestOffset, bestLength
+		}
+		lastMatch := len(src) - lz4MFLimit
+		for pos := 0; pos <= lastMatch; {
+			bestOffset, bestLength := findMatch(pos)
+			insert(pos)
+			if bestLength < lz4MinMatch {
+				pos++
+				continue
+			}
+			// lazy matching: a longer match may begin at the next position
+			for pos < lastMatch {
+				offset, length := findMatch(pos + 1)
+				if length <= bestLength {
+					break
+				}
+				pos++
+				insert(pos)
+				bestOffset, bestLength = offset, length
+			}
+			dst = appendLZ4Sequence(dst, src[anchor:pos], bestOffset, bestLength)
+			end := pos + bestLength
+			for next := pos + 1; next < end && next <= lastMatch; next++ {
+				insert(next)
+			}
+			pos = end
+			anchor = pos
+		}
+	}
+	return appendLZ4Sequence(dst, src[anchor:], 0, 0)
+}
+
+// appendLZ4Sequence writes the literals followed by the match. The match is omitted
+// if `length` is 0: that is the last sequence in the block.
+func appendLZ4Sequence(dst []byte, literals []byte, offset, length int) []byte {
+	token := len(literals)
+	if token > 15 {
+		token = 15
+	}
+	token <<= 4
+	if length > 0 {
+		if length-lz4MinMatch > 15 {
+			token |= 15
+		} else {
+			token |= length - lz4MinMatch
+		}
+	}
+	dst = append(dst, byte(token))
+	dst = appendLZ4Length(dst, len(literals))
+	dst = append(dst, literals...)
+	if length == 0 {
+		return dst
+	}
+	dst = append(dst, byte(offset), byte(offset>>8))
+	return appendLZ4Length(dst, length-lz4MinMatch)
+}
+
+// appendLZ4Length writes the extra bytes of the length which does not fit into a token.
+func appendLZ4Length(dst []byte, length int) []byte {
+	if length < 15 {
+		return dst
+	}
+	for length -= 15; length >= 255; length -= 255 {
+		dst = append(dst, 255)
+	}
+	return append(dst, byte(length))
+}
+
+// decompressLZ4 decodes the LZ4 block. `dst` must have the exact size of the original data.
+func decompressLZ4(src []byte, dst []byte) error {
+	readLength := func(pos *int, length int) (int, bool) {
+		if length < 15 {
+			return length, true
+		}
+		for *pos < len(src) {
+			b := src[*pos]
+			*pos++
+			length += int(b)
+			if b != 255 {
+				return length, true
+			}
+		}
+		return 0, false
+	}
+	srcPos, dstPos := 0, 0
+	for srcPos < len(src) {
+		token := int(src[srcPos])
+		srcPos++
+		length, ok := readLength(&srcPos, token>>4)
+		if !ok || length > len(src)-srcPos || length > len(dst)-dstPos {
+			return errCorruptedLZ4
+		}
+		copy(dst[dstPos:], src[srcPos:srcPos+length])
+		srcPos += length
+		dstPos += length
+		if srcPos == len(src) {
+			break
+		}
+		if srcPos+2 > len(src) {
+			return errCorruptedLZ4
+		}
+		offset := int(src[srcPos]) | int(src[srcPos+1])<<8
+		srcPos += 2
+		length, ok = readLength(&srcPos, token&15)
+		length += lz4MinMatch
+		if !ok || offset == 0 || offset > dstPos || length > len(dst)-dstPos {
+			return errCorruptedLZ4
+		}
+		if offset >= length {
+			copy(dst[dstPos:dstPos+length], dst[dstPos-offset:])
+		} else {
+			// the match overlaps with itself, so we repeat the period
+			copied := copy(dst[dstPos:dstPos+offset], dst[dstPos-offset:dstPos])
+			for copied < length {
+				copied += copy(dst[dstPos+copied:dstPos+length], dst[dstPos:dstPos+copied])
+			}
+		}
+		dstPos += length
+	}
+	if dstPos != len(dst) {
+		return errCorruptedLZ4
+	}
+	return nil
 }
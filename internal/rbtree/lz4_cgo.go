@@ -0,0 +1,39 @@
+// +build lz4hc
+
+package rbtree
+
+/*
+#cgo CFLAGS: -std=c99
+int LZ4_compressBound(int isize);
+int LZ4_compress_HC(const void* src, void* dst, int srcSize, int dstCapacity, int compressionLevel);
+int LZ4_decompress_fast(const void* source, void* dest, int originalSize);
+*/
+import "C"
+import "unsafe"
+
+// The original cgo implementation on top of lz4hc.c is kept for the benchmarks and
+// the compatibility tests: go test -tags lz4hc -bench . ./internal/rbtree
+
+// compressUInt32SliceCgo compresses a slice of uint32-s with LZ4 HC.
+func compressUInt32SliceCgo(data []uint32) []byte {
+	dstSize := C.LZ4_compressBound(C.int(len(data) * 4))
+	dst := make([]byte, dstSize)
+	dstSize = C.LZ4_compress_HC(
+		unsafe.Pointer(&data[0]),
+		unsafe.Pointer(&dst[0]),
+		C.int(len(data)*4),
+		dstSize,
+		12)
+	finalDst := make([]byte, dstSize)
+	copy(finalDst, dst[:dstSize])
+	return finalDst
+}
+
+// decompressUInt32SliceCgo decompresses a slice of uint32-s previously compressed with LZ4.
+// `result` must be preallocated.
+func decompressUInt32SliceCgo(data []byte, result []uint32) {
+	C.LZ4_decompress_fast(
+		unsafe.Pointer(&data[0]),
+		unsafe.Pointer(&result[0]),
+		C.int(len(result)*4))
+}
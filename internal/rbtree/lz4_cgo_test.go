@@ -0,0 +1,31 @@
+// +build lz4hc
+
+package rbtree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressUInt32SliceCgoCompatibility(t *testing.T) {
+	for _, column := range fixtureBurndownColumns() {
+		result := make([]uint32, len(column))
+		packedCgo := compressUInt32SliceCgo(column)
+		assert.Nil(t, DecompressUInt32Slice(packedCgo, result))
+		assert.Equal(t, column, result)
+		packed := CompressUInt32Slice(column)
+		result = make([]uint32, len(column))
+		decompressUInt32SliceCgo(packed, result)
+		assert.Equal(t, column, result)
+		t.Logf("%d bytes: Go %d, cgo %d", len(column)*4, len(packed), len(packedCgo))
+	}
+}
+
+func BenchmarkCompressUInt32SliceCgo(b *testing.B) {
+	benchmarkCompress(b, compressUInt32SliceCgo)
+}
+
+func BenchmarkDecompressUInt32SliceCgo(b *testing.B) {
+	benchmarkDecompress(b, decompressUInt32SliceCgo)
+}
@@ -1,6 +1,7 @@
 package rbtree
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,8 +17,168 @@ func TestCompressDecompressUInt32Slice(t *testing.T) {
 	for i := range data {
 		data[i] = 0
 	}
-	DecompressUInt32Slice(packed, data)
+	assert.Nil(t, DecompressUInt32Slice(packed, data))
 	for i := range data {
 		assert.Equal(t, uint32(7), data[i], i)
 	}
 }
+
+func TestCompressDecompressUInt32SliceSizes(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, size := range []int{0, 1, 2, 3, 4, 5, 16, 100, 1000, 100000} {
+		data := make([]uint32, size)
+		for i := range data {
+			data[i] = uint32(r.Intn(8))
+		}
+		packed := CompressUInt32Slice(data)
+		result := make([]uint32, size)
+		assert.Nil(t, DecompressUInt32Slice(packed, result), size)
+		assert.Equal(t, data, result, size)
+	}
+}
+
+func TestCompressDecompressUInt32SliceBurndown(t *testing.T) {
+	for _, column := range fixtureBurndownColumns() {
+		packed := CompressUInt32Slice(column)
+		assert.True(t, len(packed) < len(column)*4)
+		result := make([]uint32, len(column))
+		assert.Nil(t, DecompressUInt32Slice(packed, result))
+		assert.Equal(t, column, result)
+	}
+}
+
+func TestDecompressUInt32SliceFormat(t *testing.T) {
+	// produced by LZ4_compress_HC() in lz4hc.c
+	packed := []byte{
+		0x4f, 0x07, 0x00, 0x00, 0x00, 0x04, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x93, 0x50, 0x00, 0x07, 0x00, 0x00, 0x00}
+	data := make([]uint32, 1000)
+	assert.Nil(t, DecompressUInt32Slice(packed, data))
+	for i := range data {
+		assert.Equal(t, uint32(7), data[i], i)
+	}
+	assert.Equal(t, packed, CompressUInt32Slice(data))
+}
+
+func TestDecompressUInt32SliceCorrupted(t *testing.T) {
+	data := make([]uint32, 1000)
+	for i := range data {
+		data[i] = uint32(i % 10)
+	}
+	packed := CompressUInt32Slice(data)
+	assert.Equal(t, errCorruptedLZ4, DecompressUInt32Slice(packed[:len(packed)/2], data))
+	assert.Equal(t, errCorruptedLZ4, DecompressUInt32Slice(packed, data[:999]))
+	assert.Equal(t, errCorruptedLZ4, DecompressUInt32Slice(packed, make([]uint32, 1001)))
+	// the offset points before the beginning
+	assert.Equal(t, errCorruptedLZ4, DecompressUInt32Slice(
+		[]byte{0x40, 0, 0, 0, 0, 0x10, 0, 0, 0, 0, 0, 0}, make([]uint32, 3)))
+	assert.Equal(t, errCorruptedLZ4, DecompressUInt32Slice([]byte{0xf0}, data))
+}
+
+// fixtureBurndownColumns returns the deinterleaved nodes of a large allocator which is shared
+// by many file trees, the same as in the burndown analysis.
+func fixtureBurndownColumns() [][]uint32 {
+	r := rand.New(rand.NewSource(7))
+	allocator := NewAllocator()
+	trees := make([]*RBTree, 1000)
+	for i := range trees {
+		trees[i] = NewRBTree(allocator)
+	}
+	for day := 0; day < 300; day++ {
+		for _, tree := range trees[:r.Intn(len(trees))] {
+			if r.Intn(3) == 0 {
+				if iter := tree.FindGE(uint32(r.Intn(2000))); !iter.Limit() {
+					tree.DeleteWithIterator(iter)
+				}
+				continue
+			}
+			tree.Insert(Item{Key: uint32(r.Intn(2000)), Value: uint32(day)})
+		}
+	}
+	columns := make([][]uint32, 6)
+	for i := range columns {
+		columns[i] = make([]uint32, len(allocator.storage))
+	}
+	for i, n := range allocator.storage {
+		columns[0][i] = n.item.Key
+		columns[1][i] = n.item.Value
+		columns[2][i] = n.left
+		columns[3][i] = n.parent
+		columns[4][i] = n.right
+		if n.color {
+			columns[5][i] = 1
+		}
+	}
+	return columns
+}
+
+func BenchmarkCompressUInt32Slice(b *testing.B) {
+	benchmarkCompress(b, CompressUInt32Slice)
+}
+
+func BenchmarkDecompressUInt32Slice(b *testing.B) {
+	benchmarkDecompress(b, func(data []byte, result []uint32) {
+		if err := DecompressUInt32Slice(data, result); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+func BenchmarkAllocatorHibernateBoot(b *testing.B) {
+	columns := fixtureBurndownColumns()
+	allocator := NewAllocator()
+	allocator.storage = make([]node, len(columns[0]))
+	for i := range allocator.storage {
+		n := &allocator.storage[i]
+		n.item.Key = columns[0][i]
+		n.item.Value = columns[1][i]
+		n.left = columns[2][i]
+		n.parent = columns[3][i]
+		n.right = columns[4][i]
+		n.color = columns[5][i] > 0
+	}
+	b.SetBytes(int64(len(allocator.storage) * 4 * len(columns)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		allocator.Hibernate()
+		allocator.Boot()
+	}
+}
+
+func benchmarkCompress(b *testing.B, compress func([]uint32) []byte) {
+	columns := fixtureBurndownColumns()
+	size, packedSize := 0, 0
+	for _, column := range columns {
+		size += len(column) * 4
+		packedSize += len(compress(column))
+	}
+	b.Logf("compressed %d bytes to %d", size, packedSize)
+	b.SetBytes(int64(size))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, column := range columns {
+			compress(column)
+		}
+	}
+}
+
+func benchmarkDecompress(b *testing.B, decompress func([]byte, []uint32)) {
+	columns := fixtureBurndownColumns()
+	packed := make([][]byte, len(columns))
+	size := 0
+	for i, column := range columns {
+		size += len(column) * 4
+		packed[i] = CompressUInt32Slice(column)
+	}
+	result := make([]uint32, len(columns[0]))
+	b.SetBytes(int64(size))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, data := range packed {
+			decompress(data, result)
+		}
+	}
+}
@@ -1,3 +1,5 @@
+// +build lz4hc
+
 /*
    LZ4 HC - High Compression Mode of LZ4
    Copyright (C) 2011-2017, Yann Collet.
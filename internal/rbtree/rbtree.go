@@ -141,7 +141,9 @@ func (allocator *Allocator) Boot() {
 	for i := 0; i < len(buffers); i++ {
 		go func(i int) {
 			buffers[i] = make([]uint32, allocator.hibernatedStorageLen)
-			DecompressUInt32Slice(allocator.hibernatedData[i], buffers[i])
+			if err := DecompressUInt32Slice(allocator.hibernatedData[i], buffers[i]); err != nil {
+				panic(err)
+			}
 			allocator.hibernatedData[i] = nil
 			wg.Done()
 		}(i)
@@ -150,7 +152,9 @@ func (allocator *Allocator) Boot() {
 		if allocator.hibernatedGapsLen > 0 {
 			gapData := allocator.hibernatedData[len(buffers)]
 			buffer := make([]uint32, allocator.hibernatedGapsLen)
-			DecompressUInt32Slice(gapData, buffer)
+			if err := DecompressUInt32Slice(gapData, buffer); err != nil {
+				panic(err)
+			}
 			for _, key := range buffer {
 				allocator.gaps[key] = true
 			}
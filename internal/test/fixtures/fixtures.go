@@ -11,3 +11,10 @@ func FileDiff() *plumbing.FileDiff {
 	fd.Initialize(test.Repository)
 	return fd
 }
+
+// LineStats initializes a new plumbing.LinesStatsCalculator item for testing.
+func LineStats() *plumbing.LinesStatsCalculator {
+	ls := &plumbing.LinesStatsCalculator{}
+	ls.Initialize(test.Repository)
+	return ls
+}
@@ -0,0 +1,71 @@
+package yaml
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ReadStringLists parses the YAML mapping from strings to lists of strings, for example,
+//
+//	backend:
+//	  - alice@example.com
+//	  - "Bob Smith"
+//	frontend: [carol@example.com, dave]
+//
+// A single scalar value is the list of one item, an empty value is the empty list.
+// The keys are returned in the order of appearance.
+func ReadStringLists(reader io.Reader) ([]string, map[string][]string, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	var mapping yaml.MapSlice
+	if err = yaml.Unmarshal(data, &mapping); err != nil {
+		return nil, nil, err
+	}
+	var keys []string
+	lists := map[string][]string{}
+	for _, item := range mapping {
+		key, ok := scalarToString(item.Key)
+		if !ok || key == "" {
+			return nil, nil, fmt.Errorf("invalid key %v", item.Key)
+		}
+		if _, exists := lists[key]; exists {
+			return nil, nil, fmt.Errorf("duplicate key %s", key)
+		}
+		keys = append(keys, key)
+		list := []string{}
+		switch value := item.Value.(type) {
+		case nil:
+		case []interface{}:
+			for _, element := range value {
+				str, ok := scalarToString(element)
+				if !ok {
+					return nil, nil, fmt.Errorf("%s: expected a list of strings", key)
+				}
+				list = append(list, str)
+			}
+		default:
+			str, ok := scalarToString(value)
+			if !ok {
+				return nil, nil, fmt.Errorf("%s: expected a list of strings", key)
+			}
+			list = append(list, str)
+		}
+		lists[key] = list
+	}
+	return keys, lists, nil
+}
+
+// scalarToString formats the decoded YAML scalar. The second returned value is false
+// if the value is a sequence, a mapping or null.
+func scalarToString(value interface{}) (string, bool) {
+	switch value.(type) {
+	case nil, []interface{}, yaml.MapSlice, map[interface{}]interface{}:
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
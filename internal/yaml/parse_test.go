@@ -0,0 +1,55 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadStringLists(t *testing.T) {
+	keys, lists, err := ReadStringLists(strings.NewReader(`---
+# teams
+backend:
+  - alice@example.com   # lead
+  - "Bob #1 Smith"
+  - 'O''Brien'
+frontend: [carol@example.com, "dave, jr", 'eve']
+"qa: manual": frank
+ops:
+    - 42
+    - \bCVE-\d+
+empty:
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"backend", "frontend", "qa: manual", "ops", "empty"}, keys)
+	assert.Equal(t, map[string][]string{
+		"backend":    {"alice@example.com", "Bob #1 Smith", "O'Brien"},
+		"frontend":   {"carol@example.com", "dave, jr", "eve"},
+		"qa: manual": {"frank"},
+		"ops":        {"42", `\bCVE-\d+`},
+		"empty":      {},
+	}, lists)
+	keys, lists, err = ReadStringLists(strings.NewReader(""))
+	assert.Nil(t, err)
+	assert.Len(t, keys, 0)
+	assert.Len(t, lists, 0)
+}
+
+func TestReadStringListsErrors(t *testing.T) {
+	for _, text := range []string{
+		"- alice\n",
+		"backend\n",
+		"backend:\n  - alice\n - bob\n",
+		"backend: [alice\n",
+		"backend: \"alice\n",
+		"backend: []\nbackend: []\n",
+		": [alice]\n",
+		"backend: [[alice]]\n",
+		"backend:\n  lead: alice\n",
+		"backend: [~]\n",
+	} {
+		_, _, err := ReadStringLists(strings.NewReader(text))
+		assert.NotNil(t, err, text)
+	}
+}
@@ -81,10 +81,17 @@ def parse_args():
                         help="Do not run Tensorflow Projector on couples.")
     parser.add_argument("--max-people", default=20, type=int,
                         help="Maximum number of developers in churn matrix and people plots.")
+    parser.add_argument("--exclude-bots", action="store_true",
+                        help="Hide the developers which hercules detected as bots (--bots=flag or "
+                             "--bots=merge) in the people plots.")
     args = parser.parse_args()
     return args
 
 
+# the duration of the time tick in seconds in the files which do not record it
+DEFAULT_TICK_SIZE = 24 * 3600
+
+
 class Reader(object):
     def read(self, file):
         raise NotImplementedError
@@ -95,6 +102,12 @@ class Reader(object):
     def get_header(self):
         raise NotImplementedError
 
+    def get_tick_size(self):
+        raise NotImplementedError
+
+    def get_bots(self):
+        raise NotImplementedError
+
     def get_burndown_parameters(self):
         raise NotImplementedError
 
@@ -165,9 +178,15 @@ class YamlReader(Reader):
         header = self.data["hercules"]
         return header["begin_unix_time"], header["end_unix_time"]
 
+    def get_tick_size(self):
+        return self.data["hercules"].get("tick_size") or DEFAULT_TICK_SIZE
+
+    def get_bots(self):
+        return set(self.data["hercules"].get("bots") or [])
+
     def get_burndown_parameters(self):
         header = self.data["Burndown"]
-        return header["sampling"], header["granularity"]
+        return header["sampling"], header["granularity"], self.get_tick_size()
 
     def get_project_burndown(self):
         return self.data["hercules"]["repository"], \
@@ -236,8 +255,7 @@ class YamlReader(Reader):
 
     def get_devs(self):
         people = self.data["Devs"]["people"]
-        days = {int(d): {int(dev): DevDay(*(int(x) for x in day[:-1]), day[-1])
-                         for dev, day in devs.items()}
+        days = {int(d): {int(dev): DevDay.parse_yaml(day) for dev, day in devs.items()}
                 for d, devs in self.data["Devs"]["days"].items()}
         return days, people
 
@@ -296,9 +314,15 @@ class ProtobufReader(Reader):
         header = self.data.header
         return header.begin_unix_time, header.end_unix_time
 
+    def get_tick_size(self):
+        return self.data.header.tick_size or DEFAULT_TICK_SIZE
+
+    def get_bots(self):
+        return set(self.data.header.bots)
+
     def get_burndown_parameters(self):
         burndown = self.contents["Burndown"]
-        return burndown.sampling, burndown.granularity
+        return burndown.sampling, burndown.granularity, self.get_tick_size()
 
     def get_project_burndown(self):
         return self._parse_burndown_matrix(self.contents["Burndown"].project)
@@ -359,8 +383,9 @@ class ProtobufReader(Reader):
     def get_devs(self):
         people = list(self.contents["Devs"].dev_index)
         days = {d: {dev: DevDay(stats.commits, stats.stats.added, stats.stats.removed,
-                                stats.stats.changed, {k: [v.added, v.removed, v.changed]
-                                                      for k, v in stats.languages.items()})
+                                stats.stats.changed, stats.stats.moved,
+                                {k: [v.added, v.removed, v.changed, v.moved]
+                                 for k, v in stats.languages.items()})
                     for dev, stats in day.devs.items()}
                 for d, day in self.contents["Devs"].days.items()}
         return days, people
@@ -409,22 +434,75 @@ def read_input(args):
     return reader
 
 
-class DevDay(namedtuple("DevDay", ("Commits", "Added", "Removed", "Changed", "Languages"))):
+class DevDay(namedtuple("DevDay", ("Commits", "Added", "Removed", "Changed", "Moved",
+                                   "Languages"))):
+    @staticmethod
+    def parse_yaml(day):
+        """
+        Parses [commits, added, removed, changed, moved, {language: [added, removed, changed,
+        moved]}]. The files written without --detect-moves support lack the moved lines.
+        """
+        stats = [int(x) for x in day[:-1]] + [0] * (6 - len(day))
+        langs = {k: list(v) + [0] * (4 - len(v)) for k, v in day[-1].items()}
+        return DevDay(*stats, langs)
+
     def add(self, dd):
-        langs = defaultdict(lambda: [0] * 3)
+        langs = defaultdict(lambda: [0] * 4)
         for key, val in self.Languages.items():
-            for i in range(3):
+            for i in range(4):
                 langs[key][i] += val[i]
         for key, val in dd.Languages.items():
-            for i in range(3):
+            for i in range(4):
                 langs[key][i] += val[i]
         return DevDay(Commits=self.Commits + dd.Commits,
                       Added=self.Added + dd.Added,
                       Removed=self.Removed + dd.Removed,
                       Changed=self.Changed + dd.Changed,
+                      Moved=self.Moved + dd.Moved,
                       Languages=dict(langs))
 
 
+def devs_ticks_to_days(data, tick_size):
+    """
+    Converts the tick indexes in the Devs analysis results to day indexes.
+    """
+    if tick_size == DEFAULT_TICK_SIZE:
+        return data
+    ticks, people = data
+    days = defaultdict(dict)
+    for tick, devs in ticks.items():
+        day = tick * tick_size // DEFAULT_TICK_SIZE
+        for dev, stats in devs.items():
+            if dev in days[day]:
+                days[day][dev] = days[day][dev].add(stats)
+            else:
+                days[day][dev] = stats
+    return dict(days), people
+
+
+def exclude_bots_from_people(bots, people):
+    """
+    Returns the indexes of the people who are not bots.
+    """
+    return [i for i, name in enumerate(people) if name not in bots]
+
+
+def exclude_bots_from_devs(bots, data):
+    """
+    Removes the bots from the Devs analysis results. The developer indexes stay the same.
+    """
+    days, people = data
+    ids = set(range(len(people))) - set(exclude_bots_from_people(bots, people))
+    if not ids:
+        return data
+    result = {}
+    for day, devs in days.items():
+        devs = {dev: stats for dev, stats in devs.items() if dev not in ids}
+        if devs:
+            result[day] = devs
+    return result, people
+
+
 def calculate_average_lifetime(matrix):
     lifetimes = numpy.zeros(matrix.shape[1] - 1)
     for band in matrix:
@@ -579,20 +657,21 @@ def import_pandas():
 def load_burndown(header, name, matrix, resample):
     pandas = import_pandas()
 
-    start, last, sampling, granularity = header
+    start, last, sampling, granularity, tick_size = header
     assert sampling > 0
     assert granularity >= sampling
     start = datetime.fromtimestamp(start)
     last = datetime.fromtimestamp(last)
+    tick = timedelta(seconds=tick_size)
     print(name, "lifetime index:", calculate_average_lifetime(matrix))
-    finish = start + timedelta(days=matrix.shape[1] * sampling)
+    finish = start + tick * (matrix.shape[1] * sampling)
     if resample not in ("no", "raw"):
         print("resampling to %s, please wait..." % resample)
-        # Interpolate the day x day matrix.
-        # Each day brings equal weight in the granularity.
+        # Interpolate the tick x tick matrix.
+        # Each tick brings equal weight in the granularity.
         # Sampling's interpolation is linear.
         daily = interpolate_burndown_matrix(matrix, granularity, sampling)
-        daily[(last - start).days:] = 0
+        daily[(last - start) // tick:] = 0
         # Resample the bands
         aliases = {
             "year": "A",
@@ -609,20 +688,23 @@ def load_burndown(header, name, matrix, resample):
             date_granularity_sampling[0],
             periods=(finish - date_granularity_sampling[0]).days,
             freq="1D")
+        # The tick of each day
+        ticks = numpy.minimum(
+            [(sdt - start) // tick for sdt in date_range_sampling], daily.shape[1] - 1)
         # Fill the new square matrix
         matrix = numpy.zeros(
             (len(date_granularity_sampling), len(date_range_sampling)),
             dtype=numpy.float32)
         for i, gdt in enumerate(date_granularity_sampling):
-            istart = (date_granularity_sampling[i - 1] - start).days \
+            istart = (date_granularity_sampling[i - 1] - start) // tick \
                 if i > 0 else 0
-            ifinish = (gdt - start).days
+            ifinish = (gdt - start) // tick
 
             for j, sdt in enumerate(date_range_sampling):
-                if (sdt - start).days >= istart:
+                if (sdt - start) // tick >= istart:
                     break
             matrix[i, j:] = \
-                daily[istart:ifinish, (sdt - start).days:].sum(axis=0)
+                daily[istart:ifinish][:, ticks[j:]].sum(axis=0)
         # Hardcode some cases to improve labels' readability
         if resample in ("year", "A"):
             labels = [dt.year for dt in date_granularity_sampling]
@@ -632,23 +714,23 @@ def load_burndown(header, name, matrix, resample):
             labels = [dt.date() for dt in date_granularity_sampling]
     else:
         labels = [
-            "%s - %s" % ((start + timedelta(days=i * granularity)).date(),
+            "%s - %s" % ((start + tick * (i * granularity)).date(),
                          (
-                         start + timedelta(days=(i + 1) * granularity)).date())
+                         start + tick * ((i + 1) * granularity)).date())
             for i in range(matrix.shape[0])]
         if len(labels) > 18:
             warnings.warn("Too many labels - consider resampling.")
         resample = "M"  # fake resampling type is checked while plotting
         date_range_sampling = pandas.date_range(
-            start + timedelta(days=sampling), periods=matrix.shape[1],
-            freq="%dD" % sampling)
+            start + tick * sampling, periods=matrix.shape[1],
+            freq="%ds" % (tick_size * sampling))
     return name, matrix, date_range_sampling, labels, granularity, sampling, resample
 
 
 def load_ownership(header, sequence, contents, max_people):
     pandas = import_pandas()
 
-    start, last, sampling, _ = header
+    start, last, sampling, _, tick_size = header
     start = datetime.fromtimestamp(start)
     last = datetime.fromtimestamp(last)
     people = []
@@ -656,8 +738,8 @@ def load_ownership(header, sequence, contents, max_people):
         people.append(contents[name].sum(axis=1))
     people = numpy.array(people)
     date_range_sampling = pandas.date_range(
-        start + timedelta(days=sampling), periods=people[0].shape[0],
-        freq="%dD" % sampling)
+        start + timedelta(seconds=sampling * tick_size), periods=people[0].shape[0],
+        freq="%ds" % (sampling * tick_size))
 
     if people.shape[0] > max_people:
         order = numpy.argsort(-people.sum(axis=1))
@@ -1164,12 +1246,12 @@ def show_shotness_stats(data):
         print("%8d  %s:%s [%s]" % (count, r.file, r.name, r.internal_role))
 
 
-def show_sentiment_stats(args, name, resample, start_date, data):
+def show_sentiment_stats(args, name, resample, start_date, tick_size, data):
     matplotlib, pyplot = import_pyplot(args.backend, args.style)
 
     start_date = datetime.fromtimestamp(start_date)
     data = sorted(data.items())
-    xdates = [start_date + timedelta(days=d[0]) for d in data]
+    xdates = [start_date + timedelta(seconds=d[0] * tick_size) for d in data]
     xpos = []
     ypos = []
     xneg = []
@@ -1264,7 +1346,7 @@ def show_devs(args, name, start_date, end_date, data):
     else:
         chosen_people = set(people)
     devseries = defaultdict(list)
-    devstats = defaultdict(lambda: DevDay(0, 0, 0, 0, {}))
+    devstats = defaultdict(lambda: DevDay(0, 0, 0, 0, 0, {}))
     for day, devs in sorted(days.items()):
         for dev, stats in devs.items():
             if people[dev] in chosen_people:
@@ -1438,7 +1520,8 @@ def show_languages(args, name, start_date, end_date, data):
     for day, devs in days.items():
         for dev, stats in devs.items():
             for lang, vals in stats.Languages.items():
-                devlangs[dev][lang] += vals
+                # the moved lines are not counted
+                devlangs[dev][lang] += vals[:3]
     devlangs = sorted(devlangs.items(), key=lambda p: -sum(x.sum() for x in p[1].values()))
     for dev, ls in devlangs:
         print()
@@ -1482,6 +1565,7 @@ def main():
     reader = read_input(args)
     header = reader.get_header()
     name = reader.get_name()
+    bots = reader.get_bots() if args.exclude_bots else set()
 
     burndown_warning = "Burndown stats were not collected. Re-run hercules with --burndown."
     burndown_files_warning = \
@@ -1532,16 +1616,22 @@ def main():
             print(burndown_warning)
             return
         try:
-            plot_many_burndown(args, "person", full_header, reader.get_people_burndown())
+            plot_many_burndown(args, "person", full_header,
+                               [p for p in reader.get_people_burndown() if p[0] not in bots])
         except KeyError:
             print("people: " + burndown_people_warning)
 
     def churn_matrix():
         try:
-            plot_churn_matrix(args, name, *load_churn_matrix(
-                *reader.get_people_interaction(), max_people=args.max_people))
+            people, matrix = reader.get_people_interaction()
         except KeyError:
             print("churn_matrix: " + burndown_people_warning)
+            return
+        keep = exclude_bots_from_people(bots, people)
+        people = [people[i] for i in keep]
+        matrix = matrix[keep][:, [0, 1] + [2 + i for i in keep]]
+        plot_churn_matrix(args, name, *load_churn_matrix(
+            people, matrix, max_people=args.max_people))
 
     def ownership_burndown():
         try:
@@ -1550,18 +1640,23 @@ def main():
             print(burndown_warning)
             return
         try:
-            plot_ownership(args, name, *load_ownership(
-                full_header, *reader.get_ownership_burndown(), max_people=args.max_people))
+            sequence, contents = reader.get_ownership_burndown()
         except KeyError:
             print("ownership: " + burndown_people_warning)
+            return
+        sequence = [p for p in sequence if p not in bots]
+        plot_ownership(args, name, *load_ownership(
+            full_header, sequence, contents, max_people=args.max_people))
 
     def couples():
         try:
             write_embeddings("files", args.output, not args.disable_projector,
                              *train_embeddings(*reader.get_files_coocc(),
                                                tmpdir=args.couples_tmp_dir))
+            index, matrix = reader.get_people_coocc()
+            keep = exclude_bots_from_people(bots, index)
             write_embeddings("people", args.output, not args.disable_projector,
-                             *train_embeddings(*reader.get_people_coocc(),
+                             *train_embeddings([index[i] for i in keep], matrix[keep][:, keep],
                                                tmpdir=args.couples_tmp_dir))
         except KeyError:
             print(couples_warning)
@@ -1586,11 +1681,13 @@ def main():
         except KeyError:
             print(sentiment_warning)
             return
-        show_sentiment_stats(args, reader.get_name(), args.resample, reader.get_header()[0], data)
+        show_sentiment_stats(args, reader.get_name(), args.resample, reader.get_header()[0],
+                             reader.get_tick_size(), data)
 
     def devs():
         try:
-            data = reader.get_devs()
+            data = exclude_bots_from_devs(
+                bots, devs_ticks_to_days(reader.get_devs(), reader.get_tick_size()))
         except KeyError:
             print(devs_warning)
             return
@@ -1598,7 +1695,8 @@ def main():
 
     def old_vs_new():
         try:
-            data = reader.get_devs()
+            data = exclude_bots_from_devs(
+                bots, devs_ticks_to_days(reader.get_devs(), reader.get_tick_size()))
         except KeyError:
             print(devs_warning)
             return
@@ -1606,7 +1704,8 @@ def main():
 
     def languages():
         try:
-            data = reader.get_devs()
+            data = exclude_bots_from_devs(
+                bots, devs_ticks_to_days(reader.get_devs(), reader.get_tick_size()))
         except KeyError:
             print(devs_warning)
             return
@@ -0,0 +1,286 @@
+package leaves
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/burndown"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	items "gopkg.in/src-d/hercules.v8/internal/plumbing"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing/identity"
+)
+
+// BlameAnalysis attributes each line of a single file at the last commit to the commit
+// which introduced it, like `git blame`. The lines are tracked by the internal BurndownAnalysis
+// which stores the commit indexes instead of the developer indexes, so the renames are followed
+// the same way as in the burndown analysis.
+// BlameAnalysis is not registered in core.Registry because it requires the path of the file,
+// `hercules blame` deploys it explicitly.
+type BlameAnalysis struct {
+	// Path is the path of the blamed file at the last commit.
+	Path string
+
+	// burndown tracks the commit indexes of the lines in each file.
+	burndown *BurndownAnalysis
+	// origins maps the commit indexes to the commits and is shared among the forks.
+	origins *blameOrigins
+	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+}
+
+// blameOrigin is the commit which introduced a line.
+type blameOrigin struct {
+	commit *object.Commit
+	author int
+}
+
+// blameOrigins is the thread safe mapping from the commit indexes to blameOrigin-s.
+type blameOrigins struct {
+	sync.Mutex
+	commits map[int]blameOrigin
+}
+
+// BlameLine describes a single line in BlameResult.
+type BlameLine struct {
+	// Commit is the commit which introduced the line. It is nil if the origin is unknown.
+	Commit *object.Commit
+	// Author is the index of the developer in the people dictionary or identity.AuthorMissing.
+	Author int
+	// Day is the day of the commit since the beginning of the analysed history.
+	Day int
+}
+
+// BlameResult is returned by BlameAnalysis.Finalize() and carries the origins of the lines.
+type BlameResult struct {
+	// Path is the same as BlameAnalysis.Path.
+	Path string
+	// Lines are the origins of the file's lines in the natural order. Lines is nil if the file
+	// does not exist at the last commit or it is binary.
+	Lines []BlameLine
+
+	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+}
+
+const (
+	// ConfigBlamePath is the name of the option to set BlameAnalysis.Path.
+	ConfigBlamePath = "Blame.Path"
+)
+
+// Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
+func (blame *BlameAnalysis) Name() string {
+	return "Blame"
+}
+
+// Provides returns the list of names of entities which are produced by this PipelineItem.
+// Each produced entity will be inserted into `deps` of dependent Consume()-s according
+// to this list. Also used by core.Registry to build the global map of providers.
+func (blame *BlameAnalysis) Provides() []string {
+	return []string{}
+}
+
+// Requires returns the list of names of entities which are needed by this PipelineItem.
+// Each requested entity will be inserted into `deps` of Consume(). In turn, those
+// entities are Provides() upstream. They are the same as BurndownAnalysis.Requires().
+func (blame *BlameAnalysis) Requires() []string {
+	arr := [...]string{
+		items.DependencyFileDiff, items.DependencyTreeChanges, items.DependencyBlobCache,
+		items.DependencyDay, identity.DependencyAuthor, items.DependencyBaseTreeChanges,
+		identity.DependencyAuthors, items.DependencyCopies, items.DependencyIgnoredCommit,
+		items.DependencyMovedBlocks}
+	return arr[:]
+}
+
+// ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
+func (blame *BlameAnalysis) ListConfigurationOptions() []core.ConfigurationOption {
+	options := [...]core.ConfigurationOption{{
+		Name:        ConfigBlamePath,
+		Description: "Path of the blamed file at the last commit.",
+		Flag:        "blame-path",
+		Type:        core.StringConfigurationOption,
+		Default:     ""},
+	}
+	return options[:]
+}
+
+// Configure sets the properties previously published by ListConfigurationOptions().
+// The options of BurndownAnalysis are applied to the line tracking, except that the lines
+// carry the commit indexes instead of the developers and the per-file histories are not collected.
+func (blame *BlameAnalysis) Configure(facts map[string]interface{}) error {
+	if blame.burndown == nil {
+		blame.burndown = &BurndownAnalysis{}
+	}
+	// the internal line tracking must not replace FactBurndownAnalysis
+	burndownFacts := make(map[string]interface{}, len(facts))
+	for key, val := range facts {
+		burndownFacts[key] = val
+	}
+	if err := blame.burndown.Configure(burndownFacts); err != nil {
+		return err
+	}
+	if val, exists := facts[ConfigBlamePath].(string); exists {
+		blame.Path = val
+	}
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		blame.reversedPeopleDict = val
+	}
+	blame.burndown.PeopleNumber = 0
+	blame.burndown.TrackFiles = false
+	blame.burndown.teams = nil
+	return nil
+}
+
+// Flag for the command line switch which enables this analysis.
+func (blame *BlameAnalysis) Flag() string {
+	return "blame"
+}
+
+// Description returns the text which explains what the analysis is doing.
+func (blame *BlameAnalysis) Description() string {
+	return "Finds the commits which introduced the lines of a file, following the renames."
+}
+
+// Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
+// calls. The repository which is going to be analysed is supplied as an argument.
+func (blame *BlameAnalysis) Initialize(repository *git.Repository) error {
+	if blame.Path == "" {
+		return errors.New("the path of the blamed file is not set")
+	}
+	if blame.burndown == nil {
+		blame.burndown = &BurndownAnalysis{}
+	}
+	// the bands are not used, the values prevent the warnings
+	if blame.burndown.Granularity <= 0 {
+		blame.burndown.Granularity = DefaultBurndownGranularity
+	}
+	if blame.burndown.Sampling <= 0 {
+		blame.burndown.Sampling = blame.burndown.Granularity
+	}
+	blame.burndown.PeopleNumber = 0
+	blame.burndown.trackAuthors = true
+	blame.origins = &blameOrigins{commits: map[int]blameOrigin{}}
+	return blame.burndown.Initialize(repository)
+}
+
+// Consume runs this PipelineItem on the next commit's data.
+// `deps` contain all the results from upstream PipelineItem-s as requested by Requires().
+// Additionally, DependencyCommit is always present there and represents the analysed *object.Commit.
+// This function returns the mapping with analysis results. The keys must be the same as
+// in Provides(). If there was an error, nil is returned.
+func (blame *BlameAnalysis) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	index := deps[core.DependencyIndex].(int)
+	if index >= authorSelf {
+		return nil, fmt.Errorf("too many commits to blame: %d", index)
+	}
+	blame.origins.Lock()
+	blame.origins.commits[index] = blameOrigin{
+		commit: deps[core.DependencyCommit].(*object.Commit),
+		author: deps[identity.DependencyAuthor].(int),
+	}
+	blame.origins.Unlock()
+	// the line values store the commit indexes instead of the authors
+	burndownDeps := make(map[string]interface{}, len(deps))
+	for key, val := range deps {
+		burndownDeps[key] = val
+	}
+	burndownDeps[identity.DependencyAuthor] = index
+	// the co-authors are not tracked, every line belongs to a single commit
+	delete(burndownDeps, identity.DependencyAuthors)
+	_, err := blame.burndown.Consume(burndownDeps)
+	return nil, err
+}
+
+// Fork clones this item. The tracked lines are copied the same way as in BurndownAnalysis.Fork().
+func (blame *BlameAnalysis) Fork(n int) []core.PipelineItem {
+	burndowns := blame.burndown.Fork(n)
+	result := make([]core.PipelineItem, n)
+	for i := range result {
+		clone := *blame
+		clone.burndown = burndowns[i].(*BurndownAnalysis)
+		result[i] = &clone
+	}
+	return result
+}
+
+// Merge combines several items together. The lines are merged by BurndownAnalysis.Merge().
+func (blame *BlameAnalysis) Merge(branches []core.PipelineItem) {
+	burndowns := make([]core.PipelineItem, len(branches))
+	for i, branch := range branches {
+		burndowns[i] = branch.(*BlameAnalysis).burndown
+	}
+	blame.burndown.Merge(burndowns)
+}
+
+// Hibernate compresses the bound RBTree memory with the files.
+func (blame *BlameAnalysis) Hibernate() error {
+	return blame.burndown.Hibernate()
+}
+
+// Boot decompresses the bound RBTree memory with the files.
+func (blame *BlameAnalysis) Boot() error {
+	return blame.burndown.Boot()
+}
+
+// Finalize returns the result of the analysis. Further Consume() calls are not expected.
+func (blame *BlameAnalysis) Finalize() interface{} {
+	result := BlameResult{Path: blame.Path, reversedPeopleDict: blame.reversedPeopleDict}
+	file, exists := blame.burndown.files[blame.Path]
+	if !exists {
+		return result
+	}
+	result.Lines = make([]BlameLine, 0, file.Len())
+	previousLine := 0
+	previousValue := burndown.TreeEnd
+	file.ForEach(func(line, value int) {
+		for i := previousLine; i < line; i++ {
+			result.Lines = append(result.Lines, blame.newLine(previousValue))
+		}
+		previousLine = line
+		previousValue = value
+	})
+	return result
+}
+
+// newLine converts the value stored in burndown.File to BlameLine.
+func (blame *BlameAnalysis) newLine(value int) BlameLine {
+	index, day := blame.burndown.unpackPersonWithDay(value)
+	origin, exists := blame.origins.commits[index]
+	if !exists {
+		return BlameLine{Author: identity.AuthorMissing, Day: day}
+	}
+	return BlameLine{Commit: origin.commit, Author: origin.author, Day: day}
+}
+
+// Serialize converts the analysis result as returned by Finalize() to text.
+// The Protocol Buffers format is not supported.
+func (blame *BlameAnalysis) Serialize(result interface{}, binary bool, writer io.Writer) error {
+	blameResult, ok := result.(BlameResult)
+	if !ok {
+		return fmt.Errorf("result is not a blame result: '%v'", result)
+	}
+	if binary {
+		return errors.New("blame does not support the Protocol Buffers output")
+	}
+	fmt.Fprintf(writer, "  path: %q\n", blameResult.Path)
+	fmt.Fprintln(writer, "  lines:")
+	for _, line := range blameResult.Lines {
+		hash := ""
+		if line.Commit != nil {
+			hash = line.Commit.Hash.String()
+		}
+		author := line.Author
+		if author == identity.AuthorMissing {
+			author = -1
+		}
+		fmt.Fprintf(writer, "    - {commit: \"%s\", author: %d, day: %d}\n", hash, author, line.Day)
+	}
+	fmt.Fprintln(writer, "  people:")
+	for _, person := range blameResult.reversedPeopleDict {
+		fmt.Fprintf(writer, "  - %q\n", person)
+	}
+	return nil
+}
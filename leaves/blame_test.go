@@ -0,0 +1,271 @@
+package leaves
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	items "gopkg.in/src-d/hercules.v8/internal/plumbing"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+	"gopkg.in/src-d/hercules.v8/internal/test/fixtures"
+)
+
+func fixtureBlame(path string) *BlameAnalysis {
+	blame := BlameAnalysis{}
+	blame.Configure(map[string]interface{}{
+		ConfigBlamePath: path,
+		identity.FactIdentityDetectorReversedPeopleDict: []string{"one@srcd", "two@srcd"},
+	})
+	blame.Initialize(test.Repository)
+	return &blame
+}
+
+func TestBlameMeta(t *testing.T) {
+	blame := fixtureBlame("burndown.go")
+	assert.Equal(t, blame.Name(), "Blame")
+	assert.Len(t, blame.Provides(), 0)
+	assert.Equal(t, (&BurndownAnalysis{}).Requires(), blame.Requires())
+	opts := blame.ListConfigurationOptions()
+	assert.Len(t, opts, 1)
+	assert.Equal(t, opts[0].Name, ConfigBlamePath)
+	assert.Equal(t, blame.Flag(), "blame")
+	assert.NotEqual(t, blame.Description(), "")
+	assert.Equal(t, "burndown.go", blame.Path)
+	assert.True(t, blame.burndown.trackAuthors)
+	assert.Equal(t, 0, blame.burndown.PeopleNumber)
+	// blame requires the path and is deployed explicitly
+	assert.Len(t, core.Registry.Summon(blame.Name()), 0)
+}
+
+func TestBlameConfigureInitialize(t *testing.T) {
+	blame := BlameAnalysis{}
+	facts := map[string]interface{}{}
+	facts[ConfigBurndownHibernationThreshold] = 100
+	facts[ConfigBurndownHibernationToDisk] = true
+	facts[ConfigBurndownHibernationDirectory] = "xxx"
+	assert.Nil(t, blame.Configure(facts))
+	assert.Equal(t, 100, blame.burndown.HibernationThreshold)
+	assert.True(t, blame.burndown.HibernationToDisk)
+	assert.Equal(t, "xxx", blame.burndown.HibernationDirectory)
+	assert.NotNil(t, blame.Initialize(test.Repository))
+	facts[ConfigBlamePath] = "analyser.go"
+	facts[ConfigBurndownGranularity] = 7
+	facts[ConfigBurndownSampling] = 7
+	facts[ConfigBurndownTrackFiles] = true
+	facts[ConfigBurndownTrackPeople] = true
+	facts[ConfigBurndownDebug] = true
+	facts[identity.FactIdentityDetectorPeopleCount] = 2
+	facts[identity.FactIdentityDetectorReversedPeopleDict] = []string{"one", "two"}
+	assert.Nil(t, blame.Configure(facts))
+	assert.True(t, blame.burndown.Debug)
+	// the lines carry the commit indexes
+	assert.Equal(t, 0, blame.burndown.PeopleNumber)
+	assert.False(t, blame.burndown.TrackFiles)
+	assert.Nil(t, blame.Initialize(test.Repository))
+	assert.Equal(t, 7, blame.burndown.Granularity)
+	assert.Equal(t, 7, blame.burndown.Sampling)
+}
+
+// TestBlameConsumeMovedIgnored checks that the moved lines and the lines changed
+// by the ignored commits point to the commits which introduced them.
+func TestBlameConsumeMovedIgnored(t *testing.T) {
+	cache := map[plumbing.Hash]*items.CachedBlob{}
+	entry := func(name, text string) object.ChangeEntry {
+		blob := &items.CachedBlob{Data: []byte(text)}
+		blob.Hash = plumbing.ComputeHash(plumbing.BlobObject, blob.Data)
+		cache[blob.Hash] = blob
+		return object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+			Name: name, Mode: 0100644, Hash: blob.Hash}}
+	}
+	alpha := "func Alpha(x int) int {\n\ty := x * 2\n\tz := y - 1\n\treturn z + 1\n}\n"
+	aOld := entry("a.go", "package a\n\n"+alpha)
+	aNew := entry("a.go", "package a\n")
+	b := entry("b.go", "package b\n\n"+alpha)
+	bFormatted := entry("b.go", "package b\n\n"+strings.Replace(alpha, "\t", "    ", -1))
+	commits := make([]*object.Commit, 3)
+	for i, hash := range []string{
+		"cce947b98a050c6d356bc6ba95030254914027b1",
+		"2b1ed978194a94edeabbca6de7ff3b5771d4d665",
+		"a3ee37f91f0d705ec9c41ae88426f0ae44b2fbc3"} {
+		commits[i], _ = test.Repository.CommitObject(plumbing.NewHash(hash))
+		assert.NotNil(t, commits[i])
+	}
+
+	for _, enabled := range []bool{true, false} {
+		facts := map[string]interface{}{
+			ConfigBlamePath:                  "b.go",
+			items.ConfigMoveDetectionEnabled: enabled,
+		}
+		blame := &BlameAnalysis{}
+		assert.Nil(t, blame.Configure(facts))
+		assert.Nil(t, blame.Initialize(test.Repository))
+		moves := &items.MoveDetection{}
+		assert.Nil(t, moves.Configure(facts))
+		assert.Nil(t, moves.Initialize(test.Repository))
+		deps := map[string]interface{}{}
+		deps[core.DependencyIsMerge] = false
+		deps[items.DependencyBlobCache] = cache
+		consume := func(index, day int, ignored bool, changes ...*object.Change) {
+			deps[core.DependencyIndex] = index
+			deps[core.DependencyCommit] = commits[index]
+			deps[identity.DependencyAuthor] = index
+			deps[items.DependencyDay] = day
+			deps[items.DependencyIgnoredCommit] = ignored && enabled
+			deps[items.DependencyTreeChanges] = object.Changes(changes)
+			result, err := fixtures.FileDiff().Consume(deps)
+			assert.Nil(t, err)
+			deps[items.DependencyFileDiff] = result[items.DependencyFileDiff]
+			result, err = moves.Consume(deps)
+			assert.Nil(t, err)
+			deps[items.DependencyMovedBlocks] = result[items.DependencyMovedBlocks]
+			_, err = blame.Consume(deps)
+			assert.Nil(t, err)
+		}
+		consume(0, 0, false, &object.Change{To: aOld})
+		consume(1, 10, false, &object.Change{From: aOld, To: aNew}, &object.Change{To: b})
+		consume(2, 20, true, &object.Change{From: b, To: bFormatted})
+		counts := map[*object.Commit]int{}
+		for _, line := range blame.Finalize().(BlameResult).Lines {
+			counts[line.Commit]++
+		}
+		if enabled {
+			assert.Equal(t, map[*object.Commit]int{commits[0]: 5, commits[1]: 2}, counts)
+		} else {
+			assert.Equal(t, map[*object.Commit]int{commits[1]: 4, commits[2]: 3}, counts)
+		}
+	}
+}
+
+// consumeBlame inserts analyser.go and cmd/hercules/main.go in the first commit and then
+// renames and edits analyser.go to burndown.go in the second commit.
+func consumeBlame(t *testing.T, blame *BlameAnalysis) (*object.Commit, *object.Commit) {
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 0
+	deps[items.DependencyDay] = 0
+	deps[core.DependencyIndex] = 0
+	deps[core.DependencyIsMerge] = false
+	cache := map[plumbing.Hash]*items.CachedBlob{}
+	AddHash(t, cache, "baa64828831d174f40140e4b3cfa77d1e917a2c1")
+	AddHash(t, cache, "c29112dbd697ad9b401333b80c18a63951bc18d9")
+	AddHash(t, cache, "29c9fafd6a2fae8cd20298c3f60115bc31a4c0f2")
+	deps[items.DependencyBlobCache] = cache
+	deps[items.DependencyTreeChanges] = object.Changes{
+		ownershipInsertion("analyser.go", "baa64828831d174f40140e4b3cfa77d1e917a2c1"),
+		ownershipInsertion("cmd/hercules/main.go", "c29112dbd697ad9b401333b80c18a63951bc18d9"),
+	}
+	fd := fixtures.FileDiff()
+	result, err := fd.Consume(deps)
+	assert.Nil(t, err)
+	deps[items.DependencyFileDiff] = result[items.DependencyFileDiff]
+	commit1, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	deps[core.DependencyCommit] = commit1
+	result, err = blame.Consume(deps)
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+
+	deps[identity.DependencyAuthor] = 1
+	deps[items.DependencyDay] = 30
+	deps[core.DependencyIndex] = 1
+	rename := ownershipInsertion("burndown.go", "29c9fafd6a2fae8cd20298c3f60115bc31a4c0f2")
+	rename.From = ownershipInsertion(
+		"analyser.go", "baa64828831d174f40140e4b3cfa77d1e917a2c1").To
+	deps[items.DependencyTreeChanges] = object.Changes{rename}
+	result, err = fd.Consume(deps)
+	assert.Nil(t, err)
+	deps[items.DependencyFileDiff] = result[items.DependencyFileDiff]
+	commit2, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"2b1ed978194a94edeabbca6de7ff3b5771d4d665"))
+	deps[core.DependencyCommit] = commit2
+	result, err = blame.Consume(deps)
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+	return commit1, commit2
+}
+
+func TestBlameConsumeFinalize(t *testing.T) {
+	blame := fixtureBlame("burndown.go")
+	commit1, commit2 := consumeBlame(t, blame)
+	result := blame.Finalize().(BlameResult)
+	assert.Equal(t, "burndown.go", result.Path)
+	assert.Equal(t, []string{"one@srcd", "two@srcd"}, result.reversedPeopleDict)
+	assert.Len(t, result.Lines, 543)
+	counts := map[*object.Commit]int{}
+	for _, line := range result.Lines {
+		counts[line.Commit]++
+		if line.Commit == commit1 {
+			assert.Equal(t, 0, line.Author)
+			assert.Equal(t, 0, line.Day)
+		} else {
+			assert.Equal(t, commit2, line.Commit)
+			assert.Equal(t, 1, line.Author)
+			assert.Equal(t, 30, line.Day)
+		}
+	}
+	assert.Len(t, counts, 2)
+	assert.True(t, counts[commit1] > 0)
+	assert.True(t, counts[commit2] > 0)
+
+	blame.Path = "analyser.go"
+	assert.Nil(t, blame.Finalize().(BlameResult).Lines)
+	blame.Path = "cmd/hercules/main.go"
+	result = blame.Finalize().(BlameResult)
+	assert.Len(t, result.Lines, 207)
+	for _, line := range result.Lines {
+		assert.Equal(t, commit1, line.Commit)
+	}
+}
+
+func TestBlameConsumeTooManyCommits(t *testing.T) {
+	blame := fixtureBlame("burndown.go")
+	deps := map[string]interface{}{core.DependencyIndex: authorSelf}
+	result, err := blame.Consume(deps)
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+}
+
+func TestBlameFork(t *testing.T) {
+	blame := fixtureBlame("burndown.go")
+	consumeBlame(t, blame)
+	clones := blame.Fork(2)
+	assert.Len(t, clones, 2)
+	clone := clones[0].(*BlameAnalysis)
+	assert.True(t, blame.burndown != clone.burndown)
+	assert.True(t, blame.origins == clone.origins)
+	assert.Len(t, clone.burndown.files, 2)
+	blame.Merge(clones)
+	assert.Nil(t, blame.Hibernate())
+	assert.Nil(t, blame.Boot())
+	assert.Equal(t, blame.Finalize(), clone.Finalize())
+}
+
+func TestBlameSerialize(t *testing.T) {
+	blame := fixtureBlame("burndown.go")
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	result := BlameResult{
+		Path: "burndown.go",
+		Lines: []BlameLine{
+			{Commit: commit, Author: 1, Day: 10},
+			{Author: identity.AuthorMissing, Day: 0},
+		},
+		reversedPeopleDict: []string{"one@srcd", "two@srcd"},
+	}
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, blame.Serialize(result, false, buffer))
+	assert.Equal(t, `  path: "burndown.go"
+  lines:
+    - {commit: "cce947b98a050c6d356bc6ba95030254914027b1", author: 1, day: 10}
+    - {commit: "", author: -1, day: 0}
+  people:
+  - "one@srcd"
+  - "two@srcd"
+`, buffer.String())
+	assert.NotNil(t, blame.Serialize(result, true, &bytes.Buffer{}))
+	assert.NotNil(t, blame.Serialize("xxx", false, &bytes.Buffer{}))
+}
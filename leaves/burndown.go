@@ -1,6 +1,8 @@
 package leaves
 
 import (
+	"encoding/gob"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +12,7 @@ import (
 	"os"
 	"sort"
 	"sync"
+	"time"
 	"unicode/utf8"
 
 	"github.com/gogo/protobuf/proto"
@@ -18,6 +21,7 @@ import (
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
+	"gopkg.in/src-d/hercules.v8/internal"
 	"gopkg.in/src-d/hercules.v8/internal/burndown"
 	"gopkg.in/src-d/hercules.v8/internal/core"
 	"gopkg.in/src-d/hercules.v8/internal/pb"
@@ -102,6 +106,21 @@ type BurndownAnalysis struct {
 	previousDay int
 	// references IdentityDetector.ReversedPeopleDict
 	reversedPeopleDict []string
+	// references IdentityDetector.Teams
+	teams *identity.Teams
+	// trackAuthors makes the values in `files` carry the author indexes even if PeopleNumber
+	// is zero. BlameAnalysis sets it and passes the commit indexes as the authors,
+	// OwnershipAnalysis sets it through FactBurndownAnalysis to count the owned lines.
+	trackAuthors bool
+	// carryOver makes the lines replaced by the current commit keep their previous values,
+	// see IgnoreRevisions.
+	carryOver bool
+	// moves are the lines moved by the current commit, see MoveDetection.
+	moves movedLines
+	// coAuthors are the authors of the current commit who divide its new lines, see updateLines().
+	coAuthors []int
+	// coAuthorsTurn is the index of the co-author who receives the next split remainder.
+	coAuthorsTurn int
 }
 
 // BurndownResult carries the result of running BurndownAnalysis - it is returned by
@@ -126,6 +145,12 @@ type BurndownResult struct {
 	// The rest of the elements are equal the number of line removals by the corresponding
 	// authors in reversedPeopleDict: 2 -> 0, 3 -> 1, etc.
 	PeopleMatrix DenseHistory
+	// [number of teams][number of samples][number of bands]
+	// The sums of PeopleHistories of the team members. Nil if the teams were not specified.
+	TeamHistories []DenseHistory
+	// [number of teams][number of teams + 2]
+	// The same as PeopleMatrix, but the authors are grouped by teams.
+	TeamMatrix DenseHistory
 
 	// The following members are private.
 
@@ -133,6 +158,8 @@ type BurndownResult struct {
 	// Pipeline.Initialize(facts map[string]interface{}). Thus it can be obtained via
 	// facts[FactIdentityDetectorReversedPeopleDict].
 	reversedPeopleDict []string
+	// reversedTeamsDict is borrowed from IdentityDetector.Teams.Names.
+	reversedTeamsDict []string
 	// sampling and granularity are copied from BurndownAnalysis and stored for service purposes
 	// such as merging several results together.
 	sampling    int
@@ -160,6 +187,12 @@ const (
 	ConfigBurndownHibernationDirectory = "Burndown.HibernationDirectory"
 	// ConfigBurndownDebug enables some extra debug assertions.
 	ConfigBurndownDebug = "Burndown.Debug"
+	// FactBurndownAnalysis is the name of the fact which references the configured
+	// BurndownAnalysis so that the dependent items can adjust the line tracking.
+	FactBurndownAnalysis = "Burndown.Analysis"
+	// DependencyBurndownLines is the name of the dependency provided by BurndownAnalysis:
+	// the *BurndownAnalysis itself with the lines of every file after the current commit.
+	DependencyBurndownLines = "burndown_lines"
 	// DefaultBurndownGranularity is the default number of days for BurndownAnalysis.Granularity
 	// and BurndownAnalysis.Sampling.
 	DefaultBurndownGranularity = 30
@@ -183,7 +216,8 @@ func (analyser *BurndownAnalysis) Name() string {
 // Each produced entity will be inserted into `deps` of dependent Consume()-s according
 // to this list. Also used by core.Registry to build the global map of providers.
 func (analyser *BurndownAnalysis) Provides() []string {
-	return []string{}
+	arr := [...]string{DependencyBurndownLines}
+	return arr[:]
 }
 
 // Requires returns the list of names of entities which are needed by this PipelineItem.
@@ -192,7 +226,9 @@ func (analyser *BurndownAnalysis) Provides() []string {
 func (analyser *BurndownAnalysis) Requires() []string {
 	arr := [...]string{
 		items.DependencyFileDiff, items.DependencyTreeChanges, items.DependencyBlobCache,
-		items.DependencyDay, identity.DependencyAuthor}
+		items.DependencyDay, identity.DependencyAuthor, items.DependencyBaseTreeChanges,
+		identity.DependencyAuthors, items.DependencyCopies, items.DependencyIgnoredCommit,
+		items.DependencyMovedBlocks}
 	return arr[:]
 }
 
@@ -265,8 +301,12 @@ func (analyser *BurndownAnalysis) Configure(facts map[string]interface{}) error
 			analyser.PeopleNumber = val
 			analyser.reversedPeopleDict = facts[identity.FactIdentityDetectorReversedPeopleDict].([]string)
 		}
+		if val, exists := facts[identity.FactIdentityDetectorTeams].(*identity.Teams); exists {
+			analyser.teams = val
+		}
 	} else if exists {
 		analyser.PeopleNumber = 0
+		analyser.teams = nil
 	}
 	if val, exists := facts[ConfigBurndownHibernationThreshold].(int); exists {
 		analyser.HibernationThreshold = val
@@ -280,6 +320,7 @@ func (analyser *BurndownAnalysis) Configure(facts map[string]interface{}) error
 	if val, exists := facts[ConfigBurndownDebug].(bool); exists {
 		analyser.Debug = val
 	}
+	facts[FactBurndownAnalysis] = analyser
 	return nil
 }
 
@@ -340,11 +381,29 @@ func (analyser *BurndownAnalysis) Consume(deps map[string]interface{}) (map[stri
 	if analyser.fileAllocator.Size() == 0 && len(analyser.files) > 0 {
 		panic("BurndownAnalysis.Consume() was called on a hibernated instance")
 	}
-	author := deps[identity.DependencyAuthor].(int)
+	// every line has a single author, so the new lines are divided between the co-authors
+	// regardless of CoAuthorsCredit
+	authors := commitAuthors(deps)
+	if len(authors) == 0 {
+		// the lines must belong to somebody
+		authors = []int{identity.AuthorMissing}
+	}
+	author := authors[0]
 	day := deps[items.DependencyDay].(int)
+	if baseDiffs, exists := deps[items.DependencyBaseTreeChanges].(object.Changes); exists {
+		analyser.day = day
+		if err := analyser.loadBase(baseDiffs); err != nil {
+			return nil, err
+		}
+	}
+	ignored, _ := deps[items.DependencyIgnoredCommit].(bool)
+	analyser.carryOver = false
 	if !deps[core.DependencyIsMerge].(bool) {
 		analyser.day = day
 		analyser.onNewDay()
+		// the ignored commits change the lines without changing their authors and ages
+		analyser.carryOver = ignored
+		analyser.coAuthors = authors
 	} else {
 		// effectively disables the status updates if the commit is a merge
 		// we will analyse the conflicts resolution in Merge()
@@ -355,12 +414,21 @@ func (analyser *BurndownAnalysis) Consume(deps map[string]interface{}) (map[stri
 	cache := deps[items.DependencyBlobCache].(map[plumbing.Hash]*items.CachedBlob)
 	treeDiffs := deps[items.DependencyTreeChanges].(object.Changes)
 	fileDiffs := deps[items.DependencyFileDiff].(map[string]items.FileDiffData)
+	copies, _ := deps[items.DependencyCopies].(map[string]items.FileCopy)
+	// the sources must be captured before they are modified by the same commit
+	copiedFiles := analyser.snapshotCopies(copies)
+	moved, _ := deps[items.DependencyMovedBlocks].([]items.MovedBlock)
+	analyser.moves = analyser.snapshotMoves(moved, copiedFiles)
 	for _, change := range treeDiffs {
 		action, _ := change.Action()
 		var err error
 		switch action {
 		case merkletrie.Insert:
-			err = analyser.handleInsertion(change, author, cache)
+			if copied, exists := copiedFiles[change.To.Name]; exists {
+				err = analyser.handleCopy(change, author, cache, copied)
+			} else {
+				err = analyser.handleInsertion(change, author, cache)
+			}
 		case merkletrie.Delete:
 			err = analyser.handleDeletion(change, author, cache)
 		case merkletrie.Modify:
@@ -372,7 +440,9 @@ func (analyser *BurndownAnalysis) Consume(deps map[string]interface{}) (map[stri
 	}
 	// in case there is a merge analyser.day equals to TreeMergeMark
 	analyser.day = day
-	return nil, nil
+	analyser.moves = movedLines{}
+	analyser.coAuthors = nil
+	return map[string]interface{}{DependencyBurndownLines: analyser}, nil
 }
 
 // Fork clones this item. Everything is copied by reference except the files
@@ -484,6 +554,109 @@ func (analyser *BurndownAnalysis) Boot() error {
 	return nil
 }
 
+// burndownCheckpoint is the state of BurndownAnalysis which is saved in Checkpoint().
+type burndownCheckpoint struct {
+	Granularity     int
+	Sampling        int
+	TrackFiles      bool
+	PeopleNumber    int
+	TrackAuthors    bool
+	GlobalHistory   sparseHistory
+	FileHistories   map[string]sparseHistory
+	PeopleHistories []sparseHistory
+	// Files maps the file names to the flattened line interval trees.
+	Files       map[string]burndownFileCheckpoint
+	Renames     map[string]string
+	Matrix      []map[int]int64
+	Day         int
+	PreviousDay int
+}
+
+// burndownFileCheckpoint is the flattened burndown.File: the tree keys and the corresponding values.
+type burndownFileCheckpoint struct {
+	Keys   []int
+	Values []int
+}
+
+// Checkpoint writes the line interval trees of all the files and the accumulated histories.
+func (analyser *BurndownAnalysis) Checkpoint(writer io.Writer) error {
+	if analyser.fileAllocator.Size() == 0 && len(analyser.files) > 0 {
+		return errors.New("cannot checkpoint a hibernated BurndownAnalysis")
+	}
+	state := burndownCheckpoint{
+		Granularity:     analyser.Granularity,
+		Sampling:        analyser.Sampling,
+		TrackFiles:      analyser.TrackFiles,
+		PeopleNumber:    analyser.PeopleNumber,
+		TrackAuthors:    analyser.trackAuthors,
+		GlobalHistory:   analyser.globalHistory,
+		FileHistories:   analyser.fileHistories,
+		PeopleHistories: analyser.peopleHistories,
+		Files:           map[string]burndownFileCheckpoint{},
+		Renames:         analyser.renames,
+		Matrix:          analyser.matrix,
+		Day:             analyser.day,
+		PreviousDay:     analyser.previousDay,
+	}
+	for name, file := range analyser.files {
+		flat := burndownFileCheckpoint{}
+		file.ForEach(func(line, value int) {
+			if value < 0 {
+				// ForEach() reports TreeEnd as -1
+				value = burndown.TreeEnd
+			}
+			flat.Keys = append(flat.Keys, line)
+			flat.Values = append(flat.Values, value)
+		})
+		state.Files[name] = flat
+	}
+	return gob.NewEncoder(writer).Encode(&state)
+}
+
+// Restore reads the state previously written by Checkpoint(). The number of tracked people
+// may grow since the checkpoint, but the rest of the options must stay the same.
+func (analyser *BurndownAnalysis) Restore(reader io.Reader, facts map[string]interface{}) error {
+	state := burndownCheckpoint{}
+	err := gob.NewDecoder(reader).Decode(&state)
+	if err != nil {
+		return err
+	}
+	if state.Granularity != analyser.Granularity || state.Sampling != analyser.Sampling {
+		return fmt.Errorf("granularity and sampling mismatch: the checkpoint has %d and %d",
+			state.Granularity, state.Sampling)
+	}
+	if state.TrackFiles != analyser.TrackFiles {
+		return fmt.Errorf("--burndown-files mismatch: the checkpoint has %v", state.TrackFiles)
+	}
+	if (state.PeopleNumber > 0) != (analyser.PeopleNumber > 0) ||
+		state.PeopleNumber > analyser.PeopleNumber {
+		return fmt.Errorf("--burndown-people mismatch: the checkpoint has %d people, now %d",
+			state.PeopleNumber, analyser.PeopleNumber)
+	}
+	if state.TrackAuthors {
+		// the restored values carry the authors, OwnershipAnalysis is configured later
+		analyser.trackAuthors = true
+	}
+	if state.GlobalHistory != nil {
+		analyser.globalHistory = state.GlobalHistory
+	}
+	for name, history := range state.FileHistories {
+		analyser.fileHistories[name] = history
+	}
+	copy(analyser.peopleHistories, state.PeopleHistories)
+	copy(analyser.matrix, state.Matrix)
+	for from, to := range state.Renames {
+		analyser.renames[from] = to
+	}
+	for name, flat := range state.Files {
+		analyser.files[name] = burndown.NewFileFromTree(
+			flat.Keys, flat.Values, analyser.fileAllocator, analyser.fileUpdaters(name)...)
+	}
+	analyser.day = state.Day
+	analyser.previousDay = state.PreviousDay
+	return nil
+}
+
 // Finalize returns the result of the analysis. Further Consume() calls are not expected.
 func (analyser *BurndownAnalysis) Finalize() interface{} {
 	globalHistory, lastDay := analyser.groupSparseHistory(analyser.globalHistory, -1)
@@ -494,22 +667,16 @@ func (analyser *BurndownAnalysis) Finalize() interface{} {
 			continue
 		}
 		fileHistories[key], _ = analyser.groupSparseHistory(history, lastDay)
-		file := analyser.files[key]
-		previousLine := 0
-		previousAuthor := identity.AuthorMissing
-		ownership := map[int]int{}
-		fileOwnership[key] = ownership
-		file.ForEach(func(line, value int) {
-			length := line - previousLine
-			if length > 0 {
-				ownership[previousAuthor] += length
-			}
-			previousLine = line
-			previousAuthor, _ = analyser.unpackPersonWithDay(int(value))
-			if previousAuthor == identity.AuthorMissing {
-				previousAuthor = -1
+		owners := analyser.fileOwnership(analyser.files[key])
+		if analyser.PeopleNumber == 0 && analyser.trackAuthors {
+			// the authors are tracked for OwnershipAnalysis and must not leak here
+			anonymous := map[int]int{}
+			for _, lines := range owners {
+				anonymous[-1] += lines
 			}
-		})
+			owners = anonymous
+		}
+		fileOwnership[key] = owners
 	}
 	peopleHistories := make([]DenseHistory, analyser.PeopleNumber)
 	for i, history := range analyser.peopleHistories {
@@ -539,7 +706,7 @@ func (analyser *BurndownAnalysis) Finalize() interface{} {
 			}
 		}
 	}
-	return BurndownResult{
+	result := BurndownResult{
 		GlobalHistory:      globalHistory,
 		FileHistories:      fileHistories,
 		FileOwnership:      fileOwnership,
@@ -549,6 +716,57 @@ func (analyser *BurndownAnalysis) Finalize() interface{} {
 		sampling:           analyser.Sampling,
 		granularity:        analyser.Granularity,
 	}
+	if analyser.teams != nil && analyser.PeopleNumber > 0 {
+		result.TeamHistories, result.TeamMatrix = groupPeopleByTeams(
+			globalHistory, peopleHistories, peopleMatrix, analyser.teams)
+		result.reversedTeamsDict = analyser.teams.Names
+	}
+	return result
+}
+
+// groupPeopleByTeams sums the burndown histories and the overwrites matrix rows and columns
+// of the team members. The teams without members have zero histories shaped as `globalHistory`.
+func groupPeopleByTeams(globalHistory DenseHistory, peopleHistories []DenseHistory,
+	peopleMatrix DenseHistory, teams *identity.Teams) ([]DenseHistory, DenseHistory) {
+	teamHistories := make([]DenseHistory, len(teams.Names))
+	for i := range teamHistories {
+		teamHistories[i] = make(DenseHistory, len(globalHistory))
+		for j, gh := range globalHistory {
+			teamHistories[i][j] = make([]int64, len(gh))
+		}
+	}
+	for person, history := range peopleHistories {
+		team := teams.Team(person)
+		teamHistory := teamHistories[team]
+		for len(teamHistory) < len(history) {
+			teamHistory = append(teamHistory, []int64{})
+		}
+		for y, row := range history {
+			for len(teamHistory[y]) < len(row) {
+				teamHistory[y] = append(teamHistory[y], 0)
+			}
+			for x, val := range row {
+				teamHistory[y][x] += val
+			}
+		}
+		teamHistories[team] = teamHistory
+	}
+	var teamMatrix DenseHistory
+	if peopleMatrix != nil {
+		teamMatrix = make(DenseHistory, len(teams.Names))
+		for i := range teamMatrix {
+			teamMatrix[i] = make([]int64, len(teams.Names)+2)
+		}
+		for person, row := range peopleMatrix {
+			teamRow := teamMatrix[teams.Team(person)]
+			teamRow[0] += row[0]
+			teamRow[1] += row[1]
+			for other, val := range row[2:] {
+				teamRow[2+teams.Team(other)] += val
+			}
+		}
+	}
+	return teamHistories, teamMatrix
 }
 
 // Serialize converts the analysis result as returned by Finalize() to text or bytes.
@@ -562,6 +780,12 @@ func (analyser *BurndownAnalysis) Serialize(result interface{}, binary bool, wri
 	return nil
 }
 
+// SerializeJSON converts the analysis result as returned by Finalize() to JSON.
+func (analyser *BurndownAnalysis) SerializeJSON(result interface{}, writer io.Writer) error {
+	burndownResult := result.(BurndownResult)
+	return analyser.serializeJSON(&burndownResult, writer)
+}
+
 // Deserialize converts the specified protobuf bytes to BurndownResult.
 func (analyser *BurndownAnalysis) Deserialize(pbmessage []byte) (interface{}, error) {
 	msg := pb.BurndownAnalysisResults{}
@@ -591,32 +815,65 @@ func (analyser *BurndownAnalysis) Deserialize(pbmessage []byte) (interface{}, er
 			ownership[int(key)] = int(val)
 		}
 	}
-	result.reversedPeopleDict = make([]string, len(msg.People))
-	result.PeopleHistories = make([]DenseHistory, len(msg.People))
-	for i, mat := range msg.People {
-		result.PeopleHistories[i] = convertCSR(mat)
-		result.reversedPeopleDict[i] = mat.Name
-	}
-	if msg.PeopleInteraction != nil {
-		result.PeopleMatrix = make(DenseHistory, msg.PeopleInteraction.NumberOfRows)
+	convertPeople := func(people []*pb.BurndownSparseMatrix) ([]DenseHistory, []string) {
+		histories := make([]DenseHistory, len(people))
+		names := make([]string, len(people))
+		for i, mat := range people {
+			histories[i] = convertCSR(mat)
+			names[i] = mat.Name
+		}
+		return histories, names
 	}
-	for i := 0; i < len(result.PeopleMatrix); i++ {
-		result.PeopleMatrix[i] = make([]int64, msg.PeopleInteraction.NumberOfColumns)
-		for j := int(msg.PeopleInteraction.Indptr[i]); j < int(msg.PeopleInteraction.Indptr[i+1]); j++ {
-			result.PeopleMatrix[i][msg.PeopleInteraction.Indices[j]] = msg.PeopleInteraction.Data[j]
+	convertInteraction := func(mat *pb.CompressedSparseRowMatrix) DenseHistory {
+		if mat == nil {
+			return nil
+		}
+		res := make(DenseHistory, mat.NumberOfRows)
+		for i := 0; i < len(res); i++ {
+			res[i] = make([]int64, mat.NumberOfColumns)
+			for j := int(mat.Indptr[i]); j < int(mat.Indptr[i+1]); j++ {
+				res[i][mat.Indices[j]] = mat.Data[j]
+			}
 		}
+		return res
+	}
+	result.PeopleHistories, result.reversedPeopleDict = convertPeople(msg.People)
+	result.PeopleMatrix = convertInteraction(msg.PeopleInteraction)
+	if len(msg.Teams) > 0 {
+		result.TeamHistories, result.reversedTeamsDict = convertPeople(msg.Teams)
+		result.TeamMatrix = convertInteraction(msg.TeamsInteraction)
 	}
 	result.sampling = int(msg.Sampling)
 	result.granularity = int(msg.Granularity)
 	return result, nil
 }
 
-// MergeResults combines two BurndownResult-s together.
+// MergeResults combines two BurndownResult-s together. The results with different tick sizes
+// are converted to the coarser one.
 func (analyser *BurndownAnalysis) MergeResults(
 	r1, r2 interface{}, c1, c2 *core.CommonAnalysisResult) interface{} {
-	bar1 := r1.(BurndownResult)
-	bar2 := r2.(BurndownResult)
+	tickSize1, tickSize2 := resultTickSize(c1), resultTickSize(c2)
+	tickSize := core.MergeTickSizes(tickSize1, tickSize2)
+	bar1 := r1.(BurndownResult).convertTickSize(tickSize1, tickSize)
+	bar2 := r2.(BurndownResult).convertTickSize(tickSize2, tickSize)
 	merged := BurndownResult{}
+	if len(bar1.TeamHistories) > 0 && len(bar2.TeamHistories) > 0 {
+		// merge the teams the same way as people
+		teamsView := func(result BurndownResult) BurndownResult {
+			return BurndownResult{
+				PeopleHistories:    result.TeamHistories,
+				PeopleMatrix:       result.TeamMatrix,
+				reversedPeopleDict: result.reversedTeamsDict,
+				sampling:           result.sampling,
+				granularity:        result.granularity,
+			}
+		}
+		mergedTeams := analyser.MergeResults(
+			teamsView(r1.(BurndownResult)), teamsView(r2.(BurndownResult)), c1, c2).(BurndownResult)
+		merged.TeamHistories = mergedTeams.PeopleHistories
+		merged.TeamMatrix = mergedTeams.PeopleMatrix
+		merged.reversedTeamsDict = mergedTeams.reversedPeopleDict
+	}
 	if bar1.sampling < bar2.sampling {
 		merged.sampling = bar1.sampling
 	} else {
@@ -711,17 +968,113 @@ func (analyser *BurndownAnalysis) MergeResults(
 	return merged
 }
 
-func roundTime(unix int64, dir bool) int {
-	days := float64(unix) / (3600 * 24)
+// roundTime converts the UNIX timestamp to the number of ticks since the epoch.
+func roundTime(unix int64, tickSize time.Duration, dir bool) int {
+	ticks := float64(unix) / tickSize.Seconds()
 	if dir {
-		return int(math.Ceil(days))
+		return int(math.Ceil(ticks))
+	}
+	return int(math.Floor(ticks))
+}
+
+// resultTickSize returns the tick size of the analysed results. Zero and nil mean the default.
+func resultTickSize(common *core.CommonAnalysisResult) time.Duration {
+	if common == nil || common.TickSize == 0 {
+		return core.DefaultTickSize
+	}
+	return common.TickSize
+}
+
+// convertTicks multiplies the number of ticks by the ratio of the tick sizes. The result is
+// rounded to the nearest positive integer.
+func convertTicks(ticks int, ratio float64) int {
+	result := int(math.Round(float64(ticks) * ratio))
+	if result < 1 {
+		result = 1
+	}
+	return result
+}
+
+// convertTickSize resamples GlobalHistory, PeopleHistories and TeamHistories which were calculated with ticks
+// of size `from` to ticks of size `to`. The conversion is exact if the granularity and
+// the sampling in the new ticks are integers.
+func (result BurndownResult) convertTickSize(from, to time.Duration) BurndownResult {
+	if from == to {
+		return result
+	}
+	ratio := float64(from) / float64(to)
+	converted := result
+	converted.granularity = convertTicks(result.granularity, ratio)
+	converted.sampling = convertTicks(result.sampling, ratio)
+	resample := func(history DenseHistory) DenseHistory {
+		return resampleHistory(history, result.granularity, result.sampling,
+			converted.granularity, converted.sampling, ratio)
+	}
+	converted.GlobalHistory = resample(result.GlobalHistory)
+	if result.PeopleHistories != nil {
+		converted.PeopleHistories = make([]DenseHistory, len(result.PeopleHistories))
+		for i, history := range result.PeopleHistories {
+			converted.PeopleHistories[i] = resample(history)
+		}
+	}
+	if result.TeamHistories != nil {
+		converted.TeamHistories = make([]DenseHistory, len(result.TeamHistories))
+		for i, history := range result.TeamHistories {
+			converted.TeamHistories[i] = resample(history)
+		}
+	}
+	return converted
+}
+
+// resampleHistory converts the [number of samples][number of bands] matrix to the new granularity
+// and sampling which are measured in ticks `ratio` times bigger. Each new sample is the latest
+// old sample which is not later, each new band is the sum of the old bands weighted by
+// the overlaps.
+func resampleHistory(history DenseHistory, granularity, sampling, newGranularity, newSampling int,
+	ratio float64) DenseHistory {
+	if len(history) == 0 {
+		return history
+	}
+	maxCols := 0
+	for _, row := range history {
+		if maxCols < len(row) {
+			maxCols = len(row)
+		}
 	}
-	return int(math.Floor(days))
+	rows := int(math.Ceil(float64(len(history)*sampling) * ratio / float64(newSampling)))
+	cols := int(math.Ceil(float64(maxCols*granularity) * ratio / float64(newGranularity)))
+	result := make(DenseHistory, rows)
+	for i := range result {
+		y := int(float64((i+1)*newSampling)/ratio)/sampling - 1
+		if y < 0 {
+			y = 0
+		} else if y >= len(history) {
+			y = len(history) - 1
+		}
+		row := history[y]
+		result[i] = make([]int64, cols)
+		for j := range result[i] {
+			// the boundaries of the new band in the old ticks
+			begin := float64(j*newGranularity) / ratio
+			end := float64((j+1)*newGranularity) / ratio
+			accum := float64(0)
+			for x := int(begin) / granularity; x < len(row) && float64(x*granularity) < end; x++ {
+				overlap := math.Min(end, float64((x+1)*granularity)) -
+					math.Max(begin, float64(x*granularity))
+				if overlap > 0 {
+					accum += float64(row[x]) * overlap / float64(granularity)
+				}
+			}
+			result[i][j] = int64(math.Round(accum))
+		}
+	}
+	return result
 }
 
 // mergeMatrices takes two [number of samples][number of bands] matrices,
-// resamples them to days so that they become square, sums and resamples back to the
-// least of (sampling1, sampling2) and (granularity1, granularity2).
+// resamples them to ticks so that they become square, sums and resamples back to the
+// least of (sampling1, sampling2) and (granularity1, granularity2). The granularities and
+// the samplings must be already converted to the merged tick size.
 func mergeMatrices(m1, m2 DenseHistory, granularity1, sampling1, granularity2, sampling2 int,
 	c1, c2 *core.CommonAnalysisResult) DenseHistory {
 	commonMerged := c1.Copy()
@@ -739,18 +1092,27 @@ func mergeMatrices(m1, m2 DenseHistory, granularity1, sampling1, granularity2, s
 		granularity = granularity2
 	}
 
-	size := roundTime(commonMerged.EndTime, true) - roundTime(commonMerged.BeginTime, false)
-	daily := make([][]float32, size+granularity)
+	tickSize := commonMerged.TickSize
+	size := roundTime(commonMerged.EndTime, tickSize, true) -
+		roundTime(commonMerged.BeginTime, tickSize, false)
+	// the last samples and bands may stick out
+	padding := granularity1
+	for _, value := range [...]int{sampling1, granularity2, sampling2} {
+		if value > padding {
+			padding = value
+		}
+	}
+	daily := make([][]float32, size+padding)
 	for i := range daily {
-		daily[i] = make([]float32, size+sampling)
+		daily[i] = make([]float32, size+padding)
 	}
 	if len(m1) > 0 {
 		addBurndownMatrix(m1, granularity1, sampling1, daily,
-			roundTime(c1.BeginTime, false)-roundTime(commonMerged.BeginTime, false))
+			roundTime(c1.BeginTime, tickSize, false)-roundTime(commonMerged.BeginTime, tickSize, false))
 	}
 	if len(m2) > 0 {
 		addBurndownMatrix(m2, granularity2, sampling2, daily,
-			roundTime(c2.BeginTime, false)-roundTime(commonMerged.BeginTime, false))
+			roundTime(c2.BeginTime, tickSize, false)-roundTime(commonMerged.BeginTime, tickSize, false))
 	}
 
 	// convert daily to [][]int64
@@ -982,17 +1344,99 @@ func (analyser *BurndownAnalysis) serializeText(result *BurndownResult, writer i
 	}
 
 	if len(result.PeopleHistories) > 0 {
-		fmt.Fprintln(writer, "  people_sequence:")
-		for key := range result.PeopleHistories {
-			fmt.Fprintln(writer, "    - "+yaml.SafeString(result.reversedPeopleDict[key]))
+		serializePeopleHistoriesText(
+			"people", result.PeopleHistories, result.PeopleMatrix, result.reversedPeopleDict, writer)
+	}
+	if len(result.TeamHistories) > 0 {
+		serializePeopleHistoriesText(
+			"teams", result.TeamHistories, result.TeamMatrix, result.reversedTeamsDict, writer)
+	}
+}
+
+// serializePeopleHistoriesText writes the burndown histories of developers or teams
+// as YAML. `key` is the prefix of the YAML keys.
+func serializePeopleHistoriesText(key string, histories []DenseHistory, matrix DenseHistory,
+	names []string, writer io.Writer) {
+	fmt.Fprintf(writer, "  %s_sequence:\n", key)
+	for i := range histories {
+		fmt.Fprintln(writer, "    - "+yaml.SafeString(names[i]))
+	}
+	fmt.Fprintf(writer, "  %s:\n", key)
+	for i, val := range histories {
+		yaml.PrintMatrix(writer, val, 4, names[i], true)
+	}
+	fmt.Fprintf(writer, "  %s_interaction: |-\n", key)
+	yaml.PrintMatrix(writer, matrix, 4, "", false)
+}
+
+// burndownJSON is the JSON schema of BurndownResult, see doc/JSON.md.
+type burndownJSON struct {
+	Granularity       int                    `json:"granularity"`
+	Sampling          int                    `json:"sampling"`
+	Project           [][]int64              `json:"project"`
+	Files             map[string][][]int64   `json:"files,omitempty"`
+	FilesOwnership    map[string]map[int]int `json:"files_ownership,omitempty"`
+	PeopleSequence    []string               `json:"people_sequence,omitempty"`
+	People            [][][]int64            `json:"people,omitempty"`
+	PeopleInteraction [][]int64              `json:"people_interaction,omitempty"`
+	TeamsSequence     []string               `json:"teams_sequence,omitempty"`
+	Teams             [][][]int64            `json:"teams,omitempty"`
+	TeamsInteraction  [][]int64              `json:"teams_interaction,omitempty"`
+}
+
+// denseHistoryToJSON makes the matrix rectangular the same way as yaml.PrintMatrix() does.
+// `fixNegative` changes all negative values to 0.
+func denseHistoryToJSON(matrix DenseHistory, fixNegative bool) [][]int64 {
+	result := make([][]int64, len(matrix))
+	if len(matrix) == 0 {
+		return result
+	}
+	last := len(matrix[len(matrix)-1])
+	for i, status := range matrix {
+		row := make([]int64, last)
+		for j := 0; j < last && j < len(status); j++ {
+			val := status[j]
+			if fixNegative && val < 0 {
+				val = 0
+			}
+			row[j] = val
 		}
-		fmt.Fprintln(writer, "  people:")
+		result[i] = row
+	}
+	return result
+}
+
+func (analyser *BurndownAnalysis) serializeJSON(result *BurndownResult, writer io.Writer) error {
+	message := burndownJSON{
+		Granularity: result.granularity,
+		Sampling:    result.sampling,
+		Project:     denseHistoryToJSON(result.GlobalHistory, true),
+	}
+	if len(result.FileHistories) > 0 {
+		message.Files = map[string][][]int64{}
+		for key, val := range result.FileHistories {
+			message.Files[key] = denseHistoryToJSON(val, true)
+		}
+		message.FilesOwnership = result.FileOwnership
+	}
+	if len(result.PeopleHistories) > 0 {
+		message.PeopleSequence = make([]string, len(result.PeopleHistories))
+		message.People = make([][][]int64, len(result.PeopleHistories))
 		for key, val := range result.PeopleHistories {
-			yaml.PrintMatrix(writer, val, 4, result.reversedPeopleDict[key], true)
+			message.PeopleSequence[key] = result.reversedPeopleDict[key]
+			message.People[key] = denseHistoryToJSON(val, true)
 		}
-		fmt.Fprintln(writer, "  people_interaction: |-")
-		yaml.PrintMatrix(writer, result.PeopleMatrix, 4, "", false)
+		message.PeopleInteraction = denseHistoryToJSON(result.PeopleMatrix, false)
 	}
+	if len(result.TeamHistories) > 0 {
+		message.TeamsSequence = result.reversedTeamsDict
+		message.Teams = make([][][]int64, len(result.TeamHistories))
+		for key, val := range result.TeamHistories {
+			message.Teams[key] = denseHistoryToJSON(val, true)
+		}
+		message.TeamsInteraction = denseHistoryToJSON(result.TeamMatrix, false)
+	}
+	return json.NewEncoder(writer).Encode(&message)
 }
 
 func (analyser *BurndownAnalysis) serializeBinary(result *BurndownResult, writer io.Writer) error {
@@ -1031,6 +1475,17 @@ func (analyser *BurndownAnalysis) serializeBinary(result *BurndownResult, writer
 	if result.PeopleMatrix != nil {
 		message.PeopleInteraction = pb.DenseToCompressedSparseRowMatrix(result.PeopleMatrix)
 	}
+	if len(result.TeamHistories) > 0 {
+		message.Teams = make([]*pb.BurndownSparseMatrix, len(result.TeamHistories))
+		for key, val := range result.TeamHistories {
+			if len(val) > 0 {
+				message.Teams[key] = pb.ToBurndownSparseMatrix(val, result.reversedTeamsDict[key])
+			}
+		}
+		if result.TeamMatrix != nil {
+			message.TeamsInteraction = pb.DenseToCompressedSparseRowMatrix(result.TeamMatrix)
+		}
+	}
 	serialized, err := proto.Marshal(&message)
 	if err != nil {
 		return err
@@ -1059,7 +1514,7 @@ func checkClose(c io.Closer) {
 // This hack is needed to simplify the values storage inside File-s. We can compare
 // different values together and they are compared as days for the same author.
 func (analyser *BurndownAnalysis) packPersonWithDay(person int, day int) int {
-	if analyser.PeopleNumber == 0 {
+	if analyser.PeopleNumber == 0 && !analyser.trackAuthors {
 		return day
 	}
 	result := day & burndown.TreeMergeMark
@@ -1073,8 +1528,28 @@ func (analyser *BurndownAnalysis) packPersonWithDay(person int, day int) int {
 	return result
 }
 
+// fileOwnership counts the lines in the file which were last edited by each developer.
+// The unidentified developers are mapped to -1.
+func (analyser *BurndownAnalysis) fileOwnership(file *burndown.File) map[int]int {
+	previousLine := 0
+	previousAuthor := identity.AuthorMissing
+	ownership := map[int]int{}
+	file.ForEach(func(line, value int) {
+		length := line - previousLine
+		if length > 0 {
+			ownership[previousAuthor] += length
+		}
+		previousLine = line
+		previousAuthor, _ = analyser.unpackPersonWithDay(int(value))
+		if previousAuthor == identity.AuthorMissing {
+			previousAuthor = -1
+		}
+	})
+	return ownership
+}
+
 func (analyser *BurndownAnalysis) unpackPersonWithDay(value int) (int, int) {
-	if analyser.PeopleNumber == 0 {
+	if analyser.PeopleNumber == 0 && !analyser.trackAuthors {
 		return identity.AuthorMissing, value
 	}
 	return value >> burndown.TreeMaxBinPower, value & burndown.TreeMergeMark
@@ -1157,6 +1632,15 @@ func (analyser *BurndownAnalysis) updateMatrix(currentTime, previousTime, delta
 
 func (analyser *BurndownAnalysis) newFile(
 	hash plumbing.Hash, name string, author int, day int, size int) (*burndown.File, error) {
+	updaters := analyser.fileUpdaters(name)
+	if analyser.PeopleNumber > 0 || analyser.trackAuthors {
+		day = analyser.packPersonWithDay(author, day)
+	}
+	return burndown.NewFile(day, size, analyser.fileAllocator, updaters...), nil
+}
+
+// fileUpdaters returns the callbacks which must be attached to the File with the specified name.
+func (analyser *BurndownAnalysis) fileUpdaters(name string) []burndown.Updater {
 	updaters := make([]burndown.Updater, 1)
 	updaters[0] = analyser.updateGlobal
 	if analyser.TrackFiles {
@@ -1173,9 +1657,8 @@ func (analyser *BurndownAnalysis) newFile(
 	if analyser.PeopleNumber > 0 {
 		updaters = append(updaters, analyser.updateAuthor)
 		updaters = append(updaters, analyser.updateMatrix)
-		day = analyser.packPersonWithDay(author, day)
 	}
-	return burndown.NewFile(day, size, analyser.fileAllocator, updaters...), nil
+	return updaters
 }
 
 func (analyser *BurndownAnalysis) handleInsertion(
@@ -1195,7 +1678,13 @@ func (analyser *BurndownAnalysis) handleInsertion(
 	if analyser.day != burndown.TreeMergeMark {
 		hash = blob.Hash
 	}
-	file, err = analyser.newFile(hash, name, author, analyser.day, lines)
+	if moved := analyser.moves.to[name]; len(moved) == 0 && len(analyser.coAuthors) < 2 {
+		file, err = analyser.newFile(hash, name, author, analyser.day, lines)
+	} else {
+		// the moved lines keep their values, the rest are divided between the co-authors
+		file, err = analyser.newFile(hash, name, author, analyser.day, 0)
+		analyser.insertLines(file, name, author, 0, lines, moved)
+	}
 	analyser.files[name] = file
 	if analyser.day == burndown.TreeMergeMark {
 		analyser.mergedFiles[name] = true
@@ -1203,6 +1692,38 @@ func (analyser *BurndownAnalysis) handleInsertion(
 	return err
 }
 
+// loadBase inserts the files which existed before the first analysed commit. Their lines
+// are attributed to AuthorMissing at the current day.
+func (analyser *BurndownAnalysis) loadBase(changes object.Changes) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	cache := map[plumbing.Hash]*items.CachedBlob{}
+	for _, change := range changes {
+		hash := change.To.TreeEntry.Hash
+		blob, err := analyser.repository.BlobObject(hash)
+		if err != nil && change.To.Tree != nil {
+			// the file belongs to a submodule, see TreeDiff.Submodules
+			if file, errTree := change.To.Tree.TreeEntryFile(&change.To.TreeEntry); errTree == nil {
+				blob, err = &file.Blob, nil
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load blob %s of %s: %v", hash.String(), change.To.Name, err)
+		}
+		cachedBlob := &items.CachedBlob{Blob: *blob}
+		if err = cachedBlob.Cache(); err != nil {
+			return fmt.Errorf("failed to read blob %s of %s: %v", hash.String(), change.To.Name, err)
+		}
+		cache[hash] = cachedBlob
+		if err = analyser.handleInsertion(change, identity.AuthorMissing, cache); err != nil {
+			return err
+		}
+		delete(cache, hash)
+	}
+	return nil
+}
+
 func (analyser *BurndownAnalysis) handleDeletion(
 	change *object.Change, author int, cache map[plumbing.Hash]*items.CachedBlob) error {
 
@@ -1222,7 +1743,7 @@ func (analyser *BurndownAnalysis) handleDeletion(
 	if !exists {
 		return nil
 	}
-	file.Update(analyser.packPersonWithDay(author, analyser.day), 0, 0, lines)
+	analyser.deleteLines(file, name, author, 0, 0, lines, analyser.moves.from[change.From.Name])
 	file.Delete()
 	delete(analyser.files, name)
 	delete(analyser.fileHistories, name)
@@ -1281,7 +1802,16 @@ func (analyser *BurndownAnalysis) handleModification(
 		return nil
 	}
 
-	thisDiffs := diffs[change.To.Name]
+	return analyser.applyDiff(change, file, author, diffs[change.To.Name],
+		analyser.moves.from[change.From.Name], analyser.moves.to[change.To.Name])
+}
+
+// applyDiff updates the lines of the file according to the diff of the change.
+// movedFrom and movedTo are the values of the moved lines by their old and new indexes.
+func (analyser *BurndownAnalysis) applyDiff(
+	change *object.Change, file *burndown.File, author int, thisDiffs items.FileDiffData,
+	movedFrom, movedTo map[int]int) error {
+
 	if file.Len() != thisDiffs.OldLinesOfCode {
 		log.Printf("====TREE====\n%s", file.Dump())
 		return fmt.Errorf("%s: internal integrity error src %d != %d %s -> %s",
@@ -1292,15 +1822,18 @@ func (analyser *BurndownAnalysis) handleModification(
 	// we do not call RunesToDiffLines so the number of lines equals
 	// to the rune count
 	position := 0
+	// oldLine is the index of the line in the previous revision of the file
+	oldLine := 0
 	pending := diffmatchpatch.Diff{Text: ""}
 
 	apply := func(edit diffmatchpatch.Diff) {
 		length := utf8.RuneCountInString(edit.Text)
 		if edit.Type == diffmatchpatch.DiffInsert {
-			file.Update(analyser.packPersonWithDay(author, analyser.day), position, length, 0)
+			analyser.insertLines(file, change.To.Name, author, position, length, movedTo)
 			position += length
 		} else {
-			file.Update(analyser.packPersonWithDay(author, analyser.day), position, 0, length)
+			analyser.deleteLines(file, change.To.Name, author, position, oldLine, length, movedFrom)
+			oldLine += length
 		}
 		if analyser.Debug {
 			file.Validate()
@@ -1329,18 +1862,40 @@ func (analyser *BurndownAnalysis) handleModification(
 				pending.Text = ""
 			}
 			position += length
+			oldLine += length
 		case diffmatchpatch.DiffInsert:
 			if pending.Text != "" {
 				if pending.Type == diffmatchpatch.DiffInsert {
 					debugError()
 					return errors.New("DiffInsert may not appear after DiffInsert")
 				}
-				file.Update(analyser.packPersonWithDay(author, analyser.day), position, length,
-					utf8.RuneCountInString(pending.Text))
+				delLength := utf8.RuneCountInString(pending.Text)
+				if len(movedFrom) > 0 || len(movedTo) > 0 {
+					// the moved lines are deleted and inserted separately from the rest
+					apply(pending)
+					apply(edit)
+				} else if analyser.carryOver {
+					// the replaced lines keep their values, only the difference is inserted or deleted
+					common := internal.Min(length, delLength)
+					position += common
+					if length > common {
+						file.Update(analyser.insertionTime(file, author, position), position,
+							length-common, 0)
+						position += length - common
+					} else if delLength > common {
+						file.Update(analyser.packPersonWithDay(author, analyser.day), position,
+							0, delLength-common)
+					}
+				} else {
+					analyser.updateLines(file, author, position, length, delLength)
+					position += length
+				}
+				if len(movedFrom) == 0 && len(movedTo) == 0 {
+					oldLine += delLength
+				}
 				if analyser.Debug {
 					file.Validate()
 				}
-				position += length
 				pending.Text = ""
 			} else {
 				pending = edit
@@ -1368,6 +1923,219 @@ func (analyser *BurndownAnalysis) handleModification(
 	return nil
 }
 
+// insertionTime returns the value of the lines inserted at the specified position. It is the
+// current author and day unless carryOver is set, then the lines inherit the value of the
+// previous line.
+func (analyser *BurndownAnalysis) insertionTime(file *burndown.File, author int, position int) int {
+	if !analyser.carryOver || file.Len() == 0 {
+		return analyser.packPersonWithDay(author, analyser.day)
+	}
+	return file.Get(internal.Max(position-1, 0))
+}
+
+// updateLines deletes `deleted` lines at the position of the file and inserts `length` new lines
+// instead. The new lines of a commit with several authors are divided between them in contiguous
+// parts, unless the lines keep their values because of carryOver.
+func (analyser *BurndownAnalysis) updateLines(
+	file *burndown.File, author int, position int, length int, deleted int) {
+
+	authors := analyser.coAuthors
+	if len(authors) < 2 || authors[0] != author || analyser.carryOver {
+		file.Update(analyser.insertionTime(file, author, position), position, length, deleted)
+		return
+	}
+	for i := range authors {
+		// the remainders go to the authors in turn
+		turn := (i + analyser.coAuthorsTurn) % len(authors)
+		share := identity.CoAuthorsCreditSplit.Share(length, i, len(authors))
+		file.Update(analyser.packPersonWithDay(authors[turn], analyser.day), position, share, deleted)
+		position += share
+		deleted = 0
+	}
+	analyser.coAuthorsTurn = (analyser.coAuthorsTurn + length) % len(authors)
+}
+
+// insertLines inserts the lines at the position of the file. The moved lines keep their values
+// in `moved` by the new line indexes.
+func (analyser *BurndownAnalysis) insertLines(
+	file *burndown.File, name string, author int, position int, length int, moved map[int]int) {
+
+	for length > 0 {
+		value, isMoved := moved[position]
+		run := 1
+		for ; run < length; run++ {
+			if next, nextMoved := moved[position+run]; nextMoved != isMoved || next != value {
+				break
+			}
+		}
+		if isMoved {
+			file.Inherit(value, position, run)
+			analyser.updateMovedLines(name, author, value, run)
+		} else {
+			analyser.updateLines(file, author, position, run, 0)
+		}
+		position += run
+		length -= run
+	}
+}
+
+// deleteLines deletes the lines at the position of the file which start at `oldLine`
+// in the previous revision. The moved lines are found in `moved` by the old line indexes.
+func (analyser *BurndownAnalysis) deleteLines(
+	file *burndown.File, name string, author int, position int, oldLine int, length int,
+	moved map[int]int) {
+
+	for length > 0 {
+		value, isMoved := moved[oldLine]
+		run := 1
+		for ; run < length; run++ {
+			if next, nextMoved := moved[oldLine+run]; nextMoved != isMoved || next != value {
+				break
+			}
+		}
+		if isMoved {
+			file.Withdraw(position, run)
+			analyser.updateMovedLines(name, author, value, -run)
+		} else {
+			file.Update(analyser.packPersonWithDay(author, analyser.day), position, 0, run)
+		}
+		oldLine += run
+		length -= run
+	}
+}
+
+// updateMovedLines records the lines moved to or from the file in its history. The global
+// and the people histories do not change since the moved lines keep their ages and authors.
+func (analyser *BurndownAnalysis) updateMovedLines(name string, author int, value int, delta int) {
+	currentTime := analyser.packPersonWithDay(author, analyser.day)
+	// the global history must cover the days of the file histories, see Finalize()
+	analyser.updateGlobal(currentTime, value, 0)
+	if !analyser.TrackFiles {
+		return
+	}
+	if history := analyser.fileHistories[name]; history != nil {
+		analyser.updateFile(history, currentTime, value, delta)
+	}
+}
+
+// copiedFile is the line tree of the copy source before the commit.
+type copiedFile struct {
+	items.FileCopy
+	keys []int
+	vals []int
+}
+
+// snapshotCopies captures the line trees of the tracked copy sources.
+func (analyser *BurndownAnalysis) snapshotCopies(copies map[string]items.FileCopy) map[string]copiedFile {
+	if len(copies) == 0 || analyser.day == burndown.TreeMergeMark {
+		return nil
+	}
+	result := map[string]copiedFile{}
+	for name, fileCopy := range copies {
+		file, exists := analyser.files[fileCopy.From.Name]
+		if !exists {
+			continue
+		}
+		copied := copiedFile{FileCopy: fileCopy}
+		file.ForEach(func(line, value int) {
+			if value < 0 {
+				// ForEach() reports TreeEnd as -1
+				value = burndown.TreeEnd
+			}
+			copied.keys = append(copied.keys, line)
+			copied.vals = append(copied.vals, value)
+		})
+		result[name] = copied
+	}
+	return result
+}
+
+// handleCopy inserts the copied file which inherits the line ages and the authors
+// of the source, then applies the edits made after copying.
+func (analyser *BurndownAnalysis) handleCopy(
+	change *object.Change, author int, cache map[plumbing.Hash]*items.CachedBlob,
+	copied copiedFile) error {
+
+	name := change.To.Name
+	if copied.keys[len(copied.keys)-1] != copied.Diff.OldLinesOfCode {
+		// the source is tracked differently, e.g. it was analysed with a different blob
+		return analyser.handleInsertion(change, author, cache)
+	}
+	if _, exists := analyser.files[name]; exists {
+		return fmt.Errorf("file %s already exists", name)
+	}
+	updaters := analyser.fileUpdaters(name)
+	for i := 0; i < len(copied.keys)-1; i++ {
+		previousTime := copied.vals[i]
+		if previousTime&burndown.TreeMergeMark == burndown.TreeMergeMark {
+			continue
+		}
+		// the copied lines keep their authors, so the ownership matrix does not change hands
+		person, _ := analyser.unpackPersonWithDay(previousTime)
+		currentTime := analyser.packPersonWithDay(person, analyser.day)
+		for _, update := range updaters {
+			update(currentTime, previousTime, copied.keys[i+1]-copied.keys[i])
+		}
+	}
+	file := burndown.NewFileFromTree(copied.keys, copied.vals, analyser.fileAllocator, updaters...)
+	analyser.files[name] = file
+	return analyser.applyDiff(
+		&object.Change{From: copied.From, To: change.To}, file, author, copied.Diff, nil, nil)
+}
+
+// movedLines are the lines moved by the current commit with their values before the commit.
+type movedLines struct {
+	// from maps the old file names to the old line indexes.
+	from map[string]map[int]int
+	// to maps the new file names to the new line indexes.
+	to map[string]map[int]int
+}
+
+// snapshotMoves captures the values of the moved lines before they are deleted.
+// The copies are skipped because they inherit the lines of their sources anyway.
+func (analyser *BurndownAnalysis) snapshotMoves(
+	blocks []items.MovedBlock, copiedFiles map[string]copiedFile) movedLines {
+
+	moves := movedLines{from: map[string]map[int]int{}, to: map[string]map[int]int{}}
+	if len(blocks) == 0 || analyser.day == burndown.TreeMergeMark {
+		return moves
+	}
+	values := map[string][]int{}
+	for _, block := range blocks {
+		if _, copied := copiedFiles[block.To.Name]; copied {
+			continue
+		}
+		lines, exists := values[block.From.Name]
+		if !exists {
+			if file := analyser.files[block.From.Name]; file != nil {
+				previousLine, previousValue := 0, 0
+				file.ForEach(func(line, value int) {
+					for ; previousLine < line; previousLine++ {
+						lines = append(lines, previousValue)
+					}
+					previousValue = value
+				})
+			}
+			values[block.From.Name] = lines
+		}
+		for i := 0; i < block.Length; i++ {
+			line := block.From.Line + i
+			if line >= len(lines) || lines[line]&burndown.TreeMergeMark == burndown.TreeMergeMark {
+				continue
+			}
+			if moves.from[block.From.Name] == nil {
+				moves.from[block.From.Name] = map[int]int{}
+			}
+			if moves.to[block.To.Name] == nil {
+				moves.to[block.To.Name] = map[int]int{}
+			}
+			moves.from[block.From.Name][line] = lines[line]
+			moves.to[block.To.Name][block.To.Line+i] = lines[line]
+		}
+	}
+	return moves
+}
+
 func (analyser *BurndownAnalysis) handleRename(from, to string) error {
 	if from == to {
 		return nil
@@ -2,11 +2,13 @@ package leaves
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"path"
 	"testing"
+	"time"
 
 	"gopkg.in/src-d/hercules.v8/internal/burndown"
 	"gopkg.in/src-d/hercules.v8/internal/core"
@@ -35,10 +37,12 @@ func AddHash(t *testing.T, cache map[plumbing.Hash]*items.CachedBlob, hash strin
 func TestBurndownMeta(t *testing.T) {
 	bd := BurndownAnalysis{}
 	assert.Equal(t, bd.Name(), "Burndown")
-	assert.Len(t, bd.Provides(), 0)
+	assert.Equal(t, []string{DependencyBurndownLines}, bd.Provides())
 	required := [...]string{
 		items.DependencyFileDiff, items.DependencyTreeChanges, items.DependencyBlobCache,
-		items.DependencyDay, identity.DependencyAuthor}
+		items.DependencyDay, identity.DependencyAuthor, items.DependencyBaseTreeChanges,
+		identity.DependencyAuthors, items.DependencyCopies, items.DependencyIgnoredCommit,
+		items.DependencyMovedBlocks}
 	for _, name := range required {
 		assert.Contains(t, bd.Requires(), name)
 	}
@@ -80,6 +84,7 @@ func TestBurndownConfigure(t *testing.T) {
 	assert.Equal(t, bd.HibernationDirectory, "xxx")
 	assert.Equal(t, bd.Debug, true)
 	assert.Equal(t, bd.reversedPeopleDict, bd.Requires())
+	assert.Equal(t, &bd, facts[FactBurndownAnalysis])
 	facts[ConfigBurndownTrackPeople] = false
 	facts[identity.FactIdentityDetectorPeopleCount] = 50
 	assert.Nil(t, bd.Configure(facts))
@@ -200,7 +205,7 @@ func TestBurndownConsumeFinalize(t *testing.T) {
 		"cce947b98a050c6d356bc6ba95030254914027b1"))
 	deps[core.DependencyIsMerge] = false
 	result, err = bd.Consume(deps)
-	assert.Nil(t, result)
+	assert.Equal(t, map[string]interface{}{DependencyBurndownLines: &bd}, result)
 	assert.Nil(t, err)
 	assert.Equal(t, bd.previousDay, 0)
 	assert.Len(t, bd.files, 3)
@@ -302,7 +307,7 @@ func TestBurndownConsumeFinalize(t *testing.T) {
 	assert.Nil(t, err)
 	deps[items.DependencyFileDiff] = result[items.DependencyFileDiff]
 	result, err = bd.Consume(deps)
-	assert.Nil(t, result)
+	assert.Equal(t, map[string]interface{}{DependencyBurndownLines: &bd}, result)
 	assert.Nil(t, err)
 	assert.Equal(t, bd.previousDay, 30)
 	assert.Len(t, bd.files, 2)
@@ -358,6 +363,48 @@ func TestBurndownConsumeFinalize(t *testing.T) {
 	}
 }
 
+func TestBurndownConsumePartialHistory(t *testing.T) {
+	bd := BurndownAnalysis{
+		Granularity:  30,
+		Sampling:     30,
+		PeopleNumber: 1,
+		TrackFiles:   true,
+	}
+	assert.Nil(t, bd.Initialize(test.Repository))
+	// the parent fbe766ffdc3f87f6affddc051c6f8b419beea6a2 is not analysed
+	td := &items.TreeDiff{PartialHistory: true}
+	assert.Nil(t, td.Initialize(test.Repository))
+	bc := &items.BlobCache{}
+	assert.Nil(t, bc.Initialize(test.Repository))
+	deps := map[string]interface{}{}
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"2b1ed978194a94edeabbca6de7ff3b5771d4d665"))
+	deps[core.DependencyIsMerge] = false
+	deps[identity.DependencyAuthor] = 0
+	deps[items.DependencyDay] = 0
+	result, err := td.Consume(deps)
+	assert.Nil(t, err)
+	deps[items.DependencyTreeChanges] = result[items.DependencyTreeChanges]
+	deps[items.DependencyBaseTreeChanges] = result[items.DependencyBaseTreeChanges]
+	result, err = bc.Consume(deps)
+	assert.Nil(t, err)
+	deps[items.DependencyBlobCache] = result[items.DependencyBlobCache]
+	result, err = fixtures.FileDiff().Consume(deps)
+	assert.Nil(t, err)
+	deps[items.DependencyFileDiff] = result[items.DependencyFileDiff]
+	result, err = bd.Consume(deps)
+	assert.Equal(t, map[string]interface{}{DependencyBurndownLines: &bd}, result)
+	assert.Nil(t, err)
+	// linux.png is binary
+	assert.Len(t, bd.files, 20)
+	assert.NotContains(t, bd.files, "analyser.go")
+	assert.Equal(t, bd.files["rbtree.go"].Len(), 703)
+	assert.Equal(t, bd.files["cmd/hercules/main.go"].Len(), 207)
+	assert.Equal(t, bd.globalHistory[0][0], int64(3763))
+	// the lines which existed before are not attributed to anybody
+	assert.Equal(t, bd.peopleHistories[0][0][0], int64(1611))
+}
+
 func TestBurndownConsumeMergeAuthorMissing(t *testing.T) {
 	deps := map[string]interface{}{}
 	deps[items.DependencyDay] = 0
@@ -607,6 +654,322 @@ func bakeBurndownForSerialization(t *testing.T, firstAuthor, secondAuthor int) (
 	return out, &bd
 }
 
+func TestBurndownConsumeCoAuthors(t *testing.T) {
+	bd := BurndownAnalysis{
+		Granularity:  30,
+		Sampling:     30,
+		PeopleNumber: 2,
+	}
+	assert.Nil(t, bd.Initialize(test.Repository))
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 0
+	deps[identity.DependencyAuthors] = []int{0, 1}
+	deps[items.DependencyDay] = 0
+	cache := map[plumbing.Hash]*items.CachedBlob{}
+	AddHash(t, cache, "291286b4ac41952cbd1389fda66420ec03c1a9fe")
+	AddHash(t, cache, "c29112dbd697ad9b401333b80c18a63951bc18d9")
+	deps[items.DependencyBlobCache] = cache
+	treeTo, _ := test.Repository.TreeObject(plumbing.NewHash(
+		"994eac1cd07235bb9815e547a75c84265dea00f5"))
+	deps[items.DependencyTreeChanges] = object.Changes{
+		&object.Change{To: object.ChangeEntry{
+			Name: "cmd/hercules/main.go",
+			Tree: treeTo,
+			TreeEntry: object.TreeEntry{
+				Name: "cmd/hercules/main.go",
+				Mode: 0100644,
+				Hash: plumbing.NewHash("c29112dbd697ad9b401333b80c18a63951bc18d9"),
+			},
+		}},
+		&object.Change{To: object.ChangeEntry{
+			Name: ".travis.yml",
+			Tree: treeTo,
+			TreeEntry: object.TreeEntry{
+				Name: ".travis.yml",
+				Mode: 0100644,
+				Hash: plumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe"),
+			},
+		}},
+	}
+	deps[items.DependencyFileDiff] = map[string]items.FileDiffData{}
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	deps[core.DependencyIsMerge] = false
+	_, err := bd.Consume(deps)
+	assert.Nil(t, err)
+	out := bd.Finalize().(BurndownResult)
+	// the co-authors divide the lines of each file, the odd line goes to them in turn:
+	// 104 + 6 and 103 + 6
+	assert.Equal(t, int64(110), out.PeopleMatrix[0][0])
+	assert.Equal(t, int64(109), out.PeopleMatrix[1][0])
+	assert.Equal(t, int64(219), out.GlobalHistory[0][0])
+
+	// a single file is divided as well, regardless of the credit policy
+	for _, credit := range []identity.CoAuthorsCredit{
+		identity.CoAuthorsCreditDuplicate, identity.CoAuthorsCreditSplit} {
+		bd = BurndownAnalysis{}
+		assert.Nil(t, bd.Configure(map[string]interface{}{
+			ConfigBurndownGranularity:                       30,
+			ConfigBurndownSampling:                          30,
+			ConfigBurndownTrackPeople:                       true,
+			identity.FactIdentityDetectorPeopleCount:        2,
+			identity.FactIdentityDetectorReversedPeopleDict: []string{"one@srcd", "two@srcd"},
+			identity.FactIdentityDetectorCoAuthorsCredit:    credit,
+		}))
+		assert.Nil(t, bd.Initialize(test.Repository))
+		singleDeps := map[string]interface{}{}
+		for key, val := range deps {
+			singleDeps[key] = val
+		}
+		singleDeps[identity.DependencyAuthor] = 0
+		singleDeps[identity.DependencyAuthors] = []int{0, 1}
+		singleDeps[items.DependencyTreeChanges] = deps[items.DependencyTreeChanges].(object.Changes)[1:]
+		_, err = bd.Consume(singleDeps)
+		assert.Nil(t, err)
+		out = bd.Finalize().(BurndownResult)
+		assert.Equal(t, int64(6), out.PeopleMatrix[0][0], credit)
+		assert.Equal(t, int64(6), out.PeopleMatrix[1][0], credit)
+		assert.Equal(t, [][]int64{{6}}, out.PeopleHistories[0], credit)
+		assert.Equal(t, [][]int64{{6}}, out.PeopleHistories[1], credit)
+	}
+
+	// excluded bot: the lines belong to the missing author
+	assert.Nil(t, bd.Initialize(test.Repository))
+	deps[identity.DependencyAuthor] = identity.AuthorMissing
+	deps[identity.DependencyAuthors] = []int{}
+	_, err = bd.Consume(deps)
+	assert.Nil(t, err)
+	out = bd.Finalize().(BurndownResult)
+	assert.Equal(t, int64(0), out.PeopleMatrix[0][0])
+	assert.Equal(t, int64(0), out.PeopleMatrix[1][0])
+	assert.Equal(t, int64(219), out.GlobalHistory[0][0])
+}
+
+func TestBurndownConsumeCopy(t *testing.T) {
+	bd := BurndownAnalysis{
+		Granularity:  30,
+		Sampling:     30,
+		PeopleNumber: 2,
+	}
+	assert.Nil(t, bd.Initialize(test.Repository))
+	cache := map[plumbing.Hash]*items.CachedBlob{}
+	AddHash(t, cache, "baa64828831d174f40140e4b3cfa77d1e917a2c1")
+	AddHash(t, cache, "29c9fafd6a2fae8cd20298c3f60115bc31a4c0f2")
+	linesFrom, _ := cache[plumbing.NewHash("baa64828831d174f40140e4b3cfa77d1e917a2c1")].CountLines()
+	linesTo, _ := cache[plumbing.NewHash("29c9fafd6a2fae8cd20298c3f60115bc31a4c0f2")].CountLines()
+	treeFrom, _ := test.Repository.TreeObject(plumbing.NewHash(
+		"96c6ece9b2f3c7c51b83516400d278dea5605100"))
+	treeTo, _ := test.Repository.TreeObject(plumbing.NewHash(
+		"251f2094d7b523d5bcc60e663b6cf38151bf8844"))
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 0
+	deps[items.DependencyDay] = 0
+	deps[items.DependencyBlobCache] = cache
+	deps[items.DependencyTreeChanges] = object.Changes{
+		&object.Change{To: object.ChangeEntry{
+			Name: "analyser.go",
+			Tree: treeFrom,
+			TreeEntry: object.TreeEntry{
+				Name: "analyser.go",
+				Mode: 0100644,
+				Hash: plumbing.NewHash("baa64828831d174f40140e4b3cfa77d1e917a2c1"),
+			},
+		}},
+	}
+	deps[items.DependencyFileDiff] = map[string]items.FileDiffData{}
+	deps[core.DependencyIsMerge] = false
+	_, err := bd.Consume(deps)
+	assert.Nil(t, err)
+
+	// 2b1ed978194a94edeabbca6de7ff3b5771d4d665 if analyser.go was kept
+	deps[identity.DependencyAuthor] = 1
+	deps[items.DependencyDay] = 30
+	deps[items.DependencyTreeChanges] = object.Changes{
+		&object.Change{To: object.ChangeEntry{
+			Name: "burndown.go",
+			Tree: treeTo,
+			TreeEntry: object.TreeEntry{
+				Name: "burndown.go",
+				Mode: 0100644,
+				Hash: plumbing.NewHash("29c9fafd6a2fae8cd20298c3f60115bc31a4c0f2"),
+			},
+		}},
+	}
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"2b1ed978194a94edeabbca6de7ff3b5771d4d665"))
+	ra := &items.RenameAnalysis{SimilarityThreshold: 37, CopyDetection: items.CopyDetectionHarder}
+	assert.Nil(t, ra.Initialize(test.Repository))
+	result, err := ra.Consume(deps)
+	assert.Nil(t, err)
+	copies := result[items.DependencyCopies].(map[string]items.FileCopy)
+	assert.Equal(t, "analyser.go", copies["burndown.go"].From.Name)
+	deps[items.DependencyCopies] = copies
+	snapshot := bd.files["analyser.go"].Dump()
+	_, err = bd.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, snapshot, bd.files["analyser.go"].Dump())
+	assert.Equal(t, linesTo, bd.files["burndown.go"].Len())
+	ownership := bd.fileOwnership(bd.files["burndown.go"])
+	assert.True(t, ownership[0] > 0)
+	assert.True(t, ownership[1] > 0)
+	assert.Equal(t, linesTo, ownership[0]+ownership[1])
+	out := bd.Finalize().(BurndownResult)
+	assert.Equal(t, int64(linesFrom+ownership[0]), out.GlobalHistory[1][0])
+	assert.Equal(t, int64(ownership[1]), out.GlobalHistory[1][1])
+
+	// no copies - the lines are new
+	assert.Nil(t, bd.Initialize(test.Repository))
+	deps[items.DependencyDay] = 0
+	deps[identity.DependencyAuthor] = 0
+	deps[items.DependencyTreeChanges] = object.Changes{
+		&object.Change{To: copies["burndown.go"].From}}
+	deps[items.DependencyCopies] = map[string]items.FileCopy{}
+	_, err = bd.Consume(deps)
+	assert.Nil(t, err)
+	deps[items.DependencyDay] = 30
+	deps[identity.DependencyAuthor] = 1
+	deps[items.DependencyTreeChanges] = object.Changes{
+		&object.Change{To: copies["burndown.go"].To}}
+	_, err = bd.Consume(deps)
+	assert.Nil(t, err)
+	out = bd.Finalize().(BurndownResult)
+	assert.Equal(t, int64(linesFrom), out.GlobalHistory[1][0])
+	assert.Equal(t, int64(linesTo), out.GlobalHistory[1][1])
+}
+
+func TestBurndownConsumeIgnored(t *testing.T) {
+	for _, ignored := range []bool{false, true} {
+		bd := BurndownAnalysis{
+			Granularity:  30,
+			Sampling:     30,
+			PeopleNumber: 2,
+		}
+		assert.Nil(t, bd.Initialize(test.Repository))
+		cache := map[plumbing.Hash]*items.CachedBlob{}
+		AddHash(t, cache, "c29112dbd697ad9b401333b80c18a63951bc18d9")
+		AddHash(t, cache, "f7d918ec500e2f925ecde79b51cc007bac27de72")
+		from := object.ChangeEntry{
+			Name: "cmd/hercules/main.go",
+			TreeEntry: object.TreeEntry{
+				Name: "cmd/hercules/main.go",
+				Mode: 0100644,
+				Hash: plumbing.NewHash("c29112dbd697ad9b401333b80c18a63951bc18d9"),
+			},
+		}
+		to := from
+		to.TreeEntry.Hash = plumbing.NewHash("f7d918ec500e2f925ecde79b51cc007bac27de72")
+		deps := map[string]interface{}{}
+		deps[identity.DependencyAuthor] = 0
+		deps[items.DependencyDay] = 0
+		deps[items.DependencyBlobCache] = cache
+		deps[items.DependencyTreeChanges] = object.Changes{&object.Change{To: from}}
+		deps[items.DependencyFileDiff] = map[string]items.FileDiffData{}
+		deps[core.DependencyIsMerge] = false
+		_, err := bd.Consume(deps)
+		assert.Nil(t, err)
+
+		deps[identity.DependencyAuthor] = 1
+		deps[items.DependencyDay] = 30
+		deps[items.DependencyIgnoredCommit] = ignored
+		deps[items.DependencyTreeChanges] = object.Changes{&object.Change{From: from, To: to}}
+		result, err := fixtures.FileDiff().Consume(deps)
+		assert.Nil(t, err)
+		diff := result[items.DependencyFileDiff].(map[string]items.FileDiffData)[to.Name]
+		deps[items.DependencyFileDiff] = result[items.DependencyFileDiff]
+		_, err = bd.Consume(deps)
+		assert.Nil(t, err)
+		file := bd.files[to.Name]
+		assert.Equal(t, diff.NewLinesOfCode, file.Len())
+		ownership := bd.fileOwnership(file)
+		out := bd.Finalize().(BurndownResult)
+		if ignored {
+			// the lines keep their authors and ages, only the removed lines are gone
+			assert.Equal(t, map[int]int{0: diff.NewLinesOfCode}, ownership)
+			assert.Equal(t, int64(diff.NewLinesOfCode), out.GlobalHistory[1][0])
+			assert.Equal(t, int64(0), out.GlobalHistory[1][1])
+		} else {
+			assert.True(t, ownership[1] > 0)
+			assert.Equal(t, int64(ownership[1]), out.GlobalHistory[1][1])
+		}
+	}
+}
+
+func TestBurndownConsumeMoved(t *testing.T) {
+	cache := map[plumbing.Hash]*items.CachedBlob{}
+	entry := func(name, text string) object.ChangeEntry {
+		blob := &items.CachedBlob{Data: []byte(text)}
+		blob.Hash = plumbing.ComputeHash(plumbing.BlobObject, blob.Data)
+		cache[blob.Hash] = blob
+		return object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+			Name: name, Mode: 0100644, Hash: blob.Hash}}
+	}
+	alpha := "func Alpha(x int) int {\n\ty := x * 2\n\tz := y - 1\n\treturn z + 1\n}\n"
+	beta := "func Beta() {\n\tprintln(\"beta\")\n}\n"
+	one := "func One() {\n\tprintln(\"one\")\n\tprintln(\"uno\")\n}\n"
+	two := "func Two() {\n\tprintln(\"two\")\n\tprintln(\"dos\")\n}\n"
+	aOld := entry("a.go", "package a\n\n"+alpha+"\n"+beta)
+	aNew := entry("a.go", "package a\n\n"+beta)
+	b := entry("b.go", "package b\n\n// Alpha was moved here.\n"+alpha)
+	cOld := entry("c.go", "package c\n\n"+one+"\n"+two)
+	cNew := entry("c.go", "package c\n\n"+two+"\n"+one)
+
+	for _, detect := range []bool{true, false} {
+		bd := BurndownAnalysis{
+			Granularity:  30,
+			Sampling:     30,
+			PeopleNumber: 2,
+			TrackFiles:   true,
+		}
+		assert.Nil(t, bd.Initialize(test.Repository))
+		deps := map[string]interface{}{}
+		deps[identity.DependencyAuthor] = 0
+		deps[items.DependencyDay] = 0
+		deps[items.DependencyBlobCache] = cache
+		deps[items.DependencyTreeChanges] = object.Changes{
+			&object.Change{To: aOld}, &object.Change{To: cOld}}
+		deps[items.DependencyFileDiff] = map[string]items.FileDiffData{}
+		deps[core.DependencyIsMerge] = false
+		_, err := bd.Consume(deps)
+		assert.Nil(t, err)
+
+		deps[identity.DependencyAuthor] = 1
+		deps[items.DependencyDay] = 30
+		deps[items.DependencyTreeChanges] = object.Changes{
+			&object.Change{From: aOld, To: aNew}, &object.Change{To: b},
+			&object.Change{From: cOld, To: cNew}}
+		result, err := fixtures.FileDiff().Consume(deps)
+		assert.Nil(t, err)
+		deps[items.DependencyFileDiff] = result[items.DependencyFileDiff]
+		moves := &items.MoveDetection{Enabled: detect}
+		assert.Nil(t, moves.Initialize(test.Repository))
+		result, err = moves.Consume(deps)
+		assert.Nil(t, err)
+		deps[items.DependencyMovedBlocks] = result[items.DependencyMovedBlocks]
+		_, err = bd.Consume(deps)
+		assert.Nil(t, err)
+		for name, lines := range map[string]int{"a.go": 5, "b.go": 8, "c.go": 11} {
+			assert.Equal(t, lines, bd.files[name].Len(), name)
+		}
+		ownershipB := bd.fileOwnership(bd.files["b.go"])
+		ownershipC := bd.fileOwnership(bd.files["c.go"])
+		out := bd.Finalize().(BurndownResult)
+		if detect {
+			// Alpha() keeps its author and age, only the comment and the blank lines are new
+			assert.Equal(t, map[int]int{0: 5, 1: 3}, ownershipB)
+			assert.Equal(t, map[int]int{0: 10, 1: 1}, ownershipC)
+			assert.Equal(t, []int64{20, 4}, out.GlobalHistory[1])
+			assert.Equal(t, []int64{5, 0}, out.FileHistories["a.go"][1])
+			assert.Equal(t, []int64{5, 3}, out.FileHistories["b.go"][1])
+			assert.Equal(t, []int64{10, 1}, out.FileHistories["c.go"][1])
+			assert.Equal(t, []int64{22, 0, 0, -2}, out.PeopleMatrix[0])
+		} else {
+			assert.Equal(t, map[int]int{1: 8}, ownershipB)
+			assert.Equal(t, map[int]int{0: 6, 1: 5}, ownershipC)
+			assert.Equal(t, []int64{11, 13}, out.GlobalHistory[1])
+		}
+	}
+}
+
 func TestBurndownSerialize(t *testing.T) {
 	out, _ := bakeBurndownForSerialization(t, 0, 1)
 	bd := &BurndownAnalysis{}
@@ -694,6 +1057,146 @@ func TestBurndownSerialize(t *testing.T) {
 	assert.Equal(t, msg.PeopleInteraction.Indptr, indptr[:])
 }
 
+func TestBurndownSerializeJSON(t *testing.T) {
+	out, _ := bakeBurndownForSerialization(t, 0, 1)
+	bd := &BurndownAnalysis{}
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, bd.SerializeJSON(out, buffer))
+	msg := burndownJSON{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &msg))
+	assert.Equal(t, 30, msg.Granularity)
+	assert.Equal(t, 30, msg.Sampling)
+	assert.Equal(t, [][]int64{{1145, 0}, {464, 369}}, msg.Project)
+	assert.Len(t, msg.Files, 2)
+	assert.Equal(t, [][]int64{{926, 0}, {293, 250}}, msg.Files["burndown.go"])
+	assert.Equal(t, [][]int64{{207, 0}, {171, 119}}, msg.Files["cmd/hercules/main.go"])
+	assert.Equal(t, map[string]map[int]int{
+		"burndown.go":          {0: 293, 1: 250},
+		"cmd/hercules/main.go": {0: 171, 1: 119},
+	}, msg.FilesOwnership)
+	assert.Equal(t, []string{"one@srcd", "two@srcd"}, msg.PeopleSequence)
+	assert.Equal(t, [][][]int64{
+		{{1145, 0}, {464, 0}},
+		{{0, 0}, {0, 369}},
+	}, msg.People)
+	assert.Equal(t, [][]int64{{1145, 0, 0, -681}, {369, 0, 0, 0}}, msg.PeopleInteraction)
+}
+
+func TestBurndownTeams(t *testing.T) {
+	_, bd := bakeBurndownForSerialization(t, 0, 1)
+	teams := fixtureTeams(t, "all: [one@srcd, two@srcd]\n", map[string]int{"one@srcd": 0, "two@srcd": 1})
+	assert.Nil(t, bd.Configure(map[string]interface{}{
+		ConfigBurndownTrackPeople:                       true,
+		identity.FactIdentityDetectorPeopleCount:        2,
+		identity.FactIdentityDetectorReversedPeopleDict: bd.reversedPeopleDict,
+		identity.FactIdentityDetectorTeams:              teams,
+	}))
+	out := bd.Finalize().(BurndownResult)
+	assert.Equal(t, []string{"all", identity.TeamMissingName}, out.reversedTeamsDict)
+	assert.Equal(t, []DenseHistory{
+		{{1145, 0}, {464, 369}},
+		{{0, 0}, {0, 0}},
+	}, out.TeamHistories)
+	// the overwrites of the team members by each other are on the diagonal
+	assert.Equal(t, DenseHistory{{1514, 0, -681, 0}, {0, 0, 0, 0}}, out.TeamMatrix)
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, bd.Serialize(out, false, buffer))
+	assert.Contains(t, buffer.String(), `  teams_sequence:
+    - "all"
+    - "<unassigned>"
+  teams:
+    "all": |-
+      1145    0
+       464  369
+    "<unassigned>": |-
+      0 0
+      0 0
+  teams_interaction: |-
+    1514    0 -681    0
+       0    0    0    0
+`)
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, bd.SerializeJSON(out, buffer))
+	msg := burndownJSON{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &msg))
+	assert.Equal(t, []string{"all", identity.TeamMissingName}, msg.TeamsSequence)
+	assert.Equal(t, [][][]int64{{{1145, 0}, {464, 369}}, {{0, 0}, {0, 0}}}, msg.Teams)
+	assert.Equal(t, [][]int64{{1514, 0, -681, 0}, {0, 0, 0, 0}}, msg.TeamsInteraction)
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, bd.Serialize(out, true, buffer))
+	rawout2, err := bd.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	out2 := rawout2.(BurndownResult)
+	assert.Equal(t, out.TeamHistories, out2.TeamHistories)
+	assert.Equal(t, out.TeamMatrix, out2.TeamMatrix)
+	assert.Equal(t, out.reversedTeamsDict, out2.reversedTeamsDict)
+}
+
+func TestBurndownMergeTeams(t *testing.T) {
+	h1 := [][]int64{
+		{50, 0, 0},
+		{40, 80, 0},
+		{30, 50, 70},
+	}
+	h2 := [][]int64{
+		{900, 0, 0},
+		{1100, 400, 0},
+		{900, 750, 100},
+		{800, 600, 600},
+	}
+	res1 := BurndownResult{
+		GlobalHistory:     h1,
+		TeamHistories:     [][][]int64{h1, h1},
+		reversedTeamsDict: []string{"a", identity.TeamMissingName},
+		sampling:          15,
+		granularity:       20,
+	}
+	c1 := core.CommonAnalysisResult{
+		BeginTime:     600566400, // 1989 Jan 12
+		EndTime:       604540800, // 1989 February 27
+		CommitsNumber: 10,
+		RunTime:       100000,
+	}
+	res2 := BurndownResult{
+		GlobalHistory:     h2,
+		TeamHistories:     [][][]int64{h2, h2},
+		reversedTeamsDict: []string{"b", identity.TeamMissingName},
+		sampling:          14,
+		granularity:       19,
+	}
+	c2 := core.CommonAnalysisResult{
+		BeginTime:     601084800, // 1989 Jan 18
+		EndTime:       605923200, // 1989 March 15
+		CommitsNumber: 10,
+		RunTime:       100000,
+	}
+	bd := BurndownAnalysis{}
+	merged := bd.MergeResults(res1, res2, &c1, &c2).(BurndownResult)
+	assert.Equal(t, []string{"a", identity.TeamMissingName, "b"}, merged.reversedTeamsDict)
+	assert.Len(t, merged.TeamHistories, 3)
+	assert.Equal(t, [][]int64{
+		{46, 0, 0, 0},
+		{43, 66, 0, 0},
+		{30, 106, 39, 0},
+		{28, 46, 75, 0},
+		{28, 46, 75, 0},
+	}, merged.TeamHistories[0])
+	assert.Equal(t, [][]int64{
+		{560, 0, 0, 0},
+		{851, 572, 0, 0},
+		{704, 995, 217, 0},
+		{605, 767, 670, 0},
+		{575, 709, 685, 178},
+	}, merged.TeamHistories[1])
+	assert.Nil(t, merged.TeamMatrix)
+	assert.Nil(t, merged.PeopleHistories)
+	res2.TeamHistories = nil
+	merged = bd.MergeResults(res1, res2, &c1, &c2).(BurndownResult)
+	assert.Nil(t, merged.TeamHistories)
+}
+
 func TestBurndownSerializeAuthorMissing(t *testing.T) {
 	out, _ := bakeBurndownForSerialization(t, 0, identity.AuthorMissing)
 	bd := &BurndownAnalysis{}
@@ -1349,6 +1852,46 @@ func TestBurndownHibernateBootSerialize(t *testing.T) {
 	assert.Empty(t, bd.hibernatedFileName)
 }
 
+func TestBurndownCheckpointRestore(t *testing.T) {
+	out, bd := bakeBurndownForSerialization(t, 0, 1)
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, bd.Checkpoint(buffer))
+	restored := &BurndownAnalysis{
+		Granularity:  30,
+		Sampling:     30,
+		PeopleNumber: 3,
+		TrackFiles:   true,
+	}
+	assert.Nil(t, restored.Initialize(test.Repository))
+	assert.Nil(t, restored.Restore(bytes.NewReader(buffer.Bytes()), map[string]interface{}{}))
+	assert.Len(t, restored.files, len(bd.files))
+	for name, file := range bd.files {
+		assert.Equal(t, file.Dump(), restored.files[name].Dump(), name)
+	}
+	assert.Equal(t, bd.day, restored.day)
+	assert.Equal(t, bd.previousDay, restored.previousDay)
+	restored.reversedPeopleDict = []string{"one@srcd", "two@srcd", "three@srcd"}
+	result := restored.Finalize().(BurndownResult)
+	assert.Equal(t, out.GlobalHistory, result.GlobalHistory)
+	assert.Equal(t, out.FileHistories, result.FileHistories)
+	assert.Equal(t, out.FileOwnership, result.FileOwnership)
+	assert.Equal(t, out.PeopleHistories, result.PeopleHistories[:2])
+	for i, row := range out.PeopleMatrix {
+		// the matrix rows are extended with the new person
+		assert.Equal(t, row[:4], result.PeopleMatrix[i][:4])
+	}
+
+	restored = &BurndownAnalysis{Granularity: 30, Sampling: 15, PeopleNumber: 2, TrackFiles: true}
+	assert.Nil(t, restored.Initialize(test.Repository))
+	assert.NotNil(t, restored.Restore(bytes.NewReader(buffer.Bytes()), map[string]interface{}{}))
+	restored = &BurndownAnalysis{Granularity: 30, Sampling: 30, PeopleNumber: 1, TrackFiles: true}
+	assert.Nil(t, restored.Initialize(test.Repository))
+	assert.NotNil(t, restored.Restore(bytes.NewReader(buffer.Bytes()), map[string]interface{}{}))
+	restored = &BurndownAnalysis{Granularity: 30, Sampling: 30, PeopleNumber: 2}
+	assert.Nil(t, restored.Initialize(test.Repository))
+	assert.NotNil(t, restored.Restore(bytes.NewReader(buffer.Bytes()), map[string]interface{}{}))
+}
+
 func TestBurndownAddBurndownMatrix(t *testing.T) {
 	h := DenseHistory{
 		[]int64{13430, 0, 0, 0},
@@ -1462,6 +2005,58 @@ func TestBurndownMergeMatrices(t *testing.T) {
 	}
 }
 
+func TestBurndownMergeTickSizes(t *testing.T) {
+	history := DenseHistory{
+		{100, 0, 0, 0},
+		{100, 100, 0, 0},
+		{100, 100, 100, 0},
+		{100, 100, 100, 100},
+	}
+	res1 := BurndownResult{
+		GlobalHistory: history,
+		sampling:      24,
+		granularity:   24,
+	}
+	c1 := core.CommonAnalysisResult{
+		BeginTime:     600566400, // 1989 Jan 12
+		EndTime:       600566400 + 4*24*3600,
+		CommitsNumber: 10,
+		RunTime:       100000,
+		TickSize:      time.Hour,
+	}
+	res2 := BurndownResult{
+		GlobalHistory: history,
+		sampling:      1,
+		granularity:   1,
+	}
+	c2 := c1
+	c2.TickSize = 24 * time.Hour
+	burndown := BurndownAnalysis{}
+	merged := burndown.MergeResults(res1, res2, &c1, &c2).(BurndownResult)
+	assert.Equal(t, 1, merged.sampling)
+	assert.Equal(t, 1, merged.granularity)
+	assert.Len(t, merged.GlobalHistory, 4)
+	for y, row := range merged.GlobalHistory {
+		for x, v := range row {
+			assert.InDelta(t, history[y][x]*2, v, 1, fmt.Sprintf("y=%d x=%d", y, x))
+		}
+	}
+	assert.Equal(t, 2, convertTicks(48, 1.0/24))
+	assert.Equal(t, 1, convertTicks(1, 1.0/24))
+	assert.Equal(t, 14, convertTicks(2, 7))
+	// 30 hours are converted to 1 day but the span is preserved
+	converted := BurndownResult{
+		GlobalHistory: DenseHistory{{30, 0}, {30, 30}, {30, 30}, {30, 30}},
+		sampling:      30,
+		granularity:   30,
+	}.convertTickSize(time.Hour, 24*time.Hour)
+	assert.Equal(t, 1, converted.sampling)
+	assert.Equal(t, 1, converted.granularity)
+	assert.Len(t, converted.GlobalHistory, 5)
+	assert.Equal(t, []int64{24, 6, 0}, converted.GlobalHistory[0])
+	assert.Equal(t, []int64{24, 24, 12}, converted.GlobalHistory[4])
+}
+
 func TestBurndownMergePeopleHistories(t *testing.T) {
 	h1 := [][]int64{
 		{50, 0, 0},
@@ -0,0 +1,15 @@
+package leaves
+
+import (
+	"gopkg.in/src-d/hercules.v8/internal/plumbing/identity"
+)
+
+// commitAuthors returns all the authors of the analysed commit, the main author is the first.
+// The result is empty if the commit is not credited to anybody, e.g. it was made by an excluded
+// bot. It falls back to the single identity.DependencyAuthor if identity.DependencyAuthors is absent.
+func commitAuthors(deps map[string]interface{}) []int {
+	if authors, exists := deps[identity.DependencyAuthors].([]int); exists {
+		return authors
+	}
+	return []int{deps[identity.DependencyAuthor].(int)}
+}
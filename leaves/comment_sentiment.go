@@ -3,6 +3,7 @@
 package leaves
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -257,6 +258,12 @@ func (sent *CommentSentimentAnalysis) Serialize(result interface{}, binary bool,
 	return nil
 }
 
+// SerializeJSON converts the analysis result as returned by Finalize() to JSON.
+func (sent *CommentSentimentAnalysis) SerializeJSON(result interface{}, writer io.Writer) error {
+	sentimentResult := result.(CommentSentimentResult)
+	return sent.serializeJSON(&sentimentResult, writer)
+}
+
 func (sent *CommentSentimentAnalysis) serializeText(result *CommentSentimentResult, writer io.Writer) {
 	days := make([]int, 0, len(result.EmotionsByDay))
 	for day := range result.EmotionsByDay {
@@ -275,6 +282,39 @@ func (sent *CommentSentimentAnalysis) serializeText(result *CommentSentimentResu
 	}
 }
 
+// commentSentimentJSON is the JSON schema of CommentSentimentResult, see doc/JSON.md.
+type commentSentimentJSON struct {
+	Days map[int]sentimentJSON `json:"days"`
+}
+
+// sentimentJSON is the JSON schema of the sentiment of a single day, see doc/JSON.md.
+type sentimentJSON struct {
+	Value    float32  `json:"value"`
+	Commits  []string `json:"commits"`
+	Comments []string `json:"comments"`
+}
+
+func (sent *CommentSentimentAnalysis) serializeJSON(
+	result *CommentSentimentResult, writer io.Writer) error {
+	message := commentSentimentJSON{Days: map[int]sentimentJSON{}}
+	for day, val := range result.EmotionsByDay {
+		commits := make([]string, len(result.commitsByDay[day]))
+		for i, commit := range result.commitsByDay[day] {
+			commits[i] = commit.String()
+		}
+		comments := result.CommentsByDay[day]
+		if comments == nil {
+			comments = []string{}
+		}
+		message.Days[day] = sentimentJSON{
+			Value:    val,
+			Commits:  commits,
+			Comments: comments,
+		}
+	}
+	return json.NewEncoder(writer).Encode(&message)
+}
+
 func (sent *CommentSentimentAnalysis) serializeBinary(
 	result *CommentSentimentResult, writer io.Writer) error {
 	message := pb.CommentSentimentResults{
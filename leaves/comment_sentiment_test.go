@@ -4,6 +4,7 @@ package leaves
 
 import (
 	"bytes"
+	"encoding/json"
 	"log"
 	"strings"
 	"testing"
@@ -134,6 +135,23 @@ func TestCommentSentimentSerializeBinary(t *testing.T) {
 	assert.Equal(t, msg.SentimentByDay[int32(9)].Value, float32(0.5))
 }
 
+func TestCommentSentimentSerializeJSON(t *testing.T) {
+	sent := fixtureCommentSentiment()
+	result := CommentSentimentResult{
+		EmotionsByDay: map[int]float32{},
+		CommentsByDay: map[int][]string{},
+		commitsByDay:  map[int][]plumbing.Hash{},
+	}
+	result.EmotionsByDay[9] = 0.5
+	result.CommentsByDay[9] = []string{"test", "hello"}
+	result.commitsByDay[9] = []plumbing.Hash{plumbing.NewHash("4f7c7a154638a0f2468276c56188d90c9cef0dfc")}
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, sent.SerializeJSON(result, buffer))
+	assert.Equal(t, `{"days":{"9":{"value":0.5,"commits":["4f7c7a154638a0f2468276c56188d90c9cef0dfc"],`+
+		`"comments":["test","hello"]}}}
+`, buffer.String())
+}
+
 func TestCommentSentimentFinalize(t *testing.T) {
 	sent := fixtureCommentSentiment()
 	sent.commitsByDay = testSentimentCommits
@@ -0,0 +1,435 @@
+package leaves
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	"gopkg.in/src-d/hercules.v8/internal/pb"
+	items "gopkg.in/src-d/hercules.v8/internal/plumbing"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v8/internal/yaml"
+)
+
+// CommitClassesAnalysis classifies each commit by its message, e.g. "feat", "fix" or "refactor",
+// and calculates the number of commits and the line statistics through time per class
+// and per developer. The messages which follow Conventional Commits are classified by their type,
+// the rest are matched against Rules.
+type CommitClassesAnalysis struct {
+	core.NoopMerger
+	core.OneShotMergeProcessor
+	// Rules classify the commits which do not follow Conventional Commits. They are tried
+	// in order against the first line of the commit message and the first match wins.
+	Rules []CommitClassRule
+
+	// classes maps commit classes to days to developers to stats
+	classes map[string]map[int]map[int]*DevDay
+	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+	// credit is the policy of crediting the co-authors of the same commit.
+	credit identity.CoAuthorsCredit
+}
+
+// CommitClassRule assigns Class to the commits whose message subject matches Pattern.
+type CommitClassRule struct {
+	Class   string
+	Pattern *regexp.Regexp
+}
+
+// CommitClassesResult is returned by CommitClassesAnalysis.Finalize() and carries the daily
+// statistics per commit class and per developer.
+type CommitClassesResult struct {
+	// Classes is <commit class> -> <day index> -> <developer index> -> daily stats
+	Classes map[string]map[int]map[int]*DevDay
+
+	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+}
+
+const (
+	// ConfigCommitClassesRules is the name of the option to load CommitClassesAnalysis.Rules
+	// from a YAML file.
+	ConfigCommitClassesRules = "CommitClasses.Rules"
+
+	// CommitClassOther is the class of the commits which are not matched by any rule.
+	CommitClassOther = "other"
+)
+
+// conventionalCommitRegexp matches the subject of a commit which follows Conventional Commits,
+// e.g. "feat(parser)!: support arrays".
+var conventionalCommitRegexp = regexp.MustCompile(`^([a-zA-Z]+)(\([^()\r\n]*\))?!?: \S`)
+
+// conventionalCommitTypes are the recognized Conventional Commits types. Other prefixes,
+// e.g. the package names in "leaves: fix the crash", are matched against the rules instead.
+var conventionalCommitTypes = map[string]bool{
+	"build": true, "chore": true, "ci": true, "docs": true, "feat": true, "fix": true,
+	"perf": true, "refactor": true, "revert": true, "style": true, "test": true,
+}
+
+// defaultCommitClassRules are used if ConfigCommitClassesRules is not set. Each is a pair
+// of the class name and the case-insensitive regular expression.
+var defaultCommitClassRules = [][2]string{
+	{"revert", `^revert\b`},
+	{"merge", `^merge\b`},
+	{"fix", `\b((bug|hot)?fix(e[sd]|ing)?|bugs?|(close[sd]?|resolve[sd]?)\s+#\d+)\b`},
+	{"refactor", `\b(refactor(ed|ing|s)?|clean(ed)? ?up|simplif(y|ied|ies))\b`},
+	{"docs", `\b(docs?|documentation|readme|typos?)\b`},
+	{"test", `\b(tests?|testing)\b`},
+	{"feat", `^(add(ed|s)?|implement(ed|s)?|introduce[sd]?|support(ed|s)?)\b`},
+	{"issue", `#\d+\b`},
+}
+
+// Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
+func (classes *CommitClassesAnalysis) Name() string {
+	return "CommitClasses"
+}
+
+// Provides returns the list of names of entities which are produced by this PipelineItem.
+// Each produced entity will be inserted into `deps` of dependent Consume()-s according
+// to this list. Also used by core.Registry to build the global map of providers.
+func (classes *CommitClassesAnalysis) Provides() []string {
+	return []string{}
+}
+
+// Requires returns the list of names of entities which are needed by this PipelineItem.
+// Each requested entity will be inserted into `deps` of Consume(). In turn, those
+// entities are Provides() upstream.
+func (classes *CommitClassesAnalysis) Requires() []string {
+	arr := [...]string{
+		identity.DependencyAuthor, items.DependencyDay, items.DependencyLineStats,
+		identity.DependencyAuthors, items.DependencyMergeLineStats}
+	return arr[:]
+}
+
+// ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
+func (classes *CommitClassesAnalysis) ListConfigurationOptions() []core.ConfigurationOption {
+	options := [...]core.ConfigurationOption{{
+		Name: ConfigCommitClassesRules,
+		Description: "Path to the YAML file which maps the commit classes to the lists of regular " +
+			"expressions matched against the commit message subjects, e.g. \"fix: [bug, crash]\". " +
+			"The classes are tried in order. Replaces the built-in rules.",
+		Flag:    "commit-classes-rules",
+		Type:    core.PathConfigurationOption,
+		Default: ""}}
+	return options[:]
+}
+
+// Configure sets the properties previously published by ListConfigurationOptions().
+func (classes *CommitClassesAnalysis) Configure(facts map[string]interface{}) error {
+	if val, exists := facts[ConfigCommitClassesRules].(string); exists && val != "" {
+		rules, err := LoadCommitClassRules(val)
+		if err != nil {
+			return err
+		}
+		classes.Rules = rules
+	}
+	if classes.Rules == nil {
+		classes.Rules = DefaultCommitClassRules()
+	}
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		classes.reversedPeopleDict = val
+	}
+	if val, exists := facts[identity.FactIdentityDetectorCoAuthorsCredit].(identity.CoAuthorsCredit); exists {
+		classes.credit = val
+	}
+	return nil
+}
+
+// DefaultCommitClassRules returns the built-in rules which recognize reverts, merges, fixes,
+// refactorings, documentation, tests, features and issue references by keywords.
+func DefaultCommitClassRules() []CommitClassRule {
+	rules := make([]CommitClassRule, len(defaultCommitClassRules))
+	for i, rule := range defaultCommitClassRules {
+		rules[i] = CommitClassRule{Class: rule[0], Pattern: regexp.MustCompile("(?i)" + rule[1])}
+	}
+	return rules
+}
+
+// LoadCommitClassRules reads the YAML file which maps the commit classes to the lists
+// of regular expressions, for example,
+//
+//	fix: ['\bbug', '^hotfix']
+//	security:
+//	  - '\bCVE-\d+'
+//
+// The classes are tried in the order of appearance. The expressions are case-insensitive.
+func LoadCommitClassRules(path string) ([]CommitClassRule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	names, patterns, err := yaml.ReadStringLists(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", path)
+	}
+	var rules []CommitClassRule
+	for _, name := range names {
+		for _, pattern := range patterns[name] {
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s: invalid pattern of class %s", path, name)
+			}
+			rules = append(rules, CommitClassRule{Class: name, Pattern: re})
+		}
+	}
+	return rules, nil
+}
+
+// Flag for the command line switch which enables this analysis.
+func (classes *CommitClassesAnalysis) Flag() string {
+	return "commit-classes"
+}
+
+// Description returns the text which explains what the analysis is doing.
+func (classes *CommitClassesAnalysis) Description() string {
+	return "Classifies the commits by their messages (feat, fix, refactor, etc.) and calculates " +
+		"the number of commits, added, removed and changed lines per class and per developer through time."
+}
+
+// Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
+// calls. The repository which is going to be analysed is supplied as an argument.
+func (classes *CommitClassesAnalysis) Initialize(repository *git.Repository) error {
+	if classes.Rules == nil {
+		classes.Rules = DefaultCommitClassRules()
+	}
+	classes.classes = map[string]map[int]map[int]*DevDay{}
+	classes.OneShotMergeProcessor.Initialize()
+	return nil
+}
+
+// Consume runs this PipelineItem on the next commit data.
+// `deps` contain all the results from upstream PipelineItem-s as requested by Requires().
+// Additionally, DependencyCommit is always present there and represents the analysed *object.Commit.
+// This function returns the mapping with analysis results. The keys must be the same as
+// in Provides(). If there was an error, nil is returned.
+func (classes *CommitClassesAnalysis) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	if !classes.ShouldConsumeCommit(deps) {
+		return nil, nil
+	}
+	authors := commitAuthors(deps)
+	if len(authors) == 0 {
+		return nil, nil
+	}
+	files := commitFileStats(deps)
+	class := classes.classify(deps[core.DependencyCommit].(*object.Commit).Message)
+	days, exists := classes.classes[class]
+	if !exists {
+		days = map[int]map[int]*DevDay{}
+		classes.classes[class] = days
+	}
+	day := deps[items.DependencyDay].(int)
+	devsDay, exists := days[day]
+	if !exists {
+		devsDay = map[int]*DevDay{}
+		days[day] = devsDay
+	}
+	for i, author := range authors {
+		dd, exists := devsDay[author]
+		if !exists {
+			dd = &DevDay{Languages: map[string]LineStats{}}
+			devsDay[author] = dd
+		}
+		share := func(value int) int {
+			return classes.credit.Share(value, i, len(authors))
+		}
+		// a commit cannot be divided, so it counts for every author
+		dd.Commits++
+		for _, file := range files {
+			dd.add(LineStats{
+				Added:   share(file.Added),
+				Removed: share(file.Removed),
+				Changed: share(file.Changed),
+				Moved:   share(file.Moved),
+			}, file.Language)
+		}
+	}
+	return nil, nil
+}
+
+// classify returns the class of the commit message: the Conventional Commits type if the subject
+// follows the specification, otherwise the class of the first matching rule or CommitClassOther.
+func (classes *CommitClassesAnalysis) classify(message string) string {
+	subject := strings.TrimSpace(message)
+	if newline := strings.IndexAny(subject, "\r\n"); newline >= 0 {
+		subject = subject[:newline]
+	}
+	if match := conventionalCommitRegexp.FindStringSubmatch(subject); match != nil {
+		if commitType := strings.ToLower(match[1]); conventionalCommitTypes[commitType] {
+			return commitType
+		}
+	}
+	for _, rule := range classes.Rules {
+		if rule.Pattern.MatchString(subject) {
+			return rule.Class
+		}
+	}
+	return CommitClassOther
+}
+
+// Finalize returns the result of the analysis. Further Consume() calls are not expected.
+func (classes *CommitClassesAnalysis) Finalize() interface{} {
+	return CommitClassesResult{
+		Classes:            classes.classes,
+		reversedPeopleDict: classes.reversedPeopleDict,
+	}
+}
+
+// Fork clones this pipeline item.
+func (classes *CommitClassesAnalysis) Fork(n int) []core.PipelineItem {
+	return core.ForkSamePipelineItem(classes, n)
+}
+
+// Checkpoint writes the accumulated daily statistics.
+func (classes *CommitClassesAnalysis) Checkpoint(writer io.Writer) error {
+	return gob.NewEncoder(writer).Encode(classes.classes)
+}
+
+// Restore reads the state previously written by Checkpoint().
+func (classes *CommitClassesAnalysis) Restore(reader io.Reader, facts map[string]interface{}) error {
+	restored := map[string]map[int]map[int]*DevDay{}
+	err := gob.NewDecoder(reader).Decode(&restored)
+	if err != nil {
+		return err
+	}
+	for class, days := range restored {
+		if _, exists := classes.classes[class]; !exists {
+			classes.classes[class] = map[int]map[int]*DevDay{}
+		}
+		for day, dayDevs := range days {
+			classes.classes[class][day] = dayDevs
+		}
+	}
+	return nil
+}
+
+// Serialize converts the analysis result as returned by Finalize() to text or bytes.
+// The text format is YAML and the bytes format is Protocol Buffers.
+func (classes *CommitClassesAnalysis) Serialize(result interface{}, binary bool, writer io.Writer) error {
+	classesResult := result.(CommitClassesResult)
+	if binary {
+		return classes.serializeBinary(&classesResult, writer)
+	}
+	classes.serializeText(&classesResult, writer)
+	return nil
+}
+
+// SerializeJSON converts the analysis result as returned by Finalize() to JSON.
+func (classes *CommitClassesAnalysis) SerializeJSON(result interface{}, writer io.Writer) error {
+	classesResult := result.(CommitClassesResult)
+	return classes.serializeJSON(&classesResult, writer)
+}
+
+// Deserialize converts the specified protobuf bytes to CommitClassesResult.
+func (classes *CommitClassesAnalysis) Deserialize(pbmessage []byte) (interface{}, error) {
+	message := pb.CommitClassesAnalysisResults{}
+	err := proto.Unmarshal(pbmessage, &message)
+	if err != nil {
+		return nil, err
+	}
+	result := CommitClassesResult{
+		Classes:            map[string]map[int]map[int]*DevDay{},
+		reversedPeopleDict: message.DevIndex,
+	}
+	for class, days := range message.Classes {
+		result.Classes[class] = devDaysFromProto(days.Days)
+	}
+	return result, nil
+}
+
+// MergeResults combines two CommitClassesAnalysis-es together. The results with different
+// tick sizes are converted to the coarser one.
+func (classes *CommitClassesAnalysis) MergeResults(
+	r1, r2 interface{}, c1, c2 *core.CommonAnalysisResult) interface{} {
+	cr1 := r1.(CommitClassesResult)
+	cr2 := r2.(CommitClassesResult)
+	tickSize1, tickSize2 := resultTickSize(c1), resultTickSize(c2)
+	tickSize := core.MergeTickSizes(tickSize1, tickSize2)
+	merged := CommitClassesResult{Classes: map[string]map[int]map[int]*DevDay{}}
+	_, merged.reversedPeopleDict = mergeDevDays(
+		nil, nil, cr1.reversedPeopleDict, cr2.reversedPeopleDict, tickSize1, tickSize2, tickSize)
+	for _, class := range mergeClassNames(cr1.Classes, cr2.Classes) {
+		merged.Classes[class], _ = mergeDevDays(
+			cr1.Classes[class], cr2.Classes[class], cr1.reversedPeopleDict, cr2.reversedPeopleDict,
+			tickSize1, tickSize2, tickSize)
+	}
+	return merged
+}
+
+// mergeClassNames returns the sorted union of the class names.
+func mergeClassNames(results ...map[string]map[int]map[int]*DevDay) []string {
+	set := map[string]bool{}
+	for _, result := range results {
+		for class := range result {
+			set[class] = true
+		}
+	}
+	names := make([]string, 0, len(set))
+	for class := range set {
+		names = append(names, class)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (classes *CommitClassesAnalysis) serializeText(result *CommitClassesResult, writer io.Writer) {
+	fmt.Fprintln(writer, "  classes:")
+	for _, class := range mergeClassNames(result.Classes) {
+		fmt.Fprintf(writer, "    %s:\n", yaml.SafeString(class))
+		serializeDevDaysText(result.Classes[class], "      ", writer)
+	}
+	fmt.Fprintln(writer, "  people:")
+	for _, person := range result.reversedPeopleDict {
+		fmt.Fprintf(writer, "  - %s\n", yaml.SafeString(person))
+	}
+}
+
+// commitClassesJSON is the JSON schema of CommitClassesResult, see doc/JSON.md.
+type commitClassesJSON struct {
+	Classes map[string]map[int]map[int]devDayJSON `json:"classes"`
+	People  []string                              `json:"people"`
+}
+
+func (classes *CommitClassesAnalysis) serializeJSON(result *CommitClassesResult, writer io.Writer) error {
+	message := commitClassesJSON{
+		Classes: map[string]map[int]map[int]devDayJSON{},
+		People:  result.reversedPeopleDict,
+	}
+	if message.People == nil {
+		message.People = []string{}
+	}
+	for class, days := range result.Classes {
+		message.Classes[class] = newDevDaysJSON(days)
+	}
+	return json.NewEncoder(writer).Encode(&message)
+}
+
+func (classes *CommitClassesAnalysis) serializeBinary(result *CommitClassesResult, writer io.Writer) error {
+	message := pb.CommitClassesAnalysisResults{
+		Classes:  map[string]*pb.CommitClassDays{},
+		DevIndex: result.reversedPeopleDict,
+	}
+	for class, days := range result.Classes {
+		message.Classes[class] = &pb.CommitClassDays{Days: devDaysToProto(days)}
+	}
+	serialized, err := proto.Marshal(&message)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(serialized)
+	return err
+}
+
+func init() {
+	core.Registry.Register(&CommitClassesAnalysis{})
+}
@@ -0,0 +1,315 @@
+package leaves
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	gitplumbing "gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	"gopkg.in/src-d/hercules.v8/internal/pb"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+)
+
+func fixtureCommitClasses() *CommitClassesAnalysis {
+	classes := CommitClassesAnalysis{}
+	classes.Initialize(test.Repository)
+	people := [...]string{"one@srcd", "two@srcd"}
+	classes.reversedPeopleDict = people[:]
+	return &classes
+}
+
+// commitClassesDeps returns the dependencies of a regular commit with the specified message
+// which changes a Go and a YAML file.
+func commitClassesDeps(message string, day int, authors ...int) map[string]interface{} {
+	return map[string]interface{}{
+		identity.DependencyAuthor:  authors[0],
+		identity.DependencyAuthors: authors,
+		plumbing.DependencyDay:     day,
+		plumbing.DependencyLineStats: map[object.ChangeEntry]plumbing.FileLineStats{
+			{Name: "a.go"}:  {LineStats: LineStats{Added: 10, Removed: 4, Changed: 2}, Language: "Go"},
+			{Name: "b.yml"}: {LineStats: LineStats{Added: 1, Removed: 1, Changed: 1}, Language: "YAML"},
+		},
+		core.DependencyCommit:  &object.Commit{Message: message},
+		core.DependencyIsMerge: false,
+	}
+}
+
+func TestCommitClassesMeta(t *testing.T) {
+	classes := fixtureCommitClasses()
+	assert.Equal(t, classes.Name(), "CommitClasses")
+	assert.Len(t, classes.Provides(), 0)
+	assert.Equal(t, classes.Requires(), []string{
+		identity.DependencyAuthor, plumbing.DependencyDay, plumbing.DependencyLineStats,
+		identity.DependencyAuthors, plumbing.DependencyMergeLineStats})
+	assert.Equal(t, classes.Flag(), "commit-classes")
+	assert.Len(t, classes.ListConfigurationOptions(), 1)
+	assert.Equal(t, classes.ListConfigurationOptions()[0].Name, ConfigCommitClassesRules)
+	assert.Equal(t, classes.ListConfigurationOptions()[0].Flag, "commit-classes-rules")
+	assert.Equal(t, classes.ListConfigurationOptions()[0].Type, core.PathConfigurationOption)
+	assert.True(t, len(classes.Description()) > 0)
+}
+
+func TestCommitClassesRegistration(t *testing.T) {
+	summoned := core.Registry.Summon((&CommitClassesAnalysis{}).Name())
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, summoned[0].Name(), "CommitClasses")
+	leaves := core.Registry.GetLeaves()
+	matched := false
+	for _, tp := range leaves {
+		if tp.Flag() == (&CommitClassesAnalysis{}).Flag() {
+			matched = true
+			break
+		}
+	}
+	assert.True(t, matched)
+}
+
+func TestCommitClassesConfigure(t *testing.T) {
+	classes := CommitClassesAnalysis{}
+	assert.Nil(t, classes.Configure(map[string]interface{}{}))
+	assert.Len(t, classes.Rules, len(defaultCommitClassRules))
+
+	tmp, err := ioutil.TempFile("", "hercules-commit-classes-")
+	assert.Nil(t, err)
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString("security: ['\\bCVE-\\d+', vulnerab]\n# comment\nperf:\n  - faster\n")
+	assert.Nil(t, err)
+	assert.Nil(t, tmp.Close())
+	classes = CommitClassesAnalysis{}
+	assert.Nil(t, classes.Configure(map[string]interface{}{
+		ConfigCommitClassesRules:                     tmp.Name(),
+		identity.FactIdentityDetectorCoAuthorsCredit: identity.CoAuthorsCreditSplit,
+	}))
+	assert.Len(t, classes.Rules, 3)
+	assert.Equal(t, "security", classes.Rules[0].Class)
+	assert.Equal(t, "security", classes.Rules[1].Class)
+	assert.Equal(t, "perf", classes.Rules[2].Class)
+	assert.Equal(t, identity.CoAuthorsCreditSplit, classes.credit)
+	assert.Equal(t, "security", classes.classify("Patch cve-2019-1234"))
+	assert.Equal(t, "perf", classes.classify("Make it FASTER"))
+	assert.Equal(t, CommitClassOther, classes.classify("Fix the bug"))
+
+	assert.Nil(t, ioutil.WriteFile(tmp.Name(), []byte("broken: ['(']\n"), 0666))
+	assert.NotNil(t, (&CommitClassesAnalysis{}).Configure(map[string]interface{}{
+		ConfigCommitClassesRules: tmp.Name()}))
+	assert.NotNil(t, (&CommitClassesAnalysis{}).Configure(map[string]interface{}{
+		ConfigCommitClassesRules: tmp.Name() + "-missing"}))
+}
+
+func TestCommitClassesClassify(t *testing.T) {
+	classes := fixtureCommitClasses()
+	for message, class := range map[string]string{
+		"feat: add the commit classes leaf":            "feat",
+		"fix(burndown)!: crash on empty files\n\nBody": "fix",
+		"Chore: bump the dependencies":                 "chore",
+		"refactor!: drop the old API":                  "refactor",
+		"leaves: fix the crash":                        "fix",
+		"Revert \"feat: add the leaf\"":                "revert",
+		"Merge pull request #12 from user/branch":      "merge",
+		"Fixed a bug in the parser":                    "fix",
+		"Bump the version, closes #7":                  "fix",
+		"Clean up the pipeline":                        "refactor",
+		"Update README":                                "docs",
+		"Add tests for the merges":                     "test",
+		"Implement the ownership analysis":             "feat",
+		"Update the parser (#42)":                      "issue",
+		"Update the parser":                            CommitClassOther,
+		"feature: not a conventional type":             CommitClassOther,
+		"":                                             CommitClassOther,
+	} {
+		assert.Equal(t, class, classes.classify(message), message)
+	}
+}
+
+func TestCommitClassesConsumeFinalize(t *testing.T) {
+	classes := fixtureCommitClasses()
+	result, err := classes.Consume(commitClassesDeps("feat: add the leaf", 0, 0))
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+	_, err = classes.Consume(commitClassesDeps("Fix the bug", 0, 1))
+	assert.Nil(t, err)
+	_, err = classes.Consume(commitClassesDeps("fix: the crash", 3, 1, 0))
+	assert.Nil(t, err)
+	// excluded bots
+	deps := commitClassesDeps("chore: bump", 3, 0)
+	deps[identity.DependencyAuthors] = []int{}
+	_, err = classes.Consume(deps)
+	assert.Nil(t, err)
+
+	x := classes.Finalize().(CommitClassesResult)
+	assert.Equal(t, classes.reversedPeopleDict, x.reversedPeopleDict)
+	assert.Len(t, x.Classes, 2)
+	assert.Equal(t, map[int]map[int]*DevDay{0: {0: {
+		Commits:   1,
+		LineStats: LineStats{Added: 11, Removed: 5, Changed: 3},
+		Languages: map[string]LineStats{
+			"Go":   {Added: 10, Removed: 4, Changed: 2},
+			"YAML": {Added: 1, Removed: 1, Changed: 1},
+		},
+	}}}, x.Classes["feat"])
+	assert.Len(t, x.Classes["fix"], 2)
+	assert.Equal(t, 1, x.Classes["fix"][0][1].Commits)
+	assert.Equal(t, 1, x.Classes["fix"][3][0].Commits)
+	assert.Equal(t, 1, x.Classes["fix"][3][1].Commits)
+	assert.Equal(t, LineStats{Added: 11, Removed: 5, Changed: 3}, x.Classes["fix"][3][0].LineStats)
+}
+
+func TestCommitClassesConsumeCoAuthors(t *testing.T) {
+	classes := fixtureCommitClasses()
+	assert.Nil(t, classes.Configure(map[string]interface{}{
+		identity.FactIdentityDetectorCoAuthorsCredit: identity.CoAuthorsCreditSplit,
+	}))
+	_, err := classes.Consume(commitClassesDeps("fix: the crash", 0, 0, 1))
+	assert.Nil(t, err)
+	fixes := classes.classes["fix"][0]
+	assert.Equal(t, 1, fixes[0].Commits)
+	assert.Equal(t, 1, fixes[1].Commits)
+	assert.Equal(t, LineStats{Added: 6, Removed: 3, Changed: 2}, fixes[0].LineStats)
+	assert.Equal(t, LineStats{Added: 5, Removed: 2, Changed: 1}, fixes[1].LineStats)
+}
+
+func TestCommitClassesConsumeMerge(t *testing.T) {
+	classes := fixtureCommitClasses()
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 1
+	deps[plumbing.DependencyDay] = 5
+	deps[core.DependencyIsMerge] = true
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
+		"fe00c0d50719a23ee77370dc29984fe4e16b2090"))
+	deps[plumbing.DependencyMergeLineStats] = mergeLineStats(t, plumbing.MergeDiffFirstParent, deps)
+	_, err := classes.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, classes.classes, 1)
+	assert.Len(t, classes.classes["merge"], 1)
+	dev := classes.classes["merge"][5][1]
+	assert.Equal(t, 1, dev.Commits)
+	assert.Equal(t, LineStats{Added: 28, Removed: 22, Changed: 30}, dev.LineStats)
+}
+
+func TestCommitClassesCheckpointRestore(t *testing.T) {
+	classes := fixtureCommitClasses()
+	_, err := classes.Consume(commitClassesDeps("feat: add the leaf", 0, 0))
+	assert.Nil(t, err)
+	_, err = classes.Consume(commitClassesDeps("fix: the crash", 3, 1))
+	assert.Nil(t, err)
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, classes.Checkpoint(buffer))
+	restored := fixtureCommitClasses()
+	assert.Nil(t, restored.Restore(buffer, map[string]interface{}{}))
+	assert.Equal(t, classes.classes, restored.classes)
+}
+
+func fixtureCommitClassesResult() CommitClassesResult {
+	return CommitClassesResult{
+		Classes: map[string]map[int]map[int]*DevDay{
+			"fix": {
+				1: {0: {10, LineStats{Added: 20, Removed: 30, Changed: 40},
+					map[string]LineStats{"Go": {Added: 2, Removed: 3, Changed: 4}}}},
+				10: {identity.AuthorMissing: {1, LineStats{Added: 2, Removed: 3, Changed: 4},
+					map[string]LineStats{"": {Added: 2, Removed: 3, Changed: 4}}}},
+			},
+			"feat": {
+				2: {1: {3, LineStats{Added: 5, Removed: 6, Changed: 7},
+					map[string]LineStats{"Go": {Added: 5, Removed: 6, Changed: 7}}}},
+			},
+		},
+		reversedPeopleDict: []string{"one@srcd", "two@srcd"},
+	}
+}
+
+func TestCommitClassesSerialize(t *testing.T) {
+	classes := fixtureCommitClasses()
+	res := fixtureCommitClassesResult()
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, classes.Serialize(res, false, buffer))
+	assert.Equal(t, `  classes:
+    "feat":
+      2:
+        1: [3, 5, 6, 7, 0, {Go: [5, 6, 7, 0]}]
+    "fix":
+      1:
+        0: [10, 20, 30, 40, 0, {Go: [2, 3, 4, 0]}]
+      10:
+        -1: [1, 2, 3, 4, 0, {none: [2, 3, 4, 0]}]
+  people:
+  - "one@srcd"
+  - "two@srcd"
+`, buffer.String())
+
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, classes.Serialize(res, true, buffer))
+	msg := pb.CommitClassesAnalysisResults{}
+	assert.Nil(t, proto.Unmarshal(buffer.Bytes(), &msg))
+	assert.Equal(t, res.reversedPeopleDict, msg.DevIndex)
+	assert.Len(t, msg.Classes, 2)
+	assert.Equal(t, int32(10), msg.Classes["fix"].Days[1].Devs[0].Commits)
+	assert.Equal(t, int32(1), msg.Classes["fix"].Days[10].Devs[-1].Commits)
+	assert.Equal(t, int32(6), msg.Classes["feat"].Days[2].Devs[1].Stats.Removed)
+
+	deserialized, err := classes.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, res, deserialized)
+}
+
+func TestCommitClassesSerializeJSON(t *testing.T) {
+	classes := fixtureCommitClasses()
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, classes.SerializeJSON(fixtureCommitClassesResult(), buffer))
+	var message struct {
+		Classes map[string]map[string]map[string]struct {
+			Commits   int                       `json:"commits"`
+			Added     int                       `json:"added"`
+			Languages map[string]map[string]int `json:"languages"`
+		} `json:"classes"`
+		People []string `json:"people"`
+	}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &message))
+	assert.Equal(t, []string{"one@srcd", "two@srcd"}, message.People)
+	assert.Len(t, message.Classes, 2)
+	assert.Equal(t, 10, message.Classes["fix"]["1"]["0"].Commits)
+	assert.Equal(t, 20, message.Classes["fix"]["1"]["0"].Added)
+	assert.Equal(t, 2, message.Classes["fix"]["10"]["-1"].Languages["none"]["added"])
+	assert.Equal(t, 3, message.Classes["feat"]["2"]["1"].Commits)
+}
+
+func TestCommitClassesMergeResults(t *testing.T) {
+	r1 := fixtureCommitClassesResult()
+	r2 := CommitClassesResult{
+		Classes: map[string]map[int]map[int]*DevDay{
+			"fix": {
+				1: {0: {1, LineStats{Added: 1, Removed: 1, Changed: 1}, map[string]LineStats{}}},
+			},
+			"docs": {
+				4: {1: {2, LineStats{Added: 8, Removed: 0, Changed: 0},
+					map[string]LineStats{"Markdown": {Added: 8}}}},
+			},
+		},
+		reversedPeopleDict: []string{"three@srcd", "one@srcd"},
+	}
+	classes := fixtureCommitClasses()
+	rm := classes.MergeResults(r1, r2, nil, nil).(CommitClassesResult)
+	assert.Equal(t, []string{"one@srcd", "three@srcd", "two@srcd"}, rm.reversedPeopleDict)
+	assert.Len(t, rm.Classes, 3)
+	assert.Equal(t, 10, rm.Classes["fix"][1][0].Commits)
+	assert.Equal(t, 1, rm.Classes["fix"][1][1].Commits)
+	assert.Equal(t, 1, rm.Classes["fix"][10][identity.AuthorMissing].Commits)
+	assert.Equal(t, 3, rm.Classes["feat"][2][2].Commits)
+	assert.Equal(t, 2, rm.Classes["docs"][4][0].Commits)
+	assert.Equal(t, 8, rm.Classes["docs"][4][0].Languages["Markdown"].Added)
+	// the hours of r1 belong to the first day
+	rm = classes.MergeResults(r1, r2, &core.CommonAnalysisResult{TickSize: time.Hour},
+		&core.CommonAnalysisResult{TickSize: 24 * time.Hour}).(CommitClassesResult)
+	assert.Len(t, rm.Classes["fix"], 2)
+	assert.Equal(t, 10, rm.Classes["fix"][0][0].Commits)
+	assert.Equal(t, 1, rm.Classes["fix"][0][identity.AuthorMissing].Commits)
+	assert.Equal(t, 1, rm.Classes["fix"][1][1].Commits)
+}
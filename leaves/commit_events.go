@@ -0,0 +1,257 @@
+package leaves
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	items "gopkg.in/src-d/hercules.v8/internal/plumbing"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v8/internal/yaml"
+)
+
+// CommitEventsAnalysis writes the statistics of each commit to a file as soon as the commit
+// is analysed, one JSON object per line (newline-delimited JSON). It allows to watch
+// the analysis progress from outside, e.g. to update a dashboard.
+type CommitEventsAnalysis struct {
+	core.NoopMerger
+	core.OneShotMergeProcessor
+	// Output is the path to the file where to write the events. It can be a named pipe.
+	Output string
+
+	// file is the opened Output
+	file *os.File
+	// encoder writes to file
+	encoder *json.Encoder
+	// events is the number of written events
+	events int
+	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+}
+
+// CommitEventsResult is returned by CommitEventsAnalysis.Finalize() and summarizes
+// the written event stream.
+type CommitEventsResult struct {
+	// Output is the path to the file with the events.
+	Output string
+	// Events is the number of written events, that is, the number of analysed commits.
+	Events int
+
+	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+}
+
+const (
+	// ConfigCommitEventsOutput is the name of the option to set CommitEventsAnalysis.Output.
+	ConfigCommitEventsOutput = "CommitEvents.Output"
+)
+
+// commitEventJSON is the JSON schema of a single event, see doc/JSON.md.
+type commitEventJSON struct {
+	Hash   string `json:"hash"`
+	When   int64  `json:"when"`
+	Day    int    `json:"day"`
+	Author int    `json:"author"`
+	Merge  bool   `json:"merge"`
+	lineStatsJSON
+	Files []fileStatJSON `json:"files"`
+}
+
+// Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
+func (events *CommitEventsAnalysis) Name() string {
+	return "CommitEvents"
+}
+
+// Provides returns the list of names of entities which are produced by this PipelineItem.
+// Each produced entity will be inserted into `deps` of dependent Consume()-s according
+// to this list. Also used by core.Registry to build the global map of providers.
+func (events *CommitEventsAnalysis) Provides() []string {
+	return []string{}
+}
+
+// Requires returns the list of names of entities which are needed by this PipelineItem.
+// Each requested entity will be inserted into `deps` of Consume(). In turn, those
+// entities are Provides() upstream.
+func (events *CommitEventsAnalysis) Requires() []string {
+	arr := [...]string{
+		identity.DependencyAuthor, items.DependencyTreeChanges, items.DependencyLineStats,
+		items.DependencyDay, identity.DependencyAuthors}
+	return arr[:]
+}
+
+// ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
+func (events *CommitEventsAnalysis) ListConfigurationOptions() []core.ConfigurationOption {
+	options := [...]core.ConfigurationOption{{
+		Name:        ConfigCommitEventsOutput,
+		Description: "Path to the file where to write the commit events; required by --commit-events.",
+		Flag:        "commit-events-output",
+		Type:        core.PathConfigurationOption,
+		Default:     ""}}
+	return options[:]
+}
+
+// Configure sets the properties previously published by ListConfigurationOptions().
+func (events *CommitEventsAnalysis) Configure(facts map[string]interface{}) error {
+	if val, exists := facts[ConfigCommitEventsOutput].(string); exists {
+		events.Output = val
+	}
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		events.reversedPeopleDict = val
+	}
+	return nil
+}
+
+// Flag for the command line switch which enables this analysis.
+func (events *CommitEventsAnalysis) Flag() string {
+	return "commit-events"
+}
+
+// Description returns the text which explains what the analysis is doing.
+func (events *CommitEventsAnalysis) Description() string {
+	return "Writes the statistics of each commit as soon as it is analysed to a newline-delimited JSON stream."
+}
+
+// Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
+// calls. The repository which is going to be analysed is supplied as an argument.
+func (events *CommitEventsAnalysis) Initialize(repository *git.Repository) error {
+	if events.Output == "" {
+		return errors.New("the output file of the commit events must be set with --commit-events-output")
+	}
+	if events.file != nil {
+		events.file.Close()
+	}
+	file, err := os.Create(events.Output)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", events.Output)
+	}
+	events.file = file
+	events.encoder = json.NewEncoder(file)
+	events.events = 0
+	events.OneShotMergeProcessor.Initialize()
+	return nil
+}
+
+// Consume runs this PipelineItem on the next commit data.
+// `deps` contain all the results from upstream PipelineItem-s as requested by Requires().
+// Additionally, DependencyCommit is always present there and represents the analysed *object.Commit.
+// This function returns the mapping with analysis results. The keys must be the same as
+// in Provides(). If there was an error, nil is returned.
+func (events *CommitEventsAnalysis) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	if !events.ShouldConsumeCommit(deps) {
+		return nil, nil
+	}
+	authors := commitAuthors(deps)
+	if len(authors) == 0 {
+		return nil, nil
+	}
+	commit := deps[core.DependencyCommit].(*object.Commit)
+	author := authors[0]
+	if author == identity.AuthorMissing {
+		author = -1
+	}
+	event := commitEventJSON{
+		Hash:   commit.Hash.String(),
+		When:   commit.Author.When.Unix(),
+		Day:    deps[items.DependencyDay].(int),
+		Author: author,
+		Merge:  deps[core.DependencyIsMerge].(bool),
+		Files:  []fileStatJSON{},
+	}
+	// the diff of a merge commit is relative to the first parent and is not meaningful
+	if !event.Merge {
+		files, err := computeFileStats(deps)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			event.Added += file.Added
+			event.Removed += file.Removed
+			event.Changed += file.Changed
+			event.Moved += file.Moved
+			event.Files = append(event.Files, fileStatJSON{
+				To:       file.ToName,
+				From:     file.FromName,
+				Language: file.Language,
+				Stat:     newLineStatsJSON(file.LineStats),
+			})
+		}
+	}
+	if err := events.encoder.Encode(&event); err != nil {
+		return nil, errors.Wrapf(err, "failed to write to %s", events.Output)
+	}
+	events.events++
+	return nil, nil
+}
+
+// Finalize returns the result of the analysis. Further Consume() calls are not expected.
+func (events *CommitEventsAnalysis) Finalize() interface{} {
+	if events.file != nil {
+		events.file.Close()
+		events.file = nil
+	}
+	return CommitEventsResult{
+		Output:             events.Output,
+		Events:             events.events,
+		reversedPeopleDict: events.reversedPeopleDict,
+	}
+}
+
+// Fork clones this pipeline item.
+func (events *CommitEventsAnalysis) Fork(n int) []core.PipelineItem {
+	return core.ForkSamePipelineItem(events, n)
+}
+
+// Serialize converts the analysis result as returned by Finalize() to text or bytes.
+// The text format is YAML. There is nothing to write in the Protocol Buffers format:
+// the events are already in the output file.
+func (events *CommitEventsAnalysis) Serialize(result interface{}, binary bool, writer io.Writer) error {
+	eventsResult := result.(CommitEventsResult)
+	if binary {
+		return nil
+	}
+	events.serializeText(&eventsResult, writer)
+	return nil
+}
+
+// SerializeJSON converts the analysis result as returned by Finalize() to JSON.
+func (events *CommitEventsAnalysis) SerializeJSON(result interface{}, writer io.Writer) error {
+	eventsResult := result.(CommitEventsResult)
+	return events.serializeJSON(&eventsResult, writer)
+}
+
+func (events *CommitEventsAnalysis) serializeText(result *CommitEventsResult, writer io.Writer) {
+	fmt.Fprintln(writer, "  output:", yaml.SafeString(result.Output))
+	fmt.Fprintln(writer, "  events:", result.Events)
+	fmt.Fprintln(writer, "  people:")
+	for _, person := range result.reversedPeopleDict {
+		fmt.Fprintf(writer, "  - %s\n", yaml.SafeString(person))
+	}
+}
+
+// commitEventsJSON is the JSON schema of CommitEventsResult, see doc/JSON.md.
+type commitEventsJSON struct {
+	Output string   `json:"output"`
+	Events int      `json:"events"`
+	People []string `json:"people"`
+}
+
+func (events *CommitEventsAnalysis) serializeJSON(result *CommitEventsResult, writer io.Writer) error {
+	message := commitEventsJSON{
+		Output: result.Output,
+		Events: result.Events,
+		People: result.reversedPeopleDict,
+	}
+	if message.People == nil {
+		message.People = []string{}
+	}
+	return json.NewEncoder(writer).Encode(&message)
+}
+
+func init() {
+	core.Registry.Register(&CommitEventsAnalysis{})
+}
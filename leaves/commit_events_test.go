@@ -0,0 +1,249 @@
+package leaves
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	gitplumbing "gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+	"gopkg.in/src-d/hercules.v8/internal/test/fixtures"
+)
+
+func fixtureCommitEvents(t *testing.T) (*CommitEventsAnalysis, func()) {
+	tmpdir, err := ioutil.TempDir("", "hercules-")
+	assert.Nil(t, err)
+	events := &CommitEventsAnalysis{}
+	assert.Nil(t, events.Configure(map[string]interface{}{
+		ConfigCommitEventsOutput:                        filepath.Join(tmpdir, "events.ndjson"),
+		identity.FactIdentityDetectorReversedPeopleDict: []string{"one@srcd", "two@srcd"},
+	}))
+	assert.Nil(t, events.Initialize(test.Repository))
+	return events, func() { os.RemoveAll(tmpdir) }
+}
+
+func TestCommitEventsMeta(t *testing.T) {
+	events := &CommitEventsAnalysis{}
+	assert.Equal(t, events.Name(), "CommitEvents")
+	assert.Len(t, events.Provides(), 0)
+	assert.Len(t, events.Requires(), 5)
+	assert.Equal(t, events.Requires()[0], identity.DependencyAuthor)
+	assert.Equal(t, events.Requires()[2], plumbing.DependencyLineStats)
+	assert.Equal(t, events.Requires()[3], plumbing.DependencyDay)
+	assert.Equal(t, events.Requires()[4], identity.DependencyAuthors)
+	assert.Equal(t, events.Flag(), "commit-events")
+	assert.Len(t, events.ListConfigurationOptions(), 1)
+	assert.Equal(t, events.ListConfigurationOptions()[0].Name, ConfigCommitEventsOutput)
+	assert.Equal(t, events.ListConfigurationOptions()[0].Flag, "commit-events-output")
+	assert.Equal(t, events.ListConfigurationOptions()[0].Type, core.PathConfigurationOption)
+	assert.True(t, len(events.Description()) > 0)
+}
+
+func TestCommitEventsRegistration(t *testing.T) {
+	summoned := core.Registry.Summon((&CommitEventsAnalysis{}).Name())
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, summoned[0].Name(), "CommitEvents")
+	matched := false
+	for _, tp := range core.Registry.GetLeaves() {
+		if tp.Flag() == (&CommitEventsAnalysis{}).Flag() {
+			matched = true
+			break
+		}
+	}
+	assert.True(t, matched)
+}
+
+func TestCommitEventsInitialize(t *testing.T) {
+	events := &CommitEventsAnalysis{}
+	assert.NotNil(t, events.Initialize(test.Repository))
+	events.Output = filepath.Join("/", "nonexistent", "events.ndjson")
+	assert.NotNil(t, events.Initialize(test.Repository))
+}
+
+func TestCommitEventsConsumeFinalize(t *testing.T) {
+	events, cleanup := fixtureCommitEvents(t)
+	defer cleanup()
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 1
+	deps[plumbing.DependencyDay] = 7
+	cache := map[gitplumbing.Hash]*plumbing.CachedBlob{}
+	AddHash(t, cache, "291286b4ac41952cbd1389fda66420ec03c1a9fe")
+	AddHash(t, cache, "c29112dbd697ad9b401333b80c18a63951bc18d9")
+	AddHash(t, cache, "baa64828831d174f40140e4b3cfa77d1e917a2c1")
+	AddHash(t, cache, "dc248ba2b22048cc730c571a748e8ffcf7085ab9")
+	deps[plumbing.DependencyBlobCache] = cache
+	deps[plumbing.DependencyLanguages] = map[gitplumbing.Hash]string{
+		gitplumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe"): "YAML",
+		gitplumbing.NewHash("c29112dbd697ad9b401333b80c18a63951bc18d9"): "Go",
+		gitplumbing.NewHash("baa64828831d174f40140e4b3cfa77d1e917a2c1"): "Go",
+		gitplumbing.NewHash("dc248ba2b22048cc730c571a748e8ffcf7085ab9"): "Go",
+	}
+	changes := make(object.Changes, 3)
+	treeFrom, _ := test.Repository.TreeObject(gitplumbing.NewHash(
+		"a1eb2ea76eb7f9bfbde9b243861474421000eb96"))
+	treeTo, _ := test.Repository.TreeObject(gitplumbing.NewHash(
+		"994eac1cd07235bb9815e547a75c84265dea00f5"))
+	changes[0] = &object.Change{From: object.ChangeEntry{
+		Name: "analyser.go",
+		Tree: treeFrom,
+		TreeEntry: object.TreeEntry{
+			Name: "analyser.go",
+			Mode: 0100644,
+			Hash: gitplumbing.NewHash("dc248ba2b22048cc730c571a748e8ffcf7085ab9"),
+		},
+	}, To: object.ChangeEntry{
+		Name: "analyser.go",
+		Tree: treeTo,
+		TreeEntry: object.TreeEntry{
+			Name: "analyser.go",
+			Mode: 0100644,
+			Hash: gitplumbing.NewHash("baa64828831d174f40140e4b3cfa77d1e917a2c1"),
+		},
+	}}
+	changes[1] = &object.Change{From: object.ChangeEntry{}, To: object.ChangeEntry{
+		Name: "cmd/hercules/main.go",
+		Tree: treeTo,
+		TreeEntry: object.TreeEntry{
+			Name: "cmd/hercules/main.go",
+			Mode: 0100644,
+			Hash: gitplumbing.NewHash("c29112dbd697ad9b401333b80c18a63951bc18d9"),
+		},
+	},
+	}
+	changes[2] = &object.Change{From: object.ChangeEntry{}, To: object.ChangeEntry{
+		Name: ".travis.yml",
+		Tree: treeTo,
+		TreeEntry: object.TreeEntry{
+			Name: ".travis.yml",
+			Mode: 0100644,
+			Hash: gitplumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe"),
+		},
+	},
+	}
+	deps[plumbing.DependencyTreeChanges] = changes
+	fd := fixtures.FileDiff()
+	result, err := fd.Consume(deps)
+	assert.Nil(t, err)
+	deps[plumbing.DependencyFileDiff] = result[plumbing.DependencyFileDiff]
+	result, err = fixtures.LineStats().Consume(deps)
+	assert.Nil(t, err)
+	deps[plumbing.DependencyLineStats] = result[plumbing.DependencyLineStats]
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	deps[core.DependencyIsMerge] = false
+	result, err = events.Consume(deps)
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+
+	// the event must be available before Finalize()
+	data, err := ioutil.ReadFile(events.Output)
+	assert.Nil(t, err)
+	event := commitEventJSON{}
+	assert.Nil(t, json.Unmarshal(data, &event))
+	assert.Equal(t, "cce947b98a050c6d356bc6ba95030254914027b1", event.Hash)
+	assert.Equal(t, int64(1481563829), event.When)
+	assert.Equal(t, 7, event.Day)
+	assert.Equal(t, 1, event.Author)
+	assert.False(t, event.Merge)
+	assert.Equal(t, lineStatsJSON{Added: 847, Removed: 9, Changed: 67}, event.lineStatsJSON)
+	assert.Equal(t, []fileStatJSON{
+		{To: "analyser.go", From: "analyser.go", Language: "Go",
+			Stat: lineStatsJSON{Added: 628, Removed: 9, Changed: 67}},
+		{To: "cmd/hercules/main.go", Language: "Go",
+			Stat: lineStatsJSON{Added: 207}},
+		{To: ".travis.yml", Language: "YAML",
+			Stat: lineStatsJSON{Added: 12}},
+	}, event.Files)
+
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
+		"dd9dd084d5851d7dc4399fc7dbf3d8292831ebc5"))
+	deps[core.DependencyIsMerge] = true
+	deps[identity.DependencyAuthor] = identity.AuthorMissing
+	result, err = events.Consume(deps)
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+	// the second branch of the same merge is skipped
+	result, err = events.Consume(deps)
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+
+	res := events.Finalize().(CommitEventsResult)
+	assert.Equal(t, 2, res.Events)
+	assert.Equal(t, events.Output, res.Output)
+	file, err := os.Open(res.Output)
+	assert.Nil(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	assert.Len(t, lines, 2)
+	assert.Equal(t, `{"hash":"dd9dd084d5851d7dc4399fc7dbf3d8292831ebc5","when":1502431701,"day":7,`+
+		`"author":-1,"merge":true,"added":0,"removed":0,"changed":0,"moved":0,"files":[]}`, lines[1])
+}
+
+func TestCommitEventsConsumeBotsExcluded(t *testing.T) {
+	events, cleanup := fixtureCommitEvents(t)
+	defer cleanup()
+	deps := map[string]interface{}{}
+	// the excluded bot commits are not credited to anybody
+	deps[identity.DependencyAuthor] = identity.AuthorMissing
+	deps[identity.DependencyAuthors] = []int{}
+	deps[plumbing.DependencyDay] = 7
+	deps[plumbing.DependencyTreeChanges] = object.Changes{}
+	deps[plumbing.DependencyLineStats] = map[object.ChangeEntry]plumbing.FileLineStats{}
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	deps[core.DependencyIsMerge] = false
+	result, err := events.Consume(deps)
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+	res := events.Finalize().(CommitEventsResult)
+	assert.Equal(t, 0, res.Events)
+	data, err := ioutil.ReadFile(res.Output)
+	assert.Nil(t, err)
+	assert.Len(t, data, 0)
+}
+
+func TestCommitEventsFork(t *testing.T) {
+	events1 := &CommitEventsAnalysis{}
+	clones := events1.Fork(1)
+	assert.Len(t, clones, 1)
+	events2 := clones[0].(*CommitEventsAnalysis)
+	assert.True(t, events1 == events2)
+	events1.Merge([]core.PipelineItem{events2})
+}
+
+func TestCommitEventsSerialize(t *testing.T) {
+	events := &CommitEventsAnalysis{}
+	res := CommitEventsResult{
+		Output:             "/tmp/events.ndjson",
+		Events:             10,
+		reversedPeopleDict: []string{"one@srcd", "two@srcd"},
+	}
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, events.Serialize(res, false, buffer))
+	assert.Equal(t, `  output: "/tmp/events.ndjson"
+  events: 10
+  people:
+  - "one@srcd"
+  - "two@srcd"
+`, buffer.String())
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, events.Serialize(res, true, buffer))
+	assert.Equal(t, 0, buffer.Len())
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, events.SerializeJSON(res, buffer))
+	assert.Equal(t, `{"output":"/tmp/events.ndjson","events":10,"people":["one@srcd","two@srcd"]}
+`, buffer.String())
+}
@@ -1,14 +1,12 @@
 package leaves
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
-	"unicode/utf8"
 
 	"github.com/gogo/protobuf/proto"
-	"github.com/sergi/go-diff/diffmatchpatch"
 	git "gopkg.in/src-d/go-git.v4"
-	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
 	"gopkg.in/src-d/hercules.v8/internal/core"
@@ -23,6 +21,8 @@ type CommitsAnalysis struct {
 	core.NoopMerger
 	core.OneShotMergeProcessor
 
+	// SkipMerges indicates whether the merge commits are skipped, see items.MergeDiffNone.
+	SkipMerges bool
 	// days maps days to developers to stats
 	commits []*CommitStat
 	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
@@ -52,6 +52,8 @@ type CommitStat struct {
 	When   int64
 	Author int
 	Files  []FileStat
+	// Merge indicates whether this is a merge commit, see ConfigMergeDiffMode.
+	Merge bool
 }
 
 // Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
@@ -71,8 +73,8 @@ func (ca *CommitsAnalysis) Provides() []string {
 // entities are Provides() upstream.
 func (ca *CommitsAnalysis) Requires() []string {
 	arr := [...]string{
-		identity.DependencyAuthor, items.DependencyTreeChanges, items.DependencyFileDiff,
-		items.DependencyBlobCache, items.DependencyLanguages}
+		identity.DependencyAuthor, items.DependencyTreeChanges, items.DependencyLineStats,
+		items.DependencyIgnoredCommit, identity.DependencyAuthors, items.DependencyMergeLineStats}
 	return arr[:]
 }
 
@@ -86,6 +88,9 @@ func (ca *CommitsAnalysis) Configure(facts map[string]interface{}) error {
 	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
 		ca.reversedPeopleDict = val
 	}
+	if val, exists := facts[items.ConfigMergeDiffMode].(string); exists {
+		ca.SkipMerges = val == items.MergeDiffNone
+	}
 	return nil
 }
 
@@ -115,27 +120,76 @@ func (ca *CommitsAnalysis) Consume(deps map[string]interface{}) (map[string]inte
 	if !ca.ShouldConsumeCommit(deps) {
 		return nil, nil
 	}
-	if deps[core.DependencyIsMerge].(bool) {
+	if ignored, _ := deps[items.DependencyIgnoredCommit].(bool); ignored {
 		return nil, nil
 	}
-
-	commit := deps[core.DependencyCommit].(*object.Commit)
-	author := deps[identity.DependencyAuthor].(int)
-	treeDiff := deps[items.DependencyTreeChanges].(object.Changes)
-	if len(treeDiff) == 0 {
+	authors := commitAuthors(deps)
+	if len(authors) == 0 {
 		return nil, nil
 	}
 
+	commit := deps[core.DependencyCommit].(*object.Commit)
 	cs := CommitStat{
 		Hash:   commit.Hash.String(),
 		When:   commit.Author.When.Unix(),
-		Author: author,
+		Author: authors[0],
+		Merge:  deps[core.DependencyIsMerge].(bool),
 	}
 
+	if cs.Merge {
+		if ca.SkipMerges {
+			return nil, nil
+		}
+		// the tree changes of a merge are not relative to the first parent
+		for _, file := range deps[items.DependencyMergeLineStats].([]items.MergeFileLineStats) {
+			cs.Files = append(cs.Files, FileStat{
+				ToName: file.ToName, FromName: file.FromName, Language: file.Language,
+				LineStats: file.LineStats,
+			})
+		}
+	} else {
+		if len(deps[items.DependencyTreeChanges].(object.Changes)) == 0 {
+			return nil, nil
+		}
+		files, err := computeFileStats(deps)
+		if err != nil {
+			return nil, err
+		}
+		cs.Files = files
+	}
+
+	ca.commits = append(ca.commits, &cs)
+
+	return nil, nil
+}
+
+// commitFileStats returns the line statistics of each file changed in the analysed commit.
+// The merge commits take items.DependencyMergeLineStats from `deps`, the rest take
+// items.DependencyLineStats.
+func commitFileStats(deps map[string]interface{}) []items.FileLineStats {
+	var files []items.FileLineStats
+	if deps[core.DependencyIsMerge].(bool) {
+		// the tree changes of a merge are not relative to the first parent
+		for _, file := range deps[items.DependencyMergeLineStats].([]items.MergeFileLineStats) {
+			files = append(files, file.FileLineStats)
+		}
+		return files
+	}
+	lineStats := deps[items.DependencyLineStats].(map[object.ChangeEntry]items.FileLineStats)
+	for _, stats := range lineStats {
+		files = append(files, stats)
+	}
+	return files
+}
+
+// computeFileStats collects the line statistics of each file changed in the commit.
+// `deps` must contain DependencyTreeChanges and DependencyLineStats. The files are returned
+// in the order of the tree changes. Binary files have zero statistics.
+func computeFileStats(deps map[string]interface{}) ([]FileStat, error) {
+	treeDiff := deps[items.DependencyTreeChanges].(object.Changes)
+	lineStats := deps[items.DependencyLineStats].(map[object.ChangeEntry]items.FileLineStats)
+	var files []*FileStat
 	filesMap := make(map[string]*FileStat)
-	cache := deps[items.DependencyBlobCache].(map[plumbing.Hash]*items.CachedBlob)
-	fileDiffs := deps[items.DependencyFileDiff].(map[string]items.FileDiffData)
-	langs := deps[items.DependencyLanguages].(map[plumbing.Hash]string)
 	for _, change := range treeDiff {
 		action, err := change.Action()
 		if err != nil {
@@ -146,71 +200,32 @@ func (ca *CommitsAnalysis) Consume(deps map[string]interface{}) (map[string]inte
 		if !ok {
 			cf = &FileStat{}
 			filesMap[change.To.Name+change.From.Name] = cf
+			files = append(files, cf)
 		}
 		cf.ToName = change.To.Name
 		cf.FromName = change.From.Name
 
-		switch action {
-		case merkletrie.Insert:
-			blob := cache[change.To.TreeEntry.Hash]
-			lines, err := blob.CountLines()
-			if err != nil {
-				// binary
-				continue
-			}
-			cf.Added += lines
-			cf.Language = langs[change.To.TreeEntry.Hash]
-		case merkletrie.Delete:
-			blob := cache[change.From.TreeEntry.Hash]
-			lines, err := blob.CountLines()
-			if err != nil {
-				// binary
-				continue
-			}
-			cf.Removed += lines
-			cf.Language = langs[change.From.TreeEntry.Hash]
-		case merkletrie.Modify:
-			cf.Language = langs[change.To.TreeEntry.Hash]
-			thisDiffs := fileDiffs[change.To.Name]
-			var removedPending int
-			for _, edit := range thisDiffs.Diffs {
-				switch edit.Type {
-				case diffmatchpatch.DiffEqual:
-					if removedPending > 0 {
-						cf.Removed += removedPending
-					}
-					removedPending = 0
-				case diffmatchpatch.DiffInsert:
-					added := utf8.RuneCountInString(edit.Text)
-					if removedPending > added {
-						removed := removedPending - added
-						cf.Changed += added
-						cf.Removed += removed
-
-					} else {
-						added := added - removedPending
-						cf.Changed += removedPending
-						cf.Added += added
-
-					}
-					removedPending = 0
-				case diffmatchpatch.DiffDelete:
-					removedPending = utf8.RuneCountInString(edit.Text)
-				}
-			}
-			if removedPending > 0 {
-				cf.Removed += removedPending
-			}
+		entry := change.To
+		if action == merkletrie.Delete {
+			entry = change.From
+		}
+		stats, exists := lineStats[entry]
+		if !exists {
+			// binary
+			continue
 		}
+		cf.Added += stats.Added
+		cf.Removed += stats.Removed
+		cf.Changed += stats.Changed
+		cf.Moved += stats.Moved
+		cf.Language = stats.Language
 	}
 
-	for _, f := range filesMap {
-		cs.Files = append(cs.Files, *f)
+	result := make([]FileStat, len(files))
+	for i, f := range files {
+		result[i] = *f
 	}
-
-	ca.commits = append(ca.commits, &cs)
-
-	return nil, nil
+	return result, nil
 }
 
 // Finalize returns the result of the analysis. Further Consume() calls are not expected.
@@ -237,6 +252,12 @@ func (ca *CommitsAnalysis) Serialize(result interface{}, binary bool, writer io.
 	return nil
 }
 
+// SerializeJSON converts the analysis result as returned by Finalize() to JSON.
+func (ca *CommitsAnalysis) SerializeJSON(result interface{}, writer io.Writer) error {
+	commitsResult := result.(CommitsResult)
+	return ca.serializeJSON(&commitsResult, writer)
+}
+
 // Deserialize converts the specified protobuf bytes to DevsResult.
 func (ca *CommitsAnalysis) Deserialize(pbmessage []byte) (interface{}, error) {
 	panic("not implemented")
@@ -253,12 +274,14 @@ func (ca *CommitsAnalysis) serializeText(result *CommitsResult, writer io.Writer
 		fmt.Fprintf(writer, "    - hash: %s\n", c.Hash)
 		fmt.Fprintf(writer, "      when: %d\n", c.When)
 		fmt.Fprintf(writer, "      author: %d\n", c.Author)
+		fmt.Fprintf(writer, "      merge: %t\n", c.Merge)
 		fmt.Fprintf(writer, "      files:\n")
 		for _, f := range c.Files {
 			fmt.Fprintf(writer, "       - to: %s\n", f.ToName)
 			fmt.Fprintf(writer, "         from: %s\n", f.FromName)
 			fmt.Fprintf(writer, "         language: %s\n", f.Language)
-			fmt.Fprintf(writer, "         stat: [%d, %d, %d]\n", f.Added, f.Changed, f.Removed)
+			fmt.Fprintf(writer, "         stat: [%d, %d, %d, %d]\n",
+				f.Added, f.Changed, f.Removed, f.Moved)
 		}
 	}
 	fmt.Fprintln(writer, "  people:")
@@ -267,6 +290,62 @@ func (ca *CommitsAnalysis) serializeText(result *CommitsResult, writer io.Writer
 	}
 }
 
+// commitsJSON is the JSON schema of CommitsResult, see doc/JSON.md.
+type commitsJSON struct {
+	Commits []commitJSON `json:"commits"`
+	People  []string     `json:"people"`
+}
+
+// commitJSON is the JSON schema of CommitStat, see doc/JSON.md.
+type commitJSON struct {
+	Hash   string         `json:"hash"`
+	When   int64          `json:"when"`
+	Author int            `json:"author"`
+	Merge  bool           `json:"merge"`
+	Files  []fileStatJSON `json:"files"`
+}
+
+// fileStatJSON is the JSON schema of FileStat, see doc/JSON.md.
+type fileStatJSON struct {
+	To       string        `json:"to"`
+	From     string        `json:"from"`
+	Language string        `json:"language"`
+	Stat     lineStatsJSON `json:"stat"`
+}
+
+func (ca *CommitsAnalysis) serializeJSON(result *CommitsResult, writer io.Writer) error {
+	message := commitsJSON{
+		Commits: make([]commitJSON, len(result.Commits)),
+		People:  result.reversedPeopleDict,
+	}
+	for i, c := range result.Commits {
+		files := make([]fileStatJSON, len(c.Files))
+		for j, f := range c.Files {
+			files[j] = fileStatJSON{
+				To:       f.ToName,
+				From:     f.FromName,
+				Language: f.Language,
+				Stat:     newLineStatsJSON(f.LineStats),
+			}
+		}
+		author := c.Author
+		if author == identity.AuthorMissing {
+			author = -1
+		}
+		message.Commits[i] = commitJSON{
+			Hash:   c.Hash,
+			When:   c.When,
+			Author: author,
+			Merge:  c.Merge,
+			Files:  files,
+		}
+	}
+	if message.People == nil {
+		message.People = []string{}
+	}
+	return json.NewEncoder(writer).Encode(&message)
+}
+
 func (ca *CommitsAnalysis) serializeBinary(result *CommitsResult, writer io.Writer) error {
 	message := pb.CommitsAnalysisResults{}
 	message.AuthorIndex = result.reversedPeopleDict
@@ -282,6 +361,7 @@ func (ca *CommitsAnalysis) serializeBinary(result *CommitsResult, writer io.Writ
 					Added:   int32(f.LineStats.Added),
 					Changed: int32(f.LineStats.Changed),
 					Removed: int32(f.LineStats.Removed),
+					Moved:   int32(f.LineStats.Moved),
 				},
 			}
 		}
@@ -291,6 +371,7 @@ func (ca *CommitsAnalysis) serializeBinary(result *CommitsResult, writer io.Writ
 			WhenUnixTime: c.When,
 			Author:       int32(c.Author),
 			Files:        files,
+			Merge:        c.Merge,
 		}
 	}
 	serialized, err := proto.Marshal(&message)
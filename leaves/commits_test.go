@@ -0,0 +1,99 @@
+package leaves
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	"gopkg.in/src-d/hercules.v8/internal/pb"
+	items "gopkg.in/src-d/hercules.v8/internal/plumbing"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+)
+
+// mergeLineStats runs MergeLinesStatsCalculator in the specified mode on the commit in `deps`.
+func mergeLineStats(t *testing.T, mode string, deps map[string]interface{}) []items.MergeFileLineStats {
+	mlsc := items.MergeLinesStatsCalculator{}
+	assert.Nil(t, mlsc.Configure(map[string]interface{}{items.ConfigMergeDiffMode: mode}))
+	assert.Nil(t, mlsc.Initialize(test.Repository))
+	result, err := mlsc.Consume(deps)
+	assert.Nil(t, err)
+	return result[items.DependencyMergeLineStats].([]items.MergeFileLineStats)
+}
+
+func TestCommitsConsumeMerge(t *testing.T) {
+	commits := CommitsAnalysis{}
+	assert.Nil(t, commits.Configure(map[string]interface{}{
+		items.ConfigMergeDiffMode:                       items.MergeDiffCombined,
+		identity.FactIdentityDetectorReversedPeopleDict: []string{"one@srcd", "two@srcd"},
+	}))
+	assert.Nil(t, commits.Initialize(test.Repository))
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 1
+	deps[items.DependencyTreeChanges] = object.Changes{}
+	deps[core.DependencyIsMerge] = true
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"fe00c0d50719a23ee77370dc29984fe4e16b2090"))
+	deps[items.DependencyMergeLineStats] = mergeLineStats(t, items.MergeDiffCombined, deps)
+	result, err := commits.Consume(deps)
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+	commitsResult := commits.Finalize().(CommitsResult)
+	assert.Len(t, commitsResult.Commits, 1)
+	stat := commitsResult.Commits[0]
+	assert.Equal(t, "fe00c0d50719a23ee77370dc29984fe4e16b2090", stat.Hash)
+	assert.Equal(t, 1, stat.Author)
+	assert.True(t, stat.Merge)
+	assert.Len(t, stat.Files, 1)
+	assert.Equal(t, "README.md", stat.Files[0].ToName)
+	assert.Equal(t, LineStats{Added: 8, Removed: 0, Changed: 2}, stat.Files[0].LineStats)
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, commits.Serialize(commitsResult, false, buffer))
+	assert.True(t, strings.Contains(buffer.String(), "      merge: true\n"))
+	buffer.Reset()
+	assert.Nil(t, commits.SerializeJSON(commitsResult, buffer))
+	parsed := commitsJSON{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &parsed))
+	assert.True(t, parsed.Commits[0].Merge)
+	buffer.Reset()
+	assert.Nil(t, commits.Serialize(commitsResult, true, buffer))
+	msg := pb.CommitsAnalysisResults{}
+	assert.Nil(t, proto.Unmarshal(buffer.Bytes(), &msg))
+	assert.True(t, msg.Commits[0].Merge)
+
+	commits = CommitsAnalysis{}
+	assert.Nil(t, commits.Configure(map[string]interface{}{
+		items.ConfigMergeDiffMode: items.MergeDiffNone}))
+	assert.True(t, commits.SkipMerges)
+	assert.Nil(t, commits.Initialize(test.Repository))
+	result, err = commits.Consume(deps)
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+	assert.Len(t, commits.Finalize().(CommitsResult).Commits, 0)
+}
+
+func TestCommitsConsumeBotsExcluded(t *testing.T) {
+	commits := CommitsAnalysis{}
+	assert.Nil(t, commits.Configure(map[string]interface{}{}))
+	assert.Nil(t, commits.Initialize(test.Repository))
+	deps := map[string]interface{}{}
+	// the excluded bot commits are not credited to anybody
+	deps[identity.DependencyAuthor] = identity.AuthorMissing
+	deps[identity.DependencyAuthors] = []int{}
+	deps[items.DependencyTreeChanges] = object.Changes{}
+	deps[core.DependencyIsMerge] = true
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"fe00c0d50719a23ee77370dc29984fe4e16b2090"))
+	deps[items.DependencyMergeLineStats] = mergeLineStats(t, items.MergeDiffCombined, deps)
+	result, err := commits.Consume(deps)
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+	assert.Len(t, commits.Finalize().(CommitsResult).Commits, 0)
+}
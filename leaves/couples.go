@@ -1,6 +1,8 @@
 package leaves
 
 import (
+	"encoding/gob"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -38,6 +40,12 @@ type CouplesAnalysis struct {
 	lastCommit *object.Commit
 	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
 	reversedPeopleDict []string
+	// teams references IdentityDetector.Teams
+	teams *identity.Teams
+	// credit is the policy of crediting the co-authors of the same commit.
+	credit identity.CoAuthorsCredit
+	// submodules references TreeDiff.Submodules
+	submodules *items.Submodules
 }
 
 // CouplesResult is returned by CouplesAnalysis.Finalize() and carries couples matrices from
@@ -56,9 +64,16 @@ type CouplesResult struct {
 	FilesLines []int
 	// Files is the names of the files. The order matches PeopleFiles' indexes and FilesMatrix.
 	Files []string
+	// TeamMatrix is the same as PeopleMatrix, but for teams. The commit counts of the team members
+	// are summed per file. It is nil if the teams were not specified.
+	TeamMatrix []map[int]int64
+	// TeamFiles is the same as PeopleFiles, but for teams.
+	TeamFiles [][]int
 
 	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
 	reversedPeopleDict []string
+	// reversedTeamsDict references identity.Teams.Names
+	reversedTeamsDict []string
 }
 
 const (
@@ -88,7 +103,9 @@ func (couples *CouplesAnalysis) Provides() []string {
 // Each requested entity will be inserted into `deps` of Consume(). In turn, those
 // entities are Provides() upstream.
 func (couples *CouplesAnalysis) Requires() []string {
-	arr := [...]string{identity.DependencyAuthor, items.DependencyTreeChanges}
+	arr := [...]string{
+		identity.DependencyAuthor, items.DependencyTreeChanges, identity.DependencyAuthors,
+		items.DependencyIgnoredCommit}
 	return arr[:]
 }
 
@@ -103,6 +120,15 @@ func (couples *CouplesAnalysis) Configure(facts map[string]interface{}) error {
 		couples.PeopleNumber = val
 		couples.reversedPeopleDict = facts[identity.FactIdentityDetectorReversedPeopleDict].([]string)
 	}
+	if val, exists := facts[identity.FactIdentityDetectorTeams].(*identity.Teams); exists {
+		couples.teams = val
+	}
+	if val, exists := facts[identity.FactIdentityDetectorCoAuthorsCredit].(identity.CoAuthorsCredit); exists {
+		couples.credit = val
+	}
+	if val, exists := facts[items.FactTreeDiffSubmodules].(*items.Submodules); exists {
+		couples.submodules = val
+	}
 	return nil
 }
 
@@ -141,12 +167,36 @@ func (couples *CouplesAnalysis) Consume(deps map[string]interface{}) (map[string
 	firstMerge := couples.ShouldConsumeCommit(deps)
 	mergeMode := deps[core.DependencyIsMerge].(bool)
 	couples.lastCommit = deps[core.DependencyCommit].(*object.Commit)
-	author := deps[identity.DependencyAuthor].(int)
-	if author == identity.AuthorMissing {
-		author = couples.PeopleNumber
-	}
-	if firstMerge {
-		couples.peopleCommits[author]++
+	authors := append([]int{}, commitAuthors(deps)...)
+	// the ignored commits change the files, e.g. rename them, but do not couple anything
+	ignored, _ := deps[items.DependencyIgnoredCommit].(bool)
+	if ignored {
+		authors = nil
+	}
+	for i, author := range authors {
+		if author == identity.AuthorMissing {
+			authors[i] = couples.PeopleNumber
+		}
+		if firstMerge {
+			// a commit cannot be divided, so it counts for every author
+			couples.peopleCommits[authors[i]]++
+		}
+	}
+	// touched credits the file to all the authors or, if the credit is split,
+	// distributes the files among them in turn
+	touches := 0
+	touched := func(name string) {
+		if len(authors) == 0 {
+			return
+		}
+		if couples.credit == identity.CoAuthorsCreditSplit {
+			couples.people[authors[touches%len(authors)]][name]++
+		} else {
+			for _, author := range authors {
+				couples.people[author][name]++
+			}
+		}
+		touches++
 	}
 	treeDiff := deps[items.DependencyTreeChanges].(object.Changes)
 	context := make([]string, 0, len(treeDiff))
@@ -161,11 +211,11 @@ func (couples *CouplesAnalysis) Consume(deps map[string]interface{}) (map[string
 		case merkletrie.Insert:
 			if !mergeMode || couples.files[toName] == nil {
 				context = append(context, toName)
-				couples.people[author][toName]++
+				touched(toName)
 			}
 		case merkletrie.Delete:
 			if !mergeMode {
-				couples.people[author][fromName]++
+				touched(fromName)
 			}
 		case merkletrie.Modify:
 			if fromName != toName {
@@ -175,11 +225,11 @@ func (couples *CouplesAnalysis) Consume(deps map[string]interface{}) (map[string
 			}
 			if !mergeMode || couples.files[toName] == nil {
 				context = append(context, toName)
-				couples.people[author][toName]++
+				touched(toName)
 			}
 		}
 	}
-	if len(context) <= CouplesMaximumMeaningfulContextSize {
+	if !ignored && len(context) <= CouplesMaximumMeaningfulContextSize {
 		for _, file := range context {
 			for _, otherFile := range context {
 				lane, exists := couples.files[file]
@@ -210,7 +260,7 @@ func (couples *CouplesAnalysis) Finalize() interface{} {
 	}
 	filesLines := make([]int, len(filesSequence))
 	for i, name := range filesSequence {
-		file, err := couples.lastCommit.File(name)
+		file, err := couples.submodules.File(couples.lastCommit, name)
 		if err != nil {
 			log.Panicf("cannot find file %s in commit %s: %v",
 				name, couples.lastCommit.Hash.String(), err)
@@ -224,8 +274,51 @@ func (couples *CouplesAnalysis) Finalize() interface{} {
 		filesLines[i], _ = blob.CountLines()
 	}
 
-	peopleMatrix := make([]map[int]int64, couples.PeopleNumber+1)
-	peopleFiles := make([][]int, couples.PeopleNumber+1)
+	peopleMatrix, peopleFiles := couplesPeopleMatrix(people, filesIndex)
+
+	filesMatrix := make([]map[int]int64, len(filesIndex))
+	for i := range filesMatrix {
+		filesMatrix[i] = map[int]int64{}
+		for otherFile, cooccs := range files[filesSequence[i]] {
+			filesMatrix[i][filesIndex[otherFile]] = int64(cooccs)
+		}
+	}
+	result := CouplesResult{
+		PeopleMatrix:       peopleMatrix,
+		PeopleFiles:        peopleFiles,
+		Files:              filesSequence,
+		FilesLines:         filesLines,
+		FilesMatrix:        filesMatrix,
+		reversedPeopleDict: couples.reversedPeopleDict,
+	}
+	if couples.teams != nil {
+		// the last team is reserved for the missing authors, the same as with people
+		teamsNumber := len(couples.teams.Names)
+		teamPeople := make([]map[string]int, teamsNumber+1)
+		for i := range teamPeople {
+			teamPeople[i] = map[string]int{}
+		}
+		for i, counts := range people {
+			team := teamsNumber
+			if i < couples.PeopleNumber {
+				team = couples.teams.Team(i)
+			}
+			for file, count := range counts {
+				teamPeople[team][file] += count
+			}
+		}
+		result.TeamMatrix, result.TeamFiles = couplesPeopleMatrix(teamPeople, filesIndex)
+		result.reversedTeamsDict = couples.teams.Names
+	}
+	return result
+}
+
+// couplesPeopleMatrix calculates how many times each pair of developers changed the same files
+// and which files each developer changed. `people` are the per-file commit counts.
+func couplesPeopleMatrix(people []map[string]int, filesIndex map[string]int) (
+	[]map[int]int64, [][]int) {
+	peopleMatrix := make([]map[int]int64, len(people))
+	peopleFiles := make([][]int, len(people))
 	for i := range peopleMatrix {
 		peopleMatrix[i] = map[int]int64{}
 		for file, commits := range people[i] {
@@ -245,22 +338,7 @@ func (couples *CouplesAnalysis) Finalize() interface{} {
 		}
 		sort.Ints(peopleFiles[i])
 	}
-
-	filesMatrix := make([]map[int]int64, len(filesIndex))
-	for i := range filesMatrix {
-		filesMatrix[i] = map[int]int64{}
-		for otherFile, cooccs := range files[filesSequence[i]] {
-			filesMatrix[i][filesIndex[otherFile]] = int64(cooccs)
-		}
-	}
-	return CouplesResult{
-		PeopleMatrix:       peopleMatrix,
-		PeopleFiles:        peopleFiles,
-		Files:              filesSequence,
-		FilesLines:         filesLines,
-		FilesMatrix:        filesMatrix,
-		reversedPeopleDict: couples.reversedPeopleDict,
-	}
+	return peopleMatrix, peopleFiles
 }
 
 // Fork clones this pipeline item.
@@ -268,6 +346,55 @@ func (couples *CouplesAnalysis) Fork(n int) []core.PipelineItem {
 	return core.ForkCopyPipelineItem(couples, n)
 }
 
+// couplesCheckpoint is the state of CouplesAnalysis which is saved in Checkpoint().
+type couplesCheckpoint struct {
+	PeopleNumber  int
+	People        []map[string]int
+	PeopleCommits []int
+	Files         map[string]map[string]int
+	Renames       []rename
+}
+
+// Checkpoint writes the accumulated co-occurrence counters.
+func (couples *CouplesAnalysis) Checkpoint(writer io.Writer) error {
+	return gob.NewEncoder(writer).Encode(&couplesCheckpoint{
+		PeopleNumber:  couples.PeopleNumber,
+		People:        couples.people,
+		PeopleCommits: couples.peopleCommits,
+		Files:         couples.files,
+		Renames:       *couples.renames,
+	})
+}
+
+// Restore reads the state previously written by Checkpoint(). The number of people
+// may grow since the checkpoint, so the unmatched author's counters are moved to the new place.
+func (couples *CouplesAnalysis) Restore(reader io.Reader, facts map[string]interface{}) error {
+	state := couplesCheckpoint{}
+	err := gob.NewDecoder(reader).Decode(&state)
+	if err != nil {
+		return err
+	}
+	if state.PeopleNumber > couples.PeopleNumber {
+		return fmt.Errorf("the checkpoint has more people than now: %d > %d",
+			state.PeopleNumber, couples.PeopleNumber)
+	}
+	for i, files := range state.People {
+		author := i
+		if i == state.PeopleNumber {
+			author = couples.PeopleNumber
+		}
+		for file, count := range files {
+			couples.people[author][file] = count
+		}
+		couples.peopleCommits[author] = state.PeopleCommits[i]
+	}
+	for file, lane := range state.Files {
+		couples.files[file] = lane
+	}
+	*couples.renames = append(*couples.renames, state.Renames...)
+	return nil
+}
+
 // Serialize converts the analysis result as returned by Finalize() to text or bytes.
 // The text format is YAML and the bytes format is Protocol Buffers.
 func (couples *CouplesAnalysis) Serialize(result interface{}, binary bool, writer io.Writer) error {
@@ -279,6 +406,12 @@ func (couples *CouplesAnalysis) Serialize(result interface{}, binary bool, write
 	return nil
 }
 
+// SerializeJSON converts the analysis result as returned by Finalize() to JSON.
+func (couples *CouplesAnalysis) SerializeJSON(result interface{}, writer io.Writer) error {
+	couplesResult := result.(CouplesResult)
+	return couples.serializeJSON(&couplesResult, writer)
+}
+
 // Deserialize converts the specified protobuf bytes to CouplesResult.
 func (couples *CouplesAnalysis) Deserialize(pbmessage []byte) (interface{}, error) {
 	message := pb.CouplesAnalysisResults{}
@@ -314,6 +447,18 @@ func (couples *CouplesAnalysis) Deserialize(pbmessage []byte) (interface{}, erro
 	}
 	convertCSR(result.FilesMatrix, message.FileCouples.Matrix)
 	convertCSR(result.PeopleMatrix, message.PeopleCouples.Matrix)
+	if message.TeamCouples != nil {
+		result.reversedTeamsDict = message.TeamCouples.Index
+		result.TeamMatrix = make([]map[int]int64, message.TeamCouples.Matrix.NumberOfRows)
+		result.TeamFiles = make([][]int, len(message.TeamCouples.Index))
+		for i, files := range message.TeamFiles {
+			result.TeamFiles[i] = make([]int, len(files.Files))
+			for j, val := range files.Files {
+				result.TeamFiles[i][j] = int(val)
+			}
+		}
+		convertCSR(result.TeamMatrix, message.TeamCouples.Matrix)
+	}
 	return result, nil
 }
 
@@ -364,27 +509,28 @@ func (couples *CouplesAnalysis) MergeResults(r1, r2 interface{}, c1, c2 *core.Co
 		sort.Ints(merged.PeopleFiles[i])
 	}
 	merged.PeopleMatrix = make([]map[int]int64, len(merged.reversedPeopleDict)+1)
-	addPeople := func(peopleMatrix []map[int]int64, reversedPeopleDict []string,
-		reversedFilesDict []string) {
-		for pi, pc := range peopleMatrix {
-			var idx int
+	addPeople := func(peopleMatrix []map[int]int64, reversedPeopleDict []string) {
+		// the last row and column belong to the missing authors
+		mergedIndex := func(pi int) int {
 			if pi < len(reversedPeopleDict) {
-				idx = people[reversedPeopleDict[pi]][0]
-			} else {
-				idx = len(merged.reversedPeopleDict)
+				return people[reversedPeopleDict[pi]][0]
 			}
+			return len(merged.reversedPeopleDict)
+		}
+		for pi, pc := range peopleMatrix {
+			idx := mergedIndex(pi)
 			m := merged.PeopleMatrix[idx]
 			if m == nil {
 				m = map[int]int64{}
 				merged.PeopleMatrix[idx] = m
 			}
-			for file, val := range pc {
-				m[files[reversedFilesDict[file]][0]] += val
+			for other, val := range pc {
+				m[mergedIndex(other)] += val
 			}
 		}
 	}
-	addPeople(cr1.PeopleMatrix, cr1.reversedPeopleDict, cr1.Files)
-	addPeople(cr2.PeopleMatrix, cr2.reversedPeopleDict, cr2.Files)
+	addPeople(cr1.PeopleMatrix, cr1.reversedPeopleDict)
+	addPeople(cr2.PeopleMatrix, cr2.reversedPeopleDict)
 	merged.FilesMatrix = make([]map[int]int64, len(merged.Files))
 	addFiles := func(filesMatrix []map[int]int64, reversedFilesDict []string) {
 		for fi, fc := range filesMatrix {
@@ -401,6 +547,22 @@ func (couples *CouplesAnalysis) MergeResults(r1, r2 interface{}, c1, c2 *core.Co
 	}
 	addFiles(cr1.FilesMatrix, cr1.Files)
 	addFiles(cr2.FilesMatrix, cr2.Files)
+	if cr1.TeamMatrix != nil && cr2.TeamMatrix != nil {
+		// merge the teams the same way as people, the files are merged in the same order
+		teamsView := func(cr CouplesResult) CouplesResult {
+			return CouplesResult{
+				PeopleMatrix:       cr.TeamMatrix,
+				PeopleFiles:        cr.TeamFiles,
+				Files:              cr.Files,
+				FilesLines:         cr.FilesLines,
+				reversedPeopleDict: cr.reversedTeamsDict,
+			}
+		}
+		mergedTeams := couples.MergeResults(teamsView(cr1), teamsView(cr2), c1, c2).(CouplesResult)
+		merged.TeamMatrix = mergedTeams.PeopleMatrix
+		merged.TeamFiles = mergedTeams.PeopleFiles
+		merged.reversedTeamsDict = mergedTeams.reversedPeopleDict
+	}
 	return merged
 }
 
@@ -433,13 +595,26 @@ func (couples *CouplesAnalysis) serializeText(result *CouplesResult, writer io.W
 	}
 
 	fmt.Fprintln(writer, "  people_coocc:")
+	serializePeopleCouplesText(
+		result.PeopleMatrix, result.PeopleFiles, result.reversedPeopleDict, result.Files, writer)
+	if result.TeamMatrix != nil {
+		fmt.Fprintln(writer, "  team_coocc:")
+		serializePeopleCouplesText(
+			result.TeamMatrix, result.TeamFiles, result.reversedTeamsDict, result.Files, writer)
+	}
+}
+
+// serializePeopleCouplesText writes the developers' (or teams') co-occurrence matrix
+// and the changed files as YAML.
+func serializePeopleCouplesText(peopleMatrix []map[int]int64, peopleFiles [][]int,
+	peopleDict []string, filesDict []string, writer io.Writer) {
 	fmt.Fprintln(writer, "    index:")
-	for _, person := range result.reversedPeopleDict {
+	for _, person := range peopleDict {
 		fmt.Fprintf(writer, "      - %s\n", yaml.SafeString(person))
 	}
 
 	fmt.Fprintln(writer, "    matrix:")
-	for _, people := range result.PeopleMatrix {
+	for _, people := range peopleMatrix {
 		fmt.Fprint(writer, "      - {")
 		var indices []int
 		for file := range people {
@@ -456,8 +631,7 @@ func (couples *CouplesAnalysis) serializeText(result *CouplesResult, writer io.W
 	}
 
 	fmt.Fprintln(writer, "    author_files:") // sorted by number of files each author changed
-	peopleFiles := sortByNumberOfFiles(result.PeopleFiles, result.reversedPeopleDict, result.Files)
-	for _, authorFiles := range peopleFiles {
+	for _, authorFiles := range sortByNumberOfFiles(peopleFiles, peopleDict, filesDict) {
 		fmt.Fprintf(writer, "      - %s:\n", yaml.SafeString(authorFiles.Author))
 		sort.Strings(authorFiles.Files)
 		for _, file := range authorFiles.Files {
@@ -483,8 +657,8 @@ func sortByNumberOfFiles(
 }
 
 type authorFiles struct {
-	Author string
-	Files  []string
+	Author string   `json:"author"`
+	Files  []string `json:"files"`
 }
 
 type authorFilesList []authorFiles
@@ -499,6 +673,67 @@ func (s authorFilesList) Less(i, j int) bool {
 	return len(s[i].Files) < len(s[j].Files)
 }
 
+// couplesJSON is the JSON schema of CouplesResult, see doc/JSON.md.
+type couplesJSON struct {
+	FilesCoocc struct {
+		Index  []string        `json:"index"`
+		Lines  []int           `json:"lines"`
+		Matrix []map[int]int64 `json:"matrix"`
+	} `json:"files_coocc"`
+	PeopleCoocc peopleCouplesJSON  `json:"people_coocc"`
+	TeamCoocc   *peopleCouplesJSON `json:"team_coocc,omitempty"`
+}
+
+// peopleCouplesJSON is the JSON schema of the developers' or teams' co-occurrences.
+type peopleCouplesJSON struct {
+	Index       []string        `json:"index"`
+	Matrix      []map[int]int64 `json:"matrix"`
+	AuthorFiles []authorFiles   `json:"author_files"`
+}
+
+func newPeopleCouplesJSON(peopleMatrix []map[int]int64, peopleFiles [][]int,
+	peopleDict []string, filesDict []string) peopleCouplesJSON {
+	message := peopleCouplesJSON{
+		Index:       peopleDict,
+		Matrix:      couplesMatrixToJSON(peopleMatrix),
+		AuthorFiles: sortByNumberOfFiles(peopleFiles, peopleDict, filesDict),
+	}
+	for _, entry := range message.AuthorFiles {
+		sort.Strings(entry.Files)
+	}
+	if message.AuthorFiles == nil {
+		message.AuthorFiles = []authorFiles{}
+	}
+	return message
+}
+
+// couplesMatrixToJSON replaces nil rows with empty mappings so that they are not written as null.
+func couplesMatrixToJSON(matrix []map[int]int64) []map[int]int64 {
+	result := make([]map[int]int64, len(matrix))
+	for i, row := range matrix {
+		if row == nil {
+			row = map[int]int64{}
+		}
+		result[i] = row
+	}
+	return result
+}
+
+func (couples *CouplesAnalysis) serializeJSON(result *CouplesResult, writer io.Writer) error {
+	message := couplesJSON{}
+	message.FilesCoocc.Index = result.Files
+	message.FilesCoocc.Lines = result.FilesLines
+	message.FilesCoocc.Matrix = couplesMatrixToJSON(result.FilesMatrix)
+	message.PeopleCoocc = newPeopleCouplesJSON(
+		result.PeopleMatrix, result.PeopleFiles, result.reversedPeopleDict, result.Files)
+	if result.TeamMatrix != nil {
+		teamCoocc := newPeopleCouplesJSON(
+			result.TeamMatrix, result.TeamFiles, result.reversedTeamsDict, result.Files)
+		message.TeamCoocc = &teamCoocc
+	}
+	return json.NewEncoder(writer).Encode(&message)
+}
+
 func (couples *CouplesAnalysis) serializeBinary(result *CouplesResult, writer io.Writer) error {
 	message := pb.CouplesAnalysisResults{}
 
@@ -510,21 +745,18 @@ func (couples *CouplesAnalysis) serializeBinary(result *CouplesResult, writer io
 		Index:  result.reversedPeopleDict,
 		Matrix: pb.MapToCompressedSparseRowMatrix(result.PeopleMatrix),
 	}
-	message.PeopleFiles = make([]*pb.TouchedFiles, len(result.reversedPeopleDict))
-	for key := range result.reversedPeopleDict {
-		files := result.PeopleFiles[key]
-		int32Files := make([]int32, len(files))
-		for i, f := range files {
-			int32Files[i] = int32(f)
-		}
-		message.PeopleFiles[key] = &pb.TouchedFiles{
-			Files: int32Files,
-		}
-	}
+	message.PeopleFiles = touchedFilesToProto(result.PeopleFiles, len(result.reversedPeopleDict))
 	message.FilesLines = make([]int32, len(result.FilesLines))
 	for i, l := range result.FilesLines {
 		message.FilesLines[i] = int32(l)
 	}
+	if result.TeamMatrix != nil {
+		message.TeamCouples = &pb.Couples{
+			Index:  result.reversedTeamsDict,
+			Matrix: pb.MapToCompressedSparseRowMatrix(result.TeamMatrix),
+		}
+		message.TeamFiles = touchedFilesToProto(result.TeamFiles, len(result.reversedTeamsDict))
+	}
 
 	serialized, err := proto.Marshal(&message)
 	if err != nil {
@@ -534,6 +766,23 @@ func (couples *CouplesAnalysis) serializeBinary(result *CouplesResult, writer io
 	return err
 }
 
+// touchedFilesToProto converts the files changed by the first `size` developers (or teams)
+// to Protocol Buffers.
+func touchedFilesToProto(peopleFiles [][]int, size int) []*pb.TouchedFiles {
+	message := make([]*pb.TouchedFiles, size)
+	for key := range message {
+		files := peopleFiles[key]
+		int32Files := make([]int32, len(files))
+		for i, f := range files {
+			int32Files[i] = int32(f)
+		}
+		message[key] = &pb.TouchedFiles{
+			Files: int32Files,
+		}
+	}
+	return message
+}
+
 // currentFiles return the list of files in the last consumed commit.
 func (couples *CouplesAnalysis) currentFiles() map[string]bool {
 	files := map[string]bool{}
@@ -548,6 +797,14 @@ func (couples *CouplesAnalysis) currentFiles() map[string]bool {
 		files[fobj.Name] = true
 		return nil
 	})
+	submoduleFiles, err := couples.submodules.ListFiles(tree)
+	if err != nil {
+		log.Panicf("cannot list the submodule files in commit %s: %v",
+			couples.lastCommit.Hash.String(), err)
+	}
+	for _, change := range submoduleFiles {
+		files[change.To.Name] = true
+	}
 	return files
 }
 
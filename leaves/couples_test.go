@@ -2,6 +2,7 @@ package leaves
 
 import (
 	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"path"
 	"strings"
@@ -28,9 +29,11 @@ func TestCouplesMeta(t *testing.T) {
 	c := fixtureCouples()
 	assert.Equal(t, c.Name(), "Couples")
 	assert.Equal(t, len(c.Provides()), 0)
-	assert.Equal(t, len(c.Requires()), 2)
+	assert.Equal(t, len(c.Requires()), 4)
 	assert.Equal(t, c.Requires()[0], identity.DependencyAuthor)
 	assert.Equal(t, c.Requires()[1], plumbing.DependencyTreeChanges)
+	assert.Equal(t, c.Requires()[2], identity.DependencyAuthors)
+	assert.Equal(t, c.Requires()[3], plumbing.DependencyIgnoredCommit)
 	assert.Equal(t, c.Flag(), "couples")
 	assert.Len(t, c.ListConfigurationOptions(), 0)
 }
@@ -193,6 +196,81 @@ func TestCouplesConsumeFinalize(t *testing.T) {
 	assert.Equal(t, cr.FilesMatrix[2][2], int64(3))
 }
 
+func TestCouplesConsumeFinalizeTeams(t *testing.T) {
+	c := fixtureCouples()
+	teams := fixtureTeams(t, "core: [one, three]\n", map[string]int{"one": 0, "two": 1, "three": 2})
+	assert.Nil(t, c.Configure(map[string]interface{}{identity.FactIdentityDetectorTeams: teams}))
+	c.reversedPeopleDict = []string{"one", "two", "three"}
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 0
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
+		"a3ee37f91f0d705ec9c41ae88426f0ae44b2fbc3"))
+	deps[core.DependencyIsMerge] = false
+	deps[plumbing.DependencyTreeChanges] = generateChanges("+LICENSE2", "+file2.go", "+rbtree2.go")
+	c.Consume(deps)
+	deps[plumbing.DependencyTreeChanges] = generateChanges("+README.md", "-LICENSE2", "=analyser.go", ">file2.go>file_test.go")
+	c.Consume(deps)
+	deps[identity.DependencyAuthor] = 1
+	deps[plumbing.DependencyTreeChanges] = generateChanges("=README.md", "=analyser.go", "-rbtree2.go")
+	c.Consume(deps)
+	deps[identity.DependencyAuthor] = 2
+	deps[plumbing.DependencyTreeChanges] = generateChanges("=file_test.go")
+	c.Consume(deps)
+	deps[identity.DependencyAuthor] = identity.AuthorMissing
+	deps[plumbing.DependencyTreeChanges] = generateChanges("=README.md")
+	c.Consume(deps)
+	cr := c.Finalize().(CouplesResult)
+	assert.Equal(t, []string{"core", identity.TeamMissingName}, cr.reversedTeamsDict)
+	// "core" sums the commits of "one" and "three", the last row is for the missing authors
+	assert.Equal(t, []map[int]int64{
+		{0: 8, 1: 3, 2: 1}, {0: 3, 1: 3, 2: 1}, {0: 1, 1: 1, 2: 1},
+	}, cr.TeamMatrix)
+	assert.Equal(t, [][]int{{0, 1, 2}, {0, 1}, {0}}, cr.TeamFiles)
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, c.Serialize(cr, false, buffer))
+	assert.Contains(t, buffer.String(), `  team_coocc:
+    index:
+      - "core"
+      - "<unassigned>"
+    matrix:
+      - {0: 8, 1: 3, 2: 1}
+      - {0: 3, 1: 3, 2: 1}
+      - {0: 1, 1: 1, 2: 1}
+    author_files:
+      - "<unassigned>":
+        - "README.md"
+        - "analyser.go"
+      - "core":
+        - "README.md"
+        - "analyser.go"
+        - "file_test.go"
+`)
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, c.SerializeJSON(cr, buffer))
+	assert.Contains(t, buffer.String(), `"team_coocc":{"index":["core","\u003cunassigned\u003e"],`+
+		`"matrix":[{"0":8,"1":3,"2":1},{"0":3,"1":3,"2":1},{"0":1,"1":1,"2":1}]`)
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, c.Serialize(cr, true, buffer))
+	rawcr2, err := c.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	cr2 := rawcr2.(CouplesResult)
+	assert.Equal(t, cr.TeamMatrix, cr2.TeamMatrix)
+	assert.Equal(t, cr.TeamFiles[:2], cr2.TeamFiles)
+	assert.Equal(t, cr.reversedTeamsDict, cr2.reversedTeamsDict)
+
+	merged := c.MergeResults(cr2, cr2, nil, nil).(CouplesResult)
+	assert.Equal(t, cr.reversedTeamsDict, merged.reversedTeamsDict)
+	assert.Equal(t, []map[int]int64{
+		{0: 16, 1: 6, 2: 2}, {0: 6, 1: 6, 2: 2}, {0: 2, 1: 2, 2: 2},
+	}, merged.TeamMatrix)
+	assert.Equal(t, cr.TeamFiles[:2], merged.TeamFiles)
+	cr3 := cr2
+	cr3.TeamMatrix = nil
+	merged = c.MergeResults(cr2, cr3, nil, nil).(CouplesResult)
+	assert.Nil(t, merged.TeamMatrix)
+}
+
 func TestCouplesConsumeFinalizeMerge(t *testing.T) {
 	c := fixtureCouples()
 	deps := map[string]interface{}{}
@@ -358,6 +436,36 @@ func TestCouplesFork(t *testing.T) {
 	couples1.Merge([]core.PipelineItem{couples2})
 }
 
+func TestCouplesCheckpointRestore(t *testing.T) {
+	c := fixtureCouples()
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 0
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
+		"a3ee37f91f0d705ec9c41ae88426f0ae44b2fbc3"))
+	deps[core.DependencyIsMerge] = false
+	deps[plumbing.DependencyTreeChanges] = generateChanges("+LICENSE2", "+file2.go", "+rbtree2.go")
+	c.Consume(deps)
+	deps[identity.DependencyAuthor] = identity.AuthorMissing
+	deps[plumbing.DependencyTreeChanges] = generateChanges("=LICENSE2", ">file2.go>file_test.go")
+	c.Consume(deps)
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, c.Checkpoint(buffer))
+	restored := &CouplesAnalysis{PeopleNumber: 4}
+	assert.Nil(t, restored.Initialize(test.Repository))
+	assert.Nil(t, restored.Restore(buffer, map[string]interface{}{}))
+	assert.Equal(t, c.people[0], restored.people[0])
+	assert.Equal(t, c.people[3], restored.people[4])
+	assert.Len(t, restored.people[3], 0)
+	assert.Equal(t, []int{1, 0, 0, 0, 1}, restored.peopleCommits)
+	assert.Equal(t, c.files, restored.files)
+	assert.Equal(t, *c.renames, *restored.renames)
+	restored = &CouplesAnalysis{PeopleNumber: 2}
+	assert.Nil(t, restored.Initialize(test.Repository))
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, c.Checkpoint(buffer))
+	assert.NotNil(t, restored.Restore(buffer, map[string]interface{}{}))
+}
+
 func TestCouplesSerialize(t *testing.T) {
 	c := fixtureCouples()
 	result := CouplesResult{
@@ -443,6 +551,41 @@ func TestCouplesSerialize(t *testing.T) {
 	assert.Equal(t, msg.FileCouples.Matrix.Indptr, indptr2[:])
 }
 
+func TestCouplesSerializeJSON(t *testing.T) {
+	c := fixtureCouples()
+	result := CouplesResult{
+		PeopleMatrix: []map[int]int64{
+			{0: 7, 1: 3, 2: 1}, {0: 3, 1: 3}, {0: 1, 2: 1}, nil,
+		},
+		PeopleFiles: [][]int{
+			{0, 1, 2}, {1, 2}, {0}, {},
+		},
+		FilesMatrix: []map[int]int64{
+			{1: 1, 2: 1, 0: 3}, {1: 2, 2: 2, 0: 1}, {2: 2, 0: 1, 1: 2},
+		},
+		Files:              []string{"five", "one", "three"},
+		FilesLines:         []int{9, 8, 7},
+		reversedPeopleDict: []string{"p1", "p2", "p3"},
+	}
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, c.SerializeJSON(result, buffer))
+	assert.NotContains(t, buffer.String(), "null")
+	msg := couplesJSON{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &msg))
+	assert.Equal(t, result.Files, msg.FilesCoocc.Index)
+	assert.Equal(t, result.FilesLines, msg.FilesCoocc.Lines)
+	assert.Equal(t, result.FilesMatrix, msg.FilesCoocc.Matrix)
+	assert.Equal(t, result.reversedPeopleDict, msg.PeopleCoocc.Index)
+	assert.Equal(t, []map[int]int64{
+		{0: 7, 1: 3, 2: 1}, {0: 3, 1: 3}, {0: 1, 2: 1}, {},
+	}, msg.PeopleCoocc.Matrix)
+	assert.Equal(t, []authorFiles{
+		{"p3", []string{"five"}},
+		{"p2", []string{"one", "three"}},
+		{"p1", []string{"five", "one", "three"}},
+	}, msg.PeopleCoocc.AuthorFiles)
+}
+
 func TestCouplesDeserialize(t *testing.T) {
 	message, err := ioutil.ReadFile(path.Join("..", "internal", "test_data", "couples.pb"))
 	assert.Nil(t, err)
@@ -535,6 +678,18 @@ func TestCouplesCurrentFiles(t *testing.T) {
 	assert.Equal(t, files, map[string]bool{".gitignore": true, "LICENSE": true})
 }
 
+func TestCouplesConfigureSubmodules(t *testing.T) {
+	c := fixtureCouples()
+	submodules := &plumbing.Submodules{}
+	assert.Nil(t, c.Configure(map[string]interface{}{
+		plumbing.FactTreeDiffSubmodules: submodules,
+	}))
+	assert.Equal(t, submodules, c.submodules)
+	c.lastCommit, _ = test.Repository.CommitObject(gitplumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	assert.Equal(t, c.currentFiles(), map[string]bool{".gitignore": true, "LICENSE": true})
+}
+
 func TestCouplesPropagateRenames(t *testing.T) {
 	c := fixtureCouples()
 	c.files["one"] = map[string]int{
@@ -587,3 +742,66 @@ func getCouplesMap(vals ...int) map[int]int64 {
 	}
 	return res
 }
+
+func TestCouplesConsumeCoAuthors(t *testing.T) {
+	c := fixtureCouples()
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 0
+	deps[identity.DependencyAuthors] = []int{0, 2, identity.AuthorMissing}
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
+		"a3ee37f91f0d705ec9c41ae88426f0ae44b2fbc3"))
+	deps[core.DependencyIsMerge] = false
+	deps[plumbing.DependencyTreeChanges] = generateChanges("+LICENSE2", "+file2.go", "+rbtree2.go", "+README.md")
+	_, err := c.Consume(deps)
+	assert.Nil(t, err)
+	for _, author := range []int{0, 2, c.PeopleNumber} {
+		assert.Len(t, c.people[author], 4)
+		assert.Equal(t, 1, c.peopleCommits[author])
+	}
+	assert.Len(t, c.people[1], 0)
+
+	c = fixtureCouples()
+	assert.Nil(t, c.Configure(map[string]interface{}{
+		identity.FactIdentityDetectorCoAuthorsCredit: identity.CoAuthorsCreditSplit,
+	}))
+	_, err = c.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"LICENSE2": 1, "README.md": 1}, c.people[0])
+	assert.Equal(t, map[string]int{"file2.go": 1}, c.people[2])
+	assert.Equal(t, map[string]int{"rbtree2.go": 1}, c.people[c.PeopleNumber])
+	assert.Equal(t, []int{1, 0, 1, 1}, c.peopleCommits)
+	// the co-occurrence of files does not depend on the authors
+	assert.Len(t, c.files["LICENSE2"], 4)
+
+	// excluded bot
+	c = fixtureCouples()
+	deps[identity.DependencyAuthors] = []int{}
+	_, err = c.Consume(deps)
+	assert.Nil(t, err)
+	for _, people := range c.people {
+		assert.Len(t, people, 0)
+	}
+	assert.Equal(t, []int{0, 0, 0, 0}, c.peopleCommits)
+	assert.Len(t, c.files["LICENSE2"], 4)
+}
+
+func TestCouplesConsumeIgnored(t *testing.T) {
+	c := fixtureCouples()
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 0
+	deps[identity.DependencyAuthors] = []int{0}
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
+		"a3ee37f91f0d705ec9c41ae88426f0ae44b2fbc3"))
+	deps[core.DependencyIsMerge] = false
+	deps[plumbing.DependencyIgnoredCommit] = true
+	deps[plumbing.DependencyTreeChanges] = generateChanges("+LICENSE2", "+file2.go", ">rbtree.go>rbtree2.go")
+	_, err := c.Consume(deps)
+	assert.Nil(t, err)
+	for _, people := range c.people {
+		assert.Len(t, people, 0)
+	}
+	assert.Equal(t, []int{0, 0, 0, 0}, c.peopleCommits)
+	assert.Len(t, c.files, 0)
+	// the renames are still tracked
+	assert.Equal(t, []rename{{FromName: "rbtree.go", ToName: "rbtree2.go"}}, *c.renames)
+}
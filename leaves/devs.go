@@ -1,18 +1,17 @@
 package leaves
 
 import (
+	"encoding/gob"
+	"encoding/json"
 	"fmt"
 	"io"
 	"sort"
 	"strings"
-	"unicode/utf8"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
-	"github.com/sergi/go-diff/diffmatchpatch"
 	"gopkg.in/src-d/go-git.v4"
-	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
-	"gopkg.in/src-d/go-git.v4/utils/merkletrie"
 	"gopkg.in/src-d/hercules.v8/internal/core"
 	"gopkg.in/src-d/hercules.v8/internal/pb"
 	items "gopkg.in/src-d/hercules.v8/internal/plumbing"
@@ -34,6 +33,10 @@ type DevsAnalysis struct {
 	days map[int]map[int]*DevDay
 	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
 	reversedPeopleDict []string
+	// teams references IdentityDetector.Teams
+	teams *identity.Teams
+	// credit is the policy of crediting the co-authors of the same commit.
+	credit identity.CoAuthorsCredit
 }
 
 // DevsResult is returned by DevsAnalysis.Finalize() and carries the daily statistics
@@ -41,20 +44,18 @@ type DevsAnalysis struct {
 type DevsResult struct {
 	// Days is <day index> -> <developer index> -> daily stats
 	Days map[int]map[int]*DevDay
+	// TeamDays is <day index> -> <team index> -> daily stats. It is nil if the teams
+	// were not specified.
+	TeamDays map[int]map[int]*DevDay
 
 	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
 	reversedPeopleDict []string
+	// reversedTeamsDict references identity.Teams.Names
+	reversedTeamsDict []string
 }
 
-// LineStats holds the numbers of inserted, deleted and changed lines.
-type LineStats struct {
-	// Added is the number of added lines by a particular developer in a particular day.
-	Added int
-	// Removed is the number of removed lines by a particular developer in a particular day.
-	Removed int
-	// Changed is the number of changed lines by a particular developer in a particular day.
-	Changed int
-}
+// LineStats holds the numbers of inserted, deleted, changed and moved lines.
+type LineStats = items.LineStats
 
 // DevDay is the statistics for a development day and a particular developer.
 type DevDay struct {
@@ -87,8 +88,9 @@ func (devs *DevsAnalysis) Provides() []string {
 // entities are Provides() upstream.
 func (devs *DevsAnalysis) Requires() []string {
 	arr := [...]string{
-		identity.DependencyAuthor, items.DependencyTreeChanges, items.DependencyFileDiff,
-		items.DependencyBlobCache, items.DependencyDay, items.DependencyLanguages}
+		identity.DependencyAuthor, items.DependencyTreeChanges, items.DependencyDay,
+		items.DependencyLineStats, identity.DependencyAuthors, items.DependencyIgnoredCommit,
+		items.DependencyMergeLineStats}
 	return arr[:]
 }
 
@@ -111,6 +113,12 @@ func (devs *DevsAnalysis) Configure(facts map[string]interface{}) error {
 	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
 		devs.reversedPeopleDict = val
 	}
+	if val, exists := facts[identity.FactIdentityDetectorTeams].(*identity.Teams); exists {
+		devs.teams = val
+	}
+	if val, exists := facts[identity.FactIdentityDetectorCoAuthorsCredit].(identity.CoAuthorsCredit); exists {
+		devs.credit = val
+	}
 	return nil
 }
 
@@ -141,132 +149,101 @@ func (devs *DevsAnalysis) Consume(deps map[string]interface{}) (map[string]inter
 	if !devs.ShouldConsumeCommit(deps) {
 		return nil, nil
 	}
-	author := deps[identity.DependencyAuthor].(int)
+	authors := commitAuthors(deps)
 	treeDiff := deps[items.DependencyTreeChanges].(object.Changes)
-	if len(treeDiff) == 0 && !devs.ConsiderEmptyCommits {
+	if (len(treeDiff) == 0 && !devs.ConsiderEmptyCommits) || len(authors) == 0 {
+		return nil, nil
+	}
+	if ignored, _ := deps[items.DependencyIgnoredCommit].(bool); ignored {
 		return nil, nil
 	}
+	files := commitFileStats(deps)
 	day := deps[items.DependencyDay].(int)
 	devsDay, exists := devs.days[day]
 	if !exists {
 		devsDay = map[int]*DevDay{}
 		devs.days[day] = devsDay
 	}
-	dd, exists := devsDay[author]
-	if !exists {
-		dd = &DevDay{Languages: map[string]LineStats{}}
-		devsDay[author] = dd
-	}
-	dd.Commits++
-	if deps[core.DependencyIsMerge].(bool) {
-		// we ignore merge commit diffs
-		// TODO(vmarkovtsev): handle them
-		return nil, nil
-	}
-	cache := deps[items.DependencyBlobCache].(map[plumbing.Hash]*items.CachedBlob)
-	fileDiffs := deps[items.DependencyFileDiff].(map[string]items.FileDiffData)
-	langs := deps[items.DependencyLanguages].(map[plumbing.Hash]string)
-	for _, change := range treeDiff {
-		action, err := change.Action()
-		if err != nil {
-			return nil, err
+	for i, author := range authors {
+		dd, exists := devsDay[author]
+		if !exists {
+			dd = &DevDay{Languages: map[string]LineStats{}}
+			devsDay[author] = dd
 		}
-		switch action {
-		case merkletrie.Insert:
-			blob := cache[change.To.TreeEntry.Hash]
-			lines, err := blob.CountLines()
-			if err != nil {
-				// binary
-				continue
-			}
-			dd.Added += lines
-			lang := langs[change.To.TreeEntry.Hash]
-			langStats := dd.Languages[lang]
-			dd.Languages[lang] = LineStats{
-				Added:   langStats.Added + lines,
-				Removed: langStats.Removed,
-				Changed: langStats.Changed,
-			}
-		case merkletrie.Delete:
-			blob := cache[change.From.TreeEntry.Hash]
-			lines, err := blob.CountLines()
-			if err != nil {
-				// binary
-				continue
-			}
-			dd.Removed += lines
-			lang := langs[change.From.TreeEntry.Hash]
-			langStats := dd.Languages[lang]
-			dd.Languages[lang] = LineStats{
-				Added:   langStats.Added,
-				Removed: langStats.Removed + lines,
-				Changed: langStats.Changed,
-			}
-		case merkletrie.Modify:
-			lang := langs[change.To.TreeEntry.Hash]
-			thisDiffs := fileDiffs[change.To.Name]
-			var removedPending int
-			for _, edit := range thisDiffs.Diffs {
-				switch edit.Type {
-				case diffmatchpatch.DiffEqual:
-					if removedPending > 0 {
-						dd.Removed += removedPending
-						langStats := dd.Languages[lang]
-						dd.Languages[lang] = LineStats{
-							Added:   langStats.Added,
-							Removed: langStats.Removed + removedPending,
-							Changed: langStats.Changed,
-						}
-					}
-					removedPending = 0
-				case diffmatchpatch.DiffInsert:
-					added := utf8.RuneCountInString(edit.Text)
-					if removedPending > added {
-						removed := removedPending - added
-						dd.Changed += added
-						dd.Removed += removed
-						langStats := dd.Languages[lang]
-						dd.Languages[lang] = LineStats{
-							Added:   langStats.Added,
-							Removed: langStats.Removed + removed,
-							Changed: langStats.Changed + added,
-						}
-					} else {
-						added := added - removedPending
-						dd.Changed += removedPending
-						dd.Added += added
-						langStats := dd.Languages[lang]
-						dd.Languages[lang] = LineStats{
-							Added:   langStats.Added + added,
-							Removed: langStats.Removed,
-							Changed: langStats.Changed + removedPending,
-						}
-					}
-					removedPending = 0
-				case diffmatchpatch.DiffDelete:
-					removedPending = utf8.RuneCountInString(edit.Text)
-				}
-			}
-			if removedPending > 0 {
-				dd.Removed += removedPending
-				langStats := dd.Languages[lang]
-				dd.Languages[lang] = LineStats{
-					Added:   langStats.Added,
-					Removed: langStats.Removed + removedPending,
-					Changed: langStats.Changed,
-				}
-			}
+		share := func(value int) int {
+			return devs.credit.Share(value, i, len(authors))
+		}
+		// a commit cannot be divided, so it counts for every author
+		dd.Commits++
+		for _, file := range files {
+			dd.add(LineStats{
+				Added:   share(file.Added),
+				Removed: share(file.Removed),
+				Changed: share(file.Changed),
+				Moved:   share(file.Moved),
+			}, file.Language)
 		}
 	}
 	return nil, nil
 }
 
+// add records the line statistics of a file in the specified language.
+func (dd *DevDay) add(stats LineStats, language string) {
+	dd.Added += stats.Added
+	dd.Removed += stats.Removed
+	dd.Changed += stats.Changed
+	dd.Moved += stats.Moved
+	langStats := dd.Languages[language]
+	dd.Languages[language] = LineStats{
+		Added:   langStats.Added + stats.Added,
+		Removed: langStats.Removed + stats.Removed,
+		Changed: langStats.Changed + stats.Changed,
+		Moved:   langStats.Moved + stats.Moved,
+	}
+}
+
+// merge sums the commits and the line statistics of both days.
+func (dd *DevDay) merge(other *DevDay) {
+	dd.Commits += other.Commits
+	dd.Added += other.Added
+	dd.Removed += other.Removed
+	dd.Changed += other.Changed
+	dd.Moved += other.Moved
+	for lang, ls := range other.Languages {
+		prev := dd.Languages[lang]
+		dd.Languages[lang] = LineStats{
+			Added:   prev.Added + ls.Added,
+			Removed: prev.Removed + ls.Removed,
+			Changed: prev.Changed + ls.Changed,
+			Moved:   prev.Moved + ls.Moved,
+		}
+	}
+}
+
 // Finalize returns the result of the analysis. Further Consume() calls are not expected.
 func (devs *DevsAnalysis) Finalize() interface{} {
-	return DevsResult{
+	result := DevsResult{
 		Days:               devs.days,
 		reversedPeopleDict: devs.reversedPeopleDict,
 	}
+	if devs.teams != nil {
+		result.TeamDays = map[int]map[int]*DevDay{}
+		for day, dd := range devs.days {
+			teamsDay := map[int]*DevDay{}
+			result.TeamDays[day] = teamsDay
+			for dev, stats := range dd {
+				team := devs.teams.Team(dev)
+				td, exists := teamsDay[team]
+				if !exists {
+					td = &DevDay{Languages: map[string]LineStats{}}
+					teamsDay[team] = td
+				}
+				td.merge(stats)
+			}
+		}
+		result.reversedTeamsDict = devs.teams.Names
+	}
+	return result
 }
 
 // Fork clones this pipeline item.
@@ -274,6 +251,24 @@ func (devs *DevsAnalysis) Fork(n int) []core.PipelineItem {
 	return core.ForkSamePipelineItem(devs, n)
 }
 
+// Checkpoint writes the accumulated daily statistics.
+func (devs *DevsAnalysis) Checkpoint(writer io.Writer) error {
+	return gob.NewEncoder(writer).Encode(devs.days)
+}
+
+// Restore reads the state previously written by Checkpoint().
+func (devs *DevsAnalysis) Restore(reader io.Reader, facts map[string]interface{}) error {
+	days := map[int]map[int]*DevDay{}
+	err := gob.NewDecoder(reader).Decode(&days)
+	if err != nil {
+		return err
+	}
+	for day, dayDevs := range days {
+		devs.days[day] = dayDevs
+	}
+	return nil
+}
+
 // Serialize converts the analysis result as returned by Finalize() to text or bytes.
 // The text format is YAML and the bytes format is Protocol Buffers.
 func (devs *DevsAnalysis) Serialize(result interface{}, binary bool, writer io.Writer) error {
@@ -285,6 +280,12 @@ func (devs *DevsAnalysis) Serialize(result interface{}, binary bool, writer io.W
 	return nil
 }
 
+// SerializeJSON converts the analysis result as returned by Finalize() to JSON.
+func (devs *DevsAnalysis) SerializeJSON(result interface{}, writer io.Writer) error {
+	devsResult := result.(DevsResult)
+	return devs.serializeJSON(&devsResult, writer)
+}
+
 // Deserialize converts the specified protobuf bytes to DevsResult.
 func (devs *DevsAnalysis) Deserialize(pbmessage []byte) (interface{}, error) {
 	message := pb.DevsAnalysisResults{}
@@ -292,8 +293,21 @@ func (devs *DevsAnalysis) Deserialize(pbmessage []byte) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	result := DevsResult{
+		Days:               devDaysFromProto(message.Days),
+		reversedPeopleDict: message.DevIndex,
+	}
+	if len(message.TeamIndex) > 0 {
+		result.TeamDays = devDaysFromProto(message.TeamDays)
+		result.reversedTeamsDict = message.TeamIndex
+	}
+	return result, nil
+}
+
+// devDaysFromProto converts the daily statistics from Protocol Buffers.
+func devDaysFromProto(message map[int32]*pb.DayDevs) map[int]map[int]*DevDay {
 	days := map[int]map[int]*DevDay{}
-	for day, dd := range message.Days {
+	for day, dd := range message {
 		rdd := map[int]*DevDay{}
 		days[int(day)] = rdd
 		for dev, stats := range dd.Devs {
@@ -307,6 +321,7 @@ func (devs *DevsAnalysis) Deserialize(pbmessage []byte) (interface{}, error) {
 					Added:   int(stats.Stats.Added),
 					Removed: int(stats.Stats.Removed),
 					Changed: int(stats.Stats.Changed),
+					Moved:   int(stats.Stats.Moved),
 				},
 				Languages: languages,
 			}
@@ -315,31 +330,46 @@ func (devs *DevsAnalysis) Deserialize(pbmessage []byte) (interface{}, error) {
 					Added:   int(ls.Added),
 					Removed: int(ls.Removed),
 					Changed: int(ls.Changed),
+					Moved:   int(ls.Moved),
 				}
 			}
 		}
 	}
-	result := DevsResult{
-		Days:               days,
-		reversedPeopleDict: message.DevIndex,
-	}
-	return result, nil
+	return days
 }
 
-// MergeResults combines two DevsAnalysis-es together.
+// MergeResults combines two DevsAnalysis-es together. The results with different tick sizes
+// are converted to the coarser one.
 func (devs *DevsAnalysis) MergeResults(r1, r2 interface{}, c1, c2 *core.CommonAnalysisResult) interface{} {
 	cr1 := r1.(DevsResult)
 	cr2 := r2.(DevsResult)
+	tickSize1, tickSize2 := resultTickSize(c1), resultTickSize(c2)
+	tickSize := core.MergeTickSizes(tickSize1, tickSize2)
 	merged := DevsResult{}
+	merged.Days, merged.reversedPeopleDict = mergeDevDays(
+		cr1.Days, cr2.Days, cr1.reversedPeopleDict, cr2.reversedPeopleDict,
+		tickSize1, tickSize2, tickSize)
+	if cr1.TeamDays != nil && cr2.TeamDays != nil {
+		merged.TeamDays, merged.reversedTeamsDict = mergeDevDays(
+			cr1.TeamDays, cr2.TeamDays, cr1.reversedTeamsDict, cr2.reversedTeamsDict,
+			tickSize1, tickSize2, tickSize)
+	}
+	return merged
+}
+
+// mergeDevDays combines the daily statistics of two results. The developers (or teams)
+// are matched by name and the ticks are converted to `tickSize`.
+func mergeDevDays(days1, days2 map[int]map[int]*DevDay, names1, names2 []string,
+	tickSize1, tickSize2, tickSize time.Duration) (map[int]map[int]*DevDay, []string) {
 	type devIndexPair struct {
 		Index1 int
 		Index2 int
 	}
 	devIndex := map[string]devIndexPair{}
-	for dev, devName := range cr1.reversedPeopleDict {
+	for dev, devName := range names1 {
 		devIndex[devName] = devIndexPair{Index1: dev + 1, Index2: devIndex[devName].Index2}
 	}
-	for dev, devName := range cr2.reversedPeopleDict {
+	for dev, devName := range names2 {
 		devIndex[devName] = devIndexPair{Index1: devIndex[devName].Index1, Index2: dev + 1}
 	}
 	jointDevSeq := make([]string, len(devIndex))
@@ -351,7 +381,6 @@ func (devs *DevsAnalysis) MergeResults(r1, r2 interface{}, c1, c2 *core.CommonAn
 		}
 	}
 	sort.Strings(jointDevSeq)
-	merged.reversedPeopleDict = jointDevSeq
 	invDevIndex1 := map[int]int{}
 	invDevIndex2 := map[int]int{}
 	for i, dev := range jointDevSeq {
@@ -364,8 +393,8 @@ func (devs *DevsAnalysis) MergeResults(r1, r2 interface{}, c1, c2 *core.CommonAn
 		}
 	}
 	newDays := map[int]map[int]*DevDay{}
-	merged.Days = newDays
-	for day, dd := range cr1.Days {
+	for day, dd := range days1 {
+		day = convertTickIndex(day, tickSize1, tickSize)
 		newdd, exists := newDays[day]
 		if !exists {
 			newdd = map[int]*DevDay{}
@@ -381,21 +410,11 @@ func (devs *DevsAnalysis) MergeResults(r1, r2 interface{}, c1, c2 *core.CommonAn
 				newstats = &DevDay{Languages: map[string]LineStats{}}
 				newdd[newdev] = newstats
 			}
-			newstats.Commits += stats.Commits
-			newstats.Added += stats.Added
-			newstats.Removed += stats.Removed
-			newstats.Changed += stats.Changed
-			for lang, ls := range stats.Languages {
-				prev := newstats.Languages[lang]
-				newstats.Languages[lang] = LineStats{
-					Added:   prev.Added + ls.Added,
-					Removed: prev.Removed + ls.Removed,
-					Changed: prev.Changed + ls.Changed,
-				}
-			}
+			newstats.merge(stats)
 		}
 	}
-	for day, dd := range cr2.Days {
+	for day, dd := range days2 {
+		day = convertTickIndex(day, tickSize2, tickSize)
 		newdd, exists := newDays[day]
 		if !exists {
 			newdd = map[int]*DevDay{}
@@ -411,37 +430,53 @@ func (devs *DevsAnalysis) MergeResults(r1, r2 interface{}, c1, c2 *core.CommonAn
 				newstats = &DevDay{Languages: map[string]LineStats{}}
 				newdd[newdev] = newstats
 			}
-			newstats.Commits += stats.Commits
-			newstats.Added += stats.Added
-			newstats.Removed += stats.Removed
-			newstats.Changed += stats.Changed
-			for lang, ls := range stats.Languages {
-				prev := newstats.Languages[lang]
-				newstats.Languages[lang] = LineStats{
-					Added:   prev.Added + ls.Added,
-					Removed: prev.Removed + ls.Removed,
-					Changed: prev.Changed + ls.Changed,
-				}
-			}
+			newstats.merge(stats)
 		}
 	}
-	return merged
+	return newDays, jointDevSeq
+}
+
+// convertTickIndex returns the index of the tick of size `to` which contains the tick `index`
+// of size `from`.
+func convertTickIndex(index int, from, to time.Duration) int {
+	if from == to {
+		return index
+	}
+	return int(time.Duration(index) * from / to)
 }
 
 func (devs *DevsAnalysis) serializeText(result *DevsResult, writer io.Writer) {
 	fmt.Fprintln(writer, "  days:")
-	days := make([]int, len(result.Days))
+	serializeDevDaysText(result.Days, "    ", writer)
+	fmt.Fprintln(writer, "  people:")
+	for _, person := range result.reversedPeopleDict {
+		fmt.Fprintf(writer, "  - %s\n", yaml.SafeString(person))
+	}
+	if result.TeamDays == nil {
+		return
+	}
+	fmt.Fprintln(writer, "  team_days:")
+	serializeDevDaysText(result.TeamDays, "    ", writer)
+	fmt.Fprintln(writer, "  teams:")
+	for _, team := range result.reversedTeamsDict {
+		fmt.Fprintf(writer, "  - %s\n", yaml.SafeString(team))
+	}
+}
+
+// serializeDevDaysText writes the daily statistics as YAML. The days are indented with `indent`.
+func serializeDevDaysText(resultDays map[int]map[int]*DevDay, indent string, writer io.Writer) {
+	days := make([]int, len(resultDays))
 	{
 		i := 0
-		for day := range result.Days {
+		for day := range resultDays {
 			days[i] = day
 			i++
 		}
 	}
 	sort.Ints(days)
 	for _, day := range days {
-		fmt.Fprintf(writer, "    %d:\n", day)
-		rday := result.Days[day]
+		fmt.Fprintf(writer, "%s%d:\n", indent, day)
+		rday := resultDays[day]
 		devseq := make([]int, len(rday))
 		{
 			i := 0
@@ -462,27 +497,108 @@ func (devs *DevsAnalysis) serializeText(result *DevsResult, writer io.Writer) {
 					lang = "none"
 				}
 				langs = append(langs,
-					fmt.Sprintf("%s: [%d, %d, %d]", lang, ls.Added, ls.Removed, ls.Changed))
+					fmt.Sprintf("%s: [%d, %d, %d, %d]", lang, ls.Added, ls.Removed, ls.Changed,
+						ls.Moved))
 			}
 			sort.Strings(langs)
-			fmt.Fprintf(writer, "      %d: [%d, %d, %d, %d, {%s}]\n",
-				dev, stats.Commits, stats.Added, stats.Removed, stats.Changed,
+			fmt.Fprintf(writer, "%s  %d: [%d, %d, %d, %d, %d, {%s}]\n",
+				indent, dev, stats.Commits, stats.Added, stats.Removed, stats.Changed, stats.Moved,
 				strings.Join(langs, ", "))
 		}
 	}
-	fmt.Fprintln(writer, "  people:")
-	for _, person := range result.reversedPeopleDict {
-		fmt.Fprintf(writer, "  - %s\n", yaml.SafeString(person))
+}
+
+// lineStatsJSON is the JSON schema of LineStats, see doc/JSON.md.
+type lineStatsJSON struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+	Moved   int `json:"moved"`
+}
+
+func newLineStatsJSON(stats LineStats) lineStatsJSON {
+	return lineStatsJSON{
+		Added: stats.Added, Removed: stats.Removed, Changed: stats.Changed, Moved: stats.Moved}
+}
+
+// devDayJSON is the JSON schema of DevDay, see doc/JSON.md.
+type devDayJSON struct {
+	Commits int `json:"commits"`
+	lineStatsJSON
+	Languages map[string]lineStatsJSON `json:"languages"`
+}
+
+// devsJSON is the JSON schema of DevsResult, see doc/JSON.md.
+type devsJSON struct {
+	Days     map[int]map[int]devDayJSON `json:"days"`
+	People   []string                   `json:"people"`
+	TeamDays map[int]map[int]devDayJSON `json:"team_days,omitempty"`
+	Teams    []string                   `json:"teams,omitempty"`
+}
+
+func (devs *DevsAnalysis) serializeJSON(result *DevsResult, writer io.Writer) error {
+	message := devsJSON{
+		Days:   newDevDaysJSON(result.Days),
+		People: result.reversedPeopleDict,
 	}
+	if message.People == nil {
+		message.People = []string{}
+	}
+	if result.TeamDays != nil {
+		message.TeamDays = newDevDaysJSON(result.TeamDays)
+		message.Teams = result.reversedTeamsDict
+	}
+	return json.NewEncoder(writer).Encode(&message)
+}
+
+func newDevDaysJSON(days map[int]map[int]*DevDay) map[int]map[int]devDayJSON {
+	jdays := map[int]map[int]devDayJSON{}
+	for day, rday := range days {
+		jday := map[int]devDayJSON{}
+		for dev, stats := range rday {
+			if dev == identity.AuthorMissing {
+				dev = -1
+			}
+			langs := map[string]lineStatsJSON{}
+			for lang, ls := range stats.Languages {
+				if lang == "" {
+					lang = "none"
+				}
+				langs[lang] = newLineStatsJSON(ls)
+			}
+			jday[dev] = devDayJSON{
+				Commits:       stats.Commits,
+				lineStatsJSON: newLineStatsJSON(stats.LineStats),
+				Languages:     langs,
+			}
+		}
+		jdays[day] = jday
+	}
+	return jdays
 }
 
 func (devs *DevsAnalysis) serializeBinary(result *DevsResult, writer io.Writer) error {
 	message := pb.DevsAnalysisResults{}
 	message.DevIndex = result.reversedPeopleDict
-	message.Days = map[int32]*pb.DayDevs{}
-	for day, devs := range result.Days {
+	message.Days = devDaysToProto(result.Days)
+	if result.TeamDays != nil {
+		message.TeamIndex = result.reversedTeamsDict
+		message.TeamDays = devDaysToProto(result.TeamDays)
+	}
+	serialized, err := proto.Marshal(&message)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(serialized)
+	return err
+}
+
+// devDaysToProto converts the daily statistics to Protocol Buffers.
+func devDaysToProto(days map[int]map[int]*DevDay) map[int32]*pb.DayDevs {
+	message := map[int32]*pb.DayDevs{}
+	for day, devs := range days {
 		dd := &pb.DayDevs{}
-		message.Days[int32(day)] = dd
+		message[int32(day)] = dd
 		dd.Devs = map[int32]*pb.DevDay{}
 		for dev, stats := range devs {
 			if dev == identity.AuthorMissing {
@@ -495,6 +611,7 @@ func (devs *DevsAnalysis) serializeBinary(result *DevsResult, writer io.Writer)
 					Added:   int32(stats.Added),
 					Changed: int32(stats.Changed),
 					Removed: int32(stats.Removed),
+					Moved:   int32(stats.Moved),
 				},
 				Languages: languages,
 			}
@@ -503,16 +620,12 @@ func (devs *DevsAnalysis) serializeBinary(result *DevsResult, writer io.Writer)
 					Added:   int32(ls.Added),
 					Changed: int32(ls.Changed),
 					Removed: int32(ls.Removed),
+					Moved:   int32(ls.Moved),
 				}
 			}
 		}
 	}
-	serialized, err := proto.Marshal(&message)
-	if err != nil {
-		return err
-	}
-	_, err = writer.Write(serialized)
-	return err
+	return message
 }
 
 func init() {
@@ -2,7 +2,10 @@ package leaves
 
 import (
 	"bytes"
+	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/stretchr/testify/assert"
@@ -24,17 +27,31 @@ func fixtureDevs() *DevsAnalysis {
 	return &d
 }
 
+// fixtureTeams loads the teams mapping from the YAML text.
+func fixtureTeams(t *testing.T, text string, peopleDict map[string]int) *identity.Teams {
+	tmpf, err := ioutil.TempFile("", "hercules-test-")
+	assert.Nil(t, err)
+	defer os.Remove(tmpf.Name())
+	_, err = tmpf.WriteString(text)
+	assert.Nil(t, err)
+	assert.Nil(t, tmpf.Close())
+	teams, err := identity.LoadTeams(tmpf.Name(), peopleDict)
+	assert.Nil(t, err)
+	return teams
+}
+
 func TestDevsMeta(t *testing.T) {
 	d := fixtureDevs()
 	assert.Equal(t, d.Name(), "Devs")
 	assert.Equal(t, len(d.Provides()), 0)
-	assert.Equal(t, len(d.Requires()), 6)
+	assert.Equal(t, len(d.Requires()), 7)
 	assert.Equal(t, d.Requires()[0], identity.DependencyAuthor)
 	assert.Equal(t, d.Requires()[1], plumbing.DependencyTreeChanges)
-	assert.Equal(t, d.Requires()[2], plumbing.DependencyFileDiff)
-	assert.Equal(t, d.Requires()[3], plumbing.DependencyBlobCache)
-	assert.Equal(t, d.Requires()[4], plumbing.DependencyDay)
-	assert.Equal(t, d.Requires()[5], plumbing.DependencyLanguages)
+	assert.Equal(t, d.Requires()[2], plumbing.DependencyDay)
+	assert.Equal(t, d.Requires()[3], plumbing.DependencyLineStats)
+	assert.Equal(t, d.Requires()[4], identity.DependencyAuthors)
+	assert.Equal(t, d.Requires()[5], plumbing.DependencyIgnoredCommit)
+	assert.Equal(t, d.Requires()[6], plumbing.DependencyMergeLineStats)
 	assert.Equal(t, d.Flag(), "devs")
 	assert.Len(t, d.ListConfigurationOptions(), 1)
 	assert.Equal(t, d.ListConfigurationOptions()[0].Name, ConfigDevsConsiderEmptyCommits)
@@ -63,7 +80,7 @@ func TestDevsConfigure(t *testing.T) {
 	devs := DevsAnalysis{}
 	facts := map[string]interface{}{}
 	facts[ConfigDevsConsiderEmptyCommits] = true
-	devs.Configure(facts)
+	assert.Nil(t, devs.Configure(facts))
 	assert.Equal(t, devs.ConsiderEmptyCommits, true)
 }
 
@@ -138,6 +155,9 @@ func TestDevsConsumeFinalize(t *testing.T) {
 	result, err := fd.Consume(deps)
 	assert.Nil(t, err)
 	deps[plumbing.DependencyFileDiff] = result[plumbing.DependencyFileDiff]
+	result, err = fixtures.LineStats().Consume(deps)
+	assert.Nil(t, err)
+	deps[plumbing.DependencyLineStats] = result[plumbing.DependencyLineStats]
 	deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
 		"cce947b98a050c6d356bc6ba95030254914027b1"))
 	deps[core.DependencyIsMerge] = false
@@ -253,10 +273,46 @@ func TestDevsConsumeFinalize(t *testing.T) {
 	assert.Equal(t, dev.Languages["Go"].Changed, 67)
 }
 
+func TestDevsConsumeMerge(t *testing.T) {
+	consume := func(mode string) *DevDay {
+		devs := fixtureDevs()
+		deps := map[string]interface{}{}
+		deps[identity.DependencyAuthor] = 1
+		deps[plumbing.DependencyDay] = 5
+		// the tree changes of the merge are ignored
+		deps[plumbing.DependencyTreeChanges] = make(object.Changes, 1)
+		deps[core.DependencyIsMerge] = true
+		deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
+			"fe00c0d50719a23ee77370dc29984fe4e16b2090"))
+		deps[plumbing.DependencyMergeLineStats] = mergeLineStats(t, mode, deps)
+		result, err := devs.Consume(deps)
+		assert.Nil(t, result)
+		assert.Nil(t, err)
+		dev := devs.days[5][1]
+		assert.Equal(t, 1, dev.Commits)
+		var langStats LineStats
+		for _, stats := range dev.Languages {
+			langStats.Added += stats.Added
+			langStats.Removed += stats.Removed
+			langStats.Changed += stats.Changed
+		}
+		assert.Equal(t, dev.LineStats, langStats)
+		return dev
+	}
+	dev := consume(plumbing.MergeDiffFirstParent)
+	assert.Equal(t, LineStats{Added: 28, Removed: 22, Changed: 30}, dev.LineStats)
+	dev = consume(plumbing.MergeDiffCombined)
+	assert.Equal(t, LineStats{Added: 8, Removed: 0, Changed: 2}, dev.LineStats)
+	dev = consume(plumbing.MergeDiffNewLines)
+	assert.Equal(t, LineStats{Added: 1, Removed: 0, Changed: 0}, dev.LineStats)
+	dev = consume(plumbing.MergeDiffNone)
+	assert.Equal(t, LineStats{}, dev.LineStats)
+}
+
 func TestDevsFinalize(t *testing.T) {
 	devs := fixtureDevs()
 	devs.days[1] = map[int]*DevDay{}
-	devs.days[1][1] = &DevDay{10, LineStats{20, 30, 40}, nil}
+	devs.days[1][1] = &DevDay{10, LineStats{Added: 20, Removed: 30, Changed: 40}, nil}
 	x := devs.Finalize().(DevsResult)
 	assert.Equal(t, x.Days, devs.days)
 	assert.Equal(t, x.reversedPeopleDict, devs.reversedPeopleDict)
@@ -268,26 +324,43 @@ func TestDevsFork(t *testing.T) {
 	assert.True(t, devs == clone)
 }
 
+func TestDevsCheckpointRestore(t *testing.T) {
+	devs := fixtureDevs()
+	devs.days[1] = map[int]*DevDay{}
+	devs.days[1][0] = &DevDay{10, LineStats{Added: 20, Removed: 30, Changed: 40}, map[string]LineStats{"Go": {Added: 2, Removed: 3, Changed: 4}}}
+	devs.days[2] = map[int]*DevDay{}
+	devs.days[2][identity.AuthorMissing] = &DevDay{1, LineStats{Added: 2, Removed: 3, Changed: 4}, nil}
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, devs.Checkpoint(buffer))
+	restored := fixtureDevs()
+	assert.Nil(t, restored.Restore(buffer, map[string]interface{}{}))
+	assert.Len(t, restored.days, 2)
+	assert.Equal(t, *devs.days[1][0], *restored.days[1][0])
+	assert.Equal(t, devs.days[2][identity.AuthorMissing].LineStats,
+		restored.days[2][identity.AuthorMissing].LineStats)
+	assert.Equal(t, 1, restored.days[2][identity.AuthorMissing].Commits)
+}
+
 func TestDevsSerialize(t *testing.T) {
 	devs := fixtureDevs()
 	devs.days[1] = map[int]*DevDay{}
-	devs.days[1][0] = &DevDay{10, LineStats{20, 30, 40}, map[string]LineStats{"Go": {2, 3, 4}}}
-	devs.days[1][1] = &DevDay{1, LineStats{2, 3, 4}, map[string]LineStats{"Go": {25, 35, 45}}}
+	devs.days[1][0] = &DevDay{10, LineStats{Added: 20, Removed: 30, Changed: 40}, map[string]LineStats{"Go": {Added: 2, Removed: 3, Changed: 4}}}
+	devs.days[1][1] = &DevDay{1, LineStats{Added: 2, Removed: 3, Changed: 4, Moved: 5}, map[string]LineStats{"Go": {Added: 25, Removed: 35, Changed: 45, Moved: 5}}}
 	devs.days[10] = map[int]*DevDay{}
-	devs.days[10][0] = &DevDay{11, LineStats{21, 31, 41}, map[string]LineStats{"": {12, 13, 14}}}
+	devs.days[10][0] = &DevDay{11, LineStats{Added: 21, Removed: 31, Changed: 41}, map[string]LineStats{"": {Added: 12, Removed: 13, Changed: 14}}}
 	devs.days[10][identity.AuthorMissing] = &DevDay{
-		100, LineStats{200, 300, 400}, map[string]LineStats{"Go": {32, 33, 34}}}
+		100, LineStats{Added: 200, Removed: 300, Changed: 400}, map[string]LineStats{"Go": {Added: 32, Removed: 33, Changed: 34}}}
 	res := devs.Finalize().(DevsResult)
 	buffer := &bytes.Buffer{}
 	err := devs.Serialize(res, false, buffer)
 	assert.Nil(t, err)
 	assert.Equal(t, `  days:
     1:
-      0: [10, 20, 30, 40, {Go: [2, 3, 4]}]
-      1: [1, 2, 3, 4, {Go: [25, 35, 45]}]
+      0: [10, 20, 30, 40, 0, {Go: [2, 3, 4, 0]}]
+      1: [1, 2, 3, 4, 5, {Go: [25, 35, 45, 5]}]
     10:
-      0: [11, 21, 31, 41, {none: [12, 13, 14]}]
-      -1: [100, 200, 300, 400, {Go: [32, 33, 34]}]
+      0: [11, 21, 31, 41, 0, {none: [12, 13, 14, 0]}]
+      -1: [100, 200, 300, 400, 0, {Go: [32, 33, 34, 0]}]
   people:
   - "one@srcd"
   - "two@srcd"
@@ -305,8 +378,8 @@ func TestDevsSerialize(t *testing.T) {
 		Commits: 10, Stats: &pb.LineStats{Added: 20, Removed: 30, Changed: 40},
 		Languages: map[string]*pb.LineStats{"Go": {Added: 2, Removed: 3, Changed: 4}}})
 	assert.Equal(t, msg.Days[1].Devs[1], &pb.DevDay{
-		Commits: 1, Stats: &pb.LineStats{Added: 2, Removed: 3, Changed: 4},
-		Languages: map[string]*pb.LineStats{"Go": {Added: 25, Removed: 35, Changed: 45}}})
+		Commits: 1, Stats: &pb.LineStats{Added: 2, Removed: 3, Changed: 4, Moved: 5},
+		Languages: map[string]*pb.LineStats{"Go": {Added: 25, Removed: 35, Changed: 45, Moved: 5}}})
 	assert.Len(t, msg.Days[10].Devs, 2)
 	assert.Equal(t, msg.Days[10].Devs[0], &pb.DevDay{
 		Commits: 11, Stats: &pb.LineStats{Added: 21, Removed: 31, Changed: 41},
@@ -316,15 +389,36 @@ func TestDevsSerialize(t *testing.T) {
 		Languages: map[string]*pb.LineStats{"Go": {Added: 32, Removed: 33, Changed: 34}}})
 }
 
+func TestDevsSerializeJSON(t *testing.T) {
+	devs := fixtureDevs()
+	devs.days[1] = map[int]*DevDay{}
+	devs.days[1][0] = &DevDay{10, LineStats{Added: 20, Removed: 30, Changed: 40}, map[string]LineStats{"Go": {Added: 2, Removed: 3, Changed: 4}}}
+	devs.days[10] = map[int]*DevDay{}
+	devs.days[10][0] = &DevDay{11, LineStats{Added: 21, Removed: 31, Changed: 41}, map[string]LineStats{"": {Added: 12, Removed: 13, Changed: 14}}}
+	devs.days[10][identity.AuthorMissing] = &DevDay{
+		100, LineStats{Added: 200, Removed: 300, Changed: 400}, map[string]LineStats{"Go": {Added: 32, Removed: 33, Changed: 34}}}
+	res := devs.Finalize().(DevsResult)
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, devs.SerializeJSON(res, buffer))
+	assert.Equal(t, `{"days":{"1":{"0":{"commits":10,"added":20,"removed":30,"changed":40,"moved":0,`+
+		`"languages":{"Go":{"added":2,"removed":3,"changed":4,"moved":0}}}},`+
+		`"10":{"-1":{"commits":100,"added":200,"removed":300,"changed":400,"moved":0,`+
+		`"languages":{"Go":{"added":32,"removed":33,"changed":34,"moved":0}}},`+
+		`"0":{"commits":11,"added":21,"removed":31,"changed":41,"moved":0,`+
+		`"languages":{"none":{"added":12,"removed":13,"changed":14,"moved":0}}}}},`+
+		`"people":["one@srcd","two@srcd"]}
+`, buffer.String())
+}
+
 func TestDevsDeserialize(t *testing.T) {
 	devs := fixtureDevs()
 	devs.days[1] = map[int]*DevDay{}
-	devs.days[1][0] = &DevDay{10, LineStats{20, 30, 40}, map[string]LineStats{"Go": {12, 13, 14}}}
-	devs.days[1][1] = &DevDay{1, LineStats{2, 3, 4}, map[string]LineStats{"Go": {22, 23, 24}}}
+	devs.days[1][0] = &DevDay{10, LineStats{Added: 20, Removed: 30, Changed: 40}, map[string]LineStats{"Go": {Added: 12, Removed: 13, Changed: 14}}}
+	devs.days[1][1] = &DevDay{1, LineStats{Added: 2, Removed: 3, Changed: 4}, map[string]LineStats{"Go": {Added: 22, Removed: 23, Changed: 24}}}
 	devs.days[10] = map[int]*DevDay{}
-	devs.days[10][0] = &DevDay{11, LineStats{21, 31, 41}, map[string]LineStats{"Go": {32, 33, 34}}}
+	devs.days[10][0] = &DevDay{11, LineStats{Added: 21, Removed: 31, Changed: 41}, map[string]LineStats{"Go": {Added: 32, Removed: 33, Changed: 34}}}
 	devs.days[10][identity.AuthorMissing] = &DevDay{
-		100, LineStats{200, 300, 400}, map[string]LineStats{"Go": {42, 43, 44}}}
+		100, LineStats{Added: 200, Removed: 300, Changed: 400}, map[string]LineStats{"Go": {Added: 42, Removed: 43, Changed: 44}}}
 	res := devs.Finalize().(DevsResult)
 	buffer := &bytes.Buffer{}
 	err := devs.Serialize(res, true, buffer)
@@ -343,29 +437,29 @@ func TestDevsMergeResults(t *testing.T) {
 		reversedPeopleDict: people1[:],
 	}
 	r1.Days[1] = map[int]*DevDay{}
-	r1.Days[1][0] = &DevDay{10, LineStats{20, 30, 40}, map[string]LineStats{"Go": {12, 13, 14}}}
-	r1.Days[1][1] = &DevDay{1, LineStats{2, 3, 4}, map[string]LineStats{"Go": {22, 23, 24}}}
+	r1.Days[1][0] = &DevDay{10, LineStats{Added: 20, Removed: 30, Changed: 40}, map[string]LineStats{"Go": {Added: 12, Removed: 13, Changed: 14}}}
+	r1.Days[1][1] = &DevDay{1, LineStats{Added: 2, Removed: 3, Changed: 4}, map[string]LineStats{"Go": {Added: 22, Removed: 23, Changed: 24}}}
 	r1.Days[10] = map[int]*DevDay{}
-	r1.Days[10][0] = &DevDay{11, LineStats{21, 31, 41}, nil}
+	r1.Days[10][0] = &DevDay{11, LineStats{Added: 21, Removed: 31, Changed: 41}, nil}
 	r1.Days[10][identity.AuthorMissing] = &DevDay{
-		100, LineStats{200, 300, 400}, map[string]LineStats{"Go": {32, 33, 34}}}
+		100, LineStats{Added: 200, Removed: 300, Changed: 400}, map[string]LineStats{"Go": {Added: 32, Removed: 33, Changed: 34}}}
 	r1.Days[11] = map[int]*DevDay{}
-	r1.Days[11][1] = &DevDay{10, LineStats{20, 30, 40}, map[string]LineStats{"Go": {42, 43, 44}}}
+	r1.Days[11][1] = &DevDay{10, LineStats{Added: 20, Removed: 30, Changed: 40}, map[string]LineStats{"Go": {Added: 42, Removed: 43, Changed: 44}}}
 	r2 := DevsResult{
 		Days:               map[int]map[int]*DevDay{},
 		reversedPeopleDict: people2[:],
 	}
 	r2.Days[1] = map[int]*DevDay{}
-	r2.Days[1][0] = &DevDay{10, LineStats{20, 30, 40}, map[string]LineStats{"Go": {12, 13, 14}}}
-	r2.Days[1][1] = &DevDay{1, LineStats{2, 3, 4}, map[string]LineStats{"Go": {22, 23, 24}}}
+	r2.Days[1][0] = &DevDay{10, LineStats{Added: 20, Removed: 30, Changed: 40}, map[string]LineStats{"Go": {Added: 12, Removed: 13, Changed: 14}}}
+	r2.Days[1][1] = &DevDay{1, LineStats{Added: 2, Removed: 3, Changed: 4}, map[string]LineStats{"Go": {Added: 22, Removed: 23, Changed: 24}}}
 	r2.Days[2] = map[int]*DevDay{}
-	r2.Days[2][0] = &DevDay{11, LineStats{21, 31, 41}, map[string]LineStats{"Go": {32, 33, 34}}}
+	r2.Days[2][0] = &DevDay{11, LineStats{Added: 21, Removed: 31, Changed: 41}, map[string]LineStats{"Go": {Added: 32, Removed: 33, Changed: 34}}}
 	r2.Days[2][identity.AuthorMissing] = &DevDay{
-		100, LineStats{200, 300, 400}, map[string]LineStats{"Go": {42, 43, 44}}}
+		100, LineStats{Added: 200, Removed: 300, Changed: 400}, map[string]LineStats{"Go": {Added: 42, Removed: 43, Changed: 44}}}
 	r2.Days[10] = map[int]*DevDay{}
-	r2.Days[10][0] = &DevDay{11, LineStats{21, 31, 41}, map[string]LineStats{"Go": {52, 53, 54}}}
+	r2.Days[10][0] = &DevDay{11, LineStats{Added: 21, Removed: 31, Changed: 41}, map[string]LineStats{"Go": {Added: 52, Removed: 53, Changed: 54}}}
 	r2.Days[10][identity.AuthorMissing] = &DevDay{
-		100, LineStats{200, 300, 400}, map[string]LineStats{"Go": {62, 63, 64}}}
+		100, LineStats{Added: 200, Removed: 300, Changed: 400}, map[string]LineStats{"Go": {Added: 62, Removed: 63, Changed: 64}}}
 
 	devs := fixtureDevs()
 	rm := devs.MergeResults(r1, r2, nil, nil).(DevsResult)
@@ -373,20 +467,147 @@ func TestDevsMergeResults(t *testing.T) {
 	assert.Equal(t, rm.reversedPeopleDict, peoplerm[:])
 	assert.Len(t, rm.Days, 4)
 	assert.Equal(t, rm.Days[11], map[int]*DevDay{
-		1: {10, LineStats{20, 30, 40}, map[string]LineStats{"Go": {42, 43, 44}}}})
+		1: {10, LineStats{Added: 20, Removed: 30, Changed: 40}, map[string]LineStats{"Go": {Added: 42, Removed: 43, Changed: 44}}}})
 	assert.Equal(t, rm.Days[2], map[int]*DevDay{
-		identity.AuthorMissing: {100, LineStats{200, 300, 400}, map[string]LineStats{"Go": {42, 43, 44}}},
-		2:                      {11, LineStats{21, 31, 41}, map[string]LineStats{"Go": {32, 33, 34}}},
+		identity.AuthorMissing: {100, LineStats{Added: 200, Removed: 300, Changed: 400}, map[string]LineStats{"Go": {Added: 42, Removed: 43, Changed: 44}}},
+		2:                      {11, LineStats{Added: 21, Removed: 31, Changed: 41}, map[string]LineStats{"Go": {Added: 32, Removed: 33, Changed: 34}}},
 	})
 	assert.Equal(t, rm.Days[1], map[int]*DevDay{
-		0: {11, LineStats{22, 33, 44}, map[string]LineStats{"Go": {34, 36, 38}}},
-		1: {1, LineStats{2, 3, 4}, map[string]LineStats{"Go": {22, 23, 24}}},
-		2: {10, LineStats{20, 30, 40}, map[string]LineStats{"Go": {12, 13, 14}}},
+		0: {11, LineStats{Added: 22, Removed: 33, Changed: 44}, map[string]LineStats{"Go": {Added: 34, Removed: 36, Changed: 38}}},
+		1: {1, LineStats{Added: 2, Removed: 3, Changed: 4}, map[string]LineStats{"Go": {Added: 22, Removed: 23, Changed: 24}}},
+		2: {10, LineStats{Added: 20, Removed: 30, Changed: 40}, map[string]LineStats{"Go": {Added: 12, Removed: 13, Changed: 14}}},
 	})
 	assert.Equal(t, rm.Days[10], map[int]*DevDay{
-		0: {11, LineStats{21, 31, 41}, map[string]LineStats{}},
-		2: {11, LineStats{21, 31, 41}, map[string]LineStats{"Go": {52, 53, 54}}},
+		0: {11, LineStats{Added: 21, Removed: 31, Changed: 41}, map[string]LineStats{}},
+		2: {11, LineStats{Added: 21, Removed: 31, Changed: 41}, map[string]LineStats{"Go": {Added: 52, Removed: 53, Changed: 54}}},
 		identity.AuthorMissing: {
-			100 * 2, LineStats{200 * 2, 300 * 2, 400 * 2}, map[string]LineStats{"Go": {94, 96, 98}}},
+			100 * 2, LineStats{Added: 200 * 2, Removed: 300 * 2, Changed: 400 * 2}, map[string]LineStats{"Go": {Added: 94, Removed: 96, Changed: 98}}},
 	})
+	// the hours of r1 belong to the first day
+	rm = devs.MergeResults(r1, r2, &core.CommonAnalysisResult{TickSize: time.Hour},
+		&core.CommonAnalysisResult{TickSize: 24 * time.Hour}).(DevsResult)
+	assert.Len(t, rm.Days, 4)
+	assert.Equal(t, 21, rm.Days[0][0].Commits)
+	assert.Equal(t, 11, rm.Days[0][1].Commits)
+	assert.Equal(t, 100, rm.Days[0][identity.AuthorMissing].Commits)
+	assert.Equal(t, 1, rm.Days[1][0].Commits)
+	assert.Equal(t, 10, rm.Days[1][2].Commits)
+}
+
+func TestDevsTeams(t *testing.T) {
+	devs := fixtureDevs()
+	devs.reversedPeopleDict = []string{"one@srcd", "two@srcd", "three@srcd"}
+	teams := fixtureTeams(t, "core: [one@srcd, three@srcd]\n",
+		map[string]int{"one@srcd": 0, "two@srcd": 1, "three@srcd": 2})
+	assert.Nil(t, devs.Configure(map[string]interface{}{identity.FactIdentityDetectorTeams: teams}))
+	assert.Equal(t, teams, devs.teams)
+	devs.days[1] = map[int]*DevDay{}
+	devs.days[1][0] = &DevDay{10, LineStats{Added: 20, Removed: 30, Changed: 40}, map[string]LineStats{"Go": {Added: 2, Removed: 3, Changed: 4}}}
+	devs.days[1][1] = &DevDay{1, LineStats{Added: 2, Removed: 3, Changed: 4}, map[string]LineStats{"Go": {Added: 25, Removed: 35, Changed: 45}}}
+	devs.days[1][2] = &DevDay{5, LineStats{Added: 6, Removed: 7, Changed: 8}, map[string]LineStats{"Python": {Added: 6, Removed: 7, Changed: 8}}}
+	devs.days[1][identity.AuthorMissing] = &DevDay{
+		100, LineStats{Added: 200, Removed: 300, Changed: 400}, map[string]LineStats{"Go": {Added: 32, Removed: 33, Changed: 34}}}
+	res := devs.Finalize().(DevsResult)
+	assert.Equal(t, []string{"core", identity.TeamMissingName}, res.reversedTeamsDict)
+	assert.Equal(t, map[int]map[int]*DevDay{1: {
+		0: {15, LineStats{Added: 26, Removed: 37, Changed: 48}, map[string]LineStats{
+			"Go": {Added: 2, Removed: 3, Changed: 4}, "Python": {Added: 6, Removed: 7, Changed: 8}}},
+		1: {1, LineStats{Added: 2, Removed: 3, Changed: 4}, map[string]LineStats{"Go": {Added: 25, Removed: 35, Changed: 45}}},
+		identity.AuthorMissing: {
+			100, LineStats{Added: 200, Removed: 300, Changed: 400}, map[string]LineStats{"Go": {Added: 32, Removed: 33, Changed: 34}}},
+	}}, res.TeamDays)
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, devs.Serialize(res, false, buffer))
+	assert.Contains(t, buffer.String(), `  team_days:
+    1:
+      0: [15, 26, 37, 48, 0, {Go: [2, 3, 4, 0], Python: [6, 7, 8, 0]}]
+      1: [1, 2, 3, 4, 0, {Go: [25, 35, 45, 0]}]
+      -1: [100, 200, 300, 400, 0, {Go: [32, 33, 34, 0]}]
+  teams:
+  - "core"
+  - "<unassigned>"
+`)
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, devs.SerializeJSON(res, buffer))
+	assert.Contains(t, buffer.String(), `"teams":["core","\u003cunassigned\u003e"]`)
+	assert.Contains(t, buffer.String(), `"team_days":{"1":{"-1":{"commits":100,`)
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, devs.Serialize(res, true, buffer))
+	rawres2, err := devs.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, res, rawres2.(DevsResult))
+
+	rm := devs.MergeResults(res, res, nil, nil).(DevsResult)
+	// the teams are merged by name the same way as people
+	assert.Equal(t, []string{identity.TeamMissingName, "core"}, rm.reversedTeamsDict)
+	assert.Equal(t, 30, rm.TeamDays[1][1].Commits)
+	assert.Equal(t, 2, rm.TeamDays[1][0].Commits)
+	assert.Equal(t, 200, rm.TeamDays[1][identity.AuthorMissing].Commits)
+	res.TeamDays = nil
+	rm = devs.MergeResults(res, rawres2, nil, nil).(DevsResult)
+	assert.Nil(t, rm.TeamDays)
+}
+
+func TestDevsConsumeCoAuthors(t *testing.T) {
+	devs := fixtureDevs()
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 0
+	deps[identity.DependencyAuthors] = []int{0, 1}
+	deps[plumbing.DependencyDay] = 0
+	deps[plumbing.DependencyTreeChanges] = object.Changes{&object.Change{}}
+	deps[plumbing.DependencyLineStats] = map[object.ChangeEntry]plumbing.FileLineStats{
+		{Name: "a.go"}: {LineStats: LineStats{Added: 7, Removed: 3, Changed: 1}, Language: "Go"},
+	}
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	deps[core.DependencyIsMerge] = false
+	_, err := devs.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]*DevDay{
+		0: {1, LineStats{Added: 7, Removed: 3, Changed: 1}, map[string]LineStats{"Go": {Added: 7, Removed: 3, Changed: 1}}},
+		1: {1, LineStats{Added: 7, Removed: 3, Changed: 1}, map[string]LineStats{"Go": {Added: 7, Removed: 3, Changed: 1}}},
+	}, devs.days[0])
+
+	devs = fixtureDevs()
+	assert.Nil(t, devs.Configure(map[string]interface{}{
+		identity.FactIdentityDetectorCoAuthorsCredit: identity.CoAuthorsCreditSplit,
+	}))
+	_, err = devs.Consume(deps)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]*DevDay{
+		0: {1, LineStats{Added: 4, Removed: 2, Changed: 1}, map[string]LineStats{"Go": {Added: 4, Removed: 2, Changed: 1}}},
+		1: {1, LineStats{Added: 3, Removed: 1, Changed: 0}, map[string]LineStats{"Go": {Added: 3, Removed: 1, Changed: 0}}},
+	}, devs.days[0])
+
+	// excluded bot
+	devs = fixtureDevs()
+	deps[identity.DependencyAuthor] = identity.AuthorMissing
+	deps[identity.DependencyAuthors] = []int{}
+	_, err = devs.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, devs.days, 0)
+}
+
+func TestDevsConsumeIgnored(t *testing.T) {
+	devs := fixtureDevs()
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 0
+	deps[identity.DependencyAuthors] = []int{0}
+	deps[plumbing.DependencyDay] = 0
+	deps[plumbing.DependencyTreeChanges] = object.Changes{&object.Change{}}
+	deps[plumbing.DependencyLineStats] = map[object.ChangeEntry]plumbing.FileLineStats{
+		{Name: "a.go"}: {LineStats: LineStats{Added: 7, Removed: 3, Changed: 1}, Language: "Go"},
+	}
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(gitplumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	deps[core.DependencyIsMerge] = false
+	deps[plumbing.DependencyIgnoredCommit] = true
+	_, err := devs.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, devs.days, 0)
+	deps[plumbing.DependencyIgnoredCommit] = false
+	_, err = devs.Consume(deps)
+	assert.Nil(t, err)
+	assert.Len(t, devs.days[0], 1)
 }
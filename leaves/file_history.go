@@ -1,6 +1,7 @@
 package leaves
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"sort"
@@ -130,6 +131,12 @@ func (history *FileHistory) Serialize(result interface{}, binary bool, writer io
 	return nil
 }
 
+// SerializeJSON converts the analysis result as returned by Finalize() to JSON.
+func (history *FileHistory) SerializeJSON(result interface{}, writer io.Writer) error {
+	historyResult := result.(FileHistoryResult)
+	return history.serializeJSON(&historyResult, writer)
+}
+
 func (history *FileHistory) serializeText(result *FileHistoryResult, writer io.Writer) {
 	keys := make([]string, len(result.Files))
 	i := 0
@@ -148,6 +155,23 @@ func (history *FileHistory) serializeText(result *FileHistoryResult, writer io.W
 	}
 }
 
+// fileHistoryJSON is the JSON schema of FileHistoryResult, see doc/JSON.md.
+type fileHistoryJSON struct {
+	Files map[string][]string `json:"files"`
+}
+
+func (history *FileHistory) serializeJSON(result *FileHistoryResult, writer io.Writer) error {
+	message := fileHistoryJSON{Files: map[string][]string{}}
+	for key, vals := range result.Files {
+		hashes := make([]string, len(vals))
+		for i, hash := range vals {
+			hashes[i] = hash.String()
+		}
+		message.Files[key] = hashes
+	}
+	return json.NewEncoder(writer).Encode(&message)
+}
+
 func (history *FileHistory) serializeBinary(result *FileHistoryResult, writer io.Writer) error {
 	message := pb.FileHistoryResultMessage{
 		Files: map[string]*pb.FileHistory{},
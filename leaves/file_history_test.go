@@ -149,6 +149,34 @@ func TestFileHistorySerializeText(t *testing.T) {
 	assert.Equal(t, buffer.String(), "  - .travis.yml: [\"2b1ed978194a94edeabbca6de7ff3b5771d4d665\"]\n")
 }
 
+func TestFileHistorySerializeJSON(t *testing.T) {
+	fh := fixtureFileHistory()
+	deps := map[string]interface{}{}
+	changes := make(object.Changes, 1)
+	treeTo, _ := test.Repository.TreeObject(plumbing.NewHash(
+		"994eac1cd07235bb9815e547a75c84265dea00f5"))
+	changes[0] = &object.Change{From: object.ChangeEntry{}, To: object.ChangeEntry{
+		Name: ".travis.yml",
+		Tree: treeTo,
+		TreeEntry: object.TreeEntry{
+			Name: ".travis.yml",
+			Mode: 0100644,
+			Hash: plumbing.NewHash("291286b4ac41952cbd1389fda66420ec03c1a9fe"),
+		},
+	},
+	}
+	deps[items.DependencyTreeChanges] = changes
+	commit, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"2b1ed978194a94edeabbca6de7ff3b5771d4d665"))
+	deps[core.DependencyCommit] = commit
+	fh.Consume(deps)
+	res := fh.Finalize().(FileHistoryResult)
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, fh.SerializeJSON(res, buffer))
+	assert.Equal(t, `{"files":{".travis.yml":["2b1ed978194a94edeabbca6de7ff3b5771d4d665"]}}
+`, buffer.String())
+}
+
 func TestFileHistorySerializeBinary(t *testing.T) {
 	fh := fixtureFileHistory()
 	deps := map[string]interface{}{}
@@ -0,0 +1,568 @@
+package leaves
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/burndown"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	"gopkg.in/src-d/hercules.v8/internal/pb"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v8/internal/yaml"
+)
+
+// OwnershipAnalysis calculates how many lines each developer owns in every file and in every
+// directory at the last commit. A line is owned by the developer who edited it the last time.
+// Besides, it finds the bus factors and the "orphaned" files whose top owners have left.
+// The lines are tracked by BurndownAnalysis which is shared with --burndown.
+type OwnershipAnalysis struct {
+	// OrphanDays is the number of days before the last commit during which at least one
+	// of the top owners of a file must have committed, otherwise the file is orphaned.
+	OrphanDays int
+
+	// lines is the BurndownAnalysis which tracks the authors of the lines in each file.
+	lines *BurndownAnalysis
+	// lastCommitTimes maps the developer indexes to the UNIX timestamps of their latest commits.
+	lastCommitTimes map[int]int64
+	// endTime is the UNIX timestamp of the latest consumed commit.
+	endTime int64
+	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+}
+
+// OwnershipResult is returned by OwnershipAnalysis.Finalize() and carries the line ownership
+// per file and per directory.
+type OwnershipResult struct {
+	// Files maps the file paths to the developer indexes to the numbers of owned lines.
+	// -1 stands for the unidentified developers.
+	Files map[string]map[int]int
+	// Directories is the same as Files for each directory including the nested files.
+	// "." is the root directory.
+	Directories map[string]map[int]int
+	// LastCommitTimes maps the developer indexes to the UNIX timestamps of their latest commits.
+	LastCommitTimes map[int]int64
+	// EndTime is the UNIX timestamp of the latest analysed commit.
+	EndTime int64
+	// OrphanDays is the same as OwnershipAnalysis.OrphanDays.
+	OrphanDays int
+
+	// reversedPeopleDict references IdentityDetector.ReversedPeopleDict
+	reversedPeopleDict []string
+}
+
+const (
+	// ConfigOwnershipOrphanDays is the name of the option to set OwnershipAnalysis.OrphanDays.
+	ConfigOwnershipOrphanDays = "Ownership.OrphanDays"
+	// DefaultOwnershipOrphanDays is the default value of OwnershipAnalysis.OrphanDays.
+	DefaultOwnershipOrphanDays = 180
+)
+
+// Name of this PipelineItem. Uniquely identifies the type, used for mapping keys, etc.
+func (ownership *OwnershipAnalysis) Name() string {
+	return "Ownership"
+}
+
+// Provides returns the list of names of entities which are produced by this PipelineItem.
+// Each produced entity will be inserted into `deps` of dependent Consume()-s according
+// to this list. Also used by core.Registry to build the global map of providers.
+func (ownership *OwnershipAnalysis) Provides() []string {
+	return []string{}
+}
+
+// Requires returns the list of names of entities which are needed by this PipelineItem.
+// Each requested entity will be inserted into `deps` of Consume(). In turn, those
+// entities are Provides() upstream.
+func (ownership *OwnershipAnalysis) Requires() []string {
+	arr := [...]string{
+		DependencyBurndownLines, identity.DependencyAuthor, identity.DependencyAuthors}
+	return arr[:]
+}
+
+// ListConfigurationOptions returns the list of changeable public properties of this PipelineItem.
+func (ownership *OwnershipAnalysis) ListConfigurationOptions() []core.ConfigurationOption {
+	options := [...]core.ConfigurationOption{{
+		Name: ConfigOwnershipOrphanDays,
+		Description: "A file is orphaned if none of its top owners has committed " +
+			"during this number of days before the last commit.",
+		Flag:    "orphan-days",
+		Type:    core.IntConfigurationOption,
+		Default: DefaultOwnershipOrphanDays},
+	}
+	return options[:]
+}
+
+// Configure sets the properties previously published by ListConfigurationOptions().
+// It makes BurndownAnalysis track the authors of the lines even without --burndown-people.
+func (ownership *OwnershipAnalysis) Configure(facts map[string]interface{}) error {
+	if val, exists := facts[ConfigOwnershipOrphanDays].(int); exists {
+		ownership.OrphanDays = val
+	}
+	if val, exists := facts[identity.FactIdentityDetectorReversedPeopleDict].([]string); exists {
+		ownership.reversedPeopleDict = val
+	}
+	if val, exists := facts[FactBurndownAnalysis].(*BurndownAnalysis); exists {
+		val.trackAuthors = true
+	}
+	return nil
+}
+
+// Flag for the command line switch which enables this analysis.
+func (ownership *OwnershipAnalysis) Flag() string {
+	return "ownership"
+}
+
+// Description returns the text which explains what the analysis is doing.
+func (ownership *OwnershipAnalysis) Description() string {
+	return "Calculates the numbers of lines owned by each developer per file and per directory " +
+		"at the last commit, the bus factors and the orphaned files."
+}
+
+// Initialize resets the temporary caches and prepares this PipelineItem for a series of Consume()
+// calls. The repository which is going to be analysed is supplied as an argument.
+func (ownership *OwnershipAnalysis) Initialize(repository *git.Repository) error {
+	if ownership.OrphanDays <= 0 {
+		log.Printf("Warning: adjusted the orphan days to %d\n", DefaultOwnershipOrphanDays)
+		ownership.OrphanDays = DefaultOwnershipOrphanDays
+	}
+	ownership.lines = nil
+	ownership.lastCommitTimes = map[int]int64{}
+	ownership.endTime = 0
+	return nil
+}
+
+// Consume runs this PipelineItem on the next commit's data.
+// `deps` contain all the results from upstream PipelineItem-s as requested by Requires().
+// Additionally, DependencyCommit is always present there and represents the analysed *object.Commit.
+// This function returns the mapping with analysis results. The keys must be the same as
+// in Provides(). If there was an error, nil is returned.
+func (ownership *OwnershipAnalysis) Consume(deps map[string]interface{}) (map[string]interface{}, error) {
+	commit := deps[core.DependencyCommit].(*object.Commit)
+	when := commit.Author.When.Unix()
+	for _, author := range commitAuthors(deps) {
+		if author != identity.AuthorMissing && when > ownership.lastCommitTimes[author] {
+			ownership.lastCommitTimes[author] = when
+		}
+	}
+	if when > ownership.endTime {
+		ownership.endTime = when
+	}
+	ownership.lines = deps[DependencyBurndownLines].(*BurndownAnalysis)
+	return nil, nil
+}
+
+// Fork clones this item. The lines belong to the BurndownAnalysis of each branch.
+func (ownership *OwnershipAnalysis) Fork(n int) []core.PipelineItem {
+	result := make([]core.PipelineItem, n)
+	for i := range result {
+		clone := *ownership
+		clone.lastCommitTimes = map[int]int64{}
+		for dev, when := range ownership.lastCommitTimes {
+			clone.lastCommitTimes[dev] = when
+		}
+		result[i] = &clone
+	}
+	return result
+}
+
+// Merge combines several items together. The lines are merged by BurndownAnalysis.Merge().
+func (ownership *OwnershipAnalysis) Merge(branches []core.PipelineItem) {
+	all := make([]*OwnershipAnalysis, len(branches))
+	for i, branch := range branches {
+		all[i] = branch.(*OwnershipAnalysis)
+	}
+	for _, other := range all {
+		for dev, when := range other.lastCommitTimes {
+			if when > ownership.lastCommitTimes[dev] {
+				ownership.lastCommitTimes[dev] = when
+			}
+		}
+		if other.endTime > ownership.endTime {
+			ownership.endTime = other.endTime
+		}
+	}
+	for _, other := range all {
+		for dev, when := range ownership.lastCommitTimes {
+			other.lastCommitTimes[dev] = when
+		}
+		other.endTime = ownership.endTime
+	}
+}
+
+// ownershipCheckpoint is the state of OwnershipAnalysis which is saved in Checkpoint().
+type ownershipCheckpoint struct {
+	LastCommitTimes map[int]int64
+	EndTime         int64
+}
+
+// Checkpoint writes the latest commit times. The lines are saved by BurndownAnalysis.
+func (ownership *OwnershipAnalysis) Checkpoint(writer io.Writer) error {
+	return gob.NewEncoder(writer).Encode(&ownershipCheckpoint{
+		LastCommitTimes: ownership.lastCommitTimes,
+		EndTime:         ownership.endTime,
+	})
+}
+
+// Restore reads the state previously written by Checkpoint().
+func (ownership *OwnershipAnalysis) Restore(reader io.Reader, facts map[string]interface{}) error {
+	state := ownershipCheckpoint{}
+	err := gob.NewDecoder(reader).Decode(&state)
+	if err != nil {
+		return err
+	}
+	if val, exists := facts[FactBurndownAnalysis].(*BurndownAnalysis); exists {
+		ownership.lines = val
+	}
+	for dev, when := range state.LastCommitTimes {
+		ownership.lastCommitTimes[dev] = when
+	}
+	ownership.endTime = state.EndTime
+	return nil
+}
+
+// Finalize returns the result of the analysis. Further Consume() calls are not expected.
+func (ownership *OwnershipAnalysis) Finalize() interface{} {
+	files := map[string]map[int]int{}
+	directories := map[string]map[int]int{}
+	var tracked map[string]*burndown.File
+	if ownership.lines != nil {
+		tracked = ownership.lines.files
+	}
+	for name, file := range tracked {
+		if file == nil {
+			continue
+		}
+		owners := ownership.lines.fileOwnership(file)
+		if len(owners) == 0 {
+			continue
+		}
+		files[name] = owners
+		for dir := path.Dir(name); ; dir = path.Dir(dir) {
+			dirOwners, exists := directories[dir]
+			if !exists {
+				dirOwners = map[int]int{}
+				directories[dir] = dirOwners
+			}
+			for dev, lines := range owners {
+				dirOwners[dev] += lines
+			}
+			if dir == "." {
+				break
+			}
+		}
+	}
+	return OwnershipResult{
+		Files:              files,
+		Directories:        directories,
+		LastCommitTimes:    ownership.lastCommitTimes,
+		EndTime:            ownership.endTime,
+		OrphanDays:         ownership.OrphanDays,
+		reversedPeopleDict: ownership.reversedPeopleDict,
+	}
+}
+
+// TopOwners returns the minimum number of the identified developers who own more than a half
+// of the lines written by the identified developers. `owners` maps the developer indexes
+// to the numbers of owned lines. The developers are sorted by the owned lines in descending order.
+func TopOwners(owners map[int]int) []int {
+	var devs []int
+	total := 0
+	for dev, lines := range owners {
+		if dev >= 0 {
+			devs = append(devs, dev)
+			total += lines
+		}
+	}
+	sort.Slice(devs, func(i, j int) bool {
+		if owners[devs[i]] != owners[devs[j]] {
+			return owners[devs[i]] > owners[devs[j]]
+		}
+		return devs[i] < devs[j]
+	})
+	sum := 0
+	for i, dev := range devs {
+		sum += owners[dev]
+		if sum*2 > total {
+			return devs[:i+1]
+		}
+	}
+	return devs
+}
+
+// BusFactor returns the number of TopOwners().
+func BusFactor(owners map[int]int) int {
+	return len(TopOwners(owners))
+}
+
+// OrphanedFiles returns the sorted paths of the files whose top owners have not committed during
+// OrphanDays before EndTime. The files without identified owners are never orphaned.
+func (result *OwnershipResult) OrphanedFiles() []string {
+	threshold := result.EndTime - int64(result.OrphanDays)*24*3600
+	orphaned := []string{}
+	for name, owners := range result.Files {
+		top := TopOwners(owners)
+		if len(top) == 0 {
+			continue
+		}
+		active := false
+		for _, dev := range top {
+			if result.LastCommitTimes[dev] >= threshold {
+				active = true
+				break
+			}
+		}
+		if !active {
+			orphaned = append(orphaned, name)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned
+}
+
+// Serialize converts the analysis result as returned by Finalize() to text or bytes.
+// The text format is YAML and the bytes format is Protocol Buffers.
+func (ownership *OwnershipAnalysis) Serialize(result interface{}, binary bool, writer io.Writer) error {
+	ownershipResult := result.(OwnershipResult)
+	if binary {
+		return ownership.serializeBinary(&ownershipResult, writer)
+	}
+	ownership.serializeText(&ownershipResult, writer)
+	return nil
+}
+
+// SerializeJSON converts the analysis result as returned by Finalize() to JSON.
+func (ownership *OwnershipAnalysis) SerializeJSON(result interface{}, writer io.Writer) error {
+	ownershipResult := result.(OwnershipResult)
+	return ownership.serializeJSON(&ownershipResult, writer)
+}
+
+// Deserialize converts the specified protobuf bytes to OwnershipResult.
+func (ownership *OwnershipAnalysis) Deserialize(pbmessage []byte) (interface{}, error) {
+	message := pb.OwnershipAnalysisResults{}
+	err := proto.Unmarshal(pbmessage, &message)
+	if err != nil {
+		return nil, err
+	}
+	convertRecords := func(records map[string]*pb.OwnershipRecord) map[string]map[int]int {
+		result := map[string]map[int]int{}
+		for name, record := range records {
+			owners := map[int]int{}
+			for dev, lines := range record.Owners {
+				owners[int(dev)] = int(lines)
+			}
+			result[name] = owners
+		}
+		return result
+	}
+	result := OwnershipResult{
+		Files:              convertRecords(message.Files),
+		Directories:        convertRecords(message.Directories),
+		LastCommitTimes:    map[int]int64{},
+		EndTime:            message.EndTime,
+		OrphanDays:         int(message.OrphanDays),
+		reversedPeopleDict: message.DevIndex,
+	}
+	for dev, when := range message.LastCommitTimes {
+		result.LastCommitTimes[int(dev)] = when
+	}
+	return result, nil
+}
+
+// MergeResults combines two OwnershipResult-s together. The line counts are summed,
+// the developers are matched by their identities.
+func (ownership *OwnershipAnalysis) MergeResults(
+	r1, r2 interface{}, c1, c2 *core.CommonAnalysisResult) interface{} {
+	or1 := r1.(OwnershipResult)
+	or2 := r2.(OwnershipResult)
+	merged := OwnershipResult{
+		Files:           map[string]map[int]int{},
+		Directories:     map[string]map[int]int{},
+		LastCommitTimes: map[int]int64{},
+		EndTime:         or1.EndTime,
+		OrphanDays:      or1.OrphanDays,
+	}
+	if or2.EndTime > merged.EndTime {
+		merged.EndTime = or2.EndTime
+	}
+	if or2.OrphanDays < merged.OrphanDays {
+		merged.OrphanDays = or2.OrphanDays
+	}
+	var people map[string][3]int
+	people, merged.reversedPeopleDict = identity.Detector{}.MergeReversedDicts(
+		or1.reversedPeopleDict, or2.reversedPeopleDict)
+	for _, result := range [...]*OwnershipResult{&or1, &or2} {
+		reindex := func(dev int) int {
+			if dev < 0 {
+				return dev
+			}
+			return people[result.reversedPeopleDict[dev]][0]
+		}
+		mergeOwnership := func(dst, src map[string]map[int]int) {
+			for name, owners := range src {
+				mergedOwners, exists := dst[name]
+				if !exists {
+					mergedOwners = map[int]int{}
+					dst[name] = mergedOwners
+				}
+				for dev, lines := range owners {
+					mergedOwners[reindex(dev)] += lines
+				}
+			}
+		}
+		mergeOwnership(merged.Files, result.Files)
+		mergeOwnership(merged.Directories, result.Directories)
+		for dev, when := range result.LastCommitTimes {
+			dev = reindex(dev)
+			if when > merged.LastCommitTimes[dev] {
+				merged.LastCommitTimes[dev] = when
+			}
+		}
+	}
+	return merged
+}
+
+func sortedOwnershipKeys(records map[string]map[int]int) []string {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatOwners(owners map[int]int) string {
+	devs := make([]int, 0, len(owners))
+	for dev := range owners {
+		devs = append(devs, dev)
+	}
+	sort.Ints(devs)
+	pairs := make([]string, len(devs))
+	for i, dev := range devs {
+		pairs[i] = fmt.Sprintf("%d: %d", dev, owners[dev])
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func (ownership *OwnershipAnalysis) serializeText(result *OwnershipResult, writer io.Writer) {
+	for _, section := range [...]struct {
+		Name    string
+		Records map[string]map[int]int
+	}{{"files", result.Files}, {"directories", result.Directories}} {
+		fmt.Fprintf(writer, "  %s:\n", section.Name)
+		for _, name := range sortedOwnershipKeys(section.Records) {
+			owners := section.Records[name]
+			fmt.Fprintf(writer, "    %s:\n", yaml.SafeString(name))
+			fmt.Fprintf(writer, "      owners: %s\n", formatOwners(owners))
+			fmt.Fprintf(writer, "      bus_factor: %d\n", BusFactor(owners))
+		}
+	}
+	fmt.Fprintln(writer, "  orphaned_files:")
+	for _, name := range result.OrphanedFiles() {
+		fmt.Fprintf(writer, "    - %s\n", yaml.SafeString(name))
+	}
+	fmt.Fprintln(writer, "  last_commit_times:")
+	devs := make([]int, 0, len(result.LastCommitTimes))
+	for dev := range result.LastCommitTimes {
+		devs = append(devs, dev)
+	}
+	sort.Ints(devs)
+	for _, dev := range devs {
+		fmt.Fprintf(writer, "    %d: %d\n", dev, result.LastCommitTimes[dev])
+	}
+	fmt.Fprintf(writer, "  end_time: %d\n", result.EndTime)
+	fmt.Fprintf(writer, "  orphan_days: %d\n", result.OrphanDays)
+	fmt.Fprintln(writer, "  people:")
+	for _, person := range result.reversedPeopleDict {
+		fmt.Fprintf(writer, "  - %s\n", yaml.SafeString(person))
+	}
+}
+
+// ownershipRecordJSON is the JSON schema of the ownership of a file or a directory, see doc/JSON.md.
+type ownershipRecordJSON struct {
+	Owners    map[int]int `json:"owners"`
+	BusFactor int         `json:"bus_factor"`
+}
+
+// ownershipJSON is the JSON schema of OwnershipResult, see doc/JSON.md.
+type ownershipJSON struct {
+	Files           map[string]ownershipRecordJSON `json:"files"`
+	Directories     map[string]ownershipRecordJSON `json:"directories"`
+	OrphanedFiles   []string                       `json:"orphaned_files"`
+	LastCommitTimes map[int]int64                  `json:"last_commit_times"`
+	EndTime         int64                          `json:"end_time"`
+	OrphanDays      int                            `json:"orphan_days"`
+	People          []string                       `json:"people"`
+}
+
+func (ownership *OwnershipAnalysis) serializeJSON(result *OwnershipResult, writer io.Writer) error {
+	convertRecords := func(records map[string]map[int]int) map[string]ownershipRecordJSON {
+		converted := map[string]ownershipRecordJSON{}
+		for name, owners := range records {
+			converted[name] = ownershipRecordJSON{Owners: owners, BusFactor: BusFactor(owners)}
+		}
+		return converted
+	}
+	message := ownershipJSON{
+		Files:           convertRecords(result.Files),
+		Directories:     convertRecords(result.Directories),
+		OrphanedFiles:   result.OrphanedFiles(),
+		LastCommitTimes: result.LastCommitTimes,
+		EndTime:         result.EndTime,
+		OrphanDays:      result.OrphanDays,
+		People:          result.reversedPeopleDict,
+	}
+	if message.LastCommitTimes == nil {
+		message.LastCommitTimes = map[int]int64{}
+	}
+	if message.People == nil {
+		message.People = []string{}
+	}
+	return json.NewEncoder(writer).Encode(&message)
+}
+
+func (ownership *OwnershipAnalysis) serializeBinary(result *OwnershipResult, writer io.Writer) error {
+	convertRecords := func(records map[string]map[int]int) map[string]*pb.OwnershipRecord {
+		converted := map[string]*pb.OwnershipRecord{}
+		for name, owners := range records {
+			record := &pb.OwnershipRecord{
+				Owners:    map[int32]int32{},
+				BusFactor: int32(BusFactor(owners)),
+			}
+			for dev, lines := range owners {
+				record.Owners[int32(dev)] = int32(lines)
+			}
+			converted[name] = record
+		}
+		return converted
+	}
+	message := pb.OwnershipAnalysisResults{
+		Files:           convertRecords(result.Files),
+		Directories:     convertRecords(result.Directories),
+		LastCommitTimes: map[int32]int64{},
+		EndTime:         result.EndTime,
+		OrphanDays:      int32(result.OrphanDays),
+		OrphanedFiles:   result.OrphanedFiles(),
+		DevIndex:        result.reversedPeopleDict,
+	}
+	for dev, when := range result.LastCommitTimes {
+		message.LastCommitTimes[int32(dev)] = when
+	}
+	serialized, err := proto.Marshal(&message)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(serialized)
+	return err
+}
+
+func init() {
+	core.Registry.Register(&OwnershipAnalysis{})
+}
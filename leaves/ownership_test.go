@@ -0,0 +1,456 @@
+package leaves
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/hercules.v8/internal/core"
+	"gopkg.in/src-d/hercules.v8/internal/pb"
+	items "gopkg.in/src-d/hercules.v8/internal/plumbing"
+	"gopkg.in/src-d/hercules.v8/internal/plumbing/identity"
+	"gopkg.in/src-d/hercules.v8/internal/test"
+	"gopkg.in/src-d/hercules.v8/internal/test/fixtures"
+)
+
+// fixtureOwnership returns OwnershipAnalysis and the BurndownAnalysis which tracks its lines.
+func fixtureOwnership(extraFacts ...map[string]interface{}) (*OwnershipAnalysis, *BurndownAnalysis) {
+	facts := map[string]interface{}{
+		ConfigBurndownGranularity:                       DefaultBurndownGranularity,
+		ConfigBurndownSampling:                          DefaultBurndownGranularity,
+		ConfigOwnershipOrphanDays:                       DefaultOwnershipOrphanDays,
+		identity.FactIdentityDetectorPeopleCount:        2,
+		identity.FactIdentityDetectorReversedPeopleDict: []string{"one@srcd", "two@srcd"},
+	}
+	for _, extra := range extraFacts {
+		for key, val := range extra {
+			facts[key] = val
+		}
+	}
+	lines := &BurndownAnalysis{}
+	lines.Configure(facts)
+	ownership := &OwnershipAnalysis{}
+	ownership.Configure(facts)
+	lines.Initialize(test.Repository)
+	ownership.Initialize(test.Repository)
+	return ownership, lines
+}
+
+// consumeOwnershipCommit runs BurndownAnalysis and then OwnershipAnalysis on the same commit.
+func consumeOwnershipCommit(
+	t *testing.T, ownership *OwnershipAnalysis, lines *BurndownAnalysis,
+	deps map[string]interface{}) {
+	result, err := lines.Consume(deps)
+	assert.Nil(t, err)
+	deps[DependencyBurndownLines] = result[DependencyBurndownLines]
+	result, err = ownership.Consume(deps)
+	assert.Nil(t, result)
+	assert.Nil(t, err)
+}
+
+func TestOwnershipMeta(t *testing.T) {
+	ownership, lines := fixtureOwnership()
+	assert.Equal(t, ownership.Name(), "Ownership")
+	assert.Len(t, ownership.Provides(), 0)
+	assert.Equal(t, []string{
+		DependencyBurndownLines, identity.DependencyAuthor, identity.DependencyAuthors},
+		ownership.Requires())
+	opts := ownership.ListConfigurationOptions()
+	assert.Len(t, opts, 1)
+	assert.Equal(t, opts[0].Name, ConfigOwnershipOrphanDays)
+	assert.Equal(t, ownership.Flag(), "ownership")
+	assert.NotEqual(t, ownership.Description(), "")
+	assert.Equal(t, DefaultOwnershipOrphanDays, ownership.OrphanDays)
+	// --burndown-people is not required
+	assert.Equal(t, 0, lines.PeopleNumber)
+	assert.True(t, lines.trackAuthors)
+}
+
+func TestOwnershipConfigure(t *testing.T) {
+	ownership := OwnershipAnalysis{}
+	facts := map[string]interface{}{}
+	facts[ConfigOwnershipOrphanDays] = 30
+	facts[identity.FactIdentityDetectorReversedPeopleDict] = []string{"one", "two"}
+	assert.Nil(t, ownership.Configure(facts))
+	assert.Equal(t, 30, ownership.OrphanDays)
+	assert.Equal(t, []string{"one", "two"}, ownership.reversedPeopleDict)
+	lines := &BurndownAnalysis{}
+	assert.Nil(t, lines.Configure(facts))
+	assert.False(t, lines.trackAuthors)
+	assert.Nil(t, ownership.Configure(facts))
+	assert.True(t, lines.trackAuthors)
+}
+
+// TestOwnershipSharedLines checks that the lines tracked for OwnershipAnalysis do not change
+// the output of BurndownAnalysis.
+func TestOwnershipSharedLines(t *testing.T) {
+	ownership, lines := fixtureOwnership(map[string]interface{}{ConfigBurndownTrackFiles: true})
+	consumeOwnership(t, ownership, lines)
+	assert.Equal(t, map[string]map[int]int{
+		"analyser.go":          {0: 926},
+		"cmd/hercules/main.go": {0: 207},
+		".travis.yml":          {0: 12},
+		"internal/main.go":     {1: 207},
+	}, ownership.Finalize().(OwnershipResult).Files)
+	assert.Equal(t, map[string]map[int]int{
+		"analyser.go":          {-1: 926},
+		"cmd/hercules/main.go": {-1: 207},
+		".travis.yml":          {-1: 12},
+		"internal/main.go":     {-1: 207},
+	}, lines.Finalize().(BurndownResult).FileOwnership)
+}
+
+// TestOwnershipConsumeMovedIgnored checks that the moved lines and the lines changed
+// by the ignored commits keep their owners.
+func TestOwnershipConsumeMovedIgnored(t *testing.T) {
+	cache := map[plumbing.Hash]*items.CachedBlob{}
+	entry := func(name, text string) object.ChangeEntry {
+		blob := &items.CachedBlob{Data: []byte(text)}
+		blob.Hash = plumbing.ComputeHash(plumbing.BlobObject, blob.Data)
+		cache[blob.Hash] = blob
+		return object.ChangeEntry{Name: name, TreeEntry: object.TreeEntry{
+			Name: name, Mode: 0100644, Hash: blob.Hash}}
+	}
+	alpha := "func Alpha(x int) int {\n\ty := x * 2\n\tz := y - 1\n\treturn z + 1\n}\n"
+	beta := "func Beta() {\n\tprintln(\"beta\")\n}\n"
+	aOld := entry("a.go", "package a\n\n"+alpha+"\n"+beta)
+	aNew := entry("a.go", "package a\n\n"+beta)
+	aFormatted := entry("a.go", "package a\n\n"+strings.Replace(beta, "\t", "    ", -1))
+	b := entry("b.go", "package b\n\n"+alpha)
+
+	for _, enabled := range []bool{true, false} {
+		facts := map[string]interface{}{
+			ConfigOwnershipOrphanDays:                       DefaultOwnershipOrphanDays,
+			items.ConfigMoveDetectionEnabled:                enabled,
+			identity.FactIdentityDetectorPeopleCount:        2,
+			identity.FactIdentityDetectorReversedPeopleDict: []string{"one@srcd", "two@srcd"},
+		}
+		ownership, lines := fixtureOwnership(facts)
+		moves := &items.MoveDetection{}
+		assert.Nil(t, moves.Configure(facts))
+		assert.Nil(t, moves.Initialize(test.Repository))
+		deps := map[string]interface{}{}
+		deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+			"cce947b98a050c6d356bc6ba95030254914027b1"))
+		deps[core.DependencyIsMerge] = false
+		deps[items.DependencyBlobCache] = cache
+		consume := func(author, day int, ignored bool, changes ...*object.Change) {
+			deps[identity.DependencyAuthor] = author
+			deps[items.DependencyDay] = day
+			deps[items.DependencyIgnoredCommit] = ignored && enabled
+			deps[items.DependencyTreeChanges] = object.Changes(changes)
+			result, err := fixtures.FileDiff().Consume(deps)
+			assert.Nil(t, err)
+			deps[items.DependencyFileDiff] = result[items.DependencyFileDiff]
+			result, err = moves.Consume(deps)
+			assert.Nil(t, err)
+			deps[items.DependencyMovedBlocks] = result[items.DependencyMovedBlocks]
+			consumeOwnershipCommit(t, ownership, lines, deps)
+		}
+		consume(0, 0, false, &object.Change{To: aOld})
+		consume(1, 10, false, &object.Change{From: aOld, To: aNew}, &object.Change{To: b})
+		consume(1, 20, true, &object.Change{From: aNew, To: aFormatted})
+		result := ownership.Finalize().(OwnershipResult)
+		if enabled {
+			assert.Equal(t, map[string]map[int]int{
+				"a.go": {0: 5},
+				"b.go": {0: 5, 1: 2},
+			}, result.Files)
+		} else {
+			assert.Equal(t, map[string]map[int]int{
+				"a.go": {0: 4, 1: 1},
+				"b.go": {1: 7},
+			}, result.Files)
+		}
+	}
+}
+
+func TestOwnershipRegistration(t *testing.T) {
+	summoned := core.Registry.Summon((&OwnershipAnalysis{}).Name())
+	assert.Len(t, summoned, 1)
+	assert.Equal(t, summoned[0].Name(), "Ownership")
+	leaves := core.Registry.GetLeaves()
+	matched := false
+	for _, tp := range leaves {
+		if tp.Flag() == (&OwnershipAnalysis{}).Flag() {
+			matched = true
+			break
+		}
+	}
+	assert.True(t, matched)
+}
+
+func ownershipInsertion(name, hash string) *object.Change {
+	return &object.Change{From: object.ChangeEntry{}, To: object.ChangeEntry{
+		Name: name,
+		TreeEntry: object.TreeEntry{
+			Name: name,
+			Mode: 0100644,
+			Hash: plumbing.NewHash(hash),
+		},
+	}}
+}
+
+// consumeOwnership inserts 3 files by the first developer and then 1 file by the second.
+func consumeOwnership(t *testing.T, ownership *OwnershipAnalysis, lines *BurndownAnalysis) {
+	deps := map[string]interface{}{}
+	deps[identity.DependencyAuthor] = 0
+	deps[items.DependencyDay] = 0
+	cache := map[plumbing.Hash]*items.CachedBlob{}
+	AddHash(t, cache, "291286b4ac41952cbd1389fda66420ec03c1a9fe")
+	AddHash(t, cache, "c29112dbd697ad9b401333b80c18a63951bc18d9")
+	AddHash(t, cache, "baa64828831d174f40140e4b3cfa77d1e917a2c1")
+	deps[items.DependencyBlobCache] = cache
+	deps[items.DependencyTreeChanges] = object.Changes{
+		ownershipInsertion("analyser.go", "baa64828831d174f40140e4b3cfa77d1e917a2c1"),
+		ownershipInsertion("cmd/hercules/main.go", "c29112dbd697ad9b401333b80c18a63951bc18d9"),
+		ownershipInsertion(".travis.yml", "291286b4ac41952cbd1389fda66420ec03c1a9fe"),
+	}
+	fd := fixtures.FileDiff()
+	result, err := fd.Consume(deps)
+	assert.Nil(t, err)
+	deps[items.DependencyFileDiff] = result[items.DependencyFileDiff]
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	deps[core.DependencyIsMerge] = false
+	consumeOwnershipCommit(t, ownership, lines, deps)
+
+	deps[identity.DependencyAuthor] = 1
+	deps[items.DependencyDay] = 10
+	deps[items.DependencyTreeChanges] = object.Changes{
+		ownershipInsertion("internal/main.go", "c29112dbd697ad9b401333b80c18a63951bc18d9"),
+	}
+	deps[core.DependencyCommit], _ = test.Repository.CommitObject(plumbing.NewHash(
+		"2b1ed978194a94edeabbca6de7ff3b5771d4d665"))
+	result, err = fd.Consume(deps)
+	assert.Nil(t, err)
+	deps[items.DependencyFileDiff] = result[items.DependencyFileDiff]
+	consumeOwnershipCommit(t, ownership, lines, deps)
+}
+
+func TestOwnershipConsumeFinalize(t *testing.T) {
+	ownership, lines := fixtureOwnership()
+	consumeOwnership(t, ownership, lines)
+	commit1, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"cce947b98a050c6d356bc6ba95030254914027b1"))
+	commit2, _ := test.Repository.CommitObject(plumbing.NewHash(
+		"2b1ed978194a94edeabbca6de7ff3b5771d4d665"))
+	result := ownership.Finalize().(OwnershipResult)
+	assert.Equal(t, map[string]map[int]int{
+		"analyser.go":          {0: 926},
+		"cmd/hercules/main.go": {0: 207},
+		".travis.yml":          {0: 12},
+		"internal/main.go":     {1: 207},
+	}, result.Files)
+	assert.Equal(t, map[string]map[int]int{
+		".":            {0: 926 + 207 + 12, 1: 207},
+		"cmd":          {0: 207},
+		"cmd/hercules": {0: 207},
+		"internal":     {1: 207},
+	}, result.Directories)
+	assert.Equal(t, map[int]int64{
+		0: commit1.Author.When.Unix(),
+		1: commit2.Author.When.Unix(),
+	}, result.LastCommitTimes)
+	assert.Equal(t, commit2.Author.When.Unix(), result.EndTime)
+	assert.Equal(t, DefaultOwnershipOrphanDays, result.OrphanDays)
+	assert.Equal(t, []string{"one@srcd", "two@srcd"}, result.reversedPeopleDict)
+}
+
+func TestOwnershipTopOwners(t *testing.T) {
+	assert.Len(t, TopOwners(map[int]int{}), 0)
+	assert.Len(t, TopOwners(map[int]int{-1: 100}), 0)
+	assert.Equal(t, []int{2}, TopOwners(map[int]int{0: 10, 1: 20, 2: 40, -1: 100}))
+	assert.Equal(t, []int{2, 1}, TopOwners(map[int]int{0: 10, 1: 20, 2: 30}))
+	assert.Equal(t, []int{0, 1}, TopOwners(map[int]int{0: 10, 1: 10}))
+	assert.Equal(t, 0, BusFactor(map[int]int{-1: 5}))
+	assert.Equal(t, 3, BusFactor(map[int]int{0: 10, 1: 10, 2: 10, 3: 10}))
+}
+
+func TestOwnershipOrphanedFiles(t *testing.T) {
+	day := int64(24 * 3600)
+	result := OwnershipResult{
+		Files: map[string]map[int]int{
+			"active":       {0: 10, 1: 1},
+			"orphaned":     {1: 10, 0: 1},
+			"shared":       {1: 10, 0: 10},
+			"unidentified": {-1: 10},
+		},
+		LastCommitTimes: map[int]int64{0: 100 * day, 1: 50 * day},
+		EndTime:         100 * day,
+		OrphanDays:      30,
+	}
+	assert.Equal(t, []string{"orphaned"}, result.OrphanedFiles())
+	result.OrphanDays = 50
+	assert.Equal(t, []string{}, result.OrphanedFiles())
+}
+
+func TestOwnershipFork(t *testing.T) {
+	ownership, lines := fixtureOwnership()
+	consumeOwnership(t, ownership, lines)
+	clones := ownership.Fork(2)
+	assert.Len(t, clones, 2)
+	clone := clones[0].(*OwnershipAnalysis)
+	assert.True(t, ownership.lines == clone.lines)
+	clone.lastCommitTimes[0]++
+	assert.NotEqual(t, ownership.lastCommitTimes[0], clone.lastCommitTimes[0])
+	clone.endTime++
+	ownership.Merge(clones)
+	for _, item := range append(clones, ownership) {
+		other := item.(*OwnershipAnalysis)
+		assert.Equal(t, clone.lastCommitTimes, other.lastCommitTimes)
+		assert.Equal(t, clone.endTime, other.endTime)
+	}
+	assert.Len(t, ownership.Finalize().(OwnershipResult).Files, 4)
+}
+
+func TestOwnershipCheckpointRestore(t *testing.T) {
+	ownership, lines := fixtureOwnership()
+	consumeOwnership(t, ownership, lines)
+	linesBuffer := &bytes.Buffer{}
+	assert.Nil(t, lines.Checkpoint(linesBuffer))
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, ownership.Checkpoint(buffer))
+	// BurndownAnalysis is restored before OwnershipAnalysis is configured
+	facts := map[string]interface{}{}
+	restoredLines := &BurndownAnalysis{Granularity: lines.Granularity, Sampling: lines.Sampling}
+	assert.Nil(t, restoredLines.Configure(facts))
+	assert.Nil(t, restoredLines.Initialize(test.Repository))
+	assert.Nil(t, restoredLines.Restore(bytes.NewReader(linesBuffer.Bytes()), facts))
+	assert.True(t, restoredLines.trackAuthors)
+	restored := &OwnershipAnalysis{}
+	assert.Nil(t, restored.Configure(facts))
+	assert.Nil(t, restored.Initialize(test.Repository))
+	assert.Nil(t, restored.Restore(bytes.NewReader(buffer.Bytes()), facts))
+	restored.reversedPeopleDict = ownership.reversedPeopleDict
+	assert.Equal(t, ownership.Finalize(), restored.Finalize())
+	assert.NotNil(t, restored.Restore(bytes.NewReader([]byte("xxx")), nil))
+}
+
+func TestOwnershipSerialize(t *testing.T) {
+	ownership, lines := fixtureOwnership()
+	consumeOwnership(t, ownership, lines)
+	result := ownership.Finalize().(OwnershipResult)
+	result.OrphanDays = 1
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, ownership.Serialize(result, false, buffer))
+	assert.Equal(t, `  files:
+    ".travis.yml":
+      owners: {0: 12}
+      bus_factor: 1
+    "analyser.go":
+      owners: {0: 926}
+      bus_factor: 1
+    "cmd/hercules/main.go":
+      owners: {0: 207}
+      bus_factor: 1
+    "internal/main.go":
+      owners: {1: 207}
+      bus_factor: 1
+  directories:
+    ".":
+      owners: {0: 1145, 1: 207}
+      bus_factor: 1
+    "cmd":
+      owners: {0: 207}
+      bus_factor: 1
+    "cmd/hercules":
+      owners: {0: 207}
+      bus_factor: 1
+    "internal":
+      owners: {1: 207}
+      bus_factor: 1
+  orphaned_files:
+    - ".travis.yml"
+    - "analyser.go"
+    - "cmd/hercules/main.go"
+  last_commit_times:
+    0: 1481563829
+    1: 1501342361
+  end_time: 1501342361
+  orphan_days: 1
+  people:
+  - "one@srcd"
+  - "two@srcd"
+`, buffer.String())
+
+	buffer = &bytes.Buffer{}
+	assert.Nil(t, ownership.Serialize(result, true, buffer))
+	msg := pb.OwnershipAnalysisResults{}
+	assert.Nil(t, proto.Unmarshal(buffer.Bytes(), &msg))
+	assert.Len(t, msg.Files, 4)
+	assert.Equal(t, map[int32]int32{0: 1145, 1: 207}, msg.Directories["."].Owners)
+	assert.Equal(t, int32(1), msg.Directories["."].BusFactor)
+	assert.Equal(t, []string{".travis.yml", "analyser.go", "cmd/hercules/main.go"},
+		msg.OrphanedFiles)
+	assert.Equal(t, int32(1), msg.OrphanDays)
+	assert.Equal(t, result.EndTime, msg.EndTime)
+	assert.Equal(t, []string{"one@srcd", "two@srcd"}, msg.DevIndex)
+	deserialized, err := ownership.Deserialize(buffer.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, result, deserialized)
+	_, err = ownership.Deserialize([]byte("xxx"))
+	assert.NotNil(t, err)
+}
+
+func TestOwnershipSerializeJSON(t *testing.T) {
+	ownership, lines := fixtureOwnership()
+	consumeOwnership(t, ownership, lines)
+	result := ownership.Finalize().(OwnershipResult)
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, ownership.SerializeJSON(result, buffer))
+	var parsed struct {
+		Files map[string]struct {
+			Owners    map[string]int `json:"owners"`
+			BusFactor int            `json:"bus_factor"`
+		} `json:"files"`
+		OrphanedFiles   []string         `json:"orphaned_files"`
+		LastCommitTimes map[string]int64 `json:"last_commit_times"`
+		People          []string         `json:"people"`
+	}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &parsed))
+	assert.Equal(t, map[string]int{"1": 207}, parsed.Files["internal/main.go"].Owners)
+	assert.Equal(t, 1, parsed.Files["internal/main.go"].BusFactor)
+	assert.Equal(t, []string{".travis.yml", "analyser.go", "cmd/hercules/main.go"},
+		parsed.OrphanedFiles)
+	assert.Len(t, parsed.LastCommitTimes, 2)
+	assert.Equal(t, []string{"one@srcd", "two@srcd"}, parsed.People)
+}
+
+func TestOwnershipMergeResults(t *testing.T) {
+	r1 := OwnershipResult{
+		Files:              map[string]map[int]int{"a": {0: 10, -1: 1}, "b/c": {1: 5}},
+		Directories:        map[string]map[int]int{".": {0: 10, 1: 5, -1: 1}, "b": {1: 5}},
+		LastCommitTimes:    map[int]int64{0: 100, 1: 200},
+		EndTime:            200,
+		OrphanDays:         30,
+		reversedPeopleDict: []string{"one", "two"},
+	}
+	r2 := OwnershipResult{
+		Files:              map[string]map[int]int{"a": {0: 3}, "d": {1: 7}},
+		Directories:        map[string]map[int]int{".": {0: 3, 1: 7}},
+		LastCommitTimes:    map[int]int64{0: 300, 1: 50},
+		EndTime:            300,
+		OrphanDays:         60,
+		reversedPeopleDict: []string{"three", "one"},
+	}
+	merged := (&OwnershipAnalysis{}).MergeResults(r1, r2, nil, nil).(OwnershipResult)
+	assert.Equal(t, []string{"one", "two", "three"}, merged.reversedPeopleDict)
+	assert.Equal(t, map[string]map[int]int{
+		"a":   {0: 10, -1: 1, 2: 3},
+		"b/c": {1: 5},
+		"d":   {0: 7},
+	}, merged.Files)
+	assert.Equal(t, map[string]map[int]int{
+		".": {0: 17, 1: 5, 2: 3, -1: 1},
+		"b": {1: 5},
+	}, merged.Directories)
+	assert.Equal(t, map[int]int64{0: 100, 1: 200, 2: 300}, merged.LastCommitTimes)
+	assert.Equal(t, int64(300), merged.EndTime)
+	assert.Equal(t, 30, merged.OrphanDays)
+}
@@ -1,6 +1,7 @@
 package leaves
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -389,6 +390,12 @@ func (shotness *ShotnessAnalysis) Serialize(result interface{}, binary bool, wri
 	return nil
 }
 
+// SerializeJSON converts the analysis result as returned by Finalize() to JSON.
+func (shotness *ShotnessAnalysis) SerializeJSON(result interface{}, writer io.Writer) error {
+	shotnessResult := result.(ShotnessResult)
+	return shotness.serializeJSON(&shotnessResult, writer)
+}
+
 func (shotness *ShotnessAnalysis) serializeText(result *ShotnessResult, writer io.Writer) {
 	for i, summary := range result.Nodes {
 		fmt.Fprintf(writer, "  - name: %s\n    file: %s\n    internal_role: %s\n    counters: {",
@@ -413,6 +420,38 @@ func (shotness *ShotnessAnalysis) serializeText(result *ShotnessResult, writer i
 	}
 }
 
+// shotnessJSON is the JSON schema of ShotnessResult, see doc/JSON.md.
+type shotnessJSON struct {
+	Records []shotnessRecordJSON `json:"records"`
+}
+
+// shotnessRecordJSON is the JSON schema of a single node in ShotnessResult, see doc/JSON.md.
+type shotnessRecordJSON struct {
+	Name         string      `json:"name"`
+	File         string      `json:"file"`
+	InternalRole string      `json:"internal_role"`
+	Counters     map[int]int `json:"counters"`
+}
+
+func (shotness *ShotnessAnalysis) serializeJSON(result *ShotnessResult, writer io.Writer) error {
+	message := shotnessJSON{
+		Records: make([]shotnessRecordJSON, len(result.Nodes)),
+	}
+	for i, summary := range result.Nodes {
+		counters := result.Counters[i]
+		if counters == nil {
+			counters = map[int]int{}
+		}
+		message.Records[i] = shotnessRecordJSON{
+			Name:         summary.Name,
+			File:         summary.File,
+			InternalRole: summary.Type,
+			Counters:     counters,
+		}
+	}
+	return json.NewEncoder(writer).Encode(&message)
+}
+
 func (shotness *ShotnessAnalysis) serializeBinary(result *ShotnessResult, writer io.Writer) error {
 	message := pb.ShotnessAnalysisResults{
 		Records: make([]*pb.ShotnessRecord, len(result.Nodes)),
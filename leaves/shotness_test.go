@@ -315,6 +315,25 @@ func TestShotnessSerializeText(t *testing.T) {
 `)
 }
 
+func TestShotnessSerializeJSON(t *testing.T) {
+	sh := fixtureShotness()
+	result := ShotnessResult{
+		Nodes: []NodeSummary{
+			{Type: "uast:FunctionGroup", Name: "testAddEntry", File: "test.java"},
+			{Type: "uast:FunctionGroup", Name: "testZipException", File: "test.java"},
+		},
+		Counters: []map[int]int{{0: 2, 1: 1}, nil},
+	}
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, sh.SerializeJSON(result, buffer))
+	assert.Equal(t, `{"records":[`+
+		`{"name":"testAddEntry","file":"test.java","internal_role":"uast:FunctionGroup",`+
+		`"counters":{"0":2,"1":1}},`+
+		`{"name":"testZipException","file":"test.java","internal_role":"uast:FunctionGroup",`+
+		`"counters":{}}]}
+`, buffer.String())
+}
+
 func TestShotnessSerializeBinary(t *testing.T) {
 	sh, result := bakeShotness(t, false)
 	buffer := &bytes.Buffer{}